@@ -11,6 +11,8 @@ import (
 	"sync/atomic"
 	"syscall"
 	"time"
+	// The runtime image has no zoneinfo; embed it for CAPACITY_TIMEZONE
+	_ "time/tzdata"
 
 	"github.com/jmoiron/sqlx"
 	redislib "github.com/redis/go-redis/v9"
@@ -18,13 +20,16 @@ import (
 	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/adapters/cache"
 	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/adapters/database"
 	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/adapters/events"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/adapters/providers/embedding"
 	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/adapters/providers/geolocation"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/adapters/providers/routing"
 	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/adapters/providers/scheduling"
 	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/adapters/search"
 	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/api/handlers"
 	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/api/middleware"
 	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/api/routes"
 	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/application/services"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
 	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/providers"
 	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/repositories"
 	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/infrastructure/clients/openai"
@@ -32,6 +37,7 @@ import (
 	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/infrastructure/clients/providerapi"
 	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/infrastructure/clients/redis"
 	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/infrastructure/clients/typesense"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/infrastructure/notifications"
 	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/infrastructure/observability"
 	"github.com/zatekoja/Patientpricediscoverydesign/backend/pkg/config"
 	"github.com/zatekoja/Patientpricediscoverydesign/backend/pkg/secrets"
@@ -198,6 +204,16 @@ func main() {
 		log.Warn().Msg("Event bus disabled (Redis not available)")
 	}
 
+	gazetteerPath := "config/gazetteer.json"
+	if _, err := os.Stat("backend/" + gazetteerPath); err == nil {
+		gazetteerPath = "backend/" + gazetteerPath
+	}
+	gazetteer, err := geolocation.NewGazetteerGeolocationProvider(gazetteerPath)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to load gazetteer; place names resolve only through the geolocation provider")
+		gazetteer = nil
+	}
+
 	var geolocationProvider providers.GeolocationProvider
 	switch cfg.Geolocation.Provider {
 	case "google":
@@ -207,9 +223,21 @@ func main() {
 		} else {
 			geolocationProvider = geolocation.NewGoogleGeolocationProvider(cfg.Geolocation.APIKey, cacheProvider)
 		}
+	case "gazetteer":
+		if gazetteer != nil {
+			geolocationProvider = gazetteer
+		} else {
+			geolocationProvider = geolocation.NewMockGeolocationProvider()
+		}
 	default:
 		geolocationProvider = geolocation.NewMockGeolocationProvider()
 	}
+	if gazetteer != nil && cfg.Geolocation.Provider != "gazetteer" {
+		geolocationProvider = geolocation.NewTieredGeolocationProvider(gazetteer, geolocationProvider)
+	}
+	// Stored geocodes, including admin-pinned locations, answer before any provider
+	geocodeAdapter := database.NewGeocodeAdapter(pgClient)
+	geolocationProvider = geolocation.NewStoredGeolocationProvider(geolocationProvider, geocodeAdapter)
 
 	calendlyAPIKey := strings.TrimSpace(os.Getenv("CALENDLY_API_KEY"))
 	allowMockScheduling := strings.EqualFold(os.Getenv("ALLOW_MOCK_SCHEDULING"), "true")
@@ -243,6 +271,7 @@ func main() {
 		procedureAdapter,
 		insuranceAdapter,
 	)
+	facilityService.SetGeocodeRepository(geocodeAdapter)
 
 	// Initialize term expansion service
 	// Try multiple paths for robustness
@@ -276,18 +305,27 @@ func main() {
 	// Initialize Query Understanding and Search Ranking services
 	conceptDictPath := "config/concept_dictionary.json"
 	spellingPath := "config/spelling_corrections.json"
+	languagesDir := "config/languages"
 	if _, err := os.Stat("backend/" + conceptDictPath); err == nil {
 		conceptDictPath = "backend/" + conceptDictPath
 		spellingPath = "backend/" + spellingPath
+		languagesDir = "backend/" + languagesDir
 	}
 
 	quService, err := services.NewQueryUnderstandingService(conceptDictPath, spellingPath)
 	if err != nil {
 		log.Warn().Err(err).Msg("Failed to initialize Query Understanding Service")
 	} else {
+		if err := quService.LoadLanguageProfiles(languagesDir); err != nil {
+			log.Warn().Err(err).Msg("Failed to load query language profiles; continuing English-only")
+		}
 		if cacheProvider != nil {
 			quService.SetCache(cacheProvider)
 		}
+		quService.SetInsuranceRepository(insuranceAdapter)
+		if gazetteer != nil {
+			quService.SetGazetteer(gazetteer)
+		}
 		facilityService.SetQueryUnderstanding(quService)
 		log.Info().Msg("Query Understanding Service initialized successfully")
 	}
@@ -301,12 +339,71 @@ func main() {
 	facilityService.SetFeatureFlags(featureFlags)
 	log.Info().Msg("Feature Flags initialized successfully")
 
+	// Semantic search fuses keyword matches with embedding similarity. The
+	// provider must be the one the indexer embedded documents with.
+	var semanticSearch *services.SemanticSearch
+	embeddingProvider, err := embedding.NewProvider(cfg)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to initialize embedding provider; search stays keyword-only")
+	} else {
+		semanticSearch = services.NewSemanticSearch(embeddingProvider, cfg.Embedding.VectorWeight, cfg.Embedding.MinSimilarity)
+		facilityService.SetSemanticSearch(semanticSearch)
+		log.Info().Str("model", embeddingProvider.EmbeddingModel()).Bool("enabled", featureFlags.SemanticSearchEnabled()).Msg("Semantic search initialized")
+	}
+
+	// Travel times by road for search results, the travel_time sort and,
+	// behind FEATURE_TRAVEL_TIME_RANKING, ranking
+	routingProvider, err := routing.NewProvider(cfg)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to initialize routing provider; results show straight-line distance only")
+	} else {
+		if cacheProvider != nil {
+			routingProvider = routing.NewCachedRoutingProvider(routingProvider, cacheProvider)
+		}
+		facilityService.SetRoutingProvider(routingProvider)
+		log.Info().Str("provider", cfg.Routing.Provider).Bool("ranking", featureFlags.TravelTimeRankingEnabled()).Msg("Routing provider initialized")
+	}
+
 	// Initialize Search Analytics
 	analyticsAdapter := database.NewSearchAnalyticsAdapter(pgClient)
 	analyticsService := services.NewSearchAnalyticsService(analyticsAdapter)
 	facilityService.SetAnalytics(analyticsService)
 	log.Info().Msg("Search Analytics Service initialized successfully")
 
+	// Initialize search A/B experiments
+	experimentAdapter := database.NewSearchExperimentAdapter(pgClient)
+	experimentService := services.NewSearchExperimentService(experimentAdapter, analyticsAdapter)
+	if err := experimentService.LoadRunning(ctx); err != nil {
+		log.Warn().Err(err).Msg("Failed to load running search experiment")
+	}
+	// Admin calls land on one replica; the others pick up starts and stops here
+	experimentService.WatchRunning(ctx, 15*time.Second)
+	facilityService.SetExperiments(experimentService)
+	log.Info().Msg("Search Experiment Service initialized successfully")
+
+	// Initialize search click and conversion tracking
+	interactionAdapter := database.NewSearchInteractionAdapter(pgClient)
+	interactionService := services.NewSearchInteractionService(interactionAdapter)
+	log.Info().Msg("Search Interaction Service initialized successfully")
+
+	// Initialize zero-result query triage and re-apply previously accepted dictionary fixes
+	dictionaryFixAdapter := database.NewSearchDictionaryFixAdapter(pgClient)
+	searchTriageService := services.NewSearchTriageService(analyticsAdapter, dictionaryFixAdapter, procedureAdapter, quService)
+	if applied, err := searchTriageService.LoadAcceptedFixes(ctx); err != nil {
+		log.Warn().Err(err).Msg("Failed to load accepted search dictionary fixes")
+	} else if applied > 0 {
+		log.Info().Int("fixes", applied).Msg("Applied accepted search dictionary fixes")
+	}
+	// Pick up fixes accepted through other replicas and track pending fixes' outcomes
+	searchTriageService.WatchAcceptedFixes(ctx, time.Minute)
+
+	// Record operator and admin mutations in the hash-chained audit log
+	auditService := services.NewAuditService(database.NewAuditAdapter(pgClient))
+	auditService.SetTransactor(database.NewTransactor(pgClient))
+	facilityService.SetAuditLog(auditService)
+	experimentService.SetAuditLog(auditService)
+	searchTriageService.SetAuditLog(auditService)
+
 	// Set metrics for observability
 	if metrics != nil {
 		facilityService.SetMetrics(metrics)
@@ -318,10 +415,15 @@ func main() {
 		log.Info().Msg("Facility ward repository configured for facility service")
 	}
 
-	// Set event bus for real-time updates
+	// Set event bus for real-time updates. Facility events are recorded in the
+	// outbox with each change and relayed to the bus in order.
+	var facilityEventOutbox repositories.FacilityEventOutboxRepository
 	if eventBus != nil {
 		facilityService.SetEventBus(eventBus)
-		log.Info().Msg("Event bus configured for facility service")
+		facilityEventOutbox = database.NewFacilityEventOutboxAdapter(pgClient)
+		facilityService.SetOutbox(facilityEventOutbox)
+		services.NewFacilityEventRelayService(facilityEventOutbox, eventBus).Start(ctx, time.Second)
+		log.Info().Msg("Event bus and facility event outbox configured for facility service")
 	}
 
 	// Initialize cache invalidation service
@@ -342,20 +444,66 @@ func main() {
 		enrichmentProvider,
 	)
 
+	// Initialize notification senders; each channel is enabled by its own credentials
+	var notificationSenders []providers.NotificationSender
+	whatsappSender, err := notifications.NewWhatsAppCloudSender()
+	if err == nil {
+		notificationSenders = append(notificationSenders, whatsappSender)
+	} else {
+		log.Warn().Msg("WhatsApp credentials not configured; WhatsApp notifications disabled")
+	}
+	smsSender, err := notifications.NewHTTPSMSSender()
+	if err == nil {
+		notificationSenders = append(notificationSenders, smsSender)
+	} else {
+		log.Warn().Msg("SMS gateway not configured; SMS notifications disabled")
+	}
+	if emailSender, err := notifications.NewSMTPEmailSender(); err == nil {
+		notificationSenders = append(notificationSenders, emailSender)
+	} else {
+		log.Warn().Err(err).Msg("SMTP not configured; email notifications disabled")
+	}
+
 	// Initialize notification service
 	var notificationService *services.NotificationService
-	if os.Getenv("WHATSAPP_ACCESS_TOKEN") != "" && os.Getenv("WHATSAPP_PHONE_NUMBER_ID") != "" {
-		var err error
+	if len(notificationSenders) > 0 {
 		// Wrap sql.DB with sqlx for extended functionality
 		sqlxDB := sqlx.NewDb(pgClient.DB(), "postgres")
-		notificationService, err = services.NewNotificationService(sqlxDB)
+		notificationService, err = services.NewNotificationServiceWithSenders(sqlxDB, notificationSenders...)
 		if err != nil {
 			log.Warn().Err(err).Msg("Failed to initialize notification service")
 		} else {
-			log.Info().Msg("Notification service initialized successfully")
+			notificationService.SetAuditLog(auditService)
+			log.Info().Int("channels", len(notificationSenders)).Msg("Notification service initialized successfully")
 		}
 	} else {
-		log.Warn().Msg("WhatsApp credentials not configured; notification service disabled")
+		log.Warn().Msg("No notification channels configured; notification service disabled")
+	}
+
+	// Decay stale capacity to unknown and remind operators before their values expire
+	facilityService.SetCapacityFreshnessPolicy(entities.CapacityFreshnessPolicy{
+		CapacityStatusTTL: time.Duration(cfg.Capacity.StatusTTLMinutes) * time.Minute,
+		WaitTimeTTL:       time.Duration(cfg.Capacity.WaitTimeTTLMinutes) * time.Minute,
+		UrgentCareTTL:     time.Duration(cfg.Capacity.UrgentCareTTLMinutes) * time.Minute,
+		NudgeBefore:       time.Duration(cfg.Capacity.NudgeBeforeMinutes) * time.Minute,
+	})
+	capacityFreshnessService := services.NewCapacityFreshnessService(database.NewCapacityFreshnessAdapter(pgClient), facilityService)
+	capacityFreshnessService.SetNudgeSenders(notificationSenders...)
+	if cfg.Capacity.CheckIntervalMinutes > 0 {
+		capacityFreshnessService.Start(ctx, time.Duration(cfg.Capacity.CheckIntervalMinutes)*time.Minute)
+	}
+
+	// Record every capacity report and learn typical waits per hour of the week
+	capacityHistoryService := services.NewCapacityHistoryService(database.NewCapacityHistoryAdapter(pgClient), facilityService)
+	if location, err := time.LoadLocation(cfg.Capacity.Timezone); err == nil {
+		capacityHistoryService.SetLocation(location)
+	} else {
+		log.Warn().Err(err).Str("timezone", cfg.Capacity.Timezone).Msg("Unknown capacity timezone; using UTC")
+	}
+	capacityHistoryService.SetRetention(time.Duration(cfg.Capacity.HistoryRetentionDays) * 24 * time.Hour)
+	facilityService.SetCapacityHistory(capacityHistoryService)
+	if cfg.Capacity.HistoryRebuildIntervalMinutes > 0 {
+		capacityHistoryService.Start(ctx, time.Duration(cfg.Capacity.HistoryRebuildIntervalMinutes)*time.Minute)
 	}
 
 	appointmentService := services.NewAppointmentService(
@@ -367,6 +515,39 @@ func main() {
 		notificationService,
 	)
 
+	// Facilities with an enabled native calendar book against Postgres slots instead of Calendly
+	calendarAdapter := database.NewCalendarAdapter(pgClient)
+	nativeScheduling := scheduling.NewNativeAdapter(
+		calendarAdapter,
+		database.NewAvailabilityAdapter(pgClient),
+		database.NewSlotReservationAdapter(pgClient),
+	)
+	appointmentService.SetProviderResolver(scheduling.NewProviderRouter(calendarAdapter, nativeScheduling, appointmentProvider))
+	appointmentService.SetAuditLog(auditService)
+	calendarService := services.NewCalendarService(calendarAdapter, nativeScheduling)
+	calendarService.SetAuditLog(auditService)
+
+	// Reviews read facilities uncached so reindexing sees the recomputed rating
+	reviewService := services.NewReviewService(database.NewReviewAdapter(pgClient), appointmentAdapter, baseFacilityAdapter)
+	reviewService.SetReindexer(facilityService)
+	reviewService.SetAuditLog(auditService)
+
+	accountService := services.NewAccountService(
+		database.NewUserAdapter(pgClient),
+		database.NewLoginOTPAdapter(pgClient),
+		database.NewSessionAdapter(pgClient),
+		database.NewSavedFacilityAdapter(pgClient),
+		database.NewNotificationPreferenceAdapter(pgClient),
+		appointmentAdapter,
+		facilityAdapter,
+	)
+	if whatsappSender != nil {
+		accountService.SetCodeSender(whatsappSender)
+	}
+	if smsSender != nil {
+		accountService.SetCodeSender(smsSender)
+	}
+
 	// Start cache warming service for improved read performance
 	if cacheProvider != nil {
 		warmingService := services.NewCacheWarmingService(
@@ -393,10 +574,39 @@ func main() {
 	feedbackHandler := handlers.NewFeedbackHandler(feedbackService, cacheProvider)
 
 	providerPriceHandler := handlers.NewProviderPriceHandler(providerClient)
+	providerPriceHandler.SetAuditLog(auditService)
+	searchTriageHandler := handlers.NewSearchTriageHandler(searchTriageService)
+	experimentHandler := handlers.NewSearchExperimentHandler(experimentService)
+	interactionHandler := handlers.NewSearchInteractionHandler(interactionService)
+	calendarHandler := handlers.NewCalendarHandler(calendarService)
+	reviewHandler := handlers.NewReviewHandler(reviewService)
+	accountHandler := handlers.NewAccountHandler(accountService)
 
 	// Initialize fee waiver handler
 	feeWaiverAdapter := database.NewFeeWaiverAdapter(pgClient)
 	feeWaiverHandler := handlers.NewFeeWaiverHandler(feeWaiverAdapter)
+	feeWaiverHandler.SetAuditLog(auditService)
+	auditHandler := handlers.NewAuditHandler(auditService)
+	waitForecastHandler := handlers.NewWaitForecastHandler(capacityHistoryService)
+
+	// Care basket quotes take items by procedure ID, and by free text when
+	// procedure search is available
+	careBasketService := services.NewCareBasketService(database.NewProcedureOfferAdapter(pgClient), procedureAdapter, database.NewFacilityProcedureCategoryAdapter(pgClient), feeWaiverAdapter)
+	if quService != nil {
+		careBasketService.SetQueryUnderstanding(quService)
+	}
+	careBasketHandler := handlers.NewCareBasketHandler(careBasketService)
+
+	// Procedure search and typed autocomplete need the procedures collection
+	var procedureSearchHandler *handlers.ProcedureSearchHandler
+	if typesenseClient != nil {
+		procedureSearchService := services.NewProcedureSearchService(search.NewProcedureSearchAdapter(typesenseClient), facilityService)
+		if semanticSearch != nil && featureFlags.SemanticSearchEnabled() {
+			procedureSearchService.SetSemanticSearch(semanticSearch)
+		}
+		procedureSearchHandler = handlers.NewProcedureSearchHandler(procedureSearchService)
+		careBasketService.SetProcedureSearch(procedureSearchService)
+	}
 
 	// Initialize Calendly webhook handler
 	var calendlyWebhookHandler *handlers.CalendlyWebhookHandler
@@ -407,6 +617,19 @@ func main() {
 		log.Info().Msg("Calendly webhook handler initialized successfully")
 	}
 
+	// Initialize notification queue handlers and the retry worker
+	var whatsappWebhookHandler *handlers.WhatsAppWebhookHandler
+	var notificationHandler *handlers.NotificationHandler
+	if notificationService != nil {
+		notificationService.StartRetryWorker(ctx, time.Minute)
+		notificationHandler = handlers.NewNotificationHandler(notificationService)
+		if appSecret := os.Getenv("WHATSAPP_APP_SECRET"); appSecret != "" {
+			whatsappWebhookHandler = handlers.NewWhatsAppWebhookHandler(notificationService, appSecret, os.Getenv("WHATSAPP_WEBHOOK_VERIFY_TOKEN"))
+		} else {
+			log.Warn().Msg("WHATSAPP_APP_SECRET not configured; WhatsApp delivery status webhooks disabled")
+		}
+	}
+
 	pageSize := 0
 	if value := strings.TrimSpace(os.Getenv("PROVIDER_INGEST_PAGE_SIZE")); value != "" {
 		if parsed, err := strconv.Atoi(value); err == nil {
@@ -426,6 +649,10 @@ func main() {
 		cacheProvider,
 		pageSize,
 	)
+	if facilityEventOutbox != nil {
+		ingestionService.SetOutbox(facilityEventOutbox)
+	}
+	ingestionService.SetGeocodeRepository(geocodeAdapter)
 	idempotencyTTL := 24 * time.Hour
 	if value := strings.TrimSpace(os.Getenv("PROVIDER_INGESTION_IDEMPOTENCY_TTL_MINUTES")); value != "" {
 		if parsed, err := strconv.Atoi(value); err == nil && parsed > 0 {
@@ -437,6 +664,7 @@ func main() {
 		redisRaw = redisClient.Client()
 	}
 	providerIngestionHandler := handlers.NewProviderIngestionHandler(ingestionService, redisRaw, idempotencyTTL)
+	providerIngestionHandler.SetAuditLog(auditService)
 
 	// Initialize cache middleware
 	var cacheMiddleware *middleware.CacheMiddleware
@@ -460,9 +688,33 @@ func main() {
 		providerIngestionHandler,
 		calendlyWebhookHandler,
 		feeWaiverHandler,
+		searchTriageHandler,
+		experimentHandler,
+		interactionHandler,
+		calendarHandler,
+		reviewHandler,
+		accountHandler,
+		whatsappWebhookHandler,
+		notificationHandler,
+		auditHandler,
+		waitForecastHandler,
+		procedureSearchHandler,
+		careBasketHandler,
 		metrics,
 	)
 
+	router.SetImpressionRecorder(analyticsService)
+
+	// Audit entries name the operator or account behind the bearer token
+	trustedProxies, err := middleware.ParseTrustedProxies(cfg.Audit.TrustedProxies)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Invalid TRUSTED_PROXIES")
+	}
+	if len(cfg.Audit.OperatorTokens) == 0 {
+		log.Warn().Msg("No AUDIT_OPERATOR_TOKENS configured; /api/admin endpoints will reject every request")
+	}
+	router.SetAuditIdentity(middleware.NewAuditActors(cfg.Audit.OperatorTokens, accountService), trustedProxies)
+
 	handler := router.SetupRoutes()
 
 	// Create HTTP server
@@ -3,11 +3,17 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"flag"
 	"fmt"
 	"log"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/adapters/database"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/adapters/providers/embedding"
 	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/adapters/search"
 	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/application/services"
 	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
@@ -29,19 +35,159 @@ func (w *serviceWrapper) Search(ctx context.Context, params repositories.SearchP
 }
 
 func main() {
-	cfg, err := config.Load()
+	goldenPath := flag.String("golden", "config/golden_queries.json", "golden query set")
+	fixturePath := flag.String("fixture", "", "run against an in-memory fixture corpus instead of Postgres/Typesense (e.g. config/eval_fixture_corpus.json)")
+	baselinePath := flag.String("baseline", "", "baseline report to compare against")
+	thresholdsPath := flag.String("thresholds", "config/eval_thresholds.json", "regression thresholds")
+	reportDir := flag.String("report-dir", "", "directory to write the run report to")
+	updateBaseline := flag.Bool("update-baseline", false, "overwrite the baseline with this run instead of comparing")
+	format := flag.String("format", "json", "output format: json or table")
+	semantic := flag.Bool("semantic", false, "fuse keyword search with embedding similarity (EMBEDDING_PROVIDER selects the embedder)")
+	persist := flag.Bool("persist", false, "record fixture runs in the eval_runs table too (live runs are always recorded)")
+	history := flag.Int("history", 0, "print the last N recorded runs against the same corpus and golden set")
+	flag.Parse()
+
+	if *format != "json" && *format != "table" {
+		log.Fatalf("Unknown format %q (must be json or table)", *format)
+	}
+
+	facilityService, fixture, pgClient, cleanup := newFacilityService(*fixturePath)
+	defer cleanup()
+	corpus := "live"
+	if fixture != nil {
+		corpus = "fixture"
+	}
+
+	// Runs are recorded so metrics can be compared across commits
+	var runStore evaluation.RunStore
+	if pgClient == nil && (*persist || *history > 0) {
+		pgClient = connectPostgres()
+		defer pgClient.Close()
+	}
+	if pgClient != nil {
+		runStore = database.NewEvalRunAdapter(pgClient)
+	}
+
+	// Initialize Query Understanding and Search Ranking
+	quService, err := services.NewQueryUnderstandingService(resolvePath("config/concept_dictionary.json"), resolvePath("config/spelling_corrections.json"))
+	if err == nil {
+		if err := quService.LoadLanguageProfiles(resolvePath("config/languages")); err != nil {
+			log.Printf("Warning: failed to load language profiles: %v", err)
+		}
+		facilityService.SetQueryUnderstanding(quService)
+	}
+
+	rankingService := services.NewSearchRankingService()
+	facilityService.SetSearchRanking(rankingService)
+
+	var embeddingModel string
+	if *semantic {
+		embeddingModel = enableSemanticSearch(facilityService, fixture)
+	}
+
+	// Load Golden Queries
+	queries, err := evaluation.LoadGoldenQueries(resolvePath(*goldenPath))
 	if err != nil {
-		log.Fatalf("Failed to load config: %v", err)
+		log.Fatalf("Failed to load golden queries: %v", err)
 	}
 
-	pgClient, err := postgres.NewClient(&cfg.Database)
+	runner := evaluation.NewRunner(&serviceWrapper{svc: facilityService})
+	summary, err := runner.Run(context.Background(), queries)
 	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+		log.Fatalf("Evaluation failed: %v", err)
+	}
+
+	report := &evaluation.Report{
+		CreatedAt:      time.Now().UTC(),
+		Corpus:         corpus,
+		EmbeddingModel: embeddingModel,
+		GoldenSet:      *goldenPath,
+		GitCommit:      gitCommit(),
+		Summary:        summary,
+	}
+
+	if *updateBaseline {
+		if *baselinePath == "" {
+			log.Fatalf("-update-baseline requires -baseline")
+		}
+		if err := evaluation.SaveReport(resolvePath(*baselinePath), report); err != nil {
+			log.Fatalf("Failed to write baseline: %v", err)
+		}
+		log.Printf("Baseline written to %s", *baselinePath)
+		recordRun(runStore, report, *history)
+		return
+	}
+
+	var thresholds evaluation.Thresholds
+	if *thresholdsPath != "" {
+		if thresholds, err = evaluation.LoadThresholds(resolvePath(*thresholdsPath)); err != nil {
+			log.Fatalf("Failed to load thresholds: %v", err)
+		}
+	}
+
+	var baseline *evaluation.EvalSummary
+	if *baselinePath != "" {
+		baseReport, err := evaluation.LoadReport(resolvePath(*baselinePath))
+		if err != nil {
+			log.Fatalf("Failed to load baseline: %v", err)
+		}
+		if baseReport.Corpus != corpus {
+			log.Printf("Warning: baseline was recorded against the %s corpus, this run uses %s", baseReport.Corpus, corpus)
+		}
+		if baseReport.EmbeddingModel != embeddingModel {
+			log.Printf("Warning: baseline was recorded with embedding model %q, this run uses %q", baseReport.EmbeddingModel, embeddingModel)
+		}
+		baseline = baseReport.Summary
+	}
+	report.Comparison = evaluation.Compare(baseline, summary, thresholds)
+
+	if *reportDir != "" {
+		path, err := evaluation.SaveReportToDir(*reportDir, report)
+		if err != nil {
+			log.Fatalf("Failed to write report: %v", err)
+		}
+		log.Printf("Report written to %s", path)
+	}
+
+	if *format == "table" {
+		if err := report.Comparison.WriteTable(os.Stdout); err != nil {
+			log.Fatalf("Failed to write table: %v", err)
+		}
+	} else {
+		out, _ := json.MarshalIndent(report, "", "  ")
+		fmt.Println(string(out))
+	}
+	recordRun(runStore, report, *history)
+
+	if !report.Comparison.Passed() {
+		log.Printf("Regression gate failed with %d breach(es)", len(report.Comparison.Breaches))
+		cleanup()
+		os.Exit(1)
+	}
+}
+
+// newFacilityService builds the search stack against either the fixture corpus,
+// which it returns, or the live Postgres and Typesense backends, returning
+// the Postgres client.
+func newFacilityService(fixturePath string) (*services.FacilityService, *evaluation.FixtureCorpus, *postgres.Client, func()) {
+	if fixturePath != "" {
+		corpus, err := evaluation.LoadFixtureCorpus(resolvePath(fixturePath))
+		if err != nil {
+			log.Fatalf("Failed to load fixture corpus: %v", err)
+		}
+		return services.NewFacilityService(corpus, nil, nil, nil, nil), corpus, nil, func() {}
 	}
-	defer pgClient.Close()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	pgClient := connectPostgres()
 
 	tsClient, err := typesense.NewClient(&cfg.Typesense)
 	if err != nil {
+		pgClient.Close()
 		log.Fatalf("Failed to connect to Typesense: %v", err)
 	}
 
@@ -52,7 +198,6 @@ func main() {
 	procedureCatalogRepo := database.NewProcedureAdapter(pgClient)
 	insuranceRepo := database.NewInsuranceAdapter(pgClient)
 
-	// Setup Services
 	facilityService := services.NewFacilityService(
 		facilityRepo,
 		searchRepo,
@@ -60,41 +205,89 @@ func main() {
 		procedureCatalogRepo,
 		insuranceRepo,
 	)
+	return facilityService, nil, pgClient, func() { pgClient.Close() }
+}
 
-	// Initialize Query Understanding and Search Ranking
-	conceptDictPath := "config/concept_dictionary.json"
-	spellingPath := "config/spelling_corrections.json"
-	if _, err := os.Stat("backend/" + conceptDictPath); err == nil {
-		conceptDictPath = "backend/" + conceptDictPath
-		spellingPath = "backend/" + spellingPath
+func connectPostgres() *postgres.Client {
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
 	}
-
-	quService, err := services.NewQueryUnderstandingService(conceptDictPath, spellingPath)
-	if err == nil {
-		facilityService.SetQueryUnderstanding(quService)
+	pgClient, err := postgres.NewClient(&cfg.Database)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
 	}
+	return pgClient
+}
 
-	rankingService := services.NewSearchRankingService()
-	facilityService.SetSearchRanking(rankingService)
-
-	// Load Golden Queries
-	goldenPath := "config/golden_queries.json"
-	if _, err := os.Stat("backend/" + goldenPath); err == nil {
-		goldenPath = "backend/" + goldenPath
+// recordRun stores the run when a run store is configured and prints the
+// last history runs against the same corpus and golden set.
+func recordRun(store evaluation.RunStore, report *evaluation.Report, history int) {
+	if store == nil {
+		return
 	}
+	ctx := context.Background()
+	id, err := store.SaveRun(ctx, report)
+	if err != nil {
+		log.Fatalf("Failed to record run: %v", err)
+	}
+	log.Printf("Run recorded as eval run %d", id)
 
-	queries, err := evaluation.LoadGoldenQueries(goldenPath)
+	if history <= 0 {
+		return
+	}
+	runs, err := store.ListRuns(ctx, report.Corpus, report.GoldenSet, history)
 	if err != nil {
-		log.Fatalf("Failed to load golden queries: %v", err)
+		log.Fatalf("Failed to list recorded runs: %v", err)
+	}
+	fmt.Fprintln(os.Stderr)
+	if err := evaluation.WriteHistoryTable(os.Stderr, runs); err != nil {
+		log.Fatalf("Failed to write history: %v", err)
 	}
+}
 
-	runner := evaluation.NewRunner(&serviceWrapper{svc: facilityService})
-	summary, err := runner.Run(context.Background(), queries)
+// enableSemanticSearch makes searches hybrid with the configured embedding
+// provider and returns its model. The fixture corpus is embedded here; the
+// live index must have been built by an indexer using the same provider.
+func enableSemanticSearch(facilityService *services.FacilityService, fixture *evaluation.FixtureCorpus) string {
+	cfg, err := config.Load()
 	if err != nil {
-		log.Fatalf("Evaluation failed: %v", err)
+		log.Fatalf("Failed to load config: %v", err)
 	}
+	provider, err := embedding.NewProvider(cfg)
+	if err != nil {
+		log.Fatalf("Failed to create embedding provider: %v", err)
+	}
+	if fixture != nil {
+		if err := fixture.EmbedFacilities(context.Background(), provider); err != nil {
+			log.Fatalf("Failed to embed fixture corpus: %v", err)
+		}
+	}
+	facilityService.SetSemanticSearch(services.NewSemanticSearch(provider, cfg.Embedding.VectorWeight, cfg.Embedding.MinSimilarity))
+	return provider.EmbeddingModel()
+}
+
+// resolvePath prefixes relative config paths with "backend/" when run from the
+// repo root. Paths that do not exist yet are resolved by their directory.
+func resolvePath(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	for _, candidate := range []string{path, filepath.Dir(path)} {
+		if _, err := os.Stat(candidate); err == nil {
+			return path
+		}
+		if _, err := os.Stat(filepath.Join("backend", candidate)); err == nil {
+			return filepath.Join("backend", path)
+		}
+	}
+	return path
+}
 
-	// Output results as JSON
-	out, _ := json.MarshalIndent(summary, "", "  ")
-	fmt.Println(string(out))
+func gitCommit() string {
+	out, err := exec.Command("git", "rev-parse", "--short", "HEAD").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
 }
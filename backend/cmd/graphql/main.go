@@ -18,6 +18,7 @@ import (
 	"github.com/vektah/gqlparser/v2/ast"
 	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/adapters/cache"
 	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/adapters/database"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/adapters/providers/routing"
 	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/adapters/search"
 	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/api/middleware"
 	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/application/services"
@@ -141,12 +142,13 @@ func main() {
 	facilityProcedureDBAdapter := database.NewFacilityProcedureAdapter(pgClient)
 	insuranceDBAdapter := database.NewInsuranceAdapter(pgClient)
 
-	// Create search adapter (Typesense)
-	var searchAdapter *search.TypesenseAdapter
+	// Create search adapter (Typesense). It stays a nil interface without
+	// Typesense so facility search falls back to Postgres.
+	var searchAdapter repositories.FacilitySearchRepository
 	if typesenseClient != nil {
 		searchAdapter = search.NewTypesenseAdapter(typesenseClient)
 	} else {
-		log.Warn().Msg("GraphQL: Search adapter unavailable (Typesense not connected)")
+		log.Warn().Msg("GraphQL: Search adapter unavailable (Typesense not connected); searching Postgres")
 	}
 
 	// Initialize cache adapter with QueryCacheProvider wrapper
@@ -175,6 +177,16 @@ func main() {
 		&queryCacheProvider,
 		providerClient,
 	)
+	resolver.SetSearchAnalytics(database.NewSearchAnalyticsAdapter(pgClient))
+	resolver.SetReviewRepository(database.NewReviewAdapter(pgClient))
+	if routingProvider, err := routing.NewProvider(cfg); err != nil {
+		log.Warn().Err(err).Msg("GraphQL: Routing provider unavailable; travel times disabled")
+	} else {
+		if redisClient != nil {
+			routingProvider = routing.NewCachedRoutingProvider(routingProvider, cache.NewRedisAdapter(redisClient))
+		}
+		resolver.SetRoutingProvider(routingProvider)
+	}
 
 	// Create GraphQL server
 	srv := handler.New(generated.NewExecutableSchema(generated.Config{
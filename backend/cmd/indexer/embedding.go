@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/adapters/providers/embedding"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/providers"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/infrastructure/clients/typesense"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/pkg/config"
+)
+
+const (
+	// maxCachedEmbeddings bounds the embedding cache; reconciliation rebuilds
+	// every document, and unchanged texts should not be embedded again
+	maxCachedEmbeddings = 50000
+
+	// embeddingBatchSize is how many texts are sent in one embedding request
+	embeddingBatchSize = 100
+)
+
+// documentEmbedder embeds document texts, caching vectors by text
+type documentEmbedder struct {
+	provider providers.EmbeddingProvider
+
+	mu    sync.Mutex
+	cache map[string][]float32
+}
+
+// newDocumentEmbedder returns an embedder for the configured provider, or nil
+// when it cannot be created, in which case documents are indexed without
+// embeddings and search stays keyword-only
+func newDocumentEmbedder(cfg *config.Config) *documentEmbedder {
+	provider, err := embedding.NewProvider(cfg)
+	if err != nil {
+		log.Printf("Warning: indexing without embeddings: %v", err)
+		return nil
+	}
+	log.Printf("Embedding documents with %s", provider.EmbeddingModel())
+	return &documentEmbedder{provider: provider, cache: map[string][]float32{}}
+}
+
+// embedDocuments sets each document's embedding, and the model it came from,
+// from the text at the same index. It returns how many documents with text
+// were left without an embedding because their batch failed.
+func (e *documentEmbedder) embedDocuments(ctx context.Context, documents []map[string]interface{}, texts []string) int {
+	if e == nil {
+		return 0
+	}
+
+	model := e.provider.EmbeddingModel()
+	vectors := e.embed(ctx, texts)
+	missing := 0
+	for i, doc := range documents {
+		if texts[i] == "" {
+			continue
+		}
+		if vectors[i] == nil {
+			missing++
+			continue
+		}
+		doc[typesense.EmbeddingField] = vectors[i]
+		doc[typesense.EmbeddingModelField] = model
+	}
+	return missing
+}
+
+// embed returns an embedding for each text, nil for empty texts and texts
+// whose batch failed. Texts not already cached are embedded
+// embeddingBatchSize at a time.
+func (e *documentEmbedder) embed(ctx context.Context, texts []string) [][]float32 {
+	vectors := make([][]float32, len(texts))
+	var pending []string
+	queued := map[string]bool{}
+
+	e.mu.Lock()
+	for i, text := range texts {
+		if text == "" {
+			continue
+		}
+		if vector, ok := e.cache[text]; ok {
+			vectors[i] = vector
+			continue
+		}
+		if !queued[text] {
+			queued[text] = true
+			pending = append(pending, text)
+		}
+	}
+	e.mu.Unlock()
+
+	embedded := map[string][]float32{}
+	for start := 0; start < len(pending); start += embeddingBatchSize {
+		batch := pending[start:min(start+embeddingBatchSize, len(pending))]
+		batchVectors, err := e.embedBatch(ctx, batch)
+		if err == nil && len(batchVectors) != len(batch) {
+			err = fmt.Errorf("provider returned %d embeddings for %d texts", len(batchVectors), len(batch))
+		}
+		if err != nil {
+			log.Printf("Warning: failed to embed %d document texts: %v", len(batch), err)
+			continue
+		}
+		for j, text := range batch {
+			embedded[text] = batchVectors[j]
+		}
+	}
+	if len(embedded) == 0 {
+		return vectors
+	}
+
+	e.mu.Lock()
+	if len(e.cache)+len(embedded) > maxCachedEmbeddings {
+		e.cache = map[string][]float32{}
+	}
+	for text, vector := range embedded {
+		e.cache[text] = vector
+	}
+	e.mu.Unlock()
+
+	for i, text := range texts {
+		if vectors[i] == nil {
+			vectors[i] = embedded[text]
+		}
+	}
+	return vectors
+}
+
+// embedBatch embeds through the provider's rate limiter when it has one, so
+// a reindex does not starve interactive searches and enrichment
+func (e *documentEmbedder) embedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	if bulk, ok := e.provider.(providers.BulkEmbeddingProvider); ok {
+		return bulk.EmbedBulk(ctx, texts)
+	}
+	return e.provider.Embed(ctx, texts)
+}
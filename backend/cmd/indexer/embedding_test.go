@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/infrastructure/clients/typesense"
+)
+
+// batchProvider records each request and fails those containing failText
+type batchProvider struct {
+	requests [][]string
+	bulk     int
+	failText string
+}
+
+func (p *batchProvider) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	p.requests = append(p.requests, texts)
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		if text == p.failText {
+			return nil, errors.New("rate limited")
+		}
+		vectors[i] = []float32{float32(len(text))}
+	}
+	return vectors, nil
+}
+
+func (p *batchProvider) EmbedBulk(ctx context.Context, texts []string) ([][]float32, error) {
+	p.bulk++
+	return p.Embed(ctx, texts)
+}
+
+func (p *batchProvider) EmbeddingModel() string {
+	return "test-model"
+}
+
+func TestDocumentEmbedder_BatchesAndCountsMissing(t *testing.T) {
+	provider := &batchProvider{failText: "fails"}
+	embedder := &documentEmbedder{provider: provider, cache: map[string][]float32{}}
+
+	texts := make([]string, 0, embeddingBatchSize+3)
+	for i := 0; i < embeddingBatchSize; i++ {
+		texts = append(texts, string(rune('a'+i%26))+string(rune('a'+i/26)))
+	}
+	// A repeat, a text without content and a batch that fails
+	texts = append(texts, texts[0], "", "fails")
+	documents := make([]map[string]interface{}, len(texts))
+	for i := range documents {
+		documents[i] = map[string]interface{}{}
+	}
+
+	missing := embedder.embedDocuments(context.Background(), documents, texts)
+	if missing != 1 {
+		t.Fatalf("expected 1 document missing its embedding, got %d", missing)
+	}
+	if len(provider.requests) != 2 || provider.bulk != 2 {
+		t.Fatalf("expected 2 rate-limited batch requests, got %d (%d bulk)", len(provider.requests), provider.bulk)
+	}
+	if len(provider.requests[0]) != embeddingBatchSize || len(provider.requests[1]) != 1 {
+		t.Fatalf("expected batches of %d and 1, got %d and %d", embeddingBatchSize, len(provider.requests[0]), len(provider.requests[1]))
+	}
+	if documents[0][typesense.EmbeddingModelField] != "test-model" || documents[embeddingBatchSize][typesense.EmbeddingField] == nil {
+		t.Fatalf("expected embedded documents to carry their model, got %v", documents[0])
+	}
+	if _, ok := documents[embeddingBatchSize+1][typesense.EmbeddingField]; ok {
+		t.Fatal("expected no embedding for empty text")
+	}
+
+	// Cached texts are not embedded again
+	embedder.embedDocuments(context.Background(), documents[:1], texts[:1])
+	if len(provider.requests) != 2 {
+		t.Fatalf("expected the cached text to be reused, got %d requests", len(provider.requests))
+	}
+}
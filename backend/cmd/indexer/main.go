@@ -3,29 +3,80 @@ package main
 import (
 	"context"
 	"flag"
+	"fmt"
 	"log"
 	"os"
 	"os/signal"
+	"sort"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/adapters/database"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/adapters/events"
 	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/adapters/search"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/application/services"
 	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
 	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/repositories"
 	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/infrastructure/clients/postgres"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/infrastructure/clients/redis"
 	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/infrastructure/clients/typesense"
 	"github.com/zatekoja/Patientpricediscoverydesign/backend/pkg/config"
 )
 
+// minShrinkRatio is the smallest share of the live collection's documents a
+// new build may hold before it is refused as a likely partial read
+const minShrinkRatio = 0.5
+
+// smokeSamples is how many indexed facilities are searched for before a
+// new collection goes live
+const smokeSamples = 3
+
+// facilityPageSize is how many facilities are read from Postgres at a time
+const facilityPageSize = 1000
+
+type options struct {
+	keep        int
+	allowShrink bool
+}
+
+type followOptions struct {
+	debounce  time.Duration
+	reconcile time.Duration
+}
+
 func main() {
-	var reset bool
+	var reset, check, follow bool
 	var intervalFlag string
-	flag.BoolVar(&reset, "reset", false, "delete existing Typesense collection before reindexing")
+	var opts options
+	var followOpts followOptions
+	flag.BoolVar(&reset, "reset", false, "deprecated: every run builds a new collection and swaps it in")
+	flag.BoolVar(&check, "check", false, "report drift between the schema in code and the live collection, then exit")
+	flag.IntVar(&opts.keep, "keep", 1, "previous collections to keep for rollback")
+	flag.BoolVar(&opts.allowShrink, "allow-shrink", false, "go live even if the new collection holds under half the documents of the live one")
 	flag.StringVar(&intervalFlag, "interval", "", "repeat interval for reindexing (e.g. 6h, 30m)")
+	flag.BoolVar(&follow, "follow", os.Getenv("INDEXER_FOLLOW") == "true", "reindex facilities as change events arrive instead of rebuilding on an interval")
+	flag.DurationVar(&followOpts.debounce, "debounce", 2*time.Second, "with -follow, how long changes are gathered before reindexing")
+	flag.DurationVar(&followOpts.reconcile, "reconcile", time.Hour, "with -follow, how often the whole index is compared with Postgres")
 	flag.Parse()
 
+	if reset || os.Getenv("RESET_TYPESENSE") == "true" {
+		log.Println("Ignoring reset: every run builds a new collection and swaps it in behind the alias")
+	}
+
+	if check {
+		drift, err := checkDrift(context.Background())
+		if err != nil {
+			log.Fatalf("Schema check failed: %v", err)
+		}
+		if len(drift) > 0 {
+			log.Fatalf("Live collection differs from schema version %d:\n  %s", typesense.FacilitiesSchemaVersion, strings.Join(drift, "\n  "))
+		}
+		log.Printf("Live collection matches schema version %d", typesense.FacilitiesSchemaVersion)
+		return
+	}
+
 	intervalValue := strings.TrimSpace(intervalFlag)
 	if intervalValue == "" {
 		intervalValue = strings.TrimSpace(os.Getenv("REINDEX_INTERVAL"))
@@ -46,8 +97,19 @@ func main() {
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
 
+	if follow {
+		if interval > 0 {
+			log.Println("Ignoring interval: -follow reindexes on change and reconciles every -reconcile")
+		}
+		if err := followChanges(ctx, opts, followOpts); err != nil {
+			log.Fatalf("Indexer failed: %v", err)
+		}
+		log.Println("Indexer shutting down")
+		return
+	}
+
 	for {
-		if err := indexOnce(ctx, reset); err != nil {
+		if err := indexOnce(ctx, opts); err != nil {
 			log.Printf("Reindex failed: %v", err)
 		}
 
@@ -55,7 +117,6 @@ func main() {
 			break
 		}
 
-		reset = false
 		log.Printf("Reindex complete. Next run in %s.", interval)
 
 		select {
@@ -67,7 +128,98 @@ func main() {
 	}
 }
 
-func indexOnce(ctx context.Context, reset bool) error {
+// checkDrift compares the live collection with the schema in code
+func checkDrift(ctx context.Context) ([]string, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	tsClient, err := typesense.NewClient(&cfg.Typesense)
+	if err != nil {
+		return nil, err
+	}
+	live, _, err := tsClient.LiveCollection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if live == "" {
+		return []string{"there is no live collection"}, nil
+	}
+	return tsClient.SchemaDrift(ctx, live)
+}
+
+// followChanges reindexes the facilities and procedures named in facility
+// events as they arrive and reconciles the whole index periodically. It
+// builds a collection first when there is none, and reconciles on startup to
+// pick up changes made while it was not running.
+func followChanges(ctx context.Context, opts options, followOpts followOptions) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	tsClient, err := typesense.NewClient(&cfg.Typesense)
+	if err != nil {
+		return err
+	}
+	live, _, err := tsClient.LiveCollection(ctx)
+	if err != nil {
+		return err
+	}
+	if live == "" {
+		if err := indexOnce(ctx, opts); err != nil {
+			return err
+		}
+	}
+
+	pgClient, err := postgres.NewClient(&cfg.Database)
+	if err != nil {
+		return err
+	}
+	defer pgClient.Close()
+
+	redisClient, err := redis.NewClient(&cfg.Redis)
+	if err != nil {
+		return err
+	}
+	defer redisClient.Close()
+	eventBus := events.NewRedisEventBus(redisClient)
+	defer eventBus.Close()
+
+	embedder := newDocumentEmbedder(cfg)
+	source := &documentSource{
+		facilities: database.NewFacilityAdapter(pgClient),
+		builder:    newDocumentBuilder(pgClient, embedder),
+	}
+	indexSync := services.NewSearchIndexSyncService(eventBus, source, tsClient)
+	indexSync.SetDebounce(followOpts.debounce)
+	procedures := newProcedureIndexer(pgClient, tsClient, embedder)
+	if err := procedures.ensureCollection(ctx, opts.keep); err != nil {
+		log.Printf("Warning: failed to prepare the procedures collection: %v", err)
+	}
+	indexSync.SetProcedureIndexer(procedures)
+	if err := indexSync.Start(ctx, followOpts.reconcile); err != nil {
+		return err
+	}
+	log.Printf("Following facility changes (debounce %s, reconcile every %s)", followOpts.debounce, followOpts.reconcile)
+
+	drift, err := indexSync.Reconcile(ctx)
+	if err != nil {
+		log.Printf("Startup reconciliation failed: %v", err)
+	} else {
+		log.Printf("Startup reconciliation: %d checked, %d missing, %d stale, %d orphaned, %d repaired",
+			drift.Checked, drift.Missing, drift.Stale, drift.Orphaned, drift.Repaired)
+	}
+
+	<-ctx.Done()
+	return nil
+}
+
+// indexOnce builds a new collection from Postgres while the current one keeps
+// serving, verifies it, swaps it in behind the alias and drops old versions.
+// A build that fails verification is dropped and the live collection is left
+// untouched.
+func indexOnce(ctx context.Context, opts options) error {
 	cfg, err := config.Load()
 	if err != nil {
 		return err
@@ -80,198 +232,432 @@ func indexOnce(ctx context.Context, reset bool) error {
 	defer pgClient.Close()
 
 	facilityRepo := database.NewFacilityAdapter(pgClient)
-	facilityProcedureRepo := database.NewFacilityProcedureAdapter(pgClient)
-	procedureCatalogRepo := database.NewProcedureAdapter(pgClient)
-	insuranceRepo := database.NewInsuranceAdapter(pgClient)
-	enrichmentRepo := database.NewProcedureEnrichmentAdapter(pgClient)
+	embedder := newDocumentEmbedder(cfg)
+	builder := newDocumentBuilder(pgClient, embedder)
 
 	tsClient, err := typesense.NewClient(&cfg.Typesense)
 	if err != nil {
 		return err
 	}
 
-	if reset || os.Getenv("RESET_TYPESENSE") == "true" {
-		log.Println("RESET_TYPESENSE=true detected, deleting facilities collection")
-		_, err := tsClient.Client().Collection(typesense.FacilitiesCollection).Delete(ctx)
-		if err != nil {
-			log.Printf("Warning: failed to delete collection: %v", err)
-		}
+	previous, _, err := tsClient.LiveCollection(ctx)
+	if err != nil {
+		return err
 	}
 
-	if err := tsClient.InitSchema(ctx); err != nil {
+	builder.loadProcedures(ctx)
+
+	buildStarted := time.Now()
+	facilities, err := listFacilities(ctx, facilityRepo)
+	if err != nil {
 		return err
 	}
 
-	facilities, err := facilityRepo.List(ctx, repositories.FacilityFilter{Limit: 1000})
+	collection, err := tsClient.CreateVersionedCollection(ctx)
 	if err != nil {
 		return err
 	}
+	log.Printf("Building %s (schema version %d) with %d facilities...", collection, typesense.FacilitiesSchemaVersion, len(facilities))
 
-	procedureByID := map[string]*entities.Procedure{}
-	procedures, err := procedureCatalogRepo.List(ctx, repositories.ProcedureFilter{})
+	if err := build(ctx, tsClient, builder, collection, facilities, previous, opts); err != nil {
+		if dropErr := tsClient.DropCollection(context.Background(), collection); dropErr != nil {
+			log.Printf("Warning: failed to drop abandoned collection %s: %v", collection, dropErr)
+		}
+		return fmt.Errorf("build of %s abandoned, %s is still live: %w", collection, previous, err)
+	}
+
+	if _, err := tsClient.SwapAlias(ctx, collection); err != nil {
+		return err
+	}
+	log.Printf("Alias %s now points at %s (was %q)", typesense.FacilitiesCollection, collection, previous)
+
+	// Facilities changed during the build were written to the old collection
+	catchUp(ctx, tsClient, builder, facilityRepo, buildStarted)
+
+	// Procedure documents carry price statistics, so they are rebuilt with
+	// the facilities. A failure leaves both new facilities and the previous
+	// procedures collection live.
+	procedures := newProcedureIndexer(pgClient, tsClient, embedder)
+	if err := procedures.rebuild(ctx, opts.keep); err != nil {
+		log.Printf("Warning: failed to rebuild procedures: %v", err)
+	}
+
+	dropped, err := tsClient.DropStaleCollections(ctx, opts.keep)
 	if err != nil {
-		log.Printf("Warning: failed to list procedures: %v", err)
-	} else {
-		for _, procedure := range procedures {
-			if procedure == nil {
-				continue
+		log.Printf("Warning: failed to drop old collections: %v", err)
+	}
+	if len(dropped) > 0 {
+		log.Printf("Dropped old collections: %s", strings.Join(dropped, ", "))
+	}
+
+	log.Println("Indexing complete.")
+	return nil
+}
+
+// build indexes every facility into collection and verifies the result
+func build(ctx context.Context, tsClient *typesense.Client, builder *documentBuilder, collection string, facilities []*entities.Facility, previous string, opts options) error {
+	expected, missing := 0, 0
+	samples := map[string]string{}
+	for start := 0; start < len(facilities); start += embeddingBatchSize {
+		batch := facilities[start:min(start+embeddingBatchSize, len(facilities))]
+		documents, batchMissing := builder.buildAll(ctx, batch)
+		missing += batchMissing
+
+		for j, f := range batch {
+			expected++
+			if err := tsClient.IndexFacilityInto(ctx, collection, documents[j]); err != nil {
+				return fmt.Errorf("failed to index facility %s: %w", f.ID, err)
+			}
+			log.Printf("Indexed %s", f.Name)
+
+			// Sample facilities spread across the list for smoke queries
+			if i := start + j; strings.TrimSpace(f.Name) != "" && len(samples) < smokeSamples && i%max(1, len(facilities)/smokeSamples) == 0 {
+				samples[f.ID] = f.Name
 			}
-			procedureByID[procedure.ID] = procedure
 		}
 	}
+	if missing > 0 {
+		log.Printf("Warning: %d of %d facilities were indexed without an embedding; semantic search cannot find them until the next run", missing, expected)
+	}
+
+	if err := tsClient.VerifyCollection(ctx, collection, expected, samples); err != nil {
+		return err
+	}
 
-	log.Printf("Indexing %d facilities...", len(facilities))
+	if previous != "" && !opts.allowShrink {
+		liveCount, err := tsClient.DocumentCount(ctx, previous)
+		if err != nil {
+			return err
+		}
+		if float64(expected) < float64(liveCount)*minShrinkRatio {
+			return fmt.Errorf("new collection holds %d documents, under half of the %d live ones (use -allow-shrink if intended)", expected, liveCount)
+		}
+	}
+	return nil
+}
 
+// catchUp reindexes facilities updated since the build read them
+func catchUp(ctx context.Context, tsClient *typesense.Client, builder *documentBuilder, facilityRepo repositories.FacilityRepository, since time.Time) {
+	facilities, err := listFacilities(ctx, facilityRepo)
+	if err != nil {
+		log.Printf("Warning: failed to list facilities for catch-up: %v", err)
+		return
+	}
+	var changed []*entities.Facility
 	for _, f := range facilities {
-		if f == nil {
-			continue
+		if !f.UpdatedAt.Before(since) {
+			changed = append(changed, f)
 		}
+	}
 
-		tagsBuilder := newTagBuilder(maxFacilityTags)
-		tagsBuilder.add(
-			f.Name,
-			f.FacilityType,
-			f.Address.City,
-			f.Address.State,
-			f.Address.Country,
-		)
-
-		var minPrice *float64
-		facilityProcedures, err := facilityProcedureRepo.ListByFacility(ctx, f.ID)
-		if err != nil {
-			log.Printf("Warning: failed to load procedures for %s: %v", f.ID, err)
-		} else {
-			for _, fp := range facilityProcedures {
-				if fp == nil {
-					continue
-				}
-				if fp.Price > 0 {
-					if minPrice == nil || fp.Price < *minPrice {
-						price := fp.Price
-						minPrice = &price
-					}
-				}
-
-				if procedure, ok := procedureByID[fp.ProcedureID]; ok && procedure != nil {
-					tagsBuilder.add(procedure.Name, procedure.Code, procedure.Category)
-				}
-			}
+	documents, missing := builder.buildAll(ctx, changed)
+	for i, f := range changed {
+		if err := tsClient.IndexFacility(ctx, documents[i]); err != nil {
+			log.Printf("Failed to catch up facility %s: %v", f.ID, err)
+			continue
 		}
+		log.Printf("Caught up %s", f.Name)
+	}
+	if missing > 0 {
+		log.Printf("Warning: %d caught-up facilities were indexed without an embedding", missing)
+	}
+}
 
-		insuranceProviders, err := insuranceRepo.GetFacilityInsurance(ctx, f.ID)
+// listFacilities reads every facility a page at a time
+func listFacilities(ctx context.Context, facilityRepo repositories.FacilityRepository) ([]*entities.Facility, error) {
+	var facilities []*entities.Facility
+	seen := map[string]bool{}
+	for offset := 0; ; offset += facilityPageSize {
+		page, err := facilityRepo.List(ctx, repositories.FacilityFilter{Limit: facilityPageSize, Offset: offset})
 		if err != nil {
-			log.Printf("Warning: failed to load insurance for %s: %v", f.ID, err)
+			return nil, err
 		}
-
-		insuranceNames := []string{}
-		for _, provider := range insuranceProviders {
-			if provider == nil {
+		for _, f := range page {
+			// A facility created mid-read shifts later pages by one
+			if f == nil || seen[f.ID] {
 				continue
 			}
-			insuranceNames = append(insuranceNames, provider.Name)
-			tagsBuilder.add(provider.Name, provider.Code)
-		}
-
-		// Collect procedure names for dedicated search field
-		procedureNames := []string{}
-		// We iterate facilityProcedures again or can do it in previous loop if refactored
-		// Since we already iterated above to find minPrice, let's refactor or just iterate again or collect in first loop
-		// Let's refactor the loop above to collect names.
-		// Actually, let's keep it simple and iterate again or use a map
-		uniqueProcNames := map[string]struct{}{}
-		for _, fp := range facilityProcedures {
-			if fp == nil {
-				continue
-			}
-			if procedure, ok := procedureByID[fp.ProcedureID]; ok && procedure != nil {
-				uniqueProcNames[procedure.Name] = struct{}{}
-			}
+			seen[f.ID] = true
+			facilities = append(facilities, f)
 		}
-		for name := range uniqueProcNames {
-			procedureNames = append(procedureNames, name)
+		if len(page) < facilityPageSize {
+			return facilities, nil
 		}
+	}
+}
 
-		uniqueProcIDs := make(map[string]struct{})
-		var enrichments []*entities.ProcedureEnrichment
-		for _, fp := range facilityProcedures {
-			if fp == nil {
-				continue
-			}
-			if _, seen := uniqueProcIDs[fp.ProcedureID]; seen {
-				continue
-			}
-			uniqueProcIDs[fp.ProcedureID] = struct{}{}
+// documentSource builds facility documents from Postgres for the search
+// index sync
+type documentSource struct {
+	facilities repositories.FacilityRepository
+	builder    *documentBuilder
+}
 
-			enrich, err := enrichmentRepo.GetByProcedureID(ctx, fp.ProcedureID)
-			if err == nil && enrich != nil {
-				enrichments = append(enrichments, enrich)
-			}
+// FacilityDocument builds one facility's document
+func (s *documentSource) FacilityDocument(ctx context.Context, facilityID string) (map[string]interface{}, error) {
+	f, err := s.facilities.GetByID(ctx, facilityID)
+	if err != nil {
+		return nil, err
+	}
+	return s.builder.build(ctx, f), nil
+}
+
+// FacilityDocuments builds every facility's document, reloading procedures
+// so renamed ones are picked up
+func (s *documentSource) FacilityDocuments(ctx context.Context) (map[string]map[string]interface{}, error) {
+	s.builder.loadProcedures(ctx)
+	facilities, err := listFacilities(ctx, s.facilities)
+	if err != nil {
+		return nil, err
+	}
+	built, missing := s.builder.buildAll(ctx, facilities)
+	if missing > 0 {
+		log.Printf("Warning: %d of %d facility documents were built without an embedding", missing, len(built))
+	}
+	documents := make(map[string]map[string]interface{}, len(facilities))
+	for i, f := range facilities {
+		documents[f.ID] = built[i]
+	}
+	return documents, nil
+}
+
+// documentBuilder turns a facility and its available procedures, insurance
+// and enrichments into a search document
+type documentBuilder struct {
+	procedures         repositories.ProcedureRepository
+	facilityProcedures repositories.FacilityProcedureRepository
+	insurance          repositories.InsuranceRepository
+	enrichments        repositories.ProcedureEnrichmentRepository
+	embedder           *documentEmbedder
+
+	mu            sync.Mutex
+	procedureByID map[string]*entities.Procedure
+}
+
+func newDocumentBuilder(pgClient *postgres.Client, embedder *documentEmbedder) *documentBuilder {
+	return &documentBuilder{
+		procedures:         database.NewProcedureAdapter(pgClient),
+		facilityProcedures: database.NewFacilityProcedureAdapter(pgClient),
+		insurance:          database.NewInsuranceAdapter(pgClient),
+		enrichments:        database.NewProcedureEnrichmentAdapter(pgClient),
+		embedder:           embedder,
+		procedureByID:      map[string]*entities.Procedure{},
+	}
+}
+
+// loadProcedures replaces the procedure cache with every procedure
+func (b *documentBuilder) loadProcedures(ctx context.Context) {
+	procedures, err := b.procedures.List(ctx, repositories.ProcedureFilter{})
+	if err != nil {
+		log.Printf("Warning: failed to list procedures: %v", err)
+		return
+	}
+	byID := make(map[string]*entities.Procedure, len(procedures))
+	for _, procedure := range procedures {
+		if procedure == nil {
+			continue
 		}
+		byID[procedure.ID] = procedure
+	}
 
-		conceptFields := search.BuildConceptFields(enrichments)
+	b.mu.Lock()
+	b.procedureByID = byID
+	b.mu.Unlock()
+}
 
-		// Manual expansion for common specialties to bridge lay terms and medical jargon
-		for _, s := range conceptFields.Specialties {
-			if s == "pediatrics" || s == "paediatrics" {
-				conceptFields.Concepts = append(conceptFields.Concepts, "baby", "child", "infant", "newborn", "paediatric")
-			}
-			if s == "obstetrics" || s == "gynecology" || s == "obstetrics_gynaecology" {
-				conceptFields.Concepts = append(conceptFields.Concepts, "pregnancy", "maternity", "antenatal", "prenatal", "baby delivery")
-			}
-			if s == "dental" || s == "dentistry" {
-				conceptFields.Concepts = append(conceptFields.Concepts, "tooth", "teeth", "dentist", "mouth", "gum")
+// procedure returns a cached procedure, reading ones created since the
+// cache was loaded
+func (b *documentBuilder) procedure(ctx context.Context, id string) *entities.Procedure {
+	b.mu.Lock()
+	procedure, ok := b.procedureByID[id]
+	b.mu.Unlock()
+	if ok {
+		return procedure
+	}
+
+	procedure, err := b.procedures.GetByID(ctx, id)
+	if err != nil {
+		return nil
+	}
+	b.mu.Lock()
+	b.procedureByID[id] = procedure
+	b.mu.Unlock()
+	return procedure
+}
+
+// build builds one facility's document
+func (b *documentBuilder) build(ctx context.Context, f *entities.Facility) map[string]interface{} {
+	documents, _ := b.buildAll(ctx, []*entities.Facility{f})
+	return documents[0]
+}
+
+// buildAll builds the facilities' documents, embedding them in batches, and
+// returns how many were left without an embedding
+func (b *documentBuilder) buildAll(ctx context.Context, facilities []*entities.Facility) ([]map[string]interface{}, int) {
+	documents := make([]map[string]interface{}, len(facilities))
+	texts := make([]string, len(facilities))
+	for i, f := range facilities {
+		documents[i], texts[i] = b.document(ctx, f)
+	}
+	return documents, b.embedder.embedDocuments(ctx, documents, texts)
+}
+
+// document builds a facility's document without its embedding, and the text
+// the embedding is built from
+func (b *documentBuilder) document(ctx context.Context, f *entities.Facility) (map[string]interface{}, string) {
+	tagsBuilder := newTagBuilder(maxFacilityTags)
+	tagsBuilder.add(
+		f.Name,
+		f.FacilityType,
+		f.Address.City,
+		f.Address.State,
+		f.Address.Country,
+	)
+
+	var minPrice *float64
+	listed, err := b.facilityProcedures.ListByFacility(ctx, f.ID)
+	if err != nil {
+		log.Printf("Warning: failed to load procedures for %s: %v", f.ID, err)
+	}
+	// Services switched off are not offered, so they neither match nor price
+	var facilityProcedures []*entities.FacilityProcedure
+	for _, fp := range listed {
+		if fp != nil && fp.IsAvailable {
+			facilityProcedures = append(facilityProcedures, fp)
+		}
+	}
+	for _, fp := range facilityProcedures {
+		if fp.Price > 0 {
+			if minPrice == nil || fp.Price < *minPrice {
+				price := fp.Price
+				minPrice = &price
 			}
 		}
 
-		doc := map[string]interface{}{
-			"id":            f.ID,
-			"name":          f.Name,
-			"facility_type": f.FacilityType,
-			"location":      []float64{f.Location.Latitude, f.Location.Longitude},
-			"rating":        f.Rating,
-			"review_count":  f.ReviewCount,
-			"is_active":     f.IsActive,
-			"created_at":    f.CreatedAt.Unix(),
+		if procedure := b.procedure(ctx, fp.ProcedureID); procedure != nil {
+			tagsBuilder.add(procedure.Name, procedure.Code, procedure.Category)
 		}
+	}
+
+	insuranceProviders, err := b.insurance.GetFacilityInsurance(ctx, f.ID)
+	if err != nil {
+		log.Printf("Warning: failed to load insurance for %s: %v", f.ID, err)
+	}
 
-		if minPrice != nil {
-			doc["price"] = *minPrice
+	insuranceNames := []string{}
+	for _, provider := range insuranceProviders {
+		if provider == nil {
+			continue
 		}
+		insuranceNames = append(insuranceNames, provider.Name)
+		tagsBuilder.add(provider.Name, provider.Code)
+	}
 
-		if len(insuranceNames) > 0 {
-			doc["insurance"] = insuranceNames
+	// Collect procedure names for dedicated search field
+	procedureNames := []string{}
+	// We iterate facilityProcedures again or can do it in previous loop if refactored
+	// Since we already iterated above to find minPrice, let's refactor or just iterate again or collect in first loop
+	// Let's refactor the loop above to collect names.
+	// Actually, let's keep it simple and iterate again or use a map
+	uniqueProcNames := map[string]struct{}{}
+	for _, fp := range facilityProcedures {
+		if procedure := b.procedure(ctx, fp.ProcedureID); procedure != nil {
+			uniqueProcNames[procedure.Name] = struct{}{}
 		}
+	}
+	for name := range uniqueProcNames {
+		procedureNames = append(procedureNames, name)
+	}
+	sort.Strings(procedureNames)
 
-		if len(procedureNames) > 0 {
-			doc["procedures"] = procedureNames
+	uniqueProcIDs := make(map[string]struct{})
+	var enrichments []*entities.ProcedureEnrichment
+	for _, fp := range facilityProcedures {
+		if _, seen := uniqueProcIDs[fp.ProcedureID]; seen {
+			continue
 		}
+		uniqueProcIDs[fp.ProcedureID] = struct{}{}
 
-		if len(conceptFields.Concepts) > 0 {
-			doc["concepts"] = conceptFields.Concepts
+		enrich, err := b.enrichments.GetByProcedureID(ctx, fp.ProcedureID)
+		if err == nil && enrich != nil {
+			enrichments = append(enrichments, enrich)
 		}
-		if len(conceptFields.Conditions) > 0 {
-			doc["conditions"] = conceptFields.Conditions
+	}
+
+	conceptFields := search.BuildConceptFields(enrichments)
+
+	// Manual expansion for common specialties to bridge lay terms and medical jargon
+	for _, s := range conceptFields.Specialties {
+		if s == "pediatrics" || s == "paediatrics" {
+			conceptFields.Concepts = append(conceptFields.Concepts, "baby", "child", "infant", "newborn", "paediatric")
 		}
-		if len(conceptFields.Symptoms) > 0 {
-			doc["symptoms"] = conceptFields.Symptoms
+		if s == "obstetrics" || s == "gynecology" || s == "obstetrics_gynaecology" {
+			conceptFields.Concepts = append(conceptFields.Concepts, "pregnancy", "maternity", "antenatal", "prenatal", "baby delivery")
 		}
-		if len(conceptFields.Specialties) > 0 {
-			doc["specialties"] = conceptFields.Specialties
+		if s == "dental" || s == "dentistry" {
+			conceptFields.Concepts = append(conceptFields.Concepts, "tooth", "teeth", "dentist", "mouth", "gum")
 		}
+	}
 
-		if tags := tagsBuilder.tags(); len(tags) > 0 {
-			doc["tags"] = tags
-		}
+	doc := map[string]interface{}{
+		"id":              f.ID,
+		"name":            f.Name,
+		"facility_type":   f.FacilityType,
+		"location":        []float64{f.Location.Latitude, f.Location.Longitude},
+		"rating":          f.Rating,
+		"review_count":    f.ReviewCount,
+		"is_active":       f.IsActive,
+		"created_at":      f.CreatedAt.Unix(),
+		"capacity_status": f.CurrentCapacityStatus(),
+		"open_24_hours":   f.OpenAroundTheClock(),
+	}
 
-		if err := tsClient.IndexFacility(ctx, doc); err != nil {
-			log.Printf("Failed to index facility %s: %v", f.ID, err)
-		} else {
-			log.Printf("Indexed %s", f.Name)
+	if minPrice != nil {
+		doc["price"] = *minPrice
+	}
+
+	if len(insuranceNames) > 0 {
+		doc["insurance"] = insuranceNames
+	}
+
+	if len(procedureNames) > 0 {
+		doc["procedures"] = procedureNames
+	}
+	if len(uniqueProcIDs) > 0 {
+		procedureIDs := make([]string, 0, len(uniqueProcIDs))
+		for id := range uniqueProcIDs {
+			procedureIDs = append(procedureIDs, id)
 		}
+		sort.Strings(procedureIDs)
+		doc["procedure_ids"] = procedureIDs
 	}
 
-	log.Println("Indexing complete.")
-	return nil
+	if len(conceptFields.Concepts) > 0 {
+		doc["concepts"] = conceptFields.Concepts
+	}
+	if len(conceptFields.Conditions) > 0 {
+		doc["conditions"] = conceptFields.Conditions
+	}
+	if len(conceptFields.Symptoms) > 0 {
+		doc["symptoms"] = conceptFields.Symptoms
+	}
+	if len(conceptFields.Specialties) > 0 {
+		doc["specialties"] = conceptFields.Specialties
+	}
+
+	if tags := tagsBuilder.tags(); len(tags) > 0 {
+		doc["tags"] = tags
+	}
+
+	text := search.EmbeddingText(
+		[]string{f.Name, f.FacilityType},
+		procedureNames,
+		conceptFields.Conditions,
+		conceptFields.Symptoms,
+		conceptFields.Specialties,
+		conceptFields.Concepts,
+	)
+	return doc, text
 }
 
 type tagBuilder struct {
@@ -0,0 +1,254 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/adapters/database"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/adapters/search"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/repositories"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/infrastructure/clients/postgres"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/infrastructure/clients/typesense"
+)
+
+// procedureIndexer builds the procedures collection from canonical
+// procedures, their enrichments and the prices facilities list for them
+type procedureIndexer struct {
+	procedures  repositories.ProcedureRepository
+	enrichments repositories.ProcedureEnrichmentRepository
+	prices      repositories.ProcedurePriceRepository
+	tsClient    *typesense.Client
+	embedder    *documentEmbedder
+}
+
+func newProcedureIndexer(pgClient *postgres.Client, tsClient *typesense.Client, embedder *documentEmbedder) *procedureIndexer {
+	return &procedureIndexer{
+		procedures:  database.NewProcedureAdapter(pgClient),
+		enrichments: database.NewProcedureEnrichmentAdapter(pgClient),
+		prices:      database.NewProcedurePriceAdapter(pgClient),
+		tsClient:    tsClient,
+		embedder:    embedder,
+	}
+}
+
+// ensureCollection creates the procedures collection behind its alias,
+// rebuilding it when its schema has drifted from the one in code
+func (p *procedureIndexer) ensureCollection(ctx context.Context, keep int) error {
+	drift, err := p.tsClient.EnsureProceduresCollection(ctx)
+	if err != nil {
+		return err
+	}
+	if len(drift) == 0 {
+		return nil
+	}
+	log.Printf("Procedures collection drifted from schema version %d, rebuilding: %v", typesense.ProceduresSchemaVersion, drift)
+	return p.rebuild(ctx, keep)
+}
+
+// rebuild indexes every procedure into a new collection while the live one
+// keeps serving, then swaps it in behind the alias and drops old versions. A
+// build that fails is dropped and the live collection is left untouched.
+func (p *procedureIndexer) rebuild(ctx context.Context, keep int) error {
+	procedures, err := p.procedures.List(ctx, repositories.ProcedureFilter{})
+	if err != nil {
+		return fmt.Errorf("failed to load procedures: %w", err)
+	}
+	stats, err := p.prices.PriceStats(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to load procedure prices: %w", err)
+	}
+
+	collection, err := p.tsClient.CreateVersionedProceduresCollection(ctx)
+	if err != nil {
+		return err
+	}
+	if err := p.build(ctx, collection, procedures, stats); err != nil {
+		if dropErr := p.tsClient.DropCollection(context.Background(), collection); dropErr != nil {
+			log.Printf("Warning: failed to drop abandoned collection %s: %v", collection, dropErr)
+		}
+		return fmt.Errorf("build of %s abandoned: %w", collection, err)
+	}
+
+	previous, err := p.tsClient.SwapProceduresAlias(ctx, collection)
+	if err != nil {
+		return err
+	}
+	log.Printf("Alias %s now points at %s (was %q)", typesense.ProceduresCollection, collection, previous)
+
+	dropped, err := p.tsClient.DropStaleProceduresCollections(ctx, keep)
+	if err != nil {
+		log.Printf("Warning: failed to drop old procedures collections: %v", err)
+	}
+	if len(dropped) > 0 {
+		log.Printf("Dropped old procedures collections: %s", strings.Join(dropped, ", "))
+	}
+	return nil
+}
+
+// build indexes procedures into collection and checks every one arrived
+func (p *procedureIndexer) build(ctx context.Context, collection string, procedures []*entities.Procedure, stats map[string]*entities.ProcedurePriceStats) error {
+	documents := p.documents(ctx, procedures, stats)
+	expected := len(documents)
+	for _, doc := range documents {
+		if err := p.tsClient.IndexProcedureInto(ctx, collection, doc); err != nil {
+			return fmt.Errorf("failed to index procedure %s: %w", doc["id"], err)
+		}
+	}
+
+	count, err := p.tsClient.DocumentCount(ctx, collection)
+	if err != nil {
+		return err
+	}
+	if count != int64(expected) {
+		return fmt.Errorf("collection %s has %d documents, expected %d", collection, count, expected)
+	}
+	log.Printf("Indexed %d procedures into %s", expected, collection)
+	return nil
+}
+
+// IndexProcedures reindexes the given procedures, or every procedure when
+// procedureIDs is empty, in which case documents of procedures that no
+// longer exist are removed
+func (p *procedureIndexer) IndexProcedures(ctx context.Context, procedureIDs []string) error {
+	var procedures []*entities.Procedure
+	var err error
+	if len(procedureIDs) == 0 {
+		procedures, err = p.procedures.List(ctx, repositories.ProcedureFilter{})
+	} else {
+		procedures, err = p.procedures.GetByIDs(ctx, procedureIDs)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load procedures: %w", err)
+	}
+
+	stats, err := p.prices.PriceStats(ctx, procedureIDs)
+	if err != nil {
+		return fmt.Errorf("failed to load procedure prices: %w", err)
+	}
+
+	var indexed map[string]map[string]interface{}
+	if len(procedureIDs) == 0 {
+		// Read before writing, so only documents of deleted procedures remain
+		if indexed, err = p.tsClient.ExportProcedures(ctx); err != nil {
+			return fmt.Errorf("failed to export procedure documents: %w", err)
+		}
+	}
+
+	var errs []error
+	written := 0
+	for _, doc := range p.documents(ctx, procedures, stats) {
+		id, _ := doc["id"].(string)
+		delete(indexed, id)
+		if err := p.tsClient.IndexProcedure(ctx, doc); err != nil {
+			errs = append(errs, fmt.Errorf("procedure %s: %w", id, err))
+			continue
+		}
+		written++
+	}
+	for id := range indexed {
+		if err := p.tsClient.DeleteProcedure(ctx, id); err != nil {
+			errs = append(errs, fmt.Errorf("procedure %s: %w", id, err))
+		}
+	}
+
+	log.Printf("Indexed %d procedures, removed %d", written, len(indexed))
+	return errors.Join(errs...)
+}
+
+// documents builds the procedures' documents, embedding them in batches
+func (p *procedureIndexer) documents(ctx context.Context, procedures []*entities.Procedure, stats map[string]*entities.ProcedurePriceStats) []map[string]interface{} {
+	documents := make([]map[string]interface{}, 0, len(procedures))
+	texts := make([]string, 0, len(procedures))
+	for _, procedure := range procedures {
+		if procedure == nil {
+			continue
+		}
+		doc, text := p.document(ctx, procedure, stats[procedure.ID])
+		documents = append(documents, doc)
+		texts = append(texts, text)
+	}
+	if missing := p.embedder.embedDocuments(ctx, documents, texts); missing > 0 {
+		log.Printf("Warning: %d of %d procedures were indexed without an embedding", missing, len(documents))
+	}
+	return documents
+}
+
+// document builds a procedure's document without its embedding, and the text
+// the embedding is built from
+func (p *procedureIndexer) document(ctx context.Context, procedure *entities.Procedure, stats *entities.ProcedurePriceStats) (map[string]interface{}, string) {
+	doc := map[string]interface{}{
+		"id":             procedure.ID,
+		"name":           procedure.Name,
+		"is_active":      procedure.IsActive,
+		"facility_count": 0,
+	}
+	if procedure.DisplayName != "" {
+		doc["display_name"] = procedure.DisplayName
+	}
+	if procedure.Code != "" {
+		doc["code"] = procedure.Code
+	}
+	if procedure.Category != "" {
+		doc["category"] = procedure.Category
+	}
+	if tags := normalizedSet(procedure.NormalizedTags); len(tags) > 0 {
+		doc["tags"] = tags
+	}
+
+	text := []string{procedure.Name, procedure.DisplayName, procedure.Category}
+	enrichment, err := p.enrichments.GetByProcedureID(ctx, procedure.ID)
+	if err == nil && enrichment != nil && enrichment.SearchConcepts != nil {
+		concepts := enrichment.SearchConcepts
+		text = append(text, concepts.LayTerms...)
+		text = append(text, concepts.Synonyms...)
+		text = append(text, concepts.Conditions...)
+		text = append(text, concepts.Symptoms...)
+		text = append(text, concepts.Specialties...)
+		for field, values := range map[string][]string{
+			"conditions":  concepts.Conditions,
+			"symptoms":    concepts.Symptoms,
+			"lay_terms":   concepts.LayTerms,
+			"synonyms":    concepts.Synonyms,
+			"specialties": concepts.Specialties,
+		} {
+			if set := normalizedSet(values); len(set) > 0 {
+				doc[field] = set
+			}
+		}
+	}
+
+	if stats != nil && stats.FacilityCount > 0 {
+		doc["facility_count"] = stats.FacilityCount
+		doc["min_price"] = stats.MinPrice
+		doc["max_price"] = stats.MaxPrice
+		doc["median_price"] = stats.MedianPrice
+		if stats.Currency != "" {
+			doc["currency"] = stats.Currency
+		}
+	}
+	return doc, search.EmbeddingText(text, procedure.NormalizedTags)
+}
+
+// normalizedSet lowercases, dedupes and sorts values
+func normalizedSet(values []string) []string {
+	seen := map[string]struct{}{}
+	set := []string{}
+	for _, value := range values {
+		normalized := normalizeTag(value)
+		if normalized == "" {
+			continue
+		}
+		if _, ok := seen[normalized]; ok {
+			continue
+		}
+		seen[normalized] = struct{}{}
+		set = append(set, normalized)
+	}
+	sort.Strings(set)
+	return set
+}
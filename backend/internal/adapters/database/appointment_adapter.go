@@ -12,6 +12,7 @@ import (
 	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/repositories"
 	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/infrastructure/clients/postgres"
 	apperrors "github.com/zatekoja/Patientpricediscoverydesign/backend/pkg/errors"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/pkg/utils"
 )
 
 // AppointmentAdapter implements the AppointmentRepository interface
@@ -48,6 +49,7 @@ func (a *AppointmentAdapter) Create(ctx context.Context, appointment *entities.A
 		"calendly_invitee_uri":    appointment.CalendlyInviteeURI,
 		"meeting_link":            appointment.MeetingLink,
 		"booking_method":          appointment.BookingMethod,
+		"slot_reservation_id":     appointment.SlotReservationID,
 		"created_at":              appointment.CreatedAt,
 		"updated_at":              appointment.UpdatedAt,
 	}
@@ -57,7 +59,7 @@ func (a *AppointmentAdapter) Create(ctx context.Context, appointment *entities.A
 		return apperrors.NewInternalError("failed to build insert query", err)
 	}
 
-	_, err = a.client.DB().ExecContext(ctx, query, args...)
+	_, err = a.client.Conn(ctx).ExecContext(ctx, query, args...)
 	if err != nil {
 		return apperrors.NewInternalError("failed to create appointment", err)
 	}
@@ -71,6 +73,7 @@ func (a *AppointmentAdapter) GetByID(ctx context.Context, id string) (*entities.
 		"id", "user_id", "facility_id", "procedure_id", "scheduled_at",
 		"status", "patient_name", "patient_email", "patient_phone",
 		"insurance_provider", "insurance_policy_number", "notes",
+		"booking_method", "calendly_event_uri", "slot_reservation_id",
 		"created_at", "updated_at",
 	).From("appointments").
 		Where(goqu.Ex{"id": id}).
@@ -83,8 +86,9 @@ func (a *AppointmentAdapter) GetByID(ctx context.Context, id string) (*entities.
 	appointment := &entities.Appointment{}
 	var userID sql.NullString
 	var patientPhone, insuranceProvider, insurancePolicyNumber, notes sql.NullString
+	var bookingMethod, calendlyEventURI, slotReservationID sql.NullString
 
-	err = a.client.DB().QueryRowContext(ctx, query, args...).Scan(
+	err = a.client.Conn(ctx).QueryRowContext(ctx, query, args...).Scan(
 		&appointment.ID,
 		&userID,
 		&appointment.FacilityID,
@@ -97,6 +101,9 @@ func (a *AppointmentAdapter) GetByID(ctx context.Context, id string) (*entities.
 		&insuranceProvider,
 		&insurancePolicyNumber,
 		&notes,
+		&bookingMethod,
+		&calendlyEventURI,
+		&slotReservationID,
 		&appointment.CreatedAt,
 		&appointment.UpdatedAt,
 	)
@@ -115,6 +122,13 @@ func (a *AppointmentAdapter) GetByID(ctx context.Context, id string) (*entities.
 	appointment.InsuranceProvider = insuranceProvider.String
 	appointment.InsurancePolicyNumber = insurancePolicyNumber.String
 	appointment.Notes = notes.String
+	appointment.BookingMethod = entities.BookingMethod(bookingMethod.String)
+	if calendlyEventURI.Valid {
+		appointment.CalendlyEventURI = &calendlyEventURI.String
+	}
+	if slotReservationID.Valid {
+		appointment.SlotReservationID = &slotReservationID.String
+	}
 
 	return appointment, nil
 }
@@ -144,7 +158,7 @@ func (a *AppointmentAdapter) Update(ctx context.Context, appointment *entities.A
 		return apperrors.NewInternalError("failed to build update query", err)
 	}
 
-	result, err := a.client.DB().ExecContext(ctx, query, args...)
+	result, err := a.client.Conn(ctx).ExecContext(ctx, query, args...)
 	if err != nil {
 		return apperrors.NewInternalError("failed to update appointment", err)
 	}
@@ -175,7 +189,7 @@ func (a *AppointmentAdapter) Cancel(ctx context.Context, id string) error {
 		return apperrors.NewInternalError("failed to build cancel query", err)
 	}
 
-	result, err := a.client.DB().ExecContext(ctx, query, args...)
+	result, err := a.client.Conn(ctx).ExecContext(ctx, query, args...)
 	if err != nil {
 		return apperrors.NewInternalError("failed to cancel appointment", err)
 	}
@@ -229,7 +243,7 @@ func (a *AppointmentAdapter) ListByUser(ctx context.Context, userID string, filt
 		return nil, apperrors.NewInternalError("failed to build list query", err)
 	}
 
-	rows, err := a.client.DB().QueryContext(ctx, query, args...)
+	rows, err := a.client.Conn(ctx).QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, apperrors.NewInternalError("failed to list appointments", err)
 	}
@@ -275,6 +289,32 @@ func (a *AppointmentAdapter) ListByUser(ctx context.Context, userID string, filt
 	return appointments, nil
 }
 
+// LinkToUserByPhone assigns unclaimed appointments to the user when the last ten
+// digits of the patient phone match, so 0803…, 234803… and +234 803… all link.
+func (a *AppointmentAdapter) LinkToUserByPhone(ctx context.Context, userID, phone string) (int, error) {
+	subscriber := utils.PhoneSubscriberDigits(phone)
+	if len(subscriber) < 10 {
+		return 0, nil
+	}
+
+	result, err := a.client.Conn(ctx).ExecContext(ctx, `
+		UPDATE appointments
+		SET user_id = $1, updated_at = NOW()
+		WHERE user_id IS NULL
+			AND patient_phone IS NOT NULL
+			AND RIGHT(regexp_replace(patient_phone, '\D', '', 'g'), 10) = $2
+	`, userID, subscriber)
+	if err != nil {
+		return 0, apperrors.NewInternalError("failed to link appointments", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, apperrors.NewInternalError("failed to get rows affected", err)
+	}
+	return int(rows), nil
+}
+
 // ListByFacility retrieves appointments for a facility
 func (a *AppointmentAdapter) ListByFacility(ctx context.Context, facilityID string, filter repositories.AppointmentFilter) ([]*entities.Appointment, error) {
 	ds := a.db.Select(
@@ -312,7 +352,7 @@ func (a *AppointmentAdapter) ListByFacility(ctx context.Context, facilityID stri
 		return nil, apperrors.NewInternalError("failed to build list query", err)
 	}
 
-	rows, err := a.client.DB().QueryContext(ctx, query, args...)
+	rows, err := a.client.Conn(ctx).QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, apperrors.NewInternalError("failed to list appointments", err)
 	}
@@ -0,0 +1,189 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/repositories"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/infrastructure/clients/postgres"
+	apperrors "github.com/zatekoja/Patientpricediscoverydesign/backend/pkg/errors"
+)
+
+// auditAppendLockKey serializes appends so each entry chains to the latest one
+const auditAppendLockKey = 7304210362
+
+var auditColumns = []interface{}{
+	"sequence", "id", "actor_id", "action", "entity_type", "entity_id", "facility_id",
+	"changes", "request_id", "ip_address", "created_at", "prev_hash", "hash",
+}
+
+// AuditAdapter implements the AuditRepository interface
+type AuditAdapter struct {
+	client *postgres.Client
+	db     *goqu.Database
+}
+
+// NewAuditAdapter creates a new audit adapter
+func NewAuditAdapter(client *postgres.Client) repositories.AuditRepository {
+	return &AuditAdapter{
+		client: client,
+		db:     goqu.New("postgres", client.DB()),
+	}
+}
+
+// Append chains the entry to the latest one and inserts it. Inside a caller's
+// transaction the chain stays locked until that transaction ends, so the entry
+// commits or rolls back with the change it records.
+func (a *AuditAdapter) Append(ctx context.Context, entry *entities.AuditEntry) error {
+	changes, err := json.Marshal(entry.Changes)
+	if err != nil {
+		return apperrors.NewInternalError("failed to encode audit changes", err)
+	}
+
+	var prevHash, hash string
+	err = a.client.InTx(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, auditAppendLockKey); err != nil {
+			return apperrors.NewInternalError("failed to lock audit log", err)
+		}
+
+		err := tx.QueryRowContext(ctx, `SELECT hash FROM audit_log ORDER BY sequence DESC LIMIT 1`).Scan(&prevHash)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return apperrors.NewInternalError("failed to read audit chain head", err)
+		}
+
+		hash, err = entry.ComputeHash(prevHash)
+		if err != nil {
+			return apperrors.NewInternalError("failed to hash audit entry", err)
+		}
+
+		err = tx.QueryRowContext(ctx, `
+			INSERT INTO audit_log
+			(id, actor_id, action, entity_type, entity_id, facility_id, changes, request_id, ip_address, created_at, prev_hash, hash)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+			RETURNING sequence
+		`,
+			entry.ID,
+			entry.ActorID,
+			entry.Action,
+			entry.EntityType,
+			entry.EntityID,
+			nullString(entry.FacilityID),
+			changes,
+			nullString(entry.RequestID),
+			nullString(entry.IPAddress),
+			entry.CreatedAt,
+			prevHash,
+			hash,
+		).Scan(&entry.Sequence)
+		if err != nil {
+			return apperrors.NewInternalError("failed to append audit entry", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	entry.PrevHash = prevHash
+	entry.Hash = hash
+	return nil
+}
+
+// List returns entries matching the filter, newest first
+func (a *AuditAdapter) List(ctx context.Context, filter repositories.AuditFilter) ([]*entities.AuditEntry, error) {
+	ds := a.db.Select(auditColumns...).From("audit_log")
+
+	if filter.FacilityID != "" {
+		ds = ds.Where(goqu.Ex{"facility_id": filter.FacilityID})
+	}
+	if filter.ActorID != "" {
+		ds = ds.Where(goqu.Ex{"actor_id": filter.ActorID})
+	}
+	if filter.EntityType != "" {
+		ds = ds.Where(goqu.Ex{"entity_type": filter.EntityType})
+	}
+	if filter.EntityID != "" {
+		ds = ds.Where(goqu.Ex{"entity_id": filter.EntityID})
+	}
+	if filter.From != nil {
+		ds = ds.Where(goqu.C("created_at").Gte(*filter.From))
+	}
+	if filter.To != nil {
+		ds = ds.Where(goqu.C("created_at").Lte(*filter.To))
+	}
+
+	ds = ds.Order(goqu.I("sequence").Desc())
+	if filter.Limit > 0 {
+		ds = ds.Limit(uint(filter.Limit))
+	}
+	if filter.Offset > 0 {
+		ds = ds.Offset(uint(filter.Offset))
+	}
+
+	query, args, err := ds.ToSQL()
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to build list query", err)
+	}
+	return a.query(ctx, query, args...)
+}
+
+// ListAfter returns entries after the given sequence in chain order
+func (a *AuditAdapter) ListAfter(ctx context.Context, afterSequence int64, limit int) ([]*entities.AuditEntry, error) {
+	query, args, err := a.db.Select(auditColumns...).From("audit_log").
+		Where(goqu.C("sequence").Gt(afterSequence)).
+		Order(goqu.I("sequence").Asc()).
+		Limit(uint(limit)).
+		ToSQL()
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to build list query", err)
+	}
+	return a.query(ctx, query, args...)
+}
+
+func (a *AuditAdapter) query(ctx context.Context, query string, args ...interface{}) ([]*entities.AuditEntry, error) {
+	rows, err := a.client.DB().QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to list audit entries", err)
+	}
+	defer rows.Close()
+
+	var entries []*entities.AuditEntry
+	for rows.Next() {
+		entry := &entities.AuditEntry{}
+		var facilityID, requestID, ipAddress sql.NullString
+		var changes []byte
+		if err := rows.Scan(
+			&entry.Sequence,
+			&entry.ID,
+			&entry.ActorID,
+			&entry.Action,
+			&entry.EntityType,
+			&entry.EntityID,
+			&facilityID,
+			&changes,
+			&requestID,
+			&ipAddress,
+			&entry.CreatedAt,
+			&entry.PrevHash,
+			&entry.Hash,
+		); err != nil {
+			return nil, apperrors.NewInternalError("failed to scan audit entry", err)
+		}
+		entry.FacilityID = facilityID.String
+		entry.RequestID = requestID.String
+		entry.IPAddress = ipAddress.String
+		if len(changes) > 0 {
+			if err := json.Unmarshal(changes, &entry.Changes); err != nil {
+				return nil, apperrors.NewInternalError("failed to decode audit changes", err)
+			}
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, apperrors.NewInternalError("error iterating audit entries", err)
+	}
+	return entries, nil
+}
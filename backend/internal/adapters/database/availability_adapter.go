@@ -0,0 +1,238 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/repositories"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/infrastructure/clients/postgres"
+	apperrors "github.com/zatekoja/Patientpricediscoverydesign/backend/pkg/errors"
+)
+
+// availabilitySlotSelect selects slots with their booked and unexpired held reservation counts.
+const availabilitySlotSelect = `
+	SELECT s.id, s.facility_id, s.ward_id, s.template_id, s.start_time, s.end_time,
+		COALESCE(s.is_booked, false), s.capacity, s.created_at, s.updated_at,
+		COUNT(r.id) FILTER (WHERE r.status = 'booked'),
+		COUNT(r.id) FILTER (WHERE r.status = 'held' AND r.expires_at > NOW())
+	FROM availability_slots s
+	LEFT JOIN slot_reservations r ON r.slot_id = s.id
+`
+
+// AvailabilityAdapter implements the AvailabilityRepository interface
+type AvailabilityAdapter struct {
+	client *postgres.Client
+}
+
+// NewAvailabilityAdapter creates a new availability adapter
+func NewAvailabilityAdapter(client *postgres.Client) repositories.AvailabilityRepository {
+	return &AvailabilityAdapter{client: client}
+}
+
+// Create creates a new availability slot
+func (a *AvailabilityAdapter) Create(ctx context.Context, slot *entities.AvailabilitySlot) error {
+	if slot.ID == "" {
+		slot.ID = uuid.New().String()
+	}
+	if slot.Capacity <= 0 {
+		slot.Capacity = 1
+	}
+	now := time.Now().UTC()
+	slot.CreatedAt = now
+	slot.UpdatedAt = now
+
+	query := `
+		INSERT INTO availability_slots
+		(id, facility_id, ward_id, template_id, start_time, end_time, is_booked, capacity, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+
+	_, err := a.client.DB().ExecContext(ctx, query,
+		slot.ID,
+		slot.FacilityID,
+		slot.WardID,
+		slot.TemplateID,
+		slot.StartTime.UTC(),
+		slot.EndTime.UTC(),
+		slot.IsBooked,
+		slot.Capacity,
+		slot.CreatedAt,
+		slot.UpdatedAt,
+	)
+	if err != nil {
+		return apperrors.NewInternalError("failed to create availability slot", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves an availability slot by ID
+func (a *AvailabilityAdapter) GetByID(ctx context.Context, id string) (*entities.AvailabilitySlot, error) {
+	query := availabilitySlotSelect + ` WHERE s.id = $1 GROUP BY s.id`
+
+	slot, err := scanAvailabilitySlot(a.client.DB().QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, apperrors.NewNotFoundError(fmt.Sprintf("availability slot with id %s not found", id))
+	}
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to get availability slot", err)
+	}
+
+	return slot, nil
+}
+
+// ListByFacility retrieves availability slots for a facility that start in [from, to)
+func (a *AvailabilityAdapter) ListByFacility(ctx context.Context, facilityID string, from, to time.Time) ([]*entities.AvailabilitySlot, error) {
+	query := availabilitySlotSelect + `
+		WHERE s.facility_id = $1 AND s.start_time >= $2 AND s.start_time < $3
+		GROUP BY s.id
+		ORDER BY s.start_time, s.ward_id
+	`
+
+	rows, err := a.client.DB().QueryContext(ctx, query, facilityID, from.UTC(), to.UTC())
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to list availability slots", err)
+	}
+	defer rows.Close()
+
+	var slots []*entities.AvailabilitySlot
+	for rows.Next() {
+		slot, err := scanAvailabilitySlot(rows)
+		if err != nil {
+			return nil, apperrors.NewInternalError("failed to scan availability slot", err)
+		}
+		slots = append(slots, slot)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, apperrors.NewInternalError("failed to iterate availability slots", err)
+	}
+
+	return slots, nil
+}
+
+// Update updates an availability slot
+func (a *AvailabilityAdapter) Update(ctx context.Context, slot *entities.AvailabilitySlot) error {
+	slot.UpdatedAt = time.Now().UTC()
+
+	query := `
+		UPDATE availability_slots
+		SET start_time = $2, end_time = $3, is_booked = $4, capacity = $5, updated_at = $6
+		WHERE id = $1
+	`
+
+	result, err := a.client.DB().ExecContext(ctx, query,
+		slot.ID,
+		slot.StartTime.UTC(),
+		slot.EndTime.UTC(),
+		slot.IsBooked,
+		slot.Capacity,
+		slot.UpdatedAt,
+	)
+	if err != nil {
+		return apperrors.NewInternalError("failed to update availability slot", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return apperrors.NewInternalError("failed to get affected rows", err)
+	}
+	if rows == 0 {
+		return apperrors.NewNotFoundError(fmt.Sprintf("availability slot with id %s not found", slot.ID))
+	}
+
+	return nil
+}
+
+// Delete deletes an availability slot
+func (a *AvailabilityAdapter) Delete(ctx context.Context, id string) error {
+	result, err := a.client.DB().ExecContext(ctx, `DELETE FROM availability_slots WHERE id = $1`, id)
+	if err != nil {
+		return apperrors.NewInternalError("failed to delete availability slot", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return apperrors.NewInternalError("failed to get affected rows", err)
+	}
+	if rows == 0 {
+		return apperrors.NewNotFoundError(fmt.Sprintf("availability slot with id %s not found", id))
+	}
+
+	return nil
+}
+
+// EnsureSlots inserts slots that do not exist yet in a single statement
+func (a *AvailabilityAdapter) EnsureSlots(ctx context.Context, slots []*entities.AvailabilitySlot) error {
+	if len(slots) == 0 {
+		return nil
+	}
+
+	const columns = 8
+	now := time.Now().UTC()
+	placeholders := make([]string, 0, len(slots))
+	args := make([]interface{}, 0, len(slots)*columns)
+	for i, slot := range slots {
+		base := i * columns
+		// created_at and updated_at share the same parameter
+		placeholders = append(placeholders, fmt.Sprintf("($%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d)",
+			base+1, base+2, base+3, base+4, base+5, base+6, base+7, base+8, base+8))
+		args = append(args,
+			slot.ID,
+			slot.FacilityID,
+			slot.WardID,
+			slot.TemplateID,
+			slot.StartTime.UTC(),
+			slot.EndTime.UTC(),
+			slot.Capacity,
+			now,
+		)
+	}
+
+	query := `
+		INSERT INTO availability_slots
+		(id, facility_id, ward_id, template_id, start_time, end_time, capacity, created_at, updated_at)
+		VALUES ` + strings.Join(placeholders, ", ") + `
+		ON CONFLICT (id) DO NOTHING
+	`
+
+	if _, err := a.client.DB().ExecContext(ctx, query, args...); err != nil {
+		return apperrors.NewInternalError("failed to materialize availability slots", err)
+	}
+
+	return nil
+}
+
+func scanAvailabilitySlot(row rowScanner) (*entities.AvailabilitySlot, error) {
+	slot := &entities.AvailabilitySlot{}
+	var wardID, templateID sql.NullString
+	err := row.Scan(
+		&slot.ID,
+		&slot.FacilityID,
+		&wardID,
+		&templateID,
+		&slot.StartTime,
+		&slot.EndTime,
+		&slot.IsBooked,
+		&slot.Capacity,
+		&slot.CreatedAt,
+		&slot.UpdatedAt,
+		&slot.Booked,
+		&slot.Held,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if wardID.Valid {
+		slot.WardID = &wardID.String
+	}
+	if templateID.Valid {
+		slot.TemplateID = &templateID.String
+	}
+	slot.IsBooked = slot.IsBooked || slot.Remaining() == 0
+	return slot, nil
+}
@@ -3,12 +3,14 @@ package database
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 
 	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
 	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/providers"
 	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/repositories"
+	apperrors "github.com/zatekoja/Patientpricediscoverydesign/backend/pkg/errors"
 )
 
 // CachedFacilityAdapter wraps FacilityAdapter with caching
@@ -209,19 +211,32 @@ func (a *CachedFacilityAdapter) Update(ctx context.Context, facility *entities.F
 	// Update in database
 	err := a.adapter.Update(ctx, facility)
 	if err != nil {
+		var appErr *apperrors.AppError
+		if errors.As(err, &appErr) && appErr.Type == apperrors.ErrorTypeConflict {
+			// The caller's version may have come from this cache
+			a.InvalidateFacility(ctx, facility.ID)
+		}
 		return err
 	}
 
-	// Invalidate caches asynchronously
+	a.InvalidateFacility(ctx, facility.ID)
+	return nil
+}
+
+// InvalidateFacility drops the cached facility, for writes that reach the
+// database without going through Update. The facility is dropped before
+// returning so the writer's next read sees its new version; the list and
+// search caches are cleared asynchronously.
+func (a *CachedFacilityAdapter) InvalidateFacility(ctx context.Context, id string) {
+	// Delete specific facility cache
+	cacheKey := facilityCacheKey(id)
+	if err := a.cache.Delete(ctx, cacheKey); err != nil {
+		log.Printf("Failed to invalidate facility cache %s: %v", id, err)
+	}
+
 	go func() {
 		bgCtx := context.Background()
 
-		// Delete specific facility cache
-		cacheKey := facilityCacheKey(facility.ID)
-		if err := a.cache.Delete(bgCtx, cacheKey); err != nil {
-			log.Printf("Failed to invalidate facility cache %s: %v", facility.ID, err)
-		}
-
 		// Delete list and search caches
 		if err := a.cache.DeletePattern(bgCtx, "facilities:list:*"); err != nil {
 			log.Printf("Failed to invalidate facilities list cache: %v", err)
@@ -230,8 +245,6 @@ func (a *CachedFacilityAdapter) Update(ctx context.Context, facility *entities.F
 			log.Printf("Failed to invalidate facilities search cache: %v", err)
 		}
 	}()
-
-	return nil
 }
 
 // Delete deletes a facility and invalidates its cache
@@ -0,0 +1,254 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/repositories"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/infrastructure/clients/postgres"
+	apperrors "github.com/zatekoja/Patientpricediscoverydesign/backend/pkg/errors"
+)
+
+// CalendarAdapter persists native facility calendars in Postgres.
+type CalendarAdapter struct {
+	client *postgres.Client
+}
+
+// NewCalendarAdapter creates a new calendar adapter.
+func NewCalendarAdapter(client *postgres.Client) repositories.CalendarRepository {
+	return &CalendarAdapter{client: client}
+}
+
+// GetCalendar retrieves a facility's calendar settings.
+func (a *CalendarAdapter) GetCalendar(ctx context.Context, facilityID string) (*entities.FacilityCalendar, error) {
+	query := `
+		SELECT facility_id, enabled, timezone, hold_minutes, created_at, updated_at
+		FROM facility_calendars
+		WHERE facility_id = $1
+	`
+
+	calendar := &entities.FacilityCalendar{}
+	err := a.client.Conn(ctx).QueryRowContext(ctx, query, facilityID).Scan(
+		&calendar.FacilityID,
+		&calendar.Enabled,
+		&calendar.Timezone,
+		&calendar.HoldMinutes,
+		&calendar.CreatedAt,
+		&calendar.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, apperrors.NewNotFoundError(fmt.Sprintf("calendar for facility %s not found", facilityID))
+	}
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to get calendar", err)
+	}
+
+	return calendar, nil
+}
+
+// UpsertCalendar creates or replaces a facility's calendar settings.
+func (a *CalendarAdapter) UpsertCalendar(ctx context.Context, calendar *entities.FacilityCalendar) error {
+	now := time.Now().UTC()
+	calendar.UpdatedAt = now
+
+	query := `
+		INSERT INTO facility_calendars (facility_id, enabled, timezone, hold_minutes, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $5)
+		ON CONFLICT (facility_id) DO UPDATE
+		SET enabled = EXCLUDED.enabled,
+			timezone = EXCLUDED.timezone,
+			hold_minutes = EXCLUDED.hold_minutes,
+			updated_at = EXCLUDED.updated_at
+		RETURNING created_at
+	`
+
+	err := a.client.Conn(ctx).QueryRowContext(ctx, query,
+		calendar.FacilityID,
+		calendar.Enabled,
+		calendar.Timezone,
+		calendar.HoldMinutes,
+		now,
+	).Scan(&calendar.CreatedAt)
+	if err != nil {
+		return apperrors.NewInternalError("failed to save calendar", err)
+	}
+
+	return nil
+}
+
+// ListTemplates retrieves all weekly slot templates for a facility.
+func (a *CalendarAdapter) ListTemplates(ctx context.Context, facilityID string) ([]*entities.SlotTemplate, error) {
+	query := `
+		SELECT id, facility_id, ward_id, weekday, start_time, end_time, slot_minutes, capacity,
+			valid_from, valid_until, created_at, updated_at
+		FROM calendar_slot_templates
+		WHERE facility_id = $1
+		ORDER BY weekday, start_time, ward_id
+	`
+
+	rows, err := a.client.Conn(ctx).QueryContext(ctx, query, facilityID)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to list slot templates", err)
+	}
+	defer rows.Close()
+
+	var templates []*entities.SlotTemplate
+	for rows.Next() {
+		t := &entities.SlotTemplate{}
+		var wardID sql.NullString
+		var weekday int
+		var validFrom, validUntil sql.NullTime
+		if err := rows.Scan(&t.ID, &t.FacilityID, &wardID, &weekday, &t.StartTime, &t.EndTime,
+			&t.SlotMinutes, &t.Capacity, &validFrom, &validUntil, &t.CreatedAt, &t.UpdatedAt); err != nil {
+			return nil, apperrors.NewInternalError("failed to scan slot template", err)
+		}
+		t.Weekday = time.Weekday(weekday)
+		if wardID.Valid {
+			t.WardID = &wardID.String
+		}
+		if validFrom.Valid {
+			t.ValidFrom = &validFrom.Time
+		}
+		if validUntil.Valid {
+			t.ValidUntil = &validUntil.Time
+		}
+		templates = append(templates, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, apperrors.NewInternalError("failed to iterate slot templates", err)
+	}
+
+	return templates, nil
+}
+
+// CreateTemplate creates a weekly slot template.
+func (a *CalendarAdapter) CreateTemplate(ctx context.Context, template *entities.SlotTemplate) error {
+	if template.ID == "" {
+		template.ID = uuid.New().String()
+	}
+	now := time.Now().UTC()
+	template.CreatedAt = now
+	template.UpdatedAt = now
+
+	query := `
+		INSERT INTO calendar_slot_templates
+		(id, facility_id, ward_id, weekday, start_time, end_time, slot_minutes, capacity, valid_from, valid_until, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	`
+
+	_, err := a.client.Conn(ctx).ExecContext(ctx, query,
+		template.ID,
+		template.FacilityID,
+		template.WardID,
+		int(template.Weekday),
+		template.StartTime,
+		template.EndTime,
+		template.SlotMinutes,
+		template.Capacity,
+		template.ValidFrom,
+		template.ValidUntil,
+		template.CreatedAt,
+		template.UpdatedAt,
+	)
+	if err != nil {
+		return apperrors.NewInternalError("failed to create slot template", err)
+	}
+
+	return nil
+}
+
+// DeleteTemplate deletes a facility's slot template. Slots already generated
+// from it lose their template reference and are no longer offered.
+func (a *CalendarAdapter) DeleteTemplate(ctx context.Context, facilityID, id string) error {
+	return a.deleteForFacility(ctx, "calendar_slot_templates", "slot template", facilityID, id)
+}
+
+// ListBlackouts retrieves blackouts for a facility that overlap [from, to).
+func (a *CalendarAdapter) ListBlackouts(ctx context.Context, facilityID string, from, to time.Time) ([]*entities.CalendarBlackout, error) {
+	query := `
+		SELECT id, facility_id, ward_id, starts_at, ends_at, reason, created_at
+		FROM calendar_blackouts
+		WHERE facility_id = $1 AND starts_at < $3 AND ends_at > $2
+		ORDER BY starts_at
+	`
+
+	rows, err := a.client.Conn(ctx).QueryContext(ctx, query, facilityID, from, to)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to list blackouts", err)
+	}
+	defer rows.Close()
+
+	var blackouts []*entities.CalendarBlackout
+	for rows.Next() {
+		b := &entities.CalendarBlackout{}
+		var wardID, reason sql.NullString
+		if err := rows.Scan(&b.ID, &b.FacilityID, &wardID, &b.StartsAt, &b.EndsAt, &reason, &b.CreatedAt); err != nil {
+			return nil, apperrors.NewInternalError("failed to scan blackout", err)
+		}
+		if wardID.Valid {
+			b.WardID = &wardID.String
+		}
+		b.Reason = reason.String
+		blackouts = append(blackouts, b)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, apperrors.NewInternalError("failed to iterate blackouts", err)
+	}
+
+	return blackouts, nil
+}
+
+// CreateBlackout creates a blackout period.
+func (a *CalendarAdapter) CreateBlackout(ctx context.Context, blackout *entities.CalendarBlackout) error {
+	if blackout.ID == "" {
+		blackout.ID = uuid.New().String()
+	}
+	blackout.CreatedAt = time.Now().UTC()
+
+	query := `
+		INSERT INTO calendar_blackouts (id, facility_id, ward_id, starts_at, ends_at, reason, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	_, err := a.client.Conn(ctx).ExecContext(ctx, query,
+		blackout.ID,
+		blackout.FacilityID,
+		blackout.WardID,
+		blackout.StartsAt,
+		blackout.EndsAt,
+		sql.NullString{String: blackout.Reason, Valid: blackout.Reason != ""},
+		blackout.CreatedAt,
+	)
+	if err != nil {
+		return apperrors.NewInternalError("failed to create blackout", err)
+	}
+
+	return nil
+}
+
+// DeleteBlackout deletes a facility's blackout period.
+func (a *CalendarAdapter) DeleteBlackout(ctx context.Context, facilityID, id string) error {
+	return a.deleteForFacility(ctx, "calendar_blackouts", "blackout", facilityID, id)
+}
+
+func (a *CalendarAdapter) deleteForFacility(ctx context.Context, table, kind, facilityID, id string) error {
+	result, err := a.client.Conn(ctx).ExecContext(ctx,
+		`DELETE FROM `+table+` WHERE id = $1 AND facility_id = $2`, id, facilityID)
+	if err != nil {
+		return apperrors.NewInternalError(fmt.Sprintf("failed to delete %s", kind), err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return apperrors.NewInternalError("failed to get affected rows", err)
+	}
+	if rows == 0 {
+		return apperrors.NewNotFoundError(fmt.Sprintf("%s with id %s not found", kind, id))
+	}
+
+	return nil
+}
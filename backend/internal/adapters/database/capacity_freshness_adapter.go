@@ -0,0 +1,100 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/repositories"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/infrastructure/clients/postgres"
+	apperrors "github.com/zatekoja/Patientpricediscoverydesign/backend/pkg/errors"
+)
+
+// Values without a report time are always due. Ward values share the ward's
+// last_updated.
+const facilitiesReportedBeforeQuery = `
+	SELECT f.id FROM facilities f
+	WHERE f.is_active = true AND f.id > $4 AND (
+		(f.capacity_status IS NOT NULL AND f.capacity_status NOT IN ('', 'unknown')
+			AND (f.capacity_status_reported_at IS NULL OR f.capacity_status_reported_at < $1))
+		OR (f.avg_wait_minutes IS NOT NULL AND (f.avg_wait_reported_at IS NULL OR f.avg_wait_reported_at < $2))
+		OR (f.urgent_care_available IS NOT NULL AND (f.urgent_care_reported_at IS NULL OR f.urgent_care_reported_at < $3))
+		OR EXISTS (
+			SELECT 1 FROM facility_wards w
+			WHERE w.facility_id = f.id AND (
+				(w.capacity_status IS NOT NULL AND w.capacity_status NOT IN ('', 'unknown') AND w.last_updated < $1)
+				OR (w.avg_wait_minutes IS NOT NULL AND w.last_updated < $2)
+				OR (w.urgent_care_available IS NOT NULL AND w.last_updated < $3)
+			)
+		)
+	)
+	ORDER BY f.id
+	LIMIT $5
+`
+
+// CapacityFreshnessAdapter implements the CapacityFreshnessRepository interface
+type CapacityFreshnessAdapter struct {
+	client *postgres.Client
+}
+
+// NewCapacityFreshnessAdapter creates a new capacity freshness adapter
+func NewCapacityFreshnessAdapter(client *postgres.Client) repositories.CapacityFreshnessRepository {
+	return &CapacityFreshnessAdapter{client: client}
+}
+
+// ListFacilitiesReportedBefore returns facilities with capacity values reported before their cutoffs
+func (a *CapacityFreshnessAdapter) ListFacilitiesReportedBefore(ctx context.Context, cutoffs repositories.CapacityCutoffs, afterID string, limit int) ([]string, error) {
+	rows, err := a.client.DB().QueryContext(ctx, facilitiesReportedBeforeQuery,
+		cutoffs[entities.CapacityFieldStatus],
+		cutoffs[entities.CapacityFieldWaitTime],
+		cutoffs[entities.CapacityFieldUrgentCare],
+		afterID,
+		limit,
+	)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to list facilities with expiring capacity", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, apperrors.NewInternalError("failed to scan facility id", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, apperrors.NewInternalError("error iterating facilities", err)
+	}
+	return ids, nil
+}
+
+// LastNudgedAt returns when the facility's operator was last reminded
+func (a *CapacityFreshnessAdapter) LastNudgedAt(ctx context.Context, facilityID string) (*time.Time, error) {
+	var nudgedAt time.Time
+	err := a.client.DB().QueryRowContext(ctx,
+		`SELECT nudged_at FROM facility_capacity_nudges WHERE facility_id = $1`, facilityID,
+	).Scan(&nudgedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to get capacity nudge", err)
+	}
+	return &nudgedAt, nil
+}
+
+// RecordNudge records that the facility's operator was reminded
+func (a *CapacityFreshnessAdapter) RecordNudge(ctx context.Context, facilityID string, at time.Time) error {
+	_, err := a.client.DB().ExecContext(ctx, `
+		INSERT INTO facility_capacity_nudges (facility_id, nudged_at) VALUES ($1, $2)
+		ON CONFLICT (facility_id) DO UPDATE SET nudged_at = EXCLUDED.nudged_at
+	`, facilityID, at)
+	if err != nil {
+		return apperrors.NewInternalError("failed to record capacity nudge", err)
+	}
+	return nil
+}
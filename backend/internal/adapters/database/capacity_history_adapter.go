@@ -0,0 +1,221 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/repositories"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/infrastructure/clients/postgres"
+	apperrors "github.com/zatekoja/Patientpricediscoverydesign/backend/pkg/errors"
+)
+
+// CapacityHistoryAdapter implements the CapacityHistoryRepository interface
+type CapacityHistoryAdapter struct {
+	client *postgres.Client
+}
+
+// NewCapacityHistoryAdapter creates a new capacity history adapter
+func NewCapacityHistoryAdapter(client *postgres.Client) repositories.CapacityHistoryRepository {
+	return &CapacityHistoryAdapter{client: client}
+}
+
+// Record appends a capacity report
+func (a *CapacityHistoryAdapter) Record(ctx context.Context, observation *entities.CapacityObservation) error {
+	var status sql.NullString
+	if observation.CapacityStatus != nil {
+		status = sql.NullString{String: *observation.CapacityStatus, Valid: true}
+	}
+	var wait sql.NullInt64
+	if observation.AvgWaitMinutes != nil {
+		wait = sql.NullInt64{Int64: int64(*observation.AvgWaitMinutes), Valid: true}
+	}
+
+	err := a.client.DB().QueryRowContext(ctx, `
+		INSERT INTO capacity_observations (facility_id, ward_name, capacity_status, avg_wait_minutes, observed_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`, observation.FacilityID, observation.WardName, status, wait, observation.ObservedAt).Scan(&observation.ID)
+	if err != nil {
+		return apperrors.NewInternalError("failed to record capacity observation", err)
+	}
+	return nil
+}
+
+// ListObservedFacilityIDs returns the facilities with reports observed at or after since
+func (a *CapacityHistoryAdapter) ListObservedFacilityIDs(ctx context.Context, since time.Time) ([]string, error) {
+	rows, err := a.client.DB().QueryContext(ctx,
+		`SELECT DISTINCT facility_id FROM capacity_observations WHERE observed_at >= $1 ORDER BY facility_id`, since)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to list observed facilities", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, apperrors.NewInternalError("failed to scan facility id", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, apperrors.NewInternalError("error iterating observed facilities", err)
+	}
+	return ids, nil
+}
+
+// ListObservations returns a facility's reports observed at or after since
+func (a *CapacityHistoryAdapter) ListObservations(ctx context.Context, facilityID string, since time.Time) ([]*entities.CapacityObservation, error) {
+	rows, err := a.client.DB().QueryContext(ctx, `
+		SELECT id, facility_id, ward_name, capacity_status, avg_wait_minutes, observed_at
+		FROM capacity_observations
+		WHERE facility_id = $1 AND observed_at >= $2
+		ORDER BY ward_name, observed_at, id
+	`, facilityID, since)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to list capacity observations", err)
+	}
+	defer rows.Close()
+
+	var observations []*entities.CapacityObservation
+	for rows.Next() {
+		observation := &entities.CapacityObservation{}
+		var status sql.NullString
+		var wait sql.NullInt64
+		if err := rows.Scan(
+			&observation.ID,
+			&observation.FacilityID,
+			&observation.WardName,
+			&status,
+			&wait,
+			&observation.ObservedAt,
+		); err != nil {
+			return nil, apperrors.NewInternalError("failed to scan capacity observation", err)
+		}
+		if status.Valid {
+			observation.CapacityStatus = &status.String
+		}
+		if wait.Valid {
+			minutes := int(wait.Int64)
+			observation.AvgWaitMinutes = &minutes
+		}
+		observations = append(observations, observation)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, apperrors.NewInternalError("error iterating capacity observations", err)
+	}
+	return observations, nil
+}
+
+// ReplaceProfiles replaces all of a facility's profiles in one transaction
+func (a *CapacityHistoryAdapter) ReplaceProfiles(ctx context.Context, facilityID string, profiles []*entities.WaitTimeProfile) error {
+	tx, err := a.client.DB().BeginTx(ctx, nil)
+	if err != nil {
+		return apperrors.NewInternalError("failed to begin transaction", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM wait_time_profiles WHERE facility_id = $1`, facilityID); err != nil {
+		return apperrors.NewInternalError("failed to clear wait time profiles", err)
+	}
+
+	for _, profile := range profiles {
+		var wait sql.NullFloat64
+		if profile.AvgWaitMinutes != nil {
+			wait = sql.NullFloat64{Float64: *profile.AvgWaitMinutes, Valid: true}
+		}
+		var status sql.NullString
+		if profile.TypicalCapacityStatus != nil {
+			status = sql.NullString{String: *profile.TypicalCapacityStatus, Valid: true}
+		}
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO wait_time_profiles
+			(facility_id, ward_name, hour_of_week, avg_wait_minutes, wait_weight, typical_capacity_status, samples, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		`,
+			facilityID,
+			profile.WardName,
+			profile.HourOfWeek,
+			wait,
+			profile.WaitWeight,
+			status,
+			profile.Samples,
+			profile.UpdatedAt,
+		)
+		if err != nil {
+			return apperrors.NewInternalError("failed to store wait time profile", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return apperrors.NewInternalError("failed to commit transaction", err)
+	}
+	return nil
+}
+
+// ListProfiles returns the profiles of the given facilities for the given hours of the week
+func (a *CapacityHistoryAdapter) ListProfiles(ctx context.Context, facilityIDs []string, hoursOfWeek []int) ([]*entities.WaitTimeProfile, error) {
+	if len(facilityIDs) == 0 || len(hoursOfWeek) == 0 {
+		return nil, nil
+	}
+	hours := make([]int64, len(hoursOfWeek))
+	for i, hour := range hoursOfWeek {
+		hours[i] = int64(hour)
+	}
+
+	rows, err := a.client.DB().QueryContext(ctx, `
+		SELECT facility_id, ward_name, hour_of_week, avg_wait_minutes, wait_weight, typical_capacity_status, samples, updated_at
+		FROM wait_time_profiles
+		WHERE facility_id = ANY($1) AND hour_of_week = ANY($2)
+	`, pq.Array(facilityIDs), pq.Array(hours))
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to list wait time profiles", err)
+	}
+	defer rows.Close()
+
+	var profiles []*entities.WaitTimeProfile
+	for rows.Next() {
+		profile := &entities.WaitTimeProfile{}
+		var wait sql.NullFloat64
+		var status sql.NullString
+		if err := rows.Scan(
+			&profile.FacilityID,
+			&profile.WardName,
+			&profile.HourOfWeek,
+			&wait,
+			&profile.WaitWeight,
+			&status,
+			&profile.Samples,
+			&profile.UpdatedAt,
+		); err != nil {
+			return nil, apperrors.NewInternalError("failed to scan wait time profile", err)
+		}
+		if wait.Valid {
+			profile.AvgWaitMinutes = &wait.Float64
+		}
+		if status.Valid {
+			profile.TypicalCapacityStatus = &status.String
+		}
+		profiles = append(profiles, profile)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, apperrors.NewInternalError("error iterating wait time profiles", err)
+	}
+	return profiles, nil
+}
+
+// DeleteObservationsBefore removes reports observed before the given time
+func (a *CapacityHistoryAdapter) DeleteObservationsBefore(ctx context.Context, before time.Time) (int64, error) {
+	result, err := a.client.DB().ExecContext(ctx, `DELETE FROM capacity_observations WHERE observed_at < $1`, before)
+	if err != nil {
+		return 0, apperrors.NewInternalError("failed to prune capacity observations", err)
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, apperrors.NewInternalError("failed to count pruned capacity observations", err)
+	}
+	return deleted, nil
+}
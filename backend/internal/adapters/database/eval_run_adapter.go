@@ -0,0 +1,103 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/evaluation"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/infrastructure/clients/postgres"
+	apperrors "github.com/zatekoja/Patientpricediscoverydesign/backend/pkg/errors"
+)
+
+// EvalRunAdapter stores search quality evaluation runs in Postgres.
+type EvalRunAdapter struct {
+	client *postgres.Client
+}
+
+// NewEvalRunAdapter creates a new evaluation run adapter.
+func NewEvalRunAdapter(client *postgres.Client) evaluation.RunStore {
+	return &EvalRunAdapter{client: client}
+}
+
+// SaveRun stores the report's metrics alongside the full report.
+func (a *EvalRunAdapter) SaveRun(ctx context.Context, report *evaluation.Report) (int64, error) {
+	if report == nil || report.Summary == nil {
+		return 0, apperrors.NewInternalError("report has no summary", fmt.Errorf("report has no summary"))
+	}
+	data, err := json.Marshal(report)
+	if err != nil {
+		return 0, apperrors.NewInternalError("failed to encode evaluation report", err)
+	}
+	record := evaluation.NewRunRecord(report)
+
+	var id int64
+	err = a.client.DB().QueryRowContext(ctx, `
+		INSERT INTO eval_runs
+		(created_at, corpus, golden_set, git_commit, embedding_model, total_queries, avg_recall_at_10,
+		 avg_mrr_at_10, avg_latency_ms, queries_with_hits, failed_queries, passed, breaches, report)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		RETURNING id
+	`,
+		record.CreatedAt,
+		record.Corpus,
+		record.GoldenSet,
+		sql.NullString{String: record.GitCommit, Valid: record.GitCommit != ""},
+		sql.NullString{String: record.EmbeddingModel, Valid: record.EmbeddingModel != ""},
+		record.TotalQueries,
+		record.AvgRecallAt10,
+		record.AvgMRRAt10,
+		float64(record.AvgLatency)/float64(time.Millisecond),
+		record.QueriesWithHits,
+		record.FailedQueries,
+		record.Passed,
+		record.Breaches,
+		data,
+	).Scan(&id)
+	if err != nil {
+		return 0, apperrors.NewInternalError("failed to save evaluation run", err)
+	}
+	return id, nil
+}
+
+// ListRuns returns the latest runs against a corpus and golden set, newest first.
+func (a *EvalRunAdapter) ListRuns(ctx context.Context, corpus, goldenSet string, limit int) ([]evaluation.RunRecord, error) {
+	rows, err := a.client.DB().QueryContext(ctx, `
+		SELECT id, created_at, corpus, golden_set, COALESCE(git_commit, ''), COALESCE(embedding_model, ''),
+			total_queries, avg_recall_at_10, avg_mrr_at_10, avg_latency_ms, queries_with_hits,
+			failed_queries, passed, breaches
+		FROM eval_runs
+		WHERE corpus = $1 AND golden_set = $2
+		ORDER BY created_at DESC, id DESC
+		LIMIT $3
+	`, corpus, goldenSet, limit)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to list evaluation runs", err)
+	}
+	defer rows.Close()
+
+	var runs []evaluation.RunRecord
+	for rows.Next() {
+		var (
+			run       evaluation.RunRecord
+			latencyMs float64
+			passed    sql.NullBool
+		)
+		if err := rows.Scan(&run.ID, &run.CreatedAt, &run.Corpus, &run.GoldenSet, &run.GitCommit, &run.EmbeddingModel,
+			&run.TotalQueries, &run.AvgRecallAt10, &run.AvgMRRAt10, &latencyMs, &run.QueriesWithHits,
+			&run.FailedQueries, &passed, &run.Breaches); err != nil {
+			return nil, apperrors.NewInternalError("failed to scan evaluation run", err)
+		}
+		run.AvgLatency = time.Duration(latencyMs * float64(time.Millisecond))
+		if passed.Valid {
+			run.Passed = &passed.Bool
+		}
+		runs = append(runs, run)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, apperrors.NewInternalError("error iterating evaluation runs", err)
+	}
+	return runs, nil
+}
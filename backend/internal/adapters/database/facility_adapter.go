@@ -10,12 +10,17 @@ import (
 
 	"github.com/doug-martin/goqu/v9"
 	_ "github.com/doug-martin/goqu/v9/dialect/postgres"
+	"github.com/doug-martin/goqu/v9/exp"
 	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
 	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/repositories"
 	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/infrastructure/clients/postgres"
 	apperrors "github.com/zatekoja/Patientpricediscoverydesign/backend/pkg/errors"
 )
 
+// mapPointsQueryTimeout bounds the map query, which scans a viewport's worth of
+// facilities and their prices
+const mapPointsQueryTimeout = 10 * time.Second
+
 // FacilityAdapter implements the FacilityRepository interface
 type FacilityAdapter struct {
 	client *postgres.Client
@@ -24,6 +29,8 @@ type FacilityAdapter struct {
 
 var nonSlugChars = regexp.MustCompile(`[^a-z0-9]+`)
 
+var _ repositories.FacilityMapRepository = (*FacilityAdapter)(nil)
+
 // NewFacilityAdapter creates a new facility adapter
 func NewFacilityAdapter(client *postgres.Client) repositories.FacilityRepository {
 	return &FacilityAdapter{
@@ -85,11 +92,16 @@ func (a *FacilityAdapter) Create(ctx context.Context, facility *entities.Facilit
 			}(),
 			Valid: facility.UrgentCareAvailable != nil,
 		},
-		"rating":       facility.Rating,
-		"review_count": facility.ReviewCount,
-		"is_active":    facility.IsActive,
-		"created_at":   facility.CreatedAt,
-		"updated_at":   facility.UpdatedAt,
+		"capacity_status_reported_at": facility.CapacityStatusReportedAt,
+		"avg_wait_reported_at":        facility.AvgWaitReportedAt,
+		"urgent_care_reported_at":     facility.UrgentCareReportedAt,
+		"rating":                      facility.Rating,
+		"review_count":                facility.ReviewCount,
+		"is_active":                   facility.IsActive,
+		"created_at":                  facility.CreatedAt,
+		"updated_at":                  facility.UpdatedAt,
+		"location_source":             sql.NullString{String: facility.LocationSource, Valid: facility.LocationSource != ""},
+		"location_pinned":             facility.LocationPinned,
 	}
 
 	query, args, err := a.db.Insert("facilities").Rows(record).ToSQL()
@@ -97,10 +109,11 @@ func (a *FacilityAdapter) Create(ctx context.Context, facility *entities.Facilit
 		return apperrors.NewInternalError("failed to build insert query", err)
 	}
 
-	_, err = a.client.DB().ExecContext(ctx, query, args...)
+	_, err = a.client.Conn(ctx).ExecContext(ctx, query, args...)
 	if err != nil {
 		return apperrors.NewInternalError("failed to create facility", err)
 	}
+	facility.Version = 1
 
 	return nil
 }
@@ -110,8 +123,9 @@ func (a *FacilityAdapter) GetByID(ctx context.Context, id string) (*entities.Fac
 	query, args, err := a.db.Select(
 		"id", "name", "street", "city", "state", "zip_code", "country",
 		"latitude", "longitude", "phone_number", "email", "website",
-		"description", "facility_type", "scheduling_external_id", "capacity_status", "ward_statuses", "avg_wait_minutes", "urgent_care_available", "rating", "review_count",
-		"is_active", "created_at", "updated_at",
+		"description", "facility_type", "scheduling_external_id", "capacity_status", "ward_statuses", "avg_wait_minutes", "urgent_care_available",
+		"capacity_status_reported_at", "avg_wait_reported_at", "urgent_care_reported_at", "rating", "review_count",
+		"is_active", "created_at", "updated_at", "version", "location_source", "location_pinned",
 	).From("facilities").
 		Where(goqu.Ex{"id": id, "is_active": true}).
 		ToSQL()
@@ -128,8 +142,9 @@ func (a *FacilityAdapter) GetByID(ctx context.Context, id string) (*entities.Fac
 	var wardStatuses []byte
 	var avgWaitMinutes sql.NullInt64
 	var urgentCareAvailable sql.NullBool
+	var locationSource sql.NullString
 
-	err = a.client.DB().QueryRowContext(ctx, query, args...).Scan(
+	err = a.client.Conn(ctx).QueryRowContext(ctx, query, args...).Scan(
 		&facility.ID,
 		&facility.Name,
 		&street,
@@ -149,11 +164,17 @@ func (a *FacilityAdapter) GetByID(ctx context.Context, id string) (*entities.Fac
 		&wardStatuses,
 		&avgWaitMinutes,
 		&urgentCareAvailable,
+		&facility.CapacityStatusReportedAt,
+		&facility.AvgWaitReportedAt,
+		&facility.UrgentCareReportedAt,
 		&facility.Rating,
 		&facility.ReviewCount,
 		&facility.IsActive,
 		&facility.CreatedAt,
 		&facility.UpdatedAt,
+		&facility.Version,
+		&locationSource,
+		&facility.LocationPinned,
 	)
 
 	if err == sql.ErrNoRows {
@@ -175,6 +196,7 @@ func (a *FacilityAdapter) GetByID(ctx context.Context, id string) (*entities.Fac
 	facility.Description = description.String
 	facility.FacilityType = facilityType.String
 	facility.SchedulingExternalID = schedulingExternalID.String
+	facility.LocationSource = locationSource.String
 	if capacityStatus.Valid {
 		value := capacityStatus.String
 		facility.CapacityStatus = &value
@@ -194,8 +216,26 @@ func (a *FacilityAdapter) GetByID(ctx context.Context, id string) (*entities.Fac
 	return facility, nil
 }
 
-// Update updates a facility
+// Update updates a facility if it is still at the version it was read at
 func (a *FacilityAdapter) Update(ctx context.Context, facility *entities.Facility) error {
+	query, args, err := facilityUpdateQuery(a.db, facility)
+	if err != nil {
+		return apperrors.NewInternalError("failed to build update query", err)
+	}
+
+	version, err := execVersioned(ctx, a.client.Conn(ctx), query, args, "facilities", "facility", facility.ID)
+	if err != nil {
+		return err
+	}
+	facility.Version = version
+
+	return nil
+}
+
+// facilityUpdateQuery stamps the facility's update time and builds its
+// compare-and-swap UPDATE statement returning the new version, shared by the
+// plain and outbox write paths
+func facilityUpdateQuery(db *goqu.Database, facility *entities.Facility) (string, []interface{}, error) {
 	facility.UpdatedAt = time.Now()
 	facility.SchedulingExternalID = ensureSchedulingSlug(facility)
 
@@ -247,36 +287,23 @@ func (a *FacilityAdapter) Update(ctx context.Context, facility *entities.Facilit
 			}(),
 			Valid: facility.UrgentCareAvailable != nil,
 		},
-		"rating":       facility.Rating,
-		"review_count": facility.ReviewCount,
-		"is_active":    facility.IsActive,
-		"updated_at":   facility.UpdatedAt,
+		"capacity_status_reported_at": facility.CapacityStatusReportedAt,
+		"avg_wait_reported_at":        facility.AvgWaitReportedAt,
+		"urgent_care_reported_at":     facility.UrgentCareReportedAt,
+		"rating":                      facility.Rating,
+		"review_count":                facility.ReviewCount,
+		"is_active":                   facility.IsActive,
+		"updated_at":                  facility.UpdatedAt,
+		"location_source":             sql.NullString{String: facility.LocationSource, Valid: facility.LocationSource != ""},
+		"location_pinned":             facility.LocationPinned,
+		"version":                     nextVersion,
 	}
 
-	query, args, err := a.db.Update("facilities").
+	return db.Update("facilities").
 		Set(record).
-		Where(goqu.Ex{"id": facility.ID}).
+		Where(goqu.Ex{"id": facility.ID, "version": facility.Version}).
+		Returning("version").
 		ToSQL()
-
-	if err != nil {
-		return apperrors.NewInternalError("failed to build update query", err)
-	}
-
-	result, err := a.client.DB().ExecContext(ctx, query, args...)
-	if err != nil {
-		return apperrors.NewInternalError("failed to update facility", err)
-	}
-
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return apperrors.NewInternalError("failed to get rows affected", err)
-	}
-
-	if rowsAffected == 0 {
-		return apperrors.NewNotFoundError(fmt.Sprintf("facility with id %s not found", facility.ID))
-	}
-
-	return nil
 }
 
 func ensureSchedulingSlug(facility *entities.Facility) string {
@@ -313,8 +340,9 @@ func (a *FacilityAdapter) GetByIDs(ctx context.Context, ids []string) ([]*entiti
 	query, args, err := a.db.Select(
 		"id", "name", "street", "city", "state", "zip_code", "country",
 		"latitude", "longitude", "phone_number", "email", "website",
-		"description", "facility_type", "scheduling_external_id", "capacity_status", "ward_statuses", "avg_wait_minutes", "urgent_care_available", "rating", "review_count",
-		"is_active", "created_at", "updated_at",
+		"description", "facility_type", "scheduling_external_id", "capacity_status", "ward_statuses", "avg_wait_minutes", "urgent_care_available",
+		"capacity_status_reported_at", "avg_wait_reported_at", "urgent_care_reported_at", "rating", "review_count",
+		"is_active", "created_at", "updated_at", "version", "location_source", "location_pinned",
 	).From("facilities").
 		Where(goqu.Ex{"id": ids, "is_active": true}).
 		ToSQL()
@@ -323,7 +351,7 @@ func (a *FacilityAdapter) GetByIDs(ctx context.Context, ids []string) ([]*entiti
 		return nil, apperrors.NewInternalError("failed to build query", err)
 	}
 
-	rows, err := a.client.DB().QueryContext(ctx, query, args...)
+	rows, err := a.client.Conn(ctx).QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, apperrors.NewInternalError("failed to get facilities by ids", err)
 	}
@@ -339,6 +367,7 @@ func (a *FacilityAdapter) GetByIDs(ctx context.Context, ids []string) ([]*entiti
 		var wardStatuses []byte
 		var avgWaitMinutes sql.NullInt64
 		var urgentCareAvailable sql.NullBool
+		var locationSource sql.NullString
 
 		err := rows.Scan(
 			&facility.ID,
@@ -360,11 +389,17 @@ func (a *FacilityAdapter) GetByIDs(ctx context.Context, ids []string) ([]*entiti
 			&wardStatuses,
 			&avgWaitMinutes,
 			&urgentCareAvailable,
+			&facility.CapacityStatusReportedAt,
+			&facility.AvgWaitReportedAt,
+			&facility.UrgentCareReportedAt,
 			&facility.Rating,
 			&facility.ReviewCount,
 			&facility.IsActive,
 			&facility.CreatedAt,
 			&facility.UpdatedAt,
+			&facility.Version,
+			&locationSource,
+			&facility.LocationPinned,
 		)
 		if err != nil {
 			return nil, apperrors.NewInternalError("failed to scan facility", err)
@@ -382,6 +417,7 @@ func (a *FacilityAdapter) GetByIDs(ctx context.Context, ids []string) ([]*entiti
 		facility.Description = description.String
 		facility.FacilityType = facilityType.String
 		facility.SchedulingExternalID = schedulingExternalID.String
+		facility.LocationSource = locationSource.String
 		if capacityStatus.Valid {
 			value := capacityStatus.String
 			facility.CapacityStatus = &value
@@ -407,7 +443,7 @@ func (a *FacilityAdapter) GetByIDs(ctx context.Context, ids []string) ([]*entiti
 // Delete deletes a facility (soft delete)
 func (a *FacilityAdapter) Delete(ctx context.Context, id string) error {
 	query, args, err := a.db.Update("facilities").
-		Set(goqu.Record{"is_active": false, "updated_at": time.Now()}).
+		Set(goqu.Record{"is_active": false, "updated_at": time.Now(), "version": nextVersion}).
 		Where(goqu.Ex{"id": id}).
 		ToSQL()
 
@@ -415,7 +451,7 @@ func (a *FacilityAdapter) Delete(ctx context.Context, id string) error {
 		return apperrors.NewInternalError("failed to build delete query", err)
 	}
 
-	result, err := a.client.DB().ExecContext(ctx, query, args...)
+	result, err := a.client.Conn(ctx).ExecContext(ctx, query, args...)
 	if err != nil {
 		return apperrors.NewInternalError("failed to delete facility", err)
 	}
@@ -437,8 +473,9 @@ func (a *FacilityAdapter) List(ctx context.Context, filter repositories.Facility
 	ds := a.db.Select(
 		"id", "name", "street", "city", "state", "zip_code", "country",
 		"latitude", "longitude", "phone_number", "email", "website",
-		"description", "facility_type", "scheduling_external_id", "capacity_status", "ward_statuses", "avg_wait_minutes", "urgent_care_available", "rating", "review_count",
-		"is_active", "created_at", "updated_at",
+		"description", "facility_type", "scheduling_external_id", "capacity_status", "ward_statuses", "avg_wait_minutes", "urgent_care_available",
+		"capacity_status_reported_at", "avg_wait_reported_at", "urgent_care_reported_at", "rating", "review_count",
+		"is_active", "created_at", "updated_at", "version", "location_source", "location_pinned",
 	).From("facilities")
 
 	if filter.FacilityType != "" {
@@ -464,7 +501,7 @@ func (a *FacilityAdapter) List(ctx context.Context, filter repositories.Facility
 		return nil, apperrors.NewInternalError("failed to build list query", err)
 	}
 
-	rows, err := a.client.DB().QueryContext(ctx, query, args...)
+	rows, err := a.client.Conn(ctx).QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, apperrors.NewInternalError("failed to list facilities", err)
 	}
@@ -480,6 +517,7 @@ func (a *FacilityAdapter) List(ctx context.Context, filter repositories.Facility
 		var wardStatuses []byte
 		var avgWaitMinutes sql.NullInt64
 		var urgentCareAvailable sql.NullBool
+		var locationSource sql.NullString
 
 		err := rows.Scan(
 			&facility.ID,
@@ -501,11 +539,17 @@ func (a *FacilityAdapter) List(ctx context.Context, filter repositories.Facility
 			&wardStatuses,
 			&avgWaitMinutes,
 			&urgentCareAvailable,
+			&facility.CapacityStatusReportedAt,
+			&facility.AvgWaitReportedAt,
+			&facility.UrgentCareReportedAt,
 			&facility.Rating,
 			&facility.ReviewCount,
 			&facility.IsActive,
 			&facility.CreatedAt,
 			&facility.UpdatedAt,
+			&facility.Version,
+			&locationSource,
+			&facility.LocationPinned,
 		)
 		if err != nil {
 			return nil, apperrors.NewInternalError("failed to scan facility", err)
@@ -523,6 +567,7 @@ func (a *FacilityAdapter) List(ctx context.Context, filter repositories.Facility
 		facility.Description = description.String
 		facility.FacilityType = facilityType.String
 		facility.SchedulingExternalID = schedulingExternalID.String
+		facility.LocationSource = locationSource.String
 		if capacityStatus.Valid {
 			value := capacityStatus.String
 			facility.CapacityStatus = &value
@@ -555,32 +600,12 @@ func (a *FacilityAdapter) Search(ctx context.Context, params repositories.Search
 	return facilities, err
 }
 
-// SearchWithCount searches facilities and returns total match count.
+// SearchWithCount searches facilities and returns total match count. It is
+// the fallback when Typesense is unavailable, so it applies the same filters
+// and sorts: a facility's price is its cheapest available service, as in
+// the search index.
 func (a *FacilityAdapter) SearchWithCount(ctx context.Context, params repositories.SearchParams) ([]*entities.Facility, int, error) {
-	// Use a subquery to calculate distance, then filter in outer WHERE clause
-	// This avoids the HAVING issue and is more efficient
-	distanceExpr := goqu.L(
-		"(6371 * acos(cos(radians(?)) * cos(radians(latitude)) * cos(radians(longitude) - radians(?)) + sin(radians(?)) * sin(radians(latitude))))",
-		params.Latitude, params.Longitude, params.Latitude,
-	)
-
-	var searchFilter goqu.Expression
-	if params.Query != "" {
-		searchPattern := fmt.Sprintf("%%%s%%", params.Query)
-		searchFilter = goqu.Or(
-			goqu.I("name").ILike(searchPattern),
-			goqu.I("facility_type").ILike(searchPattern),
-			goqu.I("description").ILike(searchPattern),
-		)
-	}
-
-	countDs := a.db.Select(goqu.COUNT("*")).From("facilities").
-		Where(goqu.Ex{"is_active": true}).
-		Where(distanceExpr.Lte(params.RadiusKm))
-
-	if searchFilter != nil {
-		countDs = countDs.Where(searchFilter)
-	}
+	countDs, ds := searchQueries(a.db, params)
 
 	countQuery, countArgs, err := countDs.ToSQL()
 	if err != nil {
@@ -588,39 +613,16 @@ func (a *FacilityAdapter) SearchWithCount(ctx context.Context, params repositori
 	}
 
 	var totalCount int
-	if err := a.client.DB().QueryRowContext(ctx, countQuery, countArgs...).Scan(&totalCount); err != nil {
+	if err := a.client.Conn(ctx).QueryRowContext(ctx, countQuery, countArgs...).Scan(&totalCount); err != nil {
 		return nil, 0, apperrors.NewInternalError("failed to count facilities", err)
 	}
 
-	ds := a.db.Select(
-		"id", "name", "street", "city", "state", "zip_code", "country",
-		"latitude", "longitude", "phone_number", "email", "website",
-		"description", "facility_type", "scheduling_external_id", "capacity_status", "ward_statuses", "avg_wait_minutes", "urgent_care_available", "rating", "review_count",
-		"is_active", "created_at", "updated_at",
-		distanceExpr.As("distance"),
-	).From("facilities").
-		Where(goqu.Ex{"is_active": true}).
-		Where(distanceExpr.Lte(params.RadiusKm)).
-		Order(goqu.I("distance").Asc())
-
-	if searchFilter != nil {
-		ds = ds.Where(searchFilter)
-	}
-
-	if params.Limit > 0 {
-		ds = ds.Limit(uint(params.Limit))
-	}
-
-	if params.Offset > 0 {
-		ds = ds.Offset(uint(params.Offset))
-	}
-
 	query, args, err := ds.ToSQL()
 	if err != nil {
 		return nil, 0, apperrors.NewInternalError("failed to build search query", err)
 	}
 
-	rows, err := a.client.DB().QueryContext(ctx, query, args...)
+	rows, err := a.client.Conn(ctx).QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, 0, apperrors.NewInternalError("failed to search facilities", err)
 	}
@@ -636,6 +638,7 @@ func (a *FacilityAdapter) SearchWithCount(ctx context.Context, params repositori
 		var wardStatuses []byte
 		var avgWaitMinutes sql.NullInt64
 		var urgentCareAvailable sql.NullBool
+		var locationSource sql.NullString
 		var distance float64
 
 		err := rows.Scan(
@@ -658,11 +661,17 @@ func (a *FacilityAdapter) SearchWithCount(ctx context.Context, params repositori
 			&wardStatuses,
 			&avgWaitMinutes,
 			&urgentCareAvailable,
+			&facility.CapacityStatusReportedAt,
+			&facility.AvgWaitReportedAt,
+			&facility.UrgentCareReportedAt,
 			&facility.Rating,
 			&facility.ReviewCount,
 			&facility.IsActive,
 			&facility.CreatedAt,
 			&facility.UpdatedAt,
+			&facility.Version,
+			&locationSource,
+			&facility.LocationPinned,
 			&distance,
 		)
 		if err != nil {
@@ -681,6 +690,7 @@ func (a *FacilityAdapter) SearchWithCount(ctx context.Context, params repositori
 		facility.Description = description.String
 		facility.FacilityType = facilityType.String
 		facility.SchedulingExternalID = schedulingExternalID.String
+		facility.LocationSource = locationSource.String
 		if capacityStatus.Valid {
 			value := capacityStatus.String
 			facility.CapacityStatus = &value
@@ -706,3 +716,337 @@ func (a *FacilityAdapter) SearchWithCount(ctx context.Context, params repositori
 
 	return facilities, totalCount, nil
 }
+
+// searchQueries builds the count and page queries for a facility search
+// within the searched circle
+func searchQueries(db *goqu.Database, params repositories.SearchParams) (count, page *goqu.SelectDataset) {
+	lat, lon, radiusKm, ok := params.GeoFilter()
+	if !ok {
+		radiusKm = params.RadiusKm
+	}
+	distanceExpr := distanceFrom(lat, lon)
+
+	prices := db.From("facility_procedures").
+		Select(goqu.C("facility_id"), goqu.MIN("price").As("price")).
+		Where(goqu.L("COALESCE(is_available, TRUE)"), goqu.C("price").Gt(0)).
+		GroupBy("facility_id")
+
+	conditions := append([]goqu.Expression{
+		goqu.I("f.is_active").IsTrue(),
+		distanceExpr.Lte(radiusKm),
+	}, facilityFilters(params)...)
+	if params.ProcedureID != "" {
+		conditions = append(conditions, goqu.L(
+			"EXISTS (SELECT 1 FROM facility_procedures fp WHERE fp.facility_id = f.id AND fp.procedure_id = ? AND COALESCE(fp.is_available, TRUE))",
+			params.ProcedureID,
+		))
+	}
+	if params.MinPrice != nil {
+		conditions = append(conditions, goqu.I("p.price").Gte(*params.MinPrice))
+	}
+	if params.MaxPrice != nil {
+		conditions = append(conditions, goqu.I("p.price").Lte(*params.MaxPrice))
+	}
+
+	from := db.From(goqu.T("facilities").As("f")).
+		LeftJoin(prices.As("p"), goqu.On(goqu.I("p.facility_id").Eq(goqu.I("f.id")))).
+		Where(conditions...)
+
+	page = from.Select(
+		"f.id", "f.name", "f.street", "f.city", "f.state", "f.zip_code", "f.country",
+		"f.latitude", "f.longitude", "f.phone_number", "f.email", "f.website",
+		"f.description", "f.facility_type", "f.scheduling_external_id", "f.capacity_status", "f.ward_statuses", "f.avg_wait_minutes", "f.urgent_care_available",
+		"f.capacity_status_reported_at", "f.avg_wait_reported_at", "f.urgent_care_reported_at", "f.rating", "f.review_count",
+		"f.is_active", "f.created_at", "f.updated_at", "f.version", "f.location_source", "f.location_pinned",
+		distanceExpr.As("distance"),
+	)
+
+	switch params.SortBy {
+	case repositories.SearchSortRating:
+		page = page.Order(goqu.I("f.rating").Desc(), goqu.I("distance").Asc())
+	case repositories.SearchSortPrice:
+		page = page.Order(goqu.I("p.price").Asc().NullsLast(), goqu.I("distance").Asc())
+	case repositories.SearchSortDistance:
+		// Distance is measured from the user even when a named area is searched
+		if params.Area != nil && (params.Latitude != 0 || params.Longitude != 0) {
+			page = page.Order(distanceFrom(params.Latitude, params.Longitude).Asc())
+		} else {
+			page = page.Order(goqu.I("distance").Asc())
+		}
+	default:
+		page = page.Order(goqu.I("distance").Asc())
+	}
+	if params.Limit > 0 {
+		page = page.Limit(uint(params.Limit))
+	}
+	if params.Offset > 0 {
+		page = page.Offset(uint(params.Offset))
+	}
+
+	return from.Select(goqu.COUNT("*")), page
+}
+
+// distanceFrom is the great-circle distance in km from a point to a facility
+func distanceFrom(lat, lon float64) exp.LiteralExpression {
+	return goqu.L(
+		"(6371 * acos(cos(radians(?)) * cos(radians(f.latitude)) * cos(radians(f.longitude) - radians(?)) + sin(radians(?)) * sin(radians(f.latitude))))",
+		lat, lon, lat,
+	)
+}
+
+// facilityFilters returns the conditions on a facility f that list and map
+// searches share. OpenNow and Open24Hours are approximations, as in the
+// search index: a facility is open now unless it reports itself closed, and
+// open around the clock when it is urgent care or its name says so.
+func facilityFilters(params repositories.SearchParams) []goqu.Expression {
+	var conditions []goqu.Expression
+	if params.Query != "" {
+		pattern := fmt.Sprintf("%%%s%%", params.Query)
+		conditions = append(conditions, goqu.Or(
+			goqu.I("f.name").ILike(pattern),
+			goqu.I("f.facility_type").ILike(pattern),
+			goqu.I("f.description").ILike(pattern),
+		))
+	}
+	if len(params.FacilityTypes) > 0 {
+		conditions = append(conditions, goqu.I("f.facility_type").In(params.FacilityTypes))
+	}
+	if params.OpenNow {
+		conditions = append(conditions, goqu.Or(
+			goqu.I("f.capacity_status").IsNull(),
+			goqu.L("LOWER(f.capacity_status)").Neq(entities.CapacityStatusClosed),
+		))
+	}
+	if params.Open24Hours {
+		roundTheClock := []goqu.Expression{goqu.I("f.facility_type").Eq("urgent_care")}
+		for _, marker := range entities.RoundTheClockMarkers {
+			roundTheClock = append(roundTheClock, goqu.I("f.name").ILike("%"+marker+"%"))
+		}
+		conditions = append(conditions, goqu.Or(roundTheClock...))
+	}
+	if params.InsuranceProvider != "" {
+		conditions = append(conditions, goqu.L(`EXISTS (
+			SELECT 1 FROM facility_insurance fi
+			JOIN insurance_providers ip ON ip.id = fi.insurance_provider_id
+			WHERE fi.facility_id = f.id AND COALESCE(fi.is_accepted, TRUE)
+				AND (LOWER(ip.name) = LOWER(?) OR LOWER(ip.code) = LOWER(?)))`,
+			params.InsuranceProvider, params.InsuranceProvider))
+	}
+	return conditions
+}
+
+// MapPoints returns the facilities inside params.Bounds with their price: the
+// filtered procedure's when there is a procedure filter, otherwise their
+// cheapest available service. Price filters apply to that price.
+func (a *FacilityAdapter) MapPoints(ctx context.Context, params repositories.SearchParams, limit int) ([]*entities.FacilityMapPoint, int, error) {
+	if params.Bounds == nil {
+		return nil, 0, apperrors.NewValidationError("map search requires bounds")
+	}
+	query, args, err := mapPointsQuery(a.db, params, limit)
+	if err != nil {
+		return nil, 0, apperrors.NewInternalError("failed to build map query", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, mapPointsQueryTimeout)
+	defer cancel()
+	rows, err := a.client.Conn(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, apperrors.NewInternalError("failed to search facilities in bounds", err)
+	}
+	defer rows.Close()
+
+	points := []*entities.FacilityMapPoint{}
+	total := 0
+	for rows.Next() {
+		point := &entities.FacilityMapPoint{}
+		var facilityType, capacityStatus sql.NullString
+		var price sql.NullFloat64
+		if err := rows.Scan(
+			&point.ID,
+			&point.Name,
+			&facilityType,
+			&point.Location.Latitude,
+			&point.Location.Longitude,
+			&point.Rating,
+			&capacityStatus,
+			&price,
+			&total,
+		); err != nil {
+			return nil, 0, apperrors.NewInternalError("failed to scan map facility", err)
+		}
+		point.FacilityType = facilityType.String
+		facility := entities.Facility{}
+		if capacityStatus.Valid {
+			facility.CapacityStatus = &capacityStatus.String
+		}
+		point.CapacityStatus = facility.CurrentCapacityStatus()
+		if price.Valid {
+			value := price.Float64
+			point.Price = &value
+		}
+		points = append(points, point)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, apperrors.NewInternalError("error iterating map facilities", err)
+	}
+	return points, total, nil
+}
+
+// MapCells sums up the facilities inside params.Bounds per grid cell, with
+// prices as in MapPoints
+func (a *FacilityAdapter) MapCells(ctx context.Context, params repositories.SearchParams, cellDegrees float64) ([]*repositories.MapCell, error) {
+	if params.Bounds == nil {
+		return nil, apperrors.NewValidationError("map search requires bounds")
+	}
+	query, args, err := mapCellsQuery(a.db, params, cellDegrees)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to build map cells query", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, mapPointsQueryTimeout)
+	defer cancel()
+	rows, err := a.client.Conn(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to group facilities in bounds", err)
+	}
+	defer rows.Close()
+
+	type cellKey struct{ x, y int }
+	cells := map[cellKey]*repositories.MapCell{}
+	order := []*repositories.MapCell{}
+	for rows.Next() {
+		var (
+			key                       cellKey
+			status                    string
+			count                     int
+			latitudeSum, longitudeSum float64
+			bounds                    entities.GeoBounds
+			minPrice, maxPrice        sql.NullFloat64
+			id, name, facilityType    sql.NullString
+			rating                    sql.NullFloat64
+		)
+		if err := rows.Scan(
+			&key.x, &key.y, &status, &count,
+			&latitudeSum, &longitudeSum,
+			&bounds.MinLatitude, &bounds.MinLongitude, &bounds.MaxLatitude, &bounds.MaxLongitude,
+			&minPrice, &maxPrice,
+			&id, &name, &facilityType, &rating,
+		); err != nil {
+			return nil, apperrors.NewInternalError("failed to scan map cell", err)
+		}
+
+		cell, ok := cells[key]
+		if !ok {
+			cell = &repositories.MapCell{X: key.x, Y: key.y, Bounds: bounds, CapacityMix: map[string]int{}}
+			cells[key] = cell
+			order = append(order, cell)
+		}
+		// Location holds sums until every group of the cell is read
+		cell.Count += count
+		cell.Location.Latitude += latitudeSum
+		cell.Location.Longitude += longitudeSum
+		cell.Bounds.Extend(entities.Location{Latitude: bounds.MinLatitude, Longitude: bounds.MinLongitude})
+		cell.Bounds.Extend(entities.Location{Latitude: bounds.MaxLatitude, Longitude: bounds.MaxLongitude})
+		cell.CapacityMix[status] += count
+		if minPrice.Valid && (cell.MinPrice == nil || minPrice.Float64 < *cell.MinPrice) {
+			value := minPrice.Float64
+			cell.MinPrice = &value
+		}
+		if maxPrice.Valid && (cell.MaxPrice == nil || maxPrice.Float64 > *cell.MaxPrice) {
+			value := maxPrice.Float64
+			cell.MaxPrice = &value
+		}
+		if count == 1 {
+			cell.Facility = &entities.FacilityMapPoint{
+				ID:             id.String,
+				Name:           name.String,
+				FacilityType:   facilityType.String,
+				Location:       entities.Location{Latitude: latitudeSum, Longitude: longitudeSum},
+				Rating:         rating.Float64,
+				CapacityStatus: status,
+			}
+			if minPrice.Valid {
+				value := minPrice.Float64
+				cell.Facility.Price = &value
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, apperrors.NewInternalError("error iterating map cells", err)
+	}
+
+	for _, cell := range order {
+		cell.Location.Latitude /= float64(cell.Count)
+		cell.Location.Longitude /= float64(cell.Count)
+		if cell.Count > 1 {
+			cell.Facility = nil
+		}
+	}
+	return order, nil
+}
+
+// mapPointsQuery selects up to limit facilities in the viewport, highest
+// rated first, with the total number matching in every row
+func mapPointsQuery(db *goqu.Database, params repositories.SearchParams, limit int) (string, []interface{}, error) {
+	return mapFacilities(db, params).
+		Select(
+			"f.id", "f.name", "f.facility_type", "f.latitude", "f.longitude", "f.rating", "f.capacity_status",
+			goqu.I("p.price"),
+			goqu.L("COUNT(*) OVER ()").As("total"),
+		).
+		Order(goqu.I("f.rating").Desc(), goqu.I("f.id").Asc()).
+		Limit(uint(limit)).
+		ToSQL()
+}
+
+// mapCellsQuery sums up the facilities in the viewport per grid cell and
+// capacity status. A group of one facility carries that facility's fields,
+// as the minimum of each column.
+func mapCellsQuery(db *goqu.Database, params repositories.SearchParams, cellDegrees float64) (string, []interface{}, error) {
+	return mapFacilities(db, params).
+		Select(
+			goqu.L("FLOOR((f.longitude + 180) / ?)::int", cellDegrees).As("x"),
+			goqu.L("FLOOR((f.latitude + 90) / ?)::int", cellDegrees).As("y"),
+			goqu.L("COALESCE(NULLIF(LOWER(TRIM(f.capacity_status)), ''), ?)", entities.CapacityStatusUnknown).As("status"),
+			goqu.COUNT("*"),
+			goqu.SUM("f.latitude"), goqu.SUM("f.longitude"),
+			goqu.MIN("f.latitude"), goqu.MIN("f.longitude"), goqu.MAX("f.latitude"), goqu.MAX("f.longitude"),
+			goqu.MIN("p.price"), goqu.MAX("p.price"),
+			goqu.MIN("f.id"), goqu.MIN("f.name"), goqu.MIN("f.facility_type"), goqu.MIN("f.rating"),
+		).
+		GroupBy(goqu.L("1"), goqu.L("2"), goqu.L("3")).
+		ToSQL()
+}
+
+// mapFacilities selects the facilities in the viewport that match the search
+// filters, joined to their price as p
+func mapFacilities(db *goqu.Database, params repositories.SearchParams) *goqu.SelectDataset {
+	prices := db.From("facility_procedures").
+		Select(goqu.C("facility_id"), goqu.MIN("price").As("price")).
+		Where(goqu.L("COALESCE(is_available, TRUE)")).
+		GroupBy("facility_id")
+	if params.ProcedureID != "" {
+		prices = prices.Where(goqu.C("procedure_id").Eq(params.ProcedureID))
+	}
+
+	bounds := params.Bounds
+	conditions := []goqu.Expression{
+		goqu.I("f.is_active").IsTrue(),
+		goqu.I("f.latitude").Between(goqu.Range(bounds.MinLatitude, bounds.MaxLatitude)),
+		goqu.I("f.longitude").Between(goqu.Range(bounds.MinLongitude, bounds.MaxLongitude)),
+	}
+	if params.ProcedureID != "" {
+		conditions = append(conditions, goqu.I("p.price").IsNotNull())
+	}
+	if params.MinPrice != nil {
+		conditions = append(conditions, goqu.I("p.price").Gte(*params.MinPrice))
+	}
+	if params.MaxPrice != nil {
+		conditions = append(conditions, goqu.I("p.price").Lte(*params.MaxPrice))
+	}
+	conditions = append(conditions, facilityFilters(params)...)
+
+	return db.From(goqu.T("facilities").As("f")).
+		LeftJoin(prices.As("p"), goqu.On(goqu.I("p.facility_id").Eq(goqu.I("f.id")))).
+		Where(conditions...)
+}
@@ -0,0 +1,246 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/repositories"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/infrastructure/clients/postgres"
+	apperrors "github.com/zatekoja/Patientpricediscoverydesign/backend/pkg/errors"
+)
+
+// facilityEventRelayLockKey is the advisory lock held by the running relay, so
+// instances never publish the same pending events concurrently or out of order
+const facilityEventRelayLockKey = 7304210361
+
+// FacilityEventOutboxAdapter implements the FacilityEventOutboxRepository interface
+type FacilityEventOutboxAdapter struct {
+	client *postgres.Client
+	db     *goqu.Database
+}
+
+// NewFacilityEventOutboxAdapter creates a new facility event outbox adapter
+func NewFacilityEventOutboxAdapter(client *postgres.Client) repositories.FacilityEventOutboxRepository {
+	return &FacilityEventOutboxAdapter{
+		client: client,
+		db:     goqu.New("postgres", client.DB()),
+	}
+}
+
+// UpdateFacility updates a facility and records its events in one transaction
+func (a *FacilityEventOutboxAdapter) UpdateFacility(ctx context.Context, facility *entities.Facility, events []*entities.FacilityEvent) error {
+	query, args, err := facilityUpdateQuery(a.db, facility)
+	if err != nil {
+		return apperrors.NewInternalError("failed to build update query", err)
+	}
+
+	var version int
+	err = a.withEvents(ctx, events, func(tx *sql.Tx) error {
+		version, err = execVersioned(ctx, tx, query, args, "facilities", "facility", facility.ID)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	facility.Version = version
+	return nil
+}
+
+// CreateFacilityProcedure creates a facility procedure and records its events in one transaction
+func (a *FacilityEventOutboxAdapter) CreateFacilityProcedure(ctx context.Context, fp *entities.FacilityProcedure, events []*entities.FacilityEvent) error {
+	query, args, err := facilityProcedureInsertQuery(a.db, fp)
+	if err != nil {
+		return apperrors.NewInternalError("failed to build insert query", err)
+	}
+
+	err = a.withEvents(ctx, events, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			return apperrors.NewInternalError("failed to create facility procedure", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	fp.Version = 1
+	return nil
+}
+
+// UpdateFacilityProcedure updates a facility procedure and records its events in one transaction
+func (a *FacilityEventOutboxAdapter) UpdateFacilityProcedure(ctx context.Context, fp *entities.FacilityProcedure, events []*entities.FacilityEvent) error {
+	query, args, err := facilityProcedureUpdateQuery(a.db, fp)
+	if err != nil {
+		return apperrors.NewInternalError("failed to build update query", err)
+	}
+
+	var version int
+	err = a.withEvents(ctx, events, func(tx *sql.Tx) error {
+		version, err = execVersioned(ctx, tx, query, args, "facility_procedures", "facility procedure", fp.ID)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	fp.Version = version
+	return nil
+}
+
+// UpsertWard creates or updates a facility ward and records its events in one transaction
+func (a *FacilityEventOutboxAdapter) UpsertWard(ctx context.Context, ward *entities.FacilityWard, events []*entities.FacilityEvent) error {
+	query, args, err := facilityWardUpsertQuery(a.db, ward)
+	if err != nil {
+		return apperrors.NewInternalError("failed to build upsert query", err)
+	}
+
+	var version int
+	err = a.withEvents(ctx, events, func(tx *sql.Tx) error {
+		version, err = upsertVersioned(ctx, tx, query, args, "facility ward", ward.ID)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	ward.Version = version
+	return nil
+}
+
+// withEvents runs fn and inserts the events in the same transaction, joining
+// the caller's if there is one
+func (a *FacilityEventOutboxAdapter) withEvents(ctx context.Context, events []*entities.FacilityEvent, fn func(tx *sql.Tx) error) error {
+	return a.client.InTx(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		if err := fn(tx); err != nil {
+			return err
+		}
+
+		for _, event := range events {
+			payload, err := json.Marshal(event)
+			if err != nil {
+				return apperrors.NewInternalError("failed to encode facility event", err)
+			}
+			_, err = tx.ExecContext(ctx, `
+				INSERT INTO facility_event_outbox (event_id, facility_id, event_type, payload, created_at)
+				VALUES ($1, $2, $3, $4, $5)
+			`, event.ID, event.FacilityID, string(event.EventType), payload, event.Timestamp)
+			if err != nil {
+				return apperrors.NewInternalError("failed to record facility event", err)
+			}
+		}
+		return nil
+	})
+}
+
+// Relay publishes pending events in sequence order under a transaction-scoped
+// advisory lock. An event whose publish succeeded but whose mark was not
+// committed is published again on the next pass.
+func (a *FacilityEventOutboxAdapter) Relay(ctx context.Context, limit int, publish func(*entities.FacilityEvent) error) (int, error) {
+	tx, err := a.client.DB().BeginTx(ctx, nil)
+	if err != nil {
+		return 0, apperrors.NewInternalError("failed to begin transaction", err)
+	}
+	defer tx.Rollback()
+
+	var locked bool
+	if err := tx.QueryRowContext(ctx, `SELECT pg_try_advisory_xact_lock($1)`, facilityEventRelayLockKey).Scan(&locked); err != nil {
+		return 0, apperrors.NewInternalError("failed to acquire relay lock", err)
+	}
+	if !locked {
+		// Another instance is relaying
+		return 0, nil
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT sequence, payload FROM facility_event_outbox
+		WHERE published_at IS NULL
+		ORDER BY sequence
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		return 0, apperrors.NewInternalError("failed to load pending facility events", err)
+	}
+
+	type pendingEvent struct {
+		sequence int64
+		payload  []byte
+	}
+	var pending []pendingEvent
+	for rows.Next() {
+		var p pendingEvent
+		if err := rows.Scan(&p.sequence, &p.payload); err != nil {
+			rows.Close()
+			return 0, apperrors.NewInternalError("failed to scan facility event", err)
+		}
+		pending = append(pending, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, apperrors.NewInternalError("error iterating facility events", err)
+	}
+
+	published := 0
+	var publishErr error
+	for _, p := range pending {
+		var event entities.FacilityEvent
+		if err := json.Unmarshal(p.payload, &event); err != nil {
+			// An unreadable payload can never be published; park it instead of
+			// blocking every event behind it
+			if _, err := tx.ExecContext(ctx, `
+				UPDATE facility_event_outbox
+				SET published_at = NOW(), attempts = attempts + 1, last_error = $2
+				WHERE sequence = $1
+			`, p.sequence, "undecodable payload: "+err.Error()); err != nil {
+				return published, apperrors.NewInternalError("failed to park facility event", err)
+			}
+			continue
+		}
+
+		if err := publish(&event); err != nil {
+			publishErr = err
+			if _, err := tx.ExecContext(ctx, `
+				UPDATE facility_event_outbox
+				SET attempts = attempts + 1, last_error = $2
+				WHERE sequence = $1
+			`, p.sequence, err.Error()); err != nil {
+				return published, apperrors.NewInternalError("failed to record publish failure", err)
+			}
+			break
+		}
+
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE facility_event_outbox
+			SET published_at = NOW(), attempts = attempts + 1, last_error = NULL
+			WHERE sequence = $1
+		`, p.sequence); err != nil {
+			return published, apperrors.NewInternalError("failed to mark facility event published", err)
+		}
+		published++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, apperrors.NewInternalError("failed to commit transaction", err)
+	}
+	if publishErr != nil {
+		return published, apperrors.NewExternalError("failed to publish facility event", publishErr)
+	}
+	return published, nil
+}
+
+// DeletePublishedBefore prunes events published before the cutoff
+func (a *FacilityEventOutboxAdapter) DeletePublishedBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	result, err := a.client.DB().ExecContext(ctx, `
+		DELETE FROM facility_event_outbox
+		WHERE published_at IS NOT NULL AND published_at < $1
+	`, cutoff)
+	if err != nil {
+		return 0, apperrors.NewInternalError("failed to prune facility events", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, apperrors.NewInternalError("failed to get rows affected", err)
+	}
+	return deleted, nil
+}
@@ -0,0 +1,69 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/repositories"
+)
+
+// The Postgres search is the fallback for Typesense, so it must honour the
+// same filters and sorts
+func TestSearchQueries_MatchTheSearchIndex(t *testing.T) {
+	db := goqu.Dialect("postgres").DB(nil)
+	maxPrice := 5000.0
+
+	count, page := searchQueries(db, repositories.SearchParams{
+		Latitude: 6.5, Longitude: 3.3, RadiusKm: 10,
+		Open24Hours: true,
+		MaxPrice:    &maxPrice,
+		SortBy:      repositories.SearchSortPrice,
+		Limit:       20,
+	})
+	countSQL, _, err := count.ToSQL()
+	require.NoError(t, err)
+	pageSQL, _, err := page.ToSQL()
+	require.NoError(t, err)
+
+	for _, sql := range []string{countSQL, pageSQL} {
+		assert.Contains(t, sql, `("f"."facility_type" = 'urgent_care') OR ("f"."name" ILIKE '%24 hour%')`)
+		assert.Contains(t, sql, `("p"."price" <= 5000)`)
+	}
+	assert.Contains(t, pageSQL, `ORDER BY "p"."price" ASC NULLS LAST, "distance" ASC`)
+
+	// Distance sorts from the user, not from the centre of the named area
+	_, page = searchQueries(db, repositories.SearchParams{
+		Latitude: 6.5, Longitude: 3.3,
+		Area:   &repositories.SearchArea{Name: "Ikeja", Latitude: 6.6, Longitude: 3.35, RadiusKm: 15},
+		SortBy: repositories.SearchSortDistance,
+	})
+	pageSQL, _, err = page.ToSQL()
+	require.NoError(t, err)
+	assert.Contains(t, pageSQL, "radians(6.6)) * sin(radians(f.latitude)))) <= 15")
+	assert.Contains(t, pageSQL, "ORDER BY (6371 * acos(cos(radians(6.5))")
+}
+
+// Clusters are grouped in the query, so they cover every facility that
+// matches rather than the first page of them
+func TestMapCellsQuery_GroupsEveryMatchByCell(t *testing.T) {
+	db := goqu.Dialect("postgres").DB(nil)
+	maxPrice := 5000.0
+
+	sql, _, err := mapCellsQuery(db, repositories.SearchParams{
+		Bounds:   &entities.GeoBounds{MinLatitude: 4, MinLongitude: 2, MaxLatitude: 14, MaxLongitude: 15},
+		OpenNow:  true,
+		MaxPrice: &maxPrice,
+	}, 0.25)
+	require.NoError(t, err)
+
+	assert.Contains(t, sql, "FLOOR((f.longitude + 180) / 0.25)::int AS \"x\"")
+	assert.Contains(t, sql, "FLOOR((f.latitude + 90) / 0.25)::int AS \"y\"")
+	assert.Contains(t, sql, `MIN("p"."price"), MAX("p"."price")`)
+	assert.Contains(t, sql, `("p"."price" <= 5000)`)
+	assert.Contains(t, sql, `("f"."latitude" BETWEEN 4 AND 14)`)
+	assert.Contains(t, sql, "GROUP BY 1, 2, 3")
+	assert.NotContains(t, sql, "LIMIT")
+}
@@ -77,10 +77,11 @@ func (a *FacilityWardAdapter) Create(ctx context.Context, ward *entities.Facilit
 		return apperrors.NewInternalError("failed to build insert query", err)
 	}
 
-	_, err = a.client.DB().ExecContext(ctx, query, args...)
+	_, err = a.client.Conn(ctx).ExecContext(ctx, query, args...)
 	if err != nil {
 		return apperrors.NewInternalError("failed to create facility ward", err)
 	}
+	ward.Version = 1
 
 	return nil
 }
@@ -90,7 +91,7 @@ func (a *FacilityWardAdapter) GetByID(ctx context.Context, id string) (*entities
 	query, args, err := a.db.Select(
 		"id", "facility_id", "ward_name", "ward_type",
 		"capacity_status", "avg_wait_minutes", "urgent_care_available",
-		"last_updated", "created_at",
+		"last_updated", "created_at", "version",
 	).From("facility_wards").
 		Where(goqu.Ex{"id": id}).
 		ToSQL()
@@ -104,7 +105,7 @@ func (a *FacilityWardAdapter) GetByID(ctx context.Context, id string) (*entities
 	var avgWaitMinutes sql.NullInt64
 	var urgentCareAvailable sql.NullBool
 
-	err = a.client.DB().QueryRowContext(ctx, query, args...).Scan(
+	err = a.client.Conn(ctx).QueryRowContext(ctx, query, args...).Scan(
 		&ward.ID,
 		&ward.FacilityID,
 		&ward.WardName,
@@ -114,6 +115,7 @@ func (a *FacilityWardAdapter) GetByID(ctx context.Context, id string) (*entities
 		&urgentCareAvailable,
 		&ward.LastUpdated,
 		&ward.CreatedAt,
+		&ward.Version,
 	)
 
 	if err == sql.ErrNoRows {
@@ -149,7 +151,7 @@ func (a *FacilityWardAdapter) GetByFacilityID(ctx context.Context, facilityID st
 	query, args, err := a.db.Select(
 		"id", "facility_id", "ward_name", "ward_type",
 		"capacity_status", "avg_wait_minutes", "urgent_care_available",
-		"last_updated", "created_at",
+		"last_updated", "created_at", "version",
 	).From("facility_wards").
 		Where(goqu.Ex{"facility_id": facilityID}).
 		Order(goqu.I("ward_name").Asc()).
@@ -159,7 +161,7 @@ func (a *FacilityWardAdapter) GetByFacilityID(ctx context.Context, facilityID st
 		return nil, apperrors.NewInternalError("failed to build query", err)
 	}
 
-	rows, err := a.client.DB().QueryContext(ctx, query, args...)
+	rows, err := a.client.Conn(ctx).QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, apperrors.NewInternalError("failed to get facility wards", err)
 	}
@@ -182,6 +184,7 @@ func (a *FacilityWardAdapter) GetByFacilityID(ctx context.Context, facilityID st
 			&urgentCareAvailable,
 			&ward.LastUpdated,
 			&ward.CreatedAt,
+			&ward.Version,
 		)
 		if err != nil {
 			return nil, apperrors.NewInternalError("failed to scan facility ward", err)
@@ -220,7 +223,7 @@ func (a *FacilityWardAdapter) GetByFacilityIDs(ctx context.Context, facilityIDs
 	query, args, err := a.db.Select(
 		"id", "facility_id", "ward_name", "ward_type",
 		"capacity_status", "avg_wait_minutes", "urgent_care_available",
-		"last_updated", "created_at",
+		"last_updated", "created_at", "version",
 	).From("facility_wards").
 		Where(goqu.Ex{"facility_id": facilityIDs}).
 		Order(goqu.I("facility_id").Asc(), goqu.I("ward_name").Asc()).
@@ -230,7 +233,7 @@ func (a *FacilityWardAdapter) GetByFacilityIDs(ctx context.Context, facilityIDs
 		return nil, apperrors.NewInternalError("failed to build query", err)
 	}
 
-	rows, err := a.client.DB().QueryContext(ctx, query, args...)
+	rows, err := a.client.Conn(ctx).QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, apperrors.NewInternalError("failed to query facility wards", err)
 	}
@@ -255,6 +258,7 @@ func (a *FacilityWardAdapter) GetByFacilityIDs(ctx context.Context, facilityIDs
 			&urgentCareAvailable,
 			&ward.LastUpdated,
 			&ward.CreatedAt,
+			&ward.Version,
 		)
 		if err != nil {
 			return nil, apperrors.NewInternalError("failed to scan facility ward", err)
@@ -289,7 +293,7 @@ func (a *FacilityWardAdapter) GetByFacilityAndWard(ctx context.Context, facility
 	query, args, err := a.db.Select(
 		"id", "facility_id", "ward_name", "ward_type",
 		"capacity_status", "avg_wait_minutes", "urgent_care_available",
-		"last_updated", "created_at",
+		"last_updated", "created_at", "version",
 	).From("facility_wards").
 		Where(goqu.Ex{
 			"facility_id": facilityID,
@@ -306,7 +310,7 @@ func (a *FacilityWardAdapter) GetByFacilityAndWard(ctx context.Context, facility
 	var avgWaitMinutes sql.NullInt64
 	var urgentCareAvailable sql.NullBool
 
-	err = a.client.DB().QueryRowContext(ctx, query, args...).Scan(
+	err = a.client.Conn(ctx).QueryRowContext(ctx, query, args...).Scan(
 		&ward.ID,
 		&ward.FacilityID,
 		&ward.WardName,
@@ -316,6 +320,7 @@ func (a *FacilityWardAdapter) GetByFacilityAndWard(ctx context.Context, facility
 		&urgentCareAvailable,
 		&ward.LastUpdated,
 		&ward.CreatedAt,
+		&ward.Version,
 	)
 
 	if err == sql.ErrNoRows {
@@ -346,7 +351,7 @@ func (a *FacilityWardAdapter) GetByFacilityAndWard(ctx context.Context, facility
 	return ward, nil
 }
 
-// Update updates a facility ward
+// Update updates a facility ward if it is still at the version it was read at
 func (a *FacilityWardAdapter) Update(ctx context.Context, ward *entities.FacilityWard) error {
 	ward.LastUpdated = time.Now()
 
@@ -385,30 +390,24 @@ func (a *FacilityWardAdapter) Update(ctx context.Context, ward *entities.Facilit
 		"avg_wait_minutes":      sql.NullInt64{Int64: avgWaitInt, Valid: avgWaitValid},
 		"urgent_care_available": sql.NullBool{Bool: urgentCareBool, Valid: urgentCareValid},
 		"last_updated":          ward.LastUpdated,
+		"version":               nextVersion,
 	}
 
 	query, args, err := a.db.Update("facility_wards").
 		Set(record).
-		Where(goqu.Ex{"id": ward.ID}).
+		Where(goqu.Ex{"id": ward.ID, "version": ward.Version}).
+		Returning("version").
 		ToSQL()
 
 	if err != nil {
 		return apperrors.NewInternalError("failed to build update query", err)
 	}
 
-	result, err := a.client.DB().ExecContext(ctx, query, args...)
+	version, err := execVersioned(ctx, a.client.Conn(ctx), query, args, "facility_wards", "facility ward", ward.ID)
 	if err != nil {
-		return apperrors.NewInternalError("failed to update facility ward", err)
-	}
-
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return apperrors.NewInternalError("failed to get rows affected", err)
-	}
-
-	if rowsAffected == 0 {
-		return apperrors.NewNotFoundError(fmt.Sprintf("facility ward with id %s not found", ward.ID))
+		return err
 	}
+	ward.Version = version
 
 	return nil
 }
@@ -420,8 +419,26 @@ func hashString(value string) string {
 	return fmt.Sprintf("%x", hasher.Sum32())
 }
 
-// Upsert creates or updates a facility ward (inserts if not exists, updates if exists)
+// Upsert creates a facility ward, or updates it if the stored ward is still
+// at ward.Version (zero when the caller saw no ward)
 func (a *FacilityWardAdapter) Upsert(ctx context.Context, ward *entities.FacilityWard) error {
+	query, args, err := facilityWardUpsertQuery(a.db, ward)
+	if err != nil {
+		return apperrors.NewInternalError("failed to build upsert query", err)
+	}
+
+	version, err := upsertVersioned(ctx, a.client.Conn(ctx), query, args, "facility ward", ward.ID)
+	if err != nil {
+		return err
+	}
+	ward.Version = version
+
+	return nil
+}
+
+// facilityWardUpsertQuery fills in the ward's ID and timestamps and builds its
+// compare-and-swap INSERT ... ON CONFLICT statement returning the version
+func facilityWardUpsertQuery(db *goqu.Database, ward *entities.FacilityWard) (string, []interface{}, error) {
 	// Ensure ID and timestamps are set for insert path
 	if ward.ID == "" {
 		// Generate ward ID using hash of full facilityID + normalized ward name
@@ -500,25 +517,18 @@ func (a *FacilityWardAdapter) Upsert(ctx context.Context, ward *entities.Facilit
 		"avg_wait_minutes":      avgWaitNull,
 		"urgent_care_available": urgentCareNull,
 		"last_updated":          ward.LastUpdated,
+		"version":               goqu.L("facility_wards.version + 1"),
 	}
 
 	// Use INSERT ... ON CONFLICT for atomic upsert
 	// For composite unique key (facility_id, ward_name), pass target as string (goqu.DoUpdate expects target string)
-	query, args, err := a.db.Insert("facility_wards").
+	// The update only applies when the stored ward is at the version the caller read
+	return db.Insert("facility_wards").
 		Rows(record).
-		OnConflict(goqu.DoUpdate("facility_id, ward_name", updateRecord)).
+		OnConflict(goqu.DoUpdate("facility_id, ward_name", updateRecord).
+			Where(goqu.I("facility_wards.version").Eq(ward.Version))).
+		Returning("version").
 		ToSQL()
-
-	if err != nil {
-		return apperrors.NewInternalError("failed to build upsert query", err)
-	}
-
-	_, err = a.client.DB().ExecContext(ctx, query, args...)
-	if err != nil {
-		return apperrors.NewInternalError("failed to execute upsert", err)
-	}
-
-	return nil
 }
 
 // Delete deletes a facility ward
@@ -531,7 +541,7 @@ func (a *FacilityWardAdapter) Delete(ctx context.Context, id string) error {
 		return apperrors.NewInternalError("failed to build delete query", err)
 	}
 
-	result, err := a.client.DB().ExecContext(ctx, query, args...)
+	result, err := a.client.Conn(ctx).ExecContext(ctx, query, args...)
 	if err != nil {
 		return apperrors.NewInternalError("failed to delete facility ward", err)
 	}
@@ -561,7 +571,7 @@ func (a *FacilityWardAdapter) DeleteByFacilityAndWard(ctx context.Context, facil
 		return apperrors.NewInternalError("failed to build delete query", err)
 	}
 
-	result, err := a.client.DB().ExecContext(ctx, query, args...)
+	result, err := a.client.Conn(ctx).ExecContext(ctx, query, args...)
 	if err != nil {
 		return apperrors.NewInternalError("failed to delete facility ward", err)
 	}
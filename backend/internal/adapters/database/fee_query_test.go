@@ -0,0 +1,33 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// A care basket reads the fees of all its facilities in one query per category
+func TestFacilityProceduresByCategoryQuery_ListsEveryFacilityAtOnce(t *testing.T) {
+	db := goqu.Dialect("postgres").DB(nil)
+
+	sql, _, err := facilityProceduresByCategoryQuery(db, []string{"f1", "f2"}, "registration")
+	require.NoError(t, err)
+
+	assert.Contains(t, sql, `("fp"."facility_id" IN ('f1', 'f2'))`)
+	assert.Contains(t, sql, `("p"."category" ILIKE '%registration%')`)
+	assert.Contains(t, sql, `ORDER BY "fp"."facility_id" ASC, "procedure_name" ASC`)
+	assert.NotContains(t, sql, "LIMIT")
+}
+
+func TestActiveFeeWaiversQuery_ReadsEveryFacilityAndGlobalWaivers(t *testing.T) {
+	db := goqu.Dialect("postgres").DB(nil)
+
+	sql, _, err := activeFeeWaiversQuery(db, []string{"f1", "f2"}, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)).ToSQL()
+	require.NoError(t, err)
+
+	assert.Contains(t, sql, `(("facility_id" IN ('f1', 'f2')) OR ("facility_id" IS NULL))`)
+	assert.Contains(t, sql, `ORDER BY "facility_id" DESC NULLS LAST, "created_at" ASC`)
+}
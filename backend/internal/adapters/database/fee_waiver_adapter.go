@@ -52,7 +52,7 @@ func (a *FeeWaiverAdapter) Create(ctx context.Context, waiver *entities.FeeWaive
 		return apperrors.NewInternalError("failed to build insert query", err)
 	}
 
-	_, err = a.client.DB().ExecContext(ctx, query, args...)
+	_, err = a.client.Conn(ctx).ExecContext(ctx, query, args...)
 	if err != nil {
 		return apperrors.NewInternalError("failed to create fee waiver", err)
 	}
@@ -74,27 +74,7 @@ func (a *FeeWaiverAdapter) GetByID(ctx context.Context, id string) (*entities.Fe
 
 // GetActiveFacilityWaiver retrieves the active waiver for a facility (or global waiver)
 func (a *FeeWaiverAdapter) GetActiveFacilityWaiver(ctx context.Context, facilityID string) (*entities.FeeWaiver, error) {
-	now := time.Now()
-
-	query, args, err := a.db.Select("*").From("fee_waivers").
-		Where(
-			goqu.Ex{"is_active": true},
-			goqu.Or(
-				goqu.Ex{"facility_id": facilityID},
-				goqu.Ex{"facility_id": nil},
-			),
-			goqu.I("valid_from").Lte(now),
-			goqu.Or(
-				goqu.Ex{"valid_until": nil},
-				goqu.I("valid_until").Gte(now),
-			),
-			goqu.Or(
-				goqu.Ex{"max_uses": nil},
-				goqu.L("current_uses < max_uses"),
-			),
-		).
-		// Prefer facility-specific waivers over global ones
-		Order(goqu.I("facility_id").Desc().NullsLast()).
+	query, args, err := activeFeeWaiversQuery(a.db, []string{facilityID}, time.Now()).
 		Limit(1).
 		ToSQL()
 
@@ -115,10 +95,82 @@ func (a *FeeWaiverAdapter) GetActiveFacilityWaiver(ctx context.Context, facility
 	return waiver, nil
 }
 
+// GetActiveFacilityWaivers retrieves the active waiver of each facility in a
+// single query, falling back to a global waiver
+func (a *FeeWaiverAdapter) GetActiveFacilityWaivers(ctx context.Context, facilityIDs []string) (map[string]*entities.FeeWaiver, error) {
+	waivers := make(map[string]*entities.FeeWaiver)
+	if len(facilityIDs) == 0 {
+		return waivers, nil
+	}
+
+	query, args, err := activeFeeWaiversQuery(a.db, facilityIDs, time.Now()).ToSQL()
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to build query", err)
+	}
+
+	rows, err := a.client.Conn(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to query fee waivers", err)
+	}
+	defer rows.Close()
+
+	var global *entities.FeeWaiver
+	for rows.Next() {
+		waiver, err := scanFeeWaiverRow(rows)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case waiver.FacilityID == nil:
+			if global == nil {
+				global = waiver
+			}
+		case waivers[*waiver.FacilityID] == nil:
+			waivers[*waiver.FacilityID] = waiver
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, apperrors.NewInternalError("error iterating fee waivers", err)
+	}
+
+	if global != nil {
+		for _, facilityID := range facilityIDs {
+			if waivers[facilityID] == nil {
+				waivers[facilityID] = global
+			}
+		}
+	}
+	return waivers, nil
+}
+
+// activeFeeWaiversQuery selects the waivers the facilities can use at now,
+// global ones included, facility-specific waivers first
+func activeFeeWaiversQuery(db *goqu.Database, facilityIDs []string, now time.Time) *goqu.SelectDataset {
+	return db.Select("*").From("fee_waivers").
+		Where(
+			goqu.Ex{"is_active": true},
+			goqu.Or(
+				goqu.Ex{"facility_id": facilityIDs},
+				goqu.Ex{"facility_id": nil},
+			),
+			goqu.I("valid_from").Lte(now),
+			goqu.Or(
+				goqu.Ex{"valid_until": nil},
+				goqu.I("valid_until").Gte(now),
+			),
+			goqu.Or(
+				goqu.Ex{"max_uses": nil},
+				goqu.L("current_uses < max_uses"),
+			),
+		).
+		// Prefer facility-specific waivers over global ones
+		Order(goqu.I("facility_id").Desc().NullsLast(), goqu.I("created_at").Asc())
+}
+
 // IncrementUsage atomically increments the current_uses counter
 func (a *FeeWaiverAdapter) IncrementUsage(ctx context.Context, id string) error {
 	query := "UPDATE fee_waivers SET current_uses = current_uses + 1, updated_at = $1 WHERE id = $2"
-	_, err := a.client.DB().ExecContext(ctx, query, time.Now(), id)
+	_, err := a.client.Conn(ctx).ExecContext(ctx, query, time.Now(), id)
 	if err != nil {
 		return apperrors.NewInternalError("failed to increment waiver usage", err)
 	}
@@ -151,7 +203,7 @@ func (a *FeeWaiverAdapter) Update(ctx context.Context, waiver *entities.FeeWaive
 		return apperrors.NewInternalError("failed to build update query", err)
 	}
 
-	result, err := a.client.DB().ExecContext(ctx, query, args...)
+	result, err := a.client.Conn(ctx).ExecContext(ctx, query, args...)
 	if err != nil {
 		return apperrors.NewInternalError("failed to update fee waiver", err)
 	}
@@ -169,6 +221,10 @@ func (a *FeeWaiverAdapter) Update(ctx context.Context, waiver *entities.FeeWaive
 }
 
 func (a *FeeWaiverAdapter) scanFeeWaiver(ctx context.Context, query string, args ...interface{}) (*entities.FeeWaiver, error) {
+	return scanFeeWaiverRow(a.client.Conn(ctx).QueryRowContext(ctx, query, args...))
+}
+
+func scanFeeWaiverRow(row rowScanner) (*entities.FeeWaiver, error) {
 	w := &entities.FeeWaiver{}
 	var sponsorContact sql.NullString
 	var facilityID sql.NullString
@@ -176,7 +232,7 @@ func (a *FeeWaiverAdapter) scanFeeWaiver(ctx context.Context, query string, args
 	var maxUses sql.NullInt32
 	var validUntil sql.NullTime
 
-	err := a.client.DB().QueryRowContext(ctx, query, args...).Scan(
+	err := row.Scan(
 		&w.ID,
 		&w.SponsorName,
 		&sponsorContact,
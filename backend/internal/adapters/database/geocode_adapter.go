@@ -0,0 +1,115 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/repositories"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/infrastructure/clients/postgres"
+	apperrors "github.com/zatekoja/Patientpricediscoverydesign/backend/pkg/errors"
+)
+
+// GeocodeAdapter implements the GeocodeRepository interface
+type GeocodeAdapter struct {
+	client *postgres.Client
+}
+
+// NewGeocodeAdapter creates a new geocode adapter
+func NewGeocodeAdapter(client *postgres.Client) repositories.GeocodeRepository {
+	return &GeocodeAdapter{client: client}
+}
+
+// Get returns the stored result for an address key
+func (a *GeocodeAdapter) Get(ctx context.Context, addressKey string) (*entities.GeocodedLocation, error) {
+	location := &entities.GeocodedLocation{}
+	var formatted, street, city, state, zipCode, country sql.NullString
+	var source string
+	err := a.client.DB().QueryRowContext(ctx, `
+		SELECT address_key, address, formatted_address, street, city, state, zip_code, country,
+			latitude, longitude, source, confidence, created_at, updated_at
+		FROM geocoded_addresses
+		WHERE address_key = $1
+	`, addressKey).Scan(
+		&location.AddressKey,
+		&location.Address,
+		&formatted,
+		&street,
+		&city,
+		&state,
+		&zipCode,
+		&country,
+		&location.Location.Latitude,
+		&location.Location.Longitude,
+		&source,
+		&location.Confidence,
+		&location.CreatedAt,
+		&location.UpdatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperrors.NewNotFoundError("geocoded address not found")
+	}
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to get geocoded address", err)
+	}
+	location.FormattedAddress = formatted.String
+	location.Street = street.String
+	location.City = city.String
+	location.State = state.String
+	location.ZipCode = zipCode.String
+	location.Country = country.String
+	location.Source = entities.GeocodeSource(source)
+	return location, nil
+}
+
+// Save upserts the result for its address key, keeping manual entries
+// unless the new entry is manual too
+func (a *GeocodeAdapter) Save(ctx context.Context, location *entities.GeocodedLocation) error {
+	now := time.Now()
+	if location.CreatedAt.IsZero() {
+		location.CreatedAt = now
+	}
+	location.UpdatedAt = now
+
+	_, err := a.client.DB().ExecContext(ctx, `
+		INSERT INTO geocoded_addresses (
+			address_key, address, formatted_address, street, city, state, zip_code, country,
+			latitude, longitude, source, confidence, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		ON CONFLICT (address_key) DO UPDATE SET
+			address = EXCLUDED.address,
+			formatted_address = EXCLUDED.formatted_address,
+			street = EXCLUDED.street,
+			city = EXCLUDED.city,
+			state = EXCLUDED.state,
+			zip_code = EXCLUDED.zip_code,
+			country = EXCLUDED.country,
+			latitude = EXCLUDED.latitude,
+			longitude = EXCLUDED.longitude,
+			source = EXCLUDED.source,
+			confidence = EXCLUDED.confidence,
+			updated_at = EXCLUDED.updated_at
+		WHERE geocoded_addresses.source <> 'manual' OR EXCLUDED.source = 'manual'
+	`,
+		location.AddressKey,
+		location.Address,
+		location.FormattedAddress,
+		location.Street,
+		location.City,
+		location.State,
+		location.ZipCode,
+		location.Country,
+		location.Location.Latitude,
+		location.Location.Longitude,
+		string(location.Source),
+		location.Confidence,
+		location.CreatedAt,
+		location.UpdatedAt,
+	)
+	if err != nil {
+		return apperrors.NewInternalError("failed to save geocoded address", err)
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/repositories"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/infrastructure/clients/postgres"
+	apperrors "github.com/zatekoja/Patientpricediscoverydesign/backend/pkg/errors"
+)
+
+// LoginOTPAdapter implements the LoginOTPRepository interface
+type LoginOTPAdapter struct {
+	client *postgres.Client
+}
+
+// NewLoginOTPAdapter creates a new login code adapter
+func NewLoginOTPAdapter(client *postgres.Client) repositories.LoginOTPRepository {
+	return &LoginOTPAdapter{client: client}
+}
+
+// Create stores a new code
+func (a *LoginOTPAdapter) Create(ctx context.Context, otp *entities.LoginOTP) error {
+	if otp.ID == "" {
+		otp.ID = uuid.New().String()
+	}
+	if otp.CreatedAt.IsZero() {
+		otp.CreatedAt = time.Now().UTC()
+	}
+
+	_, err := a.client.DB().ExecContext(ctx, `
+		INSERT INTO login_otps (id, phone, code_hash, channel, attempts, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, otp.ID, otp.Phone, otp.CodeHash, string(otp.Channel), otp.Attempts, otp.ExpiresAt, otp.CreatedAt)
+	if err != nil {
+		return apperrors.NewInternalError("failed to create login code", err)
+	}
+	return nil
+}
+
+// GetLatest retrieves the most recently issued code for a phone number
+func (a *LoginOTPAdapter) GetLatest(ctx context.Context, phone string) (*entities.LoginOTP, error) {
+	otp := &entities.LoginOTP{}
+	var channel string
+	var consumedAt sql.NullTime
+	err := a.client.DB().QueryRowContext(ctx, `
+		SELECT id, phone, code_hash, channel, attempts, expires_at, consumed_at, created_at
+		FROM login_otps
+		WHERE phone = $1
+		ORDER BY created_at DESC
+		LIMIT 1
+	`, phone).Scan(
+		&otp.ID,
+		&otp.Phone,
+		&otp.CodeHash,
+		&channel,
+		&otp.Attempts,
+		&otp.ExpiresAt,
+		&consumedAt,
+		&otp.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, apperrors.NewNotFoundError("no login code issued for that phone")
+	}
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to get login code", err)
+	}
+
+	otp.Channel = entities.NotificationChannel(channel)
+	if consumedAt.Valid {
+		otp.ConsumedAt = &consumedAt.Time
+	}
+	return otp, nil
+}
+
+// CountSince counts codes issued to a phone number since the given time
+func (a *LoginOTPAdapter) CountSince(ctx context.Context, phone string, since time.Time) (int, error) {
+	var count int
+	err := a.client.DB().QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM login_otps WHERE phone = $1 AND created_at >= $2
+	`, phone, since).Scan(&count)
+	if err != nil {
+		return 0, apperrors.NewInternalError("failed to count login codes", err)
+	}
+	return count, nil
+}
+
+// IncrementAttempts records a verification attempt in the same statement that
+// checks the cap, so parallel guesses cannot all read a count below it
+func (a *LoginOTPAdapter) IncrementAttempts(ctx context.Context, id string, maxAttempts int) (int, error) {
+	var attempts int
+	err := a.client.DB().QueryRowContext(ctx, `
+		UPDATE login_otps SET attempts = attempts + 1
+		WHERE id = $1 AND attempts < $2 AND consumed_at IS NULL
+		RETURNING attempts
+	`, id, maxAttempts).Scan(&attempts)
+	if err == sql.ErrNoRows {
+		return 0, apperrors.NewConflictError("login code is used or out of attempts")
+	}
+	if err != nil {
+		return 0, apperrors.NewInternalError("failed to record login attempt", err)
+	}
+	return attempts, nil
+}
+
+// Consume marks a code as used, once
+func (a *LoginOTPAdapter) Consume(ctx context.Context, id string, maxAttempts int) error {
+	result, err := a.client.DB().ExecContext(ctx, `
+		UPDATE login_otps SET consumed_at = NOW()
+		WHERE id = $1 AND consumed_at IS NULL AND attempts <= $2
+	`, id, maxAttempts)
+	if err != nil {
+		return apperrors.NewInternalError("failed to consume login code", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return apperrors.NewInternalError("failed to get affected rows", err)
+	}
+	if rows == 0 {
+		return apperrors.NewConflictError("login code has already been used")
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/repositories"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/infrastructure/clients/postgres"
+	apperrors "github.com/zatekoja/Patientpricediscoverydesign/backend/pkg/errors"
+)
+
+// NotificationPreferenceAdapter implements the NotificationPreferenceRepository interface
+type NotificationPreferenceAdapter struct {
+	client *postgres.Client
+}
+
+// NewNotificationPreferenceAdapter creates a new notification preference adapter
+func NewNotificationPreferenceAdapter(client *postgres.Client) repositories.NotificationPreferenceRepository {
+	return &NotificationPreferenceAdapter{client: client}
+}
+
+// GetByUserID retrieves a user's preferences
+func (a *NotificationPreferenceAdapter) GetByUserID(ctx context.Context, userID string) (*entities.NotificationPreference, error) {
+	prefs := &entities.NotificationPreference{}
+	var phone, email sql.NullString
+	err := a.client.DB().QueryRowContext(ctx, `
+		SELECT id, user_id, phone, email, whatsapp_enabled, email_enabled, sms_enabled,
+			reminder_24h_enabled, reminder_1h_enabled, created_at, updated_at
+		FROM notification_preferences
+		WHERE user_id = $1
+	`, userID).Scan(
+		&prefs.ID,
+		&prefs.UserID,
+		&phone,
+		&email,
+		&prefs.WhatsAppEnabled,
+		&prefs.EmailEnabled,
+		&prefs.SMSEnabled,
+		&prefs.Reminder24hEnabled,
+		&prefs.Reminder1hEnabled,
+		&prefs.CreatedAt,
+		&prefs.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, apperrors.NewNotFoundError("notification preferences not found")
+	}
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to get notification preferences", err)
+	}
+
+	if phone.Valid {
+		prefs.Phone = &phone.String
+	}
+	if email.Valid {
+		prefs.Email = &email.String
+	}
+	return prefs, nil
+}
+
+// Upsert creates or replaces a user's preferences
+func (a *NotificationPreferenceAdapter) Upsert(ctx context.Context, prefs *entities.NotificationPreference) error {
+	if prefs.ID == "" {
+		prefs.ID = uuid.New().String()
+	}
+	now := time.Now().UTC()
+	if prefs.CreatedAt.IsZero() {
+		prefs.CreatedAt = now
+	}
+	prefs.UpdatedAt = now
+
+	err := a.client.DB().QueryRowContext(ctx, `
+		INSERT INTO notification_preferences
+		(id, user_id, phone, email, whatsapp_enabled, email_enabled, sms_enabled,
+			reminder_24h_enabled, reminder_1h_enabled, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		ON CONFLICT (user_id) DO UPDATE SET
+			phone = EXCLUDED.phone,
+			email = EXCLUDED.email,
+			whatsapp_enabled = EXCLUDED.whatsapp_enabled,
+			email_enabled = EXCLUDED.email_enabled,
+			sms_enabled = EXCLUDED.sms_enabled,
+			reminder_24h_enabled = EXCLUDED.reminder_24h_enabled,
+			reminder_1h_enabled = EXCLUDED.reminder_1h_enabled,
+			updated_at = EXCLUDED.updated_at
+		RETURNING id, created_at
+	`,
+		prefs.ID,
+		prefs.UserID,
+		prefs.Phone,
+		prefs.Email,
+		prefs.WhatsAppEnabled,
+		prefs.EmailEnabled,
+		prefs.SMSEnabled,
+		prefs.Reminder24hEnabled,
+		prefs.Reminder1hEnabled,
+		prefs.CreatedAt,
+		prefs.UpdatedAt,
+	).Scan(&prefs.ID, &prefs.CreatedAt)
+	if err != nil {
+		return apperrors.NewInternalError("failed to save notification preferences", err)
+	}
+	return nil
+}
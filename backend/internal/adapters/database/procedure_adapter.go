@@ -49,7 +49,7 @@ func (a *ProcedureAdapter) Create(ctx context.Context, procedure *entities.Proce
 		return apperrors.NewInternalError("failed to build insert query", err)
 	}
 
-	_, err = a.client.DB().ExecContext(ctx, query, args...)
+	_, err = a.client.Conn(ctx).ExecContext(ctx, query, args...)
 	if err != nil {
 		return apperrors.NewInternalError("failed to create procedure", err)
 	}
@@ -84,7 +84,7 @@ func (a *ProcedureAdapter) GetByIDs(ctx context.Context, ids []string) ([]*entit
 		return nil, apperrors.NewInternalError("failed to build query", err)
 	}
 
-	rows, err := a.client.DB().QueryContext(ctx, query, args...)
+	rows, err := a.client.Conn(ctx).QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, apperrors.NewInternalError("failed to get procedures by ids", err)
 	}
@@ -135,7 +135,7 @@ func (a *ProcedureAdapter) getByField(ctx context.Context, field, value string)
 	procedure := &entities.Procedure{}
 	var category, description sql.NullString
 
-	err = a.client.DB().QueryRowContext(ctx, query, args...).Scan(
+	err = a.client.Conn(ctx).QueryRowContext(ctx, query, args...).Scan(
 		&procedure.ID,
 		&procedure.Name,
 		&procedure.DisplayName,
@@ -185,7 +185,7 @@ func (a *ProcedureAdapter) Update(ctx context.Context, procedure *entities.Proce
 		return apperrors.NewInternalError("failed to build update query", err)
 	}
 
-	result, err := a.client.DB().ExecContext(ctx, query, args...)
+	result, err := a.client.Conn(ctx).ExecContext(ctx, query, args...)
 	if err != nil {
 		return apperrors.NewInternalError("failed to update procedure", err)
 	}
@@ -217,7 +217,7 @@ func (a *ProcedureAdapter) Delete(ctx context.Context, id string) error {
 		return apperrors.NewInternalError("failed to build delete query", err)
 	}
 
-	result, err := a.client.DB().ExecContext(ctx, query, args...)
+	result, err := a.client.Conn(ctx).ExecContext(ctx, query, args...)
 	if err != nil {
 		return apperrors.NewInternalError("failed to delete procedure", err)
 	}
@@ -264,7 +264,7 @@ func (a *ProcedureAdapter) List(ctx context.Context, filter repositories.Procedu
 		return nil, apperrors.NewInternalError("failed to build list query", err)
 	}
 
-	rows, err := a.client.DB().QueryContext(ctx, query, args...)
+	rows, err := a.client.Conn(ctx).QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, apperrors.NewInternalError("failed to list procedures", err)
 	}
@@ -314,8 +314,226 @@ func NewFacilityProcedureAdapter(client *postgres.Client) repositories.FacilityP
 	}
 }
 
+// NewProcedurePriceAdapter creates a facility procedure adapter for price statistics
+func NewProcedurePriceAdapter(client *postgres.Client) repositories.ProcedurePriceRepository {
+	return &FacilityProcedureAdapter{
+		client: client,
+		db:     goqu.New("postgres", client.DB()),
+	}
+}
+
+// PriceStats returns price statistics per procedure across the facilities
+// offering it
+func (a *FacilityProcedureAdapter) PriceStats(ctx context.Context, procedureIDs []string) (map[string]*entities.ProcedurePriceStats, error) {
+	rows, err := a.client.Conn(ctx).QueryContext(ctx, `
+		SELECT procedure_id,
+			COUNT(DISTINCT facility_id),
+			MIN(price),
+			MAX(price),
+			PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY price),
+			COALESCE(MODE() WITHIN GROUP (ORDER BY currency), '')
+		FROM facility_procedures
+		WHERE is_available AND price > 0
+			AND (COALESCE(cardinality($1::text[]), 0) = 0 OR procedure_id = ANY($1))
+		GROUP BY procedure_id
+	`, pq.Array(procedureIDs))
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to aggregate procedure prices", err)
+	}
+	defer rows.Close()
+
+	stats := map[string]*entities.ProcedurePriceStats{}
+	for rows.Next() {
+		s := &entities.ProcedurePriceStats{}
+		if err := rows.Scan(&s.ProcedureID, &s.FacilityCount, &s.MinPrice, &s.MaxPrice, &s.MedianPrice, &s.Currency); err != nil {
+			return nil, apperrors.NewInternalError("failed to scan procedure prices", err)
+		}
+		stats[s.ProcedureID] = s
+	}
+	if err := rows.Err(); err != nil {
+		return nil, apperrors.NewInternalError("error iterating procedure prices", err)
+	}
+	return stats, nil
+}
+
+// NewProcedureOfferAdapter creates a facility procedure adapter for finding
+// procedure offers near a location
+func NewProcedureOfferAdapter(client *postgres.Client) repositories.ProcedureOfferRepository {
+	return &FacilityProcedureAdapter{
+		client: client,
+		db:     goqu.New("postgres", client.DB()),
+	}
+}
+
+// ListOffers returns the facilities offering the procedures within the
+// radius, nearest first
+func (a *FacilityProcedureAdapter) ListOffers(ctx context.Context, params repositories.ProcedureOfferParams) ([]*entities.ProcedureOffer, error) {
+	if len(params.ProcedureIDs) == 0 {
+		return nil, nil
+	}
+	query, args, err := procedureOffersQuery(a.db, params)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to build procedure offers query", err)
+	}
+
+	rows, err := a.client.Conn(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to list procedure offers", err)
+	}
+	defer rows.Close()
+
+	var offers []*entities.ProcedureOffer
+	for rows.Next() {
+		o := &entities.ProcedureOffer{}
+		if err := rows.Scan(
+			&o.FacilityID, &o.FacilityName,
+			&o.Address.Street, &o.Address.City, &o.Address.State, &o.Address.ZipCode, &o.Address.Country,
+			&o.Location.Latitude, &o.Location.Longitude,
+			&o.ProcedureID, &o.ProcedureName, &o.Price, &o.Currency, &o.DistanceKm,
+		); err != nil {
+			return nil, apperrors.NewInternalError("failed to scan procedure offer", err)
+		}
+		offers = append(offers, o)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, apperrors.NewInternalError("error iterating procedure offers", err)
+	}
+	return offers, nil
+}
+
+// procedureOffersQuery builds the SQL for ListOffers
+func procedureOffersQuery(db *goqu.Database, params repositories.ProcedureOfferParams) (string, []interface{}, error) {
+	distance := goqu.L(
+		"(6371 * acos(LEAST(1, cos(radians(?)) * cos(radians(f.latitude)) * cos(radians(f.longitude) - radians(?)) + sin(radians(?)) * sin(radians(f.latitude)))))",
+		params.Latitude, params.Longitude, params.Latitude,
+	)
+
+	conditions := []goqu.Expression{
+		goqu.I("fp.procedure_id").In(params.ProcedureIDs),
+		goqu.L("COALESCE(fp.is_available, TRUE)"),
+		goqu.I("fp.price").Gt(0),
+		goqu.I("f.is_active").IsTrue(),
+		distance.Lte(params.RadiusKm),
+	}
+	if params.InsuranceProvider != "" {
+		conditions = append(conditions, goqu.L(`EXISTS (
+			SELECT 1 FROM facility_insurance fi
+			JOIN insurance_providers ip ON ip.id = fi.insurance_provider_id
+			WHERE fi.facility_id = f.id AND COALESCE(fi.is_accepted, TRUE)
+				AND (LOWER(ip.name) = LOWER(?) OR LOWER(ip.code) = LOWER(?)))`,
+			params.InsuranceProvider, params.InsuranceProvider))
+	}
+
+	ds := db.From(goqu.T("facility_procedures").As("fp")).
+		Join(goqu.T("facilities").As("f"), goqu.On(goqu.I("f.id").Eq(goqu.I("fp.facility_id")))).
+		Join(goqu.T("procedures").As("p"), goqu.On(goqu.I("p.id").Eq(goqu.I("fp.procedure_id")))).
+		Select(
+			"f.id", "f.name",
+			goqu.L("COALESCE(f.street, '')"), goqu.L("COALESCE(f.city, '')"), goqu.L("COALESCE(f.state, '')"),
+			goqu.L("COALESCE(f.zip_code, '')"), goqu.L("COALESCE(f.country, '')"),
+			"f.latitude", "f.longitude",
+			"fp.procedure_id",
+			goqu.L("COALESCE(NULLIF(p.display_name, ''), p.name)"),
+			"fp.price",
+			goqu.L("COALESCE(fp.currency, '')"),
+			distance.As("distance"),
+		).
+		Where(conditions...).
+		Order(goqu.I("distance").Asc(), goqu.I("fp.price").Asc())
+	if params.Limit > 0 {
+		ds = ds.Limit(uint(params.Limit))
+	}
+	return ds.ToSQL()
+}
+
+// NewFacilityProcedureCategoryAdapter creates a facility procedure adapter for
+// listing one category of procedures across many facilities
+func NewFacilityProcedureCategoryAdapter(client *postgres.Client) repositories.FacilityProcedureCategoryRepository {
+	return &FacilityProcedureAdapter{
+		client: client,
+		db:     goqu.New("postgres", client.DB()),
+	}
+}
+
+// ListByFacilitiesAndCategory returns the facilities' active procedures whose
+// category matches, keyed by facility ID
+func (a *FacilityProcedureAdapter) ListByFacilitiesAndCategory(ctx context.Context, facilityIDs []string, category string) (map[string][]*entities.FacilityProcedure, error) {
+	byFacility := make(map[string][]*entities.FacilityProcedure)
+	if len(facilityIDs) == 0 {
+		return byFacility, nil
+	}
+	query, args, err := facilityProceduresByCategoryQuery(a.db, facilityIDs, category)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to build facility procedures query", err)
+	}
+
+	rows, err := a.client.Conn(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to list facility procedures", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		fp := &entities.FacilityProcedure{}
+		var procName, procDisplayName, procCode, procCategory sql.NullString
+		if err := rows.Scan(
+			&fp.ID, &fp.FacilityID, &fp.ProcedureID, &fp.Price, &fp.Currency,
+			&fp.EstimatedDuration, &fp.IsAvailable, &fp.CreatedAt, &fp.UpdatedAt, &fp.Version,
+			&procName, &procDisplayName, &procCode, &procCategory,
+		); err != nil {
+			return nil, apperrors.NewInternalError("failed to scan facility procedure", err)
+		}
+		fp.ProcedureName = procName.String
+		fp.ProcedureDisplayName = procDisplayName.String
+		fp.ProcedureCode = procCode.String
+		fp.ProcedureCategory = procCategory.String
+		byFacility[fp.FacilityID] = append(byFacility[fp.FacilityID], fp)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, apperrors.NewInternalError("error iterating facility procedures", err)
+	}
+	return byFacility, nil
+}
+
+// facilityProceduresByCategoryQuery builds the SQL for
+// ListByFacilitiesAndCategory. The category matches as ListByFacilityWithCount
+// filters it.
+func facilityProceduresByCategoryQuery(db *goqu.Database, facilityIDs []string, category string) (string, []interface{}, error) {
+	procedureName := goqu.COALESCE(goqu.I("p.display_name"), goqu.I("p.name"))
+	return db.From(goqu.T("facility_procedures").As("fp")).
+		Join(goqu.T("procedures").As("p"), goqu.On(goqu.I("fp.procedure_id").Eq(goqu.I("p.id")))).
+		Select(
+			"fp.id", "fp.facility_id", "fp.procedure_id", "fp.price", "fp.currency",
+			"fp.estimated_duration", "fp.is_available", "fp.created_at", "fp.updated_at", "fp.version",
+			procedureName.As("procedure_name"), "p.display_name", "p.code", "p.category",
+		).
+		Where(
+			goqu.I("fp.facility_id").In(facilityIDs),
+			goqu.I("p.is_active").Eq(true),
+			goqu.I("p.category").ILike(fmt.Sprintf("%%%s%%", category)),
+		).
+		Order(goqu.I("fp.facility_id").Asc(), goqu.I("procedure_name").Asc()).
+		ToSQL()
+}
+
 // Create creates a new facility procedure
 func (a *FacilityProcedureAdapter) Create(ctx context.Context, fp *entities.FacilityProcedure) error {
+	query, args, err := facilityProcedureInsertQuery(a.db, fp)
+	if err != nil {
+		return apperrors.NewInternalError("failed to build insert query", err)
+	}
+
+	_, err = a.client.Conn(ctx).ExecContext(ctx, query, args...)
+	if err != nil {
+		return apperrors.NewInternalError("failed to create facility procedure", err)
+	}
+	fp.Version = 1
+
+	return nil
+}
+
+// facilityProcedureInsertQuery builds the facility procedure's INSERT statement
+func facilityProcedureInsertQuery(db *goqu.Database, fp *entities.FacilityProcedure) (string, []interface{}, error) {
 	record := goqu.Record{
 		"id":                 fp.ID,
 		"facility_id":        fp.FacilityID,
@@ -328,24 +546,14 @@ func (a *FacilityProcedureAdapter) Create(ctx context.Context, fp *entities.Faci
 		"updated_at":         fp.UpdatedAt,
 	}
 
-	query, args, err := a.db.Insert("facility_procedures").Rows(record).ToSQL()
-	if err != nil {
-		return apperrors.NewInternalError("failed to build insert query", err)
-	}
-
-	_, err = a.client.DB().ExecContext(ctx, query, args...)
-	if err != nil {
-		return apperrors.NewInternalError("failed to create facility procedure", err)
-	}
-
-	return nil
+	return db.Insert("facility_procedures").Rows(record).ToSQL()
 }
 
 // GetByID retrieves a facility procedure by ID
 func (a *FacilityProcedureAdapter) GetByID(ctx context.Context, id string) (*entities.FacilityProcedure, error) {
 	query, args, err := a.db.Select(
 		"id", "facility_id", "procedure_id", "price", "currency",
-		"estimated_duration", "is_available", "created_at", "updated_at",
+		"estimated_duration", "is_available", "created_at", "updated_at", "version",
 	).From("facility_procedures").
 		Where(goqu.Ex{"id": id}).
 		ToSQL()
@@ -361,7 +569,7 @@ func (a *FacilityProcedureAdapter) GetByID(ctx context.Context, id string) (*ent
 func (a *FacilityProcedureAdapter) GetByFacilityAndProcedure(ctx context.Context, facilityID, procedureID string) (*entities.FacilityProcedure, error) {
 	query, args, err := a.db.Select(
 		"id", "facility_id", "procedure_id", "price", "currency",
-		"estimated_duration", "is_available", "created_at", "updated_at",
+		"estimated_duration", "is_available", "created_at", "updated_at", "version",
 	).From("facility_procedures").
 		Where(goqu.Ex{
 			"facility_id":  facilityID,
@@ -379,7 +587,7 @@ func (a *FacilityProcedureAdapter) GetByFacilityAndProcedure(ctx context.Context
 func (a *FacilityProcedureAdapter) scanFacilityProcedure(ctx context.Context, query string, args ...interface{}) (*entities.FacilityProcedure, error) {
 	fp := &entities.FacilityProcedure{}
 
-	err := a.client.DB().QueryRowContext(ctx, query, args...).Scan(
+	err := a.client.Conn(ctx).QueryRowContext(ctx, query, args...).Scan(
 		&fp.ID,
 		&fp.FacilityID,
 		&fp.ProcedureID,
@@ -389,6 +597,7 @@ func (a *FacilityProcedureAdapter) scanFacilityProcedure(ctx context.Context, qu
 		&fp.IsAvailable,
 		&fp.CreatedAt,
 		&fp.UpdatedAt,
+		&fp.Version,
 	)
 
 	if err == sql.ErrNoRows {
@@ -406,7 +615,7 @@ func (a *FacilityProcedureAdapter) scanFacilityProcedure(ctx context.Context, qu
 func (a *FacilityProcedureAdapter) ListByFacility(ctx context.Context, facilityID string) ([]*entities.FacilityProcedure, error) {
 	query, args, err := a.db.Select(
 		"id", "facility_id", "procedure_id", "price", "currency",
-		"estimated_duration", "is_available", "created_at", "updated_at",
+		"estimated_duration", "is_available", "created_at", "updated_at", "version",
 	).From("facility_procedures").
 		Where(goqu.Ex{"facility_id": facilityID}).
 		ToSQL()
@@ -415,7 +624,7 @@ func (a *FacilityProcedureAdapter) ListByFacility(ctx context.Context, facilityI
 		return nil, apperrors.NewInternalError("failed to build list query", err)
 	}
 
-	rows, err := a.client.DB().QueryContext(ctx, query, args...)
+	rows, err := a.client.Conn(ctx).QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, apperrors.NewInternalError("failed to list facility procedures", err)
 	}
@@ -434,6 +643,7 @@ func (a *FacilityProcedureAdapter) ListByFacility(ctx context.Context, facilityI
 			&fp.IsAvailable,
 			&fp.CreatedAt,
 			&fp.UpdatedAt,
+			&fp.Version,
 		)
 		if err != nil {
 			return nil, apperrors.NewInternalError("failed to scan facility procedure", err)
@@ -516,7 +726,7 @@ func (a *FacilityProcedureAdapter) ListByFacilityWithCount(
 	}
 
 	var totalCount int
-	err = a.client.DB().QueryRowContext(ctx, countSQL, countArgs...).Scan(&totalCount)
+	err = a.client.Conn(ctx).QueryRowContext(ctx, countSQL, countArgs...).Scan(&totalCount)
 	if err != nil {
 		return nil, 0, apperrors.NewInternalError("failed to count filtered procedures", err)
 	}
@@ -524,7 +734,7 @@ func (a *FacilityProcedureAdapter) ListByFacilityWithCount(
 	// Step 4: Apply sorting to filtered data
 	sortedQuery := filteredQuery.Select(
 		"id", "facility_id", "procedure_id", "price", "currency",
-		"estimated_duration", "is_available", "created_at", "updated_at",
+		"estimated_duration", "is_available", "created_at", "updated_at", "version",
 		"procedure_name", "procedure_display_name", "procedure_code", "category", "description", "procedure_normalized_tags",
 	)
 
@@ -577,7 +787,7 @@ func (a *FacilityProcedureAdapter) ListByFacilityWithCount(
 		return nil, 0, apperrors.NewInternalError("failed to build final query", err)
 	}
 
-	rows, err := a.client.DB().QueryContext(ctx, finalSQL, finalArgs...)
+	rows, err := a.client.Conn(ctx).QueryContext(ctx, finalSQL, finalArgs...)
 	if err != nil {
 		return nil, 0, apperrors.NewInternalError("failed to execute paginated query", err)
 	}
@@ -590,7 +800,7 @@ func (a *FacilityProcedureAdapter) ListByFacilityWithCount(
 		var procTags []string
 		err := rows.Scan(
 			&fp.ID, &fp.FacilityID, &fp.ProcedureID, &fp.Price, &fp.Currency,
-			&fp.EstimatedDuration, &fp.IsAvailable, &fp.CreatedAt, &fp.UpdatedAt,
+			&fp.EstimatedDuration, &fp.IsAvailable, &fp.CreatedAt, &fp.UpdatedAt, &fp.Version,
 			&procName, &procDisplayName, &procCode, &procCategory, &procDescription, pq.Array(&procTags),
 		)
 		if err != nil {
@@ -615,8 +825,25 @@ func (a *FacilityProcedureAdapter) ListByFacilityWithCount(
 	return procedures, totalCount, nil
 }
 
-// Update updates a facility procedure
+// Update updates a facility procedure if it is still at the version it was read at
 func (a *FacilityProcedureAdapter) Update(ctx context.Context, fp *entities.FacilityProcedure) error {
+	query, args, err := facilityProcedureUpdateQuery(a.db, fp)
+	if err != nil {
+		return apperrors.NewInternalError("failed to build update query", err)
+	}
+
+	version, err := execVersioned(ctx, a.client.Conn(ctx), query, args, "facility_procedures", "facility procedure", fp.ID)
+	if err != nil {
+		return err
+	}
+	fp.Version = version
+
+	return nil
+}
+
+// facilityProcedureUpdateQuery stamps the facility procedure's update time and
+// builds its compare-and-swap UPDATE statement returning the new version
+func facilityProcedureUpdateQuery(db *goqu.Database, fp *entities.FacilityProcedure) (string, []interface{}, error) {
 	fp.UpdatedAt = time.Now()
 
 	record := goqu.Record{
@@ -625,32 +852,14 @@ func (a *FacilityProcedureAdapter) Update(ctx context.Context, fp *entities.Faci
 		"estimated_duration": fp.EstimatedDuration,
 		"is_available":       fp.IsAvailable,
 		"updated_at":         fp.UpdatedAt,
+		"version":            nextVersion,
 	}
 
-	query, args, err := a.db.Update("facility_procedures").
+	return db.Update("facility_procedures").
 		Set(record).
-		Where(goqu.Ex{"id": fp.ID}).
+		Where(goqu.Ex{"id": fp.ID, "version": fp.Version}).
+		Returning("version").
 		ToSQL()
-
-	if err != nil {
-		return apperrors.NewInternalError("failed to build update query", err)
-	}
-
-	result, err := a.client.DB().ExecContext(ctx, query, args...)
-	if err != nil {
-		return apperrors.NewInternalError("failed to update facility procedure", err)
-	}
-
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return apperrors.NewInternalError("failed to get rows affected", err)
-	}
-
-	if rowsAffected == 0 {
-		return apperrors.NewNotFoundError(fmt.Sprintf("facility procedure with id %s not found", fp.ID))
-	}
-
-	return nil
 }
 
 // Delete deletes a facility procedure
@@ -663,7 +872,7 @@ func (a *FacilityProcedureAdapter) Delete(ctx context.Context, id string) error
 		return apperrors.NewInternalError("failed to build delete query", err)
 	}
 
-	result, err := a.client.DB().ExecContext(ctx, query, args...)
+	result, err := a.client.Conn(ctx).ExecContext(ctx, query, args...)
 	if err != nil {
 		return apperrors.NewInternalError("failed to delete facility procedure", err)
 	}
@@ -0,0 +1,296 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/repositories"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/infrastructure/clients/postgres"
+	apperrors "github.com/zatekoja/Patientpricediscoverydesign/backend/pkg/errors"
+)
+
+const reviewSelect = `
+	SELECT id, user_id, facility_id, appointment_id, reviewer_name, rating, comment, status,
+		moderation_flags, moderation_note, moderated_at, facility_response, responded_at,
+		created_at, updated_at
+	FROM reviews
+`
+
+// ReviewAdapter implements the ReviewRepository interface
+type ReviewAdapter struct {
+	client *postgres.Client
+}
+
+// NewReviewAdapter creates a new review adapter
+func NewReviewAdapter(client *postgres.Client) repositories.ReviewRepository {
+	return &ReviewAdapter{client: client}
+}
+
+// Create creates a new review
+func (a *ReviewAdapter) Create(ctx context.Context, review *entities.Review) error {
+	if review.ID == "" {
+		review.ID = uuid.New().String()
+	}
+	if review.Status == "" {
+		review.Status = entities.ReviewStatusPending
+	}
+	now := time.Now().UTC()
+	review.CreatedAt = now
+	review.UpdatedAt = now
+
+	return a.withFacilityRating(ctx, review.FacilityID, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO reviews
+			(id, user_id, facility_id, appointment_id, reviewer_name, rating, comment, status,
+				moderation_flags, moderation_note, moderated_at, facility_response, responded_at, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+		`,
+			review.ID,
+			nullString(review.UserID),
+			review.FacilityID,
+			nullString(review.AppointmentID),
+			nullString(review.ReviewerName),
+			review.Rating,
+			review.Comment,
+			string(review.Status),
+			pq.Array(nonNilStrings(review.ModerationFlags)),
+			nullString(review.ModerationNote),
+			review.ModeratedAt,
+			nullString(review.FacilityResponse),
+			review.RespondedAt,
+			review.CreatedAt,
+			review.UpdatedAt,
+		)
+		// 23505 is unique_violation on the one-review-per-appointment index
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return apperrors.NewConflictError("a review already exists for this appointment")
+		}
+		if err != nil {
+			return apperrors.NewInternalError("failed to create review", err)
+		}
+		return nil
+	})
+}
+
+// GetByID retrieves a review by ID
+func (a *ReviewAdapter) GetByID(ctx context.Context, id string) (*entities.Review, error) {
+	review, err := scanReview(a.client.Conn(ctx).QueryRowContext(ctx, reviewSelect+` WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, apperrors.NewNotFoundError(fmt.Sprintf("review with id %s not found", id))
+	}
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to get review", err)
+	}
+	return review, nil
+}
+
+// GetByAppointmentID retrieves the review left for an appointment
+func (a *ReviewAdapter) GetByAppointmentID(ctx context.Context, appointmentID string) (*entities.Review, error) {
+	review, err := scanReview(a.client.Conn(ctx).QueryRowContext(ctx, reviewSelect+` WHERE appointment_id = $1`, appointmentID))
+	if err == sql.ErrNoRows {
+		return nil, apperrors.NewNotFoundError(fmt.Sprintf("review for appointment %s not found", appointmentID))
+	}
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to get review", err)
+	}
+	return review, nil
+}
+
+// ListByFacility retrieves published reviews for a facility, newest first
+func (a *ReviewAdapter) ListByFacility(ctx context.Context, facilityID string, limit, offset int) ([]*entities.Review, error) {
+	return a.list(ctx, reviewSelect+`
+		WHERE facility_id = $1 AND status = $2
+		ORDER BY created_at DESC, id
+		LIMIT $3 OFFSET $4
+	`, facilityID, string(entities.ReviewStatusPublished), limit, offset)
+}
+
+// ListByStatus retrieves reviews in a moderation status, oldest first
+func (a *ReviewAdapter) ListByStatus(ctx context.Context, status entities.ReviewStatus, limit, offset int) ([]*entities.Review, error) {
+	return a.list(ctx, reviewSelect+`
+		WHERE status = $1
+		ORDER BY created_at, id
+		LIMIT $2 OFFSET $3
+	`, string(status), limit, offset)
+}
+
+// ListByUser retrieves reviews by a user
+func (a *ReviewAdapter) ListByUser(ctx context.Context, userID string, limit, offset int) ([]*entities.Review, error) {
+	return a.list(ctx, reviewSelect+`
+		WHERE user_id = $1
+		ORDER BY created_at DESC, id
+		LIMIT $2 OFFSET $3
+	`, userID, limit, offset)
+}
+
+// Update updates a review's content, moderation state and facility response
+func (a *ReviewAdapter) Update(ctx context.Context, review *entities.Review) error {
+	review.UpdatedAt = time.Now().UTC()
+
+	return a.withFacilityRating(ctx, review.FacilityID, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, `
+			UPDATE reviews
+			SET rating = $2, comment = $3, status = $4, moderation_flags = $5, moderation_note = $6,
+				moderated_at = $7, facility_response = $8, responded_at = $9, updated_at = $10
+			WHERE id = $1
+		`,
+			review.ID,
+			review.Rating,
+			review.Comment,
+			string(review.Status),
+			pq.Array(nonNilStrings(review.ModerationFlags)),
+			nullString(review.ModerationNote),
+			review.ModeratedAt,
+			nullString(review.FacilityResponse),
+			review.RespondedAt,
+			review.UpdatedAt,
+		)
+		if err != nil {
+			return apperrors.NewInternalError("failed to update review", err)
+		}
+		return requireRowAffected(result, fmt.Sprintf("review with id %s not found", review.ID))
+	})
+}
+
+// Delete deletes a review
+func (a *ReviewAdapter) Delete(ctx context.Context, id string) error {
+	review, err := a.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return a.withFacilityRating(ctx, review.FacilityID, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, `DELETE FROM reviews WHERE id = $1`, id)
+		if err != nil {
+			return apperrors.NewInternalError("failed to delete review", err)
+		}
+		return requireRowAffected(result, fmt.Sprintf("review with id %s not found", id))
+	})
+}
+
+// withFacilityRating runs fn and then recomputes the facility's rating and
+// review count in one transaction, joining the caller's if there is one. The
+// facility row is locked first so concurrent review writes recompute one after
+// another and none is missed.
+func (a *ReviewAdapter) withFacilityRating(ctx context.Context, facilityID string, fn func(tx *sql.Tx) error) error {
+	return a.client.InTx(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		var locked string
+		err := tx.QueryRowContext(ctx, `SELECT id FROM facilities WHERE id = $1 FOR UPDATE`, facilityID).Scan(&locked)
+		if err == sql.ErrNoRows {
+			return apperrors.NewNotFoundError(fmt.Sprintf("facility with id %s not found", facilityID))
+		}
+		if err != nil {
+			return apperrors.NewInternalError("failed to lock facility", err)
+		}
+
+		if err := fn(tx); err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			UPDATE facilities
+			SET rating = COALESCE((
+					SELECT ROUND(AVG(rating)::numeric, 2) FROM reviews WHERE facility_id = $1 AND status = $2
+				), 0),
+				review_count = (SELECT COUNT(*) FROM reviews WHERE facility_id = $1 AND status = $2),
+				updated_at = NOW(),
+				version = version + 1
+			WHERE id = $1
+		`, facilityID, string(entities.ReviewStatusPublished))
+		if err != nil {
+			return apperrors.NewInternalError("failed to recompute facility rating", err)
+		}
+		return nil
+	})
+}
+
+func (a *ReviewAdapter) list(ctx context.Context, query string, args ...interface{}) ([]*entities.Review, error) {
+	rows, err := a.client.Conn(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to list reviews", err)
+	}
+	defer rows.Close()
+
+	var reviews []*entities.Review
+	for rows.Next() {
+		review, err := scanReview(rows)
+		if err != nil {
+			return nil, apperrors.NewInternalError("failed to scan review", err)
+		}
+		reviews = append(reviews, review)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, apperrors.NewInternalError("failed to iterate reviews", err)
+	}
+	return reviews, nil
+}
+
+func scanReview(row rowScanner) (*entities.Review, error) {
+	r := &entities.Review{}
+	var userID, appointmentID, reviewerName, comment, note, response sql.NullString
+	var status string
+	var moderatedAt, respondedAt sql.NullTime
+	err := row.Scan(
+		&r.ID,
+		&userID,
+		&r.FacilityID,
+		&appointmentID,
+		&reviewerName,
+		&r.Rating,
+		&comment,
+		&status,
+		pq.Array(&r.ModerationFlags),
+		&note,
+		&moderatedAt,
+		&response,
+		&respondedAt,
+		&r.CreatedAt,
+		&r.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	r.UserID = userID.String
+	r.AppointmentID = appointmentID.String
+	r.ReviewerName = reviewerName.String
+	r.Comment = comment.String
+	r.Status = entities.ReviewStatus(status)
+	r.ModerationNote = note.String
+	r.FacilityResponse = response.String
+	if moderatedAt.Valid {
+		r.ModeratedAt = &moderatedAt.Time
+	}
+	if respondedAt.Valid {
+		r.RespondedAt = &respondedAt.Time
+	}
+	return r, nil
+}
+
+func requireRowAffected(result sql.Result, notFound string) error {
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return apperrors.NewInternalError("failed to get affected rows", err)
+	}
+	if rows == 0 {
+		return apperrors.NewNotFoundError(notFound)
+	}
+	return nil
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
@@ -0,0 +1,79 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/repositories"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/infrastructure/clients/postgres"
+	apperrors "github.com/zatekoja/Patientpricediscoverydesign/backend/pkg/errors"
+)
+
+// SavedFacilityAdapter implements the SavedFacilityRepository interface
+type SavedFacilityAdapter struct {
+	client *postgres.Client
+}
+
+// NewSavedFacilityAdapter creates a new saved facility adapter
+func NewSavedFacilityAdapter(client *postgres.Client) repositories.SavedFacilityRepository {
+	return &SavedFacilityAdapter{client: client}
+}
+
+// Save adds a facility to the user's saved list
+func (a *SavedFacilityAdapter) Save(ctx context.Context, userID, facilityID string) error {
+	_, err := a.client.DB().ExecContext(ctx, `
+		INSERT INTO saved_facilities (user_id, facility_id, created_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (user_id, facility_id) DO NOTHING
+	`, userID, facilityID)
+	// 23503 is foreign_key_violation: the facility does not exist
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23503" {
+		return apperrors.NewNotFoundError(fmt.Sprintf("facility with id %s not found", facilityID))
+	}
+	if err != nil {
+		return apperrors.NewInternalError("failed to save facility", err)
+	}
+	return nil
+}
+
+// Remove removes a facility from the user's saved list
+func (a *SavedFacilityAdapter) Remove(ctx context.Context, userID, facilityID string) error {
+	result, err := a.client.DB().ExecContext(ctx, `
+		DELETE FROM saved_facilities WHERE user_id = $1 AND facility_id = $2
+	`, userID, facilityID)
+	if err != nil {
+		return apperrors.NewInternalError("failed to remove saved facility", err)
+	}
+	return requireRowAffected(result, fmt.Sprintf("facility with id %s is not saved", facilityID))
+}
+
+// ListByUser retrieves saved facilities, most recently saved first
+func (a *SavedFacilityAdapter) ListByUser(ctx context.Context, userID string) ([]*entities.SavedFacility, error) {
+	rows, err := a.client.DB().QueryContext(ctx, `
+		SELECT user_id, facility_id, created_at
+		FROM saved_facilities
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+	`, userID)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to list saved facilities", err)
+	}
+	defer rows.Close()
+
+	var saved []*entities.SavedFacility
+	for rows.Next() {
+		item := &entities.SavedFacility{}
+		if err := rows.Scan(&item.UserID, &item.FacilityID, &item.CreatedAt); err != nil {
+			return nil, apperrors.NewInternalError("failed to scan saved facility", err)
+		}
+		saved = append(saved, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, apperrors.NewInternalError("failed to iterate saved facilities", err)
+	}
+	return saved, nil
+}
@@ -2,7 +2,9 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
 	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/repositories"
 	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/infrastructure/clients/postgres"
@@ -10,6 +12,8 @@ import (
 	"time"
 )
 
+const searchEventColumns = `id, query, normalized_query, detected_language, detected_intent, intent_confidence, result_count, latency_ms, user_latitude, user_longitude, session_id, experiment_id, experiment_variant, result_facility_ids, result_offset, created_at`
+
 type SearchAnalyticsAdapter struct {
 	client *postgres.Client
 }
@@ -28,14 +32,15 @@ func (a *SearchAnalyticsAdapter) LogEvent(ctx context.Context, event *entities.S
 
 	query := `
 		INSERT INTO search_analytics 
-		(id, query, normalized_query, detected_intent, intent_confidence, result_count, latency_ms, user_latitude, user_longitude, session_id, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		(id, query, normalized_query, detected_language, detected_intent, intent_confidence, result_count, latency_ms, user_latitude, user_longitude, session_id, experiment_id, experiment_variant, result_facility_ids, result_offset, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
 	`
 
 	_, err := a.client.DB().ExecContext(ctx, query,
 		event.ID,
 		event.Query,
 		event.NormalizedQuery,
+		sql.NullString{String: event.DetectedLanguage, Valid: event.DetectedLanguage != ""},
 		event.DetectedIntent,
 		event.IntentConfidence,
 		event.ResultCount,
@@ -43,6 +48,10 @@ func (a *SearchAnalyticsAdapter) LogEvent(ctx context.Context, event *entities.S
 		event.UserLatitude,
 		event.UserLongitude,
 		event.SessionID,
+		sql.NullString{String: event.ExperimentID, Valid: event.ExperimentID != ""},
+		sql.NullString{String: event.ExperimentVariant, Valid: event.ExperimentVariant != ""},
+		pq.Array(event.ResultFacilityIDs),
+		event.ResultOffset,
 		event.CreatedAt,
 	)
 
@@ -53,13 +62,34 @@ func (a *SearchAnalyticsAdapter) LogEvent(ctx context.Context, event *entities.S
 	return nil
 }
 
+func (a *SearchAnalyticsAdapter) CopyEvent(ctx context.Context, sourceID, newID string) (bool, error) {
+	query := `
+		INSERT INTO search_analytics
+		(id, query, normalized_query, detected_language, detected_intent, intent_confidence, result_count, latency_ms, user_latitude, user_longitude, session_id, experiment_id, experiment_variant, result_facility_ids, result_offset, created_at)
+		SELECT $2, query, normalized_query, detected_language, detected_intent, intent_confidence, result_count, latency_ms, user_latitude, user_longitude, session_id, experiment_id, experiment_variant, result_facility_ids, result_offset, NOW()
+		FROM search_analytics
+		WHERE id = $1
+	`
+
+	result, err := a.client.DB().ExecContext(ctx, query, sourceID, newID)
+	if err != nil {
+		return false, apperrors.NewInternalError("failed to copy search event", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, apperrors.NewInternalError("failed to get affected rows", err)
+	}
+
+	return rows > 0, nil
+}
+
 func (a *SearchAnalyticsAdapter) GetZeroResultQueries(ctx context.Context, limit int) ([]*entities.SearchEvent, error) {
 	if limit <= 0 {
 		limit = 100
 	}
 
 	query := `
-		SELECT id, query, normalized_query, detected_intent, intent_confidence, result_count, latency_ms, user_latitude, user_longitude, session_id, created_at
+		SELECT ` + searchEventColumns + `
 		FROM search_analytics
 		WHERE result_count = 0
 		ORDER BY created_at DESC
@@ -72,27 +102,140 @@ func (a *SearchAnalyticsAdapter) GetZeroResultQueries(ctx context.Context, limit
 	}
 	defer rows.Close()
 
+	return scanSearchEvents(rows)
+}
+
+func (a *SearchAnalyticsAdapter) GetLowResultQueries(ctx context.Context, maxResultCount int, since time.Time, limit int) ([]*entities.SearchEvent, error) {
+	if limit <= 0 {
+		limit = 1000
+	}
+	if maxResultCount < 0 {
+		maxResultCount = 0
+	}
+
+	query := `
+		SELECT ` + searchEventColumns + `
+		FROM search_analytics
+		WHERE result_count <= $1 AND created_at >= $2
+		ORDER BY created_at DESC
+		LIMIT $3
+	`
+
+	rows, err := a.client.DB().QueryContext(ctx, query, maxResultCount, since, limit)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to get low result queries", err)
+	}
+	defer rows.Close()
+
+	return scanSearchEvents(rows)
+}
+
+func (a *SearchAnalyticsAdapter) GetQueryOutcome(ctx context.Context, normalizedQuery string, since time.Time) (*entities.QueryOutcome, error) {
+	query := `
+		SELECT COUNT(*), COUNT(*) FILTER (WHERE result_count > 0)
+		FROM search_analytics
+		WHERE normalized_query = $1 AND created_at >= $2
+	`
+
+	outcome := &entities.QueryOutcome{}
+	err := a.client.DB().QueryRowContext(ctx, query, normalizedQuery, since).Scan(&outcome.Searches, &outcome.WithResult)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to get query outcome", err)
+	}
+
+	return outcome, nil
+}
+
+func (a *SearchAnalyticsAdapter) GetExperimentVariantStats(ctx context.Context, experimentID string) ([]*entities.ExperimentVariantStats, error) {
+	query := `
+		SELECT experiment_variant,
+			COUNT(DISTINCT session_id),
+			COUNT(*),
+			COUNT(*) FILTER (WHERE result_count = 0),
+			COALESCE(AVG(latency_ms), 0),
+			COALESCE(PERCENTILE_CONT(0.95) WITHIN GROUP (ORDER BY latency_ms), 0),
+			COUNT(*) FILTER (WHERE EXISTS (
+				SELECT 1 FROM search_interactions si
+				WHERE si.impression_id = sa.id AND si.event_type = 'result_click'
+			))
+		FROM search_analytics sa
+		WHERE experiment_id = $1
+		GROUP BY experiment_variant
+		ORDER BY experiment_variant
+	`
+
+	rows, err := a.client.DB().QueryContext(ctx, query, experimentID)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to get experiment variant stats", err)
+	}
+	defer rows.Close()
+
+	var stats []*entities.ExperimentVariantStats
+	for rows.Next() {
+		s := &entities.ExperimentVariantStats{}
+		var variant sql.NullString
+		var clickedSearches int
+		if err := rows.Scan(&variant, &s.Sessions, &s.Searches, &s.ZeroResults, &s.AvgLatencyMs, &s.P95LatencyMs, &clickedSearches); err != nil {
+			return nil, apperrors.NewInternalError("failed to scan experiment variant stats", err)
+		}
+		s.Variant = variant.String
+		if s.Searches > 0 {
+			s.ZeroResultRate = float64(s.ZeroResults) / float64(s.Searches)
+			ctr := float64(clickedSearches) / float64(s.Searches)
+			s.ClickThroughRate = &ctr
+		}
+		stats = append(stats, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, apperrors.NewInternalError("failed to iterate experiment variant stats", err)
+	}
+
+	return stats, nil
+}
+
+func scanSearchEvents(rows *sql.Rows) ([]*entities.SearchEvent, error) {
 	var events []*entities.SearchEvent
 	for rows.Next() {
 		e := &entities.SearchEvent{}
+		var normalizedQuery, detectedLanguage, detectedIntent, sessionID, experimentID, experimentVariant sql.NullString
+		var intentConfidence, userLatitude, userLongitude sql.NullFloat64
+		var latencyMs sql.NullInt64
 		err := rows.Scan(
 			&e.ID,
 			&e.Query,
-			&e.NormalizedQuery,
-			&e.DetectedIntent,
-			&e.IntentConfidence,
+			&normalizedQuery,
+			&detectedLanguage,
+			&detectedIntent,
+			&intentConfidence,
 			&e.ResultCount,
-			&e.LatencyMs,
-			&e.UserLatitude,
-			&e.UserLongitude,
-			&e.SessionID,
+			&latencyMs,
+			&userLatitude,
+			&userLongitude,
+			&sessionID,
+			&experimentID,
+			&experimentVariant,
+			pq.Array(&e.ResultFacilityIDs),
+			&e.ResultOffset,
 			&e.CreatedAt,
 		)
 		if err != nil {
 			return nil, apperrors.NewInternalError("failed to scan search event", err)
 		}
+		e.NormalizedQuery = normalizedQuery.String
+		e.DetectedLanguage = detectedLanguage.String
+		e.DetectedIntent = detectedIntent.String
+		e.IntentConfidence = intentConfidence.Float64
+		e.LatencyMs = int(latencyMs.Int64)
+		e.UserLatitude = userLatitude.Float64
+		e.UserLongitude = userLongitude.Float64
+		e.SessionID = sessionID.String
+		e.ExperimentID = experimentID.String
+		e.ExperimentVariant = experimentVariant.String
 		events = append(events, e)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, apperrors.NewInternalError("failed to iterate search events", err)
+	}
 
 	return events, nil
 }
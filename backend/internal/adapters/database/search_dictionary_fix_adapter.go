@@ -0,0 +1,169 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/repositories"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/infrastructure/clients/postgres"
+	apperrors "github.com/zatekoja/Patientpricediscoverydesign/backend/pkg/errors"
+)
+
+// SearchDictionaryFixAdapter persists triage dictionary fixes in Postgres.
+type SearchDictionaryFixAdapter struct {
+	client *postgres.Client
+}
+
+// NewSearchDictionaryFixAdapter creates a new dictionary fix adapter.
+func NewSearchDictionaryFixAdapter(client *postgres.Client) repositories.SearchDictionaryFixRepository {
+	return &SearchDictionaryFixAdapter{client: client}
+}
+
+// Create inserts a fix, replacing any earlier fix for the same term and type.
+func (a *SearchDictionaryFixAdapter) Create(ctx context.Context, fix *entities.SearchDictionaryFix) error {
+	if fix == nil {
+		return apperrors.NewInternalError("dictionary fix is nil", fmt.Errorf("dictionary fix is nil"))
+	}
+	if fix.ID == "" {
+		fix.ID = uuid.New().String()
+	}
+	now := time.Now().UTC()
+	if fix.CreatedAt.IsZero() {
+		fix.CreatedAt = now
+	}
+	fix.UpdatedAt = now
+	if fix.Status == "" {
+		fix.Status = entities.DictionaryFixStatusPending
+	}
+
+	query := `
+		INSERT INTO search_dictionary_fixes
+		(id, normalized_query, fix_type, term, target, accepted_by, status, searches_after, successful_after, last_checked_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		ON CONFLICT (fix_type, term) DO UPDATE SET
+			normalized_query = EXCLUDED.normalized_query,
+			target = EXCLUDED.target,
+			accepted_by = EXCLUDED.accepted_by,
+			status = EXCLUDED.status,
+			searches_after = 0,
+			successful_after = 0,
+			last_checked_at = NULL,
+			created_at = EXCLUDED.created_at,
+			updated_at = EXCLUDED.updated_at
+		RETURNING id
+	`
+
+	err := a.client.Conn(ctx).QueryRowContext(ctx, query,
+		fix.ID,
+		fix.NormalizedQuery,
+		string(fix.FixType),
+		fix.Term,
+		fix.Target,
+		sql.NullString{String: fix.AcceptedBy, Valid: fix.AcceptedBy != ""},
+		string(fix.Status),
+		fix.SearchesAfter,
+		fix.SuccessfulAfter,
+		fix.LastCheckedAt,
+		fix.CreatedAt,
+		fix.UpdatedAt,
+	).Scan(&fix.ID)
+	if err != nil {
+		return apperrors.NewInternalError("failed to create dictionary fix", err)
+	}
+
+	return nil
+}
+
+// Update stores the tracked outcome of a fix.
+func (a *SearchDictionaryFixAdapter) Update(ctx context.Context, fix *entities.SearchDictionaryFix) error {
+	if fix == nil {
+		return apperrors.NewInternalError("dictionary fix is nil", fmt.Errorf("dictionary fix is nil"))
+	}
+	fix.UpdatedAt = time.Now().UTC()
+
+	query := `
+		UPDATE search_dictionary_fixes
+		SET status = $2, searches_after = $3, successful_after = $4, last_checked_at = $5, updated_at = $6
+		WHERE id = $1
+	`
+
+	result, err := a.client.Conn(ctx).ExecContext(ctx, query,
+		fix.ID,
+		string(fix.Status),
+		fix.SearchesAfter,
+		fix.SuccessfulAfter,
+		fix.LastCheckedAt,
+		fix.UpdatedAt,
+	)
+	if err != nil {
+		return apperrors.NewInternalError("failed to update dictionary fix", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return apperrors.NewInternalError("failed to get affected rows", err)
+	}
+	if rows == 0 {
+		return apperrors.NewNotFoundError(fmt.Sprintf("dictionary fix with id %s not found", fix.ID))
+	}
+
+	return nil
+}
+
+// List returns fixes, optionally filtered by status, newest first.
+func (a *SearchDictionaryFixAdapter) List(ctx context.Context, status entities.DictionaryFixStatus) ([]*entities.SearchDictionaryFix, error) {
+	query := `
+		SELECT id, normalized_query, fix_type, term, target, accepted_by, status, searches_after, successful_after, last_checked_at, created_at, updated_at
+		FROM search_dictionary_fixes
+		WHERE ($1 = '' OR status = $1)
+		ORDER BY created_at DESC
+	`
+
+	rows, err := a.client.Conn(ctx).QueryContext(ctx, query, string(status))
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to list dictionary fixes", err)
+	}
+	defer rows.Close()
+
+	var fixes []*entities.SearchDictionaryFix
+	for rows.Next() {
+		fix := &entities.SearchDictionaryFix{}
+		var fixType, fixStatus string
+		var acceptedBy sql.NullString
+		var lastCheckedAt sql.NullTime
+		err := rows.Scan(
+			&fix.ID,
+			&fix.NormalizedQuery,
+			&fixType,
+			&fix.Term,
+			&fix.Target,
+			&acceptedBy,
+			&fixStatus,
+			&fix.SearchesAfter,
+			&fix.SuccessfulAfter,
+			&lastCheckedAt,
+			&fix.CreatedAt,
+			&fix.UpdatedAt,
+		)
+		if err != nil {
+			return nil, apperrors.NewInternalError("failed to scan dictionary fix", err)
+		}
+		fix.FixType = entities.DictionaryFixType(fixType)
+		fix.Status = entities.DictionaryFixStatus(fixStatus)
+		fix.AcceptedBy = acceptedBy.String
+		if lastCheckedAt.Valid {
+			t := lastCheckedAt.Time
+			fix.LastCheckedAt = &t
+		}
+		fixes = append(fixes, fix)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, apperrors.NewInternalError("failed to iterate dictionary fixes", err)
+	}
+
+	return fixes, nil
+}
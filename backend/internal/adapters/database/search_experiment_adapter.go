@@ -0,0 +1,185 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/repositories"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/infrastructure/clients/postgres"
+	apperrors "github.com/zatekoja/Patientpricediscoverydesign/backend/pkg/errors"
+)
+
+const searchExperimentColumns = `id, name, description, status, traffic_percent, variants, started_at, stopped_at, created_at, updated_at`
+
+// SearchExperimentAdapter persists search experiments in Postgres.
+type SearchExperimentAdapter struct {
+	client *postgres.Client
+}
+
+// NewSearchExperimentAdapter creates a new search experiment adapter.
+func NewSearchExperimentAdapter(client *postgres.Client) repositories.SearchExperimentRepository {
+	return &SearchExperimentAdapter{client: client}
+}
+
+// Create inserts a new experiment.
+func (a *SearchExperimentAdapter) Create(ctx context.Context, experiment *entities.SearchExperiment) error {
+	if experiment == nil {
+		return apperrors.NewInternalError("experiment is nil", fmt.Errorf("experiment is nil"))
+	}
+	if experiment.ID == "" {
+		experiment.ID = uuid.New().String()
+	}
+	now := time.Now().UTC()
+	experiment.CreatedAt = now
+	experiment.UpdatedAt = now
+
+	variants, err := json.Marshal(experiment.Variants)
+	if err != nil {
+		return apperrors.NewInternalError("failed to encode experiment variants", err)
+	}
+
+	query := `
+		INSERT INTO search_experiments
+		(id, name, description, status, traffic_percent, variants, started_at, stopped_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+
+	_, err = a.client.Conn(ctx).ExecContext(ctx, query,
+		experiment.ID,
+		experiment.Name,
+		sql.NullString{String: experiment.Description, Valid: experiment.Description != ""},
+		string(experiment.Status),
+		experiment.TrafficPercent,
+		variants,
+		experiment.StartedAt,
+		experiment.StoppedAt,
+		experiment.CreatedAt,
+		experiment.UpdatedAt,
+	)
+	if err != nil {
+		return apperrors.NewInternalError("failed to create experiment", err)
+	}
+
+	return nil
+}
+
+// Update stores the experiment's status and timestamps.
+func (a *SearchExperimentAdapter) Update(ctx context.Context, experiment *entities.SearchExperiment) error {
+	if experiment == nil {
+		return apperrors.NewInternalError("experiment is nil", fmt.Errorf("experiment is nil"))
+	}
+	experiment.UpdatedAt = time.Now().UTC()
+
+	query := `
+		UPDATE search_experiments
+		SET status = $2, started_at = $3, stopped_at = $4, updated_at = $5
+		WHERE id = $1
+	`
+
+	result, err := a.client.Conn(ctx).ExecContext(ctx, query,
+		experiment.ID,
+		string(experiment.Status),
+		experiment.StartedAt,
+		experiment.StoppedAt,
+		experiment.UpdatedAt,
+	)
+	if err != nil {
+		return apperrors.NewInternalError("failed to update experiment", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return apperrors.NewInternalError("failed to get affected rows", err)
+	}
+	if rows == 0 {
+		return apperrors.NewNotFoundError(fmt.Sprintf("experiment with id %s not found", experiment.ID))
+	}
+
+	return nil
+}
+
+// GetByID retrieves an experiment by ID.
+func (a *SearchExperimentAdapter) GetByID(ctx context.Context, id string) (*entities.SearchExperiment, error) {
+	query := `SELECT ` + searchExperimentColumns + ` FROM search_experiments WHERE id = $1`
+
+	experiment, err := scanSearchExperiment(a.client.Conn(ctx).QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, apperrors.NewNotFoundError(fmt.Sprintf("experiment with id %s not found", id))
+	}
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to get experiment", err)
+	}
+
+	return experiment, nil
+}
+
+// List returns all experiments, newest first.
+func (a *SearchExperimentAdapter) List(ctx context.Context) ([]*entities.SearchExperiment, error) {
+	query := `SELECT ` + searchExperimentColumns + ` FROM search_experiments ORDER BY created_at DESC`
+
+	rows, err := a.client.Conn(ctx).QueryContext(ctx, query)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to list experiments", err)
+	}
+	defer rows.Close()
+
+	var experiments []*entities.SearchExperiment
+	for rows.Next() {
+		experiment, err := scanSearchExperiment(rows)
+		if err != nil {
+			return nil, apperrors.NewInternalError("failed to scan experiment", err)
+		}
+		experiments = append(experiments, experiment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, apperrors.NewInternalError("failed to iterate experiments", err)
+	}
+
+	return experiments, nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanSearchExperiment(row rowScanner) (*entities.SearchExperiment, error) {
+	e := &entities.SearchExperiment{}
+	var description sql.NullString
+	var status string
+	var variants []byte
+	var startedAt, stoppedAt sql.NullTime
+	err := row.Scan(
+		&e.ID,
+		&e.Name,
+		&description,
+		&status,
+		&e.TrafficPercent,
+		&variants,
+		&startedAt,
+		&stoppedAt,
+		&e.CreatedAt,
+		&e.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	e.Description = description.String
+	e.Status = entities.ExperimentStatus(status)
+	if err := json.Unmarshal(variants, &e.Variants); err != nil {
+		return nil, fmt.Errorf("failed to decode experiment variants: %w", err)
+	}
+	if startedAt.Valid {
+		t := startedAt.Time
+		e.StartedAt = &t
+	}
+	if stoppedAt.Valid {
+		t := stoppedAt.Time
+		e.StoppedAt = &t
+	}
+	return e, nil
+}
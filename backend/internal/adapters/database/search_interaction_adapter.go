@@ -0,0 +1,220 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/repositories"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/infrastructure/clients/postgres"
+	apperrors "github.com/zatekoja/Patientpricediscoverydesign/backend/pkg/errors"
+)
+
+// SearchInteractionAdapter persists post-search interactions in Postgres.
+type SearchInteractionAdapter struct {
+	client *postgres.Client
+}
+
+// NewSearchInteractionAdapter creates a new search interaction adapter.
+func NewSearchInteractionAdapter(client *postgres.Client) repositories.SearchInteractionRepository {
+	return &SearchInteractionAdapter{client: client}
+}
+
+// Create inserts a new interaction.
+func (a *SearchInteractionAdapter) Create(ctx context.Context, interaction *entities.SearchInteraction) error {
+	if interaction == nil {
+		return apperrors.NewInternalError("interaction is nil", fmt.Errorf("interaction is nil"))
+	}
+	if interaction.ID == "" {
+		interaction.ID = uuid.New().String()
+	}
+	if interaction.CreatedAt.IsZero() {
+		interaction.CreatedAt = time.Now().UTC()
+	}
+
+	query := `
+		INSERT INTO search_interactions
+		(id, impression_id, event_type, facility_id, position, session_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	var position sql.NullInt64
+	if interaction.Position != nil {
+		position = sql.NullInt64{Int64: int64(*interaction.Position), Valid: true}
+	}
+
+	_, err := a.client.DB().ExecContext(ctx, query,
+		interaction.ID,
+		interaction.ImpressionID,
+		string(interaction.EventType),
+		interaction.FacilityID,
+		position,
+		sql.NullString{String: interaction.SessionID, Valid: interaction.SessionID != ""},
+		interaction.CreatedAt,
+	)
+	if err != nil {
+		return apperrors.NewInternalError("failed to record search interaction", err)
+	}
+
+	return nil
+}
+
+// GetQueryEngagement aggregates searches in the window and the interactions that followed them.
+func (a *SearchInteractionAdapter) GetQueryEngagement(ctx context.Context, filter entities.SearchInteractionReportFilter) ([]*entities.QueryEngagementStats, error) {
+	query := `
+		WITH searches AS (
+			SELECT id, COALESCE(NULLIF(normalized_query, ''), LOWER(query)) AS q
+			FROM search_analytics
+			WHERE created_at >= $1 AND created_at < $2
+		), per_impression AS (
+			SELECT impression_id,
+				COUNT(*) FILTER (WHERE event_type = 'result_click') AS clicks,
+				COUNT(*) FILTER (WHERE event_type = 'facility_view') AS views,
+				COUNT(*) FILTER (WHERE event_type = 'call_tap') AS call_taps,
+				COUNT(*) FILTER (WHERE event_type = 'whatsapp_tap') AS whatsapp_taps,
+				COUNT(*) FILTER (WHERE event_type = 'booking') AS bookings,
+				COALESCE(SUM(position) FILTER (WHERE event_type = 'result_click'), 0) AS position_sum,
+				COUNT(position) FILTER (WHERE event_type = 'result_click') AS positioned_clicks
+			FROM search_interactions
+			WHERE impression_id IN (SELECT id FROM searches)
+			GROUP BY impression_id
+		)
+		SELECT s.q,
+			COUNT(*),
+			COUNT(*) FILTER (WHERE i.clicks > 0),
+			COALESCE(SUM(i.clicks), 0),
+			COALESCE(SUM(i.views), 0),
+			COALESCE(SUM(i.call_taps), 0),
+			COALESCE(SUM(i.whatsapp_taps), 0),
+			COALESCE(SUM(i.bookings), 0),
+			COUNT(*) FILTER (WHERE i.bookings > 0),
+			COALESCE(SUM(i.position_sum)::float / NULLIF(SUM(i.positioned_clicks), 0), 0)
+		FROM searches s
+		LEFT JOIN per_impression i ON i.impression_id = s.id
+		GROUP BY s.q
+		ORDER BY COUNT(*) DESC, s.q
+		LIMIT $3
+	`
+
+	rows, err := a.client.DB().QueryContext(ctx, query, filter.Since, filter.Until, filter.Limit)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to get query engagement", err)
+	}
+	defer rows.Close()
+
+	var stats []*entities.QueryEngagementStats
+	for rows.Next() {
+		s := &entities.QueryEngagementStats{}
+		if err := rows.Scan(&s.Query, &s.Searches, &s.ClickedSearches, &s.Clicks, &s.Views, &s.CallTaps,
+			&s.WhatsAppTaps, &s.Bookings, &s.ConvertedSearches, &s.MeanClickPosition); err != nil {
+			return nil, apperrors.NewInternalError("failed to scan query engagement", err)
+		}
+		stats = append(stats, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, apperrors.NewInternalError("failed to iterate query engagement", err)
+	}
+
+	return stats, nil
+}
+
+// GetFacilityEngagement aggregates how often each facility was shown and acted on.
+func (a *SearchInteractionAdapter) GetFacilityEngagement(ctx context.Context, filter entities.SearchInteractionReportFilter) ([]*entities.FacilityEngagementStats, error) {
+	query := `
+		WITH impressions AS (
+			SELECT f.facility_id, COUNT(*) AS impressions
+			FROM search_analytics sa, UNNEST(sa.result_facility_ids) AS f(facility_id)
+			WHERE sa.created_at >= $1 AND sa.created_at < $2
+			GROUP BY f.facility_id
+		), interactions AS (
+			SELECT facility_id,
+				COUNT(*) FILTER (WHERE event_type = 'result_click') AS clicks,
+				COUNT(*) FILTER (WHERE event_type = 'facility_view') AS views,
+				COUNT(*) FILTER (WHERE event_type = 'call_tap') AS call_taps,
+				COUNT(*) FILTER (WHERE event_type = 'whatsapp_tap') AS whatsapp_taps,
+				COUNT(*) FILTER (WHERE event_type = 'booking') AS bookings
+			FROM search_interactions
+			WHERE created_at >= $1 AND created_at < $2
+			GROUP BY facility_id
+		)
+		SELECT COALESCE(im.facility_id, ia.facility_id),
+			COALESCE(im.impressions, 0),
+			COALESCE(ia.clicks, 0),
+			COALESCE(ia.views, 0),
+			COALESCE(ia.call_taps, 0),
+			COALESCE(ia.whatsapp_taps, 0),
+			COALESCE(ia.bookings, 0)
+		FROM impressions im
+		FULL OUTER JOIN interactions ia ON ia.facility_id = im.facility_id
+		ORDER BY COALESCE(im.impressions, 0) DESC, COALESCE(ia.clicks, 0) DESC, 1
+		LIMIT $3
+	`
+
+	rows, err := a.client.DB().QueryContext(ctx, query, filter.Since, filter.Until, filter.Limit)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to get facility engagement", err)
+	}
+	defer rows.Close()
+
+	var stats []*entities.FacilityEngagementStats
+	for rows.Next() {
+		s := &entities.FacilityEngagementStats{}
+		if err := rows.Scan(&s.FacilityID, &s.Impressions, &s.Clicks, &s.Views, &s.CallTaps, &s.WhatsAppTaps, &s.Bookings); err != nil {
+			return nil, apperrors.NewInternalError("failed to scan facility engagement", err)
+		}
+		stats = append(stats, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, apperrors.NewInternalError("failed to iterate facility engagement", err)
+	}
+
+	return stats, nil
+}
+
+// GetPositionEngagement aggregates impressions and clicks per absolute result position.
+func (a *SearchInteractionAdapter) GetPositionEngagement(ctx context.Context, filter entities.SearchInteractionReportFilter) ([]*entities.PositionEngagementStats, error) {
+	query := `
+		WITH impressions AS (
+			SELECT sa.result_offset + p.ord AS position, COUNT(*) AS impressions
+			FROM search_analytics sa, UNNEST(sa.result_facility_ids) WITH ORDINALITY AS p(facility_id, ord)
+			WHERE sa.created_at >= $1 AND sa.created_at < $2
+			GROUP BY 1
+		), clicks AS (
+			SELECT position, COUNT(*) AS clicks
+			FROM search_interactions
+			WHERE event_type = 'result_click' AND position IS NOT NULL
+				AND created_at >= $1 AND created_at < $2
+			GROUP BY position
+		)
+		SELECT COALESCE(im.position, c.position),
+			COALESCE(im.impressions, 0),
+			COALESCE(c.clicks, 0)
+		FROM impressions im
+		FULL OUTER JOIN clicks c ON c.position = im.position
+		ORDER BY 1
+		LIMIT $3
+	`
+
+	rows, err := a.client.DB().QueryContext(ctx, query, filter.Since, filter.Until, filter.Limit)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to get position engagement", err)
+	}
+	defer rows.Close()
+
+	var stats []*entities.PositionEngagementStats
+	for rows.Next() {
+		s := &entities.PositionEngagementStats{}
+		if err := rows.Scan(&s.Position, &s.Impressions, &s.Clicks); err != nil {
+			return nil, apperrors.NewInternalError("failed to scan position engagement", err)
+		}
+		stats = append(stats, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, apperrors.NewInternalError("failed to iterate position engagement", err)
+	}
+
+	return stats, nil
+}
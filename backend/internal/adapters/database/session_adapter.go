@@ -0,0 +1,73 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/repositories"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/infrastructure/clients/postgres"
+	apperrors "github.com/zatekoja/Patientpricediscoverydesign/backend/pkg/errors"
+)
+
+// SessionAdapter implements the SessionRepository interface
+type SessionAdapter struct {
+	client *postgres.Client
+}
+
+// NewSessionAdapter creates a new session adapter
+func NewSessionAdapter(client *postgres.Client) repositories.SessionRepository {
+	return &SessionAdapter{client: client}
+}
+
+// Create stores a new session
+func (a *SessionAdapter) Create(ctx context.Context, session *entities.UserSession) error {
+	if session.ID == "" {
+		session.ID = uuid.New().String()
+	}
+	if session.CreatedAt.IsZero() {
+		session.CreatedAt = time.Now().UTC()
+	}
+
+	_, err := a.client.DB().ExecContext(ctx, `
+		INSERT INTO user_sessions (id, user_id, token_hash, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`, session.ID, session.UserID, session.TokenHash, session.ExpiresAt, session.CreatedAt)
+	if err != nil {
+		return apperrors.NewInternalError("failed to create session", err)
+	}
+	return nil
+}
+
+// GetByTokenHash retrieves a session by the SHA-256 of its token
+func (a *SessionAdapter) GetByTokenHash(ctx context.Context, tokenHash string) (*entities.UserSession, error) {
+	session := &entities.UserSession{}
+	err := a.client.DB().QueryRowContext(ctx, `
+		SELECT id, user_id, token_hash, expires_at, created_at
+		FROM user_sessions
+		WHERE token_hash = $1
+	`, tokenHash).Scan(
+		&session.ID,
+		&session.UserID,
+		&session.TokenHash,
+		&session.ExpiresAt,
+		&session.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, apperrors.NewNotFoundError("session not found")
+	}
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to get session", err)
+	}
+	return session, nil
+}
+
+// Delete removes a session
+func (a *SessionAdapter) Delete(ctx context.Context, id string) error {
+	if _, err := a.client.DB().ExecContext(ctx, `DELETE FROM user_sessions WHERE id = $1`, id); err != nil {
+		return apperrors.NewInternalError("failed to delete session", err)
+	}
+	return nil
+}
@@ -0,0 +1,222 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/repositories"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/infrastructure/clients/postgres"
+	apperrors "github.com/zatekoja/Patientpricediscoverydesign/backend/pkg/errors"
+)
+
+// SlotReservationAdapter persists holds and bookings on availability slots.
+// Every write that consumes capacity locks the slot row first, so concurrent
+// checkouts for the same slot are serialized and cannot overbook it.
+type SlotReservationAdapter struct {
+	client *postgres.Client
+}
+
+// NewSlotReservationAdapter creates a new slot reservation adapter.
+func NewSlotReservationAdapter(client *postgres.Client) repositories.SlotReservationRepository {
+	return &SlotReservationAdapter{client: client}
+}
+
+// GetByID retrieves a reservation with its slot's facility and start time.
+func (a *SlotReservationAdapter) GetByID(ctx context.Context, id string) (*entities.SlotReservation, error) {
+	query := `
+		SELECT r.id, r.slot_id, s.facility_id, s.start_time, r.status, r.expires_at, r.created_at, r.updated_at
+		FROM slot_reservations r
+		JOIN availability_slots s ON s.id = r.slot_id
+		WHERE r.id = $1
+	`
+
+	reservation, err := scanSlotReservation(a.client.DB().QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, apperrors.NewNotFoundError(fmt.Sprintf("reservation with id %s not found", id))
+	}
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to get reservation", err)
+	}
+
+	return reservation, nil
+}
+
+// Hold reserves one unit of the slot until expiresAt.
+func (a *SlotReservationAdapter) Hold(ctx context.Context, slotID string, expiresAt, now time.Time) (*entities.SlotReservation, error) {
+	return a.reserve(ctx, slotID, entities.SlotReservationHeld, &expiresAt, now)
+}
+
+// Book books one unit of the slot directly.
+func (a *SlotReservationAdapter) Book(ctx context.Context, slotID string, now time.Time) (*entities.SlotReservation, error) {
+	return a.reserve(ctx, slotID, entities.SlotReservationBooked, nil, now)
+}
+
+func (a *SlotReservationAdapter) reserve(ctx context.Context, slotID string, status entities.SlotReservationStatus, expiresAt *time.Time, now time.Time) (*entities.SlotReservation, error) {
+	tx, err := a.client.DB().BeginTx(ctx, nil)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to begin transaction", err)
+	}
+	defer tx.Rollback()
+
+	reservation := &entities.SlotReservation{
+		ID:        uuid.New().String(),
+		SlotID:    slotID,
+		Status:    status,
+		ExpiresAt: expiresAt,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	capacity, err := lockSlot(ctx, tx, slotID, reservation)
+	if err != nil {
+		return nil, err
+	}
+	taken, err := countActiveReservations(ctx, tx, slotID, now)
+	if err != nil {
+		return nil, err
+	}
+	if taken >= capacity {
+		return nil, apperrors.NewConflictError("slot is fully booked")
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO slot_reservations (id, slot_id, status, expires_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $5)
+	`, reservation.ID, slotID, string(status), expiresAt, now)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to create reservation", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, apperrors.NewInternalError("failed to commit reservation", err)
+	}
+
+	return reservation, nil
+}
+
+// ConfirmHold turns an unexpired hold into a booking. The hold already counts
+// against capacity, so no capacity check is needed beyond it still being active.
+func (a *SlotReservationAdapter) ConfirmHold(ctx context.Context, holdID string, now time.Time) (*entities.SlotReservation, error) {
+	hold, err := a.GetByID(ctx, holdID)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := a.client.DB().BeginTx(ctx, nil)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to begin transaction", err)
+	}
+	defer tx.Rollback()
+
+	// Lock the slot before the reservation, in the same order as reserve, to avoid deadlocks.
+	if _, err := lockSlot(ctx, tx, hold.SlotID, hold); err != nil {
+		return nil, err
+	}
+
+	var status string
+	var expiresAt sql.NullTime
+	err = tx.QueryRowContext(ctx,
+		`SELECT status, expires_at FROM slot_reservations WHERE id = $1 FOR UPDATE`, holdID,
+	).Scan(&status, &expiresAt)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to lock reservation", err)
+	}
+	if entities.SlotReservationStatus(status) != entities.SlotReservationHeld {
+		return nil, apperrors.NewConflictError(fmt.Sprintf("hold is %s", status))
+	}
+	if !expiresAt.Valid || !expiresAt.Time.After(now) {
+		return nil, apperrors.NewConflictError("hold has expired")
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE slot_reservations SET status = $2, expires_at = NULL, updated_at = $3 WHERE id = $1
+	`, holdID, string(entities.SlotReservationBooked), now)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to confirm hold", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, apperrors.NewInternalError("failed to commit hold confirmation", err)
+	}
+
+	hold.Status = entities.SlotReservationBooked
+	hold.ExpiresAt = nil
+	hold.UpdatedAt = now
+	return hold, nil
+}
+
+// Release releases an active hold.
+func (a *SlotReservationAdapter) Release(ctx context.Context, holdID string) error {
+	return a.transition(ctx, holdID, entities.SlotReservationHeld, entities.SlotReservationReleased)
+}
+
+// Cancel cancels a booking, returning its capacity to the slot.
+func (a *SlotReservationAdapter) Cancel(ctx context.Context, bookingID string) error {
+	return a.transition(ctx, bookingID, entities.SlotReservationBooked, entities.SlotReservationCancelled)
+}
+
+func (a *SlotReservationAdapter) transition(ctx context.Context, id string, from, to entities.SlotReservationStatus) error {
+	result, err := a.client.DB().ExecContext(ctx, `
+		UPDATE slot_reservations SET status = $3, updated_at = NOW() WHERE id = $1 AND status = $2
+	`, id, string(from), string(to))
+	if err != nil {
+		return apperrors.NewInternalError("failed to update reservation", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return apperrors.NewInternalError("failed to get affected rows", err)
+	}
+	if rows == 0 {
+		return apperrors.NewNotFoundError(fmt.Sprintf("%s reservation with id %s not found", from, id))
+	}
+
+	return nil
+}
+
+// lockSlot takes a row lock on the slot for the rest of the transaction and
+// returns its capacity. It also fills in the reservation's slot details.
+func lockSlot(ctx context.Context, tx *sql.Tx, slotID string, reservation *entities.SlotReservation) (int, error) {
+	var capacity int
+	err := tx.QueryRowContext(ctx,
+		`SELECT facility_id, start_time, capacity FROM availability_slots WHERE id = $1 FOR UPDATE`, slotID,
+	).Scan(&reservation.FacilityID, &reservation.StartTime, &capacity)
+	if err == sql.ErrNoRows {
+		return 0, apperrors.NewNotFoundError(fmt.Sprintf("availability slot with id %s not found", slotID))
+	}
+	if err != nil {
+		return 0, apperrors.NewInternalError("failed to lock availability slot", err)
+	}
+	return capacity, nil
+}
+
+func countActiveReservations(ctx context.Context, tx *sql.Tx, slotID string, now time.Time) (int, error) {
+	var taken int
+	err := tx.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM slot_reservations
+		WHERE slot_id = $1 AND (status = 'booked' OR (status = 'held' AND expires_at > $2))
+	`, slotID, now).Scan(&taken)
+	if err != nil {
+		return 0, apperrors.NewInternalError("failed to count reservations", err)
+	}
+	return taken, nil
+}
+
+func scanSlotReservation(row rowScanner) (*entities.SlotReservation, error) {
+	r := &entities.SlotReservation{}
+	var status string
+	var expiresAt sql.NullTime
+	err := row.Scan(&r.ID, &r.SlotID, &r.FacilityID, &r.StartTime, &status, &expiresAt, &r.CreatedAt, &r.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	r.Status = entities.SlotReservationStatus(status)
+	if expiresAt.Valid {
+		r.ExpiresAt = &expiresAt.Time
+	}
+	return r, nil
+}
@@ -0,0 +1,26 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/repositories"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/infrastructure/clients/postgres"
+)
+
+// Transactor implements the Transactor interface on the Postgres client
+type Transactor struct {
+	client *postgres.Client
+}
+
+// NewTransactor creates a new transactor
+func NewTransactor(client *postgres.Client) repositories.Transactor {
+	return &Transactor{client: client}
+}
+
+// InTx runs fn in a transaction, joining the caller's if there is one
+func (t *Transactor) InTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return t.client.InTx(ctx, func(ctx context.Context, _ *sql.Tx) error {
+		return fn(ctx)
+	})
+}
@@ -0,0 +1,146 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/repositories"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/infrastructure/clients/postgres"
+	apperrors "github.com/zatekoja/Patientpricediscoverydesign/backend/pkg/errors"
+)
+
+const userSelect = `
+	SELECT id, email, first_name, last_name, phone, last_login_at, created_at, updated_at
+	FROM users
+`
+
+// UserAdapter implements the UserRepository interface
+type UserAdapter struct {
+	client *postgres.Client
+}
+
+// NewUserAdapter creates a new user adapter
+func NewUserAdapter(client *postgres.Client) repositories.UserRepository {
+	return &UserAdapter{client: client}
+}
+
+// Create creates a new user
+func (a *UserAdapter) Create(ctx context.Context, user *entities.User) error {
+	if user.ID == "" {
+		user.ID = uuid.New().String()
+	}
+	now := time.Now().UTC()
+	user.CreatedAt = now
+	user.UpdatedAt = now
+
+	_, err := a.client.DB().ExecContext(ctx, `
+		INSERT INTO users (id, email, first_name, last_name, phone, last_login_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`,
+		user.ID,
+		nullString(user.Email),
+		nullString(user.FirstName),
+		nullString(user.LastName),
+		nullString(user.Phone),
+		user.LastLoginAt,
+		user.CreatedAt,
+		user.UpdatedAt,
+	)
+	if err != nil {
+		return userWriteError("failed to create user", err)
+	}
+	return nil
+}
+
+// GetByID retrieves a user by ID
+func (a *UserAdapter) GetByID(ctx context.Context, id string) (*entities.User, error) {
+	return a.get(ctx, `WHERE id = $1`, id, fmt.Sprintf("user with id %s not found", id))
+}
+
+// GetByEmail retrieves a user by email
+func (a *UserAdapter) GetByEmail(ctx context.Context, email string) (*entities.User, error) {
+	return a.get(ctx, `WHERE LOWER(email) = LOWER($1)`, email, "user with that email not found")
+}
+
+// GetByPhone retrieves a user by E.164 phone number
+func (a *UserAdapter) GetByPhone(ctx context.Context, phone string) (*entities.User, error) {
+	return a.get(ctx, `WHERE phone = $1`, phone, "user with that phone not found")
+}
+
+// Update updates a user's profile and last login
+func (a *UserAdapter) Update(ctx context.Context, user *entities.User) error {
+	user.UpdatedAt = time.Now().UTC()
+
+	result, err := a.client.DB().ExecContext(ctx, `
+		UPDATE users
+		SET email = $2, first_name = $3, last_name = $4, phone = $5, last_login_at = $6, updated_at = $7
+		WHERE id = $1
+	`,
+		user.ID,
+		nullString(user.Email),
+		nullString(user.FirstName),
+		nullString(user.LastName),
+		nullString(user.Phone),
+		user.LastLoginAt,
+		user.UpdatedAt,
+	)
+	if err != nil {
+		return userWriteError("failed to update user", err)
+	}
+	return requireRowAffected(result, fmt.Sprintf("user with id %s not found", user.ID))
+}
+
+// Delete deletes a user
+func (a *UserAdapter) Delete(ctx context.Context, id string) error {
+	result, err := a.client.DB().ExecContext(ctx, `DELETE FROM users WHERE id = $1`, id)
+	if err != nil {
+		return apperrors.NewInternalError("failed to delete user", err)
+	}
+	return requireRowAffected(result, fmt.Sprintf("user with id %s not found", id))
+}
+
+func (a *UserAdapter) get(ctx context.Context, where string, arg interface{}, notFound string) (*entities.User, error) {
+	user := &entities.User{}
+	var email, firstName, lastName, phone sql.NullString
+	var lastLoginAt sql.NullTime
+	err := a.client.DB().QueryRowContext(ctx, userSelect+where, arg).Scan(
+		&user.ID,
+		&email,
+		&firstName,
+		&lastName,
+		&phone,
+		&lastLoginAt,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, apperrors.NewNotFoundError(notFound)
+	}
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to get user", err)
+	}
+
+	user.Email = email.String
+	user.FirstName = firstName.String
+	user.LastName = lastName.String
+	user.Phone = phone.String
+	if lastLoginAt.Valid {
+		user.LastLoginAt = &lastLoginAt.Time
+	}
+	return user, nil
+}
+
+// userWriteError maps unique violations on email or phone to a conflict
+func userWriteError(message string, err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return apperrors.NewConflictError("another account already uses that email or phone")
+	}
+	return apperrors.NewInternalError(message, err)
+}
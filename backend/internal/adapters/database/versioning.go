@@ -0,0 +1,58 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/doug-martin/goqu/v9"
+	apperrors "github.com/zatekoja/Patientpricediscoverydesign/backend/pkg/errors"
+)
+
+// nextVersion bumps a row's version as part of a compare-and-swap write
+var nextVersion = goqu.L("version + 1")
+
+// rowQuerier is satisfied by both *sql.DB and *sql.Tx
+type rowQuerier interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// execVersioned runs a compare-and-swap write that returns the row's new
+// version. When no row was written it tells a missing row (NotFound) apart
+// from one another writer changed first (Conflict).
+func execVersioned(ctx context.Context, q rowQuerier, query string, args []interface{}, table, entity, id string) (int, error) {
+	var version int
+	err := q.QueryRowContext(ctx, query, args...).Scan(&version)
+	if err == nil {
+		return version, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return 0, apperrors.NewInternalError(fmt.Sprintf("failed to update %s", entity), err)
+	}
+
+	var exists bool
+	existsQuery := fmt.Sprintf(`SELECT EXISTS(SELECT 1 FROM %s WHERE id = $1)`, table)
+	if err := q.QueryRowContext(ctx, existsQuery, id).Scan(&exists); err != nil {
+		return 0, apperrors.NewInternalError(fmt.Sprintf("failed to check %s", entity), err)
+	}
+	if !exists {
+		return 0, apperrors.NewNotFoundError(fmt.Sprintf("%s with id %s not found", entity, id))
+	}
+	return 0, apperrors.NewConflictError(fmt.Sprintf("%s %s was changed by another update", entity, id))
+}
+
+// upsertVersioned runs a compare-and-swap INSERT ... ON CONFLICT DO UPDATE
+// that returns the row's version. No row back means the row already exists at
+// a different version than the caller read.
+func upsertVersioned(ctx context.Context, q rowQuerier, query string, args []interface{}, entity, id string) (int, error) {
+	var version int
+	err := q.QueryRowContext(ctx, query, args...).Scan(&version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, apperrors.NewConflictError(fmt.Sprintf("%s %s was changed by another update", entity, id))
+	}
+	if err != nil {
+		return 0, apperrors.NewInternalError(fmt.Sprintf("failed to upsert %s", entity), err)
+	}
+	return version, nil
+}
@@ -0,0 +1,95 @@
+package embedding
+
+import (
+	"context"
+	"hash/fnv"
+	"math"
+	"strings"
+	"unicode"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/providers"
+)
+
+// HashingModel identifies vectors from the hashing provider
+const HashingModel = "local-hashing-v1"
+
+// trigramWeight is how much each character trigram counts relative to a
+// whole word, so shared stems ("pain", "painful") score without dominating
+const trigramWeight = 0.5
+
+// stopWords carry no meaning of their own in health queries
+var stopWords = map[string]struct{}{
+	"a": {}, "an": {}, "and": {}, "at": {}, "for": {}, "i": {}, "in": {}, "is": {},
+	"it": {}, "me": {}, "my": {}, "of": {}, "on": {}, "or": {}, "the": {}, "to": {},
+	"when": {}, "where": {}, "with": {},
+}
+
+// HashingProvider is a deterministic, offline embedding provider. It hashes
+// words and their character trigrams into a fixed-size vector, so texts that
+// share words or stems are close. It does not understand paraphrases the way
+// a trained model does; it stands in for one in tests, evaluation and
+// deployments without an embedding API.
+type HashingProvider struct{}
+
+var _ providers.EmbeddingProvider = (*HashingProvider)(nil)
+
+// NewHashingProvider creates a new hashing embedding provider
+func NewHashingProvider() *HashingProvider {
+	return &HashingProvider{}
+}
+
+// EmbeddingModel returns the hashing model identifier
+func (p *HashingProvider) EmbeddingModel() string {
+	return HashingModel
+}
+
+// Embed returns a unit vector for each text; empty texts give zero vectors
+func (p *HashingProvider) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vectors[i] = hashText(text)
+	}
+	return vectors, nil
+}
+
+func hashText(text string) []float32 {
+	vector := make([]float64, entities.EmbeddingDimensions)
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, word := range words {
+		if _, stop := stopWords[word]; stop {
+			continue
+		}
+		addFeature(vector, "w:"+word, 1)
+
+		padded := []rune("#" + word + "#")
+		for i := 0; i+3 <= len(padded); i++ {
+			addFeature(vector, "t:"+string(padded[i:i+3]), trigramWeight)
+		}
+	}
+
+	var norm float64
+	for _, v := range vector {
+		norm += v * v
+	}
+	out := make([]float32, len(vector))
+	if norm == 0 {
+		return out
+	}
+	norm = math.Sqrt(norm)
+	for i, v := range vector {
+		out[i] = float32(v / norm)
+	}
+	return out
+}
+
+// addFeature adds weight to the feature's bucket. Weights are never negated,
+// so a shared feature always brings two texts closer; collisions only add a
+// little similarity between unrelated texts.
+func addFeature(vector []float64, feature string, weight float64) {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(feature))
+	vector[h.Sum64()%uint64(len(vector))] += weight
+}
@@ -0,0 +1,50 @@
+package embedding
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+)
+
+func TestHashingProvider_IsDeterministicAndNormalized(t *testing.T) {
+	provider := NewHashingProvider()
+	vectors, err := provider.Embed(context.Background(), []string{"Malaria test", "malaria TEST", ""})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(vectors[0]) != entities.EmbeddingDimensions {
+		t.Fatalf("expected %d dimensions, got %d", entities.EmbeddingDimensions, len(vectors[0]))
+	}
+	if sim := entities.CosineSimilarity(vectors[0], vectors[1]); math.Abs(sim-1) > 1e-6 {
+		t.Fatalf("expected identical vectors for case variants, got similarity %f", sim)
+	}
+
+	var norm float64
+	for _, v := range vectors[0] {
+		norm += float64(v) * float64(v)
+	}
+	if math.Abs(norm-1) > 1e-5 {
+		t.Fatalf("expected a unit vector, got squared norm %f", norm)
+	}
+	if sim := entities.CosineSimilarity(vectors[2], vectors[0]); sim != 0 {
+		t.Fatalf("expected an empty text to match nothing, got %f", sim)
+	}
+}
+
+func TestHashingProvider_SharedStemsAreCloserThanUnrelatedText(t *testing.T) {
+	vectors, err := NewHashingProvider().Embed(context.Background(), []string{
+		"pain when I pee",
+		"painful urination urinary tract infection",
+		"dental cleaning and tooth extraction",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	related := entities.CosineSimilarity(vectors[0], vectors[1])
+	unrelated := entities.CosineSimilarity(vectors[0], vectors[2])
+	if related <= unrelated {
+		t.Fatalf("expected the related text to be closer (%f) than the unrelated one (%f)", related, unrelated)
+	}
+}
@@ -0,0 +1,26 @@
+package embedding
+
+import (
+	"fmt"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/providers"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/infrastructure/clients/openai"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/pkg/config"
+)
+
+// NewProvider returns the embedding provider named in the configuration.
+// Every process that writes or queries embeddings must use the same one.
+func NewProvider(cfg *config.Config) (providers.EmbeddingProvider, error) {
+	switch cfg.Embedding.Provider {
+	case "", "local":
+		return NewHashingProvider(), nil
+	case "openai":
+		client, err := openai.NewClient(&cfg.OpenAI)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create openai embedding provider: %w", err)
+		}
+		return client, nil
+	default:
+		return nil, fmt.Errorf("unknown embedding provider %q", cfg.Embedding.Provider)
+	}
+}
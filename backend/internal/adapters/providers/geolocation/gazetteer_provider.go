@@ -0,0 +1,415 @@
+package geolocation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/providers"
+)
+
+// gazetteerCountry is the country every gazetteer place is in
+const gazetteerCountry = "Nigeria"
+
+// minMentionLength is the shortest place name found inside longer address
+// text; shorter names and aliases ("vi", "ife") only match a whole part
+const minMentionLength = 4
+
+// Confidence of a gazetteer answer: an address made only of place names is
+// answered as well as it was asked, while a street address placed at the
+// centroid of its area is only roughly right
+const (
+	exactPlaceConfidence = 0.8
+	areaPlaceConfidence  = 0.3
+)
+
+// defaultPlaceExtent is the half-width in degrees of the box given to places
+// listed without bounds
+var defaultPlaceExtent = map[providers.PlaceKind]float64{
+	providers.PlaceKindNeighbourhood: 0.015,
+	providers.PlaceKindLGA:           0.05,
+	providers.PlaceKindCity:          0.08,
+}
+
+// placeSpecificity orders kinds from broadest to most specific
+var placeSpecificity = map[providers.PlaceKind]int{
+	providers.PlaceKindState:         0,
+	providers.PlaceKindCity:          1,
+	providers.PlaceKindLGA:           2,
+	providers.PlaceKindNeighbourhood: 3,
+}
+
+// addressSuffixes are dropped from address parts that are not place names
+// by themselves, so "Lagos State" and "Surulere LGA" match
+var addressSuffixes = []string{"local government area", "local government", "area council", "lga", "state"}
+
+// gazetteerFile is the on-disk format of config/gazetteer.json. Bounds are
+// [min lat, min lon, max lat, max lon]; places without them get a box sized
+// by kind.
+type gazetteerFile struct {
+	Country string `json:"country"`
+	Places  []struct {
+		Name    string              `json:"name"`
+		Kind    providers.PlaceKind `json:"kind"`
+		State   string              `json:"state"`
+		City    string              `json:"city"`
+		Lat     float64             `json:"lat"`
+		Lon     float64             `json:"lon"`
+		Aliases []string            `json:"aliases"`
+		Bounds  []float64           `json:"bounds"`
+	} `json:"places"`
+}
+
+// GazetteerGeolocationProvider resolves Nigerian states, LGAs, cities and
+// neighbourhoods from a bundled gazetteer, without network calls. It cannot
+// place street addresses more precisely than their area, so it is usually
+// put in front of another provider with NewTieredGeolocationProvider.
+type GazetteerGeolocationProvider struct {
+	places []*providers.GazetteerPlace
+	// byName maps folded names and aliases to places, most specific first
+	byName map[string][]*providers.GazetteerPlace
+	states map[string]*providers.GazetteerPlace
+}
+
+// NewGazetteerGeolocationProvider loads the gazetteer at path
+func NewGazetteerGeolocationProvider(path string) (*GazetteerGeolocationProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var raw gazetteerFile
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse gazetteer %s: %w", path, err)
+	}
+	if raw.Country != gazetteerCountry {
+		return nil, fmt.Errorf("gazetteer %s: unsupported country %q", path, raw.Country)
+	}
+
+	g := &GazetteerGeolocationProvider{
+		byName: make(map[string][]*providers.GazetteerPlace),
+		states: make(map[string]*providers.GazetteerPlace),
+	}
+	aliases := make(map[*providers.GazetteerPlace][]string, len(raw.Places))
+	for _, entry := range raw.Places {
+		if strings.TrimSpace(entry.Name) == "" {
+			return nil, fmt.Errorf("gazetteer %s: place without a name", path)
+		}
+		if _, ok := placeSpecificity[entry.Kind]; !ok {
+			return nil, fmt.Errorf("gazetteer %s: %q has unknown kind %q", path, entry.Name, entry.Kind)
+		}
+		place := &providers.GazetteerPlace{
+			Name:     entry.Name,
+			Kind:     entry.Kind,
+			State:    entry.State,
+			City:     entry.City,
+			Centroid: providers.Coordinates{Latitude: entry.Lat, Longitude: entry.Lon},
+		}
+		switch {
+		case len(entry.Bounds) == 4:
+			place.Bounds = providers.BoundingBox{
+				MinLatitude: entry.Bounds[0], MinLongitude: entry.Bounds[1],
+				MaxLatitude: entry.Bounds[2], MaxLongitude: entry.Bounds[3],
+			}
+		case len(entry.Bounds) == 0 && entry.Kind != providers.PlaceKindState:
+			extent := defaultPlaceExtent[entry.Kind]
+			place.Bounds = providers.BoundingBox{
+				MinLatitude: entry.Lat - extent, MinLongitude: entry.Lon - extent,
+				MaxLatitude: entry.Lat + extent, MaxLongitude: entry.Lon + extent,
+			}
+		default:
+			return nil, fmt.Errorf("gazetteer %s: %q needs bounds of four numbers", path, entry.Name)
+		}
+		if !place.Bounds.Contains(place.Centroid) {
+			return nil, fmt.Errorf("gazetteer %s: centroid of %q is outside its bounds", path, entry.Name)
+		}
+		if place.Kind == providers.PlaceKindState {
+			place.State = place.Name
+			g.states[place.Name] = place
+		}
+		g.places = append(g.places, place)
+		aliases[place] = entry.Aliases
+	}
+
+	for _, place := range g.places {
+		state, ok := g.states[place.State]
+		if !ok {
+			return nil, fmt.Errorf("gazetteer %s: %q is in unknown state %q", path, place.Name, place.State)
+		}
+		if !state.Bounds.Contains(place.Centroid) {
+			return nil, fmt.Errorf("gazetteer %s: %q is outside %s", path, place.Name, state.Name)
+		}
+		for _, name := range append([]string{place.Name}, aliases[place]...) {
+			if key := foldPlaceName(name); key != "" {
+				g.byName[key] = append(g.byName[key], place)
+			}
+		}
+	}
+	// A name shared by a city and its state ("Kano") means the city, but a
+	// place named after another state ("Ekiti" in Kwara) does not hide that
+	// state; a name shared by places of the same kind keeps file order
+	for _, places := range g.byName {
+		rank := make(map[*providers.GazetteerPlace]int, len(places))
+		for _, place := range places {
+			rank[place] = placeRank(place, places)
+		}
+		sort.SliceStable(places, func(i, j int) bool {
+			return rank[places[i]] > rank[places[j]]
+		})
+	}
+	return g, nil
+}
+
+// placeRank prefers cities and towns over the states named after them, and
+// states over places elsewhere that share their name
+func placeRank(place *providers.GazetteerPlace, named []*providers.GazetteerPlace) int {
+	if place.Kind == providers.PlaceKindState {
+		return 1
+	}
+	for _, other := range named {
+		if other.Kind == providers.PlaceKindState && other.Name != place.State {
+			return 0
+		}
+	}
+	return 2
+}
+
+// Places returns every place in the gazetteer
+func (g *GazetteerGeolocationProvider) Places() []*providers.GazetteerPlace {
+	return g.places
+}
+
+// LookupPlace returns the place a name or alias denotes
+func (g *GazetteerGeolocationProvider) LookupPlace(name string) (*providers.GazetteerPlace, bool) {
+	places := g.lookup(foldPlaceName(name))
+	if len(places) == 0 {
+		return nil, false
+	}
+	return places[0], true
+}
+
+func (g *GazetteerGeolocationProvider) lookup(key string) []*providers.GazetteerPlace {
+	if places, ok := g.byName[key]; ok {
+		return places
+	}
+	for _, suffix := range addressSuffixes {
+		if trimmed := strings.TrimSpace(strings.TrimSuffix(key, " "+suffix)); trimmed != key {
+			if places, ok := g.byName[trimmed]; ok {
+				return places
+			}
+		}
+	}
+	return nil
+}
+
+// Geocode resolves the most specific place an address names. Parts that are
+// not place names, such as streets, are searched for place names within them.
+func (g *GazetteerGeolocationProvider) Geocode(ctx context.Context, address string) (*providers.GeocodedAddress, error) {
+	place, exact := g.ResolveAddress(address)
+	if place == nil {
+		return nil, fmt.Errorf("no gazetteer place in address")
+	}
+	return g.geocodedAddressFor(place, exact), nil
+}
+
+// ResolveAddress returns the most specific place an address names, and
+// whether every part of the address is a place name, as in
+// "Surulere, Lagos, Nigeria", so the place is as precise as the address
+func (g *GazetteerGeolocationProvider) ResolveAddress(address string) (*providers.GazetteerPlace, bool) {
+	var matches [][]*providers.GazetteerPlace
+	exact := true
+	for _, part := range strings.Split(address, ",") {
+		key := foldPlaceName(part)
+		if key == "" || key == "nigeria" || isNumeric(key) {
+			continue
+		}
+		if places := g.lookup(key); len(places) > 0 {
+			matches = append(matches, places)
+			continue
+		}
+		exact = false
+		if places := g.mentionedPlaces(key); len(places) > 0 {
+			matches = append(matches, places)
+		}
+	}
+	if len(matches) == 0 {
+		return nil, false
+	}
+
+	// The state most parts agree on decides between places sharing a name,
+	// so "Surulere, Lagos" is not the Surulere in Oyo
+	votes := map[string]int{}
+	for _, places := range matches {
+		seen := map[string]bool{}
+		for _, place := range places {
+			if !seen[place.State] {
+				votes[place.State]++
+				seen[place.State] = true
+			}
+		}
+	}
+	state, best := "", 0
+	for _, places := range matches {
+		for _, place := range places {
+			if votes[place.State] > best {
+				state, best = place.State, votes[place.State]
+			}
+		}
+	}
+
+	// Addresses run from specific to general, so among equally specific
+	// places the earlier part wins
+	var chosen *providers.GazetteerPlace
+	for _, places := range matches {
+		for _, place := range places {
+			if place.State != state {
+				continue
+			}
+			if chosen == nil || placeSpecificity[place.Kind] > placeSpecificity[chosen.Kind] {
+				chosen = place
+			}
+			break
+		}
+	}
+	return chosen, exact
+}
+
+// mentionedPlaces finds place names of up to three words inside text,
+// longest first
+func (g *GazetteerGeolocationProvider) mentionedPlaces(text string) []*providers.GazetteerPlace {
+	words := strings.Fields(text)
+	var found []*providers.GazetteerPlace
+	for size := 3; size >= 1; size-- {
+		for start := 0; start+size <= len(words); start++ {
+			name := strings.Join(words[start:start+size], " ")
+			if len(name) < minMentionLength {
+				continue
+			}
+			found = append(found, g.byName[name]...)
+		}
+	}
+	return found
+}
+
+// geocodedAddressFor is the answer to an address that resolved to place,
+// exactly or only by the area it is in
+func (g *GazetteerGeolocationProvider) geocodedAddressFor(place *providers.GazetteerPlace, exact bool) *providers.GeocodedAddress {
+	address := g.geocodedAddress(place)
+	address.Source = entities.GeocodeSourceGazetteer
+	address.Confidence = areaPlaceConfidence
+	if exact {
+		address.Confidence = exactPlaceConfidence
+	}
+	return address
+}
+
+func (g *GazetteerGeolocationProvider) geocodedAddress(place *providers.GazetteerPlace) *providers.GeocodedAddress {
+	address := &providers.GeocodedAddress{
+		State:       place.State,
+		Country:     gazetteerCountry,
+		Coordinates: place.Centroid,
+	}
+	parts := []string{}
+	switch place.Kind {
+	case providers.PlaceKindNeighbourhood:
+		address.Street = place.Name
+		address.City = place.City
+		parts = append(parts, place.Name, place.City)
+	case providers.PlaceKindLGA, providers.PlaceKindCity:
+		address.City = place.Name
+		parts = append(parts, place.Name)
+	}
+	if place.State != address.City {
+		parts = append(parts, place.State)
+	}
+	address.FormattedAddress = strings.Join(append(parts, gazetteerCountry), ", ")
+	return address
+}
+
+// ReverseGeocode returns the most specific place containing the
+// coordinates, the nearest when boxes overlap
+func (g *GazetteerGeolocationProvider) ReverseGeocode(ctx context.Context, lat, lon float64) (*providers.GeocodedAddress, error) {
+	point := providers.Coordinates{Latitude: lat, Longitude: lon}
+	var chosen *providers.GazetteerPlace
+	for _, place := range g.places {
+		if !place.Bounds.Contains(point) {
+			continue
+		}
+		if chosen == nil || placeSpecificity[place.Kind] > placeSpecificity[chosen.Kind] ||
+			(place.Kind == chosen.Kind && haversineKm(point, place.Centroid) < haversineKm(point, chosen.Centroid)) {
+			chosen = place
+		}
+	}
+	if chosen == nil {
+		return nil, fmt.Errorf("no gazetteer place contains %f, %f", lat, lon)
+	}
+	address := g.geocodedAddress(chosen)
+	address.Coordinates = point
+	return address, nil
+}
+
+// CalculateDistance returns the great-circle distance in kilometres
+func (g *GazetteerGeolocationProvider) CalculateDistance(ctx context.Context, from, to providers.Coordinates) (float64, error) {
+	return haversineKm(from, to), nil
+}
+
+// GetNearbyPlaces returns gazetteer places of the given kind, or of any kind
+// when placeType is empty, whose centroids are within the radius. The
+// gazetteer holds no facilities, so other place types find nothing.
+func (g *GazetteerGeolocationProvider) GetNearbyPlaces(ctx context.Context, center providers.Coordinates, radiusKm float64, placeType string) ([]*providers.Place, error) {
+	nearby := []*providers.Place{}
+	for _, place := range g.places {
+		if placeType != "" && string(place.Kind) != placeType {
+			continue
+		}
+		if haversineKm(center, place.Centroid) > radiusKm {
+			continue
+		}
+		nearby = append(nearby, &providers.Place{
+			ID:          string(place.Kind) + ":" + foldPlaceName(place.State+" "+place.Name),
+			Name:        place.Name,
+			Address:     g.geocodedAddress(place).FormattedAddress,
+			Coordinates: place.Centroid,
+			PlaceType:   string(place.Kind),
+		})
+	}
+	return nearby, nil
+}
+
+// foldPlaceName lower-cases a name and turns punctuation into spaces, so
+// "Ado-Ekiti" and "ado ekiti" are the same key
+func foldPlaceName(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune(' ')
+		}
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+func isNumeric(text string) bool {
+	for _, r := range text {
+		if !unicode.IsDigit(r) && r != ' ' {
+			return false
+		}
+	}
+	return true
+}
+
+func haversineKm(from, to providers.Coordinates) float64 {
+	const earthRadiusKm = 6371.0
+	deltaLat := toRadians(to.Latitude - from.Latitude)
+	deltaLon := toRadians(to.Longitude - from.Longitude)
+	a := math.Sin(deltaLat/2)*math.Sin(deltaLat/2) +
+		math.Cos(toRadians(from.Latitude))*math.Cos(toRadians(to.Latitude))*
+			math.Sin(deltaLon/2)*math.Sin(deltaLon/2)
+	return earthRadiusKm * 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
@@ -0,0 +1,178 @@
+package geolocation
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/providers"
+)
+
+func loadTestGazetteer(t *testing.T) *GazetteerGeolocationProvider {
+	t.Helper()
+	_, file, _, _ := runtime.Caller(0)
+	path := filepath.Join(filepath.Dir(file), "..", "..", "..", "..", "config", "gazetteer.json")
+	gazetteer, err := NewGazetteerGeolocationProvider(path)
+	if err != nil {
+		t.Fatalf("failed to load gazetteer: %v", err)
+	}
+	return gazetteer
+}
+
+// countingGeolocationProvider returns a fixed address, counting calls
+type countingGeolocationProvider struct {
+	providers.GeolocationProvider
+	address *providers.GeocodedAddress
+	err     error
+	calls   int
+}
+
+func (p *countingGeolocationProvider) Geocode(ctx context.Context, address string) (*providers.GeocodedAddress, error) {
+	p.calls++
+	return p.address, p.err
+}
+
+func TestGazetteer_LooksUpNamesAndAliases(t *testing.T) {
+	gazetteer := loadTestGazetteer(t)
+
+	tests := []struct {
+		name  string
+		place string
+		kind  providers.PlaceKind
+	}{
+		{name: "Wuse II", place: "Wuse 2", kind: providers.PlaceKindNeighbourhood},
+		{name: "ado-ekiti", place: "Ado-Ekiti", kind: providers.PlaceKindCity},
+		{name: "FCT", place: "Federal Capital Territory", kind: providers.PlaceKindState},
+		{name: "Kano", place: "Kano", kind: providers.PlaceKindCity},
+		{name: "Lagos State", place: "Lagos", kind: providers.PlaceKindState},
+		{name: "Ekiti", place: "Ekiti", kind: providers.PlaceKindState},
+		{name: "Nassarawa", place: "Nasarawa", kind: providers.PlaceKindState},
+		{name: "Ogbomoso North", place: "Ogbomosho North", kind: providers.PlaceKindLGA},
+	}
+	for _, tt := range tests {
+		place, ok := gazetteer.LookupPlace(tt.name)
+		if !ok || place.Name != tt.place || place.Kind != tt.kind {
+			t.Errorf("%q: expected %s %q, got %+v", tt.name, tt.kind, tt.place, place)
+		}
+	}
+	if _, ok := gazetteer.LookupPlace("malaria"); ok {
+		t.Fatalf("expected no place for a word that is not a place")
+	}
+}
+
+func TestGazetteer_ListsEveryLGAByState(t *testing.T) {
+	gazetteer := loadTestGazetteer(t)
+
+	// INEC's list of the 774 local government areas
+	expected := map[string]int{
+		"Abia": 17, "Adamawa": 21, "Akwa Ibom": 31, "Anambra": 21, "Bauchi": 20, "Bayelsa": 8,
+		"Benue": 23, "Borno": 27, "Cross River": 18, "Delta": 25, "Ebonyi": 13, "Edo": 18,
+		"Ekiti": 16, "Enugu": 17, "Federal Capital Territory": 6, "Gombe": 11, "Imo": 27,
+		"Jigawa": 27, "Kaduna": 23, "Kano": 44, "Katsina": 34, "Kebbi": 21, "Kogi": 21,
+		"Kwara": 16, "Lagos": 20, "Nasarawa": 13, "Niger": 25, "Ogun": 20, "Ondo": 18,
+		"Osun": 30, "Oyo": 33, "Plateau": 17, "Rivers": 23, "Sokoto": 23, "Taraba": 16,
+		"Yobe": 17, "Zamfara": 14,
+	}
+
+	counts := make(map[string]int)
+	total := 0
+	for _, place := range gazetteer.Places() {
+		if place.Kind == providers.PlaceKindLGA {
+			counts[place.State]++
+			total++
+		}
+	}
+	for state, want := range expected {
+		if counts[state] != want {
+			t.Errorf("%s: expected %d LGAs, got %d", state, want, counts[state])
+		}
+	}
+	if len(counts) != len(expected) || total != 774 {
+		t.Fatalf("expected 774 LGAs in %d states, got %d in %d", len(expected), total, len(counts))
+	}
+}
+
+func TestGazetteer_GeocodesTheMostSpecificPlaceInTheNamedState(t *testing.T) {
+	gazetteer := loadTestGazetteer(t)
+
+	place, exact := gazetteer.ResolveAddress("Surulere, Lagos, Nigeria")
+	if place == nil || place.State != "Lagos" || place.Kind != providers.PlaceKindLGA || !exact {
+		t.Fatalf("expected Surulere LGA in Lagos, got %+v (exact %v)", place, exact)
+	}
+	place, _ = gazetteer.ResolveAddress("Surulere, Oyo State")
+	if place == nil || place.State != "Oyo" {
+		t.Fatalf("expected Surulere in Oyo, got %+v", place)
+	}
+
+	address, err := gazetteer.Geocode(context.Background(), "12 Adeola Odeku Street, Victoria Island, Lagos")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if address.Street != "Victoria Island" || address.City != "Lagos" || address.State != "Lagos" || address.Country != "Nigeria" {
+		t.Fatalf("unexpected address %+v", address)
+	}
+	if place, exact := gazetteer.ResolveAddress("12 Adeola Odeku Street, Victoria Island, Lagos"); exact {
+		t.Fatalf("expected a street address not to be exact, got %+v", place)
+	}
+
+	if _, err := gazetteer.Geocode(context.Background(), "1600 Amphitheatre Parkway, Mountain View"); err == nil {
+		t.Fatalf("expected an error for an address outside the gazetteer")
+	}
+}
+
+func TestGazetteer_ReverseGeocodesToTheSmallestContainingPlace(t *testing.T) {
+	gazetteer := loadTestGazetteer(t)
+
+	address, err := gazetteer.ReverseGeocode(context.Background(), 9.0802, 7.4701)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if address.Street != "Wuse 2" || address.City != "Abuja" || address.State != "Federal Capital Territory" {
+		t.Fatalf("unexpected address %+v", address)
+	}
+	if _, err := gazetteer.ReverseGeocode(context.Background(), 51.5, -0.12); err == nil {
+		t.Fatalf("expected an error outside Nigeria")
+	}
+}
+
+func TestTieredProvider_UsesTheGazetteerFirst(t *testing.T) {
+	gazetteer := loadTestGazetteer(t)
+	ikeja := &providers.GeocodedAddress{
+		Street: "Allen Avenue", City: "Ikeja", State: "Lagos", Country: "Nigeria",
+		Coordinates: providers.Coordinates{Latitude: 6.6005, Longitude: 3.3554},
+	}
+	fallback := &countingGeolocationProvider{address: ikeja}
+	provider := NewTieredGeolocationProvider(gazetteer, fallback)
+
+	address, err := provider.Geocode(context.Background(), "Wuse 2, Abuja, Nigeria")
+	if err != nil || address.Street != "Wuse 2" || fallback.calls != 0 {
+		t.Fatalf("expected the gazetteer to answer an area address offline, got %+v (%d fallback calls)", address, fallback.calls)
+	}
+	if address.Source != entities.GeocodeSourceGazetteer || address.Confidence != exactPlaceConfidence {
+		t.Fatalf("expected an exact gazetteer answer, got %s at %v", address.Source, address.Confidence)
+	}
+
+	address, err = provider.Geocode(context.Background(), "20 Allen Avenue, Ikeja, Lagos, Nigeria")
+	if err != nil || address != ikeja {
+		t.Fatalf("expected the fallback to place a street address, got %+v", address)
+	}
+
+	// A fallback result outside the named state is replaced by the area
+	fallback.address = &providers.GeocodedAddress{City: "San Francisco", Coordinates: providers.Coordinates{Latitude: 37.77, Longitude: -122.42}}
+	address, err = provider.Geocode(context.Background(), "3 Hospital Road, Gwarinpa, Abuja")
+	if err != nil || address.Street != "Gwarinpa" {
+		t.Fatalf("expected the gazetteer place when the fallback strays, got %+v", address)
+	}
+
+	fallback.address, fallback.err = nil, errors.New("quota exceeded")
+	address, err = provider.Geocode(context.Background(), "5 Awolowo Road, Ikoyi, Lagos")
+	if err != nil || address.Street != "Ikoyi" || address.Confidence != areaPlaceConfidence {
+		t.Fatalf("expected the gazetteer place when the fallback fails, got %+v (%v)", address, err)
+	}
+	if _, err := provider.Geocode(context.Background(), "Unknown Plaza"); err == nil {
+		t.Fatalf("expected the fallback error when the gazetteer knows nothing")
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
 	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/providers"
 )
 
@@ -20,8 +21,24 @@ const (
 	defaultGeocodeCacheTTL = 60 * 60 * 24 * 30
 	defaultReverseCacheTTL = 60 * 60 * 24 * 30
 	defaultHTTPTimeout     = 8 * time.Second
+
+	// placeMatchConfidence is given to Places text search matches, which name a
+	// specific building
+	placeMatchConfidence = 0.9
+	// defaultGoogleConfidence is given to results without a location type,
+	// including entries cached before confidence was recorded
+	defaultGoogleConfidence = 0.5
 )
 
+// locationTypeConfidence maps a geocode result's location_type to how close
+// its coordinates are expected to be to the address
+var locationTypeConfidence = map[string]float64{
+	"ROOFTOP":            0.95,
+	"RANGE_INTERPOLATED": 0.8,
+	"GEOMETRIC_CENTER":   0.6,
+	"APPROXIMATE":        0.4,
+}
+
 // GoogleGeolocationProvider implements the GeolocationProvider using Google Maps APIs.
 type GoogleGeolocationProvider struct {
 	apiKey     string
@@ -73,6 +90,10 @@ func (g *GoogleGeolocationProvider) Geocode(ctx context.Context, address string)
 		if cached, err := g.cache.Get(ctx, cacheKey); err == nil && len(cached) > 0 {
 			var addr providers.GeocodedAddress
 			if err := json.Unmarshal(cached, &addr); err == nil && (addr.Coordinates.Latitude != 0 || addr.Coordinates.Longitude != 0) {
+				if addr.Source == "" {
+					addr.Source = entities.GeocodeSourceGoogle
+					addr.Confidence = defaultGoogleConfidence
+				}
 				return &addr, nil
 			}
 		}
@@ -110,6 +131,8 @@ func (g *GoogleGeolocationProvider) Geocode(ctx context.Context, address string)
 			Latitude:  result.Geometry.Location.Lat,
 			Longitude: result.Geometry.Location.Lng,
 		},
+		Source:     entities.GeocodeSourceGoogle,
+		Confidence: geometryConfidence(result.Geometry),
 	}
 
 	if g.cache != nil {
@@ -154,6 +177,8 @@ func (g *GoogleGeolocationProvider) ReverseGeocode(ctx context.Context, lat, lon
 			Latitude:  result.Geometry.Location.Lat,
 			Longitude: result.Geometry.Location.Lng,
 		},
+		Source:     entities.GeocodeSourceGoogle,
+		Confidence: geometryConfidence(result.Geometry),
 	}
 
 	if g.cache != nil {
@@ -236,6 +261,8 @@ func (g *GoogleGeolocationProvider) searchPlaceAddress(ctx context.Context, quer
 			Latitude:  result.Geometry.Location.Lat,
 			Longitude: result.Geometry.Location.Lng,
 		},
+		Source:     entities.GeocodeSourceGoogle,
+		Confidence: placeMatchConfidence,
 	}, nil
 }
 
@@ -336,7 +363,15 @@ type googleAddressComponent struct {
 }
 
 type googleGeometry struct {
-	Location googleLocation `json:"location"`
+	Location     googleLocation `json:"location"`
+	LocationType string         `json:"location_type,omitempty"`
+}
+
+func geometryConfidence(geometry googleGeometry) float64 {
+	if confidence, ok := locationTypeConfidence[geometry.LocationType]; ok {
+		return confidence
+	}
+	return defaultGoogleConfidence
 }
 
 type googleLocation struct {
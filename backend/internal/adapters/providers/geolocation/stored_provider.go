@@ -0,0 +1,95 @@
+package geolocation
+
+import (
+	"context"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/providers"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/repositories"
+)
+
+// minStoredConfidence is the lowest confidence worth keeping. Rougher
+// answers, such as a street address placed at its area's centroid, are asked
+// again next time in case a better provider can answer.
+const minStoredConfidence = 0.5
+
+// StoredGeolocationProvider answers addresses from the geocode store and
+// stores the inner provider's answers, so each address is geocoded once
+type StoredGeolocationProvider struct {
+	inner providers.GeolocationProvider
+	store repositories.GeocodeRepository
+}
+
+// NewStoredGeolocationProvider puts the geocode store in front of inner
+func NewStoredGeolocationProvider(inner providers.GeolocationProvider, store repositories.GeocodeRepository) providers.GeolocationProvider {
+	return &StoredGeolocationProvider{inner: inner, store: store}
+}
+
+// Geocode returns the stored result for the normalised address when there is
+// one. Otherwise it asks the inner provider and stores answers that carry a
+// source and enough confidence; a store that cannot be read or written only
+// costs a provider call.
+func (p *StoredGeolocationProvider) Geocode(ctx context.Context, address string) (*providers.GeocodedAddress, error) {
+	key := entities.NormalizeGeocodeAddress(address)
+	if key != "" {
+		if stored, err := p.store.Get(ctx, key); err == nil && stored != nil {
+			return storedAddress(stored), nil
+		}
+	}
+
+	geocoded, err := p.inner.Geocode(ctx, address)
+	if err != nil || geocoded == nil {
+		return geocoded, err
+	}
+	if key != "" && geocoded.Source != "" && geocoded.Confidence >= minStoredConfidence {
+		_ = p.store.Save(ctx, &entities.GeocodedLocation{
+			AddressKey:       key,
+			Address:          address,
+			FormattedAddress: geocoded.FormattedAddress,
+			Street:           geocoded.Street,
+			City:             geocoded.City,
+			State:            geocoded.State,
+			ZipCode:          geocoded.ZipCode,
+			Country:          geocoded.Country,
+			Location: entities.Location{
+				Latitude:  geocoded.Coordinates.Latitude,
+				Longitude: geocoded.Coordinates.Longitude,
+			},
+			Source:     geocoded.Source,
+			Confidence: geocoded.Confidence,
+		})
+	}
+	return geocoded, nil
+}
+
+// ReverseGeocode asks the inner provider
+func (p *StoredGeolocationProvider) ReverseGeocode(ctx context.Context, lat, lon float64) (*providers.GeocodedAddress, error) {
+	return p.inner.ReverseGeocode(ctx, lat, lon)
+}
+
+// CalculateDistance asks the inner provider
+func (p *StoredGeolocationProvider) CalculateDistance(ctx context.Context, from, to providers.Coordinates) (float64, error) {
+	return p.inner.CalculateDistance(ctx, from, to)
+}
+
+// GetNearbyPlaces asks the inner provider
+func (p *StoredGeolocationProvider) GetNearbyPlaces(ctx context.Context, center providers.Coordinates, radiusKm float64, placeType string) ([]*providers.Place, error) {
+	return p.inner.GetNearbyPlaces(ctx, center, radiusKm, placeType)
+}
+
+func storedAddress(stored *entities.GeocodedLocation) *providers.GeocodedAddress {
+	return &providers.GeocodedAddress{
+		FormattedAddress: stored.FormattedAddress,
+		Street:           stored.Street,
+		City:             stored.City,
+		State:            stored.State,
+		ZipCode:          stored.ZipCode,
+		Country:          stored.Country,
+		Coordinates: providers.Coordinates{
+			Latitude:  stored.Location.Latitude,
+			Longitude: stored.Location.Longitude,
+		},
+		Source:     stored.Source,
+		Confidence: stored.Confidence,
+	}
+}
@@ -0,0 +1,83 @@
+package geolocation
+
+import (
+	"context"
+	"testing"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/providers"
+	apperrors "github.com/zatekoja/Patientpricediscoverydesign/backend/pkg/errors"
+)
+
+// memoryGeocodeStore is a GeocodeRepository over a map
+type memoryGeocodeStore struct {
+	locations map[string]*entities.GeocodedLocation
+}
+
+func (s *memoryGeocodeStore) Get(ctx context.Context, addressKey string) (*entities.GeocodedLocation, error) {
+	if location, ok := s.locations[addressKey]; ok {
+		return location, nil
+	}
+	return nil, apperrors.NewNotFoundError("geocoded address not found")
+}
+
+func (s *memoryGeocodeStore) Save(ctx context.Context, location *entities.GeocodedLocation) error {
+	s.locations[location.AddressKey] = location
+	return nil
+}
+
+func TestStoredProvider_GeocodesEachAddressOnce(t *testing.T) {
+	store := &memoryGeocodeStore{locations: map[string]*entities.GeocodedLocation{}}
+	inner := &countingGeolocationProvider{address: &providers.GeocodedAddress{
+		City: "Ikeja", State: "Lagos", Country: "Nigeria",
+		Coordinates: providers.Coordinates{Latitude: 6.6005, Longitude: 3.3554},
+		Source:      entities.GeocodeSourceGoogle,
+		Confidence:  0.95,
+	}}
+	provider := NewStoredGeolocationProvider(inner, store)
+
+	if _, err := provider.Geocode(context.Background(), "20 Allen Avenue, Ikeja, Lagos"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	stored, ok := store.locations["20 allen avenue ikeja lagos"]
+	if !ok || stored.Source != entities.GeocodeSourceGoogle || stored.Location.Latitude != 6.6005 {
+		t.Fatalf("expected the answer to be stored under its normalised address, got %+v", store.locations)
+	}
+
+	address, err := provider.Geocode(context.Background(), "20 Allen Avenue,  IKEJA, Lagos.")
+	if err != nil || inner.calls != 1 || address.City != "Ikeja" || address.Source != entities.GeocodeSourceGoogle {
+		t.Fatalf("expected the stored answer without a provider call, got %+v (%d calls)", address, inner.calls)
+	}
+}
+
+func TestStoredProvider_PrefersManualEntriesAndSkipsRoughAnswers(t *testing.T) {
+	store := &memoryGeocodeStore{locations: map[string]*entities.GeocodedLocation{
+		"lagos university teaching hospital idi araba lagos": {
+			Location:   entities.Location{Latitude: 6.5176, Longitude: 3.3566},
+			Source:     entities.GeocodeSourceManual,
+			Confidence: 1,
+		},
+	}}
+	inner := &countingGeolocationProvider{address: &providers.GeocodedAddress{
+		Coordinates: providers.Coordinates{Latitude: 6.45, Longitude: 3.39},
+		Source:      entities.GeocodeSourceGazetteer,
+		Confidence:  0.3,
+	}}
+	provider := NewStoredGeolocationProvider(inner, store)
+
+	address, err := provider.Geocode(context.Background(), "Lagos University Teaching Hospital, Idi-Araba, Lagos")
+	if err != nil || address.Source != entities.GeocodeSourceManual || address.Coordinates.Latitude != 6.5176 || inner.calls != 0 {
+		t.Fatalf("expected the manual entry, got %+v (%d calls)", address, inner.calls)
+	}
+
+	if _, err := provider.Geocode(context.Background(), "3 Hospital Road, Yaba, Lagos"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	inner.address = &providers.GeocodedAddress{Coordinates: providers.Coordinates{Latitude: 37.7749, Longitude: -122.4194}}
+	if _, err := provider.Geocode(context.Background(), "Unknown Plaza"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(store.locations) != 1 {
+		t.Fatalf("expected low-confidence and sourceless answers not to be stored, got %+v", store.locations)
+	}
+}
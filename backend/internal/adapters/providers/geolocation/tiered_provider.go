@@ -0,0 +1,67 @@
+package geolocation
+
+import (
+	"context"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/providers"
+)
+
+// TieredGeolocationProvider answers from the gazetteer when it is as precise
+// as the request and asks the fallback provider otherwise
+type TieredGeolocationProvider struct {
+	gazetteer *GazetteerGeolocationProvider
+	fallback  providers.GeolocationProvider
+}
+
+// NewTieredGeolocationProvider puts the gazetteer in front of fallback
+func NewTieredGeolocationProvider(gazetteer *GazetteerGeolocationProvider, fallback providers.GeolocationProvider) providers.GeolocationProvider {
+	return &TieredGeolocationProvider{gazetteer: gazetteer, fallback: fallback}
+}
+
+// Geocode resolves addresses made only of place names, such as "Surulere,
+// Lagos", from the gazetteer. Other addresses go to the fallback; when it
+// fails, or places the address outside the state the address names, the
+// gazetteer's place for the address is used instead.
+func (p *TieredGeolocationProvider) Geocode(ctx context.Context, address string) (*providers.GeocodedAddress, error) {
+	place, exact := p.gazetteer.ResolveAddress(address)
+	if place != nil && exact {
+		return p.gazetteer.geocodedAddressFor(place, true), nil
+	}
+
+	geocoded, err := p.fallback.Geocode(ctx, address)
+	if place == nil {
+		return geocoded, err
+	}
+	if err == nil && geocoded != nil && p.gazetteer.states[place.State].Bounds.Contains(geocoded.Coordinates) {
+		return geocoded, nil
+	}
+	return p.gazetteer.geocodedAddressFor(place, false), nil
+}
+
+// ReverseGeocode asks the fallback for a street address, falling back to
+// the gazetteer place containing the coordinates
+func (p *TieredGeolocationProvider) ReverseGeocode(ctx context.Context, lat, lon float64) (*providers.GeocodedAddress, error) {
+	address, err := p.fallback.ReverseGeocode(ctx, lat, lon)
+	if err == nil && address != nil {
+		return address, nil
+	}
+	if offline, offlineErr := p.gazetteer.ReverseGeocode(ctx, lat, lon); offlineErr == nil {
+		return offline, nil
+	}
+	return address, err
+}
+
+// CalculateDistance returns the great-circle distance without a network call
+func (p *TieredGeolocationProvider) CalculateDistance(ctx context.Context, from, to providers.Coordinates) (float64, error) {
+	return p.gazetteer.CalculateDistance(ctx, from, to)
+}
+
+// GetNearbyPlaces asks the fallback, which knows facilities and landmarks
+func (p *TieredGeolocationProvider) GetNearbyPlaces(ctx context.Context, center providers.Coordinates, radiusKm float64, placeType string) ([]*providers.Place, error) {
+	return p.fallback.GetNearbyPlaces(ctx, center, radiusKm, placeType)
+}
+
+// LookupPlace returns the gazetteer place a name denotes
+func (p *TieredGeolocationProvider) LookupPlace(name string) (*providers.GazetteerPlace, bool) {
+	return p.gazetteer.LookupPlace(name)
+}
@@ -0,0 +1,76 @@
+package routing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/providers"
+)
+
+// defaultRouteCacheTTL is short because travel times follow the traffic
+const defaultRouteCacheTTL = 60 * 30
+
+// CachedRoutingProvider remembers routes from the inner provider. Points are
+// rounded to about 100m, so nearby searches share routes.
+type CachedRoutingProvider struct {
+	inner providers.RoutingProvider
+	cache providers.CacheProvider
+}
+
+// NewCachedRoutingProvider puts the cache in front of inner
+func NewCachedRoutingProvider(inner providers.RoutingProvider, cache providers.CacheProvider) providers.RoutingProvider {
+	return &CachedRoutingProvider{inner: inner, cache: cache}
+}
+
+// Routes returns cached routes and asks the inner provider for the rest. A
+// cache that cannot be read or written only costs a provider call.
+func (p *CachedRoutingProvider) Routes(ctx context.Context, origin providers.Coordinates, destinations []providers.Coordinates) ([]providers.Route, error) {
+	keys := make([]string, len(destinations))
+	for i, destination := range destinations {
+		keys[i] = routeCacheKey(origin, destination)
+	}
+	cached, err := p.cache.GetMulti(ctx, keys)
+	if err != nil {
+		cached = nil
+	}
+
+	routes := make([]providers.Route, len(destinations))
+	missing := []int{}
+	for i, key := range keys {
+		var route providers.Route
+		if payload, ok := cached[key]; ok && json.Unmarshal(payload, &route) == nil {
+			routes[i] = route
+			continue
+		}
+		missing = append(missing, i)
+	}
+	if len(missing) == 0 {
+		return routes, nil
+	}
+
+	uncached := make([]providers.Coordinates, len(missing))
+	for i, index := range missing {
+		uncached[i] = destinations[index]
+	}
+	fetched, err := p.inner.Routes(ctx, origin, uncached)
+	if err != nil {
+		return nil, err
+	}
+	if len(fetched) != len(uncached) {
+		return nil, fmt.Errorf("routing provider returned %d routes for %d destinations", len(fetched), len(uncached))
+	}
+	items := make(map[string][]byte, len(fetched))
+	for i, index := range missing {
+		routes[index] = fetched[i]
+		if payload, err := json.Marshal(fetched[i]); err == nil {
+			items[keys[index]] = payload
+		}
+	}
+	_ = p.cache.SetMulti(ctx, items, defaultRouteCacheTTL)
+	return routes, nil
+}
+
+func routeCacheKey(origin, destination providers.Coordinates) string {
+	return fmt.Sprintf("routing:v1:%.3f,%.3f:%.3f,%.3f", origin.Latitude, origin.Longitude, destination.Latitude, destination.Longitude)
+}
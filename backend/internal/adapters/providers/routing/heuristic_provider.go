@@ -0,0 +1,110 @@
+package routing
+
+import (
+	"context"
+	"math"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/providers"
+)
+
+const (
+	// roadDetourFactor is how much longer roads are than the straight line
+	roadDetourFactor = 1.35
+	// startMinutes covers getting going and parking at either end
+	startMinutes = 4
+	// urbanKm of every trip are driven at town speed, the rest at open road speed
+	urbanKm = 8
+
+	townSpeedKmh     = 30
+	openRoadSpeedKmh = 60
+	// Lagos traffic is slow throughout the metropolis
+	lagosSpeedKmh = 18
+
+	// lagoonCrossingMinutes is the queue onto a bridge between Lagos Island
+	// and the mainland
+	lagoonCrossingMinutes = 10
+)
+
+// lagosMetro is roughly the built-up Lagos metropolis
+var lagosMetro = struct{ minLat, maxLat, minLon, maxLon float64 }{6.38, 6.72, 3.05, 3.70}
+
+// lagoonBridges are the mainland ends of the Third Mainland and Eko bridges,
+// the ways between Lagos Island and the mainland
+var lagoonBridges = []providers.Coordinates{
+	{Latitude: 6.4930, Longitude: 3.4020},
+	{Latitude: 6.4640, Longitude: 3.3790},
+}
+
+// HeuristicRoutingProvider estimates routes from straight-line distance and
+// typical road speeds, without calling out. It is deterministic, so it also
+// serves tests and evaluation.
+type HeuristicRoutingProvider struct{}
+
+// NewHeuristicRoutingProvider creates a routing provider based on road-speed heuristics
+func NewHeuristicRoutingProvider() providers.RoutingProvider {
+	return &HeuristicRoutingProvider{}
+}
+
+// Routes estimates a route to each destination
+func (p *HeuristicRoutingProvider) Routes(ctx context.Context, origin providers.Coordinates, destinations []providers.Coordinates) ([]providers.Route, error) {
+	routes := make([]providers.Route, len(destinations))
+	for i, destination := range destinations {
+		routes[i] = estimateRoute(origin, destination)
+	}
+	return routes, nil
+}
+
+func estimateRoute(from, to providers.Coordinates) providers.Route {
+	if from == to {
+		return providers.Route{}
+	}
+	if inLagos(from) && inLagos(to) {
+		if onLagosIsland(from) != onLagosIsland(to) {
+			return lagoonCrossing(from, to)
+		}
+		distance := haversineKm(from, to) * roadDetourFactor
+		return providers.Route{DistanceKm: distance, DurationMinutes: startMinutes + distance/lagosSpeedKmh*60}
+	}
+	distance := haversineKm(from, to) * roadDetourFactor
+	town := math.Min(distance, urbanKm)
+	minutes := startMinutes + town/townSpeedKmh*60 + (distance-town)/openRoadSpeedKmh*60
+	return providers.Route{DistanceKm: distance, DurationMinutes: minutes}
+}
+
+// lagoonCrossing routes over whichever bridge makes the shorter trip
+func lagoonCrossing(from, to providers.Coordinates) providers.Route {
+	var best providers.Route
+	for i, bridge := range lagoonBridges {
+		distance := (haversineKm(from, bridge) + haversineKm(bridge, to)) * roadDetourFactor
+		if i == 0 || distance < best.DistanceKm {
+			best.DistanceKm = distance
+		}
+	}
+	best.DurationMinutes = startMinutes + lagoonCrossingMinutes + best.DistanceKm/lagosSpeedKmh*60
+	return best
+}
+
+func inLagos(point providers.Coordinates) bool {
+	return point.Latitude >= lagosMetro.minLat && point.Latitude <= lagosMetro.maxLat &&
+		point.Longitude >= lagosMetro.minLon && point.Longitude <= lagosMetro.maxLon
+}
+
+// onLagosIsland reports whether a Lagos point is south of the lagoon: Lagos
+// Island, Ikoyi, Victoria Island and the Lekki peninsula
+func onLagosIsland(point providers.Coordinates) bool {
+	if point.Longitude > 3.45 {
+		return point.Latitude < 6.50
+	}
+	return point.Latitude < 6.47 && point.Longitude > 3.385
+}
+
+func haversineKm(from, to providers.Coordinates) float64 {
+	const earthRadiusKm = 6371.0
+	toRadians := func(degrees float64) float64 { return degrees * math.Pi / 180 }
+	deltaLat := toRadians(to.Latitude - from.Latitude)
+	deltaLon := toRadians(to.Longitude - from.Longitude)
+	a := math.Sin(deltaLat/2)*math.Sin(deltaLat/2) +
+		math.Cos(toRadians(from.Latitude))*math.Cos(toRadians(to.Latitude))*
+			math.Sin(deltaLon/2)*math.Sin(deltaLon/2)
+	return earthRadiusKm * 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
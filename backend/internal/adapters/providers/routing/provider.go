@@ -0,0 +1,18 @@
+package routing
+
+import (
+	"fmt"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/providers"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/pkg/config"
+)
+
+// NewProvider returns the routing provider named in the configuration
+func NewProvider(cfg *config.Config) (providers.RoutingProvider, error) {
+	switch cfg.Routing.Provider {
+	case "", "local":
+		return NewHeuristicRoutingProvider(), nil
+	default:
+		return nil, fmt.Errorf("unknown routing provider %q", cfg.Routing.Provider)
+	}
+}
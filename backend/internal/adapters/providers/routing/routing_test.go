@@ -0,0 +1,111 @@
+package routing
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/providers"
+)
+
+var (
+	yaba          = providers.Coordinates{Latitude: 6.5095, Longitude: 3.3711}
+	surulere      = providers.Coordinates{Latitude: 6.4969, Longitude: 3.3553}
+	victoriaIsle  = providers.Coordinates{Latitude: 6.4281, Longitude: 3.4219}
+	lekkiPhaseOne = providers.Coordinates{Latitude: 6.4474, Longitude: 3.4723}
+	ibadan        = providers.Coordinates{Latitude: 7.3775, Longitude: 3.9470}
+)
+
+func TestHeuristicProvider_LagoonCrossingTakesLonger(t *testing.T) {
+	provider := NewHeuristicRoutingProvider()
+	routes, err := provider.Routes(context.Background(), yaba, []providers.Coordinates{surulere, victoriaIsle, yaba})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(routes) != 3 {
+		t.Fatalf("expected one route per destination, got %d", len(routes))
+	}
+	if routes[2] != (providers.Route{}) {
+		t.Fatalf("expected an empty route to the origin, got %+v", routes[2])
+	}
+	if straight := haversineKm(yaba, victoriaIsle); routes[1].DistanceKm <= straight*roadDetourFactor {
+		t.Fatalf("expected the crossing to detour over a bridge, got %.1fkm for %.1fkm straight", routes[1].DistanceKm, straight)
+	}
+	if routes[1].DurationMinutes <= routes[0].DurationMinutes+lagoonCrossingMinutes {
+		t.Fatalf("expected Victoria Island to be much further than Surulere from Yaba, got %+v", routes)
+	}
+
+	// Island to island does not cross the lagoon
+	routes, _ = provider.Routes(context.Background(), victoriaIsle, []providers.Coordinates{lekkiPhaseOne})
+	if routes[0].DistanceKm != haversineKm(victoriaIsle, lekkiPhaseOne)*roadDetourFactor {
+		t.Fatalf("expected no bridge detour on the island, got %+v", routes[0])
+	}
+}
+
+func TestHeuristicProvider_OpenRoadIsFasterThanTown(t *testing.T) {
+	routes, _ := NewHeuristicRoutingProvider().Routes(context.Background(), yaba, []providers.Coordinates{ibadan})
+	speed := routes[0].DistanceKm / (routes[0].DurationMinutes / 60)
+	if speed < townSpeedKmh || speed > openRoadSpeedKmh {
+		t.Fatalf("expected an intercity average between town and open road speed, got %.0fkm/h", speed)
+	}
+}
+
+// countingRoutingProvider counts the destinations it is asked about
+type countingRoutingProvider struct {
+	destinations int
+}
+
+func (p *countingRoutingProvider) Routes(ctx context.Context, origin providers.Coordinates, destinations []providers.Coordinates) ([]providers.Route, error) {
+	p.destinations += len(destinations)
+	return NewHeuristicRoutingProvider().Routes(ctx, origin, destinations)
+}
+
+// memoryCache is the part of a CacheProvider the routing cache uses
+type memoryCache struct {
+	providers.CacheProvider
+	values map[string][]byte
+}
+
+func (c *memoryCache) GetMulti(ctx context.Context, keys []string) (map[string][]byte, error) {
+	found := map[string][]byte{}
+	for _, key := range keys {
+		if value, ok := c.values[key]; ok {
+			found[key] = value
+		}
+	}
+	return found, nil
+}
+
+func (c *memoryCache) SetMulti(ctx context.Context, items map[string][]byte, expirationSeconds int) error {
+	for key, value := range items {
+		c.values[key] = value
+	}
+	return nil
+}
+
+func (c *memoryCache) TTL(ctx context.Context, key string) (time.Duration, error) {
+	return 0, nil
+}
+
+func TestCachedProvider_RoutesEachPairOnce(t *testing.T) {
+	inner := &countingRoutingProvider{}
+	provider := NewCachedRoutingProvider(inner, &memoryCache{values: map[string][]byte{}})
+	ctx := context.Background()
+
+	first, err := provider.Routes(ctx, yaba, []providers.Coordinates{surulere, victoriaIsle})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// A few metres away from Yaba rounds to the same origin
+	nearby := providers.Coordinates{Latitude: yaba.Latitude + 0.0001, Longitude: yaba.Longitude}
+	second, err := provider.Routes(ctx, nearby, []providers.Coordinates{victoriaIsle, ibadan, surulere})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if inner.destinations != 3 {
+		t.Fatalf("expected only Ibadan to be routed again, got %d destinations routed", inner.destinations)
+	}
+	if second[0] != first[1] || second[2] != first[0] || second[1].DistanceKm == 0 {
+		t.Fatalf("expected cached routes in destination order, got %+v then %+v", first, second)
+	}
+}
@@ -0,0 +1,285 @@
+package scheduling
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/providers"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/repositories"
+	apperrors "github.com/zatekoja/Patientpricediscoverydesign/backend/pkg/errors"
+)
+
+// MaxNativeAvailabilityRange bounds how far ahead slots are generated per request.
+const MaxNativeAvailabilityRange = 62 * 24 * time.Hour
+
+// slotNamespace scopes the deterministic slot IDs derived from template and start time.
+var slotNamespace = uuid.MustParse("8b6f3c1e-5d2a-4e7b-9c0f-1a2b3c4d5e6f")
+
+// NativeAdapter implements AppointmentProvider on facility-managed calendars in
+// Postgres. Slots are generated from weekly templates and materialized on
+// demand; capacity is enforced by the reservation repository's row locks.
+type NativeAdapter struct {
+	calendars    repositories.CalendarRepository
+	slots        repositories.AvailabilityRepository
+	reservations repositories.SlotReservationRepository
+	now          func() time.Time
+}
+
+// NewNativeAdapter creates a native calendar provider.
+func NewNativeAdapter(
+	calendars repositories.CalendarRepository,
+	slots repositories.AvailabilityRepository,
+	reservations repositories.SlotReservationRepository,
+) *NativeAdapter {
+	return &NativeAdapter{
+		calendars:    calendars,
+		slots:        slots,
+		reservations: reservations,
+		now:          time.Now,
+	}
+}
+
+var _ providers.NativeCalendarProvider = (*NativeAdapter)(nil)
+
+// GetAvailableSlots returns slots with remaining capacity. externalID is the facility ID.
+func (n *NativeAdapter) GetAvailableSlots(ctx context.Context, externalID string, from, to time.Time) ([]entities.AvailabilitySlot, error) {
+	slots, err := n.ListSlots(ctx, externalID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	available := make([]entities.AvailabilitySlot, 0, len(slots))
+	for _, slot := range slots {
+		if slot.Remaining() > 0 {
+			available = append(available, slot)
+		}
+	}
+	return available, nil
+}
+
+// ListSlots returns every slot offered in the range, including full ones.
+func (n *NativeAdapter) ListSlots(ctx context.Context, facilityID string, from, to time.Time) ([]entities.AvailabilitySlot, error) {
+	if !to.After(from) {
+		return nil, apperrors.NewValidationError("to must be after from")
+	}
+	if to.Sub(from) > MaxNativeAvailabilityRange {
+		return nil, apperrors.NewValidationError(fmt.Sprintf("availability range cannot exceed %d days", int(MaxNativeAvailabilityRange.Hours()/24)))
+	}
+
+	calendar, err := n.calendars.GetCalendar(ctx, facilityID)
+	if err != nil {
+		return nil, err
+	}
+	if !calendar.Enabled {
+		return nil, apperrors.NewValidationError("facility calendar is disabled")
+	}
+	templates, err := n.calendars.ListTemplates(ctx, facilityID)
+	if err != nil {
+		return nil, err
+	}
+	blackouts, err := n.calendars.ListBlackouts(ctx, facilityID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	now := n.now().UTC()
+	if err := n.slots.EnsureSlots(ctx, generateSlots(calendar, templates, from, to, now)); err != nil {
+		return nil, err
+	}
+	stored, err := n.slots.ListByFacility(ctx, facilityID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	// Slots from deleted templates, covered by a blackout or already started are not offered.
+	active := make(map[string]struct{}, len(templates))
+	for _, t := range templates {
+		active[t.ID] = struct{}{}
+	}
+	offered := make([]entities.AvailabilitySlot, 0, len(stored))
+	for _, slot := range stored {
+		if slot.TemplateID == nil || !slot.StartTime.After(now) {
+			continue
+		}
+		if _, ok := active[*slot.TemplateID]; !ok {
+			continue
+		}
+		if blackedOut(blackouts, slot) {
+			continue
+		}
+		offered = append(offered, *slot)
+	}
+	return offered, nil
+}
+
+// HoldSlot reserves a slot for the facility's hold period during checkout.
+func (n *NativeAdapter) HoldSlot(ctx context.Context, facilityID, slotID string) (*entities.SlotReservation, error) {
+	slot, err := n.offeredSlot(ctx, facilityID, slotID)
+	if err != nil {
+		return nil, err
+	}
+	calendar, err := n.calendars.GetCalendar(ctx, facilityID)
+	if err != nil {
+		return nil, err
+	}
+	holdMinutes := calendar.HoldMinutes
+	if holdMinutes <= 0 {
+		holdMinutes = entities.DefaultSlotHoldMinutes
+	}
+
+	now := n.now().UTC()
+	return n.reservations.Hold(ctx, slot.ID, now.Add(time.Duration(holdMinutes)*time.Minute), now)
+}
+
+// ReleaseHold gives a held slot back before the hold expires.
+func (n *NativeAdapter) ReleaseHold(ctx context.Context, facilityID, holdID string) error {
+	hold, err := n.reservations.GetByID(ctx, holdID)
+	if err != nil {
+		return err
+	}
+	if hold.FacilityID != facilityID {
+		return apperrors.NewNotFoundError(fmt.Sprintf("hold with id %s not found", holdID))
+	}
+	return n.reservations.Release(ctx, holdID)
+}
+
+// CreateAppointment books the slot chosen by the appointment's hold, slot or
+// scheduled time, in that order of preference, and returns the booking ID.
+func (n *NativeAdapter) CreateAppointment(ctx context.Context, appointment *entities.Appointment) (string, string, error) {
+	facilityID := appointment.FacilityID
+	now := n.now().UTC()
+
+	var booking *entities.SlotReservation
+	var err error
+	switch {
+	case appointment.HoldID != "":
+		hold, getErr := n.reservations.GetByID(ctx, appointment.HoldID)
+		if getErr != nil {
+			return "", "", getErr
+		}
+		if hold.FacilityID != facilityID {
+			return "", "", apperrors.NewValidationError("hold belongs to a different facility")
+		}
+		booking, err = n.reservations.ConfirmHold(ctx, hold.ID, now)
+	case appointment.SlotID != "":
+		if _, err := n.offeredSlot(ctx, facilityID, appointment.SlotID); err != nil {
+			return "", "", err
+		}
+		booking, err = n.reservations.Book(ctx, appointment.SlotID, now)
+	default:
+		slot, findErr := n.availableAt(ctx, facilityID, appointment.ScheduledAt)
+		if findErr != nil {
+			return "", "", findErr
+		}
+		booking, err = n.reservations.Book(ctx, slot.ID, now)
+	}
+	if err != nil {
+		return "", "", err
+	}
+
+	appointment.ScheduledAt = booking.StartTime
+	return booking.ID, "", nil
+}
+
+// CancelAppointment cancels the booking. externalID is the booking ID.
+func (n *NativeAdapter) CancelAppointment(ctx context.Context, externalID string, reason string) error {
+	return n.reservations.Cancel(ctx, externalID)
+}
+
+// offeredSlot returns the slot if it is currently offered by the facility's calendar.
+func (n *NativeAdapter) offeredSlot(ctx context.Context, facilityID, slotID string) (*entities.AvailabilitySlot, error) {
+	slot, err := n.slots.GetByID(ctx, slotID)
+	if err != nil {
+		return nil, err
+	}
+	if slot.FacilityID != facilityID {
+		return nil, apperrors.NewNotFoundError(fmt.Sprintf("availability slot with id %s not found", slotID))
+	}
+	offered, err := n.ListSlots(ctx, facilityID, slot.StartTime, slot.StartTime.Add(time.Second))
+	if err != nil {
+		return nil, err
+	}
+	for i := range offered {
+		if offered[i].ID == slotID {
+			return &offered[i], nil
+		}
+	}
+	return nil, apperrors.NewConflictError("slot is no longer offered")
+}
+
+// availableAt finds a slot with remaining capacity starting at the given time.
+func (n *NativeAdapter) availableAt(ctx context.Context, facilityID string, start time.Time) (*entities.AvailabilitySlot, error) {
+	slots, err := n.GetAvailableSlots(ctx, facilityID, start, start.Add(time.Second))
+	if err != nil {
+		return nil, err
+	}
+	if len(slots) == 0 {
+		return nil, apperrors.NewConflictError("no available slot at the requested time")
+	}
+	return &slots[0], nil
+}
+
+// generateSlots expands weekly templates into concrete slots starting in
+// [from, to) and after now. Template times are wall-clock in the calendar
+// timezone, so slots stay at the same local time across DST changes.
+func generateSlots(calendar *entities.FacilityCalendar, templates []*entities.SlotTemplate, from, to, now time.Time) []*entities.AvailabilitySlot {
+	loc, err := time.LoadLocation(calendar.Timezone)
+	if err != nil || calendar.Timezone == "" {
+		loc = time.UTC
+	}
+
+	var slots []*entities.AvailabilitySlot
+	localFrom := from.In(loc)
+	day := time.Date(localFrom.Year(), localFrom.Month(), localFrom.Day(), 0, 0, 0, 0, loc)
+	for ; day.Before(to); day = day.AddDate(0, 0, 1) {
+		date := day.Format("2006-01-02")
+		for _, t := range templates {
+			if t.Weekday != day.Weekday() || !templateValidOn(t, date) {
+				continue
+			}
+			startMin, err1 := entities.ParseClock(t.StartTime)
+			endMin, err2 := entities.ParseClock(t.EndTime)
+			if err1 != nil || err2 != nil || t.SlotMinutes <= 0 {
+				continue
+			}
+			for m := startMin; m+t.SlotMinutes <= endMin; m += t.SlotMinutes {
+				start := time.Date(day.Year(), day.Month(), day.Day(), m/60, m%60, 0, 0, loc)
+				if start.Before(from) || !start.Before(to) || !start.After(now) {
+					continue
+				}
+				templateID := t.ID
+				slots = append(slots, &entities.AvailabilitySlot{
+					ID:         uuid.NewSHA1(slotNamespace, []byte(t.ID+"|"+start.UTC().Format(time.RFC3339))).String(),
+					FacilityID: t.FacilityID,
+					WardID:     t.WardID,
+					TemplateID: &templateID,
+					StartTime:  start.UTC(),
+					EndTime:    start.Add(time.Duration(t.SlotMinutes) * time.Minute).UTC(),
+					Capacity:   t.Capacity,
+				})
+			}
+		}
+	}
+	return slots
+}
+
+func templateValidOn(t *entities.SlotTemplate, date string) bool {
+	if t.ValidFrom != nil && date < t.ValidFrom.Format("2006-01-02") {
+		return false
+	}
+	if t.ValidUntil != nil && date > t.ValidUntil.Format("2006-01-02") {
+		return false
+	}
+	return true
+}
+
+func blackedOut(blackouts []*entities.CalendarBlackout, slot *entities.AvailabilitySlot) bool {
+	for _, b := range blackouts {
+		if b.Covers(slot) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,324 @@
+package scheduling
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	apperrors "github.com/zatekoja/Patientpricediscoverydesign/backend/pkg/errors"
+)
+
+// memCalendars is an in-memory CalendarRepository.
+type memCalendars struct {
+	calendar  *entities.FacilityCalendar
+	templates []*entities.SlotTemplate
+	blackouts []*entities.CalendarBlackout
+}
+
+func (m *memCalendars) GetCalendar(ctx context.Context, facilityID string) (*entities.FacilityCalendar, error) {
+	if m.calendar == nil || m.calendar.FacilityID != facilityID {
+		return nil, apperrors.NewNotFoundError("calendar not found")
+	}
+	return m.calendar, nil
+}
+
+func (m *memCalendars) UpsertCalendar(ctx context.Context, calendar *entities.FacilityCalendar) error {
+	m.calendar = calendar
+	return nil
+}
+
+func (m *memCalendars) ListTemplates(ctx context.Context, facilityID string) ([]*entities.SlotTemplate, error) {
+	return m.templates, nil
+}
+
+func (m *memCalendars) CreateTemplate(ctx context.Context, template *entities.SlotTemplate) error {
+	m.templates = append(m.templates, template)
+	return nil
+}
+
+func (m *memCalendars) DeleteTemplate(ctx context.Context, facilityID, id string) error {
+	for i, t := range m.templates {
+		if t.ID == id {
+			m.templates = append(m.templates[:i], m.templates[i+1:]...)
+			return nil
+		}
+	}
+	return apperrors.NewNotFoundError("slot template not found")
+}
+
+func (m *memCalendars) ListBlackouts(ctx context.Context, facilityID string, from, to time.Time) ([]*entities.CalendarBlackout, error) {
+	return m.blackouts, nil
+}
+
+func (m *memCalendars) CreateBlackout(ctx context.Context, blackout *entities.CalendarBlackout) error {
+	m.blackouts = append(m.blackouts, blackout)
+	return nil
+}
+
+func (m *memCalendars) DeleteBlackout(ctx context.Context, facilityID, id string) error {
+	return nil
+}
+
+// memSlots is an in-memory AvailabilityRepository and SlotReservationRepository
+// that enforces capacity the same way the Postgres adapters do.
+type memSlots struct {
+	slots        map[string]*entities.AvailabilitySlot
+	reservations map[string]*entities.SlotReservation
+	now          time.Time
+}
+
+func newMemSlots(now time.Time) *memSlots {
+	return &memSlots{
+		slots:        map[string]*entities.AvailabilitySlot{},
+		reservations: map[string]*entities.SlotReservation{},
+		now:          now,
+	}
+}
+
+func (m *memSlots) withCounts(slot *entities.AvailabilitySlot) *entities.AvailabilitySlot {
+	out := *slot
+	for _, r := range m.reservations {
+		if r.SlotID != slot.ID {
+			continue
+		}
+		switch {
+		case r.Status == entities.SlotReservationBooked:
+			out.Booked++
+		case r.Status == entities.SlotReservationHeld && r.ExpiresAt.After(m.now):
+			out.Held++
+		}
+	}
+	return &out
+}
+
+func (m *memSlots) Create(ctx context.Context, slot *entities.AvailabilitySlot) error {
+	m.slots[slot.ID] = slot
+	return nil
+}
+
+func (m *memSlots) GetByID(ctx context.Context, id string) (*entities.AvailabilitySlot, error) {
+	slot, ok := m.slots[id]
+	if !ok {
+		return nil, apperrors.NewNotFoundError("availability slot not found")
+	}
+	return m.withCounts(slot), nil
+}
+
+func (m *memSlots) ListByFacility(ctx context.Context, facilityID string, from, to time.Time) ([]*entities.AvailabilitySlot, error) {
+	var out []*entities.AvailabilitySlot
+	for _, slot := range m.slots {
+		if slot.FacilityID == facilityID && !slot.StartTime.Before(from) && slot.StartTime.Before(to) {
+			out = append(out, m.withCounts(slot))
+		}
+	}
+	return out, nil
+}
+
+func (m *memSlots) EnsureSlots(ctx context.Context, slots []*entities.AvailabilitySlot) error {
+	for _, slot := range slots {
+		if _, ok := m.slots[slot.ID]; !ok {
+			m.slots[slot.ID] = slot
+		}
+	}
+	return nil
+}
+
+func (m *memSlots) Update(ctx context.Context, slot *entities.AvailabilitySlot) error { return nil }
+func (m *memSlots) Delete(ctx context.Context, id string) error                       { return nil }
+
+// memReservations shares memSlots' state under the SlotReservationRepository method set.
+type memReservations struct{ *memSlots }
+
+func (m memReservations) GetByID(ctx context.Context, id string) (*entities.SlotReservation, error) {
+	r, ok := m.reservations[id]
+	if !ok {
+		return nil, apperrors.NewNotFoundError("reservation not found")
+	}
+	return r, nil
+}
+
+func (m memReservations) reserve(slotID string, status entities.SlotReservationStatus, expiresAt *time.Time) (*entities.SlotReservation, error) {
+	slot, ok := m.slots[slotID]
+	if !ok {
+		return nil, apperrors.NewNotFoundError("availability slot not found")
+	}
+	if m.withCounts(slot).Remaining() == 0 {
+		return nil, apperrors.NewConflictError("slot is fully booked")
+	}
+	r := &entities.SlotReservation{
+		ID: uuid.New().String(), SlotID: slotID, FacilityID: slot.FacilityID,
+		StartTime: slot.StartTime, Status: status, ExpiresAt: expiresAt,
+	}
+	m.reservations[r.ID] = r
+	return r, nil
+}
+
+func (m memReservations) Hold(ctx context.Context, slotID string, expiresAt, now time.Time) (*entities.SlotReservation, error) {
+	return m.reserve(slotID, entities.SlotReservationHeld, &expiresAt)
+}
+
+func (m memReservations) Book(ctx context.Context, slotID string, now time.Time) (*entities.SlotReservation, error) {
+	return m.reserve(slotID, entities.SlotReservationBooked, nil)
+}
+
+func (m memReservations) ConfirmHold(ctx context.Context, holdID string, now time.Time) (*entities.SlotReservation, error) {
+	r, ok := m.reservations[holdID]
+	if !ok || r.Status != entities.SlotReservationHeld || !r.ExpiresAt.After(now) {
+		return nil, apperrors.NewConflictError("hold has expired")
+	}
+	r.Status = entities.SlotReservationBooked
+	r.ExpiresAt = nil
+	return r, nil
+}
+
+func (m memReservations) Release(ctx context.Context, holdID string) error {
+	m.reservations[holdID].Status = entities.SlotReservationReleased
+	return nil
+}
+
+func (m memReservations) Cancel(ctx context.Context, bookingID string) error {
+	m.reservations[bookingID].Status = entities.SlotReservationCancelled
+	return nil
+}
+
+// Monday 2 March 2026, 06:00 in Lagos (UTC+1, no DST).
+var testNow = time.Date(2026, 3, 2, 5, 0, 0, 0, time.UTC)
+
+func newTestNativeAdapter(capacity int) (*NativeAdapter, *memCalendars, *memSlots) {
+	calendars := &memCalendars{
+		calendar: &entities.FacilityCalendar{FacilityID: "fac-1", Enabled: true, Timezone: "Africa/Lagos", HoldMinutes: 10},
+		templates: []*entities.SlotTemplate{{
+			ID: "tpl-1", FacilityID: "fac-1", Weekday: time.Monday,
+			StartTime: "09:00", EndTime: "11:00", SlotMinutes: 30, Capacity: capacity,
+		}},
+	}
+	slots := newMemSlots(testNow)
+	adapter := NewNativeAdapter(calendars, slots, memReservations{slots})
+	adapter.now = func() time.Time { return slots.now }
+	return adapter, calendars, slots
+}
+
+func TestGenerateSlots_UsesCalendarTimezoneAndValidity(t *testing.T) {
+	_, calendars, _ := newTestNativeAdapter(2)
+	from := testNow
+	to := from.Add(14 * 24 * time.Hour)
+
+	slots := generateSlots(calendars.calendar, calendars.templates, from, to, testNow)
+	require.Len(t, slots, 8, "two Mondays with four 30-minute slots each")
+	assert.Equal(t, time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC), slots[0].StartTime, "09:00 Lagos is 08:00 UTC")
+	assert.Equal(t, slots[0].StartTime.Add(30*time.Minute), slots[0].EndTime)
+	assert.Equal(t, 2, slots[0].Capacity)
+
+	again := generateSlots(calendars.calendar, calendars.templates, from, to, testNow)
+	assert.Equal(t, slots[3].ID, again[3].ID, "slot IDs are deterministic")
+
+	until := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	calendars.templates[0].ValidUntil = &until
+	assert.Len(t, generateSlots(calendars.calendar, calendars.templates, from, to, testNow), 4)
+
+	// Slots that have already started are not generated.
+	assert.Len(t, generateSlots(calendars.calendar, calendars.templates, from, to, testNow.Add(3*time.Hour+30*time.Minute)), 2)
+}
+
+func TestNativeAdapter_FiltersBlackoutsAndDeletedTemplates(t *testing.T) {
+	adapter, calendars, _ := newTestNativeAdapter(1)
+	ctx := context.Background()
+	from, to := testNow, testNow.Add(24*time.Hour)
+
+	calendars.blackouts = []*entities.CalendarBlackout{{
+		FacilityID: "fac-1",
+		StartsAt:   time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC),
+		EndsAt:     time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC),
+	}}
+	slots, err := adapter.GetAvailableSlots(ctx, "fac-1", from, to)
+	require.NoError(t, err)
+	assert.Len(t, slots, 2, "the first hour is blacked out")
+
+	calendars.templates = nil
+	slots, err = adapter.GetAvailableSlots(ctx, "fac-1", from, to)
+	require.NoError(t, err)
+	assert.Empty(t, slots, "slots from a deleted template are not offered")
+}
+
+func TestNativeAdapter_HoldsConsumeCapacityUntilConfirmed(t *testing.T) {
+	adapter, _, store := newTestNativeAdapter(1)
+	ctx := context.Background()
+	from, to := testNow, testNow.Add(24*time.Hour)
+
+	slots, err := adapter.GetAvailableSlots(ctx, "fac-1", from, to)
+	require.NoError(t, err)
+	require.Len(t, slots, 4)
+	slot := slots[0]
+
+	hold, err := adapter.HoldSlot(ctx, "fac-1", slot.ID)
+	require.NoError(t, err)
+	assert.Equal(t, testNow.Add(10*time.Minute), *hold.ExpiresAt)
+
+	available, err := adapter.GetAvailableSlots(ctx, "fac-1", from, to)
+	require.NoError(t, err)
+	assert.Len(t, available, 3, "a held slot is not offered to other patients")
+
+	_, _, err = adapter.CreateAppointment(ctx, &entities.Appointment{FacilityID: "fac-1", SlotID: slot.ID})
+	var appErr *apperrors.AppError
+	require.True(t, errors.As(err, &appErr))
+	assert.Equal(t, apperrors.ErrorTypeConflict, appErr.Type)
+
+	appointment := &entities.Appointment{FacilityID: "fac-1", HoldID: hold.ID}
+	bookingID, _, err := adapter.CreateAppointment(ctx, appointment)
+	require.NoError(t, err)
+	assert.Equal(t, hold.ID, bookingID)
+	assert.Equal(t, slot.StartTime, appointment.ScheduledAt)
+
+	// Once held capacity expires the slot is offered again.
+	second, err := adapter.HoldSlot(ctx, "fac-1", slots[1].ID)
+	require.NoError(t, err)
+	store.now = testNow.Add(11 * time.Minute)
+	_, _, err = adapter.CreateAppointment(ctx, &entities.Appointment{FacilityID: "fac-1", HoldID: second.ID})
+	require.Error(t, err, "expired holds cannot be confirmed")
+	available, err = adapter.GetAvailableSlots(ctx, "fac-1", from, to)
+	require.NoError(t, err)
+	assert.Len(t, available, 3)
+}
+
+func TestNativeAdapter_BooksByScheduledTime(t *testing.T) {
+	adapter, _, _ := newTestNativeAdapter(1)
+	ctx := context.Background()
+	at := time.Date(2026, 3, 2, 9, 30, 0, 0, time.UTC)
+
+	_, _, err := adapter.CreateAppointment(ctx, &entities.Appointment{FacilityID: "fac-1", ScheduledAt: at})
+	require.NoError(t, err)
+
+	_, _, err = adapter.CreateAppointment(ctx, &entities.Appointment{FacilityID: "fac-1", ScheduledAt: at})
+	var appErr *apperrors.AppError
+	require.True(t, errors.As(err, &appErr))
+	assert.Equal(t, apperrors.ErrorTypeConflict, appErr.Type)
+}
+
+func TestProviderRouter_Resolve(t *testing.T) {
+	calendars := &memCalendars{}
+	native, external := NewMockAdapter(), NewMockAdapter()
+	router := NewProviderRouter(calendars, native, external)
+	facility := &entities.Facility{ID: "fac-1", SchedulingExternalID: " consult-30 "}
+
+	target, err := router.Resolve(context.Background(), facility)
+	require.NoError(t, err)
+	assert.Same(t, external, target.Provider)
+	assert.Equal(t, "consult-30", target.ExternalID)
+
+	calendars.calendar = &entities.FacilityCalendar{FacilityID: "fac-1", Enabled: true}
+	target, err = router.Resolve(context.Background(), facility)
+	require.NoError(t, err)
+	assert.Same(t, native, target.Provider)
+	assert.Equal(t, "fac-1", target.ExternalID)
+	assert.Equal(t, entities.BookingMethodNative, target.Method)
+
+	calendars.calendar.Enabled = false
+	target, err = router.Resolve(context.Background(), facility)
+	require.NoError(t, err)
+	assert.Equal(t, entities.BookingMethodAPI, target.Method, "disabled calendars fall back to the external provider")
+}
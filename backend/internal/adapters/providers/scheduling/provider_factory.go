@@ -4,10 +4,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
 	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/providers"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/repositories"
+	apperrors "github.com/zatekoja/Patientpricediscoverydesign/backend/pkg/errors"
 )
 
 // ErrMissingExternalID indicates the facility has no scheduling identifier configured.
@@ -116,3 +119,44 @@ func (p *FallbackProvider) CancelAppointment(ctx context.Context, externalID str
 	}
 	return err
 }
+
+// ProviderRouter sends facilities with an enabled native calendar to the
+// native provider and everyone else to the external provider.
+type ProviderRouter struct {
+	calendars repositories.CalendarRepository
+	native    providers.AppointmentProvider
+	external  providers.AppointmentProvider
+}
+
+// NewProviderRouter creates a per-facility provider resolver.
+func NewProviderRouter(
+	calendars repositories.CalendarRepository,
+	native providers.AppointmentProvider,
+	external providers.AppointmentProvider,
+) *ProviderRouter {
+	return &ProviderRouter{calendars: calendars, native: native, external: external}
+}
+
+// Resolve returns the scheduling target for the facility.
+func (r *ProviderRouter) Resolve(ctx context.Context, facility *entities.Facility) (*providers.SchedulingTarget, error) {
+	calendar, err := r.calendars.GetCalendar(ctx, facility.ID)
+	if err != nil {
+		var appErr *apperrors.AppError
+		if !errors.As(err, &appErr) || appErr.Type != apperrors.ErrorTypeNotFound {
+			return nil, err
+		}
+	}
+	if calendar != nil && calendar.Enabled {
+		return &providers.SchedulingTarget{
+			Provider:   r.native,
+			ExternalID: facility.ID,
+			Method:     entities.BookingMethodNative,
+		}, nil
+	}
+
+	return &providers.SchedulingTarget{
+		Provider:   r.external,
+		ExternalID: strings.TrimSpace(facility.SchedulingExternalID),
+		Method:     entities.BookingMethodAPI,
+	}, nil
+}
@@ -1,6 +1,7 @@
 package search
 
 import (
+	"sort"
 	"strings"
 
 	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
@@ -62,13 +63,16 @@ func add(set map[string]struct{}, terms ...string) {
 	}
 }
 
+// toSlice returns up to limit terms in sorted order, so the same enrichments
+// always build the same document
 func toSlice(set map[string]struct{}, limit int) []string {
 	result := make([]string, 0, len(set))
 	for k := range set {
 		result = append(result, k)
-		if len(result) >= limit {
-			break
-		}
+	}
+	sort.Strings(result)
+	if len(result) > limit {
+		result = result[:limit]
 	}
 	return result
 }
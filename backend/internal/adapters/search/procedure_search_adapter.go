@@ -0,0 +1,197 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/typesense/typesense-go/v2/typesense/api"
+	"github.com/typesense/typesense-go/v2/typesense/api/pointer"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/repositories"
+	tsclient "github.com/zatekoja/Patientpricediscoverydesign/backend/internal/infrastructure/clients/typesense"
+)
+
+// procedureQueryBy lists the procedure fields searched, most telling first
+const (
+	procedureQueryBy        = "display_name,name,code,synonyms,lay_terms,tags,conditions,symptoms"
+	procedureQueryByWeights = "8,7,6,5,5,3,2,1"
+)
+
+// ProcedureSearchAdapter implements procedure search using Typesense
+type ProcedureSearchAdapter struct {
+	client *tsclient.Client
+	models *embeddingModels
+}
+
+var _ repositories.ProcedureSearchRepository = (*ProcedureSearchAdapter)(nil)
+
+// NewProcedureSearchAdapter creates a new procedure search adapter
+func NewProcedureSearchAdapter(client *tsclient.Client) *ProcedureSearchAdapter {
+	return &ProcedureSearchAdapter{client: client, models: newEmbeddingModels(client.EmbeddingModels)}
+}
+
+// SearchProcedures searches active procedures, ranking equal matches by how
+// many facilities offer them
+func (a *ProcedureSearchAdapter) SearchProcedures(ctx context.Context, params repositories.ProcedureSearchParams) ([]*entities.ProcedureSearchResult, int, error) {
+	query := strings.TrimSpace(params.Query)
+	if query == "" {
+		query = "*"
+	}
+	limit := params.Limit
+	if limit <= 0 {
+		limit = 20
+	}
+
+	filter := "is_active:=true"
+	if category := strings.TrimSpace(params.Category); category != "" {
+		filter = fmt.Sprintf("%s && category:=%s", filter, escapeFilterValue(category))
+	}
+
+	searchParams := &api.SearchCollectionParams{
+		Q:              pointer.String(query),
+		QueryBy:        pointer.String(procedureQueryBy),
+		QueryByWeights: pointer.String(procedureQueryByWeights),
+		FilterBy:       pointer.String(filter),
+		SortBy:         pointer.String("_text_match:desc,facility_count:desc"),
+		Page:           pointer.Int(params.Offset/limit + 1),
+		PerPage:        pointer.Int(limit),
+		NumTypos:       pointer.String("2"),
+		MinLen1typo:    pointer.Int(4),
+		MinLen2typo:    pointer.Int(7),
+		ExcludeFields:  pointer.String(tsclient.EmbeddingField),
+	}
+	if len(params.QueryEmbedding) > 0 && a.models.matches(ctx, tsclient.ProceduresCollection, params.EmbeddingModel) {
+		// Hybrid results are ordered by the fused rank
+		searchParams.SortBy = nil
+		searchParams.VectorQuery = pointer.String(vectorQuery(params.QueryEmbedding, params.Offset+limit, params.VectorWeight, params.MinSimilarity))
+	}
+
+	result, err := a.client.Client().Collection(tsclient.ProceduresCollection).Documents().Search(ctx, searchParams)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to search procedures: %w", err)
+	}
+
+	procedures := []*entities.ProcedureSearchResult{}
+	if result.Hits != nil {
+		for _, hit := range *result.Hits {
+			if hit.Document != nil {
+				procedures = append(procedures, procedureFromDocument(*hit.Document))
+			}
+		}
+	}
+	found := 0
+	if result.Found != nil {
+		found = *result.Found
+	}
+	return procedures, found, nil
+}
+
+// SuggestConcepts completes conditions and specialties from the facet values
+// of active procedures
+func (a *ProcedureSearchAdapter) SuggestConcepts(ctx context.Context, query string, limit int) ([]entities.ConceptSuggestion, []entities.ConceptSuggestion, error) {
+	trimmed := strings.TrimSpace(query)
+	if trimmed == "" {
+		return nil, nil, nil
+	}
+	if limit <= 0 {
+		limit = 5
+	}
+
+	conditions, err := a.facetValues(ctx, "conditions", trimmed, limit)
+	if err != nil {
+		return nil, nil, err
+	}
+	specialties, err := a.facetValues(ctx, "specialties", trimmed, limit)
+	if err != nil {
+		return nil, nil, err
+	}
+	return conditions, specialties, nil
+}
+
+func (a *ProcedureSearchAdapter) facetValues(ctx context.Context, field, prefix string, limit int) ([]entities.ConceptSuggestion, error) {
+	// Facet queries match tokens, so quoting characters are dropped
+	prefix = strings.NewReplacer("`", "", ":", " ").Replace(strings.ToLower(prefix))
+
+	result, err := a.client.Client().Collection(tsclient.ProceduresCollection).Documents().Search(ctx, &api.SearchCollectionParams{
+		Q:              pointer.String("*"),
+		QueryBy:        pointer.String("name"),
+		FilterBy:       pointer.String("is_active:=true"),
+		FacetBy:        pointer.String(field),
+		FacetQuery:     pointer.String(field + ":" + prefix),
+		MaxFacetValues: pointer.Int(limit),
+		PerPage:        pointer.Int(0),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to suggest %s: %w", field, err)
+	}
+
+	suggestions := []entities.ConceptSuggestion{}
+	if result.FacetCounts == nil {
+		return suggestions, nil
+	}
+	for _, facet := range *result.FacetCounts {
+		if facet.FieldName == nil || *facet.FieldName != field || facet.Counts == nil {
+			continue
+		}
+		for _, count := range *facet.Counts {
+			if count.Value == nil {
+				continue
+			}
+			suggestion := entities.ConceptSuggestion{Value: *count.Value}
+			if count.Count != nil {
+				suggestion.Procedures = *count.Count
+			}
+			suggestions = append(suggestions, suggestion)
+		}
+	}
+	return suggestions, nil
+}
+
+func procedureFromDocument(doc map[string]interface{}) *entities.ProcedureSearchResult {
+	result := &entities.ProcedureSearchResult{
+		ID:          documentString(doc, "id"),
+		Name:        documentString(doc, "name"),
+		DisplayName: documentString(doc, "display_name"),
+		Code:        documentString(doc, "code"),
+		Category:    documentString(doc, "category"),
+		Tags:        documentStrings(doc, "tags"),
+		Conditions:  documentStrings(doc, "conditions"),
+		Specialties: documentStrings(doc, "specialties"),
+	}
+
+	count, _ := doc["facility_count"].(float64)
+	if count > 0 {
+		minPrice, _ := doc["min_price"].(float64)
+		maxPrice, _ := doc["max_price"].(float64)
+		medianPrice, _ := doc["median_price"].(float64)
+		result.Price = &entities.ProcedurePriceStats{
+			ProcedureID:   result.ID,
+			FacilityCount: int(count),
+			MinPrice:      minPrice,
+			MaxPrice:      maxPrice,
+			MedianPrice:   medianPrice,
+			Currency:      documentString(doc, "currency"),
+		}
+	}
+	return result
+}
+
+func documentString(doc map[string]interface{}, key string) string {
+	value, _ := doc[key].(string)
+	return value
+}
+
+func documentStrings(doc map[string]interface{}, key string) []string {
+	values, ok := doc[key].([]interface{})
+	if !ok {
+		return nil
+	}
+	strs := make([]string, 0, len(values))
+	for _, value := range values {
+		if str, ok := value.(string); ok {
+			strs = append(strs, str)
+		}
+	}
+	return strs
+}
@@ -0,0 +1,135 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	tsclient "github.com/zatekoja/Patientpricediscoverydesign/backend/internal/infrastructure/clients/typesense"
+)
+
+const (
+	// minVectorCandidates is the fewest nearest neighbours hybrid search
+	// considers, so later pages still have vector matches to fuse
+	minVectorCandidates = 100
+
+	// maxEmbeddingTextLength caps the text embedded for a document; the
+	// leading fields say the most about it
+	maxEmbeddingTextLength = 4000
+
+	// embeddingModelsTTL is how long a collection's embedding models are
+	// trusted before they are looked up again, so a reindex with another
+	// model is noticed
+	embeddingModelsTTL = time.Minute
+)
+
+// EmbeddingText joins a document's values into the text its embedding is
+// built from, in the order given and without repeats
+func EmbeddingText(values ...[]string) string {
+	seen := map[string]struct{}{}
+	var b strings.Builder
+	for _, group := range values {
+		for _, value := range group {
+			value = strings.TrimSpace(value)
+			key := strings.ToLower(value)
+			if value == "" {
+				continue
+			}
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			if b.Len()+len(value)+2 > maxEmbeddingTextLength {
+				return b.String()
+			}
+			seen[key] = struct{}{}
+			if b.Len() > 0 {
+				b.WriteString(". ")
+			}
+			b.WriteString(value)
+		}
+	}
+	return b.String()
+}
+
+// vectorQuery builds the Typesense vector_query that makes a keyword search
+// hybrid. Typesense fuses the keyword and vector ranks, giving vectorWeight
+// to the vector rank, and drops neighbours less similar than minSimilarity.
+func vectorQuery(embedding []float32, candidates int, vectorWeight, minSimilarity float64) string {
+	values := make([]string, len(embedding))
+	for i, v := range embedding {
+		values[i] = formatVectorValue(v)
+	}
+
+	query := fmt.Sprintf("%s:([%s], k:%d, alpha:%s",
+		tsclient.EmbeddingField, strings.Join(values, ","), max(candidates, minVectorCandidates),
+		strconv.FormatFloat(vectorWeight, 'f', -1, 64))
+	if minSimilarity > 0 {
+		// Typesense measures cosine distance, 1 - similarity
+		query += ", distance_threshold:" + strconv.FormatFloat(1-minSimilarity, 'f', -1, 64)
+	}
+	return query + ")"
+}
+
+// formatVectorValue writes a component with four decimals, which keeps the
+// query short enough to send as URL parameters
+func formatVectorValue(v float32) string {
+	s := strconv.FormatFloat(float64(v), 'f', 4, 32)
+	s = strings.TrimRight(s, "0")
+	s = strings.TrimSuffix(s, ".")
+	if s == "" || s == "-" || s == "-0" {
+		return "0"
+	}
+	return s
+}
+
+// embeddingModels remembers which models each collection's embeddings came
+// from, so vector queries are only sent where the query and document vectors
+// share a space
+type embeddingModels struct {
+	lookup func(ctx context.Context, collection string) ([]string, error)
+	now    func() time.Time
+
+	mu      sync.Mutex
+	checked map[string]checkedEmbeddingModels
+}
+
+type checkedEmbeddingModels struct {
+	models    []string
+	checkedAt time.Time
+}
+
+func newEmbeddingModels(lookup func(ctx context.Context, collection string) ([]string, error)) *embeddingModels {
+	return &embeddingModels{lookup: lookup, now: time.Now, checked: map[string]checkedEmbeddingModels{}}
+}
+
+// matches reports whether every embedding in collection came from model. A
+// failed lookup is not remembered and counts as a mismatch, which keeps the
+// search keyword-only.
+func (m *embeddingModels) matches(ctx context.Context, collection, model string) bool {
+	if model == "" {
+		return false
+	}
+
+	m.mu.Lock()
+	checked, ok := m.checked[collection]
+	m.mu.Unlock()
+	if !ok || m.now().Sub(checked.checkedAt) >= embeddingModelsTTL {
+		models, err := m.lookup(ctx, collection)
+		if err != nil {
+			log.Printf("Warning: failed to check the embedding model of %s, searching by keyword only: %v", collection, err)
+			return false
+		}
+		checked = checkedEmbeddingModels{models: models, checkedAt: m.now()}
+		m.mu.Lock()
+		m.checked[collection] = checked
+		m.mu.Unlock()
+		if len(models) != 1 || models[0] != model {
+			log.Printf("Warning: %s holds embeddings from %v, not %s; searching by keyword only until it is reindexed", collection, models, model)
+		}
+	}
+	return len(checked.models) == 1 && checked.models[0] == model
+}
@@ -13,80 +13,52 @@ import (
 )
 
 const (
-	collectionName  = "facilities"
+	// collectionName is the alias over the live versioned collection
+	collectionName  = tsclient.FacilitiesCollection
 	maxFacilityTags = 100
+	// maxPerPage is the most hits Typesense returns in one page
+	maxPerPage = 250
 )
 
 // TypesenseAdapter implements facility search using Typesense
 
 type TypesenseAdapter struct {
 	client *tsclient.Client
+	models *embeddingModels
 }
 
 // Ensure TypesenseAdapter implements FacilitySearchRepository
 
 var _ repositories.FacilitySearchRepository = (*TypesenseAdapter)(nil)
+var _ repositories.FacilityMapRepository = (*TypesenseAdapter)(nil)
 
 // NewTypesenseAdapter creates a new Typesense adapter
 
 func NewTypesenseAdapter(client *tsclient.Client) *TypesenseAdapter {
-	return &TypesenseAdapter{client: client}
+	return &TypesenseAdapter{client: client, models: newEmbeddingModels(client.EmbeddingModels)}
 }
 
-// InitSchema ensures the collection exists
+// InitSchema ensures the facilities alias and its collection exist. Schema
+// changes are rolled out by the indexer, which builds a new collection and
+// swaps it in behind the alias.
 func (a *TypesenseAdapter) InitSchema(ctx context.Context) error {
-	// Check if collection exists
-	_, err := a.client.Client().Collection(collectionName).Retrieve(ctx)
-	if err == nil {
-		// Collection exists. For dev/prototype, we might need to delete/recreate to update schema
-		// or use Update() if supported. For now, assuming the 'reset' flag in reindexer handles this.
-		return nil
-	}
-
-	// Create collection
-	schema := &api.CollectionSchema{
-		Name: collectionName,
-		Fields: []api.Field{
-			{Name: "id", Type: "string"},
-			{Name: "name", Type: "string"},
-			{Name: "facility_type", Type: "string", Facet: pointer.True()},
-			{Name: "is_active", Type: "bool"},
-			{Name: "location", Type: "geopoint"},
-			{Name: "price", Type: "float", Facet: pointer.True(), Optional: pointer.True()},
-			{Name: "rating", Type: "float"},
-			{Name: "review_count", Type: "int32"},
-			{Name: "created_at", Type: "int64"},
-			{Name: "insurance", Type: "string[]", Facet: pointer.True(), Optional: pointer.True()},
-			{Name: "tags", Type: "string[]", Optional: pointer.True()},
-			{Name: "procedures", Type: "string[]", Optional: pointer.True()},
-			{Name: "concepts", Type: "string[]", Optional: pointer.True()},
-			{Name: "conditions", Type: "string[]", Optional: pointer.True()},
-			{Name: "symptoms", Type: "string[]", Optional: pointer.True()},
-			{Name: "specialties", Type: "string[]", Facet: pointer.True(), Optional: pointer.True()},
-		},
-		DefaultSortingField: pointer.String("created_at"),
-	}
-
-	_, err = a.client.Client().Collections().Create(ctx, schema)
-	if err != nil {
-		return fmt.Errorf("failed to create typesense collection: %w", err)
-	}
-
-	return nil
+	return a.client.InitSchema(ctx)
 }
 
 // Index indexes a facility. Uses partial update to avoid wiping fields (like procedures)
 // that are set by the full reindexer but not available here.
 func (a *TypesenseAdapter) Index(ctx context.Context, facility *entities.Facility) error {
 	document := map[string]interface{}{
-		"id":            facility.ID,
-		"name":          facility.Name,
-		"facility_type": facility.FacilityType,
-		"is_active":     facility.IsActive,
-		"location":      []float64{facility.Location.Latitude, facility.Location.Longitude},
-		"rating":        facility.Rating,
-		"review_count":  facility.ReviewCount,
-		"created_at":    facility.CreatedAt.Unix(),
+		"id":              facility.ID,
+		"name":            facility.Name,
+		"facility_type":   facility.FacilityType,
+		"is_active":       facility.IsActive,
+		"location":        []float64{facility.Location.Latitude, facility.Location.Longitude},
+		"rating":          facility.Rating,
+		"review_count":    facility.ReviewCount,
+		"created_at":      facility.CreatedAt.Unix(),
+		"capacity_status": facility.CurrentCapacityStatus(),
+		"open_24_hours":   facility.OpenAroundTheClock(),
 	}
 
 	if len(facility.AcceptedInsurance) > 0 {
@@ -140,27 +112,7 @@ func (a *TypesenseAdapter) SearchWithCount(ctx context.Context, params repositor
 		limit = 20
 	}
 
-	filter := "is_active:=true"
-	// Only apply location filter if coordinates are provided (non-zero)
-	if params.Latitude != 0 || params.Longitude != 0 {
-		filter = fmt.Sprintf("%s && location:(%f, %f, %f km)", filter, params.Latitude, params.Longitude, params.RadiusKm)
-	}
-
-	if params.InsuranceProvider != "" {
-		filter = fmt.Sprintf("%s && insurance:=[%s]", filter, escapeFilterValue(params.InsuranceProvider))
-	}
-	if params.MinPrice != nil {
-		filter = fmt.Sprintf("%s && price:>=%f", filter, *params.MinPrice)
-	}
-	if params.MaxPrice != nil {
-		filter = fmt.Sprintf("%s && price:<=%f", filter, *params.MaxPrice)
-	}
-	if len(params.Specialties) > 0 {
-		filter = fmt.Sprintf("%s && specialties:=[%s]", filter, strings.Join(escapeList(params.Specialties), ","))
-	}
-	if len(params.FacilityTypes) > 0 {
-		filter = fmt.Sprintf("%s && facility_type:=[%s]", filter, strings.Join(escapeList(params.FacilityTypes), ","))
-	}
+	filter := facilityFilter(params)
 
 	searchParams := &api.SearchCollectionParams{
 		Q:                   pointer.String(query),
@@ -171,11 +123,18 @@ func (a *TypesenseAdapter) SearchWithCount(ctx context.Context, params repositor
 		PerPage:             pointer.Int(limit),
 		NumTypos:            pointer.String("2"),
 		DropTokensThreshold: pointer.Int(10),
+		ExcludeFields:       pointer.String(tsclient.EmbeddingField),
 	}
 	if params.Query != "" {
 		searchParams.MinLen1typo = pointer.Int(4)
 		searchParams.MinLen2typo = pointer.Int(7)
 	}
+	if len(params.QueryEmbedding) > 0 && a.models.matches(ctx, collectionName, params.EmbeddingModel) {
+		searchParams.VectorQuery = pointer.String(vectorQuery(params.QueryEmbedding, params.Offset+limit, params.VectorWeight, params.MinSimilarity))
+	}
+	if sortBy := facilitySortBy(params); sortBy != "" {
+		searchParams.SortBy = pointer.String(sortBy)
+	}
 
 	result, err := a.client.Client().Collection(collectionName).Documents().Search(ctx, searchParams)
 	if err != nil {
@@ -235,6 +194,138 @@ func (a *TypesenseAdapter) SearchWithCount(ctx context.Context, params repositor
 	return facilities, totalCount, nil
 }
 
+// MapPoints pages through the facilities inside params.Bounds
+func (a *TypesenseAdapter) MapPoints(ctx context.Context, params repositories.SearchParams, limit int) ([]*entities.FacilityMapPoint, int, error) {
+	if params.Bounds == nil {
+		return nil, 0, fmt.Errorf("map search requires bounds")
+	}
+	query := "*"
+	if params.Query != "" {
+		query = params.Query
+	}
+
+	points := []*entities.FacilityMapPoint{}
+	total := 0
+	for page := 1; len(points) < limit; page++ {
+		perPage := min(maxPerPage, limit-len(points))
+		searchParams := &api.SearchCollectionParams{
+			Q:             pointer.String(query),
+			QueryBy:       pointer.String("name,facility_type,tags,insurance,procedures,concepts,conditions,symptoms,specialties"),
+			FilterBy:      pointer.String(facilityFilter(params)),
+			Page:          pointer.Int(page),
+			PerPage:       pointer.Int(perPage),
+			IncludeFields: pointer.String("id,name,facility_type,location,rating,capacity_status,price"),
+		}
+		if sortBy := facilitySortBy(params); sortBy != "" {
+			searchParams.SortBy = pointer.String(sortBy)
+		}
+
+		result, err := a.client.Client().Collection(collectionName).Documents().Search(ctx, searchParams)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to search facilities in bounds: %w", err)
+		}
+		if result.Found != nil {
+			total = *result.Found
+		}
+		if result.Hits == nil || len(*result.Hits) == 0 {
+			break
+		}
+		for _, hit := range *result.Hits {
+			if hit.Document != nil {
+				points = append(points, mapPointFromDocument(*hit.Document))
+			}
+		}
+		if len(*result.Hits) < perPage {
+			break
+		}
+	}
+	if total < len(points) {
+		total = len(points)
+	}
+	return points, total, nil
+}
+
+func mapPointFromDocument(doc map[string]interface{}) *entities.FacilityMapPoint {
+	point := &entities.FacilityMapPoint{CapacityStatus: entities.CapacityStatusUnknown}
+	point.ID, _ = doc["id"].(string)
+	point.Name, _ = doc["name"].(string)
+	point.FacilityType, _ = doc["facility_type"].(string)
+	point.Rating, _ = doc["rating"].(float64)
+	if status, ok := doc["capacity_status"].(string); ok && status != "" {
+		point.CapacityStatus = status
+	}
+	if location, ok := doc["location"].([]interface{}); ok && len(location) == 2 {
+		point.Location.Latitude, _ = location[0].(float64)
+		point.Location.Longitude, _ = location[1].(float64)
+	}
+	if price, ok := doc["price"].(float64); ok {
+		point.Price = &price
+	}
+	return point
+}
+
+// facilityFilter returns the Typesense filter_by for the search filters. A
+// viewport is searched as a polygon of its four corners.
+func facilityFilter(params repositories.SearchParams) string {
+	filter := "is_active:=true"
+	// Only apply location filter if a viewport, an area or coordinates are provided
+	if b := params.Bounds; b != nil {
+		filter = fmt.Sprintf("%s && location:(%f, %f, %f, %f, %f, %f, %f, %f)", filter,
+			b.MinLatitude, b.MinLongitude, b.MaxLatitude, b.MinLongitude,
+			b.MaxLatitude, b.MaxLongitude, b.MinLatitude, b.MaxLongitude)
+	} else if lat, lon, radius, ok := params.GeoFilter(); ok {
+		filter = fmt.Sprintf("%s && location:(%f, %f, %f km)", filter, lat, lon, radius)
+	}
+	if params.OpenNow {
+		filter = fmt.Sprintf("%s && capacity_status:!=%s", filter, entities.CapacityStatusClosed)
+	}
+	if params.Open24Hours {
+		filter += " && open_24_hours:=true"
+	}
+
+	if params.ProcedureID != "" {
+		filter = fmt.Sprintf("%s && procedure_ids:=[%s]", filter, escapeFilterValue(params.ProcedureID))
+	}
+	if params.InsuranceProvider != "" {
+		filter = fmt.Sprintf("%s && insurance:=[%s]", filter, escapeFilterValue(params.InsuranceProvider))
+	}
+	if params.MinPrice != nil {
+		filter = fmt.Sprintf("%s && price:>=%f", filter, *params.MinPrice)
+	}
+	if params.MaxPrice != nil {
+		filter = fmt.Sprintf("%s && price:<=%f", filter, *params.MaxPrice)
+	}
+	if len(params.Specialties) > 0 {
+		filter = fmt.Sprintf("%s && specialties:=[%s]", filter, strings.Join(escapeList(params.Specialties), ","))
+	}
+	if len(params.FacilityTypes) > 0 {
+		filter = fmt.Sprintf("%s && facility_type:=[%s]", filter, strings.Join(escapeList(params.FacilityTypes), ","))
+	}
+	return filter
+}
+
+// facilitySortBy returns the Typesense sort_by for the requested order, with
+// text relevance breaking ties. Distance is measured from the user, or from
+// the searched area when the user's location is unknown.
+func facilitySortBy(params repositories.SearchParams) string {
+	switch params.SortBy {
+	case repositories.SearchSortPrice:
+		return "price(missing_values: last):asc,_text_match:desc"
+	case repositories.SearchSortRating:
+		return "rating:desc,_text_match:desc"
+	case repositories.SearchSortDistance:
+		lat, lon := params.Latitude, params.Longitude
+		if lat == 0 && lon == 0 && params.Area != nil {
+			lat, lon = params.Area.Latitude, params.Area.Longitude
+		}
+		if lat == 0 && lon == 0 {
+			return ""
+		}
+		return fmt.Sprintf("location(%f, %f):asc,_text_match:desc", lat, lon)
+	}
+	return ""
+}
+
 // Suggest provides lightweight autocomplete suggestions using Typesense.
 func (a *TypesenseAdapter) Suggest(ctx context.Context, query string, lat, lon float64, limit int) ([]*entities.Facility, error) {
 	trimmed := strings.TrimSpace(query)
@@ -253,12 +344,13 @@ func (a *TypesenseAdapter) Suggest(ctx context.Context, query string, lat, lon f
 	}
 
 	searchParams := &api.SearchCollectionParams{
-		Q:        pointer.String(trimmed),
-		QueryBy:  pointer.String("name,facility_type,tags,insurance,procedures"),
-		FilterBy: pointer.String(filter),
-		Page:     pointer.Int(1),
-		PerPage:  pointer.Int(limit),
-		NumTypos: pointer.String("2"),
+		Q:             pointer.String(trimmed),
+		QueryBy:       pointer.String("name,facility_type,tags,insurance,procedures"),
+		FilterBy:      pointer.String(filter),
+		Page:          pointer.Int(1),
+		PerPage:       pointer.Int(limit),
+		NumTypos:      pointer.String("2"),
+		ExcludeFields: pointer.String(tsclient.EmbeddingField),
 	}
 	if trimmed != "" {
 		searchParams.MinLen1typo = pointer.Int(4)
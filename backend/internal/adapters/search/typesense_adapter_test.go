@@ -1,10 +1,14 @@
 package search
 
 import (
+	"context"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/repositories"
 )
 
 func TestBuildFacilityTags(t *testing.T) {
@@ -35,3 +39,107 @@ func TestBuildFacilityTags(t *testing.T) {
 func TestBuildFacilityTagsNil(t *testing.T) {
 	assert.Nil(t, buildFacilityTags(nil))
 }
+
+func TestVectorQuery(t *testing.T) {
+	query := vectorQuery([]float32{0.5, -0.25, 0, 0.12344}, 20, 0.3, 0.4)
+	assert.Equal(t, "embedding:([0.5,-0.25,0,0.1234], k:100, alpha:0.3, distance_threshold:0.6)", query)
+
+	// Deep pages widen the neighbourhood; no threshold keeps every neighbour
+	assert.Equal(t, "embedding:([1], k:240, alpha:0.5)", vectorQuery([]float32{1}, 240, 0.5, 0))
+}
+
+func TestEmbeddingText(t *testing.T) {
+	text := EmbeddingText(
+		[]string{"Anchor Hospital", "hospital"},
+		[]string{"Urinalysis", " urinalysis ", ""},
+		[]string{"urinary tract infection"},
+	)
+	assert.Equal(t, "Anchor Hospital. hospital. Urinalysis. urinary tract infection", text)
+}
+
+func TestFacilitySortBy(t *testing.T) {
+	assert.Equal(t, "", facilitySortBy(repositories.SearchParams{}))
+	assert.Equal(t, "price(missing_values: last):asc,_text_match:desc",
+		facilitySortBy(repositories.SearchParams{SortBy: repositories.SearchSortPrice}))
+	assert.Equal(t, "rating:desc,_text_match:desc",
+		facilitySortBy(repositories.SearchParams{SortBy: repositories.SearchSortRating}))
+
+	// Distance sorts from the user, else from the centre of the named area
+	assert.Equal(t, "", facilitySortBy(repositories.SearchParams{SortBy: repositories.SearchSortDistance}))
+	assert.Equal(t, "location(6.500000, 3.300000):asc,_text_match:desc", facilitySortBy(repositories.SearchParams{
+		SortBy: repositories.SearchSortDistance,
+		Area:   &repositories.SearchArea{Name: "Ikeja", Latitude: 6.5, Longitude: 3.3, RadiusKm: 15},
+	}))
+}
+
+func TestFacilityFilter(t *testing.T) {
+	maxPrice := 50000.0
+	params := repositories.SearchParams{
+		Latitude:          6.5,
+		Longitude:         3.3,
+		RadiusKm:          10,
+		Bounds:            &entities.GeoBounds{MinLatitude: 6.4, MinLongitude: 3.3, MaxLatitude: 6.6, MaxLongitude: 3.5},
+		InsuranceProvider: "Hygeia HMO",
+		MaxPrice:          &maxPrice,
+		OpenNow:           true,
+	}
+	assert.Equal(t,
+		"is_active:=true && location:(6.400000, 3.300000, 6.600000, 3.300000, 6.600000, 3.500000, 6.400000, 3.500000)"+
+			" && capacity_status:!=closed && insurance:=[\"Hygeia HMO\"] && price:<=50000.000000",
+		facilityFilter(params))
+
+	// Without a viewport the radius around the user applies
+	params.Bounds = nil
+	assert.Contains(t, facilityFilter(params), "location:(6.500000, 3.300000, 10.000000 km)")
+
+	// Facilities offering a picked procedure are matched by its ID
+	params.ProcedureID = "proc_fbc"
+	assert.Contains(t, facilityFilter(params), "procedure_ids:=[\"proc_fbc\"]")
+}
+
+func TestMapPointFromDocument(t *testing.T) {
+	point := mapPointFromDocument(map[string]interface{}{
+		"id": "fac_1", "name": "Reddington", "facility_type": "hospital",
+		"location": []interface{}{6.43, 3.42}, "rating": 4.5, "price": 15000.0,
+	})
+	assert.Equal(t, entities.Location{Latitude: 6.43, Longitude: 3.42}, point.Location)
+	assert.Equal(t, entities.CapacityStatusUnknown, point.CapacityStatus)
+	if assert.NotNil(t, point.Price) {
+		assert.Equal(t, 15000.0, *point.Price)
+	}
+}
+
+func TestEmbeddingModels_Matches(t *testing.T) {
+	lookups := 0
+	indexed := []string{"text-embedding-3-small"}
+	var lookupErr error
+	models := newEmbeddingModels(func(ctx context.Context, collection string) ([]string, error) {
+		lookups++
+		return indexed, lookupErr
+	})
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	models.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	assert.True(t, models.matches(ctx, "facilities", "text-embedding-3-small"))
+	assert.False(t, models.matches(ctx, "facilities", "hashing-v1"))
+	assert.False(t, models.matches(ctx, "facilities", ""))
+	assert.Equal(t, 1, lookups, "the models are remembered for a while")
+
+	// A reindex with another model is picked up once the TTL passes
+	indexed = []string{"hashing-v1"}
+	now = now.Add(embeddingModelsTTL)
+	assert.True(t, models.matches(ctx, "facilities", "hashing-v1"))
+	assert.Equal(t, 2, lookups)
+
+	// Mixed or missing embeddings, and failed lookups, are mismatches
+	indexed = []string{"hashing-v1", "text-embedding-3-small"}
+	assert.False(t, models.matches(ctx, "procedures", "hashing-v1"))
+	indexed = []string{}
+	assert.False(t, models.matches(ctx, "empty", "hashing-v1"))
+	lookupErr = errors.New("typesense unavailable")
+	assert.False(t, models.matches(ctx, "unreachable", "hashing-v1"))
+	lookupErr = nil
+	indexed = []string{"hashing-v1"}
+	assert.True(t, models.matches(ctx, "unreachable", "hashing-v1"), "a failed lookup is not remembered")
+}
@@ -0,0 +1,235 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/application/services"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+)
+
+// AccountService defines the patient account operations used by the handler.
+type AccountService interface {
+	RequestLoginCode(ctx context.Context, phone string, channel entities.NotificationChannel) (*services.LoginCodeResult, error)
+	VerifyLoginCode(ctx context.Context, phone, code string) (*services.LoginResult, error)
+	Authenticate(ctx context.Context, token string) (*entities.User, error)
+	Logout(ctx context.Context, token string) error
+	UpdateProfile(ctx context.Context, user *entities.User, input services.UpdateProfileInput) (*entities.User, error)
+	ListAppointments(ctx context.Context, userID string, status entities.AppointmentStatus, limit, offset int) ([]*entities.Appointment, error)
+	ListSavedFacilities(ctx context.Context, userID string) ([]*entities.Facility, error)
+	SaveFacility(ctx context.Context, userID, facilityID string) error
+	RemoveSavedFacility(ctx context.Context, userID, facilityID string) error
+	GetNotificationPreferences(ctx context.Context, user *entities.User) (*entities.NotificationPreference, error)
+	UpdateNotificationPreferences(ctx context.Context, user *entities.User, input services.NotificationPreferencesInput) (*entities.NotificationPreference, error)
+}
+
+// AccountHandler serves patient login and the /api/me endpoints. Requests
+// under /api/me carry the session token as "Authorization: Bearer <token>".
+type AccountHandler struct {
+	service AccountService
+}
+
+// NewAccountHandler creates a new account handler.
+func NewAccountHandler(service AccountService) *AccountHandler {
+	return &AccountHandler{service: service}
+}
+
+// RequestLoginCode handles POST /api/auth/otp
+func (h *AccountHandler) RequestLoginCode(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Phone   string `json:"phone"`
+		Channel string `json:"channel"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	result, err := h.service.RequestLoginCode(r.Context(), req.Phone, entities.NotificationChannel(strings.ToLower(strings.TrimSpace(req.Channel))))
+	if err != nil {
+		respondWithAppError(w, err, "failed to send login code")
+		return
+	}
+	respondWithJSON(w, http.StatusAccepted, result)
+}
+
+// VerifyLoginCode handles POST /api/auth/verify
+func (h *AccountHandler) VerifyLoginCode(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Phone string `json:"phone"`
+		Code  string `json:"code"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	result, err := h.service.VerifyLoginCode(r.Context(), req.Phone, req.Code)
+	if err != nil {
+		respondWithAppError(w, err, "failed to verify login code")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, result)
+}
+
+// Logout handles POST /api/auth/logout
+func (h *AccountHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.Logout(r.Context(), bearerToken(r)); err != nil {
+		respondWithAppError(w, err, "failed to log out")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetProfile handles GET /api/me
+func (h *AccountHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.authenticate(w, r)
+	if !ok {
+		return
+	}
+	respondWithJSON(w, http.StatusOK, user)
+}
+
+// UpdateProfile handles PUT /api/me
+func (h *AccountHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	var input services.UpdateProfileInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	updated, err := h.service.UpdateProfile(r.Context(), user, input)
+	if err != nil {
+		respondWithAppError(w, err, "failed to update profile")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, updated)
+}
+
+// ListAppointments handles GET /api/me/appointments
+func (h *AccountHandler) ListAppointments(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	limit := parseIntDefault(query.Get("limit"), 20)
+	offset := parseIntDefault(query.Get("offset"), 0)
+	status := entities.AppointmentStatus(strings.TrimSpace(query.Get("status")))
+
+	appointments, err := h.service.ListAppointments(r.Context(), user.ID, status, limit, offset)
+	if err != nil {
+		respondWithAppError(w, err, "failed to list appointments")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"appointments": appointments,
+		"limit":        limit,
+		"offset":       offset,
+	})
+}
+
+// ListSavedFacilities handles GET /api/me/saved-facilities
+func (h *AccountHandler) ListSavedFacilities(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	facilities, err := h.service.ListSavedFacilities(r.Context(), user.ID)
+	if err != nil {
+		respondWithAppError(w, err, "failed to list saved facilities")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{"facilities": facilities})
+}
+
+// SaveFacility handles PUT /api/me/saved-facilities/{id}
+func (h *AccountHandler) SaveFacility(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.SaveFacility(r.Context(), user.ID, r.PathValue("id")); err != nil {
+		respondWithAppError(w, err, "failed to save facility")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RemoveSavedFacility handles DELETE /api/me/saved-facilities/{id}
+func (h *AccountHandler) RemoveSavedFacility(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.RemoveSavedFacility(r.Context(), user.ID, r.PathValue("id")); err != nil {
+		respondWithAppError(w, err, "failed to remove saved facility")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetNotificationPreferences handles GET /api/me/notification-preferences
+func (h *AccountHandler) GetNotificationPreferences(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	prefs, err := h.service.GetNotificationPreferences(r.Context(), user)
+	if err != nil {
+		respondWithAppError(w, err, "failed to get notification preferences")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, prefs)
+}
+
+// UpdateNotificationPreferences handles PUT /api/me/notification-preferences
+func (h *AccountHandler) UpdateNotificationPreferences(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	var input services.NotificationPreferencesInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	prefs, err := h.service.UpdateNotificationPreferences(r.Context(), user, input)
+	if err != nil {
+		respondWithAppError(w, err, "failed to update notification preferences")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, prefs)
+}
+
+// authenticate resolves the request's session and writes the error response when it is missing or invalid.
+func (h *AccountHandler) authenticate(w http.ResponseWriter, r *http.Request) (*entities.User, bool) {
+	user, err := h.service.Authenticate(r.Context(), bearerToken(r))
+	if err != nil {
+		respondWithAppError(w, err, "failed to authenticate")
+		return nil, false
+	}
+	return user, true
+}
+
+func bearerToken(r *http.Request) string {
+	header := strings.TrimSpace(r.Header.Get("Authorization"))
+	if len(header) > 7 && strings.EqualFold(header[:7], "bearer ") {
+		return strings.TrimSpace(header[7:])
+	}
+	return ""
+}
@@ -0,0 +1,119 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/api/handlers"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/application/services"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	apperrors "github.com/zatekoja/Patientpricediscoverydesign/backend/pkg/errors"
+)
+
+type fakeAccountService struct {
+	requestErr error
+	tokenSeen  string
+	savedID    string
+}
+
+func (f *fakeAccountService) RequestLoginCode(ctx context.Context, phone string, channel entities.NotificationChannel) (*services.LoginCodeResult, error) {
+	if f.requestErr != nil {
+		return nil, f.requestErr
+	}
+	return &services.LoginCodeResult{Phone: phone, Channel: channel}, nil
+}
+
+func (f *fakeAccountService) VerifyLoginCode(ctx context.Context, phone, code string) (*services.LoginResult, error) {
+	return &services.LoginResult{Token: "token"}, nil
+}
+
+func (f *fakeAccountService) Authenticate(ctx context.Context, token string) (*entities.User, error) {
+	f.tokenSeen = token
+	if token != "valid-token" {
+		return nil, apperrors.NewUnauthorizedError("session is invalid or has expired")
+	}
+	return &entities.User{ID: "user-1", Phone: "+2348031234567"}, nil
+}
+
+func (f *fakeAccountService) Logout(ctx context.Context, token string) error {
+	return nil
+}
+
+func (f *fakeAccountService) UpdateProfile(ctx context.Context, user *entities.User, input services.UpdateProfileInput) (*entities.User, error) {
+	return user, nil
+}
+
+func (f *fakeAccountService) ListAppointments(ctx context.Context, userID string, status entities.AppointmentStatus, limit, offset int) ([]*entities.Appointment, error) {
+	return []*entities.Appointment{}, nil
+}
+
+func (f *fakeAccountService) ListSavedFacilities(ctx context.Context, userID string) ([]*entities.Facility, error) {
+	return []*entities.Facility{}, nil
+}
+
+func (f *fakeAccountService) SaveFacility(ctx context.Context, userID, facilityID string) error {
+	f.savedID = facilityID
+	return nil
+}
+
+func (f *fakeAccountService) RemoveSavedFacility(ctx context.Context, userID, facilityID string) error {
+	return nil
+}
+
+func (f *fakeAccountService) GetNotificationPreferences(ctx context.Context, user *entities.User) (*entities.NotificationPreference, error) {
+	return &entities.NotificationPreference{UserID: user.ID, WhatsAppEnabled: true}, nil
+}
+
+func (f *fakeAccountService) UpdateNotificationPreferences(ctx context.Context, user *entities.User, input services.NotificationPreferencesInput) (*entities.NotificationPreference, error) {
+	return &entities.NotificationPreference{UserID: user.ID}, nil
+}
+
+func TestAccountHandler_RequiresBearerToken(t *testing.T) {
+	svc := &fakeAccountService{}
+	handler := handlers.NewAccountHandler(svc)
+
+	w := httptest.NewRecorder()
+	handler.GetNotificationPreferences(w, httptest.NewRequest("GET", "/api/me/notification-preferences", nil))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, "Bearer", w.Header().Get("WWW-Authenticate"))
+
+	req := httptest.NewRequest("GET", "/api/me/notification-preferences", nil)
+	req.Header.Set("Authorization", "Bearer valid-token")
+	w = httptest.NewRecorder()
+	handler.GetNotificationPreferences(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "valid-token", svc.tokenSeen)
+	assert.Contains(t, w.Body.String(), `"whatsapp_enabled":true`)
+
+	req = httptest.NewRequest("PUT", "/api/me/saved-facilities/fac-9", nil)
+	req.SetPathValue("id", "fac-9")
+	req.Header.Set("Authorization", "bearer valid-token")
+	w = httptest.NewRecorder()
+	handler.SaveFacility(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "fac-9", svc.savedID)
+}
+
+func TestAccountHandler_RequestLoginCodeErrors(t *testing.T) {
+	svc := &fakeAccountService{}
+	handler := handlers.NewAccountHandler(svc)
+
+	w := httptest.NewRecorder()
+	handler.RequestLoginCode(w, httptest.NewRequest("POST", "/api/auth/otp", strings.NewReader(`{"phone":"08031234567","channel":"WhatsApp"}`)))
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Contains(t, w.Body.String(), `"channel":"whatsapp"`)
+
+	svc.requestErr = apperrors.NewRateLimitedError("please wait a minute before requesting another code")
+	w = httptest.NewRecorder()
+	handler.RequestLoginCode(w, httptest.NewRequest("POST", "/api/auth/otp", strings.NewReader(`{"phone":"08031234567"}`)))
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+
+	svc.requestErr = apperrors.NewExternalError("failed to send login code", nil)
+	w = httptest.NewRecorder()
+	handler.RequestLoginCode(w, httptest.NewRequest("POST", "/api/auth/otp", strings.NewReader(`{"phone":"08031234567"}`)))
+	assert.Equal(t, http.StatusBadGateway, w.Code)
+}
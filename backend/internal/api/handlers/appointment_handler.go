@@ -3,6 +3,7 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"time"
 
@@ -14,6 +15,8 @@ import (
 type AppointmentService interface {
 	BookAppointment(ctx context.Context, appointment *entities.Appointment) error
 	GetAvailableSlots(ctx context.Context, facilityID string, from, to time.Time) ([]entities.AvailabilitySlot, error)
+	CancelAppointment(ctx context.Context, id, patientEmail, reason string) (*entities.Appointment, error)
+	CompleteAppointment(ctx context.Context, id string) (*entities.Appointment, error)
 }
 
 // AppointmentHandler handles appointment requests
@@ -37,6 +40,20 @@ func (h *AppointmentHandler) BookAppointment(w http.ResponseWriter, r *http.Requ
 	}
 
 	if err := h.service.BookAppointment(r.Context(), &appointment); err != nil {
+		var appErr *apperrors.AppError
+		if errors.As(err, &appErr) {
+			switch appErr.Type {
+			case apperrors.ErrorTypeNotFound:
+				respondWithError(w, http.StatusNotFound, appErr.Message)
+				return
+			case apperrors.ErrorTypeValidation:
+				respondWithError(w, http.StatusBadRequest, appErr.Message)
+				return
+			case apperrors.ErrorTypeConflict:
+				respondWithError(w, http.StatusConflict, appErr.Message)
+				return
+			}
+		}
 		respondWithError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -74,23 +91,7 @@ func (h *AppointmentHandler) GetAvailability(w http.ResponseWriter, r *http.Requ
 
 	slots, err := h.service.GetAvailableSlots(r.Context(), facilityID, from, to)
 	if err != nil {
-		if appErr, ok := err.(*apperrors.AppError); ok {
-			switch appErr.Type {
-			case apperrors.ErrorTypeNotFound:
-				respondWithError(w, http.StatusNotFound, appErr.Message)
-				return
-			case apperrors.ErrorTypeValidation:
-				respondWithError(w, http.StatusBadRequest, appErr.Message)
-				return
-			case apperrors.ErrorTypeExternal:
-				respondWithError(w, http.StatusBadGateway, appErr.Message)
-				return
-			default:
-				respondWithError(w, http.StatusInternalServerError, appErr.Message)
-				return
-			}
-		}
-		respondWithError(w, http.StatusInternalServerError, err.Error())
+		respondWithAppError(w, err, "failed to get availability")
 		return
 	}
 
@@ -98,3 +99,35 @@ func (h *AppointmentHandler) GetAvailability(w http.ResponseWriter, r *http.Requ
 		"slots": slots,
 	})
 }
+
+// CancelAppointment handles POST /api/appointments/{id}/cancel
+// The patient confirms the booking is theirs with the email it was made under.
+func (h *AppointmentHandler) CancelAppointment(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		PatientEmail string `json:"patient_email"`
+		Reason       string `json:"reason"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request payload")
+		return
+	}
+
+	appointment, err := h.service.CancelAppointment(r.Context(), r.PathValue("id"), req.PatientEmail, req.Reason)
+	if err != nil {
+		respondWithAppError(w, err, "failed to cancel appointment")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, appointment)
+}
+
+// CompleteAppointment handles POST /api/admin/appointments/{id}/complete
+// Operators mark a visit as attended, after which the patient can review it.
+// Like every admin route it only lets operator tokens through.
+func (h *AppointmentHandler) CompleteAppointment(w http.ResponseWriter, r *http.Request) {
+	appointment, err := h.service.CompleteAppointment(r.Context(), r.PathValue("id"))
+	if err != nil {
+		respondWithAppError(w, err, "failed to complete appointment")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, appointment)
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/stretchr/testify/mock"
 	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/api/handlers"
 	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	apperrors "github.com/zatekoja/Patientpricediscoverydesign/backend/pkg/errors"
 )
 
 // MockAppointmentService defines the mock service
@@ -31,6 +32,22 @@ func (m *MockAppointmentService) GetAvailableSlots(ctx context.Context, facility
 	return args.Get(0).([]entities.AvailabilitySlot), args.Error(1)
 }
 
+func (m *MockAppointmentService) CancelAppointment(ctx context.Context, id, patientEmail, reason string) (*entities.Appointment, error) {
+	args := m.Called(ctx, id, patientEmail, reason)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Appointment), args.Error(1)
+}
+
+func (m *MockAppointmentService) CompleteAppointment(ctx context.Context, id string) (*entities.Appointment, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Appointment), args.Error(1)
+}
+
 // NOTE: We need to define the Service interface in the handler package or import it
 // Since Go doesn't strict require interface implementation for mocks if we use duck typing or interface definition
 // But for type safety, let's assume the handler accepts an interface.
@@ -121,3 +138,58 @@ func TestAppointmentHandler_GetAvailability(t *testing.T) {
 		mockService.AssertExpectations(t)
 	})
 }
+
+func TestAppointmentHandler_CancelAppointment(t *testing.T) {
+	t.Run("cancels the appointment", func(t *testing.T) {
+		mockService := new(MockAppointmentService)
+		handler := handlers.NewAppointmentHandler(mockService)
+
+		req := httptest.NewRequest("POST", "/api/appointments/appt-1/cancel", bytes.NewBufferString(`{"patient_email":"ada@example.com","reason":"feeling better"}`))
+		req.SetPathValue("id", "appt-1")
+		w := httptest.NewRecorder()
+
+		mockService.On("CancelAppointment", mock.Anything, "appt-1", "ada@example.com", "feeling better").
+			Return(&entities.Appointment{ID: "appt-1", Status: entities.AppointmentStatusCancelled}, nil)
+
+		handler.CancelAppointment(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp entities.Appointment
+		assert.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		assert.Equal(t, entities.AppointmentStatusCancelled, resp.Status)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("maps a completed appointment to conflict", func(t *testing.T) {
+		mockService := new(MockAppointmentService)
+		handler := handlers.NewAppointmentHandler(mockService)
+
+		req := httptest.NewRequest("POST", "/api/appointments/appt-1/cancel", bytes.NewBufferString(`{"patient_email":"ada@example.com"}`))
+		req.SetPathValue("id", "appt-1")
+		w := httptest.NewRecorder()
+
+		mockService.On("CancelAppointment", mock.Anything, "appt-1", "ada@example.com", "").
+			Return(nil, apperrors.NewConflictError("a completed appointment cannot be cancelled"))
+
+		handler.CancelAppointment(w, req)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+}
+
+func TestAppointmentHandler_CompleteAppointment(t *testing.T) {
+	mockService := new(MockAppointmentService)
+	handler := handlers.NewAppointmentHandler(mockService)
+
+	req := httptest.NewRequest("POST", "/api/admin/appointments/appt-1/complete", nil)
+	req.SetPathValue("id", "appt-1")
+	w := httptest.NewRecorder()
+
+	mockService.On("CompleteAppointment", mock.Anything, "appt-1").
+		Return(nil, apperrors.NewConflictError("a pending appointment cannot be completed"))
+
+	handler.CompleteAppointment(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/application/services"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/repositories"
+)
+
+// AuditRecorder records mutations made directly by handlers in the audit log
+type AuditRecorder interface {
+	Record(ctx context.Context, action, entityType, entityID, facilityID string, before, after interface{})
+}
+
+// AuditTransactor writes a mutation made directly by a handler together with
+// its audit entry
+type AuditTransactor interface {
+	Transact(ctx context.Context, change func(ctx context.Context) (*services.AuditEvent, error)) error
+}
+
+// AuditLogService defines the audit log queries used by the handler
+type AuditLogService interface {
+	List(ctx context.Context, filter repositories.AuditFilter) ([]*entities.AuditEntry, error)
+	VerifyChain(ctx context.Context) (*services.AuditChainVerification, error)
+}
+
+// AuditHandler serves the audit log to admins
+type AuditHandler struct {
+	service AuditLogService
+}
+
+// NewAuditHandler creates a new audit handler
+func NewAuditHandler(service AuditLogService) *AuditHandler {
+	return &AuditHandler{service: service}
+}
+
+// ListEntries handles GET /api/admin/audit?facility_id=&actor_id=&entity_type=&entity_id=&from=&to=
+func (h *AuditHandler) ListEntries(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := repositories.AuditFilter{
+		FacilityID: strings.TrimSpace(query.Get("facility_id")),
+		ActorID:    strings.TrimSpace(query.Get("actor_id")),
+		EntityType: strings.TrimSpace(query.Get("entity_type")),
+		EntityID:   strings.TrimSpace(query.Get("entity_id")),
+		Limit:      parseIntDefault(query.Get("limit"), 50),
+		Offset:     parseIntDefault(query.Get("offset"), 0),
+	}
+	for name, dst := range map[string]**time.Time{"from": &filter.From, "to": &filter.To} {
+		value := strings.TrimSpace(query.Get(name))
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "invalid "+name+" parameter (expected RFC 3339)")
+			return
+		}
+		*dst = &t
+	}
+
+	entries, err := h.service.List(r.Context(), filter)
+	if err != nil {
+		respondWithAppError(w, err, "failed to list audit entries")
+		return
+	}
+	if entries == nil {
+		entries = []*entities.AuditEntry{}
+	}
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{"entries": entries})
+}
+
+// VerifyChain handles GET /api/admin/audit/verify
+func (h *AuditHandler) VerifyChain(w http.ResponseWriter, r *http.Request) {
+	result, err := h.service.VerifyChain(r.Context())
+	if err != nil {
+		respondWithAppError(w, err, "failed to verify audit log")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, result)
+}
@@ -0,0 +1,58 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/api/handlers"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/application/services"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/repositories"
+)
+
+type fakeAuditLogService struct {
+	filter repositories.AuditFilter
+}
+
+func (f *fakeAuditLogService) List(ctx context.Context, filter repositories.AuditFilter) ([]*entities.AuditEntry, error) {
+	f.filter = filter
+	return nil, nil
+}
+
+func (f *fakeAuditLogService) VerifyChain(ctx context.Context) (*services.AuditChainVerification, error) {
+	return &services.AuditChainVerification{Valid: true, Checked: 3}, nil
+}
+
+func TestAuditHandler_ListEntries(t *testing.T) {
+	svc := &fakeAuditLogService{}
+	handler := handlers.NewAuditHandler(svc)
+
+	w := httptest.NewRecorder()
+	handler.ListEntries(w, httptest.NewRequest(http.MethodGet,
+		"/api/admin/audit?facility_id=fac-1&actor_id=ops-1&from=2026-01-01T00:00:00Z&to=2026-02-01T00:00:00Z&limit=10", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"entries":[]}`, w.Body.String())
+	assert.Equal(t, "fac-1", svc.filter.FacilityID)
+	assert.Equal(t, "ops-1", svc.filter.ActorID)
+	assert.Equal(t, 10, svc.filter.Limit)
+	require.NotNil(t, svc.filter.From)
+	require.NotNil(t, svc.filter.To)
+	assert.Equal(t, 2026, svc.filter.From.Year())
+
+	w = httptest.NewRecorder()
+	handler.ListEntries(w, httptest.NewRequest(http.MethodGet, "/api/admin/audit?from=yesterday", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestAuditHandler_VerifyChain(t *testing.T) {
+	handler := handlers.NewAuditHandler(&fakeAuditLogService{})
+
+	w := httptest.NewRecorder()
+	handler.VerifyChain(w, httptest.NewRequest(http.MethodGet, "/api/admin/audit/verify", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"valid":true,"checked":3}`, w.Body.String())
+}
@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+)
+
+// CalendarService defines the native calendar operations used by the handler.
+type CalendarService interface {
+	GetCalendar(ctx context.Context, facilityID string) (*entities.FacilityCalendar, error)
+	SaveCalendar(ctx context.Context, calendar *entities.FacilityCalendar) error
+	ListTemplates(ctx context.Context, facilityID string) ([]*entities.SlotTemplate, error)
+	CreateTemplate(ctx context.Context, template *entities.SlotTemplate) error
+	DeleteTemplate(ctx context.Context, facilityID, templateID string) error
+	ListBlackouts(ctx context.Context, facilityID string, from, to time.Time) ([]*entities.CalendarBlackout, error)
+	CreateBlackout(ctx context.Context, blackout *entities.CalendarBlackout) error
+	DeleteBlackout(ctx context.Context, facilityID, blackoutID string) error
+	ListSlots(ctx context.Context, facilityID string, from, to time.Time) ([]entities.AvailabilitySlot, error)
+	HoldSlot(ctx context.Context, facilityID, slotID string) (*entities.SlotReservation, error)
+	ReleaseHold(ctx context.Context, facilityID, holdID string) error
+}
+
+// CalendarHandler serves operator calendar management and patient checkout holds.
+type CalendarHandler struct {
+	service CalendarService
+}
+
+// NewCalendarHandler creates a new calendar handler.
+func NewCalendarHandler(service CalendarService) *CalendarHandler {
+	return &CalendarHandler{service: service}
+}
+
+// GetCalendar handles GET /api/admin/facilities/{id}/calendar
+func (h *CalendarHandler) GetCalendar(w http.ResponseWriter, r *http.Request) {
+	calendar, err := h.service.GetCalendar(r.Context(), r.PathValue("id"))
+	if err != nil {
+		respondWithAppError(w, err, "failed to get calendar")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, calendar)
+}
+
+// SaveCalendar handles PUT /api/admin/facilities/{id}/calendar
+func (h *CalendarHandler) SaveCalendar(w http.ResponseWriter, r *http.Request) {
+	var calendar entities.FacilityCalendar
+	if err := json.NewDecoder(r.Body).Decode(&calendar); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	calendar.FacilityID = r.PathValue("id")
+
+	if err := h.service.SaveCalendar(r.Context(), &calendar); err != nil {
+		respondWithAppError(w, err, "failed to save calendar")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, calendar)
+}
+
+// ListTemplates handles GET /api/admin/facilities/{id}/calendar/templates
+func (h *CalendarHandler) ListTemplates(w http.ResponseWriter, r *http.Request) {
+	templates, err := h.service.ListTemplates(r.Context(), r.PathValue("id"))
+	if err != nil {
+		respondWithAppError(w, err, "failed to list templates")
+		return
+	}
+	if templates == nil {
+		templates = []*entities.SlotTemplate{}
+	}
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{"templates": templates})
+}
+
+// CreateTemplate handles POST /api/admin/facilities/{id}/calendar/templates
+func (h *CalendarHandler) CreateTemplate(w http.ResponseWriter, r *http.Request) {
+	var template entities.SlotTemplate
+	if err := json.NewDecoder(r.Body).Decode(&template); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	template.ID = ""
+	template.FacilityID = r.PathValue("id")
+
+	if err := h.service.CreateTemplate(r.Context(), &template); err != nil {
+		respondWithAppError(w, err, "failed to create template")
+		return
+	}
+	respondWithJSON(w, http.StatusCreated, template)
+}
+
+// DeleteTemplate handles DELETE /api/admin/facilities/{id}/calendar/templates/{templateId}
+func (h *CalendarHandler) DeleteTemplate(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.DeleteTemplate(r.Context(), r.PathValue("id"), r.PathValue("templateId")); err != nil {
+		respondWithAppError(w, err, "failed to delete template")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListBlackouts handles GET /api/admin/facilities/{id}/calendar/blackouts
+func (h *CalendarHandler) ListBlackouts(w http.ResponseWriter, r *http.Request) {
+	from, to, ok := parseCalendarRange(w, r, 90*24*time.Hour)
+	if !ok {
+		return
+	}
+	blackouts, err := h.service.ListBlackouts(r.Context(), r.PathValue("id"), from, to)
+	if err != nil {
+		respondWithAppError(w, err, "failed to list blackouts")
+		return
+	}
+	if blackouts == nil {
+		blackouts = []*entities.CalendarBlackout{}
+	}
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{"blackouts": blackouts})
+}
+
+// CreateBlackout handles POST /api/admin/facilities/{id}/calendar/blackouts
+func (h *CalendarHandler) CreateBlackout(w http.ResponseWriter, r *http.Request) {
+	var blackout entities.CalendarBlackout
+	if err := json.NewDecoder(r.Body).Decode(&blackout); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	blackout.ID = ""
+	blackout.FacilityID = r.PathValue("id")
+
+	if err := h.service.CreateBlackout(r.Context(), &blackout); err != nil {
+		respondWithAppError(w, err, "failed to create blackout")
+		return
+	}
+	respondWithJSON(w, http.StatusCreated, blackout)
+}
+
+// DeleteBlackout handles DELETE /api/admin/facilities/{id}/calendar/blackouts/{blackoutId}
+func (h *CalendarHandler) DeleteBlackout(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.DeleteBlackout(r.Context(), r.PathValue("id"), r.PathValue("blackoutId")); err != nil {
+		respondWithAppError(w, err, "failed to delete blackout")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListSlots handles GET /api/admin/facilities/{id}/calendar/slots
+func (h *CalendarHandler) ListSlots(w http.ResponseWriter, r *http.Request) {
+	from, to, ok := parseCalendarRange(w, r, 7*24*time.Hour)
+	if !ok {
+		return
+	}
+	slots, err := h.service.ListSlots(r.Context(), r.PathValue("id"), from, to)
+	if err != nil {
+		respondWithAppError(w, err, "failed to list slots")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{"slots": slots})
+}
+
+// HoldSlot handles POST /api/appointments/holds
+func (h *CalendarHandler) HoldSlot(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		FacilityID string `json:"facility_id"`
+		SlotID     string `json:"slot_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	hold, err := h.service.HoldSlot(r.Context(), req.FacilityID, req.SlotID)
+	if err != nil {
+		respondWithAppError(w, err, "failed to hold slot")
+		return
+	}
+	respondWithJSON(w, http.StatusCreated, hold)
+}
+
+// ReleaseHold handles DELETE /api/appointments/holds/{id}?facility_id=
+func (h *CalendarHandler) ReleaseHold(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.ReleaseHold(r.Context(), r.URL.Query().Get("facility_id"), r.PathValue("id")); err != nil {
+		respondWithAppError(w, err, "failed to release hold")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// parseCalendarRange reads RFC3339 from/to query parameters. from defaults to
+// now and to defaults to from plus the given window.
+func parseCalendarRange(w http.ResponseWriter, r *http.Request, window time.Duration) (time.Time, time.Time, bool) {
+	from := time.Now().UTC()
+	if v := r.URL.Query().Get("from"); v != "" {
+		parsed, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "invalid from date format (use RFC3339)")
+			return time.Time{}, time.Time{}, false
+		}
+		from = parsed
+	}
+	to := from.Add(window)
+	if v := r.URL.Query().Get("to"); v != "" {
+		parsed, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "invalid to date format (use RFC3339)")
+			return time.Time{}, time.Time{}, false
+		}
+		to = parsed
+	}
+	return from, to, true
+}
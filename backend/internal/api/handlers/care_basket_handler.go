@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+)
+
+// CareBasketQuoter defines the basket quoting used by the handler
+type CareBasketQuoter interface {
+	Quote(ctx context.Context, req entities.CareBasketRequest) (*entities.CareBasketQuote, error)
+}
+
+// CareBasketHandler quotes where a set of procedures is cheapest nearby
+type CareBasketHandler struct {
+	quoter CareBasketQuoter
+}
+
+// NewCareBasketHandler creates a new care basket handler
+func NewCareBasketHandler(quoter CareBasketQuoter) *CareBasketHandler {
+	return &CareBasketHandler{quoter: quoter}
+}
+
+// QuoteBasket handles POST /api/baskets/quote
+func (h *CareBasketHandler) QuoteBasket(w http.ResponseWriter, r *http.Request) {
+	var req entities.CareBasketRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	quote, err := h.quoter.Quote(r.Context(), req)
+	if err != nil {
+		respondWithAppError(w, err, "failed to quote basket")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, quote)
+}
@@ -0,0 +1,82 @@
+package handlers_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/api/handlers"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	apperrors "github.com/zatekoja/Patientpricediscoverydesign/backend/pkg/errors"
+)
+
+type fakeCareBasketQuoter struct {
+	req entities.CareBasketRequest
+	err error
+}
+
+func (f *fakeCareBasketQuoter) Quote(ctx context.Context, req entities.CareBasketRequest) (*entities.CareBasketQuote, error) {
+	f.req = req
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &entities.CareBasketQuote{
+		Items:           []entities.BasketProcedure{{ProcedureID: "fbc", Name: "Full Blood Count", Query: "blood count"}},
+		UnresolvedItems: []entities.CareBasketItem{},
+		Options: []entities.CareBasketOption{{
+			Visits: []entities.BasketVisit{{
+				FacilityID:       "lab",
+				FacilityName:     "Yaba Lab",
+				Items:            []entities.BasketItemPrice{{ProcedureID: "fbc", Name: "Full Blood Count", Price: 1000, Currency: "NGN"}},
+				ItemsTotal:       1000,
+				RegistrationFees: []entities.BasketFee{{ID: "card", Name: "Treatment Card", Price: 200}},
+				RegistrationFee:  200,
+				Total:            1200,
+			}},
+			MissingItems:     []entities.BasketProcedure{},
+			ItemsTotal:       1000,
+			RegistrationFees: 200,
+			Total:            1200,
+			Currency:         "NGN",
+		}},
+	}, nil
+}
+
+func TestCareBasketHandler_QuoteBasket(t *testing.T) {
+	quoter := &fakeCareBasketQuoter{}
+	handler := handlers.NewCareBasketHandler(quoter)
+
+	body := `{"items":[{"query":"blood count"}],"latitude":6.5,"longitude":3.37,"max_distance_km":15,"insurance_provider":"NHIS","allow_split":true}`
+	req := httptest.NewRequest(http.MethodPost, "/api/baskets/quote", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	handler.QuoteBasket(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"total":1200`)
+	assert.Contains(t, w.Body.String(), `"registration_fees":[{"id":"card","name":"Treatment Card","price":200}]`)
+	assert.Equal(t, "blood count", quoter.req.Items[0].Query)
+	assert.Equal(t, 15.0, quoter.req.MaxDistanceKm)
+	assert.Equal(t, "NHIS", quoter.req.InsuranceProvider)
+	assert.True(t, quoter.req.AllowSplit)
+
+	req = httptest.NewRequest(http.MethodPost, "/api/baskets/quote", strings.NewReader(`{"items":`))
+	w = httptest.NewRecorder()
+	handler.QuoteBasket(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	quoter.err = apperrors.NewValidationError("at least one item is required")
+	req = httptest.NewRequest(http.MethodPost, "/api/baskets/quote", strings.NewReader(`{"items":[]}`))
+	w = httptest.NewRecorder()
+	handler.QuoteBasket(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "at least one item is required")
+
+	quoter.err = errors.New("database unavailable")
+	req = httptest.NewRequest(http.MethodPost, "/api/baskets/quote", strings.NewReader(body))
+	w = httptest.NewRecorder()
+	handler.QuoteBasket(w, req)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
@@ -6,10 +6,13 @@ import (
 	"errors"
 	"log"
 	"net/http"
-	"regexp"
+	"net/url"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/google/uuid"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/api/middleware"
 	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/application/services"
 	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
 	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/repositories"
@@ -26,7 +29,9 @@ type FacilityService interface {
 	Suggest(ctx context.Context, query string, lat, lon float64, limit int) ([]*entities.Facility, error)
 	GetZeroResultQueries(ctx context.Context, limit int) ([]*entities.SearchEvent, error)
 	Update(ctx context.Context, facility *entities.Facility) error
-	UpdateServiceAvailability(ctx context.Context, facilityID, procedureID string, isAvailable bool) (*entities.FacilityProcedure, error)
+	UpdateServiceAvailability(ctx context.Context, facilityID, procedureID string, isAvailable bool, expectedVersion int) (*entities.FacilityProcedure, error)
+	PinLocation(ctx context.Context, facilityID string, location entities.Location, expectedVersion int) (*entities.Facility, error)
+	MapViewport(ctx context.Context, params repositories.SearchParams, zoom int) (*entities.FacilityMap, error)
 	ExpandQuery(query string) []string
 }
 
@@ -69,25 +74,17 @@ func (h *FacilityHandler) GetFacility(w http.ResponseWriter, r *http.Request) {
 	// Get facility from repository
 	facility, err := h.service.GetByID(r.Context(), facilityID)
 	if err != nil {
-		var appErr *apperrors.AppError
-		if errors.As(err, &appErr) {
-			switch appErr.Type {
-			case apperrors.ErrorTypeNotFound:
-				respondWithError(w, http.StatusNotFound, appErr.Message)
-			default:
-				respondWithError(w, http.StatusInternalServerError, "internal server error")
-			}
-			return
-		}
-		respondWithError(w, http.StatusInternalServerError, "internal server error")
+		respondWithAppError(w, err, "internal server error")
 		return
 	}
 
+	w.Header().Set("ETag", versionETag(facility.Version))
 	respondWithJSON(w, http.StatusOK, facility)
 }
 
 // UpdateFacility handles PATCH /api/facilities/:id
-// Updates facility real-time data (capacity, wait times, urgent care availability)
+// Updates facility real-time data (capacity, wait times, urgent care availability).
+// An If-Match header with the facility's ETag makes the update conditional.
 func (h *FacilityHandler) UpdateFacility(w http.ResponseWriter, r *http.Request) {
 	// Extract facility ID from URL path
 	facilityID := r.PathValue("id")
@@ -96,20 +93,20 @@ func (h *FacilityHandler) UpdateFacility(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	expectedVersion, ok := parseIfMatch(r)
+	if !ok {
+		respondWithError(w, http.StatusPreconditionFailed, "If-Match does not match the facility's ETag")
+		return
+	}
+
 	// Get existing facility
 	facility, err := h.service.GetByID(r.Context(), facilityID)
 	if err != nil {
-		var appErr *apperrors.AppError
-		if errors.As(err, &appErr) {
-			switch appErr.Type {
-			case apperrors.ErrorTypeNotFound:
-				respondWithError(w, http.StatusNotFound, appErr.Message)
-			default:
-				respondWithError(w, http.StatusInternalServerError, "internal server error")
-			}
-			return
-		}
-		respondWithError(w, http.StatusInternalServerError, "internal server error")
+		respondWithAppError(w, err, "internal server error")
+		return
+	}
+	if expectedVersion > 0 && facility.Version != expectedVersion {
+		respondWithError(w, http.StatusPreconditionFailed, "facility has been modified; reload it and retry")
 		return
 	}
 
@@ -125,18 +122,22 @@ func (h *FacilityHandler) UpdateFacility(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// Apply updates
+	// Apply updates; sending a value, even an unchanged one, confirms it is current
 	updated := false
+	now := time.Now()
 	if updateReq.CapacityStatus != nil {
 		facility.CapacityStatus = updateReq.CapacityStatus
+		facility.MarkCapacityReported(entities.CapacityFieldStatus, now)
 		updated = true
 	}
 	if updateReq.AvgWaitMinutes != nil {
 		facility.AvgWaitMinutes = updateReq.AvgWaitMinutes
+		facility.MarkCapacityReported(entities.CapacityFieldWaitTime, now)
 		updated = true
 	}
 	if updateReq.UrgentCareAvailable != nil {
 		facility.UrgentCareAvailable = updateReq.UrgentCareAvailable
+		facility.MarkCapacityReported(entities.CapacityFieldUrgentCare, now)
 		updated = true
 	}
 
@@ -145,12 +146,13 @@ func (h *FacilityHandler) UpdateFacility(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// Update facility
+	// Update facility; the write only applies at the version read above
 	if err := h.service.Update(r.Context(), facility); err != nil {
-		respondWithError(w, http.StatusInternalServerError, "failed to update facility")
+		respondWithFacilityWriteError(w, err, expectedVersion > 0, "failed to update facility")
 		return
 	}
 
+	w.Header().Set("ETag", versionETag(facility.Version))
 	respondWithJSON(w, http.StatusOK, facility)
 }
 
@@ -272,6 +274,12 @@ func (h *FacilityHandler) UpdateServiceAvailability(w http.ResponseWriter, r *ht
 		return
 	}
 
+	expectedVersion, ok := parseIfMatch(r)
+	if !ok {
+		respondWithError(w, http.StatusPreconditionFailed, "If-Match does not match the service's ETag")
+		return
+	}
+
 	var updateReq struct {
 		IsAvailable *bool `json:"is_available,omitempty"`
 	}
@@ -286,34 +294,105 @@ func (h *FacilityHandler) UpdateServiceAvailability(w http.ResponseWriter, r *ht
 		return
 	}
 
-	fp, err := h.service.UpdateServiceAvailability(r.Context(), facilityID, procedureID, *updateReq.IsAvailable)
+	fp, err := h.service.UpdateServiceAvailability(r.Context(), facilityID, procedureID, *updateReq.IsAvailable, expectedVersion)
 	if err != nil {
-		var appErr *apperrors.AppError
-		if errors.As(err, &appErr) {
-			switch appErr.Type {
-			case apperrors.ErrorTypeNotFound:
-				respondWithError(w, http.StatusNotFound, appErr.Message)
-				return
-			case apperrors.ErrorTypeValidation:
-				respondWithError(w, http.StatusBadRequest, appErr.Message)
-				return
-			default:
-				respondWithError(w, http.StatusInternalServerError, "failed to update service availability")
-				return
-			}
-		}
-		respondWithError(w, http.StatusInternalServerError, "failed to update service availability")
+		respondWithFacilityWriteError(w, err, expectedVersion > 0, "failed to update service availability")
 		return
 	}
 
+	w.Header().Set("ETag", versionETag(fp.Version))
 	respondWithJSON(w, http.StatusOK, map[string]interface{}{
 		"facility_id":  facilityID,
 		"procedure_id": procedureID,
 		"is_available": fp.IsAvailable,
 		"updated_at":   fp.UpdatedAt,
+		"version":      fp.Version,
 	})
 }
 
+// PinFacilityLocation handles PATCH /api/admin/facilities/{id}/location. It
+// sets the facility's coordinates by hand; ingestion never replaces them.
+func (h *FacilityHandler) PinFacilityLocation(w http.ResponseWriter, r *http.Request) {
+	facilityID := r.PathValue("id")
+	if facilityID == "" {
+		respondWithError(w, http.StatusBadRequest, "facility ID is required")
+		return
+	}
+
+	expectedVersion, ok := parseIfMatch(r)
+	if !ok {
+		respondWithError(w, http.StatusPreconditionFailed, "If-Match does not match the facility's ETag")
+		return
+	}
+
+	var pinReq struct {
+		Latitude  *float64 `json:"latitude"`
+		Longitude *float64 `json:"longitude"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&pinReq); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if pinReq.Latitude == nil || pinReq.Longitude == nil {
+		respondWithError(w, http.StatusBadRequest, "latitude and longitude are required")
+		return
+	}
+
+	location := entities.Location{Latitude: *pinReq.Latitude, Longitude: *pinReq.Longitude}
+	facility, err := h.service.PinLocation(r.Context(), facilityID, location, expectedVersion)
+	if err != nil {
+		respondWithFacilityWriteError(w, err, expectedVersion > 0, "failed to pin facility location")
+		return
+	}
+
+	w.Header().Set("ETag", versionETag(facility.Version))
+	respondWithJSON(w, http.StatusOK, facility)
+}
+
+// respondWithFacilityWriteError maps a failed facility or service write. A
+// version conflict is 412 when the client sent If-Match, since its
+// precondition no longer holds, and 409 when the row changed between this
+// request's own read and write.
+func respondWithFacilityWriteError(w http.ResponseWriter, err error, conditional bool, fallback string) {
+	var appErr *apperrors.AppError
+	if errors.As(err, &appErr) {
+		if appErr.Type == apperrors.ErrorTypeConflict {
+			if conditional {
+				respondWithError(w, http.StatusPreconditionFailed, "modified by another update; reload it and retry")
+			} else {
+				respondWithError(w, http.StatusConflict, "modified by another update; retry")
+			}
+			return
+		}
+	}
+	respondWithAppError(w, err, fallback)
+}
+
+// versionETag is the ETag for a row version, sent back in If-Match to make an
+// update conditional on nothing else having changed the row
+func versionETag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// parseIfMatch returns the version an If-Match header requires, or 0 when the
+// header is absent or "*". It returns false when the header is not an ETag
+// this API issued, which can never match.
+func parseIfMatch(r *http.Request) (int, bool) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" || header == "*" {
+		return 0, true
+	}
+	unquoted, err := strconv.Unquote(header)
+	if err != nil {
+		return 0, false
+	}
+	version, err := strconv.Atoi(unquoted)
+	if err != nil || version <= 0 {
+		return 0, false
+	}
+	return version, true
+}
+
 // ListFacilities handles GET /api/facilities
 func (h *FacilityHandler) ListFacilities(w http.ResponseWriter, r *http.Request) {
 	// Parse query parameters
@@ -382,14 +461,63 @@ func (h *FacilityHandler) SearchFacilities(w http.ResponseWriter, r *http.Reques
 	}
 
 	params := repositories.SearchParams{
-		Query:     strings.TrimSpace(query.Get("query")),
-		Latitude:  lat,
-		Longitude: lon,
-		RadiusKm:  radius,
-		Limit:     limit,
-		Offset:    offset,
+		Query:       strings.TrimSpace(query.Get("query")),
+		ProcedureID: strings.TrimSpace(query.Get("procedure_id")),
+		Latitude:    lat,
+		Longitude:   lon,
+		RadiusKm:    radius,
+		Limit:       limit,
+		Offset:      offset,
+		SessionID:   sessionIDFromRequest(r),
+		// The search is logged under the impression ID it was first served with;
+		// cache HITs of it are logged under their own by SearchImpressions
+		ImpressionID: middleware.ImpressionIDFromContext(r.Context()),
+	}
+	if params.ImpressionID == "" {
+		params.ImpressionID = uuid.New().String()
+		w.Header().Set(middleware.ImpressionIDHeader, params.ImpressionID)
+	}
+
+	if err := parseSearchFilters(query, &params); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	params.SortBy = strings.TrimSpace(query.Get("sort_by"))
+	if !repositories.IsValidSearchSort(params.SortBy) {
+		respondWithError(w, http.StatusBadRequest, "invalid sort_by parameter (must be price, distance, rating or travel_time)")
+		return
 	}
 
+	// Constraints read from the query that the user removed
+	for _, constraint := range strings.Split(query.Get("ignore_constraints"), ",") {
+		if constraint = strings.TrimSpace(constraint); constraint != "" {
+			params.IgnoredConstraints = append(params.IgnoredConstraints, constraint)
+		}
+	}
+
+	// Search facilities
+	facilities, totalCount, interpretation, err := h.service.SearchResultsWithCount(r.Context(), params)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "failed to search facilities")
+		return
+	}
+
+	response := map[string]interface{}{
+		"facilities": facilities,
+		"count":      totalCount,
+		"search_id":  params.ImpressionID,
+	}
+
+	if interpretation != nil {
+		response["query_interpretation"] = interpretation
+	}
+
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+// parseSearchFilters reads the filters shared by search and the map into params
+func parseSearchFilters(query url.Values, params *repositories.SearchParams) error {
 	if insuranceProvider := strings.TrimSpace(query.Get("insurance_provider")); insuranceProvider != "" {
 		params.InsuranceProvider = insuranceProvider
 	}
@@ -397,8 +525,7 @@ func (h *FacilityHandler) SearchFacilities(w http.ResponseWriter, r *http.Reques
 	if minPriceStr := strings.TrimSpace(query.Get("min_price")); minPriceStr != "" {
 		minPrice, err := strconv.ParseFloat(minPriceStr, 64)
 		if err != nil || minPrice < 0 {
-			respondWithError(w, http.StatusBadRequest, "invalid min_price parameter")
-			return
+			return errors.New("invalid min_price parameter")
 		}
 		params.MinPrice = &minPrice
 	}
@@ -406,29 +533,80 @@ func (h *FacilityHandler) SearchFacilities(w http.ResponseWriter, r *http.Reques
 	if maxPriceStr := strings.TrimSpace(query.Get("max_price")); maxPriceStr != "" {
 		maxPrice, err := strconv.ParseFloat(maxPriceStr, 64)
 		if err != nil || maxPrice < 0 {
-			respondWithError(w, http.StatusBadRequest, "invalid max_price parameter")
-			return
+			return errors.New("invalid max_price parameter")
 		}
 		params.MaxPrice = &maxPrice
 	}
 
-	// Search facilities
-	facilities, totalCount, interpretation, err := h.service.SearchResultsWithCount(r.Context(), params)
+	params.OpenNow = query.Get("open_now") == "true"
+	params.Open24Hours = query.Get("open_24_hours") == "true"
+	return nil
+}
+
+// GetFacilityMap handles GET /api/facilities/map
+func (h *FacilityHandler) GetFacilityMap(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	bounds, err := parseBoundingBox(query.Get("bbox"))
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "failed to search facilities")
+		respondWithError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	response := map[string]interface{}{
-		"facilities": facilities,
-		"count":      totalCount,
+	zoom, err := strconv.Atoi(query.Get("zoom"))
+	if err != nil || zoom < 0 || zoom > services.MaxMapZoom {
+		respondWithError(w, http.StatusBadRequest, "invalid zoom parameter (must be 0-22)")
+		return
 	}
 
-	if interpretation != nil {
-		response["query_interpretation"] = interpretation
+	params := repositories.SearchParams{
+		Query:       strings.TrimSpace(query.Get("query")),
+		ProcedureID: strings.TrimSpace(query.Get("procedure_id")),
+		Bounds:      bounds,
+	}
+	if facilityType := strings.TrimSpace(query.Get("facility_type")); facilityType != "" {
+		params.FacilityTypes = []string{facilityType}
+	}
+	if err := parseSearchFilters(query, &params); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
 	}
 
-	respondWithJSON(w, http.StatusOK, response)
+	viewport, err := h.service.MapViewport(r.Context(), params, zoom)
+	if err != nil {
+		respondWithAppError(w, err, "failed to load facility map")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, viewport)
+}
+
+// parseBoundingBox reads a bbox given as min_lon,min_lat,max_lon,max_lat
+func parseBoundingBox(value string) (*entities.GeoBounds, error) {
+	invalid := errors.New("invalid bbox parameter (must be min_lon,min_lat,max_lon,max_lat)")
+	parts := strings.Split(value, ",")
+	if len(parts) != 4 {
+		return nil, invalid
+	}
+	coordinates := make([]float64, len(parts))
+	for i, part := range parts {
+		coordinate, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return nil, invalid
+		}
+		coordinates[i] = coordinate
+	}
+	bounds := &entities.GeoBounds{
+		MinLongitude: coordinates[0],
+		MinLatitude:  coordinates[1],
+		MaxLongitude: coordinates[2],
+		MaxLatitude:  coordinates[3],
+	}
+	if bounds.MinLatitude < -90 || bounds.MaxLatitude > 90 || bounds.MinLongitude < -180 || bounds.MaxLongitude > 180 ||
+		bounds.MinLatitude >= bounds.MaxLatitude || bounds.MinLongitude >= bounds.MaxLongitude {
+		return nil, invalid
+	}
+	return bounds, nil
 }
 
 // SuggestFacilities handles GET /api/facilities/suggest
@@ -471,6 +649,7 @@ func (h *FacilityHandler) SuggestFacilities(w http.ResponseWriter, r *http.Reque
 		RadiusKm:  50,
 		Limit:     limit,
 		Offset:    0,
+		SessionID: sessionIDFromRequest(r),
 	}
 
 	results, err := h.service.SearchResults(r.Context(), params)
@@ -600,102 +779,13 @@ func (h *FacilityHandler) GetFacilityServiceFees(w http.ResponseWriter, r *http.
 		return
 	}
 
-	// Service fees should reflect *registration-type* fees (e.g., folder/card/appointment),
+	// Service fees reflect registration-type fees (e.g., folder/card/appointment),
 	// not documentation/report items (certificates, police report, medical report, etc.).
-	//
-	// Some datasets historically stored these under category=administrative.
-	// Newer ingestions/migrations may split into category=registration.
-	// To stay backward-compatible, we fetch both categories and then apply a conservative filter.
-	getByCategory := func(category string) ([]*entities.FacilityProcedure, error) {
-		filter := repositories.FacilityProcedureFilter{
-			Category:  category,
-			Limit:     500,
-			Offset:    0,
-			SortBy:    "name",
-			SortOrder: "asc",
-		}
-		items, _, err := h.facilityProcedureService.ListByFacilityWithCount(r.Context(), facilityID, filter)
-		return items, err
-	}
-
-	var services []*entities.FacilityProcedure
-	reg, err := getByCategory("registration")
+	fees, err := services.ListRegistrationFees(r.Context(), h.facilityProcedureService, facilityID)
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, "failed to fetch service fees")
 		return
 	}
-	services = append(services, reg...)
-
-	admin, err := getByCategory("administrative")
-	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "failed to fetch service fees")
-		return
-	}
-	services = append(services, admin...)
-
-	// De-duplicate by facility_procedure id.
-	seenIDs := make(map[string]struct{}, len(services))
-	dedup := make([]*entities.FacilityProcedure, 0, len(services))
-	for _, svc := range services {
-		if svc == nil || svc.ID == "" {
-			continue
-		}
-		if _, ok := seenIDs[svc.ID]; ok {
-			continue
-		}
-		seenIDs[svc.ID] = struct{}{}
-		dedup = append(dedup, svc)
-	}
-	services = dedup
-
-	includeRe := regexp.MustCompile(`(?i)\b(folder|appointment\s*card|registration|chart)\b`)
-	treatmentCardRe := regexp.MustCompile(`(?i)\btreatment\s*card\b`)
-	appointmentCardRe := regexp.MustCompile(`(?i)\bappointment\s*card\b`)
-	// Exclude documentation/report items that inflate totals.
-	excludeRe := regexp.MustCompile(`(?i)\b(certificate|report|police|assault|adoption|notification\s+of\s+birth|sick\s+leave|maternity\s+leave|suppliers?|contractors?|leaving\s+the\s+country)\b`)
-
-	// First pass: keep only registration-style items and drop documentation/report items.
-	filtered := make([]*entities.FacilityProcedure, 0, len(services))
-	appointmentCardPresent := false
-	for _, svc := range services {
-		name := strings.TrimSpace(svc.ProcedureName)
-		lower := strings.ToLower(name)
-		if lower == "" {
-			continue
-		}
-		if excludeRe.MatchString(lower) {
-			continue
-		}
-		// Keep explicit registration-style terms.
-		if includeRe.MatchString(lower) {
-			if appointmentCardRe.MatchString(lower) {
-				appointmentCardPresent = true
-			}
-			filtered = append(filtered, svc)
-			continue
-		}
-		// Optionally include treatment card only when appointment card is NOT present.
-		if treatmentCardRe.MatchString(lower) {
-			filtered = append(filtered, svc)
-			continue
-		}
-		_ = name
-	}
-
-	if appointmentCardPresent {
-		// If an appointment card exists, exclude treatment card to avoid double-counting.
-		final := make([]*entities.FacilityProcedure, 0, len(filtered))
-		for _, svc := range filtered {
-			lower := strings.ToLower(strings.TrimSpace(svc.ProcedureName))
-			if treatmentCardRe.MatchString(lower) {
-				continue
-			}
-			final = append(final, svc)
-		}
-		filtered = final
-	}
-
-	services = filtered
 
 	type serviceFeeItem struct {
 		ID       string  `json:"id"`
@@ -705,11 +795,11 @@ func (h *FacilityHandler) GetFacilityServiceFees(w http.ResponseWriter, r *http.
 		Code     string  `json:"code"`
 	}
 
-	fees := make([]serviceFeeItem, 0, len(services))
+	items := make([]serviceFeeItem, 0, len(fees))
 	var total float64
 	currency := "NGN"
-	for _, svc := range services {
-		fees = append(fees, serviceFeeItem{
+	for _, svc := range fees {
+		items = append(items, serviceFeeItem{
 			ID:       svc.ID,
 			Name:     svc.ProcedureName,
 			Price:    svc.Price,
@@ -723,7 +813,7 @@ func (h *FacilityHandler) GetFacilityServiceFees(w http.ResponseWriter, r *http.
 	}
 
 	respondWithJSON(w, http.StatusOK, map[string]interface{}{
-		"fees":     fees,
+		"fees":     items,
 		"total":    total,
 		"currency": currency,
 	})
@@ -748,18 +838,14 @@ func (h *FacilityHandler) GetZeroResultQueries(w http.ResponseWriter, r *http.Re
 }
 
 // Helper functions
-func respondWithJSON(w http.ResponseWriter, statusCode int, payload interface{}) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(statusCode)
-	if err := json.NewEncoder(w).Encode(payload); err != nil {
-		log.Printf("failed to encode JSON response: %v", err)
-	}
-}
 
-func respondWithError(w http.ResponseWriter, statusCode int, message string) {
-	respondWithJSON(w, statusCode, map[string]string{
-		"error": message,
-	})
+// sessionIDFromRequest returns the anonymous client session ID from the
+// X-Session-ID header, falling back to the session_id query parameter.
+func sessionIDFromRequest(r *http.Request) string {
+	if id := strings.TrimSpace(r.Header.Get("X-Session-ID")); id != "" {
+		return id
+	}
+	return strings.TrimSpace(r.URL.Query().Get("session_id"))
 }
 
 func parseIntDefault(str string, defaultVal int) int {
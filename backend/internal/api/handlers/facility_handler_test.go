@@ -11,10 +11,13 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/api/handlers"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/api/middleware"
 	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/application/services"
 	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
 	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/repositories"
+	apperrors "github.com/zatekoja/Patientpricediscoverydesign/backend/pkg/errors"
 )
 
 type MockFacilityService struct {
@@ -78,14 +81,30 @@ func (m *MockFacilityService) Update(ctx context.Context, facility *entities.Fac
 	return args.Error(0)
 }
 
-func (m *MockFacilityService) UpdateServiceAvailability(ctx context.Context, facilityID, procedureID string, isAvailable bool) (*entities.FacilityProcedure, error) {
-	args := m.Called(ctx, facilityID, procedureID, isAvailable)
+func (m *MockFacilityService) UpdateServiceAvailability(ctx context.Context, facilityID, procedureID string, isAvailable bool, expectedVersion int) (*entities.FacilityProcedure, error) {
+	args := m.Called(ctx, facilityID, procedureID, isAvailable, expectedVersion)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*entities.FacilityProcedure), args.Error(1)
 }
 
+func (m *MockFacilityService) PinLocation(ctx context.Context, facilityID string, location entities.Location, expectedVersion int) (*entities.Facility, error) {
+	args := m.Called(ctx, facilityID, location, expectedVersion)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Facility), args.Error(1)
+}
+
+func (m *MockFacilityService) MapViewport(ctx context.Context, params repositories.SearchParams, zoom int) (*entities.FacilityMap, error) {
+	args := m.Called(ctx, params, zoom)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.FacilityMap), args.Error(1)
+}
+
 func (m *MockFacilityService) ExpandQuery(query string) []string {
 	args := m.Called(query)
 	return args.Get(0).([]string)
@@ -98,6 +117,7 @@ func (m *MockFacilityService) GetZeroResultQueries(ctx context.Context, limit in
 type searchFacilitiesResponse struct {
 	Facilities []entities.FacilitySearchResult `json:"facilities"`
 	Count      int                             `json:"count"`
+	SearchID   string                          `json:"search_id"`
 }
 
 type suggestFacilitiesResponse struct {
@@ -177,6 +197,89 @@ func TestFacilityHandler_SearchFacilities_ReturnsContract(t *testing.T) {
 	assert.Equal(t, expected[0].ServicePrices, resp.Facilities[0].ServicePrices)
 }
 
+func TestFacilityHandler_SearchFacilities_ReturnsUniqueImpressionID(t *testing.T) {
+	mockService := new(MockFacilityService)
+	handler := handlers.NewFacilityHandler(mockService)
+
+	var passed []string
+	mockService.On("SearchResultsWithCount", mock.Anything, mock.MatchedBy(func(p repositories.SearchParams) bool {
+		passed = append(passed, p.ImpressionID)
+		return true
+	})).Return([]entities.FacilitySearchResult{}, 0, nil, nil)
+
+	var ids []string
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest("GET", "/api/facilities/search?lat=6.5244&lon=3.3792&query=clinic", nil)
+		w := httptest.NewRecorder()
+		handler.SearchFacilities(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var resp searchFacilitiesResponse
+		assert.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		id := w.Header().Get(middleware.ImpressionIDHeader)
+		assert.NotEmpty(t, id)
+		assert.Equal(t, id, resp.SearchID)
+		ids = append(ids, id)
+	}
+
+	assert.NotEqual(t, ids[0], ids[1])
+	assert.Contains(t, passed, ids[0], "the service logs the search under the returned impression ID")
+}
+
+type recordedImpression struct {
+	searchID, impressionID string
+}
+
+type stubImpressionRecorder struct {
+	recorded []recordedImpression
+}
+
+func (r *stubImpressionRecorder) TrackCachedImpression(ctx context.Context, searchID string, impression *entities.SearchEvent) {
+	r.recorded = append(r.recorded, recordedImpression{searchID, impression.ID})
+}
+
+func TestFacilityHandler_SearchFacilities_CacheHitGetsItsOwnImpression(t *testing.T) {
+	mockService := new(MockFacilityService)
+	handler := handlers.NewFacilityHandler(mockService)
+	mockService.On("SearchResultsWithCount", mock.Anything, mock.Anything).
+		Return([]entities.FacilitySearchResult{}, 0, nil, nil).Once()
+
+	// Serves the first response, then replays its body as the cache would
+	var cached []byte
+	cache := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if cached != nil {
+			w.Header().Set("X-Cache", "HIT")
+			_, _ = w.Write(cached)
+			return
+		}
+		rec := httptest.NewRecorder()
+		handler.SearchFacilities(rec, r)
+		cached = rec.Body.Bytes()
+		w.Header().Set("X-Cache", "MISS")
+		_, _ = w.Write(cached)
+	})
+	recorder := &stubImpressionRecorder{}
+	search := middleware.SearchImpressions(recorder)(cache)
+
+	var ids []string
+	var searchID string
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		search.ServeHTTP(w, httptest.NewRequest("GET", "/api/facilities/search?lat=6.5244&lon=3.3792&query=clinic", nil))
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var resp searchFacilitiesResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		searchID = resp.SearchID
+		ids = append(ids, w.Header().Get(middleware.ImpressionIDHeader))
+	}
+
+	assert.Equal(t, ids[0], searchID, "the search is logged under the impression it was first served with")
+	assert.NotEqual(t, ids[0], ids[1])
+	assert.Equal(t, []recordedImpression{{searchID, ids[1]}}, recorder.recorded)
+	mockService.AssertExpectations(t)
+}
+
 func TestFacilityHandler_SuggestFacilities_ReturnsServicePrices(t *testing.T) {
 	mockService := new(MockFacilityService)
 	handler := handlers.NewFacilityHandler(mockService)
@@ -234,6 +337,21 @@ func TestFacilityHandler_SuggestFacilities_ReturnsServicePrices(t *testing.T) {
 	assert.Equal(t, expected[0].Price.Currency, resp.Suggestions[0].Price.Currency)
 }
 
+func TestFacilityHandler_GetFacilitySetsETag(t *testing.T) {
+	mockService := new(MockFacilityService)
+	handler := handlers.NewFacilityHandler(mockService)
+	mockService.On("GetByID", mock.Anything, "fac_001").Return(&entities.Facility{ID: "fac_001", Version: 12}, nil)
+
+	req := httptest.NewRequest("GET", "/api/facilities/fac_001", nil)
+	req.SetPathValue("id", "fac_001")
+	w := httptest.NewRecorder()
+
+	handler.GetFacility(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"12"`, w.Header().Get("ETag"))
+}
+
 func TestFacilityHandler_UpdateFacility(t *testing.T) {
 	t.Run("should update facility capacity", func(t *testing.T) {
 		mockService := new(MockFacilityService)
@@ -286,6 +404,9 @@ func TestFacilityHandler_UpdateFacility(t *testing.T) {
 		assert.NoError(t, err)
 
 		assert.Equal(t, "high", *response.CapacityStatus)
+		// Only the reported field restarts its freshness window
+		assert.NotNil(t, response.CapacityStatusReportedAt)
+		assert.Nil(t, response.AvgWaitReportedAt)
 		mockService.AssertExpectations(t)
 	})
 
@@ -306,4 +427,169 @@ func TestFacilityHandler_UpdateFacility(t *testing.T) {
 
 		assert.Equal(t, http.StatusBadRequest, w.Code)
 	})
+
+	t.Run("should reject a stale If-Match without writing", func(t *testing.T) {
+		mockService := new(MockFacilityService)
+		handler := handlers.NewFacilityHandler(mockService)
+		mockService.On("GetByID", mock.Anything, "fac_001").Return(&entities.Facility{ID: "fac_001", Version: 4}, nil)
+
+		req := httptest.NewRequest("PATCH", "/api/facilities/fac_001", strings.NewReader(`{"avg_wait_minutes": 30}`))
+		req.SetPathValue("id", "fac_001")
+		req.Header.Set("If-Match", `"3"`)
+		w := httptest.NewRecorder()
+
+		handler.UpdateFacility(w, req)
+
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+		mockService.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should return the new ETag after a conditional update", func(t *testing.T) {
+		mockService := new(MockFacilityService)
+		handler := handlers.NewFacilityHandler(mockService)
+		mockService.On("GetByID", mock.Anything, "fac_001").Return(&entities.Facility{ID: "fac_001", Version: 4}, nil)
+		mockService.On("Update", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			args.Get(1).(*entities.Facility).Version = 5
+		}).Return(nil)
+
+		req := httptest.NewRequest("PATCH", "/api/facilities/fac_001", strings.NewReader(`{"avg_wait_minutes": 30}`))
+		req.SetPathValue("id", "fac_001")
+		req.Header.Set("If-Match", `"4"`)
+		w := httptest.NewRecorder()
+
+		handler.UpdateFacility(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, `"5"`, w.Header().Get("ETag"))
+	})
+
+	t.Run("should map a lost compare-and-swap to 412 or 409", func(t *testing.T) {
+		for ifMatch, want := range map[string]int{`"4"`: http.StatusPreconditionFailed, "": http.StatusConflict} {
+			mockService := new(MockFacilityService)
+			handler := handlers.NewFacilityHandler(mockService)
+			mockService.On("GetByID", mock.Anything, "fac_001").Return(&entities.Facility{ID: "fac_001", Version: 4}, nil)
+			mockService.On("Update", mock.Anything, mock.Anything).Return(apperrors.NewConflictError("facility fac_001 was changed by another update"))
+
+			req := httptest.NewRequest("PATCH", "/api/facilities/fac_001", strings.NewReader(`{"avg_wait_minutes": 30}`))
+			req.SetPathValue("id", "fac_001")
+			if ifMatch != "" {
+				req.Header.Set("If-Match", ifMatch)
+			}
+			w := httptest.NewRecorder()
+
+			handler.UpdateFacility(w, req)
+
+			assert.Equal(t, want, w.Code, "If-Match %q", ifMatch)
+		}
+	})
+}
+
+func TestFacilityHandler_UpdateServiceAvailabilityIfMatch(t *testing.T) {
+	mockService := new(MockFacilityService)
+	handler := handlers.NewFacilityHandler(mockService)
+	mockService.On("UpdateServiceAvailability", mock.Anything, "fac_001", "proc_1", false, 7).
+		Return(&entities.FacilityProcedure{ID: "fp_1", IsAvailable: false, Version: 8}, nil)
+
+	req := httptest.NewRequest("PATCH", "/api/facilities/fac_001/services/proc_1", strings.NewReader(`{"is_available": false}`))
+	req.SetPathValue("id", "fac_001")
+	req.SetPathValue("procedureId", "proc_1")
+	req.Header.Set("If-Match", `"7"`)
+	w := httptest.NewRecorder()
+
+	handler.UpdateServiceAvailability(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"8"`, w.Header().Get("ETag"))
+	mockService.AssertExpectations(t)
+
+	req = httptest.NewRequest("PATCH", "/api/facilities/fac_001/services/proc_1", strings.NewReader(`{"is_available": false}`))
+	req.SetPathValue("id", "fac_001")
+	req.SetPathValue("procedureId", "proc_1")
+	req.Header.Set("If-Match", `W/"7"`)
+	w = httptest.NewRecorder()
+
+	handler.UpdateServiceAvailability(w, req)
+
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+}
+
+func TestFacilityHandler_PinFacilityLocation(t *testing.T) {
+	mockService := new(MockFacilityService)
+	handler := handlers.NewFacilityHandler(mockService)
+	location := entities.Location{Latitude: 6.5176, Longitude: 3.3566}
+	mockService.On("PinLocation", mock.Anything, "fac_001", location, 4).
+		Return(&entities.Facility{ID: "fac_001", Location: location, LocationSource: "manual", LocationPinned: true, Version: 5}, nil)
+	mockService.On("PinLocation", mock.Anything, "fac_001", entities.Location{Latitude: 0, Longitude: 0}, 0).
+		Return(nil, apperrors.NewValidationError("location is required"))
+
+	req := httptest.NewRequest("PATCH", "/api/admin/facilities/fac_001/location", strings.NewReader(`{"latitude": 6.5176, "longitude": 3.3566}`))
+	req.SetPathValue("id", "fac_001")
+	req.Header.Set("If-Match", `"4"`)
+	w := httptest.NewRecorder()
+
+	handler.PinFacilityLocation(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"5"`, w.Header().Get("ETag"))
+	var facility entities.Facility
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &facility))
+	assert.True(t, facility.LocationPinned)
+	assert.Equal(t, "manual", facility.LocationSource)
+
+	for _, body := range []string{`{"latitude": 6.5}`, `not json`, `{"latitude": 0, "longitude": 0}`} {
+		req = httptest.NewRequest("PATCH", "/api/admin/facilities/fac_001/location", strings.NewReader(body))
+		req.SetPathValue("id", "fac_001")
+		w = httptest.NewRecorder()
+
+		handler.PinFacilityLocation(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+	}
+	mockService.AssertExpectations(t)
+}
+
+func TestFacilityHandler_GetFacilityMap(t *testing.T) {
+	mockService := new(MockFacilityService)
+	handler := handlers.NewFacilityHandler(mockService)
+	maxPrice := 50000.0
+	bounds := &entities.GeoBounds{MinLatitude: 6.3, MinLongitude: 3.1, MaxLatitude: 6.7, MaxLongitude: 3.6}
+	params := repositories.SearchParams{
+		ProcedureID:       "proc_mri",
+		InsuranceProvider: "AXA Mansard",
+		MaxPrice:          &maxPrice,
+		OpenNow:           true,
+		Bounds:            bounds,
+	}
+	mockService.On("MapViewport", mock.Anything, params, 11).Return(&entities.FacilityMap{
+		Bounds:    *bounds,
+		Zoom:      11,
+		Clustered: true,
+		Clusters:  []entities.FacilityCluster{{ID: "11:3:2", Count: 3, CapacityMix: map[string]int{"available": 3}}},
+		Total:     3,
+	}, nil)
+
+	req := httptest.NewRequest("GET", "/api/facilities/map?bbox=3.1,6.3,3.6,6.7&zoom=11&procedure_id=proc_mri&insurance_provider=AXA+Mansard&max_price=50000&open_now=true", nil)
+	w := httptest.NewRecorder()
+
+	handler.GetFacilityMap(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var viewport entities.FacilityMap
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &viewport))
+	assert.True(t, viewport.Clustered)
+	require.Len(t, viewport.Clusters, 1)
+	assert.Equal(t, 3, viewport.Clusters[0].Count)
+
+	for _, target := range []string{
+		"/api/facilities/map?zoom=11",
+		"/api/facilities/map?bbox=3.6,6.3,3.1,6.7&zoom=11",
+		"/api/facilities/map?bbox=3.1,6.3,3.6&zoom=11",
+		"/api/facilities/map?bbox=3.1,6.3,3.6,6.7&zoom=30",
+		"/api/facilities/map?bbox=3.1,6.3,3.6,6.7&zoom=11&min_price=cheap",
+	} {
+		w = httptest.NewRecorder()
+		handler.GetFacilityMap(w, httptest.NewRequest("GET", target, nil))
+		assert.Equal(t, http.StatusBadRequest, w.Code, target)
+	}
+	mockService.AssertNumberOfCalls(t, "MapViewport", 1)
 }
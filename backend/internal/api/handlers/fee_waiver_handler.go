@@ -1,19 +1,22 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"log"
 	"net/http"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/application/services"
 	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
 	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/repositories"
 )
 
 // FeeWaiverHandler handles fee waiver endpoints
 type FeeWaiverHandler struct {
-	repo repositories.FeeWaiverRepository
+	repo  repositories.FeeWaiverRepository
+	audit AuditTransactor
 }
 
 // NewFeeWaiverHandler creates a new fee waiver handler
@@ -21,6 +24,11 @@ func NewFeeWaiverHandler(repo repositories.FeeWaiverRepository) *FeeWaiverHandle
 	return &FeeWaiverHandler{repo: repo}
 }
 
+// SetAuditLog records created waivers in the audit log
+func (h *FeeWaiverHandler) SetAuditLog(audit AuditTransactor) {
+	h.audit = audit
+}
+
 // GetFacilityFeeWaiver handles GET /api/facilities/{id}/fee-waiver
 func (h *FeeWaiverHandler) GetFacilityFeeWaiver(w http.ResponseWriter, r *http.Request) {
 	facilityID := r.PathValue("id")
@@ -113,7 +121,28 @@ func (h *FeeWaiverHandler) CreateFeeWaiver(w http.ResponseWriter, r *http.Reques
 		waiver.ValidUntil = &t
 	}
 
-	if err := h.repo.Create(r.Context(), waiver); err != nil {
+	var err error
+	if h.audit != nil {
+		err = h.audit.Transact(r.Context(), func(ctx context.Context) (*services.AuditEvent, error) {
+			if err := h.repo.Create(ctx, waiver); err != nil {
+				return nil, err
+			}
+			facilityID := ""
+			if waiver.FacilityID != nil {
+				facilityID = *waiver.FacilityID
+			}
+			return &services.AuditEvent{
+				Action:     "fee_waiver.create",
+				EntityType: "fee_waiver",
+				EntityID:   waiver.ID,
+				FacilityID: facilityID,
+				After:      waiver,
+			}, nil
+		})
+	} else {
+		err = h.repo.Create(r.Context(), waiver)
+	}
+	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, "failed to create fee waiver")
 		return
 	}
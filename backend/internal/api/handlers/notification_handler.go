@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+)
+
+// NotificationAdminService defines the notification queue operations used by the handler.
+type NotificationAdminService interface {
+	ListNotifications(ctx context.Context, status entities.NotificationStatus, limit, offset int) ([]*entities.AppointmentNotification, error)
+	ResendNotification(ctx context.Context, id string) (*entities.AppointmentNotification, error)
+}
+
+// NotificationHandler serves the failed notification queue for admins.
+type NotificationHandler struct {
+	service NotificationAdminService
+}
+
+// NewNotificationHandler creates a new notification handler.
+func NewNotificationHandler(service NotificationAdminService) *NotificationHandler {
+	return &NotificationHandler{service: service}
+}
+
+// ListNotifications handles GET /api/admin/notifications?status=failed
+func (h *NotificationHandler) ListNotifications(w http.ResponseWriter, r *http.Request) {
+	status := entities.NotificationStatus(strings.TrimSpace(r.URL.Query().Get("status")))
+	limit := parseIntDefault(r.URL.Query().Get("limit"), 50)
+	offset := parseIntDefault(r.URL.Query().Get("offset"), 0)
+
+	notifications, err := h.service.ListNotifications(r.Context(), status, limit, offset)
+	if err != nil {
+		respondWithAppError(w, err, "failed to list notifications")
+		return
+	}
+	if notifications == nil {
+		notifications = []*entities.AppointmentNotification{}
+	}
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{"notifications": notifications})
+}
+
+// ResendNotification handles POST /api/admin/notifications/{id}/resend
+func (h *NotificationHandler) ResendNotification(w http.ResponseWriter, r *http.Request) {
+	notification, err := h.service.ResendNotification(r.Context(), r.PathValue("id"))
+	if err != nil {
+		respondWithAppError(w, err, "failed to resend notification")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, notification)
+}
@@ -0,0 +1,71 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/api/handlers"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	apperrors "github.com/zatekoja/Patientpricediscoverydesign/backend/pkg/errors"
+)
+
+type fakeNotificationAdminService struct {
+	statusSeen entities.NotificationStatus
+	resendErr  error
+}
+
+func (f *fakeNotificationAdminService) ListNotifications(ctx context.Context, status entities.NotificationStatus, limit, offset int) ([]*entities.AppointmentNotification, error) {
+	f.statusSeen = status
+	if status == "bogus" {
+		return nil, apperrors.NewValidationError(`unknown notification status "bogus"`)
+	}
+	return nil, nil
+}
+
+func (f *fakeNotificationAdminService) ResendNotification(ctx context.Context, id string) (*entities.AppointmentNotification, error) {
+	if f.resendErr != nil {
+		return nil, f.resendErr
+	}
+	return &entities.AppointmentNotification{ID: id, Status: entities.NotificationStatusSent}, nil
+}
+
+func TestNotificationHandler_ListNotifications(t *testing.T) {
+	svc := &fakeNotificationAdminService{}
+	handler := handlers.NewNotificationHandler(svc)
+
+	w := httptest.NewRecorder()
+	handler.ListNotifications(w, httptest.NewRequest(http.MethodGet, "/api/admin/notifications?status=dead_letter", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, entities.NotificationStatusDeadLetter, svc.statusSeen)
+	assert.JSONEq(t, `{"notifications":[]}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	handler.ListNotifications(w, httptest.NewRequest(http.MethodGet, "/api/admin/notifications?status=bogus", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestNotificationHandler_ResendNotification(t *testing.T) {
+	svc := &fakeNotificationAdminService{}
+	handler := handlers.NewNotificationHandler(svc)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/admin/notifications/notif-1/resend", nil)
+	req.SetPathValue("id", "notif-1")
+	w := httptest.NewRecorder()
+	handler.ResendNotification(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"sent"`)
+
+	for err, want := range map[error]int{
+		apperrors.NewNotFoundError("notification not found"):                  http.StatusNotFound,
+		apperrors.NewConflictError("only failed notifications can be resent"): http.StatusConflict,
+		apperrors.NewExternalError("failed to resend notification", nil):      http.StatusBadGateway,
+	} {
+		svc.resendErr = err
+		w = httptest.NewRecorder()
+		handler.ResendNotification(w, req)
+		assert.Equal(t, want, w.Code, err.Error())
+	}
+}
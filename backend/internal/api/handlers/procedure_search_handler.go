@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/repositories"
+)
+
+// ProcedureSearcher defines the procedure search and autocomplete used by the handler
+type ProcedureSearcher interface {
+	Search(ctx context.Context, params repositories.ProcedureSearchParams) ([]*entities.ProcedureSearchResult, int, error)
+	Suggest(ctx context.Context, query string, lat, lon float64, limit int) ([]entities.Suggestion, error)
+}
+
+// ProcedureSearchHandler serves procedure search and typed autocomplete
+type ProcedureSearchHandler struct {
+	searcher ProcedureSearcher
+}
+
+// NewProcedureSearchHandler creates a new procedure search handler
+func NewProcedureSearchHandler(searcher ProcedureSearcher) *ProcedureSearchHandler {
+	return &ProcedureSearchHandler{searcher: searcher}
+}
+
+// SearchProcedures handles GET /api/procedures/search?query=&category=&limit=&offset=
+func (h *ProcedureSearchHandler) SearchProcedures(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	limit := 20
+	if limitStr := query.Get("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed <= 0 || parsed > 50 {
+			respondWithError(w, http.StatusBadRequest, "invalid limit parameter (must be 1-50)")
+			return
+		}
+		limit = parsed
+	}
+
+	offset := 0
+	if offsetStr := query.Get("offset"); offsetStr != "" {
+		parsed, err := strconv.Atoi(offsetStr)
+		if err != nil || parsed < 0 || parsed%limit != 0 {
+			respondWithError(w, http.StatusBadRequest, "invalid offset parameter (must be a multiple of limit)")
+			return
+		}
+		offset = parsed
+	}
+
+	procedures, total, err := h.searcher.Search(r.Context(), repositories.ProcedureSearchParams{
+		Query:    strings.TrimSpace(query.Get("query")),
+		Category: strings.TrimSpace(query.Get("category")),
+		Limit:    limit,
+		Offset:   offset,
+	})
+	if err != nil {
+		respondWithAppError(w, err, "failed to search procedures")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"procedures": procedures,
+		"count":      total,
+	})
+}
+
+// Suggest handles GET /api/suggest?query=&lat=&lon=&limit=, returning typed
+// suggestions for procedures, conditions, facilities and specialties. The
+// location is optional and only narrows facility suggestions.
+func (h *ProcedureSearchHandler) Suggest(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	text := strings.TrimSpace(query.Get("query"))
+	if text == "" {
+		respondWithJSON(w, http.StatusOK, map[string]interface{}{
+			"suggestions": []entities.Suggestion{},
+			"count":       0,
+		})
+		return
+	}
+
+	var lat, lon float64
+	if query.Get("lat") != "" || query.Get("lon") != "" {
+		var err error
+		if lat, err = strconv.ParseFloat(query.Get("lat"), 64); err != nil {
+			respondWithError(w, http.StatusBadRequest, "invalid latitude parameter")
+			return
+		}
+		if lon, err = strconv.ParseFloat(query.Get("lon"), 64); err != nil {
+			respondWithError(w, http.StatusBadRequest, "invalid longitude parameter")
+			return
+		}
+	}
+
+	limit := 8
+	if limitStr := query.Get("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed <= 0 || parsed > 20 {
+			respondWithError(w, http.StatusBadRequest, "invalid limit parameter (must be 1-20)")
+			return
+		}
+		limit = parsed
+	}
+
+	suggestions, err := h.searcher.Suggest(r.Context(), text, lat, lon, limit)
+	if err != nil {
+		respondWithAppError(w, err, "failed to fetch suggestions")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"suggestions": suggestions,
+		"count":       len(suggestions),
+	})
+}
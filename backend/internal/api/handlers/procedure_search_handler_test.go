@@ -0,0 +1,89 @@
+package handlers_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/api/handlers"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/repositories"
+)
+
+type fakeProcedureSearcher struct {
+	params   repositories.ProcedureSearchParams
+	lat, lon float64
+	limit    int
+	err      error
+}
+
+func (f *fakeProcedureSearcher) Search(ctx context.Context, params repositories.ProcedureSearchParams) ([]*entities.ProcedureSearchResult, int, error) {
+	f.params = params
+	if f.err != nil {
+		return nil, 0, f.err
+	}
+	return []*entities.ProcedureSearchResult{{ID: "proc-1", Name: "full blood count", DisplayName: "Full Blood Count"}}, 31, nil
+}
+
+func (f *fakeProcedureSearcher) Suggest(ctx context.Context, query string, lat, lon float64, limit int) ([]entities.Suggestion, error) {
+	f.lat, f.lon, f.limit = lat, lon, limit
+	if f.err != nil {
+		return nil, f.err
+	}
+	return []entities.Suggestion{{
+		Type:   entities.SuggestionTypeProcedure,
+		ID:     "proc-1",
+		Label:  "Full Blood Count",
+		Action: entities.SuggestionAction{Type: entities.SuggestionActionSearchFacilities, Path: "/api/facilities/search", Params: map[string]string{"procedure_id": "proc-1"}},
+	}}, nil
+}
+
+func TestProcedureSearchHandler_SearchProcedures(t *testing.T) {
+	searcher := &fakeProcedureSearcher{}
+	handler := handlers.NewProcedureSearchHandler(searcher)
+
+	w := httptest.NewRecorder()
+	handler.SearchProcedures(w, httptest.NewRequest(http.MethodGet, "/api/procedures/search?query=+blood+count&category=laboratory&limit=10&offset=20", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"procedures":[{"id":"proc-1","name":"full blood count","display_name":"Full Blood Count"}],"count":31}`, w.Body.String())
+	assert.Equal(t, repositories.ProcedureSearchParams{Query: "blood count", Category: "laboratory", Limit: 10, Offset: 20}, searcher.params)
+
+	w = httptest.NewRecorder()
+	handler.SearchProcedures(w, httptest.NewRequest(http.MethodGet, "/api/procedures/search?limit=10&offset=5", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	searcher.err = errors.New("typesense unavailable")
+	w = httptest.NewRecorder()
+	handler.SearchProcedures(w, httptest.NewRequest(http.MethodGet, "/api/procedures/search?query=ct", nil))
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestProcedureSearchHandler_Suggest(t *testing.T) {
+	searcher := &fakeProcedureSearcher{}
+	handler := handlers.NewProcedureSearchHandler(searcher)
+
+	w := httptest.NewRecorder()
+	handler.Suggest(w, httptest.NewRequest(http.MethodGet, "/api/suggest?query=full+bl", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"suggestions":[{"type":"procedure","id":"proc-1","label":"Full Blood Count","action":{"type":"search_facilities","path":"/api/facilities/search","params":{"procedure_id":"proc-1"}}}],"count":1}`, w.Body.String())
+	assert.Equal(t, 8, searcher.limit)
+	assert.Zero(t, searcher.lat)
+
+	w = httptest.NewRecorder()
+	handler.Suggest(w, httptest.NewRequest(http.MethodGet, "/api/suggest?query=ct&lat=6.5&lon=3.4&limit=5", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 6.5, searcher.lat)
+	assert.Equal(t, 5, searcher.limit)
+
+	w = httptest.NewRecorder()
+	handler.Suggest(w, httptest.NewRequest(http.MethodGet, "/api/suggest?query=ct&lat=6.5", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	handler.Suggest(w, httptest.NewRequest(http.MethodGet, "/api/suggest?query=", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"suggestions":[],"count":0}`, w.Body.String())
+}
@@ -19,6 +19,7 @@ type ProviderIngestionHandler struct {
 	service        *services.ProviderIngestionService
 	redisClient    *redislib.Client
 	idempotencyTTL time.Duration
+	audit          AuditRecorder
 }
 
 func NewProviderIngestionHandler(
@@ -36,6 +37,11 @@ func NewProviderIngestionHandler(
 	}
 }
 
+// SetAuditLog records triggered ingestion runs in the audit log
+func (h *ProviderIngestionHandler) SetAuditLog(audit AuditRecorder) {
+	h.audit = audit
+}
+
 func (h *ProviderIngestionHandler) TriggerIngestion(w http.ResponseWriter, r *http.Request) {
 	if h.service == nil {
 		respondWithError(w, http.StatusServiceUnavailable, "provider ingestion service not configured")
@@ -52,6 +58,13 @@ func (h *ProviderIngestionHandler) TriggerIngestion(w http.ResponseWriter, r *ht
 
 	providerID := strings.TrimSpace(r.URL.Query().Get("providerId"))
 	summary, err := h.service.SyncCurrentData(r.Context(), providerID)
+	if h.audit != nil {
+		var after interface{} = summary
+		if err != nil {
+			after = map[string]string{"error": err.Error()}
+		}
+		h.audit.Record(r.Context(), "ingestion.trigger", "provider", providerID, "", nil, after)
+	}
 	if err != nil {
 		respondWithError(w, http.StatusBadGateway, err.Error())
 		return
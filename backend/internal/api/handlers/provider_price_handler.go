@@ -12,12 +12,18 @@ import (
 // ProviderPriceHandler proxies provider API data through the core REST API.
 type ProviderPriceHandler struct {
 	client providerapi.Client
+	audit  AuditRecorder
 }
 
 func NewProviderPriceHandler(client providerapi.Client) *ProviderPriceHandler {
 	return &ProviderPriceHandler{client: client}
 }
 
+// SetAuditLog records triggered syncs in the audit log
+func (h *ProviderPriceHandler) SetAuditLog(audit AuditRecorder) {
+	h.audit = audit
+}
+
 func (h *ProviderPriceHandler) ensureClient(w http.ResponseWriter) bool {
 	if h.client == nil {
 		respondWithError(w, http.StatusServiceUnavailable, "provider api client not configured")
@@ -130,6 +136,9 @@ func (h *ProviderPriceHandler) TriggerSync(w http.ResponseWriter, r *http.Reques
 		respondWithError(w, http.StatusBadGateway, "failed to trigger provider sync")
 		return
 	}
+	if h.audit != nil {
+		h.audit.Record(r.Context(), "provider_sync.trigger", "provider", providerID, "", nil, resp)
+	}
 	respondWithJSON(w, http.StatusOK, resp)
 }
 
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	apperrors "github.com/zatekoja/Patientpricediscoverydesign/backend/pkg/errors"
+)
+
+func respondWithJSON(w http.ResponseWriter, statusCode int, payload interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	if err := json.NewEncoder(w).Encode(payload); err != nil {
+		log.Printf("failed to encode JSON response: %v", err)
+	}
+}
+
+func respondWithError(w http.ResponseWriter, statusCode int, message string) {
+	respondWithJSON(w, statusCode, map[string]string{
+		"error": message,
+	})
+}
+
+// respondWithAppError writes the status for a service error's AppError type
+// with its message, and a 500 with fallback for anything unclassified so
+// internal detail never reaches the client.
+func respondWithAppError(w http.ResponseWriter, err error, fallback string) {
+	var appErr *apperrors.AppError
+	if errors.As(err, &appErr) {
+		switch appErr.Type {
+		case apperrors.ErrorTypeValidation:
+			respondWithError(w, http.StatusBadRequest, appErr.Message)
+			return
+		case apperrors.ErrorTypeUnauthorized:
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			respondWithError(w, http.StatusUnauthorized, appErr.Message)
+			return
+		case apperrors.ErrorTypeNotFound:
+			respondWithError(w, http.StatusNotFound, appErr.Message)
+			return
+		case apperrors.ErrorTypeConflict:
+			respondWithError(w, http.StatusConflict, appErr.Message)
+			return
+		case apperrors.ErrorTypeRateLimited:
+			respondWithError(w, http.StatusTooManyRequests, appErr.Message)
+			return
+		case apperrors.ErrorTypeExternal:
+			respondWithError(w, http.StatusBadGateway, appErr.Message)
+			return
+		}
+	}
+	respondWithError(w, http.StatusInternalServerError, fallback)
+}
@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/application/services"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+)
+
+// ReviewService defines the review operations used by the handler.
+type ReviewService interface {
+	Submit(ctx context.Context, input services.SubmitReviewInput) (*entities.Review, error)
+	ListFacilityReviews(ctx context.Context, facilityID string, limit, offset int) ([]*entities.Review, int, error)
+	ModerationQueue(ctx context.Context, status entities.ReviewStatus, limit, offset int) ([]*entities.Review, error)
+	Moderate(ctx context.Context, reviewID string, status entities.ReviewStatus, note string) (*entities.Review, error)
+	Respond(ctx context.Context, reviewID, response string) (*entities.Review, error)
+}
+
+// ReviewHandler serves patient reviews and the moderation queue.
+type ReviewHandler struct {
+	service ReviewService
+}
+
+// NewReviewHandler creates a new review handler.
+func NewReviewHandler(service ReviewService) *ReviewHandler {
+	return &ReviewHandler{service: service}
+}
+
+// publicReview is the patient-facing view of a published review.
+type publicReview struct {
+	ID               string     `json:"id"`
+	ReviewerName     string     `json:"reviewer_name"`
+	Rating           int        `json:"rating"`
+	Comment          string     `json:"comment,omitempty"`
+	FacilityResponse string     `json:"facility_response,omitempty"`
+	RespondedAt      *time.Time `json:"responded_at,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+}
+
+func toPublicReview(review *entities.Review) publicReview {
+	return publicReview{
+		ID:               review.ID,
+		ReviewerName:     review.ReviewerName,
+		Rating:           review.Rating,
+		Comment:          review.Comment,
+		FacilityResponse: review.FacilityResponse,
+		RespondedAt:      review.RespondedAt,
+		CreatedAt:        review.CreatedAt,
+	}
+}
+
+// SubmitReview handles POST /api/reviews
+func (h *ReviewHandler) SubmitReview(w http.ResponseWriter, r *http.Request) {
+	var input services.SubmitReviewInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	review, err := h.service.Submit(r.Context(), input)
+	if err != nil {
+		respondWithAppError(w, err, "failed to submit review")
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, map[string]interface{}{
+		"id":     review.ID,
+		"status": review.Status,
+	})
+}
+
+// ListFacilityReviews handles GET /api/facilities/{id}/reviews
+func (h *ReviewHandler) ListFacilityReviews(w http.ResponseWriter, r *http.Request) {
+	limit := parseIntDefault(r.URL.Query().Get("limit"), 10)
+	offset := parseIntDefault(r.URL.Query().Get("offset"), 0)
+
+	reviews, total, err := h.service.ListFacilityReviews(r.Context(), r.PathValue("id"), limit, offset)
+	if err != nil {
+		respondWithAppError(w, err, "failed to list reviews")
+		return
+	}
+
+	items := make([]publicReview, 0, len(reviews))
+	for _, review := range reviews {
+		items = append(items, toPublicReview(review))
+	}
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"reviews":     items,
+		"total_count": total,
+		"limit":       limit,
+		"offset":      offset,
+	})
+}
+
+// ListModerationQueue handles GET /api/admin/reviews?status=pending
+func (h *ReviewHandler) ListModerationQueue(w http.ResponseWriter, r *http.Request) {
+	status := entities.ReviewStatus(strings.TrimSpace(r.URL.Query().Get("status")))
+	limit := parseIntDefault(r.URL.Query().Get("limit"), 50)
+	offset := parseIntDefault(r.URL.Query().Get("offset"), 0)
+
+	reviews, err := h.service.ModerationQueue(r.Context(), status, limit, offset)
+	if err != nil {
+		respondWithAppError(w, err, "failed to list reviews")
+		return
+	}
+	if reviews == nil {
+		reviews = []*entities.Review{}
+	}
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{"reviews": reviews})
+}
+
+// ModerateReview handles POST /api/admin/reviews/{id}/moderate
+func (h *ReviewHandler) ModerateReview(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Status string `json:"status"`
+		Note   string `json:"note"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	review, err := h.service.Moderate(r.Context(), r.PathValue("id"), entities.ReviewStatus(strings.TrimSpace(req.Status)), req.Note)
+	if err != nil {
+		respondWithAppError(w, err, "failed to moderate review")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, review)
+}
+
+// RespondToReview handles PUT /api/admin/reviews/{id}/response
+func (h *ReviewHandler) RespondToReview(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Response string `json:"response"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	review, err := h.service.Respond(r.Context(), r.PathValue("id"), req.Response)
+	if err != nil {
+		respondWithAppError(w, err, "failed to save response")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, review)
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+)
+
+// SearchExperimentService defines the experiment operations used by the handler.
+type SearchExperimentService interface {
+	Create(ctx context.Context, experiment *entities.SearchExperiment) error
+	Get(ctx context.Context, id string) (*entities.SearchExperiment, error)
+	List(ctx context.Context) ([]*entities.SearchExperiment, error)
+	Start(ctx context.Context, id string) (*entities.SearchExperiment, error)
+	Stop(ctx context.Context, id string) (*entities.SearchExperiment, error)
+	Report(ctx context.Context, id string) (*entities.ExperimentReport, error)
+}
+
+// SearchExperimentHandler exposes search A/B experiments to admins.
+type SearchExperimentHandler struct {
+	service SearchExperimentService
+}
+
+// NewSearchExperimentHandler creates a new search experiment handler.
+func NewSearchExperimentHandler(service SearchExperimentService) *SearchExperimentHandler {
+	return &SearchExperimentHandler{service: service}
+}
+
+// ListExperiments handles GET /api/admin/experiments
+func (h *SearchExperimentHandler) ListExperiments(w http.ResponseWriter, r *http.Request) {
+	experiments, err := h.service.List(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "failed to list experiments")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"experiments": experiments,
+		"count":       len(experiments),
+	})
+}
+
+// CreateExperiment handles POST /api/admin/experiments
+func (h *SearchExperimentHandler) CreateExperiment(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Name           string                       `json:"name"`
+		Description    string                       `json:"description"`
+		TrafficPercent int                          `json:"traffic_percent"`
+		Variants       []entities.ExperimentVariant `json:"variants"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	experiment := &entities.SearchExperiment{
+		Name:           req.Name,
+		Description:    req.Description,
+		TrafficPercent: req.TrafficPercent,
+		Variants:       req.Variants,
+	}
+	if err := h.service.Create(r.Context(), experiment); err != nil {
+		respondWithAppError(w, err, "failed to create experiment")
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, experiment)
+}
+
+// GetExperiment handles GET /api/admin/experiments/{id}
+func (h *SearchExperimentHandler) GetExperiment(w http.ResponseWriter, r *http.Request) {
+	experiment, err := h.service.Get(r.Context(), r.PathValue("id"))
+	if err != nil {
+		respondWithAppError(w, err, "failed to get experiment")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, experiment)
+}
+
+// StartExperiment handles POST /api/admin/experiments/{id}/start
+func (h *SearchExperimentHandler) StartExperiment(w http.ResponseWriter, r *http.Request) {
+	experiment, err := h.service.Start(r.Context(), r.PathValue("id"))
+	if err != nil {
+		respondWithAppError(w, err, "failed to start experiment")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, experiment)
+}
+
+// StopExperiment handles POST /api/admin/experiments/{id}/stop
+func (h *SearchExperimentHandler) StopExperiment(w http.ResponseWriter, r *http.Request) {
+	experiment, err := h.service.Stop(r.Context(), r.PathValue("id"))
+	if err != nil {
+		respondWithAppError(w, err, "failed to stop experiment")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, experiment)
+}
+
+// GetExperimentReport handles GET /api/admin/experiments/{id}/report
+func (h *SearchExperimentHandler) GetExperimentReport(w http.ResponseWriter, r *http.Request) {
+	report, err := h.service.Report(r.Context(), r.PathValue("id"))
+	if err != nil {
+		respondWithAppError(w, err, "failed to build experiment report")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, report)
+}
@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+)
+
+// SearchInteractionService defines the interaction tracking operations used by the handler.
+type SearchInteractionService interface {
+	Record(ctx context.Context, interaction *entities.SearchInteraction) error
+	QueryReport(ctx context.Context, filter entities.SearchInteractionReportFilter) ([]*entities.QueryEngagementStats, error)
+	FacilityReport(ctx context.Context, filter entities.SearchInteractionReportFilter) ([]*entities.FacilityEngagementStats, error)
+	PositionReport(ctx context.Context, filter entities.SearchInteractionReportFilter) ([]*entities.PositionEngagementStats, error)
+}
+
+// SearchInteractionHandler records post-search interactions and serves engagement reports.
+type SearchInteractionHandler struct {
+	service SearchInteractionService
+}
+
+// NewSearchInteractionHandler creates a new search interaction handler.
+func NewSearchInteractionHandler(service SearchInteractionService) *SearchInteractionHandler {
+	return &SearchInteractionHandler{service: service}
+}
+
+// RecordEvent handles POST /api/analytics/events
+func (h *SearchInteractionHandler) RecordEvent(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ImpressionID string `json:"impression_id"`
+		EventType    string `json:"event_type"`
+		FacilityID   string `json:"facility_id"`
+		Position     *int   `json:"position"`
+		SessionID    string `json:"session_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	sessionID := req.SessionID
+	if sessionID == "" {
+		sessionID = sessionIDFromRequest(r)
+	}
+
+	interaction := &entities.SearchInteraction{
+		ImpressionID: req.ImpressionID,
+		EventType:    entities.SearchInteractionType(strings.TrimSpace(req.EventType)),
+		FacilityID:   req.FacilityID,
+		Position:     req.Position,
+		SessionID:    sessionID,
+	}
+	if err := h.service.Record(r.Context(), interaction); err != nil {
+		respondWithAppError(w, err, "failed to record event")
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, interaction)
+}
+
+// GetQueryReport handles GET /api/analytics/reports/queries
+func (h *SearchInteractionHandler) GetQueryReport(w http.ResponseWriter, r *http.Request) {
+	filter, ok := parseEngagementFilter(w, r)
+	if !ok {
+		return
+	}
+	stats, err := h.service.QueryReport(r.Context(), filter)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "failed to build query report")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"queries": stats,
+		"count":   len(stats),
+	})
+}
+
+// GetFacilityReport handles GET /api/analytics/reports/facilities
+func (h *SearchInteractionHandler) GetFacilityReport(w http.ResponseWriter, r *http.Request) {
+	filter, ok := parseEngagementFilter(w, r)
+	if !ok {
+		return
+	}
+	stats, err := h.service.FacilityReport(r.Context(), filter)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "failed to build facility report")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"facilities": stats,
+		"count":      len(stats),
+	})
+}
+
+// GetPositionReport handles GET /api/analytics/reports/positions
+func (h *SearchInteractionHandler) GetPositionReport(w http.ResponseWriter, r *http.Request) {
+	filter, ok := parseEngagementFilter(w, r)
+	if !ok {
+		return
+	}
+	stats, err := h.service.PositionReport(r.Context(), filter)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "failed to build position report")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"positions": stats,
+		"count":     len(stats),
+	})
+}
+
+// parseEngagementFilter reads the optional since/until (RFC 3339) and limit
+// query parameters. It writes a 400 response and returns false on bad input.
+func parseEngagementFilter(w http.ResponseWriter, r *http.Request) (entities.SearchInteractionReportFilter, bool) {
+	query := r.URL.Query()
+	filter := entities.SearchInteractionReportFilter{
+		Limit: parseIntDefault(query.Get("limit"), 0),
+	}
+	for name, dst := range map[string]*time.Time{"since": &filter.Since, "until": &filter.Until} {
+		value := strings.TrimSpace(query.Get(name))
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "invalid "+name+" parameter (expected RFC 3339)")
+			return filter, false
+		}
+		*dst = t
+	}
+	if !filter.Since.IsZero() && !filter.Until.IsZero() && !filter.Since.Before(filter.Until) {
+		respondWithError(w, http.StatusBadRequest, "since must be before until")
+		return filter, false
+	}
+	return filter, true
+}
@@ -0,0 +1,83 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/api/handlers"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	apperrors "github.com/zatekoja/Patientpricediscoverydesign/backend/pkg/errors"
+)
+
+type fakeInteractionService struct {
+	recorded   *entities.SearchInteraction
+	recordErr  error
+	lastFilter entities.SearchInteractionReportFilter
+}
+
+func (f *fakeInteractionService) Record(ctx context.Context, interaction *entities.SearchInteraction) error {
+	f.recorded = interaction
+	return f.recordErr
+}
+
+func (f *fakeInteractionService) QueryReport(ctx context.Context, filter entities.SearchInteractionReportFilter) ([]*entities.QueryEngagementStats, error) {
+	f.lastFilter = filter
+	return []*entities.QueryEngagementStats{{Query: "malaria", Searches: 3}}, nil
+}
+
+func (f *fakeInteractionService) FacilityReport(ctx context.Context, filter entities.SearchInteractionReportFilter) ([]*entities.FacilityEngagementStats, error) {
+	f.lastFilter = filter
+	return nil, nil
+}
+
+func (f *fakeInteractionService) PositionReport(ctx context.Context, filter entities.SearchInteractionReportFilter) ([]*entities.PositionEngagementStats, error) {
+	f.lastFilter = filter
+	return nil, nil
+}
+
+func TestSearchInteractionHandler_RecordEvent(t *testing.T) {
+	svc := &fakeInteractionService{}
+	handler := handlers.NewSearchInteractionHandler(svc)
+
+	body := `{"impression_id":"6f1c2a8e-3b1d-4f5e-9a7c-2d8e4b6f1a3c","event_type":"result_click","facility_id":"fac-1","position":2}`
+	req := httptest.NewRequest("POST", "/api/analytics/events", strings.NewReader(body))
+	req.Header.Set("X-Session-ID", "sess-1")
+	w := httptest.NewRecorder()
+	handler.RecordEvent(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	require.NotNil(t, svc.recorded)
+	assert.Equal(t, entities.SearchInteractionResultClick, svc.recorded.EventType)
+	assert.Equal(t, 2, *svc.recorded.Position)
+	assert.Equal(t, "sess-1", svc.recorded.SessionID)
+
+	svc.recordErr = apperrors.NewValidationError("facility_id is required")
+	w = httptest.NewRecorder()
+	handler.RecordEvent(w, httptest.NewRequest("POST", "/api/analytics/events", strings.NewReader(`{"event_type":"booking"}`)))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestSearchInteractionHandler_ReportFilter(t *testing.T) {
+	svc := &fakeInteractionService{}
+	handler := handlers.NewSearchInteractionHandler(svc)
+
+	w := httptest.NewRecorder()
+	handler.GetQueryReport(w, httptest.NewRequest("GET", "/api/analytics/reports/queries?since=2026-04-01T00:00:00Z&limit=20", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 20, svc.lastFilter.Limit)
+	assert.Equal(t, 2026, svc.lastFilter.Since.Year())
+	assert.True(t, svc.lastFilter.Until.IsZero())
+
+	w = httptest.NewRecorder()
+	handler.GetPositionReport(w, httptest.NewRequest("GET", "/api/analytics/reports/positions?since=yesterday", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	handler.GetFacilityReport(w, httptest.NewRequest("GET", "/api/analytics/reports/facilities?since=2026-05-01T00:00:00Z&until=2026-04-01T00:00:00Z", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
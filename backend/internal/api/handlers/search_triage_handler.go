@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/application/services"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+)
+
+// SearchTriageService defines the zero-result triage operations used by the handler.
+type SearchTriageService interface {
+	GetClusters(ctx context.Context, opts services.SearchTriageOptions) ([]*entities.ZeroResultQueryCluster, error)
+	AcceptFix(ctx context.Context, fix *entities.SearchDictionaryFix) error
+	ListFixes(ctx context.Context, status entities.DictionaryFixStatus) ([]*entities.SearchDictionaryFix, error)
+}
+
+// SearchTriageHandler exposes the zero-result query triage workflow to admins.
+type SearchTriageHandler struct {
+	service SearchTriageService
+}
+
+// NewSearchTriageHandler creates a new search triage handler.
+func NewSearchTriageHandler(service SearchTriageService) *SearchTriageHandler {
+	return &SearchTriageHandler{service: service}
+}
+
+// ListClusters handles GET /api/admin/search-triage/clusters
+func (h *SearchTriageHandler) ListClusters(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	opts := services.SearchTriageOptions{
+		WindowDays:     parseIntDefault(query.Get("days"), 14),
+		MaxResultCount: parseIntDefault(query.Get("max_results"), 0),
+		MinOccurrences: parseIntDefault(query.Get("min_occurrences"), 1),
+		Limit:          parseIntDefault(query.Get("limit"), 50),
+	}
+	if opts.WindowDays > 90 {
+		opts.WindowDays = 90
+	}
+
+	clusters, err := h.service.GetClusters(r.Context(), opts)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "failed to build triage clusters")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"clusters": clusters,
+		"count":    len(clusters),
+	})
+}
+
+// AcceptFix handles POST /api/admin/search-triage/fixes
+func (h *SearchTriageHandler) AcceptFix(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		NormalizedQuery string `json:"normalized_query"`
+		FixType         string `json:"fix_type"`
+		Term            string `json:"term"`
+		Target          string `json:"target"`
+		AcceptedBy      string `json:"accepted_by"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	fix := &entities.SearchDictionaryFix{
+		NormalizedQuery: req.NormalizedQuery,
+		FixType:         entities.DictionaryFixType(req.FixType),
+		Term:            req.Term,
+		Target:          req.Target,
+		AcceptedBy:      req.AcceptedBy,
+	}
+	if err := h.service.AcceptFix(r.Context(), fix); err != nil {
+		respondWithAppError(w, err, "failed to accept fix")
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, fix)
+}
+
+// ListFixes handles GET /api/admin/search-triage/fixes
+func (h *SearchTriageHandler) ListFixes(w http.ResponseWriter, r *http.Request) {
+	status := entities.DictionaryFixStatus(r.URL.Query().Get("status"))
+	switch status {
+	case "", entities.DictionaryFixStatusPending, entities.DictionaryFixStatusResolved, entities.DictionaryFixStatusUnresolved:
+	default:
+		respondWithError(w, http.StatusBadRequest, "status must be 'pending', 'resolved' or 'unresolved'")
+		return
+	}
+
+	fixes, err := h.service.ListFixes(r.Context(), status)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "failed to list fixes")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"fixes": fixes,
+		"count": len(fixes),
+	})
+}
@@ -15,6 +15,10 @@ import (
 	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/providers"
 )
 
+// sseEventDedupeSize is how many recent event IDs each stream remembers to drop
+// events redelivered by the outbox relay
+const sseEventDedupeSize = 256
+
 // SSEHandler handles Server-Sent Events for real-time facility updates
 type SSEHandler struct {
 	eventBus providers.EventBus
@@ -196,6 +200,7 @@ func (h *SSEHandler) StreamRegionalUpdates(w http.ResponseWriter, r *http.Reques
 
 // forwardEvents forwards events from the event bus to a client channel
 func (h *SSEHandler) forwardEvents(ctx context.Context, eventChan <-chan *entities.FacilityEvent, clientChan chan<- *entities.FacilityEvent) {
+	deduper := entities.NewFacilityEventDeduper(sseEventDedupeSize)
 	for {
 		select {
 		case <-ctx.Done():
@@ -204,6 +209,10 @@ func (h *SSEHandler) forwardEvents(ctx context.Context, eventChan <-chan *entiti
 			if !ok {
 				return
 			}
+			if deduper.Seen(event) {
+				// Redelivered by the outbox relay
+				continue
+			}
 			select {
 			case clientChan <- event:
 			default:
@@ -215,6 +224,7 @@ func (h *SSEHandler) forwardEvents(ctx context.Context, eventChan <-chan *entiti
 
 // forwardRegionalEvents forwards events within a specific region
 func (h *SSEHandler) forwardRegionalEvents(ctx context.Context, eventChan <-chan *entities.FacilityEvent, clientChan chan<- *entities.FacilityEvent, lat, lon, radiusKm float64) {
+	deduper := entities.NewFacilityEventDeduper(sseEventDedupeSize)
 	for {
 		select {
 		case <-ctx.Done():
@@ -223,6 +233,9 @@ func (h *SSEHandler) forwardRegionalEvents(ctx context.Context, eventChan <-chan
 			if !ok {
 				return
 			}
+			if deduper.Seen(event) {
+				continue
+			}
 			// Check if event is within region
 			distance := haversineDistance(lat, lon, event.Location.Latitude, event.Location.Longitude)
 			if distance <= radiusKm {
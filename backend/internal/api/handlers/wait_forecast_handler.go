@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+)
+
+// WaitForecaster defines the wait forecast used by the handler
+type WaitForecaster interface {
+	Forecast(ctx context.Context, facilityID, wardName string, at time.Time) (*entities.WaitForecast, error)
+}
+
+// WaitForecastHandler serves expected waits learned from capacity history
+type WaitForecastHandler struct {
+	forecaster WaitForecaster
+}
+
+// NewWaitForecastHandler creates a new wait forecast handler
+func NewWaitForecastHandler(forecaster WaitForecaster) *WaitForecastHandler {
+	return &WaitForecastHandler{forecaster: forecaster}
+}
+
+// GetWaitForecast handles GET /api/facilities/{id}/wait-forecast?at=&ward=
+// where at is the arrival time (RFC 3339, default now)
+func (h *WaitForecastHandler) GetWaitForecast(w http.ResponseWriter, r *http.Request) {
+	facilityID := r.PathValue("id")
+	if facilityID == "" {
+		respondWithError(w, http.StatusBadRequest, "facility ID is required")
+		return
+	}
+
+	query := r.URL.Query()
+	at := time.Now()
+	if value := strings.TrimSpace(query.Get("at")); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "invalid at parameter (expected RFC 3339)")
+			return
+		}
+		at = parsed
+	}
+
+	forecast, err := h.forecaster.Forecast(r.Context(), facilityID, strings.TrimSpace(query.Get("ward")), at)
+	if err != nil {
+		respondWithAppError(w, err, "failed to forecast wait time")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, forecast)
+}
@@ -0,0 +1,55 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/api/handlers"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	apperrors "github.com/zatekoja/Patientpricediscoverydesign/backend/pkg/errors"
+)
+
+type fakeWaitForecaster struct {
+	facilityID string
+	wardName   string
+	at         time.Time
+}
+
+func (f *fakeWaitForecaster) Forecast(ctx context.Context, facilityID, wardName string, at time.Time) (*entities.WaitForecast, error) {
+	if facilityID == "missing" {
+		return nil, apperrors.NewNotFoundError("facility not found")
+	}
+	f.facilityID, f.wardName, f.at = facilityID, wardName, at
+	minutes := 35
+	return &entities.WaitForecast{FacilityID: facilityID, WardName: wardName, At: at, ExpectedWaitMinutes: &minutes, Basis: entities.ForecastBasisSeasonal, Samples: 12}, nil
+}
+
+func TestWaitForecastHandler_GetWaitForecast(t *testing.T) {
+	forecaster := &fakeWaitForecaster{}
+	handler := handlers.NewWaitForecastHandler(forecaster)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/facilities/fac-1/wait-forecast?at=2026-03-02T18:30:00%2B01:00&ward=emergency", nil)
+	req.SetPathValue("id", "fac-1")
+	w := httptest.NewRecorder()
+	handler.GetWaitForecast(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"facility_id":"fac-1","ward_name":"emergency","at":"2026-03-02T18:30:00+01:00","expected_wait_minutes":35,"basis":"seasonal","samples":12}`, w.Body.String())
+	assert.Equal(t, "emergency", forecaster.wardName)
+	assert.True(t, forecaster.at.Equal(time.Date(2026, 3, 2, 17, 30, 0, 0, time.UTC)))
+
+	req = httptest.NewRequest(http.MethodGet, "/api/facilities/fac-1/wait-forecast?at=monday", nil)
+	req.SetPathValue("id", "fac-1")
+	w = httptest.NewRecorder()
+	handler.GetWaitForecast(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/facilities/missing/wait-forecast", nil)
+	req.SetPathValue("id", "missing")
+	w = httptest.NewRecorder()
+	handler.GetWaitForecast(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
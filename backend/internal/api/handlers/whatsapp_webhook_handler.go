@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/application/services"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	apperrors "github.com/zatekoja/Patientpricediscoverydesign/backend/pkg/errors"
+)
+
+// maxWhatsAppWebhookBody bounds the callback payload read into memory
+const maxWhatsAppWebhookBody = 1 << 20
+
+// DeliveryStatusRecorder records provider delivery callbacks on sent notifications
+type DeliveryStatusRecorder interface {
+	ApplyDeliveryStatus(ctx context.Context, update services.DeliveryStatusUpdate) error
+}
+
+// WhatsAppWebhookHandler handles WhatsApp Cloud API webhooks
+type WhatsAppWebhookHandler struct {
+	recorder    DeliveryStatusRecorder
+	appSecret   string
+	verifyToken string
+}
+
+// NewWhatsAppWebhookHandler creates a new webhook handler. Callbacks are
+// verified with the Meta app secret; the verify token answers the subscription
+// handshake.
+func NewWhatsAppWebhookHandler(recorder DeliveryStatusRecorder, appSecret, verifyToken string) *WhatsAppWebhookHandler {
+	return &WhatsAppWebhookHandler{
+		recorder:    recorder,
+		appSecret:   appSecret,
+		verifyToken: verifyToken,
+	}
+}
+
+// WhatsAppWebhookPayload is the envelope Meta posts for WhatsApp Business accounts
+type WhatsAppWebhookPayload struct {
+	Object string `json:"object"`
+	Entry  []struct {
+		ID      string `json:"id"`
+		Changes []struct {
+			Field string `json:"field"`
+			Value struct {
+				Statuses []WhatsAppStatusCallback `json:"statuses"`
+			} `json:"value"`
+		} `json:"changes"`
+	} `json:"entry"`
+}
+
+// WhatsAppStatusCallback reports the delivery state of one sent message
+type WhatsAppStatusCallback struct {
+	ID          string `json:"id"`
+	Status      string `json:"status"`
+	Timestamp   string `json:"timestamp"`
+	RecipientID string `json:"recipient_id"`
+	Errors      []struct {
+		Code    int    `json:"code"`
+		Title   string `json:"title"`
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// VerifyWebhook handles GET /webhooks/whatsapp, Meta's subscription handshake
+func (h *WhatsAppWebhookHandler) VerifyWebhook(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	token := query.Get("hub.verify_token")
+	if query.Get("hub.mode") != "subscribe" || h.verifyToken == "" ||
+		!hmac.Equal([]byte(token), []byte(h.verifyToken)) {
+		http.Error(w, "Verification failed", http.StatusForbidden)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(query.Get("hub.challenge")))
+}
+
+// HandleWebhook handles POST /webhooks/whatsapp status callbacks
+func (h *WhatsAppWebhookHandler) HandleWebhook(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxWhatsAppWebhookBody))
+	if err != nil {
+		http.Error(w, "Failed to read body", http.StatusBadRequest)
+		return
+	}
+
+	if !h.verifySignature(r.Header.Get("X-Hub-Signature-256"), body) {
+		http.Error(w, "Invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	var payload WhatsAppWebhookPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	applied := 0
+	for _, entry := range payload.Entry {
+		for _, change := range entry.Changes {
+			for _, callback := range change.Value.Statuses {
+				update, ok := toDeliveryStatusUpdate(callback)
+				if !ok {
+					continue
+				}
+				err := h.recorder.ApplyDeliveryStatus(r.Context(), update)
+				var appErr *apperrors.AppError
+				if errors.As(err, &appErr) && appErr.Type == apperrors.ErrorTypeNotFound {
+					// Messages sent outside the notification queue, such as login codes
+					continue
+				}
+				if err != nil {
+					// A non-2xx response makes Meta redeliver the callback
+					fmt.Printf("Failed to apply WhatsApp status for %s: %v\n", callback.ID, err)
+					http.Error(w, "Processing error", http.StatusInternalServerError)
+					return
+				}
+				applied++
+			}
+		}
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{"status": "processed", "applied": applied})
+}
+
+// verifySignature checks the "sha256=<hex>" HMAC of the raw body
+func (h *WhatsAppWebhookHandler) verifySignature(header string, body []byte) bool {
+	if h.appSecret == "" {
+		return false
+	}
+	signature, ok := strings.CutPrefix(header, "sha256=")
+	if !ok {
+		return false
+	}
+	expected, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(h.appSecret))
+	mac.Write(body)
+	return hmac.Equal(expected, mac.Sum(nil))
+}
+
+func toDeliveryStatusUpdate(callback WhatsAppStatusCallback) (services.DeliveryStatusUpdate, bool) {
+	var status entities.NotificationStatus
+	switch callback.Status {
+	case "sent":
+		status = entities.NotificationStatusSent
+	case "delivered":
+		status = entities.NotificationStatusDelivered
+	case "read":
+		status = entities.NotificationStatusRead
+	case "failed":
+		status = entities.NotificationStatusFailed
+	default:
+		return services.DeliveryStatusUpdate{}, false
+	}
+	if callback.ID == "" {
+		return services.DeliveryStatusUpdate{}, false
+	}
+
+	update := services.DeliveryStatusUpdate{MessageID: callback.ID, Status: status}
+	if seconds, err := strconv.ParseInt(callback.Timestamp, 10, 64); err == nil {
+		update.Timestamp = time.Unix(seconds, 0).UTC()
+	}
+	if len(callback.Errors) > 0 {
+		callbackErr := callback.Errors[0]
+		detail := callbackErr.Message
+		if detail == "" {
+			detail = callbackErr.Title
+		}
+		update.Error = fmt.Sprintf("WhatsApp error %d: %s", callbackErr.Code, detail)
+	}
+	return update, true
+}
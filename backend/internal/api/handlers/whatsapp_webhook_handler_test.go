@@ -0,0 +1,124 @@
+package handlers_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/api/handlers"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/application/services"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	apperrors "github.com/zatekoja/Patientpricediscoverydesign/backend/pkg/errors"
+)
+
+type fakeDeliveryStatusRecorder struct {
+	updates []services.DeliveryStatusUpdate
+	errs    map[string]error
+}
+
+func (f *fakeDeliveryStatusRecorder) ApplyDeliveryStatus(ctx context.Context, update services.DeliveryStatusUpdate) error {
+	f.updates = append(f.updates, update)
+	return f.errs[update.MessageID]
+}
+
+const whatsappStatusPayload = `{
+  "object": "whatsapp_business_account",
+  "entry": [{
+    "id": "1234",
+    "changes": [{
+      "field": "messages",
+      "value": {
+        "statuses": [
+          {"id": "wamid.delivered", "status": "delivered", "timestamp": "1772442000", "recipient_id": "2348031234567"},
+          {"id": "wamid.otp", "status": "read", "timestamp": "1772442060", "recipient_id": "2348031234567"},
+          {"id": "wamid.failed", "status": "failed", "timestamp": "1772442120", "recipient_id": "2348031234567",
+           "errors": [{"code": 131026, "title": "Message undeliverable", "message": "Message undeliverable"}]},
+          {"id": "wamid.deleted", "status": "deleted", "timestamp": "1772442180"}
+        ]
+      }
+    }]
+  }]
+}`
+
+func signWhatsApp(secret, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestWhatsAppWebhookHandler_RejectsBadSignature(t *testing.T) {
+	recorder := &fakeDeliveryStatusRecorder{}
+	handler := handlers.NewWhatsAppWebhookHandler(recorder, "app-secret", "verify")
+
+	for name, signature := range map[string]string{
+		"missing":    "",
+		"wrong key":  signWhatsApp("other-secret", whatsappStatusPayload),
+		"not hex":    "sha256=zz",
+		"bad prefix": strings.TrimPrefix(signWhatsApp("app-secret", whatsappStatusPayload), "sha256="),
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/webhooks/whatsapp", strings.NewReader(whatsappStatusPayload))
+		if signature != "" {
+			req.Header.Set("X-Hub-Signature-256", signature)
+		}
+		w := httptest.NewRecorder()
+		handler.HandleWebhook(w, req)
+		assert.Equal(t, http.StatusUnauthorized, w.Code, name)
+	}
+	assert.Empty(t, recorder.updates)
+}
+
+func TestWhatsAppWebhookHandler_AppliesStatuses(t *testing.T) {
+	recorder := &fakeDeliveryStatusRecorder{errs: map[string]error{
+		"wamid.otp": apperrors.NewNotFoundError("no notification was sent with this message id"),
+	}}
+	handler := handlers.NewWhatsAppWebhookHandler(recorder, "app-secret", "verify")
+
+	req := httptest.NewRequest(http.MethodPost, "/webhooks/whatsapp", strings.NewReader(whatsappStatusPayload))
+	req.Header.Set("X-Hub-Signature-256", signWhatsApp("app-secret", whatsappStatusPayload))
+	w := httptest.NewRecorder()
+	handler.HandleWebhook(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"applied":2`)
+	require.Len(t, recorder.updates, 3, "unknown statuses are skipped")
+	assert.Equal(t, entities.NotificationStatusDelivered, recorder.updates[0].Status)
+	assert.Equal(t, time.Unix(1772442000, 0).UTC(), recorder.updates[0].Timestamp)
+	assert.Equal(t, entities.NotificationStatusFailed, recorder.updates[2].Status)
+	assert.Equal(t, "WhatsApp error 131026: Message undeliverable", recorder.updates[2].Error)
+}
+
+func TestWhatsAppWebhookHandler_StorageErrorRequestsRedelivery(t *testing.T) {
+	recorder := &fakeDeliveryStatusRecorder{errs: map[string]error{
+		"wamid.delivered": errors.New("connection refused"),
+	}}
+	handler := handlers.NewWhatsAppWebhookHandler(recorder, "app-secret", "verify")
+
+	req := httptest.NewRequest(http.MethodPost, "/webhooks/whatsapp", strings.NewReader(whatsappStatusPayload))
+	req.Header.Set("X-Hub-Signature-256", signWhatsApp("app-secret", whatsappStatusPayload))
+	w := httptest.NewRecorder()
+	handler.HandleWebhook(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestWhatsAppWebhookHandler_VerifyWebhook(t *testing.T) {
+	handler := handlers.NewWhatsAppWebhookHandler(&fakeDeliveryStatusRecorder{}, "app-secret", "verify-me")
+
+	w := httptest.NewRecorder()
+	handler.VerifyWebhook(w, httptest.NewRequest(http.MethodGet, "/webhooks/whatsapp?hub.mode=subscribe&hub.verify_token=verify-me&hub.challenge=12345", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "12345", w.Body.String())
+
+	w = httptest.NewRecorder()
+	handler.VerifyWebhook(w, httptest.NewRequest(http.MethodGet, "/webhooks/whatsapp?hub.mode=subscribe&hub.verify_token=wrong&hub.challenge=12345", nil))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
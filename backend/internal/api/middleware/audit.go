@@ -0,0 +1,237 @@
+package middleware
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+)
+
+// operatorActorPrefix starts the actor ID of an operator
+const operatorActorPrefix = "operator:"
+
+// ActorResolver identifies the principal behind a bearer token for the audit log
+type ActorResolver interface {
+	// ResolveActor returns the actor ID for token, or false when the token
+	// authenticates no one
+	ResolveActor(ctx context.Context, token string) (string, bool)
+}
+
+// SessionAuthenticator resolves an account session token to its user
+type SessionAuthenticator interface {
+	Authenticate(ctx context.Context, token string) (*entities.User, error)
+}
+
+// AuditActors resolves configured operator tokens, then account sessions
+type AuditActors struct {
+	operators map[string]string // name -> token
+	sessions  SessionAuthenticator
+}
+
+// NewAuditActors creates a resolver for the given operator tokens, keyed by
+// operator name. sessions may be nil when accounts are disabled.
+func NewAuditActors(operatorTokens map[string]string, sessions SessionAuthenticator) *AuditActors {
+	return &AuditActors{
+		operators: operatorTokens,
+		sessions:  sessions,
+	}
+}
+
+// ResolveActor returns "operator:<name>" for an operator token and
+// "user:<id>" for an active account session
+func (a *AuditActors) ResolveActor(ctx context.Context, token string) (string, bool) {
+	if token == "" {
+		return "", false
+	}
+
+	// Compare against every token so timing does not reveal which one matched
+	operator := ""
+	for name, operatorToken := range a.operators {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(operatorToken)) == 1 {
+			operator = name
+		}
+	}
+	if operator != "" {
+		return operatorActorPrefix + operator, true
+	}
+
+	if a.sessions != nil {
+		if user, err := a.sessions.Authenticate(ctx, token); err == nil && user != nil {
+			return "user:" + user.ID, true
+		}
+	}
+	return "", false
+}
+
+// ParseTrustedProxies parses CIDRs and bare addresses into networks
+func ParseTrustedProxies(proxies []string) ([]*net.IPNet, error) {
+	networks := make([]*net.IPNet, 0, len(proxies))
+	for _, proxy := range proxies {
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q", proxy)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", proxy, err)
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+// AuditContextMiddleware attaches the acting principal, request ID and client
+// IP to the request context so audited mutations can record who made them.
+// The actor is whoever the bearer token authenticates, or anonymous; it is
+// only resolved for requests that can mutate. The request ID is taken from
+// X-Request-ID when present, otherwise generated, and echoed back on the
+// response. Forwarding headers are only believed from trustedProxies.
+func AuditContextMiddleware(actors ActorResolver, trustedProxies []*net.IPNet) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestID := strings.TrimSpace(r.Header.Get("X-Request-ID"))
+			if requestID == "" {
+				requestID = uuid.New().String()
+			}
+			w.Header().Set("X-Request-ID", requestID)
+
+			actor := entities.AuditActorAnonymous
+			if actors != nil && isMutation(r.Method) {
+				if id, ok := actors.ResolveActor(r.Context(), bearerToken(r)); ok {
+					actor = id
+				}
+			}
+
+			ctx := entities.WithAuditContext(r.Context(), entities.AuditContext{
+				ActorID:   actor,
+				RequestID: requestID,
+				IPAddress: clientIP(r, trustedProxies),
+			})
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// RequireOperator only lets requests through whose bearer token is an
+// operator token. Requests without a known token get a 401, and account
+// sessions a 403.
+func RequireOperator(actors ActorResolver) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var (
+				actor string
+				ok    bool
+			)
+			if actors != nil {
+				actor, ok = actors.ResolveActor(r.Context(), bearerToken(r))
+			}
+			switch {
+			case !ok:
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				writeJSONError(w, http.StatusUnauthorized, "an operator token is required")
+			case !strings.HasPrefix(actor, operatorActorPrefix):
+				writeJSONError(w, http.StatusForbidden, "only operators may do this")
+			default:
+				next.ServeHTTP(w, r)
+			}
+		})
+	}
+}
+
+// RequireOperatorUnder applies RequireOperator to the paths under prefix and
+// lets every other request through
+func RequireOperatorUnder(prefix string, actors ActorResolver) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		guarded := RequireOperator(actors)(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if strings.HasPrefix(r.URL.Path, prefix) {
+				guarded.ServeHTTP(w, r)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func writeJSONError(w http.ResponseWriter, statusCode int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
+
+func isMutation(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+	return true
+}
+
+func bearerToken(r *http.Request) string {
+	header := strings.TrimSpace(r.Header.Get("Authorization"))
+	if len(header) > 7 && strings.EqualFold(header[:7], "bearer ") {
+		return strings.TrimSpace(header[7:])
+	}
+	return ""
+}
+
+// clientIP returns the socket peer unless it is a trusted proxy, in which case
+// X-Forwarded-For is walked from the right past the trusted hops to the first
+// address a trusted proxy saw connect. X-Real-IP is used when a trusted proxy
+// sends no X-Forwarded-For.
+func clientIP(r *http.Request, trustedProxies []*net.IPNet) string {
+	peer, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		peer = r.RemoteAddr
+	}
+	if !isTrustedProxy(peer, trustedProxies) {
+		return peer
+	}
+
+	if forwarded := r.Header.Values("X-Forwarded-For"); len(forwarded) > 0 {
+		hops := strings.Split(strings.Join(forwarded, ","), ",")
+		client := peer
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := strings.TrimSpace(hops[i])
+			if net.ParseIP(hop) == nil {
+				break
+			}
+			client = hop
+			if !isTrustedProxy(hop, trustedProxies) {
+				break
+			}
+		}
+		return client
+	}
+	if ip := strings.TrimSpace(r.Header.Get("X-Real-IP")); net.ParseIP(ip) != nil {
+		return ip
+	}
+	return peer
+}
+
+func isTrustedProxy(address string, trustedProxies []*net.IPNet) bool {
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return false
+	}
+	for _, network := range trustedProxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,157 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/api/middleware"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	apperrors "github.com/zatekoja/Patientpricediscoverydesign/backend/pkg/errors"
+)
+
+type stubSessions map[string]string
+
+func (s stubSessions) Authenticate(ctx context.Context, token string) (*entities.User, error) {
+	if id, ok := s[token]; ok {
+		return &entities.User{ID: id}, nil
+	}
+	return nil, apperrors.NewUnauthorizedError("session is invalid or has expired")
+}
+
+func auditContextFor(t *testing.T, actors middleware.ActorResolver, proxies []string, r *http.Request) entities.AuditContext {
+	t.Helper()
+	trusted, err := middleware.ParseTrustedProxies(proxies)
+	require.NoError(t, err)
+
+	var got entities.AuditContext
+	handler := middleware.AuditContextMiddleware(actors, trusted)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = entities.AuditContextFrom(r.Context())
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+	return got
+}
+
+func TestAuditContextMiddleware_ActorComesFromTheBearerToken(t *testing.T) {
+	actors := middleware.NewAuditActors(map[string]string{"ada": "op-token"}, stubSessions{"session-token": "user-1"})
+
+	tests := []struct {
+		name   string
+		method string
+		header map[string]string
+		want   string
+	}{
+		{"operator token", http.MethodPost, map[string]string{"Authorization": "Bearer op-token"}, "operator:ada"},
+		{"account session", http.MethodPut, map[string]string{"Authorization": "bearer session-token"}, "user:user-1"},
+		{"unknown token", http.MethodPost, map[string]string{"Authorization": "Bearer guess"}, entities.AuditActorAnonymous},
+		{"self-declared actor is ignored", http.MethodPost, map[string]string{"X-Actor-ID": "ada"}, entities.AuditActorAnonymous},
+		{"reads are not resolved", http.MethodGet, map[string]string{"Authorization": "Bearer op-token"}, entities.AuditActorAnonymous},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, "/api/admin/fee-waivers", nil)
+			for key, value := range tt.header {
+				r.Header.Set(key, value)
+			}
+			assert.Equal(t, tt.want, auditContextFor(t, actors, nil, r).ActorID)
+		})
+	}
+}
+
+func TestAuditContextMiddleware_ForwardedForOnlyFromTrustedProxies(t *testing.T) {
+	proxies := []string{"10.0.0.0/8", "192.168.1.5"}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		forwarded  string
+		realIP     string
+		want       string
+	}{
+		{"direct client spoofing the header", "203.0.113.9:4000", "1.2.3.4", "", "203.0.113.9"},
+		{"trusted proxy", "10.0.0.2:4000", "198.51.100.7", "", "198.51.100.7"},
+		{"spoofed hop left of the client", "10.0.0.2:4000", "1.2.3.4, 198.51.100.7", "", "198.51.100.7"},
+		{"chain of trusted proxies", "10.0.0.2:4000", "198.51.100.7, 192.168.1.5, 10.1.1.1", "", "198.51.100.7"},
+		{"garbage hop stops the walk", "10.0.0.2:4000", "198.51.100.7, not-an-ip", "", "10.0.0.2"},
+		{"real ip from a trusted proxy", "192.168.1.5:4000", "", "198.51.100.8", "198.51.100.8"},
+		{"real ip from an untrusted peer", "203.0.113.9:4000", "", "198.51.100.8", "203.0.113.9"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/api/admin/fee-waivers", nil)
+			r.RemoteAddr = tt.remoteAddr
+			if tt.forwarded != "" {
+				r.Header.Set("X-Forwarded-For", tt.forwarded)
+			}
+			if tt.realIP != "" {
+				r.Header.Set("X-Real-IP", tt.realIP)
+			}
+			assert.Equal(t, tt.want, auditContextFor(t, nil, proxies, r).IPAddress)
+		})
+	}
+}
+
+func TestParseTrustedProxies_RejectsInvalidEntries(t *testing.T) {
+	_, err := middleware.ParseTrustedProxies([]string{"10.0.0.0/33"})
+	assert.Error(t, err)
+	_, err = middleware.ParseTrustedProxies([]string{"proxy.internal"})
+	assert.Error(t, err)
+}
+
+func TestRequireOperator_RejectsAnyoneButOperators(t *testing.T) {
+	actors := middleware.NewAuditActors(map[string]string{"ada": "op-token"}, stubSessions{"session-token": "user-1"})
+
+	tests := []struct {
+		name   string
+		actors middleware.ActorResolver
+		token  string
+		want   int
+	}{
+		{"operator", actors, "op-token", http.StatusOK},
+		{"account session", actors, "session-token", http.StatusForbidden},
+		{"unknown token", actors, "guess", http.StatusUnauthorized},
+		{"no token", actors, "", http.StatusUnauthorized},
+		{"no operators configured", nil, "op-token", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := middleware.RequireOperator(tt.actors)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+			r := httptest.NewRequest(http.MethodPost, "/api/admin/appointments/a1/complete", nil)
+			if tt.token != "" {
+				r.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			assert.Equal(t, tt.want, w.Code)
+		})
+	}
+}
+
+func TestRequireOperatorUnder_GuardsOnlyThePrefix(t *testing.T) {
+	actors := middleware.NewAuditActors(map[string]string{"ada": "op-token"}, nil)
+	handler := middleware.RequireOperatorUnder("/api/admin/", actors)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	for path, want := range map[string]int{
+		"/api/admin/audit":        http.StatusUnauthorized,
+		"/api/admin/audit/verify": http.StatusUnauthorized,
+		"/api/facilities/search":  http.StatusOK,
+	} {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, want, w.Code, path)
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/api/admin/audit", nil)
+	r.Header.Set("Authorization", "Bearer op-token")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
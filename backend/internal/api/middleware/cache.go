@@ -14,8 +14,9 @@ import (
 
 // CacheConfig holds cache configuration for specific routes
 type CacheConfig struct {
-	TTLSeconds int
-	Enabled    bool
+	TTLSeconds  int
+	Enabled     bool
+	VaryHeaders []string // request headers that change the response
 }
 
 // CacheMiddleware provides HTTP response caching
@@ -29,12 +30,16 @@ func NewCacheMiddleware(cache providers.CacheProvider) *CacheMiddleware {
 	return &CacheMiddleware{
 		cache: cache,
 		routeConfigs: map[string]CacheConfig{
-			"/api/facilities/search":   {TTLSeconds: 300, Enabled: true},  // 5 minutes
-			"/api/facilities/":         {TTLSeconds: 600, Enabled: true},  // 10 minutes (prefix match)
-			"/api/insurance-providers": {TTLSeconds: 1800, Enabled: true}, // 30 minutes
-			"/api/procedures":          {TTLSeconds: 1800, Enabled: true}, // 30 minutes
-			"/api/geocode":             {TTLSeconds: 3600, Enabled: true}, // 1 hour
-			"/api/facilities/suggest":  {TTLSeconds: 180, Enabled: true},  // 3 minutes
+			// The per-response impression ID is a header set outside the cache, see SearchImpressions
+			"/api/facilities/search":   {TTLSeconds: 300, Enabled: true, VaryHeaders: []string{"X-Session-ID"}}, // 5 minutes
+			"/api/facilities/":         {TTLSeconds: 600, Enabled: true},                                        // 10 minutes (prefix match)
+			"/api/insurance-providers": {TTLSeconds: 1800, Enabled: true},                                       // 30 minutes
+			"/api/procedures":          {TTLSeconds: 1800, Enabled: true},                                       // 30 minutes
+			"/api/geocode":             {TTLSeconds: 3600, Enabled: true},                                       // 1 hour
+			// Suggestions run a search, so they vary by session like search does
+			"/api/facilities/suggest": {TTLSeconds: 180, Enabled: true, VaryHeaders: []string{"X-Session-ID"}}, // 3 minutes
+			"/api/procedures/search":  {TTLSeconds: 300, Enabled: true},                                        // 5 minutes; carries live price stats
+			"/api/suggest":            {TTLSeconds: 180, Enabled: true},                                        // 3 minutes
 		},
 	}
 }
@@ -62,7 +67,7 @@ func (m *CacheMiddleware) Middleware(next http.Handler) http.Handler {
 		}
 
 		// Generate cache key
-		cacheKey := m.generateCacheKey(r)
+		cacheKey := m.generateCacheKey(r, config)
 
 		// Try to get from cache
 		if cached, err := m.cache.Get(r.Context(), cacheKey); err == nil {
@@ -109,6 +114,22 @@ func (m *CacheMiddleware) getRouteConfig(path string) CacheConfig {
 		return config
 	}
 
+	// Availability changes with every hold and booking, so it is never cached
+	if strings.HasSuffix(path, "/availability") {
+		return CacheConfig{Enabled: false}
+	}
+
+	// Forecasts blend in the live wait, which changes with every capacity report
+	if strings.HasSuffix(path, "/wait-forecast") {
+		return CacheConfig{Enabled: false}
+	}
+
+	// A facility's ETag is its version, which clients send back in If-Match,
+	// so the facility must never be served stale
+	if isFacilityDetailPath(path) {
+		return CacheConfig{Enabled: false}
+	}
+
 	// Prefix match for dynamic routes (e.g., /api/facilities/{id})
 	for pattern, config := range m.routeConfigs {
 		if strings.HasPrefix(path, pattern) {
@@ -120,8 +141,14 @@ func (m *CacheMiddleware) getRouteConfig(path string) CacheConfig {
 	return CacheConfig{Enabled: false}
 }
 
+// isFacilityDetailPath reports whether path is a single facility, /api/facilities/{id}
+func isFacilityDetailPath(path string) bool {
+	id := strings.TrimPrefix(path, "/api/facilities/")
+	return id != path && id != "" && !strings.Contains(id, "/")
+}
+
 // generateCacheKey generates a cache key from the request
-func (m *CacheMiddleware) generateCacheKey(r *http.Request) string {
+func (m *CacheMiddleware) generateCacheKey(r *http.Request, config CacheConfig) string {
 	// Include method, path, and query parameters
 	key := fmt.Sprintf("%s:%s", r.Method, r.URL.Path)
 
@@ -130,6 +157,12 @@ func (m *CacheMiddleware) generateCacheKey(r *http.Request) string {
 		key += "?" + r.URL.RawQuery
 	}
 
+	for _, header := range config.VaryHeaders {
+		if value := r.Header.Get(header); value != "" {
+			key += "|" + header + "=" + value
+		}
+	}
+
 	// Hash the key to keep it reasonable length
 	hash := sha256.Sum256([]byte(key))
 	return "http:cache:" + hex.EncodeToString(hash[:])
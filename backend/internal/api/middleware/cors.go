@@ -44,8 +44,9 @@ func CORSMiddleware(next http.Handler) http.Handler {
 			}
 		}
 
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Session-ID, X-Request-ID, If-Match")
+		w.Header().Set("Access-Control-Expose-Headers", "ETag, X-Request-ID, X-Impression-ID")
 
 		// Handle preflight requests
 		if r.Method == "OPTIONS" {
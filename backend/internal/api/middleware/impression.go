@@ -0,0 +1,139 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+)
+
+// ImpressionIDHeader carries the impression ID of a search response, which is
+// also its impression_id field. Clients send it back as impression_id when
+// reporting clicks and bookings.
+const ImpressionIDHeader = "X-Impression-ID"
+
+const searchPath = "/api/facilities/search"
+
+type impressionIDKey struct{}
+
+// ImpressionIDFromContext returns the impression ID minted for this request,
+// or "" when the request did not pass through SearchImpressions
+func ImpressionIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(impressionIDKey{}).(string)
+	return id
+}
+
+// CachedImpressionRecorder logs a search served from cache as a new
+// impression of the search it was cached from. impression is the response as
+// served, under its impression ID, for when that search was never logged.
+type CachedImpressionRecorder interface {
+	TrackCachedImpression(ctx context.Context, searchID string, impression *entities.SearchEvent)
+}
+
+// SearchImpressions mints an impression ID for every facility search response.
+// It must wrap the cache middleware: the ID is added to the body as
+// impression_id and sent in a header only after the cache, so a cache HIT
+// still gets an ID of its own, and the HIT is recorded against the search_id
+// in the cached body.
+func SearchImpressions(recorder CachedImpressionRecorder) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet || r.URL.Path != searchPath {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			impressionID := uuid.New().String()
+			w.Header().Set(ImpressionIDHeader, impressionID)
+			ctx := context.WithValue(r.Context(), impressionIDKey{}, impressionID)
+
+			held := &heldResponse{ResponseWriter: w, statusCode: http.StatusOK}
+			next.ServeHTTP(held, r.WithContext(ctx))
+
+			body := held.body.Bytes()
+			if held.statusCode == http.StatusOK {
+				var fields map[string]json.RawMessage
+				if err := json.Unmarshal(body, &fields); err == nil && fields != nil {
+					if rewritten, err := withImpressionID(fields, impressionID); err == nil {
+						body = rewritten
+					}
+					if recorder != nil && w.Header().Get("X-Cache") == "HIT" {
+						var searchID string
+						if err := json.Unmarshal(fields["search_id"], &searchID); err == nil && searchID != "" {
+							recorder.TrackCachedImpression(ctx, searchID, cachedImpression(r, fields, impressionID))
+						}
+					}
+				}
+			}
+
+			w.WriteHeader(held.statusCode)
+			if _, err := w.Write(body); err != nil {
+				log.Printf("Failed to write search response: %v", err)
+			}
+		})
+	}
+}
+
+// cachedImpression describes a search served from cache from its request and
+// cached body
+func cachedImpression(r *http.Request, fields map[string]json.RawMessage, impressionID string) *entities.SearchEvent {
+	query := r.URL.Query()
+	impression := &entities.SearchEvent{
+		ID:        impressionID,
+		Query:     strings.TrimSpace(query.Get("query")),
+		SessionID: strings.TrimSpace(r.Header.Get("X-Session-ID")),
+	}
+	if impression.SessionID == "" {
+		impression.SessionID = strings.TrimSpace(query.Get("session_id"))
+	}
+	impression.UserLatitude, _ = strconv.ParseFloat(query.Get("lat"), 64)
+	impression.UserLongitude, _ = strconv.ParseFloat(query.Get("lon"), 64)
+	impression.ResultOffset, _ = strconv.Atoi(query.Get("offset"))
+
+	var facilities []struct {
+		ID string `json:"id"`
+	}
+	_ = json.Unmarshal(fields["count"], &impression.ResultCount)
+	if err := json.Unmarshal(fields["facilities"], &facilities); err == nil {
+		for _, facility := range facilities {
+			impression.ResultFacilityIDs = append(impression.ResultFacilityIDs, facility.ID)
+		}
+	}
+	return impression
+}
+
+func withImpressionID(fields map[string]json.RawMessage, impressionID string) ([]byte, error) {
+	id, err := json.Marshal(impressionID)
+	if err != nil {
+		return nil, err
+	}
+	fields["impression_id"] = id
+	return json.Marshal(fields)
+}
+
+// heldResponse holds a response back so its body can be rewritten before it
+// is sent
+type heldResponse struct {
+	http.ResponseWriter
+	statusCode int
+	body       bytes.Buffer
+	written    bool
+}
+
+func (h *heldResponse) WriteHeader(statusCode int) {
+	if !h.written {
+		h.statusCode = statusCode
+		h.written = true
+	}
+}
+
+func (h *heldResponse) Write(data []byte) (int, error) {
+	h.written = true
+	return h.body.Write(data)
+}
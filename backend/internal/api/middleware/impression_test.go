@@ -0,0 +1,61 @@
+package middleware_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/api/middleware"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+)
+
+type capturingImpressions struct {
+	searchID   string
+	impression *entities.SearchEvent
+}
+
+func (c *capturingImpressions) TrackCachedImpression(ctx context.Context, searchID string, impression *entities.SearchEvent) {
+	c.searchID, c.impression = searchID, impression
+}
+
+func TestSearchImpressions_PutsTheImpressionIDInHeaderAndBody(t *testing.T) {
+	recorder := &capturingImpressions{}
+
+	for _, cache := range []string{"MISS", "HIT"} {
+		t.Run(cache, func(t *testing.T) {
+			handler := middleware.SearchImpressions(recorder)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("X-Cache", cache)
+				_, _ = w.Write([]byte(`{"facilities":[{"id":"fac-1"},{"id":"fac-2"}],"count":7,"search_id":"search-1"}`))
+			}))
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/api/facilities/search?query=malaria&lat=6.5&lon=3.4&offset=20", nil)
+			r.Header.Set("X-Session-ID", "session-1")
+			handler.ServeHTTP(w, r)
+
+			var body map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			impressionID := w.Header().Get(middleware.ImpressionIDHeader)
+			assert.NotEmpty(t, impressionID)
+			assert.Equal(t, impressionID, body["impression_id"])
+			assert.Equal(t, "search-1", body["search_id"])
+		})
+	}
+	assert.Equal(t, "search-1", recorder.searchID, "the HIT is recorded against the cached search")
+	require.NotNil(t, recorder.impression)
+	assert.NotEqual(t, "search-1", recorder.impression.ID)
+	assert.Equal(t, entities.SearchEvent{
+		ID:                recorder.impression.ID,
+		Query:             "malaria",
+		SessionID:         "session-1",
+		UserLatitude:      6.5,
+		UserLongitude:     3.4,
+		ResultOffset:      20,
+		ResultCount:       7,
+		ResultFacilityIDs: []string{"fac-1", "fac-2"},
+	}, *recorder.impression, "the served response is described for when the search was never logged")
+}
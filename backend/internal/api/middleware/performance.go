@@ -87,9 +87,13 @@ func ETag(next http.Handler) http.Handler {
 
 		// Only add ETag for successful responses
 		if rec.statusCode == 0 || rec.statusCode == http.StatusOK {
-			// Generate ETag from response body
-			hash := sha256.Sum256(rec.buffer.Bytes())
-			etag := `"` + hex.EncodeToString(hash[:16]) + `"` // Use first 16 bytes for shorter ETag
+			// Keep an ETag the handler set, such as a row version clients send
+			// back in If-Match; otherwise generate one from the response body
+			etag := w.Header().Get("ETag")
+			if etag == "" {
+				hash := sha256.Sum256(rec.buffer.Bytes())
+				etag = `"` + hex.EncodeToString(hash[:16]) + `"` // Use first 16 bytes for shorter ETag
+			}
 
 			// Check if client has matching ETag
 			clientETag := r.Header.Get("If-None-Match")
@@ -143,12 +147,15 @@ func CacheControl(next http.Handler) http.Handler {
 		path := r.URL.Path
 
 		switch {
+		case strings.HasPrefix(path, "/api/facilities/") && strings.HasSuffix(path, "/availability"):
+			// Availability changes with every hold and booking
+			w.Header().Set("Cache-Control", "private, no-cache, must-revalidate")
 		case strings.HasPrefix(path, "/api/facilities/") && !strings.Contains(path, "search"):
 			// Individual facilities: cache for 5 minutes
 			w.Header().Set("Cache-Control", "public, max-age=300, must-revalidate")
 		case strings.Contains(path, "/api/search") || strings.Contains(path, "/api/facilities/search"):
-			// Search results: cache for 2 minutes
-			w.Header().Set("Cache-Control", "public, max-age=120, must-revalidate")
+			// Search results carry a per-response impression ID and must not be reused
+			w.Header().Set("Cache-Control", "private, no-store")
 		case strings.HasPrefix(path, "/api/procedures"):
 			// Procedures: cache for 10 minutes (changes rarely)
 			w.Header().Set("Cache-Control", "public, max-age=600, must-revalidate")
@@ -1,6 +1,7 @@
 package routes
 
 import (
+	"net"
 	"net/http"
 
 	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/api/handlers"
@@ -31,9 +32,24 @@ type Router struct {
 
 	calendlyWebhookHandler *handlers.CalendlyWebhookHandler
 	feeWaiverHandler       *handlers.FeeWaiverHandler
-
-	cacheMiddleware *middleware.CacheMiddleware
-	metrics         *observability.Metrics
+	searchTriageHandler    *handlers.SearchTriageHandler
+	experimentHandler      *handlers.SearchExperimentHandler
+	interactionHandler     *handlers.SearchInteractionHandler
+	calendarHandler        *handlers.CalendarHandler
+	reviewHandler          *handlers.ReviewHandler
+	accountHandler         *handlers.AccountHandler
+	whatsappWebhookHandler *handlers.WhatsAppWebhookHandler
+	notificationHandler    *handlers.NotificationHandler
+	auditHandler           *handlers.AuditHandler
+	waitForecastHandler    *handlers.WaitForecastHandler
+	procedureSearchHandler *handlers.ProcedureSearchHandler
+	careBasketHandler      *handlers.CareBasketHandler
+
+	cacheMiddleware    *middleware.CacheMiddleware
+	impressionRecorder middleware.CachedImpressionRecorder
+	auditActors        middleware.ActorResolver
+	trustedProxies     []*net.IPNet
+	metrics            *observability.Metrics
 }
 
 // NewRouter creates a new router
@@ -59,6 +75,18 @@ func NewRouter(
 
 	calendlyWebhookHandler *handlers.CalendlyWebhookHandler,
 	feeWaiverHandler *handlers.FeeWaiverHandler,
+	searchTriageHandler *handlers.SearchTriageHandler,
+	experimentHandler *handlers.SearchExperimentHandler,
+	interactionHandler *handlers.SearchInteractionHandler,
+	calendarHandler *handlers.CalendarHandler,
+	reviewHandler *handlers.ReviewHandler,
+	accountHandler *handlers.AccountHandler,
+	whatsappWebhookHandler *handlers.WhatsAppWebhookHandler,
+	notificationHandler *handlers.NotificationHandler,
+	auditHandler *handlers.AuditHandler,
+	waitForecastHandler *handlers.WaitForecastHandler,
+	procedureSearchHandler *handlers.ProcedureSearchHandler,
+	careBasketHandler *handlers.CareBasketHandler,
 
 	metrics *observability.Metrics,
 
@@ -86,6 +114,18 @@ func NewRouter(
 
 		calendlyWebhookHandler: calendlyWebhookHandler,
 		feeWaiverHandler:       feeWaiverHandler,
+		searchTriageHandler:    searchTriageHandler,
+		experimentHandler:      experimentHandler,
+		interactionHandler:     interactionHandler,
+		calendarHandler:        calendarHandler,
+		reviewHandler:          reviewHandler,
+		accountHandler:         accountHandler,
+		whatsappWebhookHandler: whatsappWebhookHandler,
+		notificationHandler:    notificationHandler,
+		auditHandler:           auditHandler,
+		waitForecastHandler:    waitForecastHandler,
+		procedureSearchHandler: procedureSearchHandler,
+		careBasketHandler:      careBasketHandler,
 
 		cacheMiddleware: cacheMiddleware,
 		metrics:         metrics,
@@ -93,6 +133,20 @@ func NewRouter(
 
 }
 
+// SetImpressionRecorder logs facility searches served from the response cache
+// as impressions of their own
+func (r *Router) SetImpressionRecorder(recorder middleware.CachedImpressionRecorder) {
+	r.impressionRecorder = recorder
+}
+
+// SetAuditIdentity sets how audited requests are attributed: actors resolves
+// bearer tokens to principals, and forwarding headers are only believed from
+// trustedProxies
+func (r *Router) SetAuditIdentity(actors middleware.ActorResolver, trustedProxies []*net.IPNet) {
+	r.auditActors = actors
+	r.trustedProxies = trustedProxies
+}
+
 // SetupRoutes configures all application routes
 
 func (r *Router) SetupRoutes() http.Handler {
@@ -115,6 +169,8 @@ func (r *Router) SetupRoutes() http.Handler {
 
 	r.mux.HandleFunc("GET /api/facilities/search", r.facilityHandler.SearchFacilities)
 
+	r.mux.HandleFunc("GET /api/facilities/map", r.facilityHandler.GetFacilityMap)
+
 	r.mux.HandleFunc("GET /api/facilities/suggest", r.facilityHandler.SuggestFacilities)
 
 	r.mux.HandleFunc("GET /api/facilities/{id}", r.facilityHandler.GetFacility)
@@ -124,10 +180,13 @@ func (r *Router) SetupRoutes() http.Handler {
 
 	r.mux.HandleFunc("PATCH /api/facilities/{id}", r.facilityHandler.UpdateFacility)
 	r.mux.HandleFunc("PATCH /api/facilities/{id}/services/{procedureId}", r.facilityHandler.UpdateServiceAvailability)
+	r.mux.HandleFunc("PATCH /api/admin/facilities/{id}/location", r.facilityHandler.PinFacilityLocation)
 
 	// Appointment endpoints
 
 	r.mux.HandleFunc("POST /api/appointments", r.appointmentHandler.BookAppointment)
+	r.mux.HandleFunc("POST /api/appointments/{id}/cancel", r.appointmentHandler.CancelAppointment)
+	r.mux.HandleFunc("POST /api/admin/appointments/{id}/complete", r.appointmentHandler.CompleteAppointment)
 
 	r.mux.HandleFunc("GET /api/facilities/{id}/availability", r.appointmentHandler.GetAvailability)
 
@@ -138,6 +197,12 @@ func (r *Router) SetupRoutes() http.Handler {
 	r.mux.HandleFunc("GET /api/procedures/{id}", r.procedureHandler.GetProcedure)
 	r.mux.HandleFunc("GET /api/procedures/{id}/enrichment", r.procedureHandler.GetProcedureEnrichment)
 
+	// Procedure search and typed autocomplete
+	if r.procedureSearchHandler != nil {
+		r.mux.HandleFunc("GET /api/procedures/search", r.procedureSearchHandler.SearchProcedures)
+		r.mux.HandleFunc("GET /api/suggest", r.procedureSearchHandler.Suggest)
+	}
+
 	// Insurance endpoints
 
 	r.mux.HandleFunc("GET /api/insurance-providers", r.insuranceHandler.ListInsuranceProviders)
@@ -181,21 +246,117 @@ func (r *Router) SetupRoutes() http.Handler {
 	// Analytics endpoints
 	r.mux.HandleFunc("GET /api/analytics/zero-result-queries", r.facilityHandler.GetZeroResultQueries)
 
+	// Search click and conversion tracking endpoints
+	if r.interactionHandler != nil {
+		r.mux.HandleFunc("POST /api/analytics/events", r.interactionHandler.RecordEvent)
+		r.mux.HandleFunc("GET /api/analytics/reports/queries", r.interactionHandler.GetQueryReport)
+		r.mux.HandleFunc("GET /api/analytics/reports/facilities", r.interactionHandler.GetFacilityReport)
+		r.mux.HandleFunc("GET /api/analytics/reports/positions", r.interactionHandler.GetPositionReport)
+	}
+
+	// Zero-result query triage endpoints
+	if r.searchTriageHandler != nil {
+		r.mux.HandleFunc("GET /api/admin/search-triage/clusters", r.searchTriageHandler.ListClusters)
+		r.mux.HandleFunc("GET /api/admin/search-triage/fixes", r.searchTriageHandler.ListFixes)
+		r.mux.HandleFunc("POST /api/admin/search-triage/fixes", r.searchTriageHandler.AcceptFix)
+	}
+
+	// Search A/B experiment endpoints
+	if r.experimentHandler != nil {
+		r.mux.HandleFunc("GET /api/admin/experiments", r.experimentHandler.ListExperiments)
+		r.mux.HandleFunc("POST /api/admin/experiments", r.experimentHandler.CreateExperiment)
+		r.mux.HandleFunc("GET /api/admin/experiments/{id}", r.experimentHandler.GetExperiment)
+		r.mux.HandleFunc("POST /api/admin/experiments/{id}/start", r.experimentHandler.StartExperiment)
+		r.mux.HandleFunc("POST /api/admin/experiments/{id}/stop", r.experimentHandler.StopExperiment)
+		r.mux.HandleFunc("GET /api/admin/experiments/{id}/report", r.experimentHandler.GetExperimentReport)
+	}
+
 	// Fee waiver endpoints
 	if r.feeWaiverHandler != nil {
 		r.mux.HandleFunc("GET /api/facilities/{id}/fee-waiver", r.feeWaiverHandler.GetFacilityFeeWaiver)
 		r.mux.HandleFunc("POST /api/admin/fee-waivers", r.feeWaiverHandler.CreateFeeWaiver)
 	}
 
+	// Care basket quotes across nearby facilities
+	if r.careBasketHandler != nil {
+		r.mux.HandleFunc("POST /api/baskets/quote", r.careBasketHandler.QuoteBasket)
+	}
+
+	// Native facility calendar endpoints
+	if r.calendarHandler != nil {
+		r.mux.HandleFunc("GET /api/admin/facilities/{id}/calendar", r.calendarHandler.GetCalendar)
+		r.mux.HandleFunc("PUT /api/admin/facilities/{id}/calendar", r.calendarHandler.SaveCalendar)
+		r.mux.HandleFunc("GET /api/admin/facilities/{id}/calendar/templates", r.calendarHandler.ListTemplates)
+		r.mux.HandleFunc("POST /api/admin/facilities/{id}/calendar/templates", r.calendarHandler.CreateTemplate)
+		r.mux.HandleFunc("DELETE /api/admin/facilities/{id}/calendar/templates/{templateId}", r.calendarHandler.DeleteTemplate)
+		r.mux.HandleFunc("GET /api/admin/facilities/{id}/calendar/blackouts", r.calendarHandler.ListBlackouts)
+		r.mux.HandleFunc("POST /api/admin/facilities/{id}/calendar/blackouts", r.calendarHandler.CreateBlackout)
+		r.mux.HandleFunc("DELETE /api/admin/facilities/{id}/calendar/blackouts/{blackoutId}", r.calendarHandler.DeleteBlackout)
+		r.mux.HandleFunc("GET /api/admin/facilities/{id}/calendar/slots", r.calendarHandler.ListSlots)
+		r.mux.HandleFunc("POST /api/appointments/holds", r.calendarHandler.HoldSlot)
+		r.mux.HandleFunc("DELETE /api/appointments/holds/{id}", r.calendarHandler.ReleaseHold)
+	}
+
+	// Patient review and moderation endpoints
+	if r.reviewHandler != nil {
+		r.mux.HandleFunc("POST /api/reviews", r.reviewHandler.SubmitReview)
+		r.mux.HandleFunc("GET /api/facilities/{id}/reviews", r.reviewHandler.ListFacilityReviews)
+		r.mux.HandleFunc("GET /api/admin/reviews", r.reviewHandler.ListModerationQueue)
+		r.mux.HandleFunc("POST /api/admin/reviews/{id}/moderate", r.reviewHandler.ModerateReview)
+		r.mux.HandleFunc("PUT /api/admin/reviews/{id}/response", r.reviewHandler.RespondToReview)
+	}
+
+	// Patient login and account endpoints
+	if r.accountHandler != nil {
+		r.mux.HandleFunc("POST /api/auth/otp", r.accountHandler.RequestLoginCode)
+		r.mux.HandleFunc("POST /api/auth/verify", r.accountHandler.VerifyLoginCode)
+		r.mux.HandleFunc("POST /api/auth/logout", r.accountHandler.Logout)
+		r.mux.HandleFunc("GET /api/me", r.accountHandler.GetProfile)
+		r.mux.HandleFunc("PUT /api/me", r.accountHandler.UpdateProfile)
+		r.mux.HandleFunc("GET /api/me/appointments", r.accountHandler.ListAppointments)
+		r.mux.HandleFunc("GET /api/me/saved-facilities", r.accountHandler.ListSavedFacilities)
+		r.mux.HandleFunc("PUT /api/me/saved-facilities/{id}", r.accountHandler.SaveFacility)
+		r.mux.HandleFunc("DELETE /api/me/saved-facilities/{id}", r.accountHandler.RemoveSavedFacility)
+		r.mux.HandleFunc("GET /api/me/notification-preferences", r.accountHandler.GetNotificationPreferences)
+		r.mux.HandleFunc("PUT /api/me/notification-preferences", r.accountHandler.UpdateNotificationPreferences)
+	}
+
+	// Failed notification queue
+	if r.notificationHandler != nil {
+		r.mux.HandleFunc("GET /api/admin/notifications", r.notificationHandler.ListNotifications)
+		r.mux.HandleFunc("POST /api/admin/notifications/{id}/resend", r.notificationHandler.ResendNotification)
+	}
+
+	// Audit log of operator and admin mutations
+	if r.auditHandler != nil {
+		r.mux.HandleFunc("GET /api/admin/audit", r.auditHandler.ListEntries)
+		r.mux.HandleFunc("GET /api/admin/audit/verify", r.auditHandler.VerifyChain)
+	}
+
+	// Expected waits learned from capacity history
+	if r.waitForecastHandler != nil {
+		r.mux.HandleFunc("GET /api/facilities/{id}/wait-forecast", r.waitForecastHandler.GetWaitForecast)
+	}
+
 	// Calendly webhook endpoint for appointment notifications
 	if r.calendlyWebhookHandler != nil {
 		r.mux.HandleFunc("POST /webhooks/calendly", r.calendlyWebhookHandler.HandleWebhook)
 	}
 
+	// WhatsApp delivery status callbacks
+	if r.whatsappWebhookHandler != nil {
+		r.mux.HandleFunc("GET /webhooks/whatsapp", r.whatsappWebhookHandler.VerifyWebhook)
+		r.mux.HandleFunc("POST /webhooks/whatsapp", r.whatsappWebhookHandler.HandleWebhook)
+	}
+
 	// Apply middleware in reverse order (last middleware wraps first)
 	// CORS must be outermost so cached responses also get CORS headers.
 
 	var handler http.Handler = r.mux
+	// Admin endpoints, reads included, are for operators only, so every admin
+	// change is attributed to one
+	handler = middleware.RequireOperatorUnder("/api/admin/", r.auditActors)(handler)
+	handler = middleware.AuditContextMiddleware(r.auditActors, r.trustedProxies)(handler)
 	handler = middleware.LoggingMiddleware(handler)
 
 	// Apply cache middleware if available
@@ -203,6 +364,9 @@ func (r *Router) SetupRoutes() http.Handler {
 		handler = r.cacheMiddleware.Middleware(handler)
 	}
 
+	// Impression IDs are minted outside the cache so cached searches get their own
+	handler = middleware.SearchImpressions(r.impressionRecorder)(handler)
+
 	handler = middleware.ObservabilityMiddleware(r.metrics)(handler)
 
 	// Apply HTTP performance optimizations (compression, ETag, cache headers)
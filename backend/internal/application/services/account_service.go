@@ -0,0 +1,522 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"math/big"
+	"net/mail"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/providers"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/repositories"
+	apperrors "github.com/zatekoja/Patientpricediscoverydesign/backend/pkg/errors"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/pkg/utils"
+)
+
+const (
+	loginCodeDigits      = 6
+	loginCodeTTL         = 10 * time.Minute
+	loginCodeMaxAttempts = 5
+	loginCodeResendAfter = time.Minute
+	loginCodeHourlyLimit = 5
+	sessionTTL           = 30 * 24 * time.Hour
+	maxProfileNameLength = 100
+	defaultHistoryLimit  = 20
+	maxHistoryLimit      = 100
+)
+
+// LoginCodeResult describes a login code that was sent.
+type LoginCodeResult struct {
+	Phone     string                       `json:"phone"`
+	Channel   entities.NotificationChannel `json:"channel"`
+	ExpiresAt time.Time                    `json:"expires_at"`
+}
+
+// LoginResult is a new session for a verified phone number.
+type LoginResult struct {
+	Token              string         `json:"token"`
+	ExpiresAt          time.Time      `json:"expires_at"`
+	User               *entities.User `json:"user"`
+	LinkedAppointments int            `json:"linked_appointments"`
+}
+
+// UpdateProfileInput holds profile changes; nil fields are left unchanged.
+type UpdateProfileInput struct {
+	FirstName *string `json:"first_name"`
+	LastName  *string `json:"last_name"`
+	Email     *string `json:"email"`
+}
+
+// NotificationPreferencesInput holds preference changes; nil fields are left unchanged.
+type NotificationPreferencesInput struct {
+	WhatsAppEnabled    *bool `json:"whatsapp_enabled"`
+	SMSEnabled         *bool `json:"sms_enabled"`
+	EmailEnabled       *bool `json:"email_enabled"`
+	Reminder24hEnabled *bool `json:"reminder_24h_enabled"`
+	Reminder1hEnabled  *bool `json:"reminder_1h_enabled"`
+}
+
+// AccountService handles patient phone login, profiles, saved facilities,
+// appointment history and notification preferences.
+type AccountService struct {
+	users        repositories.UserRepository
+	otps         repositories.LoginOTPRepository
+	sessions     repositories.SessionRepository
+	saved        repositories.SavedFacilityRepository
+	preferences  repositories.NotificationPreferenceRepository
+	appointments repositories.AppointmentRepository
+	facilities   repositories.FacilityRepository
+	senders      map[entities.NotificationChannel]providers.NotificationSender
+	now          func() time.Time
+}
+
+// NewAccountService creates a new account service
+func NewAccountService(
+	users repositories.UserRepository,
+	otps repositories.LoginOTPRepository,
+	sessions repositories.SessionRepository,
+	saved repositories.SavedFacilityRepository,
+	preferences repositories.NotificationPreferenceRepository,
+	appointments repositories.AppointmentRepository,
+	facilities repositories.FacilityRepository,
+) *AccountService {
+	return &AccountService{
+		users:        users,
+		otps:         otps,
+		sessions:     sessions,
+		saved:        saved,
+		preferences:  preferences,
+		appointments: appointments,
+		facilities:   facilities,
+		senders:      map[entities.NotificationChannel]providers.NotificationSender{},
+		now:          time.Now,
+	}
+}
+
+// SetCodeSender enables login code delivery over the sender's channel (whatsapp or sms).
+func (s *AccountService) SetCodeSender(sender providers.NotificationSender) {
+	s.senders[sender.Channel()] = sender
+}
+
+// RequestLoginCode sends a one-time login code to the phone number. Codes can
+// be resent once a minute and at most five times an hour per number.
+func (s *AccountService) RequestLoginCode(ctx context.Context, phone string, channel entities.NotificationChannel) (*LoginCodeResult, error) {
+	normalized, err := utils.NormalizePhone(phone)
+	if err != nil {
+		return nil, apperrors.NewValidationError("phone must be a valid phone number")
+	}
+	if channel == "" {
+		channel = entities.ChannelWhatsApp
+	}
+	sender, ok := s.senders[channel]
+	if !ok {
+		return nil, apperrors.NewValidationError(fmt.Sprintf("login codes cannot be sent by %s", channel))
+	}
+
+	now := s.now().UTC()
+	latest, err := s.otps.GetLatest(ctx, normalized)
+	if err != nil && !isNotFound(err) {
+		return nil, err
+	}
+	if latest != nil && now.Sub(latest.CreatedAt) < loginCodeResendAfter {
+		return nil, apperrors.NewRateLimitedError("please wait a minute before requesting another code")
+	}
+	sent, err := s.otps.CountSince(ctx, normalized, now.Add(-time.Hour))
+	if err != nil {
+		return nil, err
+	}
+	if sent >= loginCodeHourlyLimit {
+		return nil, apperrors.NewRateLimitedError("too many codes requested for this number, try again later")
+	}
+
+	code, err := generateLoginCode()
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to generate login code", err)
+	}
+	otp := &entities.LoginOTP{
+		Phone:     normalized,
+		CodeHash:  hashLoginCode(normalized, code),
+		Channel:   channel,
+		ExpiresAt: now.Add(loginCodeTTL),
+		CreatedAt: now,
+	}
+	if err := s.otps.Create(ctx, otp); err != nil {
+		return nil, err
+	}
+
+	body := fmt.Sprintf("Your login code is %s. It expires in %d minutes. Do not share it with anyone.", code, int(loginCodeTTL.Minutes()))
+	if _, err := sender.Send(ctx, providers.NotificationMessage{To: normalized, Body: body}); err != nil {
+		return nil, apperrors.NewExternalError("failed to send login code", err)
+	}
+
+	return &LoginCodeResult{Phone: normalized, Channel: channel, ExpiresAt: otp.ExpiresAt}, nil
+}
+
+// VerifyLoginCode exchanges a valid code for a session. The account is created
+// on first login, and appointments booked without an account under the same
+// phone number are linked to it.
+func (s *AccountService) VerifyLoginCode(ctx context.Context, phone, code string) (*LoginResult, error) {
+	normalized, err := utils.NormalizePhone(phone)
+	if err != nil {
+		return nil, apperrors.NewValidationError("phone must be a valid phone number")
+	}
+	code = strings.TrimSpace(code)
+	if len(code) != loginCodeDigits {
+		return nil, apperrors.NewValidationError(fmt.Sprintf("code must be %d digits", loginCodeDigits))
+	}
+
+	now := s.now().UTC()
+	invalid := apperrors.NewUnauthorizedError("code is invalid or has expired")
+	otp, err := s.otps.GetLatest(ctx, normalized)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, invalid
+		}
+		return nil, err
+	}
+	if otp.ConsumedAt != nil || !now.Before(otp.ExpiresAt) || otp.Attempts >= loginCodeMaxAttempts {
+		return nil, invalid
+	}
+	// Every attempt, the right one included, is counted before the code is
+	// compared, and the count is capped in the database, not on the read
+	// above, so parallel guesses cannot exceed the cap
+	if _, err := s.otps.IncrementAttempts(ctx, otp.ID, loginCodeMaxAttempts); err != nil {
+		if isConflict(err) {
+			return nil, invalid
+		}
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare([]byte(otp.CodeHash), []byte(hashLoginCode(normalized, code))) != 1 {
+		return nil, invalid
+	}
+	if err := s.otps.Consume(ctx, otp.ID, loginCodeMaxAttempts); err != nil {
+		if isConflict(err) {
+			return nil, invalid
+		}
+		return nil, err
+	}
+
+	user, err := s.findOrCreateUser(ctx, normalized)
+	if err != nil {
+		return nil, err
+	}
+	user.LastLoginAt = &now
+	if err := s.users.Update(ctx, user); err != nil {
+		return nil, err
+	}
+
+	linked, err := s.appointments.LinkToUserByPhone(ctx, user.ID, normalized)
+	if err != nil {
+		// Login still succeeds; linking is retried on the next login
+		log.Printf("Warning: Failed to link appointments to user %s: %v", user.ID, err)
+	}
+
+	token, err := generateSessionToken()
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to generate session token", err)
+	}
+	session := &entities.UserSession{
+		UserID:    user.ID,
+		TokenHash: hashSessionToken(token),
+		ExpiresAt: now.Add(sessionTTL),
+		CreatedAt: now,
+	}
+	if err := s.sessions.Create(ctx, session); err != nil {
+		return nil, err
+	}
+
+	return &LoginResult{
+		Token:              token,
+		ExpiresAt:          session.ExpiresAt,
+		User:               user,
+		LinkedAppointments: linked,
+	}, nil
+}
+
+// Authenticate resolves a bearer token to its user.
+func (s *AccountService) Authenticate(ctx context.Context, token string) (*entities.User, error) {
+	session, err := s.activeSession(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.users.GetByID(ctx, session.UserID)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, apperrors.NewUnauthorizedError("session is invalid or has expired")
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+// Logout ends the session for a bearer token.
+func (s *AccountService) Logout(ctx context.Context, token string) error {
+	session, err := s.activeSession(ctx, token)
+	if err != nil {
+		return err
+	}
+	return s.sessions.Delete(ctx, session.ID)
+}
+
+// UpdateProfile changes the user's name and email.
+func (s *AccountService) UpdateProfile(ctx context.Context, user *entities.User, input UpdateProfileInput) (*entities.User, error) {
+	if input.FirstName != nil {
+		name := strings.TrimSpace(*input.FirstName)
+		if utf8.RuneCountInString(name) > maxProfileNameLength {
+			return nil, apperrors.NewValidationError(fmt.Sprintf("first_name cannot exceed %d characters", maxProfileNameLength))
+		}
+		user.FirstName = name
+	}
+	if input.LastName != nil {
+		name := strings.TrimSpace(*input.LastName)
+		if utf8.RuneCountInString(name) > maxProfileNameLength {
+			return nil, apperrors.NewValidationError(fmt.Sprintf("last_name cannot exceed %d characters", maxProfileNameLength))
+		}
+		user.LastName = name
+	}
+	emailChanged := false
+	if input.Email != nil {
+		email := strings.ToLower(strings.TrimSpace(*input.Email))
+		if email != "" {
+			if address, err := mail.ParseAddress(email); err != nil || address.Address != email {
+				return nil, apperrors.NewValidationError("email must be a valid email address")
+			}
+		}
+		emailChanged = email != user.Email
+		user.Email = email
+	}
+
+	if err := s.users.Update(ctx, user); err != nil {
+		return nil, err
+	}
+
+	if emailChanged {
+		s.syncPreferenceContacts(ctx, user)
+	}
+	return user, nil
+}
+
+// ListAppointments returns the user's appointments, most recent first.
+func (s *AccountService) ListAppointments(ctx context.Context, userID string, status entities.AppointmentStatus, limit, offset int) ([]*entities.Appointment, error) {
+	limit, offset = clampPage(limit, offset, defaultHistoryLimit, maxHistoryLimit)
+	appointments, err := s.appointments.ListByUser(ctx, userID, repositories.AppointmentFilter{
+		Status: status,
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if appointments == nil {
+		appointments = []*entities.Appointment{}
+	}
+	return appointments, nil
+}
+
+// ListSavedFacilities returns the user's saved facilities, most recently saved first.
+func (s *AccountService) ListSavedFacilities(ctx context.Context, userID string) ([]*entities.Facility, error) {
+	saved, err := s.saved.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(saved) == 0 {
+		return []*entities.Facility{}, nil
+	}
+
+	ids := make([]string, 0, len(saved))
+	for _, item := range saved {
+		ids = append(ids, item.FacilityID)
+	}
+	facilities, err := s.facilities.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string]*entities.Facility, len(facilities))
+	for _, facility := range facilities {
+		byID[facility.ID] = facility
+	}
+	ordered := make([]*entities.Facility, 0, len(saved))
+	for _, item := range saved {
+		if facility, ok := byID[item.FacilityID]; ok {
+			ordered = append(ordered, facility)
+		}
+	}
+	return ordered, nil
+}
+
+// SaveFacility adds a facility to the user's saved list.
+func (s *AccountService) SaveFacility(ctx context.Context, userID, facilityID string) error {
+	if _, err := s.facilities.GetByID(ctx, facilityID); err != nil {
+		return err
+	}
+	return s.saved.Save(ctx, userID, facilityID)
+}
+
+// RemoveSavedFacility removes a facility from the user's saved list.
+func (s *AccountService) RemoveSavedFacility(ctx context.Context, userID, facilityID string) error {
+	return s.saved.Remove(ctx, userID, facilityID)
+}
+
+// GetNotificationPreferences returns the user's preferences, or the defaults
+// used for patients who have not chosen any.
+func (s *AccountService) GetNotificationPreferences(ctx context.Context, user *entities.User) (*entities.NotificationPreference, error) {
+	prefs, err := s.preferences.GetByUserID(ctx, user.ID)
+	if err == nil {
+		return prefs, nil
+	}
+	if !isNotFound(err) {
+		return nil, err
+	}
+	return defaultNotificationPreferences(user), nil
+}
+
+// UpdateNotificationPreferences changes the user's channel and reminder settings.
+func (s *AccountService) UpdateNotificationPreferences(ctx context.Context, user *entities.User, input NotificationPreferencesInput) (*entities.NotificationPreference, error) {
+	prefs, err := s.GetNotificationPreferences(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	applyBool := func(target *bool, value *bool) {
+		if value != nil {
+			*target = *value
+		}
+	}
+	applyBool(&prefs.WhatsAppEnabled, input.WhatsAppEnabled)
+	applyBool(&prefs.SMSEnabled, input.SMSEnabled)
+	applyBool(&prefs.EmailEnabled, input.EmailEnabled)
+	applyBool(&prefs.Reminder24hEnabled, input.Reminder24hEnabled)
+	applyBool(&prefs.Reminder1hEnabled, input.Reminder1hEnabled)
+
+	if prefs.EmailEnabled && user.Email == "" {
+		return nil, apperrors.NewValidationError("add an email address to your profile before enabling email notifications")
+	}
+
+	setPreferenceContacts(prefs, user)
+	if err := s.preferences.Upsert(ctx, prefs); err != nil {
+		return nil, err
+	}
+	return prefs, nil
+}
+
+func (s *AccountService) findOrCreateUser(ctx context.Context, phone string) (*entities.User, error) {
+	user, err := s.users.GetByPhone(ctx, phone)
+	if err == nil {
+		return user, nil
+	}
+	if !isNotFound(err) {
+		return nil, err
+	}
+
+	user = &entities.User{Phone: phone}
+	if err := s.users.Create(ctx, user); err != nil {
+		// A concurrent first login for the same number created the account
+		if isConflict(err) {
+			return s.users.GetByPhone(ctx, phone)
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+func (s *AccountService) activeSession(ctx context.Context, token string) (*entities.UserSession, error) {
+	invalid := apperrors.NewUnauthorizedError("session is invalid or has expired")
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return nil, apperrors.NewUnauthorizedError("authentication required")
+	}
+	session, err := s.sessions.GetByTokenHash(ctx, hashSessionToken(token))
+	if err != nil {
+		if isNotFound(err) {
+			return nil, invalid
+		}
+		return nil, err
+	}
+	if !s.now().Before(session.ExpiresAt) {
+		return nil, invalid
+	}
+	return session, nil
+}
+
+// syncPreferenceContacts keeps stored preferences pointed at the profile's
+// current email. Failures are logged because the profile update succeeded.
+func (s *AccountService) syncPreferenceContacts(ctx context.Context, user *entities.User) {
+	prefs, err := s.preferences.GetByUserID(ctx, user.ID)
+	if err != nil {
+		return
+	}
+	setPreferenceContacts(prefs, user)
+	if user.Email == "" {
+		prefs.EmailEnabled = false
+	}
+	if err := s.preferences.Upsert(ctx, prefs); err != nil {
+		log.Printf("Warning: Failed to sync notification contacts for user %s: %v", user.ID, err)
+	}
+}
+
+func defaultNotificationPreferences(user *entities.User) *entities.NotificationPreference {
+	prefs := &entities.NotificationPreference{
+		UserID:             user.ID,
+		WhatsAppEnabled:    true,
+		EmailEnabled:       user.Email != "",
+		Reminder24hEnabled: true,
+		Reminder1hEnabled:  true,
+	}
+	setPreferenceContacts(prefs, user)
+	return prefs
+}
+
+func setPreferenceContacts(prefs *entities.NotificationPreference, user *entities.User) {
+	prefs.UserID = user.ID
+	prefs.Phone = nil
+	prefs.Email = nil
+	if user.Phone != "" {
+		phone := user.Phone
+		prefs.Phone = &phone
+	}
+	if user.Email != "" {
+		email := user.Email
+		prefs.Email = &email
+	}
+}
+
+func generateLoginCode() (string, error) {
+	max := big.NewInt(1)
+	for i := 0; i < loginCodeDigits; i++ {
+		max.Mul(max, big.NewInt(10))
+	}
+	n, err := rand.Int(rand.Reader, max)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%0*d", loginCodeDigits, n.Int64()), nil
+}
+
+// hashLoginCode binds the code to the phone number so a leaked hash cannot be
+// replayed against another number.
+func hashLoginCode(phone, code string) string {
+	sum := sha256.Sum256([]byte(phone + ":" + code))
+	return hex.EncodeToString(sum[:])
+}
+
+func generateSessionToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func hashSessionToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,501 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/providers"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/repositories"
+	apperrors "github.com/zatekoja/Patientpricediscoverydesign/backend/pkg/errors"
+)
+
+type stubUserRepo struct {
+	users map[string]*entities.User
+}
+
+func (r *stubUserRepo) Create(ctx context.Context, user *entities.User) error {
+	user.ID = "user-" + user.Phone
+	r.users[user.ID] = user
+	return nil
+}
+
+func (r *stubUserRepo) GetByID(ctx context.Context, id string) (*entities.User, error) {
+	if user, ok := r.users[id]; ok {
+		return user, nil
+	}
+	return nil, apperrors.NewNotFoundError("user not found")
+}
+
+func (r *stubUserRepo) GetByEmail(ctx context.Context, email string) (*entities.User, error) {
+	return nil, apperrors.NewNotFoundError("user not found")
+}
+
+func (r *stubUserRepo) GetByPhone(ctx context.Context, phone string) (*entities.User, error) {
+	for _, user := range r.users {
+		if user.Phone == phone {
+			return user, nil
+		}
+	}
+	return nil, apperrors.NewNotFoundError("user not found")
+}
+
+func (r *stubUserRepo) Update(ctx context.Context, user *entities.User) error {
+	r.users[user.ID] = user
+	return nil
+}
+
+func (r *stubUserRepo) Delete(ctx context.Context, id string) error {
+	delete(r.users, id)
+	return nil
+}
+
+// stubOTPRepo keeps codes in memory and caps attempts under a lock, as the
+// database does. With staleReads every read returns a code as it was issued,
+// as if all reads raced ahead of the writes.
+type stubOTPRepo struct {
+	mu         sync.Mutex
+	codes      []*entities.LoginOTP
+	staleReads bool
+	issued     map[string]entities.LoginOTP
+}
+
+func (r *stubOTPRepo) Create(ctx context.Context, otp *entities.LoginOTP) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	otp.ID = "otp-" + otp.CreatedAt.Format(time.RFC3339Nano)
+	r.codes = append(r.codes, otp)
+	if r.issued == nil {
+		r.issued = map[string]entities.LoginOTP{}
+	}
+	r.issued[otp.ID] = *otp
+	return nil
+}
+
+func (r *stubOTPRepo) GetLatest(ctx context.Context, phone string) (*entities.LoginOTP, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := len(r.codes) - 1; i >= 0; i-- {
+		if r.codes[i].Phone == phone {
+			otp := *r.codes[i]
+			if r.staleReads {
+				otp = r.issued[otp.ID]
+			}
+			return &otp, nil
+		}
+	}
+	return nil, apperrors.NewNotFoundError("no login code")
+}
+
+func (r *stubOTPRepo) CountSince(ctx context.Context, phone string, since time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	count := 0
+	for _, otp := range r.codes {
+		if otp.Phone == phone && !otp.CreatedAt.Before(since) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *stubOTPRepo) IncrementAttempts(ctx context.Context, id string, maxAttempts int) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, otp := range r.codes {
+		if otp.ID == id && otp.ConsumedAt == nil && otp.Attempts < maxAttempts {
+			otp.Attempts++
+			return otp.Attempts, nil
+		}
+	}
+	return 0, apperrors.NewConflictError("used or out of attempts")
+}
+
+func (r *stubOTPRepo) Consume(ctx context.Context, id string, maxAttempts int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, otp := range r.codes {
+		if otp.ID == id {
+			if otp.ConsumedAt != nil || otp.Attempts > maxAttempts {
+				return apperrors.NewConflictError("already used")
+			}
+			now := time.Now()
+			otp.ConsumedAt = &now
+		}
+	}
+	return nil
+}
+
+type stubSessionRepo struct {
+	sessions map[string]*entities.UserSession
+}
+
+func (r *stubSessionRepo) Create(ctx context.Context, session *entities.UserSession) error {
+	session.ID = "session-" + session.TokenHash[:8]
+	r.sessions[session.TokenHash] = session
+	return nil
+}
+
+func (r *stubSessionRepo) GetByTokenHash(ctx context.Context, tokenHash string) (*entities.UserSession, error) {
+	if session, ok := r.sessions[tokenHash]; ok {
+		return session, nil
+	}
+	return nil, apperrors.NewNotFoundError("session not found")
+}
+
+func (r *stubSessionRepo) Delete(ctx context.Context, id string) error {
+	for hash, session := range r.sessions {
+		if session.ID == id {
+			delete(r.sessions, hash)
+		}
+	}
+	return nil
+}
+
+type stubSavedFacilityRepo struct {
+	saved []*entities.SavedFacility
+}
+
+func (r *stubSavedFacilityRepo) Save(ctx context.Context, userID, facilityID string) error {
+	r.saved = append([]*entities.SavedFacility{{UserID: userID, FacilityID: facilityID}}, r.saved...)
+	return nil
+}
+
+func (r *stubSavedFacilityRepo) Remove(ctx context.Context, userID, facilityID string) error {
+	return nil
+}
+
+func (r *stubSavedFacilityRepo) ListByUser(ctx context.Context, userID string) ([]*entities.SavedFacility, error) {
+	return r.saved, nil
+}
+
+type stubPreferenceRepo struct {
+	prefs map[string]*entities.NotificationPreference
+}
+
+func (r *stubPreferenceRepo) GetByUserID(ctx context.Context, userID string) (*entities.NotificationPreference, error) {
+	if prefs, ok := r.prefs[userID]; ok {
+		copied := *prefs
+		return &copied, nil
+	}
+	return nil, apperrors.NewNotFoundError("preferences not found")
+}
+
+func (r *stubPreferenceRepo) Upsert(ctx context.Context, prefs *entities.NotificationPreference) error {
+	r.prefs[prefs.UserID] = prefs
+	return nil
+}
+
+// stubAccountAppointments only implements what the account service uses.
+type stubAccountAppointments struct {
+	repositories.AppointmentRepository
+	linkedPhone string
+	linked      int
+}
+
+func (r *stubAccountAppointments) LinkToUserByPhone(ctx context.Context, userID, phone string) (int, error) {
+	r.linkedPhone = phone
+	return r.linked, nil
+}
+
+// stubAccountFacilities only implements what the account service uses.
+type stubAccountFacilities struct {
+	repositories.FacilityRepository
+	facilities map[string]*entities.Facility
+}
+
+func (r *stubAccountFacilities) GetByID(ctx context.Context, id string) (*entities.Facility, error) {
+	if facility, ok := r.facilities[id]; ok {
+		return facility, nil
+	}
+	return nil, apperrors.NewNotFoundError("facility not found")
+}
+
+func (r *stubAccountFacilities) GetByIDs(ctx context.Context, ids []string) ([]*entities.Facility, error) {
+	var found []*entities.Facility
+	for _, id := range ids {
+		if facility, ok := r.facilities[id]; ok {
+			found = append(found, facility)
+		}
+	}
+	return found, nil
+}
+
+type capturingCodeSender struct {
+	to   string
+	body string
+}
+
+func (s *capturingCodeSender) Channel() entities.NotificationChannel {
+	return entities.ChannelWhatsApp
+}
+
+func (s *capturingCodeSender) Send(ctx context.Context, message providers.NotificationMessage) (string, error) {
+	s.to = message.To
+	s.body = message.Body
+	return "msg-1", nil
+}
+
+var sentCodePattern = regexp.MustCompile(`\b(\d{6})\b`)
+
+func (s *capturingCodeSender) code(t *testing.T) string {
+	t.Helper()
+	match := sentCodePattern.FindStringSubmatch(s.body)
+	require.Len(t, match, 2, "message should contain a 6 digit code: %q", s.body)
+	return match[1]
+}
+
+type accountFixture struct {
+	service      *AccountService
+	users        *stubUserRepo
+	otps         *stubOTPRepo
+	preferences  *stubPreferenceRepo
+	appointments *stubAccountAppointments
+	sender       *capturingCodeSender
+	clock        time.Time
+}
+
+func newAccountFixture() *accountFixture {
+	f := &accountFixture{
+		users:        &stubUserRepo{users: map[string]*entities.User{}},
+		otps:         &stubOTPRepo{},
+		preferences:  &stubPreferenceRepo{prefs: map[string]*entities.NotificationPreference{}},
+		appointments: &stubAccountAppointments{linked: 2},
+		sender:       &capturingCodeSender{},
+		clock:        time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC),
+	}
+	f.service = NewAccountService(
+		f.users,
+		f.otps,
+		&stubSessionRepo{sessions: map[string]*entities.UserSession{}},
+		&stubSavedFacilityRepo{},
+		f.preferences,
+		f.appointments,
+		&stubAccountFacilities{facilities: map[string]*entities.Facility{
+			"fac-1": {ID: "fac-1", Name: "Lagoon Hospital"},
+			"fac-2": {ID: "fac-2", Name: "Reddington"},
+		}},
+	)
+	f.service.SetCodeSender(f.sender)
+	f.service.now = func() time.Time { return f.clock }
+	return f
+}
+
+func (f *accountFixture) login(t *testing.T) *LoginResult {
+	t.Helper()
+	_, err := f.service.RequestLoginCode(context.Background(), "0803 123 4567", "")
+	require.NoError(t, err)
+	result, err := f.service.VerifyLoginCode(context.Background(), "+2348031234567", f.sender.code(t))
+	require.NoError(t, err)
+	return result
+}
+
+func TestAccountService_LoginCreatesAccountAndLinksAppointments(t *testing.T) {
+	f := newAccountFixture()
+
+	sent, err := f.service.RequestLoginCode(context.Background(), "0803 123 4567", "")
+	require.NoError(t, err)
+	assert.Equal(t, "+2348031234567", sent.Phone)
+	assert.Equal(t, entities.ChannelWhatsApp, sent.Channel)
+	assert.Equal(t, "+2348031234567", f.sender.to)
+	assert.NotContains(t, f.otps.codes[0].CodeHash, f.sender.code(t), "only a hash of the code is stored")
+
+	result, err := f.service.VerifyLoginCode(context.Background(), "08031234567", f.sender.code(t))
+	require.NoError(t, err)
+
+	assert.NotEmpty(t, result.Token)
+	assert.Equal(t, "+2348031234567", result.User.Phone)
+	require.NotNil(t, result.User.LastLoginAt)
+	assert.Equal(t, 2, result.LinkedAppointments)
+	assert.Equal(t, "+2348031234567", f.appointments.linkedPhone)
+
+	user, err := f.service.Authenticate(context.Background(), result.Token)
+	require.NoError(t, err)
+	assert.Equal(t, result.User.ID, user.ID)
+}
+
+func TestAccountService_VerifyRejectsWrongReusedAndExpiredCodes(t *testing.T) {
+	f := newAccountFixture()
+	_, err := f.service.RequestLoginCode(context.Background(), "08031234567", "")
+	require.NoError(t, err)
+	code := f.sender.code(t)
+
+	wrong := "000000"
+	if code == wrong {
+		wrong = "111111"
+	}
+	_, err = f.service.VerifyLoginCode(context.Background(), "08031234567", wrong)
+	requireAppErrorType(t, err, apperrors.ErrorTypeUnauthorized, "wrong code")
+	assert.Equal(t, 1, f.otps.codes[0].Attempts)
+
+	_, err = f.service.VerifyLoginCode(context.Background(), "08031234567", code)
+	require.NoError(t, err)
+
+	_, err = f.service.VerifyLoginCode(context.Background(), "08031234567", code)
+	requireAppErrorType(t, err, apperrors.ErrorTypeUnauthorized, "reused code")
+
+	f.clock = f.clock.Add(2 * time.Minute)
+	_, err = f.service.RequestLoginCode(context.Background(), "08031234567", "")
+	require.NoError(t, err)
+	f.clock = f.clock.Add(loginCodeTTL)
+	_, err = f.service.VerifyLoginCode(context.Background(), "08031234567", f.sender.code(t))
+	requireAppErrorType(t, err, apperrors.ErrorTypeUnauthorized, "expired code")
+}
+
+func TestAccountService_VerifyLocksCodeAfterMaxAttempts(t *testing.T) {
+	f := newAccountFixture()
+	_, err := f.service.RequestLoginCode(context.Background(), "08031234567", "")
+	require.NoError(t, err)
+	f.otps.codes[0].Attempts = loginCodeMaxAttempts
+
+	_, err = f.service.VerifyLoginCode(context.Background(), "08031234567", f.sender.code(t))
+	requireAppErrorType(t, err, apperrors.ErrorTypeUnauthorized, "locked code")
+}
+
+func TestAccountService_VerifyCapsConcurrentWrongGuesses(t *testing.T) {
+	f := newAccountFixture()
+	_, err := f.service.RequestLoginCode(context.Background(), "08031234567", "")
+	require.NoError(t, err)
+	code := f.sender.code(t)
+	f.otps.staleReads = true
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4*loginCodeMaxAttempts; i++ {
+		wrong := fmt.Sprintf("%06d", i)
+		if wrong == code {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := f.service.VerifyLoginCode(context.Background(), "08031234567", wrong)
+			assert.Error(t, err)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, loginCodeMaxAttempts, f.otps.codes[0].Attempts)
+	_, err = f.service.VerifyLoginCode(context.Background(), "08031234567", code)
+	requireAppErrorType(t, err, apperrors.ErrorTypeUnauthorized, "code out of attempts")
+}
+
+func TestAccountService_RequestLoginCodeRateLimits(t *testing.T) {
+	f := newAccountFixture()
+	ctx := context.Background()
+
+	_, err := f.service.RequestLoginCode(ctx, "08031234567", "")
+	require.NoError(t, err)
+	_, err = f.service.RequestLoginCode(ctx, "08031234567", "")
+	requireAppErrorType(t, err, apperrors.ErrorTypeRateLimited, "resend within a minute")
+
+	for i := 1; i < loginCodeHourlyLimit; i++ {
+		f.clock = f.clock.Add(2 * time.Minute)
+		_, err = f.service.RequestLoginCode(ctx, "08031234567", "")
+		require.NoError(t, err)
+	}
+	f.clock = f.clock.Add(2 * time.Minute)
+	_, err = f.service.RequestLoginCode(ctx, "08031234567", "")
+	requireAppErrorType(t, err, apperrors.ErrorTypeRateLimited, "hourly limit")
+
+	_, err = f.service.RequestLoginCode(ctx, "08031234567", entities.ChannelSMS)
+	requireAppErrorType(t, err, apperrors.ErrorTypeValidation, "sms sender not configured")
+
+	_, err = f.service.RequestLoginCode(ctx, "12345", "")
+	requireAppErrorType(t, err, apperrors.ErrorTypeValidation, "invalid phone")
+}
+
+func TestAccountService_AuthenticateRejectsExpiredAndLoggedOutSessions(t *testing.T) {
+	f := newAccountFixture()
+	result := f.login(t)
+	ctx := context.Background()
+
+	_, err := f.service.Authenticate(ctx, "")
+	requireAppErrorType(t, err, apperrors.ErrorTypeUnauthorized, "missing token")
+	_, err = f.service.Authenticate(ctx, "not-a-token")
+	requireAppErrorType(t, err, apperrors.ErrorTypeUnauthorized, "unknown token")
+
+	f.clock = f.clock.Add(sessionTTL)
+	_, err = f.service.Authenticate(ctx, result.Token)
+	requireAppErrorType(t, err, apperrors.ErrorTypeUnauthorized, "expired session")
+
+	f.clock = f.clock.Add(-time.Hour)
+	require.NoError(t, f.service.Logout(ctx, result.Token))
+	_, err = f.service.Authenticate(ctx, result.Token)
+	requireAppErrorType(t, err, apperrors.ErrorTypeUnauthorized, "logged out session")
+}
+
+func TestAccountService_UpdateProfile(t *testing.T) {
+	f := newAccountFixture()
+	user := f.login(t).User
+	ctx := context.Background()
+
+	first, email := "  Ada ", " Ada@Example.com "
+	updated, err := f.service.UpdateProfile(ctx, user, UpdateProfileInput{FirstName: &first, Email: &email})
+	require.NoError(t, err)
+	assert.Equal(t, "Ada", updated.FirstName)
+	assert.Equal(t, "ada@example.com", updated.Email)
+
+	invalid := "not-an-email"
+	_, err = f.service.UpdateProfile(ctx, user, UpdateProfileInput{Email: &invalid})
+	requireAppErrorType(t, err, apperrors.ErrorTypeValidation, "invalid email")
+}
+
+func TestAccountService_NotificationPreferences(t *testing.T) {
+	f := newAccountFixture()
+	user := f.login(t).User
+	ctx := context.Background()
+
+	defaults, err := f.service.GetNotificationPreferences(ctx, user)
+	require.NoError(t, err)
+	assert.True(t, defaults.WhatsAppEnabled)
+	assert.False(t, defaults.EmailEnabled, "email is off until the profile has an address")
+	assert.True(t, defaults.Reminder24hEnabled)
+
+	enable, disable := true, false
+	_, err = f.service.UpdateNotificationPreferences(ctx, user, NotificationPreferencesInput{EmailEnabled: &enable})
+	requireAppErrorType(t, err, apperrors.ErrorTypeValidation, "email without address")
+
+	prefs, err := f.service.UpdateNotificationPreferences(ctx, user, NotificationPreferencesInput{
+		SMSEnabled:        &enable,
+		Reminder1hEnabled: &disable,
+	})
+	require.NoError(t, err)
+	assert.True(t, prefs.SMSEnabled)
+	assert.False(t, prefs.Reminder1hEnabled)
+	assert.True(t, prefs.WhatsAppEnabled, "unset fields are unchanged")
+	require.NotNil(t, prefs.Phone)
+	assert.Equal(t, "+2348031234567", *prefs.Phone)
+
+	stored := f.preferences.prefs[user.ID]
+	require.NotNil(t, stored)
+	assert.Equal(t, user.ID, stored.UserID)
+
+	email := "ada@example.com"
+	_, err = f.service.UpdateProfile(ctx, user, UpdateProfileInput{Email: &email})
+	require.NoError(t, err)
+	require.NotNil(t, f.preferences.prefs[user.ID].Email)
+	assert.Equal(t, email, *f.preferences.prefs[user.ID].Email)
+}
+
+func TestAccountService_SavedFacilities(t *testing.T) {
+	f := newAccountFixture()
+	user := f.login(t).User
+	ctx := context.Background()
+
+	require.NoError(t, f.service.SaveFacility(ctx, user.ID, "fac-1"))
+	require.NoError(t, f.service.SaveFacility(ctx, user.ID, "fac-2"))
+	err := f.service.SaveFacility(ctx, user.ID, "missing")
+	requireAppErrorType(t, err, apperrors.ErrorTypeNotFound, "unknown facility")
+
+	facilities, err := f.service.ListSavedFacilities(ctx, user.ID)
+	require.NoError(t, err)
+	require.Len(t, facilities, 2)
+	assert.Equal(t, "fac-2", facilities[0].ID, "most recently saved first")
+	assert.Equal(t, "fac-1", facilities[1].ID)
+}
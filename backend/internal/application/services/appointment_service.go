@@ -2,7 +2,9 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"path"
 	"strings"
 	"time"
 
@@ -21,6 +23,8 @@ type AppointmentService struct {
 	provider               providers.AppointmentProvider
 	allowMissingExternalID bool
 	notificationService    *NotificationService
+	resolver               providers.AppointmentProviderResolver
+	audit                  *AuditService
 }
 
 // NewAppointmentService creates a new appointment service
@@ -42,10 +46,22 @@ func NewAppointmentService(
 	}
 }
 
+// SetProviderResolver routes each facility to its own scheduling provider,
+// so facilities with a native calendar do not go through Calendly.
+func (s *AppointmentService) SetProviderResolver(resolver providers.AppointmentProviderResolver) {
+	s.resolver = resolver
+}
+
+// SetAuditLog records operator completions in the audit log.
+func (s *AppointmentService) SetAuditLog(audit *AuditService) {
+	s.audit = audit
+}
+
 // BookAppointment books an appointment
 func (s *AppointmentService) BookAppointment(ctx context.Context, appointment *entities.Appointment) error {
-	// 1. Validate appointment (e.g., check if time is in future)
-	if appointment.ScheduledAt.Before(time.Now()) {
+	// 1. Validate appointment (e.g., check if time is in future).
+	// Slot and hold bookings take their time from the slot instead.
+	if appointment.SlotID == "" && appointment.HoldID == "" && appointment.ScheduledAt.Before(time.Now()) {
 		return fmt.Errorf("cannot book appointment in the past")
 	}
 
@@ -61,14 +77,20 @@ func (s *AppointmentService) BookAppointment(ctx context.Context, appointment *e
 		return fmt.Errorf("facility not found")
 	}
 
-	externalID := strings.TrimSpace(facility.SchedulingExternalID)
-	if externalID == "" {
+	target, err := s.schedulingTarget(ctx, facility)
+	if err != nil {
+		return fmt.Errorf("failed to resolve scheduling provider: %w", err)
+	}
+	native := target.Method == entities.BookingMethodNative
+	if target.ExternalID == "" {
 		return fmt.Errorf("facility has no scheduling external id configured")
 	}
-	appointment.SchedulingExternalID = externalID
+	if !native {
+		appointment.SchedulingExternalID = target.ExternalID
+	}
 
-	// 2. Call external provider to book slot
-	providerExternalID, link, err := s.provider.CreateAppointment(ctx, appointment)
+	// 2. Call provider to book slot
+	providerExternalID, link, err := target.Provider.CreateAppointment(ctx, appointment)
 	if err != nil {
 		return fmt.Errorf("failed to book with provider: %w", err)
 	}
@@ -77,14 +99,21 @@ func (s *AppointmentService) BookAppointment(ctx context.Context, appointment *e
 	if appointment.ID == "" {
 		appointment.ID = uuid.New().String()
 	}
-	// Booking is pending until Calendly webhook (invitee.created) confirms attendance.
-	appointment.Status = entities.AppointmentStatusPending
-	appointment.BookingMethod = entities.BookingMethodAPI
-	if providerExternalID != "" {
-		appointment.CalendlyEventID = &providerExternalID
-	}
-	if link != "" {
-		appointment.MeetingLink = &link
+	if native {
+		// The slot is reserved under a row lock, so native bookings are confirmed immediately.
+		appointment.Status = entities.AppointmentStatusConfirmed
+		appointment.BookingMethod = entities.BookingMethodNative
+		appointment.SlotReservationID = &providerExternalID
+	} else {
+		// Booking is pending until Calendly webhook (invitee.created) confirms attendance.
+		appointment.Status = entities.AppointmentStatusPending
+		appointment.BookingMethod = entities.BookingMethodAPI
+		if providerExternalID != "" {
+			appointment.CalendlyEventID = &providerExternalID
+		}
+		if link != "" {
+			appointment.MeetingLink = &link
+		}
 	}
 
 	appointment.CreatedAt = time.Now()
@@ -92,12 +121,17 @@ func (s *AppointmentService) BookAppointment(ctx context.Context, appointment *e
 
 	// 4. Save to repository
 	if err := s.repo.Create(ctx, appointment); err != nil {
-		// Rollback external booking if DB fails? (Advanced: Saga pattern)
-		// For now, return error
+		if native {
+			// Give the slot back so a failed save does not leak capacity.
+			_ = target.Provider.CancelAppointment(ctx, providerExternalID, "appointment could not be saved")
+		}
 		return fmt.Errorf("failed to save appointment: %w", err)
 	}
 
-	// NOTE: confirmation notification is sent by Calendly webhook handler after invitee.created.
+	// NOTE: Calendly bookings are confirmed by the webhook handler after invitee.created.
+	if native {
+		s.sendNativeConfirmation(ctx, appointment, facility)
+	}
 
 	return nil
 }
@@ -110,20 +144,192 @@ func (s *AppointmentService) GetAvailableSlots(ctx context.Context, facilityID s
 
 	facility, err := s.facilityRepo.GetByID(ctx, facilityID)
 	if err != nil {
-		if appErr, ok := err.(*apperrors.AppError); ok && appErr.Type == apperrors.ErrorTypeNotFound {
+		var appErr *apperrors.AppError
+		if errors.As(err, &appErr) && appErr.Type == apperrors.ErrorTypeNotFound {
 			return nil, err
 		}
 		return nil, apperrors.NewNotFoundError("facility not found")
 	}
 
-	externalID := strings.TrimSpace(facility.SchedulingExternalID)
-	if externalID == "" && !s.allowMissingExternalID {
+	target, err := s.schedulingTarget(ctx, facility)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to resolve scheduling provider", err)
+	}
+	if target.ExternalID == "" && !s.allowMissingExternalID {
 		return nil, apperrors.NewValidationError("facility has no scheduling external id configured")
 	}
 
-	slots, err := s.provider.GetAvailableSlots(ctx, externalID, from, to)
+	slots, err := target.Provider.GetAvailableSlots(ctx, target.ExternalID, from, to)
 	if err != nil {
+		// Native calendars report their own validation and not-found errors.
+		var appErr *apperrors.AppError
+		if errors.As(err, &appErr) && target.Method == entities.BookingMethodNative {
+			return nil, appErr
+		}
 		return nil, apperrors.NewExternalError("failed to fetch availability", err)
 	}
 	return slots, nil
 }
+
+// CancelAppointment cancels a booking on behalf of the patient, who proves it is
+// theirs with the email it was booked under. The booking is released with the
+// scheduling provider first, so a cancelled appointment never holds a slot.
+// Cancelling an already cancelled appointment is a no-op.
+func (s *AppointmentService) CancelAppointment(ctx context.Context, id, patientEmail, reason string) (*entities.Appointment, error) {
+	patientEmail = strings.TrimSpace(patientEmail)
+	if patientEmail == "" {
+		return nil, apperrors.NewValidationError("patient_email is required")
+	}
+
+	appointment, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	// A wrong email reads as not found so appointment IDs cannot be probed
+	if !strings.EqualFold(strings.TrimSpace(appointment.PatientEmail), patientEmail) {
+		return nil, apperrors.NewNotFoundError(fmt.Sprintf("appointment with id %s not found", id))
+	}
+
+	switch appointment.Status {
+	case entities.AppointmentStatusCancelled:
+		return appointment, nil
+	case entities.AppointmentStatusCompleted:
+		return nil, apperrors.NewConflictError("a completed appointment cannot be cancelled")
+	}
+
+	if err := s.cancelWithProvider(ctx, appointment, reason); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Cancel(ctx, appointment.ID); err != nil {
+		return nil, err
+	}
+	appointment.Status = entities.AppointmentStatusCancelled
+	appointment.UpdatedAt = time.Now()
+
+	s.sendCancellationNotice(ctx, appointment)
+	return appointment, nil
+}
+
+// CompleteAppointment marks a confirmed appointment as attended once its
+// scheduled time has passed, which is what lets the patient review the visit.
+// Completing an already completed appointment is a no-op.
+func (s *AppointmentService) CompleteAppointment(ctx context.Context, id string) (*entities.Appointment, error) {
+	appointment, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	switch appointment.Status {
+	case entities.AppointmentStatusCompleted:
+		return appointment, nil
+	case entities.AppointmentStatusConfirmed:
+	default:
+		return nil, apperrors.NewConflictError(fmt.Sprintf("a %s appointment cannot be completed", appointment.Status))
+	}
+	if appointment.ScheduledAt.After(time.Now()) {
+		return nil, apperrors.NewValidationError("an appointment cannot be completed before its scheduled time")
+	}
+
+	before := *appointment
+	appointment.Status = entities.AppointmentStatusCompleted
+	err = s.audit.Transact(ctx, func(ctx context.Context) (*AuditEvent, error) {
+		if err = s.repo.Update(ctx, appointment); err != nil {
+			return nil, err
+		}
+		return &AuditEvent{
+			Action:     "appointment.complete",
+			EntityType: "appointment",
+			EntityID:   appointment.ID,
+			FacilityID: appointment.FacilityID,
+			Before:     &before,
+			After:      appointment,
+		}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return appointment, nil
+}
+
+// cancelWithProvider releases the booking with whichever provider holds it. A
+// Calendly booking still pending its webhook has no scheduled event to cancel.
+func (s *AppointmentService) cancelWithProvider(ctx context.Context, appointment *entities.Appointment, reason string) error {
+	var externalID string
+	switch {
+	case appointment.BookingMethod == entities.BookingMethodNative && appointment.SlotReservationID != nil:
+		externalID = *appointment.SlotReservationID
+	case appointment.CalendlyEventURI != nil:
+		externalID = path.Base(*appointment.CalendlyEventURI)
+	}
+	if externalID == "" {
+		return nil
+	}
+
+	if s.facilityRepo == nil {
+		return apperrors.NewInternalError("facility repository not configured", nil)
+	}
+	facility, err := s.facilityRepo.GetByID(ctx, appointment.FacilityID)
+	if err != nil {
+		return err
+	}
+	target, err := s.schedulingTarget(ctx, facility)
+	if err != nil {
+		return apperrors.NewInternalError("failed to resolve scheduling provider", err)
+	}
+	native := appointment.BookingMethod == entities.BookingMethodNative
+	if native != (target.Method == entities.BookingMethodNative) {
+		return apperrors.NewConflictError("the facility's scheduling provider changed since booking; contact the facility to cancel")
+	}
+
+	if reason == "" {
+		reason = "cancelled by patient"
+	}
+	if err := target.Provider.CancelAppointment(ctx, externalID, reason); err != nil {
+		var appErr *apperrors.AppError
+		if native && errors.As(err, &appErr) {
+			return appErr
+		}
+		return apperrors.NewExternalError("failed to cancel with scheduling provider", err)
+	}
+	return nil
+}
+
+// schedulingTarget resolves the facility's provider, defaulting to the configured one.
+func (s *AppointmentService) schedulingTarget(ctx context.Context, facility *entities.Facility) (*providers.SchedulingTarget, error) {
+	if s.resolver != nil {
+		return s.resolver.Resolve(ctx, facility)
+	}
+	return &providers.SchedulingTarget{
+		Provider:   s.provider,
+		ExternalID: strings.TrimSpace(facility.SchedulingExternalID),
+		Method:     entities.BookingMethodAPI,
+	}, nil
+}
+
+// sendCancellationNotice sends a best-effort cancellation notice; the cancellation stands if it fails.
+func (s *AppointmentService) sendCancellationNotice(ctx context.Context, appointment *entities.Appointment) {
+	if s.notificationService == nil || s.procedureRepo == nil || s.facilityRepo == nil {
+		return
+	}
+	facility, err := s.facilityRepo.GetByID(ctx, appointment.FacilityID)
+	if err != nil || facility == nil {
+		return
+	}
+	procedure, err := s.procedureRepo.GetByID(ctx, appointment.ProcedureID)
+	if err != nil || procedure == nil {
+		return
+	}
+	_ = s.notificationService.SendCancellationNotice(ctx, appointment, facility, procedure)
+}
+
+// sendNativeConfirmation sends a best-effort booking confirmation; the booking stands if it fails.
+func (s *AppointmentService) sendNativeConfirmation(ctx context.Context, appointment *entities.Appointment, facility *entities.Facility) {
+	if s.notificationService == nil || s.procedureRepo == nil {
+		return
+	}
+	procedure, err := s.procedureRepo.GetByID(ctx, appointment.ProcedureID)
+	if err != nil || procedure == nil {
+		return
+	}
+	_ = s.notificationService.SendBookingConfirmation(ctx, appointment, facility, procedure)
+}
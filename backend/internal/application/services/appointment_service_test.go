@@ -10,7 +10,9 @@ import (
 	"github.com/stretchr/testify/mock"
 	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/application/services"
 	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/providers"
 	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/repositories"
+	apperrors "github.com/zatekoja/Patientpricediscoverydesign/backend/pkg/errors"
 )
 
 // Mocks
@@ -37,7 +39,8 @@ func (m *MockAppointmentRepository) Update(ctx context.Context, appointment *ent
 	return nil
 }
 func (m *MockAppointmentRepository) Cancel(ctx context.Context, id string) error {
-	return nil
+	args := m.Called(ctx, id)
+	return args.Error(0)
 }
 func (m *MockAppointmentRepository) ListByUser(ctx context.Context, userID string, filter repositories.AppointmentFilter) ([]*entities.Appointment, error) {
 	return nil, nil
@@ -46,6 +49,10 @@ func (m *MockAppointmentRepository) ListByFacility(ctx context.Context, facility
 	return nil, nil
 }
 
+func (m *MockAppointmentRepository) LinkToUserByPhone(ctx context.Context, userID, phone string) (int, error) {
+	return 0, nil
+}
+
 type MockAppointmentProvider struct {
 	mock.Mock
 }
@@ -241,3 +248,186 @@ func TestAppointmentService_BookAppointment(t *testing.T) {
 		facilityRepo.AssertExpectations(t)
 	})
 }
+
+type staticResolver struct {
+	target *providers.SchedulingTarget
+}
+
+func (r *staticResolver) Resolve(ctx context.Context, facility *entities.Facility) (*providers.SchedulingTarget, error) {
+	return r.target, nil
+}
+
+func TestAppointmentService_BookNativeSlot(t *testing.T) {
+	t.Run("confirms immediately with the slot reservation", func(t *testing.T) {
+		repo := new(MockAppointmentRepository)
+		facilityRepo := new(MockFacilityRepository)
+		native := new(MockAppointmentProvider)
+		service := services.NewAppointmentService(repo, facilityRepo, nil, new(MockAppointmentProvider), false, nil)
+		service.SetProviderResolver(&staticResolver{target: &providers.SchedulingTarget{
+			Provider: native, ExternalID: "facility-1", Method: entities.BookingMethodNative,
+		}})
+
+		// Slot bookings take their time from the slot, so no ScheduledAt is needed.
+		appointment := &entities.Appointment{FacilityID: "facility-1", SlotID: "slot-1", PatientName: "Ada"}
+		facilityRepo.On("GetByID", mock.Anything, "facility-1").Return(&entities.Facility{ID: "facility-1"}, nil)
+		native.On("CreateAppointment", mock.Anything, appointment).Return("booking-1", "", nil)
+		repo.On("Create", mock.Anything, mock.MatchedBy(func(a *entities.Appointment) bool {
+			return a.Status == entities.AppointmentStatusConfirmed &&
+				a.BookingMethod == entities.BookingMethodNative &&
+				a.SlotReservationID != nil && *a.SlotReservationID == "booking-1" &&
+				a.CalendlyEventID == nil
+		})).Return(nil)
+
+		assert.NoError(t, service.BookAppointment(context.Background(), appointment))
+		repo.AssertExpectations(t)
+		native.AssertExpectations(t)
+	})
+
+	t.Run("releases the slot when the appointment cannot be saved", func(t *testing.T) {
+		repo := new(MockAppointmentRepository)
+		facilityRepo := new(MockFacilityRepository)
+		native := new(MockAppointmentProvider)
+		service := services.NewAppointmentService(repo, facilityRepo, nil, new(MockAppointmentProvider), false, nil)
+		service.SetProviderResolver(&staticResolver{target: &providers.SchedulingTarget{
+			Provider: native, ExternalID: "facility-1", Method: entities.BookingMethodNative,
+		}})
+
+		appointment := &entities.Appointment{FacilityID: "facility-1", HoldID: "hold-1"}
+		facilityRepo.On("GetByID", mock.Anything, "facility-1").Return(&entities.Facility{ID: "facility-1"}, nil)
+		native.On("CreateAppointment", mock.Anything, appointment).Return("hold-1", "", nil)
+		native.On("CancelAppointment", mock.Anything, "hold-1", mock.Anything).Return(nil)
+		repo.On("Create", mock.Anything, mock.Anything).Return(errors.New("db down"))
+
+		assert.Error(t, service.BookAppointment(context.Background(), appointment))
+		native.AssertExpectations(t)
+	})
+}
+
+func TestAppointmentService_CancelAppointment(t *testing.T) {
+	t.Run("releases the native slot and cancels", func(t *testing.T) {
+		repo := new(MockAppointmentRepository)
+		facilityRepo := new(MockFacilityRepository)
+		native := new(MockAppointmentProvider)
+		service := services.NewAppointmentService(repo, facilityRepo, nil, new(MockAppointmentProvider), false, nil)
+		service.SetProviderResolver(&staticResolver{target: &providers.SchedulingTarget{
+			Provider: native, ExternalID: "facility-1", Method: entities.BookingMethodNative,
+		}})
+
+		reservation := "booking-1"
+		repo.On("GetByID", mock.Anything, "appt-1").Return(&entities.Appointment{
+			ID: "appt-1", FacilityID: "facility-1", PatientEmail: "ada@example.com",
+			Status: entities.AppointmentStatusConfirmed, BookingMethod: entities.BookingMethodNative,
+			SlotReservationID: &reservation,
+		}, nil)
+		facilityRepo.On("GetByID", mock.Anything, "facility-1").Return(&entities.Facility{ID: "facility-1"}, nil)
+		native.On("CancelAppointment", mock.Anything, "booking-1", "feeling better").Return(nil)
+		repo.On("Cancel", mock.Anything, "appt-1").Return(nil)
+
+		appointment, err := service.CancelAppointment(context.Background(), "appt-1", " ADA@example.com ", "feeling better")
+		assert.NoError(t, err)
+		assert.Equal(t, entities.AppointmentStatusCancelled, appointment.Status)
+		repo.AssertExpectations(t)
+		native.AssertExpectations(t)
+	})
+
+	t.Run("cancels the scheduled Calendly event", func(t *testing.T) {
+		repo := new(MockAppointmentRepository)
+		facilityRepo := new(MockFacilityRepository)
+		provider := new(MockAppointmentProvider)
+		service := services.NewAppointmentService(repo, facilityRepo, nil, provider, false, nil)
+
+		uri := "https://api.calendly.com/scheduled_events/evt-9"
+		repo.On("GetByID", mock.Anything, "appt-1").Return(&entities.Appointment{
+			ID: "appt-1", FacilityID: "facility-1", PatientEmail: "ada@example.com",
+			Status: entities.AppointmentStatusConfirmed, BookingMethod: entities.BookingMethodCalendly,
+			CalendlyEventURI: &uri,
+		}, nil)
+		facilityRepo.On("GetByID", mock.Anything, "facility-1").Return(&entities.Facility{ID: "facility-1"}, nil)
+		provider.On("CancelAppointment", mock.Anything, "evt-9", "cancelled by patient").Return(nil)
+		repo.On("Cancel", mock.Anything, "appt-1").Return(nil)
+
+		_, err := service.CancelAppointment(context.Background(), "appt-1", "ada@example.com", "")
+		assert.NoError(t, err)
+		provider.AssertExpectations(t)
+	})
+
+	t.Run("keeps the appointment when the provider fails", func(t *testing.T) {
+		repo := new(MockAppointmentRepository)
+		facilityRepo := new(MockFacilityRepository)
+		provider := new(MockAppointmentProvider)
+		service := services.NewAppointmentService(repo, facilityRepo, nil, provider, false, nil)
+
+		uri := "https://api.calendly.com/scheduled_events/evt-9"
+		repo.On("GetByID", mock.Anything, "appt-1").Return(&entities.Appointment{
+			ID: "appt-1", FacilityID: "facility-1", PatientEmail: "ada@example.com",
+			Status: entities.AppointmentStatusConfirmed, CalendlyEventURI: &uri,
+		}, nil)
+		facilityRepo.On("GetByID", mock.Anything, "facility-1").Return(&entities.Facility{ID: "facility-1"}, nil)
+		provider.On("CancelAppointment", mock.Anything, "evt-9", mock.Anything).Return(errors.New("calendly down"))
+
+		_, err := service.CancelAppointment(context.Background(), "appt-1", "ada@example.com", "")
+		var appErr *apperrors.AppError
+		assert.True(t, errors.As(err, &appErr))
+		assert.Equal(t, apperrors.ErrorTypeExternal, appErr.Type)
+		repo.AssertNotCalled(t, "Cancel", mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejects a different email and completed visits", func(t *testing.T) {
+		repo := new(MockAppointmentRepository)
+		service := services.NewAppointmentService(repo, new(MockFacilityRepository), nil, new(MockAppointmentProvider), false, nil)
+		repo.On("GetByID", mock.Anything, "appt-1").Return(&entities.Appointment{
+			ID: "appt-1", PatientEmail: "ada@example.com", Status: entities.AppointmentStatusPending,
+		}, nil)
+		repo.On("GetByID", mock.Anything, "appt-2").Return(&entities.Appointment{
+			ID: "appt-2", PatientEmail: "ada@example.com", Status: entities.AppointmentStatusCompleted,
+		}, nil)
+
+		var appErr *apperrors.AppError
+		_, err := service.CancelAppointment(context.Background(), "appt-1", "eve@example.com", "")
+		assert.True(t, errors.As(err, &appErr))
+		assert.Equal(t, apperrors.ErrorTypeNotFound, appErr.Type)
+
+		_, err = service.CancelAppointment(context.Background(), "appt-2", "ada@example.com", "")
+		assert.True(t, errors.As(err, &appErr))
+		assert.Equal(t, apperrors.ErrorTypeConflict, appErr.Type)
+
+		_, err = service.CancelAppointment(context.Background(), "appt-1", "", "")
+		assert.True(t, errors.As(err, &appErr))
+		assert.Equal(t, apperrors.ErrorTypeValidation, appErr.Type)
+		repo.AssertNotCalled(t, "Cancel", mock.Anything, mock.Anything)
+	})
+}
+
+func TestAppointmentService_CompleteAppointment(t *testing.T) {
+	tests := map[string]struct {
+		appointment *entities.Appointment
+		want        apperrors.ErrorType
+	}{
+		"pending": {
+			appointment: &entities.Appointment{ID: "appt-1", Status: entities.AppointmentStatusPending, ScheduledAt: time.Now().Add(-time.Hour)},
+			want:        apperrors.ErrorTypeConflict,
+		},
+		"cancelled": {
+			appointment: &entities.Appointment{ID: "appt-1", Status: entities.AppointmentStatusCancelled, ScheduledAt: time.Now().Add(-time.Hour)},
+			want:        apperrors.ErrorTypeConflict,
+		},
+		"not yet started": {
+			appointment: &entities.Appointment{ID: "appt-1", Status: entities.AppointmentStatusConfirmed, ScheduledAt: time.Now().Add(time.Hour)},
+			want:        apperrors.ErrorTypeValidation,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			repo := new(MockAppointmentRepository)
+			service := services.NewAppointmentService(repo, new(MockFacilityRepository), nil, new(MockAppointmentProvider), false, nil)
+			repo.On("GetByID", mock.Anything, "appt-1").Return(tt.appointment, nil)
+
+			_, err := service.CompleteAppointment(context.Background(), "appt-1")
+			var appErr *apperrors.AppError
+			if assert.True(t, errors.As(err, &appErr)) {
+				assert.Equal(t, tt.want, appErr.Type)
+			}
+		})
+	}
+}
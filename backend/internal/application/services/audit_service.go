@@ -0,0 +1,291 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"reflect"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/repositories"
+	apperrors "github.com/zatekoja/Patientpricediscoverydesign/backend/pkg/errors"
+)
+
+const (
+	defaultAuditLimit = 50
+	maxAuditLimit     = 500
+	// auditVerifyBatchSize is how many entries VerifyChain loads at a time
+	auditVerifyBatchSize = 500
+)
+
+// auditIgnoredFields change on every write and would drown out the real changes
+var auditIgnoredFields = map[string]bool{
+	"updated_at":   true,
+	"last_updated": true,
+	"version":      true,
+	// Capacity is re-reported without changing; only the values are audited
+	"capacity_status_reported_at": true,
+	"avg_wait_reported_at":        true,
+	"urgent_care_reported_at":     true,
+}
+
+// AuditService records operator and admin mutations in the hash-chained audit log.
+type AuditService struct {
+	repo repositories.AuditRepository
+	tx   repositories.Transactor
+	now  func() time.Time
+}
+
+// NewAuditService creates a new audit service
+func NewAuditService(repo repositories.AuditRepository) *AuditService {
+	return &AuditService{
+		repo: repo,
+		now:  time.Now,
+	}
+}
+
+// SetTransactor lets Transact write the audit entry in the same transaction
+// as the change it records
+func (s *AuditService) SetTransactor(tx repositories.Transactor) {
+	s.tx = tx
+}
+
+// AuditEvent describes a mutation to audit. Before and After are the entity's
+// state around the change (nil for creates and deletes).
+type AuditEvent struct {
+	Action     string
+	EntityType string
+	EntityID   string
+	FacilityID string
+	Before     interface{}
+	After      interface{}
+}
+
+// Transact runs change and appends the audit entry for the event it returns
+// in one transaction, so a change is committed if and only if its entry is.
+// change must write through repositories using the ctx it is given, and
+// returns a nil event when there is nothing to audit. Without a transactor
+// the entry is appended after the change, as Record does. On a nil service
+// change simply runs.
+func (s *AuditService) Transact(ctx context.Context, change func(ctx context.Context) (*AuditEvent, error)) error {
+	if s == nil {
+		_, err := change(ctx)
+		return err
+	}
+	if s.tx == nil {
+		event, err := change(ctx)
+		if err != nil {
+			return err
+		}
+		if event != nil {
+			s.Record(ctx, event.Action, event.EntityType, event.EntityID, event.FacilityID, event.Before, event.After)
+		}
+		return nil
+	}
+
+	return s.tx.InTx(ctx, func(ctx context.Context) error {
+		event, err := change(ctx)
+		if err != nil || event == nil {
+			return err
+		}
+		entry := s.entry(ctx, event)
+		if entry == nil {
+			return nil
+		}
+		return s.repo.Append(ctx, entry)
+	})
+}
+
+// Record appends an audit entry for a completed mutation that is not a
+// database write, such as a triggered sync. Only the fields that differ
+// between before and after are stored, and updates that change nothing are not
+// recorded. The actor, request ID and IP come from the request's audit
+// context. Record is a no-op on a nil service, and a failed append is logged
+// rather than failing a mutation that has already happened.
+func (s *AuditService) Record(ctx context.Context, action, entityType, entityID, facilityID string, before, after interface{}) {
+	if s == nil {
+		return
+	}
+	entry := s.entry(ctx, &AuditEvent{
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+		FacilityID: facilityID,
+		Before:     before,
+		After:      after,
+	})
+	if entry == nil {
+		return
+	}
+
+	// The mutation is done, so don't let a cancelled request drop its record
+	appendCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+	if err := s.repo.Append(appendCtx, entry); err != nil {
+		log.Printf("Warning: failed to record audit entry %s for %s %s: %v", action, entityType, entityID, err)
+	}
+}
+
+// entry builds the audit entry for event, or returns nil for an update that
+// changes nothing
+func (s *AuditService) entry(ctx context.Context, event *AuditEvent) *entities.AuditEntry {
+	changes, err := auditChanges(event.Before, event.After)
+	if err != nil {
+		log.Printf("Warning: failed to diff audit entry for %s %s: %v", event.EntityType, event.EntityID, err)
+		changes = map[string]entities.AuditChange{}
+	} else if len(changes) == 0 && !isNilValue(event.Before) && !isNilValue(event.After) {
+		return nil
+	}
+
+	requestCtx := entities.AuditContextFrom(ctx)
+	actor := requestCtx.ActorID
+	if actor == "" {
+		actor = entities.AuditActorSystem
+	}
+
+	return &entities.AuditEntry{
+		ID:         uuid.New().String(),
+		ActorID:    actor,
+		Action:     event.Action,
+		EntityType: event.EntityType,
+		EntityID:   event.EntityID,
+		FacilityID: event.FacilityID,
+		Changes:    changes,
+		RequestID:  requestCtx.RequestID,
+		IPAddress:  requestCtx.IPAddress,
+		// Postgres keeps microseconds; truncating keeps the hash reproducible
+		CreatedAt: s.now().UTC().Truncate(time.Microsecond),
+	}
+}
+
+// List returns audit entries matching the filter, newest first
+func (s *AuditService) List(ctx context.Context, filter repositories.AuditFilter) ([]*entities.AuditEntry, error) {
+	if filter.From != nil && filter.To != nil && filter.To.Before(*filter.From) {
+		return nil, apperrors.NewValidationError("to must not be before from")
+	}
+	filter.Limit, filter.Offset = clampPage(filter.Limit, filter.Offset, defaultAuditLimit, maxAuditLimit)
+	return s.repo.List(ctx, filter)
+}
+
+// AuditChainVerification is the result of re-walking the audit hash chain
+type AuditChainVerification struct {
+	Valid    bool   `json:"valid"`
+	Checked  int    `json:"checked"`
+	BrokenAt *int64 `json:"broken_at,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// VerifyChain recomputes every entry's hash in sequence order and reports the
+// first entry that was altered or whose predecessor was removed.
+func (s *AuditService) VerifyChain(ctx context.Context) (*AuditChainVerification, error) {
+	result := &AuditChainVerification{Valid: true}
+	var afterSequence int64
+	prevHash := ""
+
+	for {
+		entries, err := s.repo.ListAfter(ctx, afterSequence, auditVerifyBatchSize)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, entry := range entries {
+			if reason := verifyAuditEntry(entry, prevHash); reason != "" {
+				sequence := entry.Sequence
+				result.Valid = false
+				result.BrokenAt = &sequence
+				result.Reason = reason
+				return result, nil
+			}
+			result.Checked++
+			prevHash = entry.Hash
+			afterSequence = entry.Sequence
+		}
+
+		if len(entries) < auditVerifyBatchSize {
+			return result, nil
+		}
+	}
+}
+
+func verifyAuditEntry(entry *entities.AuditEntry, prevHash string) string {
+	if entry.PrevHash != prevHash {
+		return "previous hash does not match the preceding entry"
+	}
+	hash, err := entry.ComputeHash(prevHash)
+	if err != nil {
+		return "entry changes cannot be encoded"
+	}
+	if hash != entry.Hash {
+		return "entry content does not match its hash"
+	}
+	return ""
+}
+
+// auditChanges returns the top-level JSON fields that differ between before
+// and after. Values are normalized through JSON so they hash the same after
+// being stored.
+func auditChanges(before, after interface{}) (map[string]entities.AuditChange, error) {
+	beforeFields, err := auditFields(before)
+	if err != nil {
+		return nil, err
+	}
+	afterFields, err := auditFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(map[string]entities.AuditChange)
+	for field, value := range afterFields {
+		if auditIgnoredFields[field] {
+			continue
+		}
+		if previous, ok := beforeFields[field]; !ok || !reflect.DeepEqual(previous, value) {
+			changes[field] = entities.AuditChange{Before: beforeFields[field], After: value}
+		}
+	}
+	for field, previous := range beforeFields {
+		if auditIgnoredFields[field] {
+			continue
+		}
+		if _, ok := afterFields[field]; !ok {
+			changes[field] = entities.AuditChange{Before: previous}
+		}
+	}
+	return changes, nil
+}
+
+func auditFields(value interface{}) (map[string]interface{}, error) {
+	if isNilValue(value) {
+		return map[string]interface{}{}, nil
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		// Scalars and lists are recorded under a single field
+		var scalar interface{}
+		if err := json.Unmarshal(data, &scalar); err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"value": scalar}, nil
+	}
+	return fields, nil
+}
+
+func isNilValue(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface:
+		return v.IsNil()
+	}
+	return false
+}
@@ -0,0 +1,198 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/repositories"
+	apperrors "github.com/zatekoja/Patientpricediscoverydesign/backend/pkg/errors"
+)
+
+// memoryAuditLog chains entries in memory the way the adapter does
+type memoryAuditLog struct {
+	entries   []*entities.AuditEntry
+	appendErr error
+}
+
+// rollbackTransactor undoes the audit entries and the changes a failed
+// transaction made, standing in for a database rollback
+type rollbackTransactor struct {
+	log     *memoryAuditLog
+	changes *[]string
+}
+
+func (r *rollbackTransactor) InTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	entries, changes := len(r.log.entries), len(*r.changes)
+	if err := fn(ctx); err != nil {
+		r.log.entries = r.log.entries[:entries]
+		*r.changes = (*r.changes)[:changes]
+		return err
+	}
+	return nil
+}
+
+func (m *memoryAuditLog) Append(ctx context.Context, entry *entities.AuditEntry) error {
+	if m.appendErr != nil {
+		return m.appendErr
+	}
+	prevHash := ""
+	if len(m.entries) > 0 {
+		prevHash = m.entries[len(m.entries)-1].Hash
+	}
+	hash, err := entry.ComputeHash(prevHash)
+	if err != nil {
+		return err
+	}
+	entry.Sequence = int64(len(m.entries) + 1)
+	entry.PrevHash = prevHash
+	entry.Hash = hash
+	m.entries = append(m.entries, entry)
+	return nil
+}
+
+func (m *memoryAuditLog) List(ctx context.Context, filter repositories.AuditFilter) ([]*entities.AuditEntry, error) {
+	var out []*entities.AuditEntry
+	for i := len(m.entries) - 1; i >= 0; i-- {
+		if filter.ActorID == "" || m.entries[i].ActorID == filter.ActorID {
+			out = append(out, m.entries[i])
+		}
+	}
+	return out, nil
+}
+
+func (m *memoryAuditLog) ListAfter(ctx context.Context, afterSequence int64, limit int) ([]*entities.AuditEntry, error) {
+	var out []*entities.AuditEntry
+	for _, entry := range m.entries {
+		if entry.Sequence > afterSequence && len(out) < limit {
+			out = append(out, entry)
+		}
+	}
+	return out, nil
+}
+
+func TestAuditService_RecordDiffsAndChains(t *testing.T) {
+	repo := &memoryAuditLog{}
+	svc := NewAuditService(repo)
+	ctx := entities.WithAuditContext(context.Background(), entities.AuditContext{
+		ActorID:   "ops-1",
+		RequestID: "req-1",
+		IPAddress: "10.0.0.1",
+	})
+
+	before := &entities.Facility{ID: "fac-1", Name: "Old Name", UpdatedAt: time.Now()}
+	after := &entities.Facility{ID: "fac-1", Name: "New Name", UpdatedAt: time.Now().Add(time.Minute)}
+	svc.Record(ctx, "facility.update", "facility", "fac-1", "fac-1", before, after)
+	svc.Record(context.Background(), "fee_waiver.create", "fee_waiver", "fw-1", "", nil, map[string]interface{}{"waiver_type": "full"})
+
+	require.Len(t, repo.entries, 2)
+	first := repo.entries[0]
+	assert.Equal(t, "ops-1", first.ActorID)
+	assert.Equal(t, "req-1", first.RequestID)
+	assert.Equal(t, "10.0.0.1", first.IPAddress)
+	assert.Equal(t, map[string]entities.AuditChange{"name": {Before: "Old Name", After: "New Name"}}, first.Changes)
+	assert.Empty(t, first.PrevHash)
+
+	second := repo.entries[1]
+	assert.Equal(t, entities.AuditActorSystem, second.ActorID)
+	assert.Equal(t, first.Hash, second.PrevHash)
+	assert.Equal(t, "full", second.Changes["waiver_type"].After)
+}
+
+func TestAuditService_RecordSkipsNoOpUpdates(t *testing.T) {
+	repo := &memoryAuditLog{}
+	svc := NewAuditService(repo)
+
+	facility := &entities.Facility{ID: "fac-1", Name: "Same"}
+	touched := &entities.Facility{ID: "fac-1", Name: "Same", UpdatedAt: time.Now()}
+	svc.Record(context.Background(), "facility.update", "facility", "fac-1", "fac-1", facility, touched)
+
+	assert.Empty(t, repo.entries)
+
+	var nilService *AuditService
+	nilService.Record(context.Background(), "facility.update", "facility", "fac-1", "fac-1", nil, touched)
+}
+
+func TestAuditService_VerifyChainDetectsTampering(t *testing.T) {
+	repo := &memoryAuditLog{}
+	svc := NewAuditService(repo)
+	ctx := context.Background()
+	for _, name := range []string{"a", "b", "c"} {
+		svc.Record(ctx, "experiment.create", "experiment", name, "", nil, map[string]string{"name": name})
+	}
+
+	result, err := svc.VerifyChain(ctx)
+	require.NoError(t, err)
+	assert.True(t, result.Valid)
+	assert.Equal(t, 3, result.Checked)
+
+	repo.entries[1].ActorID = "someone-else"
+	result, err = svc.VerifyChain(ctx)
+	require.NoError(t, err)
+	assert.False(t, result.Valid)
+	require.NotNil(t, result.BrokenAt)
+	assert.Equal(t, int64(2), *result.BrokenAt)
+	assert.Equal(t, 1, result.Checked)
+
+	repo.entries[1].ActorID = entities.AuditActorSystem
+	repo.entries = append(repo.entries[:1], repo.entries[2:]...)
+	result, err = svc.VerifyChain(ctx)
+	require.NoError(t, err)
+	assert.False(t, result.Valid)
+	assert.Equal(t, int64(3), *result.BrokenAt)
+}
+
+func TestAuditService_ListRejectsInvertedRange(t *testing.T) {
+	svc := NewAuditService(&memoryAuditLog{})
+	from := time.Now()
+	to := from.Add(-time.Hour)
+
+	_, err := svc.List(context.Background(), repositories.AuditFilter{From: &from, To: &to})
+	requireAppErrorType(t, err, apperrors.ErrorTypeValidation, "inverted range")
+}
+
+func TestAuditService_TransactCommitsChangeWithItsEntry(t *testing.T) {
+	repo := &memoryAuditLog{}
+	var changes []string
+	svc := NewAuditService(repo)
+	svc.SetTransactor(&rollbackTransactor{log: repo, changes: &changes})
+
+	change := func(ctx context.Context) (*AuditEvent, error) {
+		changes = append(changes, "fac-1")
+		return &AuditEvent{
+			Action:     "facility.update",
+			EntityType: "facility",
+			EntityID:   "fac-1",
+			Before:     map[string]string{"name": "Old"},
+			After:      map[string]string{"name": "New"},
+		}, nil
+	}
+
+	require.NoError(t, svc.Transact(context.Background(), change))
+	assert.Equal(t, []string{"fac-1"}, changes)
+	require.Len(t, repo.entries, 1)
+	assert.Equal(t, "facility.update", repo.entries[0].Action)
+
+	// A failed append rolls the change back with it
+	repo.appendErr = apperrors.NewInternalError("audit log unavailable", nil)
+	err := svc.Transact(context.Background(), change)
+	requireAppErrorType(t, err, apperrors.ErrorTypeInternal, "failed append")
+	assert.Equal(t, []string{"fac-1"}, changes)
+	assert.Len(t, repo.entries, 1)
+
+	// A failed change is not audited
+	repo.appendErr = nil
+	err = svc.Transact(context.Background(), func(ctx context.Context) (*AuditEvent, error) {
+		return nil, apperrors.NewConflictError("stale version")
+	})
+	requireAppErrorType(t, err, apperrors.ErrorTypeConflict, "failed change")
+	assert.Len(t, repo.entries, 1)
+
+	var nilService *AuditService
+	require.NoError(t, nilService.Transact(context.Background(), change))
+	assert.Len(t, changes, 2)
+}
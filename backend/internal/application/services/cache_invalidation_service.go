@@ -10,10 +10,15 @@ import (
 	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/providers"
 )
 
+// facilityEventDedupeSize is how many recent event IDs a consumer remembers to
+// drop redelivered events
+const facilityEventDedupeSize = 1024
+
 // CacheInvalidationService handles cache invalidation based on events
 type CacheInvalidationService struct {
 	cache    providers.CacheProvider
 	eventBus providers.EventBus
+	deduper  *entities.FacilityEventDeduper
 	ctx      context.Context
 	cancel   context.CancelFunc
 }
@@ -24,6 +29,7 @@ func NewCacheInvalidationService(cache providers.CacheProvider, eventBus provide
 	return &CacheInvalidationService{
 		cache:    cache,
 		eventBus: eventBus,
+		deduper:  entities.NewFacilityEventDeduper(facilityEventDedupeSize),
 		ctx:      ctx,
 		cancel:   cancel,
 	}
@@ -55,7 +61,7 @@ func (s *CacheInvalidationService) processEvents(eventChan <-chan *entities.Faci
 		case <-s.ctx.Done():
 			return
 		case event := <-eventChan:
-			if event == nil {
+			if event == nil || s.deduper.Seen(event) {
 				continue
 			}
 			s.handleEvent(event)
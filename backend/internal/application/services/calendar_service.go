@@ -0,0 +1,247 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/providers"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/repositories"
+	apperrors "github.com/zatekoja/Patientpricediscoverydesign/backend/pkg/errors"
+)
+
+// maxSlotCapacity bounds per-slot capacity to catch typos in operator input.
+const maxSlotCapacity = 500
+
+// CalendarService manages native facility calendars for operators and
+// exposes checkout holds to patients.
+type CalendarService struct {
+	calendars repositories.CalendarRepository
+	native    providers.NativeCalendarProvider
+	audit     *AuditService
+}
+
+// NewCalendarService creates a new calendar service
+func NewCalendarService(calendars repositories.CalendarRepository, native providers.NativeCalendarProvider) *CalendarService {
+	return &CalendarService{calendars: calendars, native: native}
+}
+
+// SetAuditLog records operator changes to calendars, templates and blackouts
+func (s *CalendarService) SetAuditLog(audit *AuditService) {
+	s.audit = audit
+}
+
+// GetCalendar returns a facility's calendar settings
+func (s *CalendarService) GetCalendar(ctx context.Context, facilityID string) (*entities.FacilityCalendar, error) {
+	return s.calendars.GetCalendar(ctx, facilityID)
+}
+
+// SaveCalendar creates or updates a facility's calendar settings
+func (s *CalendarService) SaveCalendar(ctx context.Context, calendar *entities.FacilityCalendar) error {
+	calendar.Timezone = strings.TrimSpace(calendar.Timezone)
+	if calendar.Timezone == "" {
+		calendar.Timezone = entities.DefaultCalendarTimezone
+	}
+	if _, err := time.LoadLocation(calendar.Timezone); err != nil {
+		return apperrors.NewValidationError(fmt.Sprintf("unknown timezone %q", calendar.Timezone))
+	}
+	if calendar.HoldMinutes == 0 {
+		calendar.HoldMinutes = entities.DefaultSlotHoldMinutes
+	}
+	if calendar.HoldMinutes < 1 || calendar.HoldMinutes > 60 {
+		return apperrors.NewValidationError("hold_minutes must be between 1 and 60")
+	}
+
+	// A missing calendar is recorded as created
+	before, _ := s.calendars.GetCalendar(ctx, calendar.FacilityID)
+	return s.audit.Transact(ctx, func(ctx context.Context) (*AuditEvent, error) {
+		if err := s.calendars.UpsertCalendar(ctx, calendar); err != nil {
+			return nil, err
+		}
+		return &AuditEvent{
+			Action:     "calendar.save",
+			EntityType: "facility_calendar",
+			EntityID:   calendar.FacilityID,
+			FacilityID: calendar.FacilityID,
+			Before:     before,
+			After:      calendar,
+		}, nil
+	})
+}
+
+// ListTemplates returns a facility's weekly slot templates
+func (s *CalendarService) ListTemplates(ctx context.Context, facilityID string) ([]*entities.SlotTemplate, error) {
+	return s.calendars.ListTemplates(ctx, facilityID)
+}
+
+// CreateTemplate validates and creates a weekly slot template. Templates for
+// the same ward and weekday may not overlap, since both would offer the same time.
+func (s *CalendarService) CreateTemplate(ctx context.Context, template *entities.SlotTemplate) error {
+	if template.Weekday < time.Sunday || template.Weekday > time.Saturday {
+		return apperrors.NewValidationError("weekday must be between 0 (Sunday) and 6 (Saturday)")
+	}
+	start, err := entities.ParseClock(template.StartTime)
+	if err != nil {
+		return apperrors.NewValidationError(err.Error())
+	}
+	end, err := entities.ParseClock(template.EndTime)
+	if err != nil {
+		return apperrors.NewValidationError(err.Error())
+	}
+	if end <= start {
+		return apperrors.NewValidationError("end_time must be after start_time")
+	}
+	if template.SlotMinutes <= 0 || template.SlotMinutes > end-start {
+		return apperrors.NewValidationError("slot_minutes must be positive and fit between start_time and end_time")
+	}
+	if template.Capacity <= 0 || template.Capacity > maxSlotCapacity {
+		return apperrors.NewValidationError(fmt.Sprintf("capacity must be between 1 and %d", maxSlotCapacity))
+	}
+	if template.ValidFrom != nil && template.ValidUntil != nil && template.ValidUntil.Before(*template.ValidFrom) {
+		return apperrors.NewValidationError("valid_until must not be before valid_from")
+	}
+	if template.WardID != nil && strings.TrimSpace(*template.WardID) == "" {
+		template.WardID = nil
+	}
+
+	existing, err := s.calendars.ListTemplates(ctx, template.FacilityID)
+	if err != nil {
+		return err
+	}
+	for _, other := range existing {
+		if other.Weekday != template.Weekday || !sameWard(other.WardID, template.WardID) || !validityOverlaps(other, template) {
+			continue
+		}
+		otherStart, err1 := entities.ParseClock(other.StartTime)
+		otherEnd, err2 := entities.ParseClock(other.EndTime)
+		if err1 == nil && err2 == nil && start < otherEnd && otherStart < end {
+			return apperrors.NewConflictError(fmt.Sprintf("template overlaps existing template %s", other.ID))
+		}
+	}
+
+	return s.audit.Transact(ctx, func(ctx context.Context) (*AuditEvent, error) {
+		if err := s.calendars.CreateTemplate(ctx, template); err != nil {
+			return nil, err
+		}
+		return &AuditEvent{
+			Action:     "calendar.template.create",
+			EntityType: "slot_template",
+			EntityID:   template.ID,
+			FacilityID: template.FacilityID,
+			After:      template,
+		}, nil
+	})
+}
+
+// DeleteTemplate deletes a facility's slot template
+func (s *CalendarService) DeleteTemplate(ctx context.Context, facilityID, templateID string) error {
+	var before *entities.SlotTemplate
+	if s.audit != nil {
+		if templates, err := s.calendars.ListTemplates(ctx, facilityID); err == nil {
+			for _, template := range templates {
+				if template.ID == templateID {
+					before = template
+				}
+			}
+		}
+	}
+
+	return s.audit.Transact(ctx, func(ctx context.Context) (*AuditEvent, error) {
+		if err := s.calendars.DeleteTemplate(ctx, facilityID, templateID); err != nil {
+			return nil, err
+		}
+		return &AuditEvent{
+			Action:     "calendar.template.delete",
+			EntityType: "slot_template",
+			EntityID:   templateID,
+			FacilityID: facilityID,
+			Before:     before,
+		}, nil
+	})
+}
+
+// ListBlackouts returns a facility's blackouts overlapping [from, to)
+func (s *CalendarService) ListBlackouts(ctx context.Context, facilityID string, from, to time.Time) ([]*entities.CalendarBlackout, error) {
+	return s.calendars.ListBlackouts(ctx, facilityID, from, to)
+}
+
+// CreateBlackout validates and creates a blackout period
+func (s *CalendarService) CreateBlackout(ctx context.Context, blackout *entities.CalendarBlackout) error {
+	if blackout.StartsAt.IsZero() || blackout.EndsAt.IsZero() {
+		return apperrors.NewValidationError("starts_at and ends_at are required")
+	}
+	if !blackout.EndsAt.After(blackout.StartsAt) {
+		return apperrors.NewValidationError("ends_at must be after starts_at")
+	}
+	if blackout.WardID != nil && strings.TrimSpace(*blackout.WardID) == "" {
+		blackout.WardID = nil
+	}
+	blackout.Reason = strings.TrimSpace(blackout.Reason)
+	return s.audit.Transact(ctx, func(ctx context.Context) (*AuditEvent, error) {
+		if err := s.calendars.CreateBlackout(ctx, blackout); err != nil {
+			return nil, err
+		}
+		return &AuditEvent{
+			Action:     "calendar.blackout.create",
+			EntityType: "calendar_blackout",
+			EntityID:   blackout.ID,
+			FacilityID: blackout.FacilityID,
+			After:      blackout,
+		}, nil
+	})
+}
+
+// DeleteBlackout deletes a facility's blackout period
+func (s *CalendarService) DeleteBlackout(ctx context.Context, facilityID, blackoutID string) error {
+	return s.audit.Transact(ctx, func(ctx context.Context) (*AuditEvent, error) {
+		if err := s.calendars.DeleteBlackout(ctx, facilityID, blackoutID); err != nil {
+			return nil, err
+		}
+		return &AuditEvent{
+			Action:     "calendar.blackout.delete",
+			EntityType: "calendar_blackout",
+			EntityID:   blackoutID,
+			FacilityID: facilityID,
+		}, nil
+	})
+}
+
+// ListSlots returns every offered slot in the range with booked and held counts
+func (s *CalendarService) ListSlots(ctx context.Context, facilityID string, from, to time.Time) ([]entities.AvailabilitySlot, error) {
+	return s.native.ListSlots(ctx, facilityID, from, to)
+}
+
+// HoldSlot holds a slot for a patient during checkout
+func (s *CalendarService) HoldSlot(ctx context.Context, facilityID, slotID string) (*entities.SlotReservation, error) {
+	if strings.TrimSpace(facilityID) == "" || strings.TrimSpace(slotID) == "" {
+		return nil, apperrors.NewValidationError("facility_id and slot_id are required")
+	}
+	return s.native.HoldSlot(ctx, facilityID, slotID)
+}
+
+// ReleaseHold releases a checkout hold
+func (s *CalendarService) ReleaseHold(ctx context.Context, facilityID, holdID string) error {
+	if strings.TrimSpace(facilityID) == "" {
+		return apperrors.NewValidationError("facility_id is required")
+	}
+	return s.native.ReleaseHold(ctx, facilityID, holdID)
+}
+
+func sameWard(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+func validityOverlaps(a, b *entities.SlotTemplate) bool {
+	if a.ValidUntil != nil && b.ValidFrom != nil && a.ValidUntil.Before(*b.ValidFrom) {
+		return false
+	}
+	if b.ValidUntil != nil && a.ValidFrom != nil && b.ValidUntil.Before(*a.ValidFrom) {
+		return false
+	}
+	return true
+}
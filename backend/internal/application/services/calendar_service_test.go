@@ -0,0 +1,129 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	apperrors "github.com/zatekoja/Patientpricediscoverydesign/backend/pkg/errors"
+)
+
+type stubCalendarRepo struct {
+	calendar  *entities.FacilityCalendar
+	templates []*entities.SlotTemplate
+	blackouts []*entities.CalendarBlackout
+}
+
+func (r *stubCalendarRepo) GetCalendar(ctx context.Context, facilityID string) (*entities.FacilityCalendar, error) {
+	if r.calendar == nil {
+		return nil, apperrors.NewNotFoundError("calendar not found")
+	}
+	return r.calendar, nil
+}
+
+func (r *stubCalendarRepo) UpsertCalendar(ctx context.Context, calendar *entities.FacilityCalendar) error {
+	r.calendar = calendar
+	return nil
+}
+
+func (r *stubCalendarRepo) ListTemplates(ctx context.Context, facilityID string) ([]*entities.SlotTemplate, error) {
+	return r.templates, nil
+}
+
+func (r *stubCalendarRepo) CreateTemplate(ctx context.Context, template *entities.SlotTemplate) error {
+	r.templates = append(r.templates, template)
+	return nil
+}
+
+func (r *stubCalendarRepo) DeleteTemplate(ctx context.Context, facilityID, id string) error {
+	return nil
+}
+
+func (r *stubCalendarRepo) ListBlackouts(ctx context.Context, facilityID string, from, to time.Time) ([]*entities.CalendarBlackout, error) {
+	return r.blackouts, nil
+}
+
+func (r *stubCalendarRepo) CreateBlackout(ctx context.Context, blackout *entities.CalendarBlackout) error {
+	r.blackouts = append(r.blackouts, blackout)
+	return nil
+}
+
+func (r *stubCalendarRepo) DeleteBlackout(ctx context.Context, facilityID, id string) error {
+	return nil
+}
+
+func requireAppErrorType(t *testing.T, err error, want apperrors.ErrorType, msg string) {
+	t.Helper()
+	var appErr *apperrors.AppError
+	require.ErrorAs(t, err, &appErr, msg)
+	assert.Equal(t, want, appErr.Type, msg)
+}
+
+func TestCalendarService_SaveCalendarDefaults(t *testing.T) {
+	repo := &stubCalendarRepo{}
+	svc := NewCalendarService(repo, nil)
+
+	calendar := &entities.FacilityCalendar{FacilityID: "fac-1", Enabled: true}
+	require.NoError(t, svc.SaveCalendar(context.Background(), calendar))
+	assert.Equal(t, entities.DefaultCalendarTimezone, repo.calendar.Timezone)
+	assert.Equal(t, entities.DefaultSlotHoldMinutes, repo.calendar.HoldMinutes)
+
+	err := svc.SaveCalendar(context.Background(), &entities.FacilityCalendar{FacilityID: "fac-1", Timezone: "Mars/Olympus"})
+	requireAppErrorType(t, err, apperrors.ErrorTypeValidation, "unknown timezone")
+}
+
+func TestCalendarService_CreateTemplateValidation(t *testing.T) {
+	ward := "maternity"
+	repo := &stubCalendarRepo{templates: []*entities.SlotTemplate{{
+		ID: "existing", FacilityID: "fac-1", Weekday: time.Monday, StartTime: "09:00", EndTime: "12:00", SlotMinutes: 30, Capacity: 1,
+	}}}
+	svc := NewCalendarService(repo, nil)
+	valid := func() *entities.SlotTemplate {
+		return &entities.SlotTemplate{FacilityID: "fac-1", Weekday: time.Tuesday, StartTime: "09:00", EndTime: "12:00", SlotMinutes: 30, Capacity: 2}
+	}
+
+	invalid := map[string]func(*entities.SlotTemplate){
+		"bad clock":       func(t *entities.SlotTemplate) { t.StartTime = "9am" },
+		"end before":      func(t *entities.SlotTemplate) { t.EndTime = "08:00" },
+		"slot too long":   func(t *entities.SlotTemplate) { t.SlotMinutes = 240 },
+		"zero capacity":   func(t *entities.SlotTemplate) { t.Capacity = 0 },
+		"bad weekday":     func(t *entities.SlotTemplate) { t.Weekday = 7 },
+		"minutes over 59": func(t *entities.SlotTemplate) { t.EndTime = "11:75" },
+	}
+	for name, mutate := range invalid {
+		template := valid()
+		mutate(template)
+		requireAppErrorType(t, svc.CreateTemplate(context.Background(), template), apperrors.ErrorTypeValidation, name)
+	}
+
+	overlapping := valid()
+	overlapping.Weekday = time.Monday
+	overlapping.StartTime = "11:00"
+	overlapping.EndTime = "13:00"
+	requireAppErrorType(t, svc.CreateTemplate(context.Background(), overlapping), apperrors.ErrorTypeConflict, "overlap")
+
+	otherWard := valid()
+	otherWard.Weekday = time.Monday
+	otherWard.WardID = &ward
+	require.NoError(t, svc.CreateTemplate(context.Background(), otherWard), "templates for different wards may overlap")
+	require.NoError(t, svc.CreateTemplate(context.Background(), valid()))
+	assert.Len(t, repo.templates, 3)
+}
+
+func TestCalendarService_CreateBlackoutValidation(t *testing.T) {
+	repo := &stubCalendarRepo{}
+	svc := NewCalendarService(repo, nil)
+	start := time.Date(2026, 12, 25, 0, 0, 0, 0, time.UTC)
+
+	err := svc.CreateBlackout(context.Background(), &entities.CalendarBlackout{FacilityID: "fac-1", StartsAt: start, EndsAt: start})
+	requireAppErrorType(t, err, apperrors.ErrorTypeValidation, "empty period")
+
+	blank := " "
+	blackout := &entities.CalendarBlackout{FacilityID: "fac-1", WardID: &blank, StartsAt: start, EndsAt: start.Add(24 * time.Hour), Reason: " Christmas "}
+	require.NoError(t, svc.CreateBlackout(context.Background(), blackout))
+	assert.Nil(t, repo.blackouts[0].WardID)
+	assert.Equal(t, "Christmas", repo.blackouts[0].Reason)
+}
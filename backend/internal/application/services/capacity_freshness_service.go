@@ -0,0 +1,294 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/providers"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/repositories"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/pkg/utils"
+)
+
+const capacityFreshnessBatchSize = 100
+
+// CapacityFreshnessSweep summarizes one pass over expiring capacity data
+type CapacityFreshnessSweep struct {
+	FacilitiesChecked     int `json:"facilities_checked"`
+	FacilityValuesExpired int `json:"facility_values_expired"`
+	WardValuesExpired     int `json:"ward_values_expired"`
+	NudgesSent            int `json:"nudges_sent"`
+}
+
+// CapacityFreshnessService decays facility and ward capacity values to unknown
+// once their reports are older than the facility service's freshness policy,
+// publishing capacity events so live clients update, and reminds operators
+// shortly before their values expire.
+type CapacityFreshnessService struct {
+	repo       repositories.CapacityFreshnessRepository
+	facilities *FacilityService
+	senders    map[entities.NotificationChannel]providers.NotificationSender
+	now        func() time.Time
+}
+
+// NewCapacityFreshnessService creates a new capacity freshness service. Writes
+// go through the facility service so they are versioned, audited, indexed
+// and evented like any other update.
+func NewCapacityFreshnessService(repo repositories.CapacityFreshnessRepository, facilities *FacilityService) *CapacityFreshnessService {
+	return &CapacityFreshnessService{
+		repo:       repo,
+		facilities: facilities,
+		senders:    make(map[entities.NotificationChannel]providers.NotificationSender),
+		now:        time.Now,
+	}
+}
+
+// SetNudgeSenders sets the channels operator reminders are sent on, tried in
+// the same order as patient notifications
+func (s *CapacityFreshnessService) SetNudgeSenders(senders ...providers.NotificationSender) {
+	for _, sender := range senders {
+		s.senders[sender.Channel()] = sender
+	}
+}
+
+// Sweep expires stale capacity values and reminds operators whose values are
+// about to expire. A facility that fails is logged and skipped so the rest
+// are still checked.
+func (s *CapacityFreshnessService) Sweep(ctx context.Context) (*CapacityFreshnessSweep, error) {
+	now := s.now()
+	policy := s.facilities.capacityFreshness
+
+	// Values are due once they enter their reminder window
+	cutoffs := make(repositories.CapacityCutoffs, len(entities.CapacityFields))
+	for _, field := range entities.CapacityFields {
+		cutoffs[field] = now.Add(policy.NudgeBefore - policy.TTL(field))
+	}
+
+	sweep := &CapacityFreshnessSweep{}
+	afterID := ""
+	for {
+		ids, err := s.repo.ListFacilitiesReportedBefore(ctx, cutoffs, afterID, capacityFreshnessBatchSize)
+		if err != nil {
+			return sweep, err
+		}
+		for _, id := range ids {
+			if err := s.checkFacility(ctx, id, now, sweep); err != nil {
+				log.Printf("Warning: capacity freshness check failed for facility %s: %v", id, err)
+			}
+			afterID = id
+		}
+		if len(ids) < capacityFreshnessBatchSize {
+			return sweep, nil
+		}
+	}
+}
+
+// Start sweeps every interval until ctx is done
+func (s *CapacityFreshnessService) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				sweep, err := s.Sweep(ctx)
+				if err != nil {
+					log.Printf("Capacity freshness sweep failed: %v", err)
+					continue
+				}
+				if sweep.FacilityValuesExpired > 0 || sweep.WardValuesExpired > 0 || sweep.NudgesSent > 0 {
+					log.Printf("Capacity freshness: expired %d facility and %d ward values, sent %d reminders",
+						sweep.FacilityValuesExpired, sweep.WardValuesExpired, sweep.NudgesSent)
+				}
+			}
+		}
+	}()
+}
+
+// expiringCapacity tracks the values of one facility that are about to expire
+type expiringCapacity struct {
+	count int
+	// expiresAt is when the first of them expires
+	expiresAt time.Time
+	// reportedAt is when the most recent of them was reported
+	reportedAt time.Time
+}
+
+func (e *expiringCapacity) add(freshness entities.DataFreshness) {
+	if e.count == 0 || freshness.ExpiresAt.Before(e.expiresAt) {
+		e.expiresAt = *freshness.ExpiresAt
+	}
+	if freshness.ReportedAt.After(e.reportedAt) {
+		e.reportedAt = *freshness.ReportedAt
+	}
+	e.count++
+}
+
+func (s *CapacityFreshnessService) checkFacility(ctx context.Context, facilityID string, now time.Time, sweep *CapacityFreshnessSweep) error {
+	policy := s.facilities.capacityFreshness
+
+	facility, err := s.facilities.repo.GetByID(ctx, facilityID)
+	if err != nil {
+		if isNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	sweep.FacilitiesChecked++
+
+	var expiring expiringCapacity
+
+	expired := *facility
+	changedFields := make(map[string]interface{})
+	for field, reportedAt := range facility.CapacityValues() {
+		freshness := policy.Freshness(field, reportedAt, now)
+		switch freshness.State {
+		case entities.FreshnessStale:
+			expired.ClearCapacity(field)
+			changedFields[string(field)] = capacityValue(&expired, field)
+		case entities.FreshnessExpiring:
+			expiring.add(freshness)
+		}
+	}
+	if len(changedFields) > 0 {
+		event := entities.NewFacilityEvent(facility.ID, entities.FacilityEventTypeCapacityUpdate, facility.Location, changedFields)
+		err := s.facilities.update(ctx, "facility.capacity_expire", facility, &expired, event)
+		switch {
+		case err == nil:
+			sweep.FacilityValuesExpired += len(changedFields)
+		case isConflict(err):
+			// Updated while we looked; the next sweep sees the new report
+		default:
+			return err
+		}
+	}
+
+	if s.facilities.facilityWardRepo != nil {
+		wards, err := s.facilities.facilityWardRepo.GetByFacilityID(ctx, facility.ID)
+		if err != nil {
+			return err
+		}
+		for _, ward := range wards {
+			expiredWard := *ward
+			count := 0
+			for field, reportedAt := range ward.CapacityValues() {
+				freshness := policy.Freshness(field, reportedAt, now)
+				switch freshness.State {
+				case entities.FreshnessStale:
+					expiredWard.ClearCapacity(field)
+					count++
+				case entities.FreshnessExpiring:
+					expiring.add(freshness)
+				}
+			}
+			if count == 0 {
+				continue
+			}
+			event := wardCapacityEvent(facility.Location, ward, &expiredWard)
+			err := s.facilities.upsertWard(ctx, "facility_ward.capacity_expire", ward, &expiredWard, event)
+			switch {
+			case err == nil:
+				sweep.WardValuesExpired += count
+			case isConflict(err):
+				// Reported again while we looked
+			default:
+				return err
+			}
+		}
+	}
+
+	if expiring.count == 0 {
+		return nil
+	}
+	sent, err := s.nudge(ctx, facility, expiring, now)
+	if sent {
+		sweep.NudgesSent++
+	}
+	return err
+}
+
+// nudge reminds the facility's operator that capacity values are about to
+// expire. Each report is nudged at most once: no reminder goes out if one was
+// already sent after the latest expiring value was reported.
+func (s *CapacityFreshnessService) nudge(ctx context.Context, facility *entities.Facility, expiring expiringCapacity, now time.Time) (bool, error) {
+	if len(s.senders) == 0 {
+		return false, nil
+	}
+
+	lastNudged, err := s.repo.LastNudgedAt(ctx, facility.ID)
+	if err != nil {
+		return false, err
+	}
+	if lastNudged != nil && !lastNudged.Before(expiring.reportedAt) {
+		return false, nil
+	}
+
+	minutes := int(expiring.expiresAt.Sub(now).Round(time.Minute).Minutes())
+	body := fmt.Sprintf(
+		"Capacity information for %s will be shown to patients as unknown in %d minutes unless it is updated. Please send your current capacity and wait times to keep it visible.",
+		facility.Name, minutes,
+	)
+
+	var errs []error
+	for _, channel := range notificationFallbackOrder {
+		sender, ok := s.senders[channel]
+		if !ok {
+			continue
+		}
+		recipient := operatorRecipient(channel, facility)
+		if recipient == "" {
+			continue
+		}
+
+		message := providers.NotificationMessage{To: recipient, Body: body}
+		if channel == entities.ChannelEmail {
+			message.Subject = "Capacity update needed for " + facility.Name
+		}
+		if _, err := sender.Send(ctx, message); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", channel, err))
+			continue
+		}
+		return true, s.repo.RecordNudge(ctx, facility.ID, now)
+	}
+	return false, errors.Join(errs...)
+}
+
+// operatorRecipient returns the facility's contact for the channel
+func operatorRecipient(channel entities.NotificationChannel, facility *entities.Facility) string {
+	var phone string
+	switch channel {
+	case entities.ChannelEmail:
+		return facility.Email
+	case entities.ChannelWhatsApp:
+		phone = facility.WhatsAppNumber
+	case entities.ChannelSMS:
+		phone = facility.PhoneNumber
+	}
+	if phone == "" {
+		return ""
+	}
+	if normalized, err := utils.NormalizePhone(phone); err == nil {
+		return normalized
+	}
+	return phone
+}
+
+// capacityValue returns the facility's current value for the field, as it is
+// sent in capacity events
+func capacityValue(facility *entities.Facility, field entities.CapacityField) interface{} {
+	switch field {
+	case entities.CapacityFieldStatus:
+		return facility.CapacityStatus
+	case entities.CapacityFieldWaitTime:
+		return facility.AvgWaitMinutes
+	case entities.CapacityFieldUrgentCare:
+		return facility.UrgentCareAvailable
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,156 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/providers"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/repositories"
+)
+
+// memoryCapacityFreshness lists every facility it is given and records nudges
+type memoryCapacityFreshness struct {
+	facilityIDs []string
+	nudges      map[string]time.Time
+}
+
+func (r *memoryCapacityFreshness) ListFacilitiesReportedBefore(ctx context.Context, cutoffs repositories.CapacityCutoffs, afterID string, limit int) ([]string, error) {
+	var ids []string
+	for _, id := range r.facilityIDs {
+		if id > afterID && len(ids) < limit {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+func (r *memoryCapacityFreshness) LastNudgedAt(ctx context.Context, facilityID string) (*time.Time, error) {
+	if at, ok := r.nudges[facilityID]; ok {
+		return &at, nil
+	}
+	return nil, nil
+}
+
+func (r *memoryCapacityFreshness) RecordNudge(ctx context.Context, facilityID string, at time.Time) error {
+	r.nudges[facilityID] = at
+	return nil
+}
+
+type capturingEventBus struct {
+	providers.EventBus
+	events []*entities.FacilityEvent
+}
+
+func (b *capturingEventBus) Publish(ctx context.Context, channel string, event *entities.FacilityEvent) error {
+	if channel == providers.EventChannelFacilityUpdates {
+		b.events = append(b.events, event)
+	}
+	return nil
+}
+
+type recordingNudgeSender struct {
+	channel  entities.NotificationChannel
+	messages []providers.NotificationMessage
+}
+
+func (s *recordingNudgeSender) Channel() entities.NotificationChannel {
+	return s.channel
+}
+
+func (s *recordingNudgeSender) Send(ctx context.Context, message providers.NotificationMessage) (string, error) {
+	s.messages = append(s.messages, message)
+	return "msg-1", nil
+}
+
+func TestCapacityFreshness_SweepExpiresStaleValuesAndNudgesOnce(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	status, wait := "available", 20
+	facilities := &versionedFacilityRepo{facilities: map[string]entities.Facility{
+		"fac-1": {
+			ID:                       "fac-1",
+			Name:                     "General Hospital",
+			WhatsAppNumber:           "+2348012345678",
+			CapacityStatus:           &status,
+			CapacityStatusReportedAt: timePtr(now.Add(-11*time.Hour - 30*time.Minute)),
+			AvgWaitMinutes:           &wait,
+			AvgWaitReportedAt:        timePtr(now.Add(-5 * time.Hour)),
+			Version:                  1,
+		},
+	}}
+	wardStatus := "busy"
+	wards := &versionedWardRepo{wards: map[string]entities.FacilityWard{
+		"fac-1/maternity": {ID: "ward-1", FacilityID: "fac-1", WardName: "maternity", CapacityStatus: &wardStatus, LastUpdated: now.Add(-13 * time.Hour), Version: 1},
+	}}
+	bus := &capturingEventBus{}
+
+	facilityService := NewFacilityService(facilities, nil, nil, nil, nil)
+	facilityService.SetFacilityWardRepository(wards)
+	facilityService.SetEventBus(bus)
+	facilityService.SetCapacityFreshnessPolicy(entities.CapacityFreshnessPolicy{
+		CapacityStatusTTL: 12 * time.Hour,
+		WaitTimeTTL:       4 * time.Hour,
+		UrgentCareTTL:     24 * time.Hour,
+		NudgeBefore:       time.Hour,
+	})
+
+	repo := &memoryCapacityFreshness{facilityIDs: []string{"fac-1"}, nudges: map[string]time.Time{}}
+	sender := &recordingNudgeSender{channel: entities.ChannelWhatsApp}
+	service := NewCapacityFreshnessService(repo, facilityService)
+	service.SetNudgeSenders(sender)
+	service.now = func() time.Time { return now }
+
+	sweep, err := service.Sweep(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sweep.FacilityValuesExpired != 1 || sweep.WardValuesExpired != 1 || sweep.NudgesSent != 1 {
+		t.Fatalf("unexpected sweep %+v", sweep)
+	}
+
+	stored, _ := facilities.GetByID(context.Background(), "fac-1")
+	if stored.AvgWaitMinutes != nil {
+		t.Fatalf("expected stale wait time to be cleared, got %d", *stored.AvgWaitMinutes)
+	}
+	if stored.CapacityStatus == nil || *stored.CapacityStatus != "available" {
+		t.Fatalf("expiring status must be kept until it expires, got %v", stored.CapacityStatus)
+	}
+	ward, _ := wards.GetByFacilityAndWard(context.Background(), "fac-1", "maternity")
+	if ward.CapacityStatus == nil || *ward.CapacityStatus != entities.CapacityStatusUnknown {
+		t.Fatalf("expected stale ward status to decay to unknown, got %v", ward.CapacityStatus)
+	}
+	if !ward.LastUpdated.Equal(now.Add(-13 * time.Hour)) {
+		t.Fatalf("expiring a ward must not change when it was reported, got %v", ward.LastUpdated)
+	}
+
+	if len(bus.events) != 2 {
+		t.Fatalf("expected a facility and a ward event, got %d", len(bus.events))
+	}
+	if bus.events[0].EventType != entities.FacilityEventTypeCapacityUpdate {
+		t.Fatalf("expected a capacity update event, got %s", bus.events[0].EventType)
+	}
+	if _, ok := bus.events[0].ChangedFields["avg_wait_minutes"]; !ok {
+		t.Fatalf("expected the cleared wait time in the event, got %v", bus.events[0].ChangedFields)
+	}
+
+	if len(sender.messages) != 1 || sender.messages[0].To != "+2348012345678" {
+		t.Fatalf("expected one nudge to the facility's WhatsApp number, got %+v", sender.messages)
+	}
+
+	// The next sweep finds nothing new to expire and has already nudged this report
+	sweep, err = service.Sweep(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sweep.FacilityValuesExpired != 0 || sweep.WardValuesExpired != 0 || sweep.NudgesSent != 0 {
+		t.Fatalf("expected an idle second sweep, got %+v", sweep)
+	}
+	if len(sender.messages) != 1 {
+		t.Fatalf("expected no second nudge, got %d messages", len(sender.messages))
+	}
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
@@ -0,0 +1,505 @@
+package services
+
+import (
+	"context"
+	"log"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/repositories"
+)
+
+const (
+	// capacityHistoryWindow is how far back profiles look
+	capacityHistoryWindow = 12 * 7 * 24 * time.Hour
+	// capacityHistoryHalfLife is the age at which a report counts half as
+	// much as one made now
+	capacityHistoryHalfLife = 4 * 7 * 24 * time.Hour
+	// liveForecastHalfLife is how quickly the current report gives way to the
+	// profile as the arrival time moves away from when it was reported
+	liveForecastHalfLife = time.Hour
+	// defaultCapacityHistoryRetention is how long reports are kept
+	defaultCapacityHistoryRetention = 26 * 7 * 24 * time.Hour
+)
+
+// forecastKernel spreads a forecast over the arrival hour and its neighbours
+// so a single busy hour in the history does not dominate
+var forecastKernel = map[int]float64{-1: 0.25, 0: 0.5, 1: 0.25}
+
+// CapacityHistoryService records every capacity and wait time report, builds
+// hour-of-week profiles from them and forecasts the expected wait for an
+// arrival time.
+type CapacityHistoryService struct {
+	repo       repositories.CapacityHistoryRepository
+	facilities *FacilityService
+	location   *time.Location
+	retention  time.Duration
+	now        func() time.Time
+}
+
+// NewCapacityHistoryService creates a new capacity history service. The
+// facility service supplies current reports and the freshness policy, which
+// also bounds how long a report is assumed to hold in the history.
+func NewCapacityHistoryService(repo repositories.CapacityHistoryRepository, facilities *FacilityService) *CapacityHistoryService {
+	return &CapacityHistoryService{
+		repo:       repo,
+		facilities: facilities,
+		location:   time.UTC,
+		retention:  defaultCapacityHistoryRetention,
+		now:        time.Now,
+	}
+}
+
+// SetLocation sets the timezone hours of the week are counted in, normally
+// the one the facilities operate in
+func (s *CapacityHistoryService) SetLocation(location *time.Location) {
+	if location != nil {
+		s.location = location
+	}
+}
+
+// SetRetention sets how long reports are kept before they are pruned
+func (s *CapacityHistoryService) SetRetention(retention time.Duration) {
+	if retention > 0 {
+		s.retention = retention
+	}
+}
+
+// RecordFacility records the facility capacity values newly reported by a
+// write from before to after (before is nil for a new facility). Values that
+// decayed to unknown are not reports and are skipped. Failures are logged so
+// they never fail the write.
+func (s *CapacityHistoryService) RecordFacility(ctx context.Context, before, after *entities.Facility) {
+	if s == nil || after == nil {
+		return
+	}
+
+	observation := &entities.CapacityObservation{FacilityID: after.ID}
+	var observedAt time.Time
+	if isKnownCapacityStatus(after.CapacityStatus) &&
+		(before == nil || reportedSince(before.CapacityStatusReportedAt, after.CapacityStatusReportedAt) || !strPtrEqual(before.CapacityStatus, after.CapacityStatus)) {
+		status := *after.CapacityStatus
+		observation.CapacityStatus = &status
+		observedAt = latestReport(observedAt, after.CapacityStatusReportedAt)
+	}
+	if after.AvgWaitMinutes != nil &&
+		(before == nil || reportedSince(before.AvgWaitReportedAt, after.AvgWaitReportedAt) || !intPtrEqual(before.AvgWaitMinutes, after.AvgWaitMinutes)) {
+		wait := *after.AvgWaitMinutes
+		observation.AvgWaitMinutes = &wait
+		observedAt = latestReport(observedAt, after.AvgWaitReportedAt)
+	}
+	if observation.CapacityStatus == nil && observation.AvgWaitMinutes == nil {
+		return
+	}
+	if observedAt.IsZero() {
+		observedAt = s.now()
+	}
+	observation.ObservedAt = observedAt
+
+	if err := s.repo.Record(ctx, observation); err != nil {
+		log.Printf("Warning: failed to record capacity history for facility %s: %v", after.ID, err)
+	}
+}
+
+// RecordWard records a ward's capacity values when a write from before to
+// after (before is nil for a new ward) carries a newer report or changed
+// values. Failures are logged so they never fail the write.
+func (s *CapacityHistoryService) RecordWard(ctx context.Context, before, after *entities.FacilityWard) {
+	if s == nil || after == nil {
+		return
+	}
+	if before != nil && !after.LastUpdated.After(before.LastUpdated) &&
+		strPtrEqual(before.CapacityStatus, after.CapacityStatus) &&
+		intPtrEqual(before.AvgWaitMinutes, after.AvgWaitMinutes) {
+		return
+	}
+
+	observation := &entities.CapacityObservation{
+		FacilityID: after.FacilityID,
+		WardName:   after.WardName,
+		ObservedAt: after.LastUpdated,
+	}
+	if isKnownCapacityStatus(after.CapacityStatus) {
+		status := *after.CapacityStatus
+		observation.CapacityStatus = &status
+	}
+	if after.AvgWaitMinutes != nil {
+		wait := *after.AvgWaitMinutes
+		observation.AvgWaitMinutes = &wait
+	}
+	if observation.CapacityStatus == nil && observation.AvgWaitMinutes == nil {
+		return
+	}
+	if observation.ObservedAt.IsZero() {
+		observation.ObservedAt = s.now()
+	}
+
+	if err := s.repo.Record(ctx, observation); err != nil {
+		log.Printf("Warning: failed to record capacity history for ward %s of facility %s: %v", after.WardName, after.FacilityID, err)
+	}
+}
+
+// RebuildProfiles rebuilds the hour-of-week profiles of every facility with
+// reports in the history window, and returns how many were rebuilt.
+// Facilities whose last reports left the window within the past week are
+// rebuilt too, which clears their profiles.
+func (s *CapacityHistoryService) RebuildProfiles(ctx context.Context) (int, error) {
+	now := s.now()
+	since := now.Add(-capacityHistoryWindow)
+
+	ids, err := s.repo.ListObservedFacilityIDs(ctx, since.Add(-7*24*time.Hour))
+	if err != nil {
+		return 0, err
+	}
+
+	rebuilt := 0
+	for _, id := range ids {
+		observations, err := s.repo.ListObservations(ctx, id, since)
+		if err != nil {
+			log.Printf("Warning: failed to load capacity history for facility %s: %v", id, err)
+			continue
+		}
+		profiles := buildWaitTimeProfiles(observations, s.facilities.capacityFreshness, s.location, now)
+		if err := s.repo.ReplaceProfiles(ctx, id, profiles); err != nil {
+			log.Printf("Warning: failed to store wait time profiles for facility %s: %v", id, err)
+			continue
+		}
+		rebuilt++
+	}
+	return rebuilt, nil
+}
+
+// Prune removes reports older than the retention period
+func (s *CapacityHistoryService) Prune(ctx context.Context) (int64, error) {
+	return s.repo.DeleteObservationsBefore(ctx, s.now().Add(-s.retention))
+}
+
+// Start rebuilds profiles every interval, and prunes old reports once a day,
+// until ctx is done
+func (s *CapacityHistoryService) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		var lastPruned time.Time
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				rebuilt, err := s.RebuildProfiles(ctx)
+				if err != nil {
+					log.Printf("Capacity history rebuild failed: %v", err)
+				} else if rebuilt > 0 {
+					log.Printf("Capacity history: rebuilt wait time profiles for %d facilities", rebuilt)
+				}
+
+				if time.Since(lastPruned) < 24*time.Hour {
+					continue
+				}
+				pruned, err := s.Prune(ctx)
+				if err != nil {
+					log.Printf("Capacity history prune failed: %v", err)
+					continue
+				}
+				lastPruned = time.Now()
+				if pruned > 0 {
+					log.Printf("Capacity history: pruned %d old reports", pruned)
+				}
+			}
+		}
+	}()
+}
+
+// Forecast predicts the wait for an arrival at the facility, or one of its
+// wards when wardName is set, at the given time. The hour-of-week profile
+// around the arrival is blended with the current report while it is still
+// fresh, the current report counting less the further the arrival is from
+// when it was made.
+func (s *CapacityHistoryService) Forecast(ctx context.Context, facilityID, wardName string, at time.Time) (*entities.WaitForecast, error) {
+	facility, err := s.facilities.repo.GetByID(ctx, facilityID)
+	if err != nil {
+		return nil, err
+	}
+
+	liveWait, liveReportedAt := facility.AvgWaitMinutes, facility.AvgWaitReportedAt
+	if wardName != "" {
+		liveWait, liveReportedAt = nil, nil
+		if s.facilities.facilityWardRepo != nil {
+			ward, err := s.facilities.facilityWardRepo.GetByFacilityAndWard(ctx, facilityID, wardName)
+			if err != nil {
+				return nil, err
+			}
+			lastUpdated := ward.LastUpdated
+			liveWait, liveReportedAt = ward.AvgWaitMinutes, &lastUpdated
+		}
+	}
+
+	hour := entities.HourOfWeek(at.In(s.location))
+	profiles, err := s.repo.ListProfiles(ctx, []string{facilityID}, kernelHours(hour))
+	if err != nil {
+		return nil, err
+	}
+	byHour := make(map[int]*entities.WaitTimeProfile, len(forecastKernel))
+	for _, profile := range profiles {
+		if profile.WardName == wardName {
+			byHour[profile.HourOfWeek] = profile
+		}
+	}
+
+	forecast := &entities.WaitForecast{FacilityID: facilityID, WardName: wardName, At: at, Basis: entities.ForecastBasisNone}
+	if profile, ok := byHour[hour]; ok {
+		forecast.TypicalCapacityStatus = profile.TypicalCapacityStatus
+	}
+	seasonal, samples, hasSeasonal := seasonalWait(byHour, hour)
+	forecast.Samples = samples
+
+	policy := s.facilities.capacityFreshness
+	hasLive := liveWait != nil && policy.Freshness(entities.CapacityFieldWaitTime, liveReportedAt, s.now()).State != entities.FreshnessStale
+
+	var expected float64
+	switch {
+	case hasLive && hasSeasonal:
+		age := math.Max(0, at.Sub(*liveReportedAt).Hours())
+		weight := math.Pow(0.5, age/liveForecastHalfLife.Hours())
+		expected = weight*float64(*liveWait) + (1-weight)*seasonal
+		forecast.Basis = entities.ForecastBasisBlended
+	case hasLive:
+		expected = float64(*liveWait)
+		forecast.Basis = entities.ForecastBasisLive
+	case hasSeasonal:
+		expected = seasonal
+		forecast.Basis = entities.ForecastBasisSeasonal
+	default:
+		return forecast, nil
+	}
+	minutes := int(math.Round(expected))
+	forecast.ExpectedWaitMinutes = &minutes
+	return forecast, nil
+}
+
+// expectedWaits returns the seasonal wait, keyed by facility and then ward
+// name ("" for the facility itself), for an arrival at the given time. It is
+// used in search results where the live wait is missing or stale, so the
+// current report is not blended in.
+func (s *CapacityHistoryService) expectedWaits(ctx context.Context, facilityIDs []string, at time.Time) map[string]map[string]int {
+	if s == nil || len(facilityIDs) == 0 {
+		return nil
+	}
+	hour := entities.HourOfWeek(at.In(s.location))
+	profiles, err := s.repo.ListProfiles(ctx, facilityIDs, kernelHours(hour))
+	if err != nil {
+		log.Printf("Warning: failed to load wait time profiles: %v", err)
+		return nil
+	}
+
+	type profileKey struct{ facilityID, wardName string }
+	grouped := make(map[profileKey]map[int]*entities.WaitTimeProfile)
+	for _, profile := range profiles {
+		key := profileKey{profile.FacilityID, profile.WardName}
+		if grouped[key] == nil {
+			grouped[key] = make(map[int]*entities.WaitTimeProfile, len(forecastKernel))
+		}
+		grouped[key][profile.HourOfWeek] = profile
+	}
+
+	waits := make(map[string]map[string]int)
+	for key, byHour := range grouped {
+		wait, _, ok := seasonalWait(byHour, hour)
+		if !ok {
+			continue
+		}
+		if waits[key.facilityID] == nil {
+			waits[key.facilityID] = make(map[string]int)
+		}
+		waits[key.facilityID][key.wardName] = int(math.Round(wait))
+	}
+	return waits
+}
+
+// seasonalWait averages the profile waits around hour with forecastKernel,
+// each hour also weighted by how much history is behind it
+func seasonalWait(byHour map[int]*entities.WaitTimeProfile, hour int) (float64, int, bool) {
+	var sum, weight float64
+	samples := 0
+	for offset, k := range forecastKernel {
+		profile, ok := byHour[wrapHourOfWeek(hour+offset)]
+		if !ok || profile.AvgWaitMinutes == nil || profile.WaitWeight <= 0 {
+			continue
+		}
+		sum += k * profile.WaitWeight * *profile.AvgWaitMinutes
+		weight += k * profile.WaitWeight
+		samples += profile.Samples
+	}
+	if weight == 0 {
+		return 0, samples, false
+	}
+	return sum / weight, samples, true
+}
+
+// profileBucket accumulates the reports covering one ward and hour of the week
+type profileBucket struct {
+	waitSum      float64
+	waitWeight   float64
+	statusWeight map[string]float64
+	reports      map[*entities.CapacityObservation]bool
+}
+
+// buildWaitTimeProfiles builds hour-of-week profiles from a facility's
+// reports. Each report is assumed to hold until the next report of the same
+// field or until it would have expired under the freshness policy, and the
+// hours it covers are weighted by how long ago they were, halving every
+// capacityHistoryHalfLife before until.
+func buildWaitTimeProfiles(observations []*entities.CapacityObservation, policy entities.CapacityFreshnessPolicy, location *time.Location, until time.Time) []*entities.WaitTimeProfile {
+	byWard := make(map[string][]*entities.CapacityObservation)
+	for _, observation := range observations {
+		byWard[observation.WardName] = append(byWard[observation.WardName], observation)
+	}
+
+	buckets := make(map[string]map[int]*profileBucket)
+	bucket := func(wardName string, hour int) *profileBucket {
+		if buckets[wardName] == nil {
+			buckets[wardName] = make(map[int]*profileBucket)
+		}
+		b, ok := buckets[wardName][hour]
+		if !ok {
+			b = &profileBucket{statusWeight: make(map[string]float64), reports: make(map[*entities.CapacityObservation]bool)}
+			buckets[wardName][hour] = b
+		}
+		return b
+	}
+
+	for wardName, series := range byWard {
+		sort.SliceStable(series, func(i, j int) bool { return series[i].ObservedAt.Before(series[j].ObservedAt) })
+
+		for _, field := range []entities.CapacityField{entities.CapacityFieldStatus, entities.CapacityFieldWaitTime} {
+			var reports []*entities.CapacityObservation
+			for _, observation := range series {
+				if (field == entities.CapacityFieldStatus && observation.CapacityStatus != nil) ||
+					(field == entities.CapacityFieldWaitTime && observation.AvgWaitMinutes != nil) {
+					reports = append(reports, observation)
+				}
+			}
+
+			for i, report := range reports {
+				start := report.ObservedAt
+				end := start.Add(policy.TTL(field))
+				if i+1 < len(reports) && reports[i+1].ObservedAt.Before(end) {
+					end = reports[i+1].ObservedAt
+				}
+				if end.After(until) {
+					end = until
+				}
+
+				spreadOverHours(start, end, location, func(hour int, from time.Time, hours float64) {
+					weight := hours * math.Pow(0.5, until.Sub(from).Hours()/capacityHistoryHalfLife.Hours())
+					b := bucket(wardName, hour)
+					if field == entities.CapacityFieldWaitTime {
+						b.waitSum += weight * float64(*report.AvgWaitMinutes)
+						b.waitWeight += weight
+					} else {
+						b.statusWeight[*report.CapacityStatus] += weight
+					}
+					b.reports[report] = true
+				})
+			}
+		}
+	}
+
+	var profiles []*entities.WaitTimeProfile
+	for wardName, hours := range buckets {
+		for hour, b := range hours {
+			profile := &entities.WaitTimeProfile{
+				FacilityID: facilityIDOf(observations),
+				WardName:   wardName,
+				HourOfWeek: hour,
+				WaitWeight: b.waitWeight,
+				Samples:    len(b.reports),
+				UpdatedAt:  until,
+			}
+			if b.waitWeight > 0 {
+				avg := b.waitSum / b.waitWeight
+				profile.AvgWaitMinutes = &avg
+			}
+			profile.TypicalCapacityStatus = typicalStatus(b.statusWeight)
+			profiles = append(profiles, profile)
+		}
+	}
+	sort.Slice(profiles, func(i, j int) bool {
+		if profiles[i].WardName != profiles[j].WardName {
+			return profiles[i].WardName < profiles[j].WardName
+		}
+		return profiles[i].HourOfWeek < profiles[j].HourOfWeek
+	})
+	return profiles
+}
+
+// spreadOverHours calls fn for each local clock hour overlapping [start, end)
+// with its hour of the week, where the overlap starts and its length in hours
+func spreadOverHours(start, end time.Time, location *time.Location, fn func(hour int, from time.Time, hours float64)) {
+	for cursor := start; cursor.Before(end); {
+		local := cursor.In(location)
+		next := time.Date(local.Year(), local.Month(), local.Day(), local.Hour(), 0, 0, 0, location).Add(time.Hour)
+		if next.After(end) {
+			next = end
+		}
+		fn(entities.HourOfWeek(local), cursor, next.Sub(cursor).Hours())
+		cursor = next
+	}
+}
+
+// typicalStatus returns the status that held for the most weighted time,
+// preferring the first by name on a tie
+func typicalStatus(weights map[string]float64) *string {
+	var best string
+	bestWeight := 0.0
+	for status, weight := range weights {
+		if weight > bestWeight || (weight == bestWeight && weight > 0 && status < best) {
+			best, bestWeight = status, weight
+		}
+	}
+	if bestWeight == 0 {
+		return nil
+	}
+	return &best
+}
+
+func facilityIDOf(observations []*entities.CapacityObservation) string {
+	if len(observations) == 0 {
+		return ""
+	}
+	return observations[0].FacilityID
+}
+
+// kernelHours returns the hours of the week forecastKernel reads around hour
+func kernelHours(hour int) []int {
+	hours := make([]int, 0, len(forecastKernel))
+	for offset := range forecastKernel {
+		hours = append(hours, wrapHourOfWeek(hour+offset))
+	}
+	sort.Ints(hours)
+	return hours
+}
+
+func wrapHourOfWeek(hour int) int {
+	return ((hour % entities.HoursPerWeek) + entities.HoursPerWeek) % entities.HoursPerWeek
+}
+
+// isKnownCapacityStatus reports whether status is an actual report rather
+// than missing or decayed to unknown
+func isKnownCapacityStatus(status *string) bool {
+	return status != nil && *status != "" && *status != entities.CapacityStatusUnknown
+}
+
+// reportedSince reports whether after is a newer report time than before
+func reportedSince(before, after *time.Time) bool {
+	return after != nil && (before == nil || after.After(*before))
+}
+
+func latestReport(current time.Time, reportedAt *time.Time) time.Time {
+	if reportedAt != nil && reportedAt.After(current) {
+		return *reportedAt
+	}
+	return current
+}
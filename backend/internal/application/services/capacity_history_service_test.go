@@ -0,0 +1,249 @@
+package services
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+)
+
+// memoryCapacityHistory keeps recorded reports and serves preset profiles
+type memoryCapacityHistory struct {
+	observations []*entities.CapacityObservation
+	profiles     []*entities.WaitTimeProfile
+}
+
+func (r *memoryCapacityHistory) Record(ctx context.Context, observation *entities.CapacityObservation) error {
+	r.observations = append(r.observations, observation)
+	return nil
+}
+
+func (r *memoryCapacityHistory) ListObservedFacilityIDs(ctx context.Context, since time.Time) ([]string, error) {
+	seen := map[string]bool{}
+	var ids []string
+	for _, observation := range r.observations {
+		if !observation.ObservedAt.Before(since) && !seen[observation.FacilityID] {
+			seen[observation.FacilityID] = true
+			ids = append(ids, observation.FacilityID)
+		}
+	}
+	return ids, nil
+}
+
+func (r *memoryCapacityHistory) ListObservations(ctx context.Context, facilityID string, since time.Time) ([]*entities.CapacityObservation, error) {
+	var observations []*entities.CapacityObservation
+	for _, observation := range r.observations {
+		if observation.FacilityID == facilityID && !observation.ObservedAt.Before(since) {
+			observations = append(observations, observation)
+		}
+	}
+	return observations, nil
+}
+
+func (r *memoryCapacityHistory) ReplaceProfiles(ctx context.Context, facilityID string, profiles []*entities.WaitTimeProfile) error {
+	kept := r.profiles[:0]
+	for _, profile := range r.profiles {
+		if profile.FacilityID != facilityID {
+			kept = append(kept, profile)
+		}
+	}
+	r.profiles = append(kept, profiles...)
+	return nil
+}
+
+func (r *memoryCapacityHistory) ListProfiles(ctx context.Context, facilityIDs []string, hoursOfWeek []int) ([]*entities.WaitTimeProfile, error) {
+	var profiles []*entities.WaitTimeProfile
+	for _, profile := range r.profiles {
+		for _, id := range facilityIDs {
+			for _, hour := range hoursOfWeek {
+				if profile.FacilityID == id && profile.HourOfWeek == hour {
+					profiles = append(profiles, profile)
+				}
+			}
+		}
+	}
+	return profiles, nil
+}
+
+func (r *memoryCapacityHistory) DeleteObservationsBefore(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+func TestBuildWaitTimeProfiles(t *testing.T) {
+	lagos := time.FixedZone("WAT", 60*60)
+	monday := func(day, hour, minute int) time.Time {
+		return time.Date(2026, 3, day, hour, minute, 0, 0, lagos)
+	}
+	busy := "busy"
+	wait := func(minutes int) *int { return &minutes }
+
+	observations := []*entities.CapacityObservation{
+		{FacilityID: "fac-1", CapacityStatus: &busy, AvgWaitMinutes: wait(60), ObservedAt: monday(2, 18, 0)},
+		{FacilityID: "fac-1", AvgWaitMinutes: wait(30), ObservedAt: monday(2, 19, 30)},
+		// In the emergency ward the same hour was much busier five weeks earlier
+		{FacilityID: "fac-1", WardName: "emergency", AvgWaitMinutes: wait(60), ObservedAt: monday(2, 18, 0)},
+		{FacilityID: "fac-1", WardName: "emergency", AvgWaitMinutes: wait(120), ObservedAt: monday(2, 18, 0).AddDate(0, 0, -35)},
+	}
+	until := monday(9, 12, 0)
+
+	profiles := buildWaitTimeProfiles(observations, entities.DefaultCapacityFreshnessPolicy(), lagos, until)
+	byHour := map[int]*entities.WaitTimeProfile{}
+	emergency := map[int]*entities.WaitTimeProfile{}
+	for _, profile := range profiles {
+		if profile.WardName == "emergency" {
+			emergency[profile.HourOfWeek] = profile
+			continue
+		}
+		byHour[profile.HourOfWeek] = profile
+	}
+
+	const monday18, monday19 = 24 + 18, 24 + 19
+	evening := byHour[monday18]
+	if evening == nil || evening.AvgWaitMinutes == nil {
+		t.Fatalf("expected a Monday 18:00 profile, got %+v", profiles)
+	}
+	if *evening.AvgWaitMinutes != 60 {
+		t.Fatalf("expected 60 minutes at 18:00, got %.1f", *evening.AvgWaitMinutes)
+	}
+	if evening.TypicalCapacityStatus == nil || *evening.TypicalCapacityStatus != "busy" {
+		t.Fatalf("expected busy to be typical, got %v", evening.TypicalCapacityStatus)
+	}
+	if evening.Samples != 1 {
+		t.Fatalf("expected 1 sample at 18:00, got %d", evening.Samples)
+	}
+
+	// The recent report outweighs the older, busier one
+	ward := emergency[monday18]
+	if ward == nil || ward.AvgWaitMinutes == nil || *ward.AvgWaitMinutes <= 60 || *ward.AvgWaitMinutes >= 90 {
+		t.Fatalf("expected a recency-weighted ward wait between 60 and 90, got %+v", ward)
+	}
+	if ward.Samples != 2 {
+		t.Fatalf("expected 2 ward samples at 18:00, got %d", ward.Samples)
+	}
+
+	// 19:00-19:30 is the 60 minute report, 19:30-20:00 the 30 minute one
+	split := byHour[monday19]
+	if split == nil || split.AvgWaitMinutes == nil || math.Abs(*split.AvgWaitMinutes-45) > 0.5 {
+		t.Fatalf("expected about 45 minutes at 19:00, got %+v", split)
+	}
+
+	// A report holds no longer than its freshness window: 19:30 + 4h
+	if _, ok := byHour[24+23]; !ok {
+		t.Fatal("expected the 30 minute report to cover 23:00")
+	}
+	if profile, ok := byHour[24*2]; ok && profile.AvgWaitMinutes != nil {
+		t.Fatalf("expected no wait after the report expired, got %.1f", *profile.AvgWaitMinutes)
+	}
+}
+
+func TestCapacityHistory_Forecast(t *testing.T) {
+	now := time.Date(2026, 3, 2, 17, 0, 0, 0, time.UTC) // Monday
+	seasonal := 40.0
+	hour := entities.HourOfWeek(now)
+
+	tests := []struct {
+		name      string
+		liveWait  *int
+		reported  time.Time
+		at        time.Time
+		profiles  bool
+		wantWait  *int
+		wantBasis entities.ForecastBasis
+	}{
+		{name: "profile only", at: now, profiles: true, wantWait: intPtr(40), wantBasis: entities.ForecastBasisSeasonal},
+		{name: "fresh report at arrival", liveWait: intPtr(80), reported: now, at: now, profiles: true, wantWait: intPtr(80), wantBasis: entities.ForecastBasisBlended},
+		{name: "report gives way to profile", liveWait: intPtr(80), reported: now, at: now.Add(time.Hour), profiles: true, wantWait: intPtr(60), wantBasis: entities.ForecastBasisBlended},
+		{name: "stale report is ignored", liveWait: intPtr(80), reported: now.Add(-5 * time.Hour), at: now, profiles: true, wantWait: intPtr(40), wantBasis: entities.ForecastBasisSeasonal},
+		{name: "no history", liveWait: intPtr(80), reported: now, at: now, wantWait: intPtr(80), wantBasis: entities.ForecastBasisLive},
+		{name: "nothing known", at: now, wantBasis: entities.ForecastBasisNone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			facility := entities.Facility{ID: "fac-1", AvgWaitMinutes: tt.liveWait}
+			if tt.liveWait != nil {
+				facility.AvgWaitReportedAt = timePtr(tt.reported)
+			}
+			facilities := NewFacilityService(&versionedFacilityRepo{facilities: map[string]entities.Facility{"fac-1": facility}}, nil, nil, nil, nil)
+
+			repo := &memoryCapacityHistory{}
+			if tt.profiles {
+				// Flat across the arrival hour and its neighbours
+				for _, h := range []int{hour - 1, hour, hour + 1, hour + 2} {
+					repo.profiles = append(repo.profiles, &entities.WaitTimeProfile{FacilityID: "fac-1", HourOfWeek: h, AvgWaitMinutes: &seasonal, WaitWeight: 3, Samples: 3})
+				}
+			}
+			history := NewCapacityHistoryService(repo, facilities)
+			history.now = func() time.Time { return now }
+
+			forecast, err := history.Forecast(context.Background(), "fac-1", "", tt.at)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if forecast.Basis != tt.wantBasis {
+				t.Fatalf("expected basis %s, got %s", tt.wantBasis, forecast.Basis)
+			}
+			if !intPtrEqual(forecast.ExpectedWaitMinutes, tt.wantWait) {
+				t.Fatalf("expected wait %v, got %v", derefInt(tt.wantWait), derefInt(forecast.ExpectedWaitMinutes))
+			}
+		})
+	}
+}
+
+func TestCapacityHistory_RecordsReportsButNotDecays(t *testing.T) {
+	now := time.Date(2026, 3, 2, 17, 0, 0, 0, time.UTC)
+	repo := &versionedFacilityRepo{facilities: map[string]entities.Facility{
+		"fac-1": {ID: "fac-1", Version: 1},
+	}}
+	facilities := NewFacilityService(repo, nil, nil, nil, nil)
+	history := &memoryCapacityHistory{}
+	facilities.SetCapacityHistory(NewCapacityHistoryService(history, facilities))
+
+	// An operator reports a wait time
+	facility, _ := repo.GetByID(context.Background(), "fac-1")
+	facility.AvgWaitMinutes = intPtr(25)
+	facility.MarkCapacityReported(entities.CapacityFieldWaitTime, now)
+	if err := facilities.Update(context.Background(), facility); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(history.observations) != 1 || *history.observations[0].AvgWaitMinutes != 25 || !history.observations[0].ObservedAt.Equal(now) {
+		t.Fatalf("expected the report to be recorded, got %+v", history.observations)
+	}
+	if history.observations[0].CapacityStatus != nil {
+		t.Fatal("fields that were not reported must not be recorded")
+	}
+
+	// The same value reported again later is a new report
+	facility, _ = repo.GetByID(context.Background(), "fac-1")
+	facility.MarkCapacityReported(entities.CapacityFieldWaitTime, now.Add(time.Hour))
+	if err := facilities.Update(context.Background(), facility); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(history.observations) != 2 {
+		t.Fatalf("expected the repeated report to be recorded, got %d", len(history.observations))
+	}
+
+	// Expiring the value is not a report
+	existing, _ := repo.GetByID(context.Background(), "fac-1")
+	expired := *existing
+	expired.ClearCapacity(entities.CapacityFieldWaitTime)
+	if err := facilities.update(context.Background(), "facility.capacity_expire", existing, &expired, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(history.observations) != 2 {
+		t.Fatalf("expected the decay not to be recorded, got %d observations", len(history.observations))
+	}
+}
+
+func intPtr(v int) *int {
+	return &v
+}
+
+func derefInt(v *int) interface{} {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
@@ -0,0 +1,513 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/repositories"
+	apperrors "github.com/zatekoja/Patientpricediscoverydesign/backend/pkg/errors"
+)
+
+const (
+	maxBasketItems          = 20
+	defaultBasketDistanceKm = 10.0
+	maxBasketDistanceKm     = 100.0
+	defaultBasketOptions    = 5
+	maxBasketOptions        = 20
+	// maxBasketVisits caps how many facilities a split basket spreads over
+	maxBasketVisits = 3
+	// basketShortlist is how many facilities, best coverage first, are
+	// combined into options, besides the cheapest facility for each item
+	basketShortlist = 12
+	maxBasketOffers = 5000
+	// maxResolveQueries caps the procedure searches for one free-text item
+	maxResolveQueries = 6
+	// basketCurrency is what quotes are priced in. Offers and fees in any
+	// other currency are left out, since they cannot be summed with it.
+	basketCurrency = "NGN"
+)
+
+// CareBasketService quotes the cheapest nearby ways to get a set of
+// procedures done, registration fees and fee waivers included
+type CareBasketService struct {
+	offers             repositories.ProcedureOfferRepository
+	procedures         repositories.ProcedureRepository
+	fees               repositories.FacilityProcedureCategoryRepository
+	waivers            repositories.FeeWaiverRepository
+	procedureSearch    *ProcedureSearchService
+	queryUnderstanding *QueryUnderstandingService
+}
+
+// NewCareBasketService creates a new care basket service
+func NewCareBasketService(
+	offers repositories.ProcedureOfferRepository,
+	procedures repositories.ProcedureRepository,
+	fees repositories.FacilityProcedureCategoryRepository,
+	waivers repositories.FeeWaiverRepository,
+) *CareBasketService {
+	return &CareBasketService{
+		offers:     offers,
+		procedures: procedures,
+		fees:       fees,
+		waivers:    waivers,
+	}
+}
+
+// SetProcedureSearch resolves free-text basket items to procedures. Without
+// it only items given by procedure ID resolve.
+func (s *CareBasketService) SetProcedureSearch(search *ProcedureSearchService) {
+	s.procedureSearch = search
+}
+
+// SetQueryUnderstanding corrects, translates and expands free-text items
+// before they are searched
+func (s *CareBasketService) SetQueryUnderstanding(qu *QueryUnderstandingService) {
+	s.queryUnderstanding = qu
+}
+
+// Quote returns the best options for buying the basket near the request's
+// location. Options with fewer missing items come first, then cheaper ones,
+// then ones needing fewer visits. Only with AllowSplit do options spread the
+// items over several facilities, each item going to the cheapest of them.
+func (s *CareBasketService) Quote(ctx context.Context, req entities.CareBasketRequest) (*entities.CareBasketQuote, error) {
+	if err := normalizeBasketRequest(&req); err != nil {
+		return nil, err
+	}
+
+	items, unresolved, err := s.resolveItems(ctx, req.Items)
+	if err != nil {
+		return nil, err
+	}
+	quote := &entities.CareBasketQuote{
+		Items:           items,
+		UnresolvedItems: unresolved,
+		Options:         []entities.CareBasketOption{},
+	}
+	if len(items) == 0 {
+		return quote, nil
+	}
+
+	procedureIDs := make([]string, len(items))
+	for i, item := range items {
+		procedureIDs[i] = item.ProcedureID
+	}
+	offers, err := s.offers.ListOffers(ctx, repositories.ProcedureOfferParams{
+		ProcedureIDs:      procedureIDs,
+		Latitude:          req.Latitude,
+		Longitude:         req.Longitude,
+		RadiusKm:          req.MaxDistanceKm,
+		InsuranceProvider: req.InsuranceProvider,
+		Limit:             maxBasketOffers,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	facilities := shortlistBasketFacilities(groupBasketOffers(offers), items)
+	if err := s.loadFees(ctx, facilities); err != nil {
+		return nil, err
+	}
+
+	maxVisits := 1
+	if req.AllowSplit {
+		maxVisits = maxBasketVisits
+	}
+	var options []entities.CareBasketOption
+	forEachCombination(len(facilities), maxVisits, func(combination []int) {
+		chosen := make([]*basketFacility, len(combination))
+		for i, index := range combination {
+			chosen[i] = facilities[index]
+		}
+		if option, ok := buildBasketOption(chosen, items); ok {
+			options = append(options, option)
+		}
+	})
+
+	sort.SliceStable(options, func(i, j int) bool {
+		a, b := options[i], options[j]
+		if len(a.MissingItems) != len(b.MissingItems) {
+			return len(a.MissingItems) < len(b.MissingItems)
+		}
+		if a.Total != b.Total {
+			return a.Total < b.Total
+		}
+		if len(a.Visits) != len(b.Visits) {
+			return len(a.Visits) < len(b.Visits)
+		}
+		return basketDistance(a) < basketDistance(b)
+	})
+	if len(options) > req.Limit {
+		options = options[:req.Limit]
+	}
+	if options != nil {
+		quote.Options = options
+	}
+	return quote, nil
+}
+
+// normalizeBasketRequest validates the request and fills in its defaults
+func normalizeBasketRequest(req *entities.CareBasketRequest) error {
+	if len(req.Items) == 0 {
+		return apperrors.NewValidationError("at least one item is required")
+	}
+	if len(req.Items) > maxBasketItems {
+		return apperrors.NewValidationError(fmt.Sprintf("at most %d items are allowed", maxBasketItems))
+	}
+	for _, item := range req.Items {
+		if strings.TrimSpace(item.ProcedureID) == "" && strings.TrimSpace(item.Query) == "" {
+			return apperrors.NewValidationError("each item needs a procedure_id or a query")
+		}
+	}
+	if req.Latitude == 0 && req.Longitude == 0 {
+		return apperrors.NewValidationError("latitude and longitude are required")
+	}
+	if req.Latitude < -90 || req.Latitude > 90 || req.Longitude < -180 || req.Longitude > 180 {
+		return apperrors.NewValidationError("latitude or longitude is out of range")
+	}
+	if req.MaxDistanceKm < 0 || req.MaxDistanceKm > maxBasketDistanceKm {
+		return apperrors.NewValidationError(fmt.Sprintf("max_distance_km must be between 0 and %g", maxBasketDistanceKm))
+	}
+	if req.MaxDistanceKm == 0 {
+		req.MaxDistanceKm = defaultBasketDistanceKm
+	}
+	if req.Limit < 0 || req.Limit > maxBasketOptions {
+		return apperrors.NewValidationError(fmt.Sprintf("limit must be between 0 and %d", maxBasketOptions))
+	}
+	if req.Limit == 0 {
+		req.Limit = defaultBasketOptions
+	}
+	req.InsuranceProvider = strings.TrimSpace(req.InsuranceProvider)
+	return nil
+}
+
+// resolveItems turns basket items into procedures. Items naming an unknown
+// procedure, or whose text matches none, are returned as unresolved, and
+// items resolving to a procedure already in the basket are dropped.
+func (s *CareBasketService) resolveItems(ctx context.Context, items []entities.CareBasketItem) ([]entities.BasketProcedure, []entities.CareBasketItem, error) {
+	resolved := []entities.BasketProcedure{}
+	unresolved := []entities.CareBasketItem{}
+	seen := map[string]bool{}
+	for _, item := range items {
+		var (
+			procedure *entities.BasketProcedure
+			err       error
+		)
+		if id := strings.TrimSpace(item.ProcedureID); id != "" {
+			procedure, err = s.resolveProcedureID(ctx, id)
+		} else {
+			procedure, err = s.resolveQuery(ctx, strings.TrimSpace(item.Query))
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		if procedure == nil {
+			unresolved = append(unresolved, item)
+			continue
+		}
+		if seen[procedure.ProcedureID] {
+			continue
+		}
+		seen[procedure.ProcedureID] = true
+		resolved = append(resolved, *procedure)
+	}
+	return resolved, unresolved, nil
+}
+
+func (s *CareBasketService) resolveProcedureID(ctx context.Context, id string) (*entities.BasketProcedure, error) {
+	procedure, err := s.procedures.GetByID(ctx, id)
+	if err != nil {
+		var appErr *apperrors.AppError
+		if errors.As(err, &appErr) && appErr.Type == apperrors.ErrorTypeNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if procedure == nil {
+		return nil, nil
+	}
+	name := procedure.DisplayName
+	if name == "" {
+		name = procedure.Name
+	}
+	return &entities.BasketProcedure{ProcedureID: procedure.ID, Name: name}, nil
+}
+
+// resolveQuery searches procedures for the item's text, trying the
+// corrected, translated and normalized forms of it before its search terms,
+// and takes the top match of the first search finding any
+func (s *CareBasketService) resolveQuery(ctx context.Context, query string) (*entities.BasketProcedure, error) {
+	if s.procedureSearch == nil {
+		return nil, nil
+	}
+
+	candidates := []string{query}
+	if s.queryUnderstanding != nil {
+		interpretation := s.queryUnderstanding.Interpret(query)
+		candidates = append([]string{
+			interpretation.CorrectedQuery,
+			interpretation.TranslatedQuery,
+			interpretation.NormalizedQuery,
+		}, candidates...)
+		candidates = append(candidates, interpretation.SearchTerms...)
+	}
+
+	tried := map[string]bool{}
+	for _, candidate := range candidates {
+		candidate = strings.TrimSpace(candidate)
+		key := strings.ToLower(candidate)
+		if candidate == "" || tried[key] {
+			continue
+		}
+		if len(tried) == maxResolveQueries {
+			break
+		}
+		tried[key] = true
+
+		results, _, err := s.procedureSearch.Search(ctx, repositories.ProcedureSearchParams{Query: candidate, Limit: 1})
+		if err != nil {
+			return nil, err
+		}
+		if len(results) > 0 && results[0] != nil {
+			return &entities.BasketProcedure{
+				ProcedureID: results[0].ID,
+				Name:        results[0].Label(),
+				Query:       query,
+			}, nil
+		}
+	}
+	return nil, nil
+}
+
+// basketFacility is a facility's cheapest offer for each basket procedure it
+// has, with the registration fees a visit to it costs
+type basketFacility struct {
+	offer  *entities.ProcedureOffer
+	prices map[string]*entities.ProcedureOffer
+	fees   []entities.BasketFee
+	fee    float64
+	waiver *entities.FeeWaiver
+}
+
+// groupBasketOffers groups the offers in the basket currency by facility
+func groupBasketOffers(offers []*entities.ProcedureOffer) []*basketFacility {
+	byID := map[string]*basketFacility{}
+	var facilities []*basketFacility
+	for _, offer := range offers {
+		if offer == nil || !isBasketCurrency(offer.Currency) {
+			continue
+		}
+		facility, ok := byID[offer.FacilityID]
+		if !ok {
+			facility = &basketFacility{offer: offer, prices: map[string]*entities.ProcedureOffer{}}
+			byID[offer.FacilityID] = facility
+			facilities = append(facilities, facility)
+		}
+		if current, ok := facility.prices[offer.ProcedureID]; !ok || offer.Price < current.Price {
+			facility.prices[offer.ProcedureID] = offer
+		}
+	}
+	return facilities
+}
+
+// shortlistBasketFacilities keeps the facilities covering the most of the
+// basket, cheapest then nearest first, plus the cheapest facility for each
+// item so that split options can use it
+func shortlistBasketFacilities(facilities []*basketFacility, items []entities.BasketProcedure) []*basketFacility {
+	covered := func(f *basketFacility) (int, float64) {
+		count, total := 0, 0.0
+		for _, item := range items {
+			if offer, ok := f.prices[item.ProcedureID]; ok {
+				count++
+				total += offer.Price
+			}
+		}
+		return count, total
+	}
+	sort.SliceStable(facilities, func(i, j int) bool {
+		ci, pi := covered(facilities[i])
+		cj, pj := covered(facilities[j])
+		if ci != cj {
+			return ci > cj
+		}
+		if pi != pj {
+			return pi < pj
+		}
+		return facilities[i].offer.DistanceKm < facilities[j].offer.DistanceKm
+	})
+
+	shortlist := facilities[:min(len(facilities), basketShortlist)]
+	listed := map[*basketFacility]bool{}
+	for _, f := range shortlist {
+		listed[f] = true
+	}
+	for _, item := range items {
+		var cheapest *basketFacility
+		for _, f := range facilities {
+			offer, ok := f.prices[item.ProcedureID]
+			if ok && (cheapest == nil || offer.Price < cheapest.prices[item.ProcedureID].Price) {
+				cheapest = f
+			}
+		}
+		if cheapest != nil && !listed[cheapest] {
+			listed[cheapest] = true
+			shortlist = append(shortlist, cheapest)
+		}
+	}
+	return shortlist
+}
+
+// loadFees reads the facilities' registration fees and active fee waivers,
+// each in one batch. Waivers that cannot be read are left out rather than
+// failing the quote.
+func (s *CareBasketService) loadFees(ctx context.Context, facilities []*basketFacility) error {
+	facilityIDs := make([]string, len(facilities))
+	for i, facility := range facilities {
+		facilityIDs[i] = facility.offer.FacilityID
+	}
+
+	if s.fees != nil && len(facilityIDs) > 0 {
+		feesByFacility, err := ListRegistrationFeesByFacility(ctx, s.fees, facilityIDs)
+		if err != nil {
+			return err
+		}
+		for _, facility := range facilities {
+			for _, fee := range feesByFacility[facility.offer.FacilityID] {
+				if !isBasketCurrency(fee.Currency) {
+					continue
+				}
+				facility.fees = append(facility.fees, entities.BasketFee{ID: fee.ID, Name: fee.ProcedureName, Price: fee.Price})
+				facility.fee += fee.Price
+			}
+		}
+	}
+
+	var charging []string
+	for _, facility := range facilities {
+		if facility.fee > 0 {
+			charging = append(charging, facility.offer.FacilityID)
+		}
+	}
+	if s.waivers == nil || len(charging) == 0 {
+		return nil
+	}
+	waivers, err := s.waivers.GetActiveFacilityWaivers(ctx, charging)
+	if err != nil {
+		log.Printf("Warning: Failed to read fee waivers of %d facilities: %v", len(charging), err)
+		return nil
+	}
+	for _, facility := range facilities {
+		if waiver := waivers[facility.offer.FacilityID]; facility.fee > 0 && waiver != nil && waiver.IsValid() {
+			facility.waiver = waiver
+		}
+	}
+	return nil
+}
+
+// isBasketCurrency reports whether an amount is in the basket currency. An
+// amount without a currency is taken to be in it.
+func isBasketCurrency(currency string) bool {
+	currency = strings.TrimSpace(currency)
+	return currency == "" || strings.EqualFold(currency, basketCurrency)
+}
+
+// buildBasketOption assigns each item to the cheapest of the facilities
+// offering it. It reports false when a facility would get no items, since
+// the option without that visit is better. The offers are all in the basket
+// currency, as groupBasketOffers keeps no others.
+func buildBasketOption(facilities []*basketFacility, items []entities.BasketProcedure) (entities.CareBasketOption, bool) {
+	visits := make([]entities.BasketVisit, len(facilities))
+	option := entities.CareBasketOption{MissingItems: []entities.BasketProcedure{}, Currency: basketCurrency}
+	for _, item := range items {
+		best := -1
+		for i, f := range facilities {
+			offer, ok := f.prices[item.ProcedureID]
+			if ok && (best < 0 || offer.Price < facilities[best].prices[item.ProcedureID].Price) {
+				best = i
+			}
+		}
+		if best < 0 {
+			option.MissingItems = append(option.MissingItems, item)
+			continue
+		}
+		offer := facilities[best].prices[item.ProcedureID]
+		visits[best].Items = append(visits[best].Items, entities.BasketItemPrice{
+			ProcedureID: item.ProcedureID,
+			Name:        item.Name,
+			Price:       offer.Price,
+			Currency:    basketCurrency,
+		})
+		visits[best].ItemsTotal += offer.Price
+	}
+
+	for i, f := range facilities {
+		visit := &visits[i]
+		if len(visit.Items) == 0 && len(facilities) > 1 {
+			return entities.CareBasketOption{}, false
+		}
+		visit.FacilityID = f.offer.FacilityID
+		visit.FacilityName = f.offer.FacilityName
+		visit.Address = f.offer.Address
+		visit.Location = f.offer.Location
+		visit.DistanceKm = roundBasketAmount(f.offer.DistanceKm)
+		visit.RegistrationFees = append([]entities.BasketFee{}, f.fees...)
+		visit.RegistrationFee = f.fee
+		if visit.Items == nil {
+			visit.Items = []entities.BasketItemPrice{}
+		}
+
+		savings := 0.0
+		if f.waiver != nil {
+			savings = f.fee - f.waiver.ApplyToServiceFee(f.fee)
+			visit.FeeWaiver = &entities.BasketFeeWaiver{
+				SponsorName:  f.waiver.SponsorName,
+				WaiverType:   f.waiver.WaiverType,
+				WaiverAmount: f.waiver.WaiverAmount,
+				Savings:      savings,
+			}
+		}
+		visit.Total = visit.ItemsTotal + visit.RegistrationFee - savings
+
+		option.ItemsTotal += visit.ItemsTotal
+		option.RegistrationFees += visit.RegistrationFee
+		option.WaiverSavings += savings
+		option.Total += visit.Total
+	}
+	option.Visits = visits
+	return option, true
+}
+
+func basketDistance(option entities.CareBasketOption) float64 {
+	total := 0.0
+	for _, visit := range option.Visits {
+		total += visit.DistanceKm
+	}
+	return total
+}
+
+func roundBasketAmount(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// forEachCombination calls fn with every set of 1 to k indexes below n, in
+// increasing order. fn must not keep the slice.
+func forEachCombination(n, k int, fn func([]int)) {
+	combination := make([]int, 0, k)
+	var extend func(start int)
+	extend = func(start int) {
+		for i := start; i < n; i++ {
+			combination = append(combination, i)
+			fn(combination)
+			if len(combination) < k {
+				extend(i + 1)
+			}
+			combination = combination[:len(combination)-1]
+		}
+	}
+	extend(0)
+}
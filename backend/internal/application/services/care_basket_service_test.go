@@ -0,0 +1,271 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/repositories"
+	apperrors "github.com/zatekoja/Patientpricediscoverydesign/backend/pkg/errors"
+)
+
+type stubProcedureOffers struct {
+	offers []*entities.ProcedureOffer
+	params repositories.ProcedureOfferParams
+}
+
+func (s *stubProcedureOffers) ListOffers(ctx context.Context, params repositories.ProcedureOfferParams) ([]*entities.ProcedureOffer, error) {
+	s.params = params
+	return s.offers, nil
+}
+
+type stubBasketProcedures struct {
+	repositories.ProcedureRepository
+	procedures map[string]*entities.Procedure
+}
+
+func (s *stubBasketProcedures) GetByID(ctx context.Context, id string) (*entities.Procedure, error) {
+	if procedure, ok := s.procedures[id]; ok {
+		return procedure, nil
+	}
+	return nil, apperrors.NewNotFoundError("procedure not found")
+}
+
+// stubBasketFees lists the facilities' items of the category and counts the
+// queries
+type stubBasketFees struct {
+	items map[string][]*entities.FacilityProcedure
+	calls int
+}
+
+func (s *stubBasketFees) ListByFacilitiesAndCategory(ctx context.Context, facilityIDs []string, category string) (map[string][]*entities.FacilityProcedure, error) {
+	s.calls++
+	byFacility := map[string][]*entities.FacilityProcedure{}
+	for _, facilityID := range facilityIDs {
+		for _, item := range s.items[facilityID] {
+			if item.ProcedureCategory == category {
+				byFacility[facilityID] = append(byFacility[facilityID], item)
+			}
+		}
+	}
+	return byFacility, nil
+}
+
+type stubBasketWaivers struct {
+	repositories.FeeWaiverRepository
+	waivers map[string]*entities.FeeWaiver
+	calls   int
+}
+
+func (s *stubBasketWaivers) GetActiveFacilityWaivers(ctx context.Context, facilityIDs []string) (map[string]*entities.FeeWaiver, error) {
+	s.calls++
+	waivers := map[string]*entities.FeeWaiver{}
+	for _, facilityID := range facilityIDs {
+		if waiver, ok := s.waivers[facilityID]; ok {
+			waivers[facilityID] = waiver
+		}
+	}
+	return waivers, nil
+}
+
+func basketOffer(facilityID, procedureID string, price, distanceKm float64) *entities.ProcedureOffer {
+	return &entities.ProcedureOffer{
+		FacilityID:   facilityID,
+		FacilityName: facilityID + " clinic",
+		ProcedureID:  procedureID,
+		Price:        price,
+		Currency:     "NGN",
+		DistanceKm:   distanceKm,
+	}
+}
+
+// newTestBasketService prices an X-ray and a blood count at three clinics:
+// "full" offers both and charges a folder fee, "xray" only has a pricier
+// X-ray and no fees, and "lab" has the cheapest blood count and a card fee
+func newTestBasketService() (*CareBasketService, *stubBasketWaivers) {
+	offers := &stubProcedureOffers{offers: []*entities.ProcedureOffer{
+		basketOffer("full", "xray", 1000, 2),
+		basketOffer("full", "fbc", 5000, 2),
+		basketOffer("xray", "xray", 3000, 1),
+		basketOffer("lab", "fbc", 1000, 3),
+	}}
+	procedures := &stubBasketProcedures{procedures: map[string]*entities.Procedure{
+		"xray": {ID: "xray", Name: "XRAY CHEST", DisplayName: "Chest X-Ray"},
+		"fbc":  {ID: "fbc", Name: "Full Blood Count"},
+	}}
+	fees := &stubBasketFees{items: map[string][]*entities.FacilityProcedure{
+		"full": {
+			{ID: "full-folder", ProcedureName: "Folder Fee", ProcedureCategory: "registration", Price: 500},
+			{ID: "full-report", ProcedureName: "Medical Report", ProcedureCategory: "administrative", Price: 4000},
+		},
+		"lab": {{ID: "lab-card", ProcedureName: "Treatment Card", ProcedureCategory: "administrative", Price: 200}},
+	}}
+	waivers := &stubBasketWaivers{waivers: map[string]*entities.FeeWaiver{}}
+	return NewCareBasketService(offers, procedures, fees, waivers), waivers
+}
+
+func basketRequest(allowSplit bool) entities.CareBasketRequest {
+	return entities.CareBasketRequest{
+		Items:      []entities.CareBasketItem{{ProcedureID: "xray"}, {ProcedureID: "fbc"}},
+		Latitude:   6.5244,
+		Longitude:  3.3792,
+		AllowSplit: allowSplit,
+	}
+}
+
+func TestCareBasketQuote_SingleFacilityFirst(t *testing.T) {
+	svc, _ := newTestBasketService()
+
+	quote, err := svc.Quote(context.Background(), basketRequest(false))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(quote.Options) != 3 {
+		t.Fatalf("expected one option per facility, got %d", len(quote.Options))
+	}
+	best := quote.Options[0]
+	if len(best.Visits) != 1 || best.Visits[0].FacilityID != "full" {
+		t.Fatalf("expected the facility offering everything first, got %+v", best.Visits)
+	}
+	if len(best.MissingItems) != 0 || best.ItemsTotal != 6000 || best.RegistrationFees != 500 || best.Total != 6500 {
+		t.Fatalf("unexpected best option: %+v", best)
+	}
+	if len(best.Visits[0].RegistrationFees) != 1 || best.Visits[0].RegistrationFees[0].ID != "full-folder" {
+		t.Fatalf("expected only the folder fee, got %+v", best.Visits[0].RegistrationFees)
+	}
+	for _, option := range quote.Options[1:] {
+		if len(option.MissingItems) != 1 {
+			t.Fatalf("expected the other facilities to miss an item, got %+v", option)
+		}
+	}
+}
+
+func TestCareBasketQuote_SplitAssignsItemsToCheapestFacility(t *testing.T) {
+	svc, _ := newTestBasketService()
+
+	quote, err := svc.Quote(context.Background(), basketRequest(true))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	best := quote.Options[0]
+	if len(best.Visits) != 2 || best.Total != 2700 {
+		t.Fatalf("expected a split over two facilities costing 2700, got %+v", best)
+	}
+	assigned := map[string]string{}
+	for _, visit := range best.Visits {
+		for _, item := range visit.Items {
+			assigned[item.ProcedureID] = visit.FacilityID
+		}
+	}
+	if assigned["xray"] != "full" || assigned["fbc"] != "lab" {
+		t.Fatalf("expected each item at its cheapest facility, got %v", assigned)
+	}
+	for _, option := range quote.Options {
+		for _, visit := range option.Visits {
+			if len(visit.Items) == 0 {
+				t.Fatalf("expected no visits without items, got %+v", option)
+			}
+		}
+	}
+}
+
+func TestCareBasketQuote_AppliesFeeWaiver(t *testing.T) {
+	svc, waivers := newTestBasketService()
+	waivers.waivers["full"] = &entities.FeeWaiver{SponsorName: "Lagos Health Trust", WaiverType: "full", IsActive: true}
+
+	quote, err := svc.Quote(context.Background(), basketRequest(false))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	best := quote.Options[0]
+	waiver := best.Visits[0].FeeWaiver
+	if waiver == nil || waiver.SponsorName != "Lagos Health Trust" || waiver.Savings != 500 {
+		t.Fatalf("expected the waiver to save the folder fee, got %+v", waiver)
+	}
+	if best.WaiverSavings != 500 || best.Total != 6000 {
+		t.Fatalf("expected the waiver off the total, got %+v", best)
+	}
+}
+
+func TestCareBasketQuote_BatchesFeeAndWaiverLookups(t *testing.T) {
+	svc, waivers := newTestBasketService()
+	fees := svc.fees.(*stubBasketFees)
+
+	if _, err := svc.Quote(context.Background(), basketRequest(true)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fees.calls != len(registrationFeeCategories) {
+		t.Fatalf("expected one fee query per category, got %d", fees.calls)
+	}
+	if waivers.calls != 1 {
+		t.Fatalf("expected one waiver query, got %d", waivers.calls)
+	}
+}
+
+func TestCareBasketQuote_LeavesOutOtherCurrencies(t *testing.T) {
+	svc, _ := newTestBasketService()
+	offers := svc.offers.(*stubProcedureOffers)
+	usd := basketOffer("usd", "xray", 10, 1)
+	usd.Currency = "USD"
+	offers.offers = append(offers.offers, usd)
+	svc.fees.(*stubBasketFees).items["full"][0].Currency = "USD"
+
+	quote, err := svc.Quote(context.Background(), basketRequest(true))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, option := range quote.Options {
+		if option.Currency != "NGN" {
+			t.Fatalf("expected every option in NGN, got %+v", option)
+		}
+		for _, visit := range option.Visits {
+			if visit.FacilityID == "usd" {
+				t.Fatalf("expected the USD offer left out, got %+v", option)
+			}
+			if visit.FacilityID == "full" && visit.RegistrationFee != 0 {
+				t.Fatalf("expected the USD folder fee left out, got %+v", visit)
+			}
+		}
+	}
+	if best := quote.Options[0]; best.Total != 2200 {
+		t.Fatalf("expected the split without fees to cost 2200, got %+v", best)
+	}
+}
+
+func TestCareBasketQuote_ResolvesFreeTextItems(t *testing.T) {
+	svc, _ := newTestBasketService()
+	svc.SetProcedureSearch(NewProcedureSearchService(&fakeProcedureSearch{
+		procedures: []*entities.ProcedureSearchResult{{ID: "fbc", Name: "Full Blood Count"}},
+	}, nil))
+	req := basketRequest(false)
+	req.Items = []entities.CareBasketItem{{Query: "blood count"}, {ProcedureID: "unknown"}, {ProcedureID: "fbc"}}
+
+	quote, err := svc.Quote(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(quote.Items) != 1 || quote.Items[0].ProcedureID != "fbc" || quote.Items[0].Query != "blood count" {
+		t.Fatalf("expected the query to resolve to one blood count, got %+v", quote.Items)
+	}
+	if len(quote.UnresolvedItems) != 1 || quote.UnresolvedItems[0].ProcedureID != "unknown" {
+		t.Fatalf("expected the unknown procedure unresolved, got %+v", quote.UnresolvedItems)
+	}
+	if quote.Options[0].Visits[0].FacilityID != "lab" || quote.Options[0].Total != 1200 {
+		t.Fatalf("expected the lab first, got %+v", quote.Options[0])
+	}
+}
+
+func TestCareBasketQuote_ValidatesRequest(t *testing.T) {
+	svc, _ := newTestBasketService()
+	for name, req := range map[string]entities.CareBasketRequest{
+		"no items":     {Latitude: 6.5, Longitude: 3.4},
+		"empty item":   {Items: []entities.CareBasketItem{{}}, Latitude: 6.5, Longitude: 3.4},
+		"no location":  {Items: []entities.CareBasketItem{{ProcedureID: "xray"}}},
+		"far distance": {Items: []entities.CareBasketItem{{ProcedureID: "xray"}}, Latitude: 6.5, Longitude: 3.4, MaxDistanceKm: 500},
+	} {
+		_, err := svc.Quote(context.Background(), req)
+		if appErr, ok := err.(*apperrors.AppError); !ok || appErr.Type != apperrors.ErrorTypeValidation {
+			t.Fatalf("%s: expected a validation error, got %v", name, err)
+		}
+	}
+}
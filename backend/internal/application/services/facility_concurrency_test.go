@@ -0,0 +1,259 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/repositories"
+	apperrors "github.com/zatekoja/Patientpricediscoverydesign/backend/pkg/errors"
+)
+
+// versionedFacilityRepo stores facilities with the same compare-and-swap
+// update the Postgres adapter uses.
+type versionedFacilityRepo struct {
+	repositories.FacilityRepository
+	mu         sync.Mutex
+	facilities map[string]entities.Facility
+	// beforeUpdate runs once before the next update, to simulate a concurrent writer
+	beforeUpdate func()
+}
+
+func (r *versionedFacilityRepo) GetByID(ctx context.Context, id string) (*entities.Facility, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	facility, ok := r.facilities[id]
+	if !ok {
+		return nil, apperrors.NewNotFoundError("facility not found")
+	}
+	return &facility, nil
+}
+
+func (r *versionedFacilityRepo) Update(ctx context.Context, facility *entities.Facility) error {
+	if hook := r.beforeUpdate; hook != nil {
+		r.beforeUpdate = nil
+		hook()
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.facilities[facility.ID]
+	if !ok {
+		return apperrors.NewNotFoundError("facility not found")
+	}
+	if stored.Version != facility.Version {
+		return apperrors.NewConflictError("facility was changed by another update")
+	}
+	facility.Version++
+	r.facilities[facility.ID] = *facility
+	return nil
+}
+
+// versionedWardRepo is the ward counterpart of versionedFacilityRepo.
+type versionedWardRepo struct {
+	repositories.FacilityWardRepository
+	mu           sync.Mutex
+	wards        map[string]entities.FacilityWard
+	beforeUpsert func()
+}
+
+func (r *versionedWardRepo) GetByFacilityAndWard(ctx context.Context, facilityID, wardName string) (*entities.FacilityWard, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ward, ok := r.wards[facilityID+"/"+wardName]
+	if !ok {
+		return nil, apperrors.NewNotFoundError("ward not found")
+	}
+	return &ward, nil
+}
+
+func (r *versionedWardRepo) GetByFacilityID(ctx context.Context, facilityID string) ([]*entities.FacilityWard, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var wards []*entities.FacilityWard
+	for _, ward := range r.wards {
+		if ward.FacilityID == facilityID {
+			copied := ward
+			wards = append(wards, &copied)
+		}
+	}
+	return wards, nil
+}
+
+func (r *versionedWardRepo) Upsert(ctx context.Context, ward *entities.FacilityWard) error {
+	if hook := r.beforeUpsert; hook != nil {
+		r.beforeUpsert = nil
+		hook()
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := ward.FacilityID + "/" + ward.WardName
+	stored, ok := r.wards[key]
+	if ok && stored.Version != ward.Version {
+		return apperrors.NewConflictError("ward was changed by another update")
+	}
+	ward.Version = stored.Version + 1
+	r.wards[key] = *ward
+	return nil
+}
+
+// versionedProcedureRepo serves a single facility procedure.
+type versionedProcedureRepo struct {
+	repositories.FacilityProcedureRepository
+	mu sync.Mutex
+	fp entities.FacilityProcedure
+}
+
+func (r *versionedProcedureRepo) GetByFacilityAndProcedure(ctx context.Context, facilityID, procedureID string) (*entities.FacilityProcedure, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	fp := r.fp
+	return &fp, nil
+}
+
+func (r *versionedProcedureRepo) Update(ctx context.Context, fp *entities.FacilityProcedure) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.fp.Version != fp.Version {
+		return apperrors.NewConflictError("facility procedure was changed by another update")
+	}
+	fp.Version++
+	r.fp = *fp
+	return nil
+}
+
+func TestFacilityService_ConcurrentUpdatesAtSameVersion(t *testing.T) {
+	repo := &versionedFacilityRepo{facilities: map[string]entities.Facility{
+		"fac-1": {ID: "fac-1", Name: "General Hospital", Version: 1},
+	}}
+	service := NewFacilityService(repo, nil, nil, nil, nil)
+
+	const writers = 8
+	var wg sync.WaitGroup
+	errs := make([]error, writers)
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			facility, _ := repo.GetByID(context.Background(), "fac-1")
+			facility.Version = 1
+			facility.Name = "General Hospital " + string(rune('A'+i))
+			errs[i] = service.Update(context.Background(), facility)
+		}(i)
+	}
+	wg.Wait()
+
+	succeeded := 0
+	for _, err := range errs {
+		if err == nil {
+			succeeded++
+			continue
+		}
+		requireAppErrorType(t, err, apperrors.ErrorTypeConflict, "losing writer")
+	}
+	if succeeded != 1 {
+		t.Fatalf("expected exactly one update to win, got %d", succeeded)
+	}
+	stored, _ := repo.GetByID(context.Background(), "fac-1")
+	if stored.Version != 2 {
+		t.Fatalf("expected version 2 after one update, got %d", stored.Version)
+	}
+}
+
+func TestFacilityService_UpdateServiceAvailabilityExpectedVersion(t *testing.T) {
+	procedures := &versionedProcedureRepo{fp: entities.FacilityProcedure{ID: "fp-1", FacilityID: "fac-1", ProcedureID: "proc-1", IsAvailable: true, Version: 3}}
+	service := NewFacilityService(&versionedFacilityRepo{}, nil, procedures, nil, nil)
+
+	_, err := service.UpdateServiceAvailability(context.Background(), "fac-1", "proc-1", false, 2)
+	requireAppErrorType(t, err, apperrors.ErrorTypeConflict, "stale expected version")
+	if !procedures.fp.IsAvailable {
+		t.Fatal("stale update must not be applied")
+	}
+
+	fp, err := service.UpdateServiceAvailability(context.Background(), "fac-1", "proc-1", false, 3)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fp.IsAvailable || fp.Version != 4 {
+		t.Fatalf("expected unavailable at version 4, got available=%v version=%d", fp.IsAvailable, fp.Version)
+	}
+}
+
+func TestProviderIngestion_SaveFacilityReappliesOnConflict(t *testing.T) {
+	repo := &versionedFacilityRepo{facilities: map[string]entities.Facility{
+		"fac-1": {ID: "fac-1", Name: "General Hospital", Version: 1},
+	}}
+	service := &ProviderIngestionService{facilityRepo: repo}
+
+	facility, _ := repo.GetByID(context.Background(), "fac-1")
+	apply := func(f *entities.Facility) { f.Tags = []string{"ingested"} }
+	apply(facility)
+
+	// An operator renames the facility between ingestion's read and write
+	repo.beforeUpdate = func() {
+		repo.mu.Lock()
+		defer repo.mu.Unlock()
+		edited := repo.facilities["fac-1"]
+		edited.Name = "General Hospital (Renamed)"
+		edited.Version++
+		repo.facilities["fac-1"] = edited
+	}
+
+	saved, err := service.saveFacility(context.Background(), facility, apply)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if saved.Name != "General Hospital (Renamed)" {
+		t.Fatalf("ingestion overwrote the operator's edit: name %q", saved.Name)
+	}
+	if len(saved.Tags) != 1 || saved.Tags[0] != "ingested" {
+		t.Fatalf("ingestion change was not reapplied: tags %v", saved.Tags)
+	}
+	if saved.Version != 3 {
+		t.Fatalf("expected version 3, got %d", saved.Version)
+	}
+}
+
+func TestProviderIngestion_UpsertWardOnConflict(t *testing.T) {
+	readingTime := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	facility := &entities.Facility{ID: "fac-1"}
+
+	tests := []struct {
+		name           string
+		concurrentTime time.Time
+		wantWait       int
+	}{
+		{name: "older concurrent write is replaced", concurrentTime: readingTime.Add(-time.Minute), wantWait: 15},
+		{name: "newer concurrent write is kept", concurrentTime: readingTime.Add(time.Minute), wantWait: 90},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stored := entities.FacilityWard{ID: "ward-1", FacilityID: "fac-1", WardName: "maternity", LastUpdated: readingTime.Add(-time.Hour), Version: 1}
+			repo := &versionedWardRepo{wards: map[string]entities.FacilityWard{"fac-1/maternity": stored}}
+			repo.beforeUpsert = func() {
+				repo.mu.Lock()
+				defer repo.mu.Unlock()
+				wait := 90
+				concurrent := stored
+				concurrent.AvgWaitMinutes = &wait
+				concurrent.LastUpdated = tt.concurrentTime
+				concurrent.Version = 2
+				repo.wards["fac-1/maternity"] = concurrent
+			}
+			service := &ProviderIngestionService{facilityWardRepo: repo}
+
+			wait := 15
+			reading := &entities.FacilityWard{ID: "ward-1", FacilityID: "fac-1", WardName: "maternity", AvgWaitMinutes: &wait, LastUpdated: readingTime}
+			if err := service.upsertWard(context.Background(), facility, reading, &stored); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			current, _ := repo.GetByFacilityAndWard(context.Background(), "fac-1", "maternity")
+			if current.AvgWaitMinutes == nil || *current.AvgWaitMinutes != tt.wantWait {
+				t.Fatalf("expected %d minute wait, got %v", tt.wantWait, current.AvgWaitMinutes)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/providers"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/repositories"
+)
+
+const (
+	facilityEventRelayBatchSize = 100
+	// facilityEventRetention is how long published events are kept for
+	// troubleshooting before they are pruned
+	facilityEventRetention = 7 * 24 * time.Hour
+)
+
+// FacilityEventRelayService publishes facility events recorded in the outbox to
+// the event bus. Delivery is at least once: an event can be published again if
+// the relay stops between publishing and marking it, so consumers dedupe by
+// event ID.
+type FacilityEventRelayService struct {
+	outbox   repositories.FacilityEventOutboxRepository
+	eventBus providers.EventBus
+}
+
+// NewFacilityEventRelayService creates a new facility event relay
+func NewFacilityEventRelayService(outbox repositories.FacilityEventOutboxRepository, eventBus providers.EventBus) *FacilityEventRelayService {
+	return &FacilityEventRelayService{
+		outbox:   outbox,
+		eventBus: eventBus,
+	}
+}
+
+// RelayPending publishes pending events in the order they were recorded until
+// the outbox is drained or a publish fails, and returns how many were published
+func (s *FacilityEventRelayService) RelayPending(ctx context.Context) (int, error) {
+	total := 0
+	for {
+		published, err := s.outbox.Relay(ctx, facilityEventRelayBatchSize, func(event *entities.FacilityEvent) error {
+			return publishFacilityEvent(ctx, s.eventBus, event)
+		})
+		total += published
+		if err != nil || published < facilityEventRelayBatchSize {
+			return total, err
+		}
+	}
+}
+
+// Start relays pending events every interval and prunes old published events
+// daily until ctx is done
+func (s *FacilityEventRelayService) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	pruneTicker := time.NewTicker(24 * time.Hour)
+	go func() {
+		defer ticker.Stop()
+		defer pruneTicker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := s.RelayPending(ctx); err != nil {
+					log.Printf("Facility event relay failed: %v", err)
+				}
+			case <-pruneTicker.C:
+				deleted, err := s.outbox.DeletePublishedBefore(ctx, time.Now().Add(-facilityEventRetention))
+				if err != nil {
+					log.Printf("Failed to prune facility event outbox: %v", err)
+				} else if deleted > 0 {
+					log.Printf("Pruned %d published facility events", deleted)
+				}
+			}
+		}
+	}()
+}
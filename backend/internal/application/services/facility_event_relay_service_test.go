@@ -0,0 +1,197 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/providers"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/repositories"
+)
+
+// memoryOutbox keeps recorded events in memory with the adapter's relay semantics
+type memoryOutbox struct {
+	events    []*entities.FacilityEvent
+	published int
+	updated   []*entities.Facility
+}
+
+func (o *memoryOutbox) UpdateFacility(ctx context.Context, facility *entities.Facility, events []*entities.FacilityEvent) error {
+	o.updated = append(o.updated, facility)
+	o.events = append(o.events, events...)
+	return nil
+}
+
+func (o *memoryOutbox) CreateFacilityProcedure(ctx context.Context, fp *entities.FacilityProcedure, events []*entities.FacilityEvent) error {
+	o.events = append(o.events, events...)
+	return nil
+}
+
+func (o *memoryOutbox) UpdateFacilityProcedure(ctx context.Context, fp *entities.FacilityProcedure, events []*entities.FacilityEvent) error {
+	o.events = append(o.events, events...)
+	return nil
+}
+
+func (o *memoryOutbox) UpsertWard(ctx context.Context, ward *entities.FacilityWard, events []*entities.FacilityEvent) error {
+	o.events = append(o.events, events...)
+	return nil
+}
+
+func (o *memoryOutbox) Relay(ctx context.Context, limit int, publish func(*entities.FacilityEvent) error) (int, error) {
+	count := 0
+	for o.published < len(o.events) && count < limit {
+		if err := publish(o.events[o.published]); err != nil {
+			return count, err
+		}
+		o.published++
+		count++
+	}
+	return count, nil
+}
+
+func (o *memoryOutbox) DeletePublishedBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	return 0, nil
+}
+
+type publishedEvent struct {
+	channel string
+	eventID string
+}
+
+// recordingEventBus records publishes and fails those for failEventID
+type recordingEventBus struct {
+	providers.EventBus
+	published   []publishedEvent
+	failEventID string
+}
+
+func (b *recordingEventBus) Publish(ctx context.Context, channel string, event *entities.FacilityEvent) error {
+	if event.ID == b.failEventID {
+		return errors.New("redis unavailable")
+	}
+	b.published = append(b.published, publishedEvent{channel: channel, eventID: event.ID})
+	return nil
+}
+
+type invalidatingFacilityRepo struct {
+	repositories.FacilityRepository
+	facility    *entities.Facility
+	updates     int
+	invalidated []string
+}
+
+func (r *invalidatingFacilityRepo) GetByID(ctx context.Context, id string) (*entities.Facility, error) {
+	copied := *r.facility
+	return &copied, nil
+}
+
+func (r *invalidatingFacilityRepo) Update(ctx context.Context, facility *entities.Facility) error {
+	r.updates++
+	return nil
+}
+
+func (r *invalidatingFacilityRepo) InvalidateFacility(ctx context.Context, id string) {
+	r.invalidated = append(r.invalidated, id)
+}
+
+func TestFacilityEventRelay_PublishesInOrderAndResumesAfterFailure(t *testing.T) {
+	outbox := &memoryOutbox{events: []*entities.FacilityEvent{
+		{ID: "evt-1", FacilityID: "fac-1"},
+		{ID: "evt-2", FacilityID: "fac-2"},
+		{ID: "evt-3", FacilityID: "fac-1"},
+	}}
+	bus := &recordingEventBus{failEventID: "evt-2"}
+	relay := NewFacilityEventRelayService(outbox, bus)
+
+	published, err := relay.RelayPending(context.Background())
+	if err == nil {
+		t.Fatal("expected the publish failure to be reported")
+	}
+	if published != 1 || outbox.published != 1 {
+		t.Fatalf("published = %d, want only evt-1 before the failure", published)
+	}
+
+	bus.failEventID = ""
+	published, err = relay.RelayPending(context.Background())
+	if err != nil {
+		t.Fatalf("RelayPending() error = %v", err)
+	}
+	if published != 2 {
+		t.Errorf("published = %d, want 2", published)
+	}
+
+	want := []publishedEvent{
+		{providers.GetFacilityChannel("fac-1"), "evt-1"},
+		{providers.EventChannelFacilityUpdates, "evt-1"},
+		{providers.GetFacilityChannel("fac-2"), "evt-2"},
+		{providers.EventChannelFacilityUpdates, "evt-2"},
+		{providers.GetFacilityChannel("fac-1"), "evt-3"},
+		{providers.EventChannelFacilityUpdates, "evt-3"},
+	}
+	if len(bus.published) != len(want) {
+		t.Fatalf("published %d messages, want %d: %+v", len(bus.published), len(want), bus.published)
+	}
+	for i := range want {
+		if bus.published[i] != want[i] {
+			t.Errorf("message %d = %+v, want %+v", i, bus.published[i], want[i])
+		}
+	}
+}
+
+func TestFacilityService_UpdateRecordsEventInOutbox(t *testing.T) {
+	low, high := "low", "high"
+	repo := &invalidatingFacilityRepo{facility: &entities.Facility{ID: "fac-1", CapacityStatus: &low}}
+	outbox := &memoryOutbox{}
+	bus := &recordingEventBus{}
+
+	service := NewFacilityService(repo, nil, nil, nil, nil)
+	service.SetEventBus(bus)
+	service.SetOutbox(outbox)
+
+	err := service.Update(context.Background(), &entities.Facility{ID: "fac-1", CapacityStatus: &high})
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	if repo.updates != 0 || len(outbox.updated) != 1 {
+		t.Errorf("expected the write to go through the outbox, repo updates = %d", repo.updates)
+	}
+	if len(outbox.events) != 1 || outbox.events[0].EventType != entities.FacilityEventTypeCapacityUpdate {
+		t.Fatalf("expected one capacity event in the outbox, got %+v", outbox.events)
+	}
+	if len(bus.published) != 0 {
+		t.Errorf("events must be left to the relay, got %d direct publishes", len(bus.published))
+	}
+	if len(repo.invalidated) != 1 || repo.invalidated[0] != "fac-1" {
+		t.Errorf("invalidated = %v, want the updated facility", repo.invalidated)
+	}
+
+	// An update without real-time changes records no event
+	err = service.Update(context.Background(), &entities.Facility{ID: "fac-1", CapacityStatus: &low, Name: "Renamed"})
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if len(outbox.events) != 1 {
+		t.Errorf("expected no new event, outbox has %d", len(outbox.events))
+	}
+}
+
+func TestWardCapacityEvent(t *testing.T) {
+	busy, full := "busy", "full"
+	old := &entities.FacilityWard{FacilityID: "fac-1", WardName: "Emergency", CapacityStatus: &busy}
+
+	if event := wardCapacityEvent(entities.Location{}, old, &entities.FacilityWard{FacilityID: "fac-1", WardName: "Emergency", CapacityStatus: &busy}); event != nil {
+		t.Errorf("unchanged ward produced event %+v", event)
+	}
+
+	event := wardCapacityEvent(entities.Location{}, old, &entities.FacilityWard{FacilityID: "fac-1", WardName: "Emergency", CapacityStatus: &full})
+	if event == nil || event.EventType != entities.FacilityEventTypeWardCapacityUpdate || event.ChangedFields["ward_name"] != "Emergency" {
+		t.Errorf("unexpected event for changed ward: %+v", event)
+	}
+
+	if event := wardCapacityEvent(entities.Location{}, nil, old); event == nil {
+		t.Error("new ward should produce an event")
+	}
+}
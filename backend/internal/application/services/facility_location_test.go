@@ -0,0 +1,73 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	apperrors "github.com/zatekoja/Patientpricediscoverydesign/backend/pkg/errors"
+)
+
+// recordingGeocodeRepo keeps the geocodes saved to it
+type recordingGeocodeRepo struct {
+	saved []*entities.GeocodedLocation
+}
+
+func (r *recordingGeocodeRepo) Get(ctx context.Context, addressKey string) (*entities.GeocodedLocation, error) {
+	return nil, apperrors.NewNotFoundError("geocoded address not found")
+}
+
+func (r *recordingGeocodeRepo) Save(ctx context.Context, location *entities.GeocodedLocation) error {
+	r.saved = append(r.saved, location)
+	return nil
+}
+
+func TestFacilityService_PinLocation(t *testing.T) {
+	repo := &versionedFacilityRepo{facilities: map[string]entities.Facility{
+		"fac_1": {
+			ID:             "fac_1",
+			Address:        entities.Address{Street: "Ishaga Road, Idi-Araba", City: "Surulere", State: "Lagos"},
+			Location:       entities.Location{Latitude: 6.4541, Longitude: 3.3947},
+			LocationSource: string(entities.GeocodeSourceGazetteer),
+			Version:        3,
+		},
+	}}
+	geocodes := &recordingGeocodeRepo{}
+	svc := NewFacilityService(repo, nil, nil, nil, nil)
+	svc.SetGeocodeRepository(geocodes)
+	ctx := context.Background()
+	pin := entities.Location{Latitude: 6.5176, Longitude: 3.3566}
+
+	if _, err := svc.PinLocation(ctx, "fac_1", entities.Location{}, 0); !isValidation(err) {
+		t.Fatalf("expected a validation error for a missing location, got %v", err)
+	}
+	if _, err := svc.PinLocation(ctx, "fac_1", entities.Location{Latitude: 95, Longitude: 3}, 0); !isValidation(err) {
+		t.Fatalf("expected a validation error for an out-of-range latitude, got %v", err)
+	}
+	if _, err := svc.PinLocation(ctx, "fac_1", pin, 2); !isConflict(err) {
+		t.Fatalf("expected a conflict for a stale version, got %v", err)
+	}
+
+	facility, err := svc.PinLocation(ctx, "fac_1", pin, 3)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	stored := repo.facilities["fac_1"]
+	if facility.Version != 4 || stored.Location != pin || !stored.LocationPinned || stored.LocationSource != string(entities.GeocodeSourceManual) {
+		t.Fatalf("expected the pinned location to be stored, got %+v", stored)
+	}
+
+	if len(geocodes.saved) != 1 {
+		t.Fatalf("expected one manual geocode, got %d", len(geocodes.saved))
+	}
+	saved := geocodes.saved[0]
+	if saved.AddressKey != "ishaga road idi araba surulere lagos nigeria" || saved.Source != entities.GeocodeSourceManual || saved.Confidence != 1 || saved.Location != pin {
+		t.Fatalf("unexpected manual geocode %+v", saved)
+	}
+}
+
+func isValidation(err error) bool {
+	var appErr *apperrors.AppError
+	return errors.As(err, &appErr) && appErr.Type == apperrors.ErrorTypeValidation
+}
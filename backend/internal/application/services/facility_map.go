@@ -0,0 +1,121 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/repositories"
+	apperrors "github.com/zatekoja/Patientpricediscoverydesign/backend/pkg/errors"
+)
+
+const (
+	// MaxMapZoom is the deepest web map zoom level
+	MaxMapZoom = 22
+	// clusterBelowZoom is the zoom from which facilities are shown on their own
+	clusterBelowZoom = 14
+	// mapCellsPerTile is how many grid cells span a 256px map tile, making
+	// cells about 64px across at any zoom
+	mapCellsPerTile = 4
+	// maxMapFacilities caps the facilities placed on one unclustered map
+	maxMapFacilities = 2000
+)
+
+// MapViewport returns the facilities inside params.Bounds that match the
+// search filters, clustered on a grid below clusterBelowZoom
+func (s *FacilityService) MapViewport(ctx context.Context, params repositories.SearchParams, zoom int) (*entities.FacilityMap, error) {
+	bounds := params.Bounds
+	if bounds == nil {
+		return nil, apperrors.NewValidationError("bbox is required")
+	}
+	if bounds.MinLatitude < -90 || bounds.MaxLatitude > 90 || bounds.MinLongitude < -180 || bounds.MaxLongitude > 180 ||
+		bounds.MinLatitude > bounds.MaxLatitude || bounds.MinLongitude > bounds.MaxLongitude {
+		return nil, apperrors.NewValidationError("bbox must be min_lon,min_lat,max_lon,max_lat within valid coordinates")
+	}
+	if zoom < 0 || zoom > MaxMapZoom {
+		return nil, apperrors.NewValidationError(fmt.Sprintf("zoom must be between 0 and %d", MaxMapZoom))
+	}
+
+	if zoom < clusterBelowZoom {
+		return s.clusteredMapViewport(ctx, params, zoom)
+	}
+
+	points, total, err := s.mapPoints(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	viewport := &entities.FacilityMap{
+		Bounds:     *bounds,
+		Zoom:       zoom,
+		Total:      total,
+		Truncated:  total > len(points),
+		Facilities: []entities.FacilityMapPoint{},
+		Clusters:   []entities.FacilityCluster{},
+	}
+	for _, point := range points {
+		viewport.Facilities = append(viewport.Facilities, *point)
+	}
+	return viewport, nil
+}
+
+// clusteredMapViewport groups every matching facility by grid cell in the
+// database. Cells are square in degrees, which near the equator is close
+// enough to square on a web map. A cell with a single facility shows the
+// facility itself.
+func (s *FacilityService) clusteredMapViewport(ctx context.Context, params repositories.SearchParams, zoom int) (*entities.FacilityMap, error) {
+	database, ok := s.repo.(repositories.FacilityMapCellRepository)
+	if !ok {
+		return nil, fmt.Errorf("facility repository does not support map clustering")
+	}
+	cells, err := database.MapCells(ctx, params, 360/(math.Exp2(float64(zoom))*mapCellsPerTile))
+	if err != nil {
+		return nil, err
+	}
+
+	viewport := &entities.FacilityMap{
+		Bounds:     *params.Bounds,
+		Zoom:       zoom,
+		Clustered:  true,
+		Facilities: []entities.FacilityMapPoint{},
+		Clusters:   []entities.FacilityCluster{},
+	}
+	for _, cell := range cells {
+		viewport.Total += cell.Count
+		if cell.Facility != nil {
+			viewport.Facilities = append(viewport.Facilities, *cell.Facility)
+			continue
+		}
+		viewport.Clusters = append(viewport.Clusters, entities.FacilityCluster{
+			ID:          fmt.Sprintf("%d:%d:%d", zoom, cell.X, cell.Y),
+			Location:    cell.Location,
+			Bounds:      cell.Bounds,
+			Count:       cell.Count,
+			MinPrice:    cell.MinPrice,
+			MaxPrice:    cell.MaxPrice,
+			CapacityMix: cell.CapacityMix,
+		})
+	}
+	sort.SliceStable(viewport.Clusters, func(i, j int) bool { return viewport.Clusters[i].Count > viewport.Clusters[j].Count })
+	return viewport, nil
+}
+
+// mapPoints asks the search index, falling back to the database when it
+// fails. The index only holds each facility's lowest price, so maps for a
+// procedure, which show that procedure's price, go to the database.
+func (s *FacilityService) mapPoints(ctx context.Context, params repositories.SearchParams) ([]*entities.FacilityMapPoint, int, error) {
+	if index, ok := s.searchRepo.(repositories.FacilityMapRepository); ok && params.ProcedureID == "" {
+		points, total, err := index.MapPoints(ctx, params, maxMapFacilities)
+		if err == nil {
+			return points, total, nil
+		}
+		log.Printf("Warning: Typesense map search failed, falling back to database: %v", err)
+	}
+	database, ok := s.repo.(repositories.FacilityMapRepository)
+	if !ok {
+		return nil, 0, fmt.Errorf("facility repository does not support map search")
+	}
+	return database.MapPoints(ctx, params, maxMapFacilities)
+}
@@ -0,0 +1,144 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/repositories"
+)
+
+// stubMapFacilityRepo serves fixed map points and cells
+type stubMapFacilityRepo struct {
+	repositories.FacilityRepository
+	points      []*entities.FacilityMapPoint
+	cells       []*repositories.MapCell
+	cellDegrees float64
+	calls       int
+}
+
+func (r *stubMapFacilityRepo) MapPoints(ctx context.Context, params repositories.SearchParams, limit int) ([]*entities.FacilityMapPoint, int, error) {
+	r.calls++
+	return r.points, len(r.points), nil
+}
+
+func (r *stubMapFacilityRepo) MapCells(ctx context.Context, params repositories.SearchParams, cellDegrees float64) ([]*repositories.MapCell, error) {
+	r.cellDegrees = cellDegrees
+	return r.cells, nil
+}
+
+// failingMapIndex is a search index whose map search always fails
+type failingMapIndex struct {
+	repositories.FacilitySearchRepository
+	calls int
+}
+
+func (r *failingMapIndex) MapPoints(ctx context.Context, params repositories.SearchParams, limit int) ([]*entities.FacilityMapPoint, int, error) {
+	r.calls++
+	return nil, 0, errors.New("typesense unavailable")
+}
+
+func mapPoint(id string, lat, lon float64, status string, price float64) *entities.FacilityMapPoint {
+	point := &entities.FacilityMapPoint{ID: id, Location: entities.Location{Latitude: lat, Longitude: lon}, CapacityStatus: status}
+	if price > 0 {
+		point.Price = &price
+	}
+	return point
+}
+
+func lagosAndAbujaPoints() []*entities.FacilityMapPoint {
+	return []*entities.FacilityMapPoint{
+		mapPoint("lagos_1", 6.45, 3.39, "available", 15000),
+		mapPoint("lagos_2", 6.46, 3.40, "busy", 40000),
+		mapPoint("lagos_3", 6.47, 3.41, "available", 0),
+		mapPoint("abuja_1", 9.06, 7.49, "full", 25000),
+	}
+}
+
+func TestFacilityService_MapViewportClustersEveryMatchAtLowZoom(t *testing.T) {
+	minPrice, maxPrice := 15000.0, 40000.0
+	repo := &stubMapFacilityRepo{
+		points: lagosAndAbujaPoints()[:1],
+		cells: []*repositories.MapCell{
+			{X: 33, Y: 43, Count: 1, Location: entities.Location{Latitude: 9.06, Longitude: 7.49},
+				CapacityMix: map[string]int{"full": 1}, Facility: lagosAndAbujaPoints()[3]},
+			{X: 32, Y: 42, Count: 3, Location: entities.Location{Latitude: 6.46, Longitude: 3.40},
+				Bounds:   entities.GeoBounds{MinLatitude: 6.45, MinLongitude: 3.39, MaxLatitude: 6.47, MaxLongitude: 3.41},
+				MinPrice: &minPrice, MaxPrice: &maxPrice,
+				CapacityMix: map[string]int{"available": 2, "busy": 1}},
+			{X: 40, Y: 50, Count: 5000, Location: entities.Location{Latitude: 12.0, Longitude: 8.5},
+				CapacityMix: map[string]int{"unknown": 5000}},
+		},
+	}
+	svc := NewFacilityService(repo, &failingMapIndex{}, nil, nil, nil)
+	params := repositories.SearchParams{Bounds: &entities.GeoBounds{MinLatitude: 4, MinLongitude: 2, MaxLatitude: 14, MaxLongitude: 15}}
+
+	viewport, err := svc.MapViewport(context.Background(), params, 6)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo.cellDegrees != 360.0/(64*mapCellsPerTile) || repo.calls != 0 {
+		t.Fatalf("expected cells of %v degrees and no point search, got %v and %d calls", 360.0/(64*mapCellsPerTile), repo.cellDegrees, repo.calls)
+	}
+	if !viewport.Clustered || viewport.Total != 5004 || viewport.Truncated {
+		t.Fatalf("expected every match to be counted, got %+v", viewport)
+	}
+	if len(viewport.Facilities) != 1 || viewport.Facilities[0].ID != "abuja_1" {
+		t.Fatalf("expected Abuja on its own, got %+v", viewport.Facilities)
+	}
+	if len(viewport.Clusters) != 2 || viewport.Clusters[0].Count != 5000 || viewport.Clusters[0].ID != "6:40:50" {
+		t.Fatalf("expected clusters largest first, got %+v", viewport.Clusters)
+	}
+	lagos := viewport.Clusters[1]
+	if lagos.Count != 3 || *lagos.MinPrice != 15000 || *lagos.MaxPrice != 40000 || lagos.CapacityMix["available"] != 2 {
+		t.Fatalf("unexpected cluster %+v", lagos)
+	}
+
+	viewport, err = svc.MapViewport(context.Background(), params, clusterBelowZoom)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if viewport.Clustered || len(viewport.Facilities) != 1 || len(viewport.Clusters) != 0 || repo.calls != 1 {
+		t.Fatalf("expected individual facilities at high zoom, got %+v", viewport)
+	}
+}
+
+func TestFacilityService_MapViewportFallsBackToDatabase(t *testing.T) {
+	repo := &stubMapFacilityRepo{points: lagosAndAbujaPoints()}
+	index := &failingMapIndex{}
+	svc := NewFacilityService(repo, index, nil, nil, nil)
+	params := repositories.SearchParams{Bounds: &entities.GeoBounds{MinLatitude: 4, MinLongitude: 2, MaxLatitude: 14, MaxLongitude: 15}}
+
+	if _, err := svc.MapViewport(context.Background(), params, 16); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if index.calls != 1 || repo.calls != 1 {
+		t.Fatalf("expected the index then the database, got %d index and %d database calls", index.calls, repo.calls)
+	}
+
+	params.ProcedureID = "proc_mri"
+	if _, err := svc.MapViewport(context.Background(), params, 16); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if index.calls != 1 || repo.calls != 2 {
+		t.Fatalf("expected procedure filters to go straight to the database, got %d index calls", index.calls)
+	}
+}
+
+func TestFacilityService_MapViewportValidates(t *testing.T) {
+	svc := NewFacilityService(&stubMapFacilityRepo{}, nil, nil, nil, nil)
+	ctx := context.Background()
+
+	if _, err := svc.MapViewport(ctx, repositories.SearchParams{}, 10); !isValidation(err) {
+		t.Fatalf("expected a validation error without bounds, got %v", err)
+	}
+	inverted := repositories.SearchParams{Bounds: &entities.GeoBounds{MinLatitude: 10, MinLongitude: 2, MaxLatitude: 4, MaxLongitude: 15}}
+	if _, err := svc.MapViewport(ctx, inverted, 10); !isValidation(err) {
+		t.Fatalf("expected a validation error for inverted bounds, got %v", err)
+	}
+	valid := repositories.SearchParams{Bounds: &entities.GeoBounds{MinLatitude: 4, MinLongitude: 2, MaxLatitude: 14, MaxLongitude: 15}}
+	if _, err := svc.MapViewport(ctx, valid, MaxMapZoom+1); !isValidation(err) {
+		t.Fatalf("expected a validation error for zoom, got %v", err)
+	}
+}
@@ -12,7 +12,9 @@ import (
 	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
 	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/providers"
 	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/repositories"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/evaluation"
 	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/infrastructure/observability"
+	apperrors "github.com/zatekoja/Patientpricediscoverydesign/backend/pkg/errors"
 )
 
 // FacilityService handles business logic for facilities
@@ -24,16 +26,27 @@ type FacilityService struct {
 	procedureCatalogRepo repositories.ProcedureRepository
 	insuranceRepo        repositories.InsuranceRepository
 	eventBus             providers.EventBus
+	outbox               repositories.FacilityEventOutboxRepository
+	audit                *AuditService
 	termExpander         *TermExpansionService
 	queryUnderstanding   *QueryUnderstandingService
 	searchRanking        *SearchRankingService
+	semanticSearch       *SemanticSearch
 	featureFlags         *FeatureFlags
+	experiments          *SearchExperimentService
 	analytics            *SearchAnalyticsService
 	metrics              *observability.Metrics
+	capacityFreshness    entities.CapacityFreshnessPolicy
+	capacityHistory      *CapacityHistoryService
+	geocodeRepo          repositories.GeocodeRepository
+	routing              providers.RoutingProvider
 }
 
 const maxSearchTags = 12
 
+// defaultMaxExpansionTerms caps the expanded terms sent to the search backend.
+const defaultMaxExpansionTerms = 5
+
 // NewFacilityService creates a new facility service
 func NewFacilityService(
 	repo repositories.FacilityRepository,
@@ -50,6 +63,7 @@ func NewFacilityService(
 		procedureCatalogRepo: procedureCatalogRepo,
 		insuranceRepo:        insuranceRepo,
 		eventBus:             nil, // Injected separately to avoid breaking existing code
+		capacityFreshness:    entities.DefaultCapacityFreshnessPolicy(),
 	}
 }
 
@@ -58,11 +72,40 @@ func (s *FacilityService) SetFacilityWardRepository(repo repositories.FacilityWa
 	s.facilityWardRepo = repo
 }
 
+// SetCapacityFreshnessPolicy sets how long reported capacity values stay valid
+func (s *FacilityService) SetCapacityFreshnessPolicy(policy entities.CapacityFreshnessPolicy) {
+	s.capacityFreshness = policy
+}
+
+// SetCapacityHistory records capacity reports written through the service
+// and adds expected waits to search results whose live wait is stale
+func (s *FacilityService) SetCapacityHistory(history *CapacityHistoryService) {
+	s.capacityHistory = history
+}
+
+// SetGeocodeRepository stores pinned locations as manual geocodes of the
+// facility's address, so the address resolves to them from then on
+func (s *FacilityService) SetGeocodeRepository(repo repositories.GeocodeRepository) {
+	s.geocodeRepo = repo
+}
+
 // SetEventBus sets the event bus for publishing real-time updates
 func (s *FacilityService) SetEventBus(eventBus providers.EventBus) {
 	s.eventBus = eventBus
 }
 
+// SetOutbox records facility and service availability events in the same
+// transaction as the change instead of publishing them directly; the
+// FacilityEventRelayService then delivers them to the event bus.
+func (s *FacilityService) SetOutbox(outbox repositories.FacilityEventOutboxRepository) {
+	s.outbox = outbox
+}
+
+// SetAuditLog records facility and service availability changes in the audit log
+func (s *FacilityService) SetAuditLog(audit *AuditService) {
+	s.audit = audit
+}
+
 // SetTermExpander sets the term expansion service
 func (s *FacilityService) SetTermExpander(expander *TermExpansionService) {
 	s.termExpander = expander
@@ -78,11 +121,22 @@ func (s *FacilityService) SetSearchRanking(svc *SearchRankingService) {
 	s.searchRanking = svc
 }
 
+// SetSemanticSearch enables hybrid search, fusing keyword matches with
+// facilities whose concept embeddings are close to the query
+func (s *FacilityService) SetSemanticSearch(semantic *SemanticSearch) {
+	s.semanticSearch = semantic
+}
+
 // SetFeatureFlags sets the feature flags service
 func (s *FacilityService) SetFeatureFlags(ff *FeatureFlags) {
 	s.featureFlags = ff
 }
 
+// SetExperiments sets the search experiment service used to bucket sessions into variants
+func (s *FacilityService) SetExperiments(svc *SearchExperimentService) {
+	s.experiments = svc
+}
+
 // SetAnalytics sets the search analytics service
 func (s *FacilityService) SetAnalytics(svc *SearchAnalyticsService) {
 	s.analytics = svc
@@ -110,6 +164,7 @@ func (s *FacilityService) Create(ctx context.Context, facility *entities.Facilit
 	if err := s.repo.Create(ctx, facility); err != nil {
 		return err
 	}
+	s.capacityHistory.RecordFacility(ctx, nil, facility)
 
 	// 2. Index in search engine
 	if s.searchRepo != nil {
@@ -136,10 +191,102 @@ func (s *FacilityService) Update(ctx context.Context, facility *entities.Facilit
 		return err
 	}
 
-	// 1. Update in database
-	if err := s.repo.Update(ctx, facility); err != nil {
+	return s.update(ctx, "facility.update", existing, facility, facilityUpdateEvent(existing, facility))
+}
+
+// PinLocation sets a facility's coordinates by hand and marks them pinned, so
+// ingestion never replaces them. A non-zero expectedVersion must match the
+// stored facility's version.
+func (s *FacilityService) PinLocation(ctx context.Context, facilityID string, location entities.Location, expectedVersion int) (*entities.Facility, error) {
+	if location.Latitude < -90 || location.Latitude > 90 || location.Longitude < -180 || location.Longitude > 180 {
+		return nil, apperrors.NewValidationError("latitude must be between -90 and 90 and longitude between -180 and 180")
+	}
+	if location.Latitude == 0 && location.Longitude == 0 {
+		return nil, apperrors.NewValidationError("location is required")
+	}
+
+	existing, err := s.repo.GetByID(ctx, facilityID)
+	if err != nil {
+		return nil, err
+	}
+	if expectedVersion > 0 && existing.Version != expectedVersion {
+		return nil, apperrors.NewConflictError(fmt.Sprintf("facility %s is at version %d, not %d", facilityID, existing.Version, expectedVersion))
+	}
+
+	facility := *existing
+	facility.Location = location
+	facility.LocationSource = string(entities.GeocodeSourceManual)
+	facility.LocationPinned = true
+	if err := s.update(ctx, "facility.location.pin", existing, &facility, nil); err != nil {
+		return nil, err
+	}
+	s.storeManualGeocode(ctx, &facility)
+	return &facility, nil
+}
+
+// storeManualGeocode records a pinned location against the facility's street
+// address; a facility without one has nothing specific to key it by
+func (s *FacilityService) storeManualGeocode(ctx context.Context, facility *entities.Facility) {
+	if s.geocodeRepo == nil || strings.TrimSpace(facility.Address.Street) == "" {
+		return
+	}
+	address := facility.Address.GeocodeQuery()
+	err := s.geocodeRepo.Save(ctx, &entities.GeocodedLocation{
+		AddressKey: entities.NormalizeGeocodeAddress(address),
+		Address:    address,
+		Street:     facility.Address.Street,
+		City:       facility.Address.City,
+		State:      facility.Address.State,
+		ZipCode:    facility.Address.ZipCode,
+		Country:    facility.Address.Country,
+		Location:   facility.Location,
+		Source:     entities.GeocodeSourceManual,
+		Confidence: 1,
+	})
+	if err != nil {
+		log.Printf("Warning: Failed to store pinned location of facility %s: %v", facility.ID, err)
+	}
+}
+
+// update writes a facility read as existing, along with its real-time event
+// (nil when there is none) and its audit entry under action, then reindexes
+func (s *FacilityService) update(ctx context.Context, action string, existing, facility *entities.Facility, event *entities.FacilityEvent) error {
+	// 1. Update in database, recording the event with the change when the
+	// outbox is configured
+	err := s.audit.Transact(ctx, func(ctx context.Context) (*AuditEvent, error) {
+		var err error
+		if s.outbox != nil {
+			var events []*entities.FacilityEvent
+			if event != nil {
+				events = append(events, event)
+			}
+			err = s.outbox.UpdateFacility(ctx, facility, events)
+		} else {
+			err = s.repo.Update(ctx, facility)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &AuditEvent{
+			Action:     action,
+			EntityType: "facility",
+			EntityID:   facility.ID,
+			FacilityID: facility.ID,
+			Before:     existing,
+			After:      facility,
+		}, nil
+	})
+	if err != nil {
+		if isConflict(err) {
+			// The version may have come from a stale cached copy
+			s.invalidateFacility(ctx, facility.ID)
+		}
 		return err
 	}
+	if s.outbox != nil {
+		s.invalidateFacility(ctx, facility.ID)
+	}
+	s.capacityHistory.RecordFacility(ctx, existing, facility)
 
 	// 2. Update index
 	if s.searchRepo != nil {
@@ -149,16 +296,68 @@ func (s *FacilityService) Update(ctx context.Context, facility *entities.Facilit
 		}
 	}
 
-	// 3. Publish real-time update events if relevant fields changed
-	if s.eventBus != nil {
-		s.publishUpdateEvents(ctx, existing, facility)
+	// 3. Without the outbox, publish real-time update events directly
+	if s.outbox == nil && s.eventBus != nil && event != nil {
+		s.publishEvent(ctx, event)
+	}
+
+	return nil
+}
+
+// upsertWard writes a ward read as existing, keeping its LastUpdated, along
+// with its real-time event (nil when there is none) and its audit entry under
+// action
+func (s *FacilityService) upsertWard(ctx context.Context, action string, existing, ward *entities.FacilityWard, event *entities.FacilityEvent) error {
+	if s.outbox == nil && s.facilityWardRepo == nil {
+		return fmt.Errorf("facility ward repository not configured")
+	}
+	err := s.audit.Transact(ctx, func(ctx context.Context) (*AuditEvent, error) {
+		var err error
+		if s.outbox != nil {
+			var events []*entities.FacilityEvent
+			if event != nil {
+				events = append(events, event)
+			}
+			err = s.outbox.UpsertWard(ctx, ward, events)
+		} else {
+			err = s.facilityWardRepo.Upsert(ctx, ward)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &AuditEvent{
+			Action:     action,
+			EntityType: "facility_ward",
+			EntityID:   ward.ID,
+			FacilityID: ward.FacilityID,
+			Before:     existing,
+			After:      ward,
+		}, nil
+	})
+	if err != nil {
+		return err
 	}
+	s.capacityHistory.RecordWard(ctx, existing, ward)
 
+	if s.outbox == nil && s.eventBus != nil && event != nil {
+		s.publishEvent(ctx, event)
+	}
 	return nil
 }
 
+// Reindex refreshes a facility's search document after a change made outside
+// Update, such as a rating recomputed from reviews.
+func (s *FacilityService) Reindex(ctx context.Context, facility *entities.Facility) error {
+	if s.searchRepo == nil {
+		return nil
+	}
+	s.enrichFacilityForSearch(ctx, facility)
+	return s.searchRepo.Index(ctx, facility)
+}
+
 // UpdateServiceAvailability updates availability for a specific facility procedure and publishes an event.
-func (s *FacilityService) UpdateServiceAvailability(ctx context.Context, facilityID, procedureID string, isAvailable bool) (*entities.FacilityProcedure, error) {
+// A non-zero expectedVersion must match the stored facility procedure's version.
+func (s *FacilityService) UpdateServiceAvailability(ctx context.Context, facilityID, procedureID string, isAvailable bool, expectedVersion int) (*entities.FacilityProcedure, error) {
 	if s.procedureRepo == nil {
 		return nil, fmt.Errorf("facility procedure repository not configured")
 	}
@@ -168,70 +367,94 @@ func (s *FacilityService) UpdateServiceAvailability(ctx context.Context, facilit
 		return nil, err
 	}
 
+	if expectedVersion > 0 && fp.Version != expectedVersion {
+		return nil, apperrors.NewConflictError(fmt.Sprintf("facility procedure %s is at version %d, not %d", fp.ID, fp.Version, expectedVersion))
+	}
+
 	if fp.IsAvailable == isAvailable {
 		return fp, nil
 	}
 
+	before := *fp
 	fp.IsAvailable = isAvailable
-	if err := s.procedureRepo.Update(ctx, fp); err != nil {
-		return nil, err
-	}
 
-	if s.eventBus != nil {
-		location := entities.Location{}
-		if facility, err := s.repo.GetByID(ctx, facilityID); err == nil && facility != nil {
-			location = facility.Location
+	err = s.audit.Transact(ctx, func(ctx context.Context) (*AuditEvent, error) {
+		var err error
+		if s.outbox != nil {
+			event := s.serviceAvailabilityEvent(ctx, facilityID, procedureID, fp)
+			err = s.outbox.UpdateFacilityProcedure(ctx, fp, []*entities.FacilityEvent{event})
+		} else {
+			err = s.procedureRepo.Update(ctx, fp)
 		}
-
-		changedFields := map[string]interface{}{
-			"procedure_id":       procedureID,
-			"is_available":       isAvailable,
-			"price":              fp.Price,
-			"currency":           fp.Currency,
-			"estimated_duration": fp.EstimatedDuration,
+		if err != nil {
+			return nil, err
 		}
+		return &AuditEvent{
+			Action:     "facility_procedure.update_availability",
+			EntityType: "facility_procedure",
+			EntityID:   fp.ID,
+			FacilityID: facilityID,
+			Before:     &before,
+			After:      fp,
+		}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
 
-		if s.procedureCatalogRepo != nil {
-			if procedure, err := s.procedureCatalogRepo.GetByID(ctx, procedureID); err == nil && procedure != nil {
-				if procedure.Name != "" {
-					changedFields["procedure_name"] = procedure.Name
-				}
-				if procedure.Code != "" {
-					changedFields["procedure_code"] = procedure.Code
-				}
-				if procedure.Category != "" {
-					changedFields["procedure_category"] = procedure.Category
-				}
-				if procedure.Description != "" {
-					changedFields["procedure_description"] = procedure.Description
-				}
-			}
-		}
+	if s.outbox != nil {
+		s.invalidateFacility(ctx, facilityID)
+	} else if s.eventBus != nil {
+		s.publishEvent(ctx, s.serviceAvailabilityEvent(ctx, facilityID, procedureID, fp))
+	}
 
-		event := entities.NewFacilityEvent(
-			facilityID,
-			entities.FacilityEventTypeServiceAvailabilityUpdate,
-			location,
-			changedFields,
-		)
+	return fp, nil
+}
 
-		facilityChannel := providers.GetFacilityChannel(facilityID)
-		if err := s.eventBus.Publish(ctx, facilityChannel, event); err != nil {
-			log.Printf("Warning: Failed to publish service availability event to %s: %v", facilityChannel, err)
-		}
+// serviceAvailabilityEvent describes an availability change with the facility
+// location and procedure details subscribers display
+func (s *FacilityService) serviceAvailabilityEvent(ctx context.Context, facilityID, procedureID string, fp *entities.FacilityProcedure) *entities.FacilityEvent {
+	location := entities.Location{}
+	if facility, err := s.repo.GetByID(ctx, facilityID); err == nil && facility != nil {
+		location = facility.Location
+	}
 
-		if err := s.eventBus.Publish(ctx, providers.EventChannelFacilityUpdates, event); err != nil {
-			log.Printf("Warning: Failed to publish service availability event to global channel: %v", err)
-		}
+	changedFields := map[string]interface{}{
+		"procedure_id":       procedureID,
+		"is_available":       fp.IsAvailable,
+		"price":              fp.Price,
+		"currency":           fp.Currency,
+		"estimated_duration": fp.EstimatedDuration,
+	}
 
-		log.Printf("Published %s event for facility %s", entities.FacilityEventTypeServiceAvailabilityUpdate, facilityID)
+	if s.procedureCatalogRepo != nil {
+		if procedure, err := s.procedureCatalogRepo.GetByID(ctx, procedureID); err == nil && procedure != nil {
+			if procedure.Name != "" {
+				changedFields["procedure_name"] = procedure.Name
+			}
+			if procedure.Code != "" {
+				changedFields["procedure_code"] = procedure.Code
+			}
+			if procedure.Category != "" {
+				changedFields["procedure_category"] = procedure.Category
+			}
+			if procedure.Description != "" {
+				changedFields["procedure_description"] = procedure.Description
+			}
+		}
 	}
 
-	return fp, nil
+	return entities.NewFacilityEvent(
+		facilityID,
+		entities.FacilityEventTypeServiceAvailabilityUpdate,
+		location,
+		changedFields,
+	)
 }
 
-// publishUpdateEvents publishes events for facility updates
-func (s *FacilityService) publishUpdateEvents(ctx context.Context, old, new *entities.Facility) {
+// facilityUpdateEvent describes the real-time relevant changes between two
+// versions of a facility, or returns nil when there are none
+func facilityUpdateEvent(old, new *entities.Facility) *entities.FacilityEvent {
 	changedFields := make(map[string]interface{})
 	var eventType entities.FacilityEventType
 
@@ -255,29 +478,72 @@ func (s *FacilityService) publishUpdateEvents(ctx context.Context, old, new *ent
 
 	// If no relevant changes, don't publish
 	if len(changedFields) == 0 {
-		return
+		return nil
 	}
 
-	// Create and publish event
-	event := entities.NewFacilityEvent(
+	return entities.NewFacilityEvent(
 		new.ID,
 		eventType,
 		new.Location,
 		changedFields,
 	)
+}
 
-	// Publish to facility-specific channel
-	facilityChannel := providers.GetFacilityChannel(new.ID)
-	if err := s.eventBus.Publish(ctx, facilityChannel, event); err != nil {
-		log.Printf("Warning: Failed to publish event to %s: %v", facilityChannel, err)
+// wardCapacityEvent describes a new ward or a change to a ward's capacity, or
+// returns nil when nothing changed
+func wardCapacityEvent(location entities.Location, old, new *entities.FacilityWard) *entities.FacilityEvent {
+	if old != nil &&
+		strPtrEqual(old.WardType, new.WardType) &&
+		strPtrEqual(old.CapacityStatus, new.CapacityStatus) &&
+		intPtrEqual(old.AvgWaitMinutes, new.AvgWaitMinutes) &&
+		boolPtrEqual(old.UrgentCareAvailable, new.UrgentCareAvailable) {
+		return nil
 	}
 
-	// Publish to global updates channel for regional subscribers
-	if err := s.eventBus.Publish(ctx, providers.EventChannelFacilityUpdates, event); err != nil {
-		log.Printf("Warning: Failed to publish event to global channel: %v", err)
+	return entities.NewFacilityEvent(
+		new.FacilityID,
+		entities.FacilityEventTypeWardCapacityUpdate,
+		location,
+		map[string]interface{}{
+			"ward_name":             new.WardName,
+			"ward_type":             new.WardType,
+			"capacity_status":       new.CapacityStatus,
+			"avg_wait_minutes":      new.AvgWaitMinutes,
+			"urgent_care_available": new.UrgentCareAvailable,
+		},
+	)
+}
+
+// publishEvent publishes an event to the facility's channel and the global
+// updates channel for regional subscribers
+func (s *FacilityService) publishEvent(ctx context.Context, event *entities.FacilityEvent) {
+	if err := publishFacilityEvent(ctx, s.eventBus, event); err != nil {
+		log.Printf("Warning: %v", err)
+		return
+	}
+	log.Printf("Published %s event for facility %s", event.EventType, event.FacilityID)
+}
+
+// publishFacilityEvent publishes to the facility-specific and global channels
+func publishFacilityEvent(ctx context.Context, eventBus providers.EventBus, event *entities.FacilityEvent) error {
+	facilityChannel := providers.GetFacilityChannel(event.FacilityID)
+	if err := eventBus.Publish(ctx, facilityChannel, event); err != nil {
+		return fmt.Errorf("failed to publish event to %s: %w", facilityChannel, err)
+	}
+	if err := eventBus.Publish(ctx, providers.EventChannelFacilityUpdates, event); err != nil {
+		return fmt.Errorf("failed to publish event to global channel: %w", err)
 	}
+	return nil
+}
 
-	log.Printf("Published %s event for facility %s", eventType, new.ID)
+// invalidateFacility drops cached copies of a facility written through the
+// outbox, which bypasses the caching repository's own invalidation
+func (s *FacilityService) invalidateFacility(ctx context.Context, id string) {
+	if invalidator, ok := s.repo.(interface {
+		InvalidateFacility(ctx context.Context, id string)
+	}); ok {
+		invalidator.InvalidateFacility(ctx, id)
+	}
 }
 
 // Helper functions to compare pointer values
@@ -345,20 +611,48 @@ func (s *FacilityService) Search(ctx context.Context, params repositories.Search
 	return s.repo.Search(ctx, params)
 }
 
-func (s *FacilityService) searchWithCount(ctx context.Context, params repositories.SearchParams) ([]*entities.Facility, int, *QueryInterpretation, error) {
+// searchWithCount runs a search with query understanding, semantic search and
+// ranking, returning the routes to the facilities found
+func (s *FacilityService) searchWithCount(ctx context.Context, params repositories.SearchParams) ([]*entities.Facility, int, *QueryInterpretation, map[string]providers.Route, error) {
 	start := time.Now()
+	originalQuery := params.Query
 	var interpretation *QueryInterpretation
 	useContextual := s.featureFlags == nil || s.featureFlags.ContextualSearchEnabled()
+	useSemantic := s.featureFlags == nil || s.featureFlags.SemanticSearchEnabled()
+	useTravelTime := s.featureFlags == nil || s.featureFlags.TravelTimeRankingEnabled()
+
+	// Experiment variants may override contextual search, expansion and ranking.
+	var assignment *ExperimentAssignment
+	if s.experiments != nil {
+		assignment = s.experiments.Assign(params.SessionID)
+	}
+	guardrails := evaluation.GuardrailConfig{MaxExpansionTerms: defaultMaxExpansionTerms}
+	ranking := s.searchRanking
+	if assignment != nil {
+		if assignment.Config.ContextualSearch != nil {
+			useContextual = *assignment.Config.ContextualSearch
+		}
+		if assignment.Config.SemanticSearch != nil {
+			useSemantic = *assignment.Config.SemanticSearch
+		}
+		if assignment.Config.MaxExpansionTerms != nil {
+			guardrails.MaxExpansionTerms = *assignment.Config.MaxExpansionTerms
+		}
+		if assignment.Config.RankingWeights != nil && ranking != nil {
+			ranking = ranking.WithWeights(*assignment.Config.RankingWeights)
+		}
+	}
 
 	if useContextual && s.queryUnderstanding != nil && params.Query != "" {
-		interpretation = s.queryUnderstanding.Interpret(params.Query)
+		interpretation = s.queryUnderstanding.InterpretSearch(ctx, params.Query)
+		if len(interpretation.Constraints) > 0 {
+			// Constraint words become filters; the rest is matched as keywords
+			applyQueryConstraints(&params, interpretation.Constraints)
+			params.Query = interpretation.KeywordQuery
+		}
 		if len(params.ExpandedTerms) == 0 {
 			// Limit expansion terms to avoid overly restrictive AND behavior in Typesense
-			expanded := interpretation.SearchTerms
-			if len(expanded) > 5 {
-				expanded = expanded[:5]
-			}
-			params.ExpandedTerms = expanded
+			params.ExpandedTerms = evaluation.NewGuardrails(guardrails).LimitExpansion(interpretation.SearchTerms)
 		}
 		if params.DetectedIntent == "" {
 			params.DetectedIntent = string(interpretation.DetectedIntent)
@@ -372,48 +666,28 @@ func (s *FacilityService) searchWithCount(ctx context.Context, params repositori
 		}
 	}
 
-	var facilities []*entities.Facility
-	var totalCount int
-	var err error
-	var usedSearchRepo bool
-
-	if s.searchRepo != nil {
-		usedSearchRepo = true
-		if adapterWithCount, ok := s.searchRepo.(interface {
-			SearchWithCount(ctx context.Context, params repositories.SearchParams) ([]*entities.Facility, int, error)
-		}); ok {
-			facilities, totalCount, err = adapterWithCount.SearchWithCount(ctx, params)
-		} else {
-			facilities, err = s.searchRepo.Search(ctx, params)
-			if err == nil {
-				totalCount = len(facilities)
-			}
-		}
-	}
-
-	if !usedSearchRepo || err != nil {
-		if usedSearchRepo {
-			log.Printf("Warning: Typesense search failed, falling back to database: %v", err)
-		}
-
-		if adapterWithCount, ok := s.repo.(interface {
-			SearchWithCount(ctx context.Context, params repositories.SearchParams) ([]*entities.Facility, int, error)
-		}); ok {
-			facilities, totalCount, err = adapterWithCount.SearchWithCount(ctx, params)
-		} else {
-			facilities, err = s.repo.Search(ctx, params)
-			if err == nil {
-				totalCount = len(facilities)
-			}
+	// The raw query is embedded: paraphrases are what keyword expansion misses.
+	// An explicit sort would rank loose vector matches alongside keyword ones.
+	if useSemantic && s.semanticSearch != nil && params.Query != "" && params.SortBy == "" && len(params.QueryEmbedding) == 0 {
+		if embedding := s.semanticSearch.EmbedQuery(ctx, params.Query); embedding != nil {
+			params.QueryEmbedding = embedding
+			params.EmbeddingModel = s.semanticSearch.EmbeddingModel()
+			params.VectorWeight = s.semanticSearch.VectorWeight()
+			params.MinSimilarity = s.semanticSearch.MinSimilarity()
 		}
 	}
 
+	facilities, totalCount, routes, err := s.SearchWithRoutes(ctx, params)
 	if err != nil {
-		return nil, 0, interpretation, err
+		return nil, 0, interpretation, nil, err
 	}
 
-	if useContextual && s.searchRanking != nil && len(facilities) > 0 {
-		ranked := s.searchRanking.Rank(facilities, interpretation, params.Latitude, params.Longitude)
+	if useContextual && ranking != nil && params.SortBy == "" && len(facilities) > 0 {
+		var minutes map[string]float64
+		if useTravelTime {
+			minutes = travelMinutes(routes)
+		}
+		ranked := ranking.RankWithTravelTimes(facilities, interpretation, params.Latitude, params.Longitude, minutes)
 		facilities = make([]*entities.Facility, len(ranked))
 		for i, r := range ranked {
 			facilities[i] = r.Facility
@@ -421,17 +695,31 @@ func (s *FacilityService) searchWithCount(ctx context.Context, params repositori
 	}
 
 	// Track search event for analytics
-	if params.Query != "" {
+	if originalQuery != "" {
 		if s.analytics != nil {
 			event := &entities.SearchEvent{
-				Query:         params.Query,
+				ID:            params.ImpressionID,
+				Query:         originalQuery,
 				ResultCount:   totalCount,
 				LatencyMs:     int(time.Since(start).Milliseconds()),
 				UserLatitude:  params.Latitude,
 				UserLongitude: params.Longitude,
+				SessionID:     params.SessionID,
+				ResultOffset:  params.Offset,
+			}
+			event.ResultFacilityIDs = make([]string, 0, len(facilities))
+			for _, f := range facilities {
+				if f != nil {
+					event.ResultFacilityIDs = append(event.ResultFacilityIDs, f.ID)
+				}
+			}
+			if assignment != nil {
+				event.ExperimentID = assignment.ExperimentID
+				event.ExperimentVariant = assignment.Variant
 			}
 			if interpretation != nil {
 				event.NormalizedQuery = interpretation.NormalizedQuery
+				event.DetectedLanguage = string(interpretation.DetectedLanguage)
 				event.DetectedIntent = string(interpretation.DetectedIntent)
 				event.IntentConfidence = interpretation.IntentConfidence
 			}
@@ -448,7 +736,48 @@ func (s *FacilityService) searchWithCount(ctx context.Context, params repositori
 		}
 	}
 
-	return facilities, totalCount, interpretation, nil
+	return facilities, totalCount, interpretation, routes, nil
+}
+
+// searchBackends searches the index, falling back to the database when it
+// fails or there is none
+func (s *FacilityService) searchBackends(ctx context.Context, params repositories.SearchParams) ([]*entities.Facility, int, error) {
+	var facilities []*entities.Facility
+	var totalCount int
+	var err error
+	var usedSearchRepo bool
+
+	if s.searchRepo != nil {
+		usedSearchRepo = true
+		if adapterWithCount, ok := s.searchRepo.(interface {
+			SearchWithCount(ctx context.Context, params repositories.SearchParams) ([]*entities.Facility, int, error)
+		}); ok {
+			facilities, totalCount, err = adapterWithCount.SearchWithCount(ctx, params)
+		} else {
+			facilities, err = s.searchRepo.Search(ctx, params)
+			if err == nil {
+				totalCount = len(facilities)
+			}
+		}
+	}
+
+	if !usedSearchRepo || err != nil {
+		if usedSearchRepo {
+			log.Printf("Warning: Typesense search failed, falling back to database: %v", err)
+		}
+
+		if adapterWithCount, ok := s.repo.(interface {
+			SearchWithCount(ctx context.Context, params repositories.SearchParams) ([]*entities.Facility, int, error)
+		}); ok {
+			facilities, totalCount, err = adapterWithCount.SearchWithCount(ctx, params)
+		} else {
+			facilities, err = s.repo.Search(ctx, params)
+			if err == nil {
+				totalCount = len(facilities)
+			}
+		}
+	}
+	return facilities, totalCount, err
 }
 
 // SearchResults returns enriched facility search results for the UI.
@@ -459,30 +788,38 @@ func (s *FacilityService) SearchResults(ctx context.Context, params repositories
 
 // SearchResultsWithCount returns enriched facility search results and total count for pagination.
 func (s *FacilityService) SearchResultsWithCount(ctx context.Context, params repositories.SearchParams) ([]entities.FacilitySearchResult, int, *QueryInterpretation, error) {
-	facilities, totalCount, interpretation, err := s.searchWithCount(ctx, params)
+	facilities, totalCount, interpretation, routes, err := s.searchWithCount(ctx, params)
 	if err != nil {
 		return nil, 0, nil, err
 	}
 
+	facilityIDs := make([]string, 0, len(facilities))
+	for _, facility := range facilities {
+		if facility != nil {
+			facilityIDs = append(facilityIDs, facility.ID)
+		}
+	}
+
 	// Batch load wards for all facilities to avoid N+1 queries
 	var wardsByFacility map[string][]*entities.FacilityWard
-	if s.facilityWardRepo != nil && len(facilities) > 0 {
-		facilityIDs := make([]string, 0, len(facilities))
-		for _, facility := range facilities {
-			if facility != nil {
-				facilityIDs = append(facilityIDs, facility.ID)
-			}
-		}
-		if len(facilityIDs) > 0 {
-			wardsByFacility, err = s.facilityWardRepo.GetByFacilityIDs(ctx, facilityIDs)
-			if err != nil {
-				// Log error but don't fail the request
-				log.Printf("Error loading wards for facilities %v: %v", facilityIDs, err)
-				wardsByFacility = make(map[string][]*entities.FacilityWard)
-			}
+	if s.facilityWardRepo != nil && len(facilityIDs) > 0 {
+		wardsByFacility, err = s.facilityWardRepo.GetByFacilityIDs(ctx, facilityIDs)
+		if err != nil {
+			// Log error but don't fail the request
+			log.Printf("Error loading wards for facilities %v: %v", facilityIDs, err)
+			wardsByFacility = make(map[string][]*entities.FacilityWard)
 		}
 	}
 
+	// Services are matched against the query without its constraint words
+	serviceQuery := params.Query
+	if interpretation != nil && len(interpretation.Constraints) > 0 {
+		serviceQuery = interpretation.KeywordQuery
+	}
+
+	now := time.Now()
+	// Typical waits stand in for live waits that are missing or stale
+	expectedWaits := s.capacityHistory.expectedWaits(ctx, facilityIDs, now)
 	results := make([]entities.FacilitySearchResult, 0, len(facilities))
 	for _, facility := range facilities {
 		if facility == nil {
@@ -519,6 +856,7 @@ func (s *FacilityService) SearchResultsWithCount(ctx context.Context, params rep
 			AcceptedInsurance: []string{},
 			UpdatedAt:         facility.UpdatedAt,
 		}
+		setTravelTime(&result, routes)
 
 		if facility.CapacityStatus != nil {
 			result.CapacityStatus = *facility.CapacityStatus
@@ -532,6 +870,12 @@ func (s *FacilityService) SearchResultsWithCount(ctx context.Context, params rep
 		if facility.UrgentCareAvailable != nil {
 			result.UrgentCareAvailable = facility.UrgentCareAvailable
 		}
+		result.CapacityFreshness = s.capacityFreshnessOf(facility.CapacityValues(), facility.CapacityStatus, facility.CapacityStatusReportedAt, now)
+		if !hasLiveWait(result.CapacityFreshness) {
+			if minutes, ok := expectedWaits[facility.ID][""]; ok {
+				result.ExpectedWaitMinutes = &minutes
+			}
+		}
 
 		// Load ward capacity from the batched results
 		if wardsByFacility != nil {
@@ -554,6 +898,13 @@ func (s *FacilityService) SearchResultsWithCount(ctx context.Context, params rep
 					if ward.UrgentCareAvailable != nil {
 						wardResult.UrgentCareAvailable = ward.UrgentCareAvailable
 					}
+					lastUpdated := ward.LastUpdated
+					wardResult.CapacityFreshness = s.capacityFreshnessOf(ward.CapacityValues(), ward.CapacityStatus, &lastUpdated, now)
+					if !hasLiveWait(wardResult.CapacityFreshness) {
+						if minutes, ok := expectedWaits[facility.ID][ward.WardName]; ok {
+							wardResult.ExpectedWaitMinutes = &minutes
+						}
+					}
 					result.Wards = append(result.Wards, wardResult)
 				}
 			}
@@ -567,7 +918,7 @@ func (s *FacilityService) SearchResultsWithCount(ctx context.Context, params rep
 				}
 
 				if s.procedureCatalogRepo != nil {
-					result.ServicePrices, result.MatchedServices = servicePricesFromProcedures(ctx, facilityProcedures, s.procedureCatalogRepo, 8, params.Query, interpretation)
+					result.ServicePrices, result.MatchedServices = servicePricesFromProcedures(ctx, facilityProcedures, s.procedureCatalogRepo, 8, serviceQuery, interpretation)
 					result.Services = serviceNamesFromPrices(result.ServicePrices)
 				}
 			}
@@ -1014,6 +1365,30 @@ func serviceNamesFromPrices(items []entities.ServicePrice) []string {
 	return names
 }
 
+// capacityFreshnessOf rates each reported capacity value. A status that has
+// already decayed to unknown is reported as stale.
+func (s *FacilityService) capacityFreshnessOf(values map[entities.CapacityField]*time.Time, status *string, statusReportedAt *time.Time, now time.Time) map[entities.CapacityField]entities.DataFreshness {
+	freshness := make(map[entities.CapacityField]entities.DataFreshness, len(values)+1)
+	for field, reportedAt := range values {
+		freshness[field] = s.capacityFreshness.Freshness(field, reportedAt, now)
+	}
+	if status != nil && *status == entities.CapacityStatusUnknown {
+		stale := s.capacityFreshness.Freshness(entities.CapacityFieldStatus, statusReportedAt, now)
+		stale.State = entities.FreshnessStale
+		freshness[entities.CapacityFieldStatus] = stale
+	}
+	if len(freshness) == 0 {
+		return nil
+	}
+	return freshness
+}
+
+// hasLiveWait reports whether a wait time was reported and has not yet expired
+func hasLiveWait(freshness map[entities.CapacityField]entities.DataFreshness) bool {
+	wait, ok := freshness[entities.CapacityFieldWaitTime]
+	return ok && wait.State != entities.FreshnessStale
+}
+
 func haversineKm(lat1, lon1, lat2, lon2 float64) float64 {
 	const earthRadiusKm = 6371.0
 	lat1Rad := toRadians(lat1)
@@ -0,0 +1,120 @@
+package services
+
+import (
+	"context"
+	"log"
+	"math"
+	"sort"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/providers"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/repositories"
+)
+
+// travelTimeCandidates is how many of the nearest facilities are reordered by
+// travel time, at least; a page further in reorders the facilities up to it
+const travelTimeCandidates = 50
+
+// SetRoutingProvider enables travel times on search results, the travel_time
+// sort and, behind its feature flag, ranking by travel time
+func (s *FacilityService) SetRoutingProvider(routing providers.RoutingProvider) {
+	s.routing = routing
+}
+
+// SearchWithRoutes runs a facility search and estimates the road trip from
+// params' location to each facility found, by facility ID. For a travel time
+// sort the backends return the nearest candidates by distance, which are
+// reordered by their routes and paged here.
+func (s *FacilityService) SearchWithRoutes(ctx context.Context, params repositories.SearchParams) ([]*entities.Facility, int, map[string]providers.Route, error) {
+	sortByTravel := params.SortBy == repositories.SearchSortTravelTime
+	backendParams := params
+	if sortByTravel {
+		backendParams.SortBy = repositories.SearchSortDistance
+		backendParams.Offset = 0
+		backendParams.Limit = max(params.Offset+params.Limit, travelTimeCandidates)
+	}
+
+	facilities, totalCount, err := s.searchBackends(ctx, backendParams)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+
+	routes := s.travelRoutes(ctx, params.Latitude, params.Longitude, facilities)
+	if sortByTravel {
+		sortByTravelTime(facilities, routes)
+		end := len(facilities)
+		if params.Limit > 0 {
+			end = min(params.Offset+params.Limit, end)
+		}
+		facilities = facilities[min(params.Offset, end):end]
+	}
+	return facilities, totalCount, routes, nil
+}
+
+// travelRoutes estimates the road trip from the origin to each facility, by
+// facility ID. Without a routing provider or an origin there are none, and a
+// failing provider only costs the travel times.
+func (s *FacilityService) travelRoutes(ctx context.Context, lat, lon float64, facilities []*entities.Facility) map[string]providers.Route {
+	if s.routing == nil || (lat == 0 && lon == 0) {
+		return nil
+	}
+	ids := make([]string, 0, len(facilities))
+	destinations := make([]providers.Coordinates, 0, len(facilities))
+	for _, facility := range facilities {
+		if facility == nil || (facility.Location.Latitude == 0 && facility.Location.Longitude == 0) {
+			continue
+		}
+		ids = append(ids, facility.ID)
+		destinations = append(destinations, providers.Coordinates{Latitude: facility.Location.Latitude, Longitude: facility.Location.Longitude})
+	}
+	if len(destinations) == 0 {
+		return nil
+	}
+	routes, err := s.routing.Routes(ctx, providers.Coordinates{Latitude: lat, Longitude: lon}, destinations)
+	if err != nil || len(routes) != len(destinations) {
+		log.Printf("Warning: routing failed, travel times unavailable: %v", err)
+		return nil
+	}
+	byFacility := make(map[string]providers.Route, len(routes))
+	for i, route := range routes {
+		byFacility[ids[i]] = route
+	}
+	return byFacility
+}
+
+// travelMinutes is the travel time to each routed facility, for ranking
+func travelMinutes(routes map[string]providers.Route) map[string]float64 {
+	if len(routes) == 0 {
+		return nil
+	}
+	minutes := make(map[string]float64, len(routes))
+	for id, route := range routes {
+		minutes[id] = route.DurationMinutes
+	}
+	return minutes
+}
+
+// sortByTravelTime orders facilities by travel time, keeping unrouted
+// facilities after the routed ones in their distance order
+func sortByTravelTime(facilities []*entities.Facility, routes map[string]providers.Route) {
+	sort.SliceStable(facilities, func(i, j int) bool {
+		left, leftRouted := routes[facilities[i].ID]
+		right, rightRouted := routes[facilities[j].ID]
+		if leftRouted != rightRouted {
+			return leftRouted
+		}
+		return leftRouted && left.DurationMinutes < right.DurationMinutes
+	})
+}
+
+// setTravelTime fills a search result's travel fields from its route
+func setTravelTime(result *entities.FacilitySearchResult, routes map[string]providers.Route) {
+	route, ok := routes[result.ID]
+	if !ok {
+		return
+	}
+	minutes := int(math.Ceil(route.DurationMinutes))
+	distance := math.Round(route.DistanceKm*10) / 10
+	result.TravelMinutes = &minutes
+	result.RoadDistanceKm = &distance
+}
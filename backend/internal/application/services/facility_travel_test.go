@@ -0,0 +1,76 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/providers"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/repositories"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/evaluation"
+)
+
+// stubRoutingProvider routes to known places in fixed times, counting calls
+type stubRoutingProvider struct {
+	minutes map[providers.Coordinates]float64
+	calls   int
+}
+
+func (p *stubRoutingProvider) Routes(ctx context.Context, origin providers.Coordinates, destinations []providers.Coordinates) ([]providers.Route, error) {
+	p.calls++
+	routes := make([]providers.Route, len(destinations))
+	for i, destination := range destinations {
+		routes[i] = providers.Route{DurationMinutes: p.minutes[destination], DistanceKm: p.minutes[destination] / 3}
+	}
+	return routes, nil
+}
+
+func TestFacilitySearch_SortsByTravelTime(t *testing.T) {
+	// From Yaba, Lagos Island is nearer in a straight line than Ikeja but
+	// the trip crosses the lagoon
+	marina := entities.Location{Latitude: 6.4500, Longitude: 3.3950}
+	ikeja := entities.Location{Latitude: 6.5800, Longitude: 3.3550}
+	corpus := evaluation.NewFixtureCorpus([]*evaluation.FixtureFacility{
+		{Facility: entities.Facility{ID: "marina", Name: "Marina Clinic", Location: marina, IsActive: true}},
+		{Facility: entities.Facility{ID: "ikeja", Name: "Ikeja Clinic", Location: ikeja, IsActive: true}},
+	})
+	facilityService := NewFacilityService(corpus, nil, nil, nil, nil)
+	params := repositories.SearchParams{Latitude: 6.5095, Longitude: 3.3711, RadiusKm: 30, Limit: 1, SortBy: repositories.SearchSortDistance}
+
+	results, _, _, err := facilityService.SearchResultsWithCount(context.Background(), params)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if results[0].ID != "marina" || results[0].TravelMinutes != nil {
+		t.Fatalf("expected Marina nearest and no travel times without routing, got %+v", results[0])
+	}
+
+	routing := &stubRoutingProvider{minutes: map[providers.Coordinates]float64{
+		{Latitude: marina.Latitude, Longitude: marina.Longitude}: 47.6,
+		{Latitude: ikeja.Latitude, Longitude: ikeja.Longitude}:   40.1,
+	}}
+	facilityService.SetRoutingProvider(routing)
+	params.SortBy = repositories.SearchSortTravelTime
+	results, total, _, err := facilityService.SearchResultsWithCount(context.Background(), params)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if total != 2 || len(results) != 1 || results[0].ID != "ikeja" {
+		t.Fatalf("expected Ikeja first of 2 by travel time, got %d: %+v", total, results)
+	}
+	if results[0].TravelMinutes == nil || *results[0].TravelMinutes != 41 || *results[0].RoadDistanceKm != 13.4 {
+		t.Fatalf("expected the travel time rounded up and the road distance, got %+v", results[0])
+	}
+	if routing.calls != 1 {
+		t.Fatalf("expected the routes to be computed once per search, got %d calls", routing.calls)
+	}
+
+	params.Offset = 1
+	results, _, _, err = facilityService.SearchResultsWithCount(context.Background(), params)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != 1 || results[0].ID != "marina" {
+		t.Fatalf("expected Marina on the second page, got %+v", results)
+	}
+}
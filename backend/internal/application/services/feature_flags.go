@@ -5,17 +5,23 @@ import (
 )
 
 type FeatureFlags struct {
-	contextualSearchEnabled bool
-	shadowModeEnabled       bool
+	contextualSearchEnabled  bool
+	shadowModeEnabled        bool
+	semanticSearchEnabled    bool
+	travelTimeRankingEnabled bool
 }
 
 func NewFeatureFlags() *FeatureFlags {
 	enabled := os.Getenv("FEATURE_CONTEXTUAL_SEARCH") == "true"
 	shadow := os.Getenv("FEATURE_CONTEXTUAL_SEARCH_SHADOW") == "true"
+	semantic := os.Getenv("FEATURE_SEMANTIC_SEARCH") == "true"
+	travelTime := os.Getenv("FEATURE_TRAVEL_TIME_RANKING") == "true"
 
 	return &FeatureFlags{
-		contextualSearchEnabled: enabled,
-		shadowModeEnabled:       shadow,
+		contextualSearchEnabled:  enabled,
+		shadowModeEnabled:        shadow,
+		semanticSearchEnabled:    semantic,
+		travelTimeRankingEnabled: travelTime,
 	}
 }
 
@@ -26,3 +32,13 @@ func (f *FeatureFlags) ContextualSearchEnabled() bool {
 func (f *FeatureFlags) ShadowModeEnabled() bool {
 	return f.shadowModeEnabled
 }
+
+// SemanticSearchEnabled reports whether keyword search is fused with vector similarity
+func (f *FeatureFlags) SemanticSearchEnabled() bool {
+	return f.semanticSearchEnabled
+}
+
+// TravelTimeRankingEnabled reports whether ranking uses travel time by road instead of distance
+func (f *FeatureFlags) TravelTimeRankingEnabled() bool {
+	return f.travelTimeRankingEnabled
+}
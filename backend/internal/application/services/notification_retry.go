@@ -0,0 +1,341 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/providers"
+	apperrors "github.com/zatekoja/Patientpricediscoverydesign/backend/pkg/errors"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/pkg/retry"
+)
+
+// notificationRetryConfig schedules resends of failed notifications after about
+// 1, 2, 4, 8 and 16 minutes, jittered so a channel outage does not resend every
+// failure at once. After MaxAttempts retries the notification moves to the
+// dead-letter state and is only resent manually.
+var notificationRetryConfig = retry.Config{
+	MaxAttempts:   5,
+	InitialDelay:  time.Minute,
+	MaxDelay:      time.Hour,
+	BackoffFactor: 2.0,
+	Jitter:        0.1,
+}
+
+const (
+	// notificationRetryLease is how long a claimed retry stays hidden from other
+	// workers; a worker that dies mid-batch leaves its rows due again afterwards.
+	notificationRetryLease     = 5 * time.Minute
+	notificationRetryBatchSize = 50
+)
+
+// notificationColumns selects a notification with its JSONB metadata as raw bytes
+const notificationColumns = `id, appointment_id, notification_type, channel, recipient, status, message_id,
+	sent_at, delivered_at, read_at, failed_at, error_message, COALESCE(retry_count, 0) AS retry_count,
+	next_retry_at, metadata AS metadata_json, created_at, updated_at`
+
+type notificationRow struct {
+	entities.AppointmentNotification
+	MetadataJSON []byte `db:"metadata_json"`
+}
+
+func (r *notificationRow) toEntity() *entities.AppointmentNotification {
+	notification := r.AppointmentNotification
+	if len(r.MetadataJSON) > 0 {
+		_ = json.Unmarshal(r.MetadataJSON, &notification.Metadata)
+	}
+	return &notification
+}
+
+// DeliveryStatusUpdate is a provider callback about a sent message
+type DeliveryStatusUpdate struct {
+	MessageID string
+	Status    entities.NotificationStatus
+	Timestamp time.Time
+	Error     string
+}
+
+// ApplyDeliveryStatus records a delivery callback on the notification sent with
+// the update's message ID. Statuses only move forward (sent, delivered, read),
+// so late or repeated callbacks are harmless. A failed callback queues the
+// notification for retry.
+func (n *NotificationService) ApplyDeliveryStatus(ctx context.Context, update DeliveryStatusUpdate) error {
+	if update.MessageID == "" {
+		return apperrors.NewValidationError("message id is required")
+	}
+
+	var row notificationRow
+	query := `SELECT ` + notificationColumns + ` FROM appointment_notifications WHERE message_id = $1 LIMIT 1`
+	if err := n.db.GetContext(ctx, &row, query, update.MessageID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return apperrors.NewNotFoundError("no notification was sent with this message id")
+		}
+		return apperrors.NewInternalError("failed to load notification", err)
+	}
+	notification := row.toEntity()
+
+	at := update.Timestamp
+	if at.IsZero() {
+		at = time.Now()
+	}
+	if !applyDeliveryStatus(notification, update.Status, at, update.Error) {
+		return nil
+	}
+	notification.UpdatedAt = time.Now()
+
+	if err := n.updateNotification(ctx, notification); err != nil {
+		return apperrors.NewInternalError("failed to update notification", err)
+	}
+	return nil
+}
+
+// applyDeliveryStatus moves the notification to the reported status and
+// reports whether anything changed
+func applyDeliveryStatus(notification *entities.AppointmentNotification, status entities.NotificationStatus, at time.Time, errMsg string) bool {
+	switch status {
+	case entities.NotificationStatusSent:
+		if notification.Status != entities.NotificationStatusPending {
+			return false
+		}
+		notification.Status = entities.NotificationStatusSent
+		notification.SentAt = &at
+	case entities.NotificationStatusDelivered:
+		if notification.Status == entities.NotificationStatusDelivered || notification.Status == entities.NotificationStatusRead {
+			return false
+		}
+		notification.Status = entities.NotificationStatusDelivered
+		notification.DeliveredAt = &at
+		notification.NextRetryAt = nil
+	case entities.NotificationStatusRead:
+		if notification.Status == entities.NotificationStatusRead {
+			return false
+		}
+		notification.Status = entities.NotificationStatusRead
+		notification.ReadAt = &at
+		if notification.DeliveredAt == nil {
+			notification.DeliveredAt = &at
+		}
+		notification.NextRetryAt = nil
+	case entities.NotificationStatusFailed:
+		if notification.Status != entities.NotificationStatusPending && notification.Status != entities.NotificationStatusSent {
+			return false
+		}
+		if errMsg == "" {
+			errMsg = "delivery failed"
+		}
+		recordFailure(notification, errMsg, at)
+	default:
+		return false
+	}
+	return true
+}
+
+// recordFailure marks the notification failed and schedules its next retry,
+// or dead-letters it once the retries are used up
+func recordFailure(notification *entities.AppointmentNotification, errMsg string, now time.Time) {
+	notification.FailedAt = &now
+	notification.ErrorMessage = &errMsg
+	if notification.RetryCount >= notificationRetryConfig.MaxAttempts {
+		notification.Status = entities.NotificationStatusDeadLetter
+		notification.NextRetryAt = nil
+		return
+	}
+	notification.Status = entities.NotificationStatusFailed
+	next := now.Add(retry.Backoff(notificationRetryConfig, notification.RetryCount+1))
+	notification.NextRetryAt = &next
+}
+
+// scheduleRetry queues a failed first attempt for resending
+func (n *NotificationService) scheduleRetry(ctx context.Context, notification *entities.AppointmentNotification) error {
+	errMsg := "delivery failed"
+	if notification.ErrorMessage != nil {
+		errMsg = *notification.ErrorMessage
+	}
+	now := time.Now()
+	recordFailure(notification, errMsg, now)
+	notification.UpdatedAt = now
+	return n.updateNotification(ctx, notification)
+}
+
+// ProcessRetries resends failed notifications whose retry is due and returns
+// how many were sent. Rows are claimed with SKIP LOCKED so several API
+// instances can run the worker at once.
+func (n *NotificationService) ProcessRetries(ctx context.Context, limit int) (int, error) {
+	if limit <= 0 {
+		limit = notificationRetryBatchSize
+	}
+
+	now := time.Now()
+	var rows []notificationRow
+	query := `
+		UPDATE appointment_notifications
+		SET next_retry_at = $1
+		WHERE id IN (
+			SELECT id FROM appointment_notifications
+			WHERE status = 'failed' AND next_retry_at IS NOT NULL AND next_retry_at <= $2
+			ORDER BY next_retry_at
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + notificationColumns
+	if err := n.db.SelectContext(ctx, &rows, query, now.Add(notificationRetryLease), now, limit); err != nil {
+		return 0, fmt.Errorf("failed to claim due notifications: %w", err)
+	}
+
+	sent := 0
+	var errs []error
+	for i := range rows {
+		notification := rows[i].toEntity()
+		if err := n.resend(ctx, notification); err != nil {
+			errs = append(errs, fmt.Errorf("notification %s: %w", notification.ID, err))
+			continue
+		}
+		sent++
+	}
+	return sent, errors.Join(errs...)
+}
+
+// ListNotifications returns notifications in the given status, newest first.
+// The status defaults to failed.
+func (n *NotificationService) ListNotifications(ctx context.Context, status entities.NotificationStatus, limit, offset int) ([]*entities.AppointmentNotification, error) {
+	if status == "" {
+		status = entities.NotificationStatusFailed
+	}
+	switch status {
+	case entities.NotificationStatusPending, entities.NotificationStatusSent, entities.NotificationStatusDelivered,
+		entities.NotificationStatusRead, entities.NotificationStatusFailed, entities.NotificationStatusDeadLetter:
+	default:
+		return nil, apperrors.NewValidationError(fmt.Sprintf("unknown notification status %q", status))
+	}
+	limit, offset = clampPage(limit, offset, 50, 200)
+
+	var rows []notificationRow
+	query := `SELECT ` + notificationColumns + ` FROM appointment_notifications
+		WHERE status = $1 ORDER BY updated_at DESC LIMIT $2 OFFSET $3`
+	if err := n.db.SelectContext(ctx, &rows, query, string(status), limit, offset); err != nil {
+		return nil, apperrors.NewInternalError("failed to list notifications", err)
+	}
+
+	notifications := make([]*entities.AppointmentNotification, 0, len(rows))
+	for i := range rows {
+		notifications = append(notifications, rows[i].toEntity())
+	}
+	return notifications, nil
+}
+
+// ResendNotification immediately resends a failed or dead-lettered notification
+func (n *NotificationService) ResendNotification(ctx context.Context, id string) (*entities.AppointmentNotification, error) {
+	var row notificationRow
+	query := `SELECT ` + notificationColumns + ` FROM appointment_notifications WHERE id = $1`
+	if err := n.db.GetContext(ctx, &row, query, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperrors.NewNotFoundError("notification not found")
+		}
+		return nil, apperrors.NewInternalError("failed to load notification", err)
+	}
+	notification := row.toEntity()
+
+	if notification.Status != entities.NotificationStatusFailed && notification.Status != entities.NotificationStatusDeadLetter {
+		return nil, apperrors.NewConflictError("only failed notifications can be resent")
+	}
+
+	before := *notification
+	err := n.resend(ctx, notification)
+	n.audit.Record(ctx, "notification.resend", "appointment_notification", notification.ID, "", &before, notification)
+	if err != nil {
+		return notification, apperrors.NewExternalError("failed to resend notification", err)
+	}
+	return notification, nil
+}
+
+// resend sends a stored notification again on its original channel. Failures
+// count as a retry and reschedule or dead-letter the notification.
+func (n *NotificationService) resend(ctx context.Context, notification *entities.AppointmentNotification) error {
+	var sendErr error
+	var messageID string
+	sender, ok := n.senders[notification.Channel]
+	if !ok {
+		sendErr = fmt.Errorf("no sender configured for channel %s", notification.Channel)
+	} else {
+		message := messageFromMetadata(notification.Metadata)
+		message.To = notification.Recipient
+		messageID, sendErr = sender.Send(ctx, message)
+	}
+
+	now := time.Now()
+	if sendErr != nil {
+		notification.RetryCount++
+		recordFailure(notification, sendErr.Error(), now)
+	} else {
+		notification.Status = entities.NotificationStatusSent
+		notification.MessageID = &messageID
+		notification.SentAt = &now
+		notification.NextRetryAt = nil
+		notification.ErrorMessage = nil
+	}
+	notification.UpdatedAt = now
+
+	if err := n.updateNotification(ctx, notification); err != nil {
+		return fmt.Errorf("failed to update notification: %w", err)
+	}
+	return sendErr
+}
+
+// StartRetryWorker resends due notifications every interval until ctx is done
+func (n *NotificationService) StartRetryWorker(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				sent, err := n.ProcessRetries(ctx, notificationRetryBatchSize)
+				if err != nil {
+					fmt.Printf("Notification retry batch failed: %v\n", err)
+				}
+				if sent > 0 {
+					fmt.Printf("Resent %d queued notifications\n", sent)
+				}
+			}
+		}
+	}()
+}
+
+// messageMetadata stores the rendered message so a retry sends the same content
+func messageMetadata(message providers.NotificationMessage) map[string]interface{} {
+	metadata := map[string]interface{}{"body": message.Body}
+	if message.Subject != "" {
+		metadata["subject"] = message.Subject
+	}
+	if message.TemplateName != "" {
+		metadata["template_name"] = message.TemplateName
+		metadata["template_language"] = message.TemplateLanguage
+		metadata["template_params"] = message.TemplateParams
+	}
+	return metadata
+}
+
+func messageFromMetadata(metadata map[string]interface{}) providers.NotificationMessage {
+	var message providers.NotificationMessage
+	message.Body, _ = metadata["body"].(string)
+	message.Subject, _ = metadata["subject"].(string)
+	message.TemplateName, _ = metadata["template_name"].(string)
+	message.TemplateLanguage, _ = metadata["template_language"].(string)
+	switch params := metadata["template_params"].(type) {
+	case []string:
+		message.TemplateParams = params
+	case []interface{}:
+		for _, param := range params {
+			if value, ok := param.(string); ok {
+				message.TemplateParams = append(message.TemplateParams, value)
+			}
+		}
+	}
+	return message
+}
@@ -0,0 +1,206 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/providers"
+	apperrors "github.com/zatekoja/Patientpricediscoverydesign/backend/pkg/errors"
+)
+
+type recordingNotificationSender struct {
+	channel  entities.NotificationChannel
+	messages []providers.NotificationMessage
+}
+
+func (r *recordingNotificationSender) Channel() entities.NotificationChannel {
+	return r.channel
+}
+
+func (r *recordingNotificationSender) Send(ctx context.Context, message providers.NotificationMessage) (string, error) {
+	r.messages = append(r.messages, message)
+	return "wamid.retry", nil
+}
+
+var notificationRowColumns = []string{"id", "appointment_id", "notification_type", "channel", "recipient", "status", "message_id",
+	"sent_at", "delivered_at", "read_at", "failed_at", "error_message", "retry_count", "next_retry_at", "metadata_json", "created_at", "updated_at"}
+
+func notificationRows(status entities.NotificationStatus, retryCount int, metadata string) *sqlmock.Rows {
+	now := time.Now()
+	return sqlmock.NewRows(notificationRowColumns).AddRow(
+		"notif-1", "appt-1", "booking_confirmation", "whatsapp", "+2348031234567", string(status), "wamid.1",
+		nil, nil, nil, now, "provider unavailable", retryCount, now, []byte(metadata), now, now,
+	)
+}
+
+func TestApplyDeliveryStatus_OnlyMovesForward(t *testing.T) {
+	at := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	notification := &entities.AppointmentNotification{Status: entities.NotificationStatusSent}
+
+	if !applyDeliveryStatus(notification, entities.NotificationStatusRead, at, "") {
+		t.Fatal("read should apply to a sent notification")
+	}
+	if notification.Status != entities.NotificationStatusRead || notification.ReadAt == nil || notification.DeliveredAt == nil {
+		t.Errorf("read should set read and delivered times, got %+v", notification)
+	}
+
+	// A late delivered or failed callback must not move a read message backwards
+	if applyDeliveryStatus(notification, entities.NotificationStatusDelivered, at.Add(time.Second), "") {
+		t.Error("delivered after read should be ignored")
+	}
+	if applyDeliveryStatus(notification, entities.NotificationStatusFailed, at.Add(time.Second), "expired") {
+		t.Error("failed after read should be ignored")
+	}
+	if notification.Status != entities.NotificationStatusRead {
+		t.Errorf("status = %q, want read", notification.Status)
+	}
+}
+
+func TestApplyDeliveryStatus_FailedQueuesRetry(t *testing.T) {
+	at := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	notification := &entities.AppointmentNotification{Status: entities.NotificationStatusSent}
+
+	if !applyDeliveryStatus(notification, entities.NotificationStatusFailed, at, "Message undeliverable") {
+		t.Fatal("failed should apply to a sent notification")
+	}
+	if notification.Status != entities.NotificationStatusFailed {
+		t.Errorf("status = %q, want failed", notification.Status)
+	}
+	if notification.NextRetryAt == nil || !withinJitter(notification.NextRetryAt.Sub(at), time.Minute) {
+		t.Errorf("next retry = %v, want about one minute after the failure", notification.NextRetryAt)
+	}
+	if notification.ErrorMessage == nil || *notification.ErrorMessage != "Message undeliverable" {
+		t.Errorf("error message = %v", notification.ErrorMessage)
+	}
+}
+
+func TestRecordFailure_BacksOffThenDeadLetters(t *testing.T) {
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	notification := &entities.AppointmentNotification{}
+
+	wantDelays := []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute, 8 * time.Minute, 16 * time.Minute}
+	for retryCount, want := range wantDelays {
+		notification.RetryCount = retryCount
+		recordFailure(notification, "timeout", now)
+		if notification.Status != entities.NotificationStatusFailed {
+			t.Fatalf("retry %d: status = %q, want failed", retryCount, notification.Status)
+		}
+		if got := notification.NextRetryAt.Sub(now); !withinJitter(got, want) {
+			t.Errorf("retry %d: delay = %v, want %v ±10%%", retryCount, got, want)
+		}
+	}
+
+	notification.RetryCount = notificationRetryConfig.MaxAttempts
+	recordFailure(notification, "timeout", now)
+	if notification.Status != entities.NotificationStatusDeadLetter || notification.NextRetryAt != nil {
+		t.Errorf("expected dead letter with no retry scheduled, got %q next %v", notification.Status, notification.NextRetryAt)
+	}
+}
+
+// withinJitter reports whether got is want spread by the retry jitter
+func withinJitter(got, want time.Duration) bool {
+	spread := time.Duration(notificationRetryConfig.Jitter * float64(want))
+	return got >= want-spread && got <= want+spread
+}
+
+func TestNotificationService_ProcessRetriesResendsStoredMessage(t *testing.T) {
+	sender := &recordingNotificationSender{channel: entities.ChannelWhatsApp}
+	db, mock := setupMockDB(t)
+	defer db.Close()
+	service, err := NewNotificationServiceWithSenders(db, sender)
+	if err != nil {
+		t.Fatalf("NewNotificationServiceWithSenders() error = %v", err)
+	}
+
+	metadata := `{"body":"Confirmed","template_name":"appointment_confirmation","template_language":"en_US","template_params":["Monday","9:00 AM","Lagoon Hospital"]}`
+	mock.ExpectQuery(`UPDATE appointment_notifications\s+SET next_retry_at = \$1\s+WHERE id IN`).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), 50).
+		WillReturnRows(notificationRows(entities.NotificationStatusFailed, 1, metadata))
+	expectNotificationUpdate(mock, entities.NotificationStatusSent, 1)
+
+	sent, err := service.ProcessRetries(context.Background(), 0)
+	if err != nil {
+		t.Fatalf("ProcessRetries() error = %v", err)
+	}
+	if sent != 1 {
+		t.Errorf("sent = %d, want 1", sent)
+	}
+	if len(sender.messages) != 1 {
+		t.Fatalf("sender called %d times, want 1", len(sender.messages))
+	}
+	got := sender.messages[0]
+	if got.To != "+2348031234567" || got.TemplateName != "appointment_confirmation" || len(got.TemplateParams) != 3 || got.TemplateParams[2] != "Lagoon Hospital" {
+		t.Errorf("unexpected resent message: %+v", got)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet database expectations: %v", err)
+	}
+}
+
+func TestNotificationService_ResendNotification(t *testing.T) {
+	failing := &failingNotificationSender{channel: entities.ChannelWhatsApp}
+	db, mock := setupMockDB(t)
+	defer db.Close()
+	service, err := NewNotificationServiceWithSenders(db, failing)
+	if err != nil {
+		t.Fatalf("NewNotificationServiceWithSenders() error = %v", err)
+	}
+
+	mock.ExpectQuery(`FROM appointment_notifications WHERE id = \$1`).
+		WithArgs("missing").
+		WillReturnError(sql.ErrNoRows)
+	_, err = service.ResendNotification(context.Background(), "missing")
+	requireAppErrorType(t, err, apperrors.ErrorTypeNotFound, "unknown notification")
+
+	mock.ExpectQuery(`FROM appointment_notifications WHERE id = \$1`).
+		WithArgs("notif-1").
+		WillReturnRows(notificationRows(entities.NotificationStatusDelivered, 0, `{"body":"Confirmed"}`))
+	_, err = service.ResendNotification(context.Background(), "notif-1")
+	requireAppErrorType(t, err, apperrors.ErrorTypeConflict, "delivered notification")
+
+	// A dead-lettered notification that fails again stays dead-lettered
+	mock.ExpectQuery(`FROM appointment_notifications WHERE id = \$1`).
+		WithArgs("notif-1").
+		WillReturnRows(notificationRows(entities.NotificationStatusDeadLetter, notificationRetryConfig.MaxAttempts, `{"body":"Confirmed"}`))
+	expectNotificationUpdate(mock, entities.NotificationStatusDeadLetter, notificationRetryConfig.MaxAttempts+1)
+	notification, err := service.ResendNotification(context.Background(), "notif-1")
+	requireAppErrorType(t, err, apperrors.ErrorTypeExternal, "failed resend")
+	if notification.Status != entities.NotificationStatusDeadLetter {
+		t.Errorf("status = %q, want dead_letter", notification.Status)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet database expectations: %v", err)
+	}
+}
+
+func TestNotificationService_ApplyDeliveryStatusUnknownMessage(t *testing.T) {
+	db, mock := setupMockDB(t)
+	defer db.Close()
+	service, err := NewNotificationServiceWithSenders(db, &recordingNotificationSender{channel: entities.ChannelWhatsApp})
+	if err != nil {
+		t.Fatalf("NewNotificationServiceWithSenders() error = %v", err)
+	}
+
+	mock.ExpectQuery(`FROM appointment_notifications WHERE message_id = \$1`).
+		WithArgs("wamid.unknown").
+		WillReturnError(sql.ErrNoRows)
+	err = service.ApplyDeliveryStatus(context.Background(), DeliveryStatusUpdate{MessageID: "wamid.unknown", Status: entities.NotificationStatusDelivered})
+	requireAppErrorType(t, err, apperrors.ErrorTypeNotFound, "unknown message id")
+
+	mock.ExpectQuery(`FROM appointment_notifications WHERE message_id = \$1`).
+		WithArgs("wamid.1").
+		WillReturnRows(notificationRows(entities.NotificationStatusSent, 0, `{"body":"Confirmed"}`))
+	expectNotificationUpdate(mock, entities.NotificationStatusDelivered, 0)
+	err = service.ApplyDeliveryStatus(context.Background(), DeliveryStatusUpdate{MessageID: "wamid.1", Status: entities.NotificationStatusDelivered, Timestamp: time.Now()})
+	if err != nil {
+		t.Fatalf("ApplyDeliveryStatus() error = %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet database expectations: %v", err)
+	}
+}
@@ -2,7 +2,9 @@ package services
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -11,28 +13,58 @@ import (
 	"github.com/jmoiron/sqlx"
 
 	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/providers"
 	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/infrastructure/notifications"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/pkg/utils"
 )
 
+// notificationFallbackOrder is the order channels are tried in until one delivers
+var notificationFallbackOrder = []entities.NotificationChannel{
+	entities.ChannelWhatsApp,
+	entities.ChannelSMS,
+	entities.ChannelEmail,
+}
+
 // NotificationService handles sending notifications
 type NotificationService struct {
-	db             *sqlx.DB
-	whatsappSender *notifications.WhatsAppCloudSender
+	db      *sqlx.DB
+	senders map[entities.NotificationChannel]providers.NotificationSender
+	audit   *AuditService
 }
 
-// NewNotificationService creates a new notification service
+// NewNotificationService creates a new notification service that delivers over WhatsApp
 func NewNotificationService(db *sqlx.DB) (*NotificationService, error) {
 	whatsappSender, err := notifications.NewWhatsAppCloudSender()
 	if err != nil {
 		return nil, fmt.Errorf("failed to create WhatsApp sender: %w", err)
 	}
 
+	return NewNotificationServiceWithSenders(db, whatsappSender)
+}
+
+// NewNotificationServiceWithSenders creates a notification service that delivers
+// over the given senders, one per channel
+func NewNotificationServiceWithSenders(db *sqlx.DB, senders ...providers.NotificationSender) (*NotificationService, error) {
+	if len(senders) == 0 {
+		return nil, fmt.Errorf("at least one notification sender is required")
+	}
+
+	byChannel := make(map[entities.NotificationChannel]providers.NotificationSender, len(senders))
+	for _, sender := range senders {
+		byChannel[sender.Channel()] = sender
+	}
+
 	return &NotificationService{
-		db:             db,
-		whatsappSender: whatsappSender,
+		db:      db,
+		senders: byChannel,
 	}, nil
 }
 
+// SetAuditLog records manual resends in the audit log
+func (n *NotificationService) SetAuditLog(audit *AuditService) {
+	n.audit = audit
+}
+
 // NotificationContext contains all data needed for notification rendering
 type NotificationContext struct {
 	AppointmentID     string
@@ -51,17 +83,7 @@ type NotificationContext struct {
 
 // SendBookingConfirmation sends a booking confirmation notification
 func (n *NotificationService) SendBookingConfirmation(ctx context.Context, appointment *entities.Appointment, facility *entities.Facility, procedure *entities.Procedure) error {
-	// Get notification preferences
-	prefs, err := n.getNotificationPreferences(ctx, appointment.PatientPhone)
-	if err != nil {
-		// If no preferences, use defaults (WhatsApp enabled)
-		prefs = &entities.NotificationPreference{
-			Phone:           &appointment.PatientPhone,
-			Email:           &appointment.PatientEmail,
-			WhatsAppEnabled: true,
-			EmailEnabled:    true,
-		}
-	}
+	prefs := n.resolvePreferences(ctx, appointment)
 
 	// Build notification context
 	notifCtx := &NotificationContext{
@@ -79,12 +101,9 @@ func (n *NotificationService) SendBookingConfirmation(ctx context.Context, appoi
 		Notes:             appointment.Notes,
 	}
 
-	// Send WhatsApp notification if enabled
-	if prefs.WhatsAppEnabled && prefs.Phone != nil && *prefs.Phone != "" {
-		if err := n.sendWhatsAppNotification(ctx, entities.NotificationBookingConfirmation, notifCtx); err != nil {
-			// Log error but don't fail - try other channels
-			fmt.Printf("Failed to send WhatsApp notification: %v\n", err)
-		}
+	// Log error but don't fail the booking
+	if err := n.deliver(ctx, entities.NotificationBookingConfirmation, prefs, notifCtx); err != nil {
+		fmt.Printf("Failed to send booking confirmation: %v\n", err)
 	}
 
 	return nil
@@ -92,17 +111,12 @@ func (n *NotificationService) SendBookingConfirmation(ctx context.Context, appoi
 
 // SendCancellationNotice sends a cancellation notice
 func (n *NotificationService) SendCancellationNotice(ctx context.Context, appointment *entities.Appointment, facility *entities.Facility, procedure *entities.Procedure) error {
-	prefs, err := n.getNotificationPreferences(ctx, appointment.PatientPhone)
-	if err != nil {
-		prefs = &entities.NotificationPreference{
-			Phone:           &appointment.PatientPhone,
-			WhatsAppEnabled: true,
-		}
-	}
+	prefs := n.resolvePreferences(ctx, appointment)
 
 	notifCtx := &NotificationContext{
 		AppointmentID: appointment.ID,
 		PatientName:   appointment.PatientName,
+		PatientEmail:  appointment.PatientEmail,
 		PatientPhone:  appointment.PatientPhone,
 		FacilityName:  facility.Name,
 		ProcedureName: procedure.Name,
@@ -110,10 +124,8 @@ func (n *NotificationService) SendCancellationNotice(ctx context.Context, appoin
 		ScheduledTime: appointment.ScheduledAt.Format("3:04 PM"),
 	}
 
-	if prefs.WhatsAppEnabled && prefs.Phone != nil {
-		if err := n.sendWhatsAppNotification(ctx, entities.NotificationCancellation, notifCtx); err != nil {
-			fmt.Printf("Failed to send WhatsApp cancellation: %v\n", err)
-		}
+	if err := n.deliver(ctx, entities.NotificationCancellation, prefs, notifCtx); err != nil {
+		fmt.Printf("Failed to send cancellation notice: %v\n", err)
 	}
 
 	return nil
@@ -121,15 +133,7 @@ func (n *NotificationService) SendCancellationNotice(ctx context.Context, appoin
 
 // SendReminder sends a reminder notification
 func (n *NotificationService) SendReminder(ctx context.Context, appointment *entities.Appointment, facility *entities.Facility, procedure *entities.Procedure, reminderType entities.NotificationType) error {
-	prefs, err := n.getNotificationPreferences(ctx, appointment.PatientPhone)
-	if err != nil {
-		prefs = &entities.NotificationPreference{
-			Phone:              &appointment.PatientPhone,
-			WhatsAppEnabled:    true,
-			Reminder24hEnabled: true,
-			Reminder1hEnabled:  true,
-		}
-	}
+	prefs := n.resolvePreferences(ctx, appointment)
 
 	// Check if this reminder type is enabled
 	if reminderType == entities.NotificationReminder24h && !prefs.Reminder24hEnabled {
@@ -142,6 +146,7 @@ func (n *NotificationService) SendReminder(ctx context.Context, appointment *ent
 	notifCtx := &NotificationContext{
 		AppointmentID:   appointment.ID,
 		PatientName:     appointment.PatientName,
+		PatientEmail:    appointment.PatientEmail,
 		PatientPhone:    appointment.PatientPhone,
 		FacilityName:    facility.Name,
 		FacilityAddress: fmt.Sprintf("%s, %s", facility.Address.Street, facility.Address.City),
@@ -151,83 +156,171 @@ func (n *NotificationService) SendReminder(ctx context.Context, appointment *ent
 		MeetingLink:     appointment.MeetingLink,
 	}
 
-	if prefs.WhatsAppEnabled && prefs.Phone != nil {
-		if err := n.sendWhatsAppNotification(ctx, reminderType, notifCtx); err != nil {
-			fmt.Printf("Failed to send WhatsApp reminder: %v\n", err)
-		}
+	if err := n.deliver(ctx, reminderType, prefs, notifCtx); err != nil {
+		fmt.Printf("Failed to send reminder: %v\n", err)
 	}
 
 	return nil
 }
 
-// sendWhatsAppNotification sends a WhatsApp notification
-func (n *NotificationService) sendWhatsAppNotification(ctx context.Context, notifType entities.NotificationType, notifCtx *NotificationContext) error {
+// resolvePreferences returns the patient's saved preferences, or defaults that
+// allow every channel when the patient has none
+func (n *NotificationService) resolvePreferences(ctx context.Context, appointment *entities.Appointment) *entities.NotificationPreference {
+	prefs, err := n.getNotificationPreferences(ctx, appointment)
+	if err == nil {
+		return prefs
+	}
+	return &entities.NotificationPreference{
+		Phone:              &appointment.PatientPhone,
+		Email:              &appointment.PatientEmail,
+		WhatsAppEnabled:    true,
+		SMSEnabled:         true,
+		EmailEnabled:       true,
+		Reminder24hEnabled: true,
+		Reminder1hEnabled:  true,
+	}
+}
+
+// deliver sends the notification over the first channel that succeeds, trying
+// WhatsApp, then SMS, then email among the channels the patient has enabled.
+// Each attempt is recorded. If every channel fails, the preferred channel's
+// attempt is queued for retry and the joined errors are returned.
+func (n *NotificationService) deliver(ctx context.Context, notifType entities.NotificationType, prefs *entities.NotificationPreference, notifCtx *NotificationContext) error {
+	var errs []error
+	var firstFailed *entities.AppointmentNotification
+	for _, channel := range notificationFallbackOrder {
+		sender, ok := n.senders[channel]
+		if !ok || !channelEnabled(prefs, channel) {
+			continue
+		}
+		recipient := notificationRecipient(channel, prefs, notifCtx)
+		if recipient == "" {
+			continue
+		}
+
+		notification, err := n.sendOnChannel(ctx, sender, notifType, recipient, notifCtx)
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", channel, err))
+		if firstFailed == nil && notification != nil {
+			firstFailed = notification
+		}
+	}
+
+	if firstFailed != nil {
+		if err := n.scheduleRetry(ctx, firstFailed); err != nil {
+			errs = append(errs, fmt.Errorf("failed to schedule retry: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func channelEnabled(prefs *entities.NotificationPreference, channel entities.NotificationChannel) bool {
+	switch channel {
+	case entities.ChannelWhatsApp:
+		return prefs.WhatsAppEnabled
+	case entities.ChannelSMS:
+		return prefs.SMSEnabled
+	case entities.ChannelEmail:
+		return prefs.EmailEnabled
+	default:
+		return false
+	}
+}
+
+// notificationRecipient prefers the contact details saved in the patient's
+// preferences over those given on the appointment
+func notificationRecipient(channel entities.NotificationChannel, prefs *entities.NotificationPreference, notifCtx *NotificationContext) string {
+	if channel == entities.ChannelEmail {
+		if prefs.Email != nil && *prefs.Email != "" {
+			return *prefs.Email
+		}
+		return notifCtx.PatientEmail
+	}
+
+	phone := notifCtx.PatientPhone
+	if prefs.Phone != nil && *prefs.Phone != "" {
+		phone = *prefs.Phone
+	}
+	if phone == "" {
+		return ""
+	}
+	if normalized, err := utils.NormalizePhone(phone); err == nil {
+		return normalized
+	}
+	return phone
+}
+
+// sendOnChannel renders the channel's template and sends it, recording the
+// attempt. The notification record is returned once it has been saved.
+func (n *NotificationService) sendOnChannel(ctx context.Context, sender providers.NotificationSender, notifType entities.NotificationType, recipient string, notifCtx *NotificationContext) (*entities.AppointmentNotification, error) {
+	channel := sender.Channel()
+
 	// Get template
-	template, err := n.getTemplate(ctx, entities.ChannelWhatsApp, notifType)
+	template, err := n.getTemplate(ctx, channel, notifType)
 	if err != nil {
-		return fmt.Errorf("failed to get template: %w", err)
+		return nil, fmt.Errorf("failed to get template: %w", err)
 	}
 
-	// Render message body
-	body := n.renderTemplate(template.Body, notifCtx)
+	message := providers.NotificationMessage{
+		To:   recipient,
+		Body: n.renderTemplate(template.Body, notifCtx),
+	}
+	if channel == entities.ChannelEmail {
+		subject := "Your appointment at {{facility_name}}"
+		if template.Subject != nil && *template.Subject != "" {
+			subject = *template.Subject
+		}
+		message.Subject = n.renderTemplate(subject, notifCtx)
+	}
+	if channel == entities.ChannelWhatsApp && template.WhatsAppTemplateName != nil && *template.WhatsAppTemplateName != "" {
+		// Use approved template; freeform text is sent otherwise
+		message.TemplateName = *template.WhatsAppTemplateName
+		message.TemplateLanguage = template.WhatsAppTemplateLang
+		message.TemplateParams = n.extractTemplateParameters(notifCtx)
+	}
 
 	// Create notification record
 	notification := &entities.AppointmentNotification{
 		ID:               uuid.New().String(),
 		AppointmentID:    notifCtx.AppointmentID,
 		NotificationType: notifType,
-		Channel:          entities.ChannelWhatsApp,
-		Recipient:        notifCtx.PatientPhone,
+		Channel:          channel,
+		Recipient:        recipient,
 		Status:           entities.NotificationStatusPending,
 		RetryCount:       0,
+		Metadata:         messageMetadata(message),
 		CreatedAt:        time.Now(),
 		UpdatedAt:        time.Now(),
 	}
 
 	// Save notification record
 	if err := n.createNotification(ctx, notification); err != nil {
-		return fmt.Errorf("failed to create notification record: %w", err)
-	}
-
-	// Send via WhatsApp
-	var messageID string
-	var sendErr error
-
-	if template.WhatsAppTemplateName != nil && *template.WhatsAppTemplateName != "" {
-		// Use approved template
-		parameters := n.extractTemplateParameters(notifCtx)
-		messageID, sendErr = n.whatsappSender.SendTemplate(
-			notifCtx.PatientPhone,
-			*template.WhatsAppTemplateName,
-			template.WhatsAppTemplateLang,
-			parameters,
-		)
-	} else {
-		// Use freeform text (for testing or if template not approved)
-		messageID, sendErr = n.whatsappSender.SendText(notifCtx.PatientPhone, body)
+		return nil, fmt.Errorf("failed to create notification record: %w", err)
 	}
 
+	messageID, sendErr := sender.Send(ctx, message)
+
 	// Update notification status
+	now := time.Now()
 	if sendErr != nil {
-		now := time.Now()
 		errMsg := sendErr.Error()
 		notification.Status = entities.NotificationStatusFailed
 		notification.FailedAt = &now
 		notification.ErrorMessage = &errMsg
-		notification.UpdatedAt = now
 	} else {
-		now := time.Now()
 		notification.Status = entities.NotificationStatusSent
 		notification.MessageID = &messageID
 		notification.SentAt = &now
-		notification.UpdatedAt = now
 	}
+	notification.UpdatedAt = now
 
 	if err := n.updateNotification(ctx, notification); err != nil {
-		return fmt.Errorf("failed to update notification: %w", err)
+		return notification, fmt.Errorf("failed to update notification: %w", err)
 	}
 
-	return sendErr
+	return notification, sendErr
 }
 
 // renderTemplate replaces placeholders in template
@@ -287,13 +380,30 @@ func (n *NotificationService) extractTemplateParameters(ctx *NotificationContext
 }
 
 // Database operations
-func (n *NotificationService) getNotificationPreferences(ctx context.Context, phone string) (*entities.NotificationPreference, error) {
+
+// getNotificationPreferences looks up the patient's account preferences, by
+// user for linked appointments and otherwise by normalized phone number.
+func (n *NotificationService) getNotificationPreferences(ctx context.Context, appointment *entities.Appointment) (*entities.NotificationPreference, error) {
 	var prefs entities.NotificationPreference
-	query := `SELECT * FROM notification_preferences WHERE phone = $1 LIMIT 1`
-	err := n.db.GetContext(ctx, &prefs, query, phone)
+	if appointment.UserID != nil && *appointment.UserID != "" {
+		query := `SELECT * FROM notification_preferences WHERE user_id = $1 LIMIT 1`
+		err := n.db.GetContext(ctx, &prefs, query, *appointment.UserID)
+		if err == nil {
+			return &prefs, nil
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+	}
+
+	phone, err := utils.NormalizePhone(appointment.PatientPhone)
 	if err != nil {
 		return nil, err
 	}
+	query := `SELECT * FROM notification_preferences WHERE phone = $1 LIMIT 1`
+	if err := n.db.GetContext(ctx, &prefs, query, phone); err != nil {
+		return nil, err
+	}
 	return &prefs, nil
 }
 
@@ -329,13 +439,13 @@ func (n *NotificationService) updateNotification(ctx context.Context, notificati
 	query := `
 		UPDATE appointment_notifications 
 		SET status = $1, message_id = $2, sent_at = $3, delivered_at = $4, read_at = $5,
-		    failed_at = $6, error_message = $7, retry_count = $8, metadata = $9, updated_at = $10
-		WHERE id = $11
+		    failed_at = $6, error_message = $7, retry_count = $8, next_retry_at = $9, metadata = $10, updated_at = $11
+		WHERE id = $12
 	`
 	_, err := n.db.ExecContext(ctx, query,
 		notification.Status, notification.MessageID, notification.SentAt, notification.DeliveredAt,
 		notification.ReadAt, notification.FailedAt, notification.ErrorMessage, notification.RetryCount,
-		metadata, notification.UpdatedAt, notification.ID,
+		notification.NextRetryAt, metadata, notification.UpdatedAt, notification.ID,
 	)
 	return err
 }
@@ -1,10 +1,20 @@
 package services
 
 import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/jmoiron/sqlx"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/providers"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/infrastructure/notifications"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/infrastructure/notifications/notificationstest"
 )
 
 func setupMockDB(t *testing.T) (*sqlx.DB, sqlmock.Sqlmock) {
@@ -123,3 +133,185 @@ func TestNotificationService_ExtractTemplateParameters(t *testing.T) {
 		})
 	}
 }
+
+type failingNotificationSender struct {
+	channel entities.NotificationChannel
+	calls   int
+}
+
+func (f *failingNotificationSender) Channel() entities.NotificationChannel {
+	return f.channel
+}
+
+func (f *failingNotificationSender) Send(ctx context.Context, message providers.NotificationMessage) (string, error) {
+	f.calls++
+	return "", errors.New("provider unavailable")
+}
+
+var templateColumns = []string{"id", "name", "channel", "template_type", "subject", "body", "whatsapp_template_name", "whatsapp_template_lang", "is_active", "created_at", "updated_at"}
+
+func expectTemplate(mock sqlmock.Sqlmock, channel entities.NotificationChannel, subject interface{}, body string) {
+	mock.ExpectQuery(`SELECT \* FROM notification_templates WHERE channel = \$1`).
+		WithArgs(string(channel), string(entities.NotificationBookingConfirmation)).
+		WillReturnRows(sqlmock.NewRows(templateColumns).
+			AddRow("tmpl-"+string(channel), "booking_confirmation_"+string(channel), string(channel), "booking_confirmation", subject, body, nil, "en_US", true, time.Now(), time.Now()))
+}
+
+func expectNotificationAttempt(mock sqlmock.Sqlmock, channel entities.NotificationChannel, recipient string, status entities.NotificationStatus) {
+	mock.ExpectExec(`INSERT INTO appointment_notifications`).
+		WithArgs(sqlmock.AnyArg(), "appt-1", entities.NotificationBookingConfirmation, channel, recipient, entities.NotificationStatusPending,
+			sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), 0, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectNotificationUpdate(mock, status, 0)
+}
+
+func expectNotificationUpdate(mock sqlmock.Sqlmock, status entities.NotificationStatus, retryCount int) {
+	mock.ExpectExec(`UPDATE appointment_notifications`).
+		WithArgs(status, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), retryCount, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+}
+
+func TestNotificationService_FallsBackToSMSWhenWhatsAppFails(t *testing.T) {
+	gateway := notificationstest.NewSMSGateway(t)
+	t.Setenv("SMS_GATEWAY_URL", gateway.URL())
+	t.Setenv("SMS_GATEWAY_API_KEY", "sms-key")
+	smsSender, err := notifications.NewHTTPSMSSender()
+	if err != nil {
+		t.Fatalf("NewHTTPSMSSender() error = %v", err)
+	}
+	whatsapp := &failingNotificationSender{channel: entities.ChannelWhatsApp}
+
+	db, mock := setupMockDB(t)
+	defer db.Close()
+	service, err := NewNotificationServiceWithSenders(db, whatsapp, smsSender)
+	if err != nil {
+		t.Fatalf("NewNotificationServiceWithSenders() error = %v", err)
+	}
+
+	// No saved preferences: every channel is allowed
+	mock.ExpectQuery(`SELECT \* FROM notification_preferences WHERE phone = \$1`).
+		WithArgs("+2348031234567").
+		WillReturnError(sql.ErrNoRows)
+	expectTemplate(mock, entities.ChannelWhatsApp, nil, "Confirmed at {{facility_name}}")
+	expectNotificationAttempt(mock, entities.ChannelWhatsApp, "+2348031234567", entities.NotificationStatusFailed)
+	expectTemplate(mock, entities.ChannelSMS, nil, "Confirmed: {{procedure_name}} at {{facility_name}} on {{scheduled_date}}")
+	expectNotificationAttempt(mock, entities.ChannelSMS, "+2348031234567", entities.NotificationStatusSent)
+
+	appointment := &entities.Appointment{
+		ID:           "appt-1",
+		PatientName:  "Ada",
+		PatientPhone: "0803 123 4567",
+		PatientEmail: "ada@example.com",
+		ScheduledAt:  time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC),
+	}
+	facility := &entities.Facility{Name: "Lagoon Hospital"}
+	procedure := &entities.Procedure{Name: "MRI Scan"}
+
+	if err := service.SendBookingConfirmation(context.Background(), appointment, facility, procedure); err != nil {
+		t.Fatalf("SendBookingConfirmation() error = %v", err)
+	}
+	if whatsapp.calls != 1 {
+		t.Errorf("WhatsApp sender called %d times, want 1", whatsapp.calls)
+	}
+	requests := gateway.Requests()
+	if len(requests) != 1 {
+		t.Fatalf("SMS gateway received %d requests, want 1", len(requests))
+	}
+	if requests[0].To != "+2348031234567" || requests[0].Message != "Confirmed: MRI Scan at Lagoon Hospital on Monday, March 2, 2026" {
+		t.Errorf("unexpected SMS: %+v", requests[0])
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet database expectations: %v", err)
+	}
+}
+
+func TestNotificationService_DeliverHonoursPreferences(t *testing.T) {
+	server := notificationstest.NewSMTPServer(t)
+	t.Setenv("SMTP_HOST", server.Host())
+	t.Setenv("SMTP_PORT", server.Port())
+	t.Setenv("SMTP_FROM", "no-reply@example.com")
+	emailSender, err := notifications.NewSMTPEmailSender()
+	if err != nil {
+		t.Fatalf("NewSMTPEmailSender() error = %v", err)
+	}
+	whatsapp := &failingNotificationSender{channel: entities.ChannelWhatsApp}
+
+	db, mock := setupMockDB(t)
+	defer db.Close()
+	service, err := NewNotificationServiceWithSenders(db, whatsapp, emailSender)
+	if err != nil {
+		t.Fatalf("NewNotificationServiceWithSenders() error = %v", err)
+	}
+
+	expectTemplate(mock, entities.ChannelEmail, "Appointment confirmed: {{procedure_name}}", "Hi {{patient_name}}, see you at {{facility_name}}.")
+	expectNotificationAttempt(mock, entities.ChannelEmail, "ada.saved@example.com", entities.NotificationStatusSent)
+
+	savedEmail := "ada.saved@example.com"
+	prefs := &entities.NotificationPreference{Email: &savedEmail, WhatsAppEnabled: false, EmailEnabled: true}
+	notifCtx := &NotificationContext{
+		AppointmentID: "appt-1",
+		PatientName:   "Ada",
+		PatientPhone:  "+2348031234567",
+		PatientEmail:  "ada@example.com",
+		FacilityName:  "Lagoon Hospital",
+		ProcedureName: "MRI Scan",
+	}
+
+	if err := service.deliver(context.Background(), entities.NotificationBookingConfirmation, prefs, notifCtx); err != nil {
+		t.Fatalf("deliver() error = %v", err)
+	}
+	if whatsapp.calls != 0 {
+		t.Errorf("WhatsApp is disabled in preferences but was called %d times", whatsapp.calls)
+	}
+	messages := server.Messages()
+	if len(messages) != 1 {
+		t.Fatalf("SMTP server received %d messages, want 1", len(messages))
+	}
+	if messages[0].To[0] != savedEmail {
+		t.Errorf("email sent to %v, want the saved address", messages[0].To)
+	}
+	if !strings.Contains(messages[0].Data, "Subject: Appointment confirmed: MRI Scan") || !strings.Contains(messages[0].Data, "Hi Ada, see you at Lagoon Hospital.") {
+		t.Errorf("unexpected email:\n%s", messages[0].Data)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet database expectations: %v", err)
+	}
+}
+
+func TestNotificationService_DeliverQueuesRetryWhenAllChannelsFail(t *testing.T) {
+	whatsapp := &failingNotificationSender{channel: entities.ChannelWhatsApp}
+	sms := &failingNotificationSender{channel: entities.ChannelSMS}
+
+	db, mock := setupMockDB(t)
+	defer db.Close()
+	service, err := NewNotificationServiceWithSenders(db, whatsapp, sms)
+	if err != nil {
+		t.Fatalf("NewNotificationServiceWithSenders() error = %v", err)
+	}
+
+	expectTemplate(mock, entities.ChannelWhatsApp, nil, "Confirmed")
+	expectNotificationAttempt(mock, entities.ChannelWhatsApp, "+2348031234567", entities.NotificationStatusFailed)
+	expectTemplate(mock, entities.ChannelSMS, nil, "Confirmed")
+	expectNotificationAttempt(mock, entities.ChannelSMS, "+2348031234567", entities.NotificationStatusFailed)
+	// The preferred channel's attempt is queued for retry
+	expectNotificationUpdate(mock, entities.NotificationStatusFailed, 0)
+
+	phone := "08031234567"
+	prefs := &entities.NotificationPreference{Phone: &phone, WhatsAppEnabled: true, SMSEnabled: true, EmailEnabled: true}
+	err = service.deliver(context.Background(), entities.NotificationBookingConfirmation, prefs, &NotificationContext{AppointmentID: "appt-1"})
+	if err == nil || !strings.Contains(err.Error(), "whatsapp") || !strings.Contains(err.Error(), "sms") {
+		t.Fatalf("deliver() error = %v, want errors from both channels", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet database expectations: %v", err)
+	}
+}
+
+func TestNewNotificationServiceWithSenders_RequiresSender(t *testing.T) {
+	db, _ := setupMockDB(t)
+	defer db.Close()
+
+	if _, err := NewNotificationServiceWithSenders(db); err == nil {
+		t.Error("expected error when no senders are given")
+	}
+}
@@ -0,0 +1,216 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/repositories"
+)
+
+const (
+	defaultSuggestionLimit = 8
+	facilitySearchPath     = "/api/facilities/search"
+)
+
+// suggestionOrder is the order suggestion types are listed and fill spare slots in
+var suggestionOrder = []entities.SuggestionType{
+	entities.SuggestionTypeProcedure,
+	entities.SuggestionTypeCondition,
+	entities.SuggestionTypeFacility,
+	entities.SuggestionTypeSpecialty,
+}
+
+// FacilitySuggester suggests facilities for a partial query
+type FacilitySuggester interface {
+	Suggest(ctx context.Context, query string, lat, lon float64, limit int) ([]*entities.Facility, error)
+}
+
+// ProcedureSearchService searches canonical procedures and builds the typed
+// suggestions behind autocomplete
+type ProcedureSearchService struct {
+	search     repositories.ProcedureSearchRepository
+	facilities FacilitySuggester
+	semantic   *SemanticSearch
+}
+
+// NewProcedureSearchService creates a new procedure search service
+func NewProcedureSearchService(search repositories.ProcedureSearchRepository, facilities FacilitySuggester) *ProcedureSearchService {
+	return &ProcedureSearchService{
+		search:     search,
+		facilities: facilities,
+	}
+}
+
+// SetSemanticSearch fuses procedure search with embedding similarity.
+// Suggestions stay keyword-only, since partial words embed poorly.
+func (s *ProcedureSearchService) SetSemanticSearch(semantic *SemanticSearch) {
+	s.semantic = semantic
+}
+
+// Search returns the procedures matching params and the total number of
+// matches. Without a query the most widely offered procedures come first.
+func (s *ProcedureSearchService) Search(ctx context.Context, params repositories.ProcedureSearchParams) ([]*entities.ProcedureSearchResult, int, error) {
+	params.Query = strings.TrimSpace(params.Query)
+	if s.semantic != nil && params.Query != "" {
+		if embedding := s.semantic.EmbedQuery(ctx, params.Query); embedding != nil {
+			params.QueryEmbedding = embedding
+			params.EmbeddingModel = s.semantic.EmbeddingModel()
+			params.VectorWeight = s.semantic.VectorWeight()
+			params.MinSimilarity = s.semantic.MinSimilarity()
+		}
+	}
+	return s.search.SearchProcedures(ctx, params)
+}
+
+// Suggest returns up to limit suggestions for a partial query. Procedures
+// get half the slots and conditions, facilities and specialties share the
+// rest, with slots a type cannot fill going to the others. A source that
+// fails is left out rather than failing the whole suggestion.
+func (s *ProcedureSearchService) Suggest(ctx context.Context, query string, lat, lon float64, limit int) ([]entities.Suggestion, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return []entities.Suggestion{}, nil
+	}
+	if limit <= 0 {
+		limit = defaultSuggestionLimit
+	}
+
+	candidates := map[entities.SuggestionType][]entities.Suggestion{}
+	var errs []error
+
+	procedures, _, err := s.search.SearchProcedures(ctx, repositories.ProcedureSearchParams{Query: query, Limit: limit})
+	if err != nil {
+		errs = append(errs, err)
+	}
+	for _, procedure := range procedures {
+		candidates[entities.SuggestionTypeProcedure] = append(candidates[entities.SuggestionTypeProcedure], procedureSuggestion(procedure))
+	}
+
+	conditions, specialties, err := s.search.SuggestConcepts(ctx, query, limit)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	for _, condition := range conditions {
+		candidates[entities.SuggestionTypeCondition] = append(candidates[entities.SuggestionTypeCondition], conceptSuggestion(entities.SuggestionTypeCondition, condition))
+	}
+	for _, specialty := range specialties {
+		candidates[entities.SuggestionTypeSpecialty] = append(candidates[entities.SuggestionTypeSpecialty], conceptSuggestion(entities.SuggestionTypeSpecialty, specialty))
+	}
+
+	sources := 2
+	if s.facilities != nil {
+		sources++
+		facilities, err := s.facilities.Suggest(ctx, query, lat, lon, limit)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		for _, facility := range facilities {
+			if facility != nil {
+				candidates[entities.SuggestionTypeFacility] = append(candidates[entities.SuggestionTypeFacility], facilitySuggestion(facility))
+			}
+		}
+	}
+
+	if len(errs) == sources {
+		return nil, fmt.Errorf("failed to build suggestions: %w", errs[0])
+	}
+	for _, err := range errs {
+		log.Printf("Warning: suggestion source failed for %q: %v", query, err)
+	}
+	return mergeSuggestions(candidates, limit), nil
+}
+
+// mergeSuggestions picks up to limit suggestions, giving procedures half the
+// slots and every other type an equal share, then hands unused slots out in
+// suggestionOrder
+func mergeSuggestions(candidates map[entities.SuggestionType][]entities.Suggestion, limit int) []entities.Suggestion {
+	quota := map[entities.SuggestionType]int{
+		entities.SuggestionTypeProcedure: (limit + 1) / 2,
+	}
+	others := limit - quota[entities.SuggestionTypeProcedure]
+	for i, suggestionType := range suggestionOrder[1:] {
+		share := others / (len(suggestionOrder) - 1)
+		if i < others%(len(suggestionOrder)-1) {
+			share++
+		}
+		quota[suggestionType] = share
+	}
+
+	taken := map[entities.SuggestionType]int{}
+	remaining := limit
+	for _, suggestionType := range suggestionOrder {
+		taken[suggestionType] = min(quota[suggestionType], len(candidates[suggestionType]))
+		remaining -= taken[suggestionType]
+	}
+	for _, suggestionType := range suggestionOrder {
+		extra := min(remaining, len(candidates[suggestionType])-taken[suggestionType])
+		taken[suggestionType] += extra
+		remaining -= extra
+	}
+
+	suggestions := make([]entities.Suggestion, 0, limit-remaining)
+	for _, suggestionType := range suggestionOrder {
+		suggestions = append(suggestions, candidates[suggestionType][:taken[suggestionType]]...)
+	}
+	return suggestions
+}
+
+// procedureSuggestion searches for facilities offering the procedure itself,
+// rather than for its name, which would also match unrelated services
+func procedureSuggestion(procedure *entities.ProcedureSearchResult) entities.Suggestion {
+	return entities.Suggestion{
+		Type:   entities.SuggestionTypeProcedure,
+		ID:     procedure.ID,
+		Label:  procedure.Label(),
+		Detail: procedure.Category,
+		Price:  procedure.Price,
+		Action: entities.SuggestionAction{
+			Type:   entities.SuggestionActionSearchFacilities,
+			Path:   facilitySearchPath,
+			Params: map[string]string{"procedure_id": procedure.ID},
+		},
+	}
+}
+
+func conceptSuggestion(suggestionType entities.SuggestionType, concept entities.ConceptSuggestion) entities.Suggestion {
+	detail := fmt.Sprintf("%d procedures", concept.Procedures)
+	if concept.Procedures == 1 {
+		detail = "1 procedure"
+	}
+	return entities.Suggestion{
+		Type:   suggestionType,
+		Label:  concept.Value,
+		Detail: detail,
+		Action: searchFacilitiesAction(concept.Value),
+	}
+}
+
+func facilitySuggestion(facility *entities.Facility) entities.Suggestion {
+	var detail []string
+	for _, part := range []string{facility.FacilityType, facility.Address.City} {
+		if part = strings.TrimSpace(part); part != "" {
+			detail = append(detail, part)
+		}
+	}
+	return entities.Suggestion{
+		Type:   entities.SuggestionTypeFacility,
+		ID:     facility.ID,
+		Label:  facility.Name,
+		Detail: strings.Join(detail, ", "),
+		Action: entities.SuggestionAction{
+			Type: entities.SuggestionActionViewFacility,
+			Path: "/api/facilities/" + facility.ID,
+		},
+	}
+}
+
+func searchFacilitiesAction(query string) entities.SuggestionAction {
+	return entities.SuggestionAction{
+		Type:   entities.SuggestionActionSearchFacilities,
+		Path:   facilitySearchPath,
+		Params: map[string]string{"query": query},
+	}
+}
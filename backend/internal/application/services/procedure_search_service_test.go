@@ -0,0 +1,120 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/repositories"
+)
+
+type fakeProcedureSearch struct {
+	procedures  []*entities.ProcedureSearchResult
+	conditions  []entities.ConceptSuggestion
+	specialties []entities.ConceptSuggestion
+	err         error
+}
+
+func (f *fakeProcedureSearch) SearchProcedures(ctx context.Context, params repositories.ProcedureSearchParams) ([]*entities.ProcedureSearchResult, int, error) {
+	if f.err != nil {
+		return nil, 0, f.err
+	}
+	return f.procedures, len(f.procedures), nil
+}
+
+func (f *fakeProcedureSearch) SuggestConcepts(ctx context.Context, query string, limit int) ([]entities.ConceptSuggestion, []entities.ConceptSuggestion, error) {
+	if f.err != nil {
+		return nil, nil, f.err
+	}
+	return f.conditions, f.specialties, nil
+}
+
+type fakeFacilitySuggester struct {
+	facilities []*entities.Facility
+	err        error
+}
+
+func (f *fakeFacilitySuggester) Suggest(ctx context.Context, query string, lat, lon float64, limit int) ([]*entities.Facility, error) {
+	return f.facilities, f.err
+}
+
+func suggestionTypes(suggestions []entities.Suggestion) []entities.SuggestionType {
+	types := make([]entities.SuggestionType, 0, len(suggestions))
+	for _, suggestion := range suggestions {
+		types = append(types, suggestion.Type)
+	}
+	return types
+}
+
+func TestProcedureSearch_SuggestMixesTypesWithinLimit(t *testing.T) {
+	search := &fakeProcedureSearch{
+		conditions:  []entities.ConceptSuggestion{{Value: "anaemia", Procedures: 4}, {Value: "anxiety", Procedures: 1}},
+		specialties: []entities.ConceptSuggestion{{Value: "anaesthesiology", Procedures: 2}},
+	}
+	for i := 0; i < 6; i++ {
+		search.procedures = append(search.procedures, &entities.ProcedureSearchResult{ID: fmt.Sprintf("proc-%d", i), Name: fmt.Sprintf("procedure %d", i)})
+	}
+	search.procedures[0].DisplayName = "Full Blood Count"
+	search.procedures[0].Price = &entities.ProcedurePriceStats{FacilityCount: 12, MinPrice: 3000}
+	facilities := &fakeFacilitySuggester{facilities: []*entities.Facility{
+		{ID: "fac-1", Name: "Anchor Hospital", FacilityType: "hospital", Address: entities.Address{City: "Lagos"}},
+	}}
+
+	suggestions, err := NewProcedureSearchService(search, facilities).Suggest(context.Background(), "an", 0, 0, 8)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// 4 procedures, then the conditions, facility and specialty, in type order
+	want := []entities.SuggestionType{"procedure", "procedure", "procedure", "procedure", "condition", "condition", "facility", "specialty"}
+	if got := suggestionTypes(suggestions); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+
+	first := suggestions[0]
+	if first.Label != "Full Blood Count" || first.Price == nil || first.Action.Type != entities.SuggestionActionSearchFacilities || first.Action.Params["procedure_id"] != first.ID || first.Action.Params["query"] != "" {
+		t.Fatalf("unexpected procedure suggestion %+v", first)
+	}
+	if suggestions[5].Detail != "1 procedure" {
+		t.Fatalf("expected a singular detail, got %q", suggestions[5].Detail)
+	}
+	facility := suggestions[6]
+	if facility.Detail != "hospital, Lagos" || facility.Action.Type != entities.SuggestionActionViewFacility || facility.Action.Path != "/api/facilities/fac-1" {
+		t.Fatalf("unexpected facility suggestion %+v", facility)
+	}
+}
+
+func TestProcedureSearch_SuggestFillsUnusedSlots(t *testing.T) {
+	search := &fakeProcedureSearch{}
+	for i := 0; i < 6; i++ {
+		search.procedures = append(search.procedures, &entities.ProcedureSearchResult{ID: fmt.Sprintf("proc-%d", i), Name: "ct head"})
+	}
+
+	suggestions, err := NewProcedureSearchService(search, nil).Suggest(context.Background(), "ct", 0, 0, 5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(suggestions) != 5 {
+		t.Fatalf("expected procedures to fill all 5 slots, got %d", len(suggestions))
+	}
+}
+
+func TestProcedureSearch_SuggestSurvivesAFailingSource(t *testing.T) {
+	search := &fakeProcedureSearch{err: errors.New("typesense unavailable")}
+	facilities := &fakeFacilitySuggester{facilities: []*entities.Facility{{ID: "fac-1", Name: "CT Centre"}}}
+
+	suggestions, err := NewProcedureSearchService(search, facilities).Suggest(context.Background(), "ct", 0, 0, 5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(suggestions) != 1 || suggestions[0].Type != entities.SuggestionTypeFacility {
+		t.Fatalf("expected the facility suggestion alone, got %+v", suggestions)
+	}
+
+	facilities.err = errors.New("database unavailable")
+	if _, err := NewProcedureSearchService(search, facilities).Suggest(context.Background(), "ct", 0, 0, 5); err == nil {
+		t.Fatal("expected an error when every source fails")
+	}
+}
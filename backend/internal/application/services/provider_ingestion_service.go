@@ -22,6 +22,14 @@ import (
 
 const enrichWorkerCount = 5
 
+// ingestionWriteAttempts bounds how often ingestion re-reads a facility, ward
+// or service that changed underneath it before giving up on the write
+const ingestionWriteAttempts = 3
+
+// profileLocationConfidence is the confidence given to coordinates a
+// provider lists for its own facility: usually right, but entered by hand
+const profileLocationConfidence = 0.7
+
 type ProviderIngestionSummary struct {
 	RecordsProcessed            int `json:"records_processed"`
 	FacilitiesCreated           int `json:"facilities_created"`
@@ -39,6 +47,7 @@ type ProviderIngestionService struct {
 	client                providerapi.Client
 	facilityRepo          repositories.FacilityRepository
 	facilityWardRepo      repositories.FacilityWardRepository
+	outbox                repositories.FacilityEventOutboxRepository
 	facilityService       *FacilityService
 	procedureRepo         repositories.ProcedureRepository
 	facilityProcedureRepo repositories.FacilityProcedureRepository
@@ -46,6 +55,7 @@ type ProviderIngestionService struct {
 	enrichmentProvider    providers.ProcedureEnrichmentProvider
 	geolocationProvider   providers.GeolocationProvider
 	geocodeCache          map[string]*providers.GeocodedAddress
+	geocodeRepo           repositories.GeocodeRepository
 	cacheProvider         providers.CacheProvider
 	pageSize              int
 	normalizer            *utils.ServiceNameNormalizer
@@ -96,6 +106,19 @@ func NewProviderIngestionService(
 	}
 }
 
+// SetOutbox writes ward capacity and service price changes through the
+// transactional outbox so each changed ward emits a ward capacity event and
+// each changed service a service price event
+func (s *ProviderIngestionService) SetOutbox(outbox repositories.FacilityEventOutboxRepository) {
+	s.outbox = outbox
+}
+
+// SetGeocodeRepository records the coordinates in provider facility profiles
+// as geocodes of the profile's address
+func (s *ProviderIngestionService) SetGeocodeRepository(repo repositories.GeocodeRepository) {
+	s.geocodeRepo = repo
+}
+
 func (s *ProviderIngestionService) SyncCurrentData(ctx context.Context, providerID string) (*ProviderIngestionSummary, error) {
 	if s.client == nil {
 		return nil, fmt.Errorf("provider api client not configured")
@@ -186,7 +209,7 @@ func (s *ProviderIngestionService) SyncCurrentData(ctx context.Context, provider
 				}
 				// Sync ward capacity if available
 				if len(profile.Wards) > 0 {
-					if err := s.syncWardCapacity(ctx, facilityID, profile.Wards); err != nil {
+					if err := s.syncWardCapacity(ctx, facility, profile.Wards); err != nil {
 						log.Printf("failed to sync ward capacity for facility %s: %v", facilityID, err)
 						// Continue processing other facilities even if ward sync fails
 					}
@@ -201,7 +224,7 @@ func (s *ProviderIngestionService) SyncCurrentData(ctx context.Context, provider
 				summary.ProceduresCreated++
 			}
 
-			updated, err := s.ensureFacilityProcedure(ctx, facility.ID, procedure.ID, record)
+			updated, err := s.ensureFacilityProcedure(ctx, facility, procedure.ID, record)
 			if err != nil {
 				return summary, err
 			}
@@ -241,15 +264,22 @@ func (s *ProviderIngestionService) SyncCurrentData(ctx context.Context, provider
 		for tag := range tags {
 			mergedTags = append(mergedTags, tag)
 		}
-		facility.Tags = mergedTags
-		if facility.FacilityType == "" {
-			facility.FacilityType = inferFacilityType(facility.Name, mergedTags)
+		profile := facilityProfiles[facilityID]
+		apply := func(f *entities.Facility) {
+			applyProfileStatus(f, profile)
+			f.Tags = mergedTags
+			if f.FacilityType == "" {
+				f.FacilityType = inferFacilityType(f.Name, mergedTags)
+			}
 		}
+		apply(facility)
 
 		if s.facilityService != nil {
-			if err := s.facilityService.Update(ctx, facility); err != nil {
+			saved, err := s.saveFacility(ctx, facility, apply)
+			if err != nil {
 				return summary, err
 			}
+			facilityCache[facilityID] = saved
 			if !facilityUpdated[facilityID] {
 				summary.FacilitiesUpdated++
 				facilityUpdated[facilityID] = true
@@ -270,9 +300,14 @@ func (s *ProviderIngestionService) SyncCurrentData(ctx context.Context, provider
 			}
 		}
 		if s.facilityService != nil {
-			if err := s.facilityService.Update(ctx, facility); err != nil {
+			profile := facilityProfiles[facilityID]
+			saved, err := s.saveFacility(ctx, facility, func(f *entities.Facility) {
+				applyProfileStatus(f, profile)
+			})
+			if err != nil {
 				return summary, err
 			}
+			facilityCache[facilityID] = saved
 			summary.FacilitiesUpdated++
 			facilityUpdated[facilityID] = true
 		}
@@ -296,12 +331,11 @@ func (s *ProviderIngestionService) ensureFacility(ctx context.Context, id string
 	if err == nil {
 		updated := s.ensureFacilityLocation(ctx, facility, record, profile, tags)
 		if updated {
-			if s.facilityService != nil {
-				if updateErr := s.facilityService.Update(ctx, facility); updateErr != nil {
-					return facility, false, updateErr
-				}
-			} else if updateErr := s.facilityRepo.Update(ctx, facility); updateErr != nil {
-				return facility, false, updateErr
+			facility, err = s.saveFacility(ctx, facility, func(f *entities.Facility) {
+				s.ensureFacilityLocation(ctx, f, record, profile, tags)
+			})
+			if err != nil {
+				return facility, false, err
 			}
 		}
 		return facility, false, nil
@@ -353,21 +387,18 @@ func (s *ProviderIngestionService) ensureFacility(ctx context.Context, id string
 			Latitude:  profile.Location.Latitude,
 			Longitude: profile.Location.Longitude,
 		}
+		if hasLocation(facility.Location) {
+			facility.LocationSource = string(entities.GeocodeSourceProviderProfile)
+		}
 		if len(profile.Tags) > 0 {
 			facility.Tags = profile.Tags
 		}
-		if profile.CapacityStatus != nil {
-			facility.CapacityStatus = profile.CapacityStatus
-		}
-		if profile.AvgWaitMinutes != nil {
-			facility.AvgWaitMinutes = profile.AvgWaitMinutes
-		}
-		if profile.UrgentCareAvailable != nil {
-			facility.UrgentCareAvailable = profile.UrgentCareAvailable
-		}
+		applyProfileStatus(facility, profile)
 	}
 
-	s.ensureFacilityLocation(ctx, facility, record, profile, tags)
+	if !s.ensureFacilityLocation(ctx, facility, record, profile, tags) && facility.LocationSource == string(entities.GeocodeSourceProviderProfile) {
+		s.storeProfileLocation(ctx, facility, record, profile, tags)
+	}
 
 	if s.facilityService != nil {
 		if err := s.facilityService.Create(ctx, facility); err != nil {
@@ -383,8 +414,10 @@ func (s *ProviderIngestionService) ensureFacility(ctx context.Context, id string
 	return facility, true, nil
 }
 
+// ensureFacilityLocation geocodes a facility without usable coordinates and
+// reports whether it changed them. Pinned locations are never changed.
 func (s *ProviderIngestionService) ensureFacilityLocation(ctx context.Context, facility *entities.Facility, record providerapi.PriceRecord, profile *providerapi.FacilityProfile, tags []string) bool {
-	if facility == nil || s.geolocationProvider == nil {
+	if facility == nil || facility.LocationPinned || s.geolocationProvider == nil {
 		return false
 	}
 
@@ -414,12 +447,40 @@ func (s *ProviderIngestionService) ensureFacilityLocation(ctx context.Context, f
 	return true
 }
 
+// storeProfileLocation records a profile's coordinates under the address
+// ingestion geocodes the facility by
+func (s *ProviderIngestionService) storeProfileLocation(ctx context.Context, facility *entities.Facility, record providerapi.PriceRecord, profile *providerapi.FacilityProfile, tags []string) {
+	if s.geocodeRepo == nil {
+		return
+	}
+	query := buildGeocodeQuery(facility, record, profile, tags)
+	if query == "" {
+		return
+	}
+	err := s.geocodeRepo.Save(ctx, &entities.GeocodedLocation{
+		AddressKey: entities.NormalizeGeocodeAddress(query),
+		Address:    query,
+		Street:     facility.Address.Street,
+		City:       facility.Address.City,
+		State:      facility.Address.State,
+		ZipCode:    facility.Address.ZipCode,
+		Country:    facility.Address.Country,
+		Location:   facility.Location,
+		Source:     entities.GeocodeSourceProviderProfile,
+		Confidence: profileLocationConfidence,
+	})
+	if err != nil {
+		log.Printf("Warning: Failed to store profile location of facility %s: %v", facility.ID, err)
+	}
+}
+
 func applyGeocodedAddress(facility *entities.Facility, geo *providers.GeocodedAddress) {
 	if facility == nil || geo == nil {
 		return
 	}
 	facility.Location.Latitude = geo.Coordinates.Latitude
 	facility.Location.Longitude = geo.Coordinates.Longitude
+	facility.LocationSource = string(geo.Source)
 
 	// Populate street address
 	if facility.Address.Street == "" {
@@ -710,9 +771,12 @@ func (s *ProviderIngestionService) ensureProcedure(ctx context.Context, record p
 	return procedure, true, nil
 }
 
-func (s *ProviderIngestionService) ensureFacilityProcedure(ctx context.Context, facilityID, procedureID string, record providerapi.PriceRecord) (bool, error) {
+func (s *ProviderIngestionService) ensureFacilityProcedure(ctx context.Context, facility *entities.Facility, procedureID string, record providerapi.PriceRecord) (bool, error) {
+	facilityID := facility.ID
 	existing, err := s.facilityProcedureRepo.GetByFacilityAndProcedure(ctx, facilityID, procedureID)
-	if err == nil && existing != nil {
+	for attempt := 1; err == nil && existing != nil; attempt++ {
+		before := *existing
+
 		// Price Aggregation Strategy: Average prices from multiple providers
 		// When a facility-procedure already exists (from another provider),
 		// calculate the average price between the existing price and the new price
@@ -725,10 +789,25 @@ func (s *ProviderIngestionService) ensureFacilityProcedure(ctx context.Context,
 			existing.EstimatedDuration = *record.EstimatedDurationMin
 		}
 		existing.UpdatedAt = time.Now()
-		if updateErr := s.facilityProcedureRepo.Update(ctx, existing); updateErr != nil {
+
+		var updateErr error
+		if s.outbox != nil {
+			var events []*entities.FacilityEvent
+			if event := servicePriceEvent(facility.Location, &before, existing); event != nil {
+				events = append(events, event)
+			}
+			updateErr = s.outbox.UpdateFacilityProcedure(ctx, existing, events)
+		} else {
+			updateErr = s.facilityProcedureRepo.Update(ctx, existing)
+		}
+		if updateErr == nil {
+			return true, nil
+		}
+		if !isConflict(updateErr) || attempt == ingestionWriteAttempts {
 			return false, updateErr
 		}
-		return true, nil
+		// Changed since it was read; average against the current row instead
+		existing, err = s.facilityProcedureRepo.GetByFacilityAndProcedure(ctx, facilityID, procedureID)
 	}
 
 	if err != nil && !isNotFound(err) {
@@ -753,6 +832,14 @@ func (s *ProviderIngestionService) ensureFacilityProcedure(ctx context.Context,
 		UpdatedAt:   now,
 	}
 
+	if s.outbox != nil {
+		events := []*entities.FacilityEvent{servicePriceEvent(facility.Location, nil, fp)}
+		if err := s.outbox.CreateFacilityProcedure(ctx, fp, events); err != nil {
+			return false, err
+		}
+		return false, nil
+	}
+
 	if err := s.facilityProcedureRepo.Create(ctx, fp); err != nil {
 		return false, err
 	}
@@ -760,6 +847,25 @@ func (s *ProviderIngestionService) ensureFacilityProcedure(ctx context.Context,
 	return false, nil
 }
 
+// servicePriceEvent describes a new service or a change to a service's price
+// or availability, or returns nil when neither changed. Search documents carry
+// the cheapest available price, so the indexer reindexes on these events.
+func servicePriceEvent(location entities.Location, old, new *entities.FacilityProcedure) *entities.FacilityEvent {
+	if old != nil && old.Price == new.Price && old.Currency == new.Currency && old.IsAvailable == new.IsAvailable {
+		return nil
+	}
+	changed := map[string]interface{}{
+		"procedure_id": new.ProcedureID,
+		"price":        new.Price,
+		"currency":     new.Currency,
+		"is_available": new.IsAvailable,
+	}
+	if old != nil {
+		changed["previous_price"] = old.Price
+	}
+	return entities.NewFacilityEvent(new.FacilityID, entities.FacilityEventTypeServicePriceUpdate, location, changed)
+}
+
 func isNotFound(err error) bool {
 	var appErr *apperrors.AppError
 	if errors.As(err, &appErr) {
@@ -768,6 +874,14 @@ func isNotFound(err error) bool {
 	return false
 }
 
+func isConflict(err error) bool {
+	var appErr *apperrors.AppError
+	if errors.As(err, &appErr) {
+		return appErr.Type == apperrors.ErrorTypeConflict
+	}
+	return false
+}
+
 // calculateAveragePrice computes the average of two prices
 // Used for price aggregation strategy when multiple providers report prices for same facility-procedure
 func calculateAveragePrice(existingPrice, newPrice float64) float64 {
@@ -944,23 +1058,23 @@ func applyProfileStatus(facility *entities.Facility, profile *providerapi.Facili
 	}
 	changed := false
 
+	// Every value the provider sends is a fresh report, even when unchanged,
+	// so it restarts the value's freshness window
+	now := time.Now()
 	if profile.CapacityStatus != nil {
-		if facility.CapacityStatus == nil || *facility.CapacityStatus != *profile.CapacityStatus {
-			facility.CapacityStatus = profile.CapacityStatus
-			changed = true
-		}
+		facility.CapacityStatus = profile.CapacityStatus
+		facility.MarkCapacityReported(entities.CapacityFieldStatus, now)
+		changed = true
 	}
 	if profile.AvgWaitMinutes != nil {
-		if facility.AvgWaitMinutes == nil || *facility.AvgWaitMinutes != *profile.AvgWaitMinutes {
-			facility.AvgWaitMinutes = profile.AvgWaitMinutes
-			changed = true
-		}
+		facility.AvgWaitMinutes = profile.AvgWaitMinutes
+		facility.MarkCapacityReported(entities.CapacityFieldWaitTime, now)
+		changed = true
 	}
 	if profile.UrgentCareAvailable != nil {
-		if facility.UrgentCareAvailable == nil || *facility.UrgentCareAvailable != *profile.UrgentCareAvailable {
-			facility.UrgentCareAvailable = profile.UrgentCareAvailable
-			changed = true
-		}
+		facility.UrgentCareAvailable = profile.UrgentCareAvailable
+		facility.MarkCapacityReported(entities.CapacityFieldUrgentCare, now)
+		changed = true
 	}
 	if profile.WardStatuses != nil {
 		facility.WardStatuses = profile.WardStatuses
@@ -971,11 +1085,23 @@ func applyProfileStatus(facility *entities.Facility, profile *providerapi.Facili
 }
 
 // syncWardCapacity syncs ward capacity data from MongoDB (via Provider API) to PostgreSQL
-func (s *ProviderIngestionService) syncWardCapacity(ctx context.Context, facilityID string, wards []providerapi.WardCapacity) error {
+func (s *ProviderIngestionService) syncWardCapacity(ctx context.Context, facility *entities.Facility, wards []providerapi.WardCapacity) error {
 	if s.facilityWardRepo == nil {
 		// Ward repository not configured, skip ward sync
 		return nil
 	}
+	facilityID := facility.ID
+
+	// Compare against stored wards so writes carry the version they replace
+	// and only new or changed wards emit events
+	stored, err := s.facilityWardRepo.GetByFacilityID(ctx, facilityID)
+	if err != nil {
+		return fmt.Errorf("failed to load wards for facility %s: %w", facilityID, err)
+	}
+	existing := make(map[string]*entities.FacilityWard, len(stored))
+	for _, ward := range stored {
+		existing[ward.WardName] = ward
+	}
 
 	for _, ward := range wards {
 		// Generate ward ID using hash of full facilityID + normalized ward name
@@ -1009,8 +1135,7 @@ func (s *ProviderIngestionService) syncWardCapacity(ctx context.Context, facilit
 			CreatedAt:           time.Now(), // Will be preserved by Upsert if already exists
 		}
 
-		// Use Upsert to create or update the ward
-		if err := s.facilityWardRepo.Upsert(ctx, facilityWard); err != nil {
+		if err := s.upsertWard(ctx, facility, facilityWard, existing[ward.WardName]); err != nil {
 			return fmt.Errorf("failed to upsert ward %s for facility %s: %w", ward.WardName, facilityID, err)
 		}
 	}
@@ -1018,6 +1143,73 @@ func (s *ProviderIngestionService) syncWardCapacity(ctx context.Context, facilit
 	return nil
 }
 
+// upsertWard writes a provider ward reading over the stored ward it was
+// compared against. If the ward changed in the meantime the reading is
+// compared against the current ward instead, and dropped when that ward was
+// updated after the reading was taken.
+func (s *ProviderIngestionService) upsertWard(ctx context.Context, facility *entities.Facility, ward, stored *entities.FacilityWard) error {
+	for attempt := 1; ; attempt++ {
+		ward.Version = 0
+		if stored != nil {
+			ward.Version = stored.Version
+		}
+
+		var err error
+		if s.outbox != nil {
+			var events []*entities.FacilityEvent
+			if event := wardCapacityEvent(facility.Location, stored, ward); event != nil {
+				events = append(events, event)
+			}
+			err = s.outbox.UpsertWard(ctx, ward, events)
+		} else {
+			err = s.facilityWardRepo.Upsert(ctx, ward)
+		}
+		if err == nil {
+			if s.facilityService != nil {
+				s.facilityService.capacityHistory.RecordWard(ctx, stored, ward)
+			}
+			return nil
+		}
+		if !isConflict(err) || attempt == ingestionWriteAttempts {
+			return err
+		}
+
+		current, err := s.facilityWardRepo.GetByFacilityAndWard(ctx, ward.FacilityID, ward.WardName)
+		if err != nil && !isNotFound(err) {
+			return err
+		}
+		if current != nil && current.LastUpdated.After(ward.LastUpdated) {
+			return nil
+		}
+		stored = current
+	}
+}
+
+// saveFacility writes a facility changed by apply. Operators edit facilities
+// while ingestion runs, so on a version conflict the facility is re-read and
+// apply is re-run against the current row rather than overwriting it. It
+// returns the facility as written.
+func (s *ProviderIngestionService) saveFacility(ctx context.Context, facility *entities.Facility, apply func(*entities.Facility)) (*entities.Facility, error) {
+	for attempt := 1; ; attempt++ {
+		var err error
+		if s.facilityService != nil {
+			err = s.facilityService.Update(ctx, facility)
+		} else {
+			err = s.facilityRepo.Update(ctx, facility)
+		}
+		if err == nil || !isConflict(err) || attempt == ingestionWriteAttempts {
+			return facility, err
+		}
+
+		current, err := s.facilityRepo.GetByID(ctx, facility.ID)
+		if err != nil {
+			return facility, err
+		}
+		apply(current)
+		facility = current
+	}
+}
+
 // enrichProceduresBatch enriches all procedures that don't have enrichment data yet.
 // This runs after ingestion to populate enrichment data once for all procedures.
 // Uses a worker pool for concurrent OpenAI calls.
@@ -1,10 +1,12 @@
 package services
 
 import (
+	"context"
 	"testing"
 
 	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
 	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/providers"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/infrastructure/clients/providerapi"
 )
 
 // TestCalculateAveragePrice tests the price averaging logic
@@ -421,3 +423,61 @@ func TestApplyGeocodedAddress_NilInputs(t *testing.T) {
 		applyGeocodedAddress(facility, nil)
 	})
 }
+
+func TestEnsureFacilityLocation_NeverMovesPinnedLocations(t *testing.T) {
+	geocoder := &stubPlaceGeocoder{places: map[string]*providers.GeocodedAddress{
+		"Ishaga Road, Surulere, Lagos, Nigeria": {
+			City: "Surulere", State: "Lagos", Country: "Nigeria",
+			Coordinates: providers.Coordinates{Latitude: 6.5, Longitude: 3.35},
+			Source:      entities.GeocodeSourceGoogle,
+			Confidence:  0.8,
+		},
+	}}
+	svc := &ProviderIngestionService{geolocationProvider: geocoder, geocodeCache: map[string]*providers.GeocodedAddress{}}
+	address := entities.Address{Street: "Ishaga Road", City: "Surulere", State: "Lagos", Country: "Nigeria"}
+	mockLocation := entities.Location{Latitude: 37.7749, Longitude: -122.4194}
+
+	pinned := &entities.Facility{ID: "fac_1", Address: address, Location: mockLocation, LocationSource: "manual", LocationPinned: true}
+	if svc.ensureFacilityLocation(context.Background(), pinned, providerapi.PriceRecord{}, nil, nil) {
+		t.Fatalf("expected a pinned location to be left alone")
+	}
+	if pinned.Location != mockLocation || pinned.LocationSource != "manual" || geocoder.calls != 0 {
+		t.Fatalf("expected no geocoding for a pinned facility, got %+v (%d calls)", pinned.Location, geocoder.calls)
+	}
+
+	unpinned := &entities.Facility{ID: "fac_2", Address: address, Location: mockLocation}
+	if !svc.ensureFacilityLocation(context.Background(), unpinned, providerapi.PriceRecord{}, nil, nil) {
+		t.Fatalf("expected the placeholder location to be replaced")
+	}
+	if unpinned.Location.Latitude != 6.5 || unpinned.LocationSource != string(entities.GeocodeSourceGoogle) {
+		t.Fatalf("expected the geocoded location and its source, got %+v from %q", unpinned.Location, unpinned.LocationSource)
+	}
+}
+
+func TestServicePriceEvent(t *testing.T) {
+	location := entities.Location{Latitude: 6.5, Longitude: 3.4}
+	stored := &entities.FacilityProcedure{FacilityID: "fac-1", ProcedureID: "proc-1", Price: 5000, Currency: "NGN", IsAvailable: true}
+
+	unchanged := *stored
+	if event := servicePriceEvent(location, stored, &unchanged); event != nil {
+		t.Fatalf("expected no event when nothing changed, got %+v", event)
+	}
+
+	repriced := *stored
+	repriced.Price = 6000
+	event := servicePriceEvent(location, stored, &repriced)
+	if event == nil || event.EventType != entities.FacilityEventTypeServicePriceUpdate || event.FacilityID != "fac-1" {
+		t.Fatalf("expected a service price event, got %+v", event)
+	}
+	if event.ChangedFields["price"] != 6000.0 || event.ChangedFields["previous_price"] != 5000.0 {
+		t.Fatalf("unexpected changed fields %v", event.ChangedFields)
+	}
+
+	created := servicePriceEvent(location, nil, stored)
+	if created == nil {
+		t.Fatal("expected an event for a new service")
+	}
+	if _, ok := created.ChangedFields["previous_price"]; ok {
+		t.Fatal("a new service has no previous price")
+	}
+}
@@ -0,0 +1,657 @@
+package services
+
+import (
+	"context"
+	"log"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/providers"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/repositories"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/evaluation"
+)
+
+// QueryConstraintType names the search filter a query constraint sets
+type QueryConstraintType string
+
+const (
+	ConstraintMinPrice     QueryConstraintType = "min_price"
+	ConstraintMaxPrice     QueryConstraintType = "max_price"
+	ConstraintLocality     QueryConstraintType = "locality"
+	ConstraintFacilityType QueryConstraintType = "facility_type"
+	ConstraintInsurance    QueryConstraintType = "insurance"
+	ConstraintOpenNow      QueryConstraintType = "open_now"
+	ConstraintOpen24Hours  QueryConstraintType = "open_24_hours"
+	ConstraintSort         QueryConstraintType = "sort"
+)
+
+// QueryConstraint is a filter read from the words of a query, such as a
+// price bound or a locality. Interpretations report them so the UI can show
+// each as a removable chip; searching again with its type in
+// ignore_constraints drops it.
+type QueryConstraint struct {
+	Type QueryConstraintType `json:"type"`
+	// Text is the part of the query the constraint was read from
+	Text string `json:"text"`
+	// Value is the normalized value: an amount, facility type, insurer,
+	// locality or sort order
+	Value string `json:"value"`
+	Label string `json:"label"`
+	// Note explains a constraint that is only approximated
+	Note string `json:"note,omitempty"`
+
+	Amount        *float64                 `json:"amount,omitempty"`
+	FacilityTypes []string                 `json:"facility_types,omitempty"`
+	Area          *repositories.SearchArea `json:"area,omitempty"`
+
+	// Applied is false when an explicit search parameter took precedence
+	// or the user removed the constraint
+	Applied bool `json:"applied"`
+}
+
+const (
+	// minBareAmount is the smallest number read as a price without a currency
+	// or k/m suffix, so "children under 5" is not a price bound
+	minBareAmount = 1000
+
+	// insuranceRefreshInterval is how long the insurer names matched in
+	// queries are kept before they are read again
+	insuranceRefreshInterval = 10 * time.Minute
+
+	// Bounds on the radius covering a gazetteer place's box
+	minLocalityRadiusKm = 2.0
+	maxLocalityRadiusKm = 200.0
+
+	// maxLocalityWords is the longest place name looked up, as in "lekki
+	// phase 1"
+	maxLocalityWords = 3
+
+	// minBareLocalityLength is the shortest place name read without a cue
+	// word, so "vi" or "ado" alone are not taken for places
+	minBareLocalityLength = 4
+)
+
+// amountPattern matches a naira amount: "50k", "₦50,000", "n1.5m", "20000 naira"
+const amountPattern = `(₦\s*|\bngn\s*|\bn)?(\d[\d,]*(?:\.\d+)?)\s*(k|m|thousand|million)?\b(\s*(?:naira|ngn)\b)?`
+
+var (
+	priceRangePattern = regexp.MustCompile(`\b(between\s+)?` + amountPattern + `\s*(?:-|–|to|and)\s*` + amountPattern)
+	maxPricePattern   = regexp.MustCompile(`(?:\b(?:under|below|less than|cheaper than|not more than|no more than|at most|max|maximum|up to|within|budget of|budget)\s+|<\s*)` + amountPattern)
+	minPricePattern   = regexp.MustCompile(`(?:\b(?:over|above|more than|at least|min|minimum|from)\s+|>\s*)` + amountPattern)
+	maxSuffixPattern  = regexp.MustCompile(amountPattern + `\s+(?:or less|and below|or below|or cheaper|max)\b`)
+	minSuffixPattern  = regexp.MustCompile(amountPattern + `\s+(?:or more|and above|or above|plus)\b`)
+)
+
+// constraintPhrase maps a phrase to the constraint it states
+type constraintPhrase struct {
+	words         []string
+	kind          QueryConstraintType
+	value         string
+	label         string
+	facilityTypes []string
+}
+
+var constraintPhrases = buildConstraintPhrases()
+
+// constraintNotes explain the opening hours constraints, which are
+// approximated until facilities record their opening hours
+var constraintNotes = map[QueryConstraintType]string{
+	ConstraintOpenNow:     "Opening hours are not known yet, so this only hides facilities reporting themselves closed",
+	ConstraintOpen24Hours: "Opening hours are not known yet, so this keeps urgent care and facilities named as open 24 hours",
+}
+
+func buildConstraintPhrases() []constraintPhrase {
+	var phrases []constraintPhrase
+	add := func(kind QueryConstraintType, value, label string, facilityTypes []string, texts ...string) {
+		for _, text := range texts {
+			phrases = append(phrases, constraintPhrase{
+				words: strings.Fields(text), kind: kind, value: value, label: label, facilityTypes: facilityTypes,
+			})
+		}
+	}
+
+	add(ConstraintOpenNow, "true", "Open now", nil,
+		"open now", "opened now", "open right now", "currently open", "open today", "still open")
+	add(ConstraintOpen24Hours, "true", "Open 24 hours", nil,
+		"open 24 hours", "open 24 hour", "open 24/7", "open 24hrs", "24 hours", "24 hour", "24 hrs", "24 hr",
+		"24hrs", "24hr", "24h", "24/7", "24-hour", "24-hours", "all night", "round the clock", "around the clock", "overnight")
+
+	add(ConstraintSort, repositories.SearchSortPrice, "Lowest price", nil,
+		"cheapest", "cheap", "cheaper", "affordable", "most affordable", "inexpensive", "least expensive",
+		"lowest price", "lowest cost", "low cost")
+	add(ConstraintSort, repositories.SearchSortRating, "Highest rated", nil,
+		"best", "best rated", "top rated", "highest rated", "highly rated", "best reviewed")
+	add(ConstraintSort, repositories.SearchSortDistance, "Nearest", nil,
+		"nearest", "closest", "nearest to me", "closest to me")
+	add(ConstraintSort, repositories.SearchSortTravelTime, "Quickest to reach", nil,
+		"quickest to reach", "fastest to reach", "quickest to get to", "shortest drive", "shortest trip")
+
+	add(ConstraintFacilityType, "pharmacy", "Pharmacies", []string{"pharmacy"},
+		"pharmacy", "pharmacies", "chemist", "chemists", "drug store", "drug stores", "drugstore")
+	add(ConstraintFacilityType, "hospital", "Hospitals", []string{"hospital"},
+		"hospital", "hospitals")
+	// Specialty clinics are mostly hospital outpatient departments, so an
+	// "orthopaedic clinic" may well be a hospital
+	add(ConstraintFacilityType, "clinic", "Clinics", []string{"clinic", "specialty_clinic", "hospital"},
+		"clinic", "clinics")
+	add(ConstraintFacilityType, "diagnostic_lab", "Labs", []string{"diagnostic_lab"},
+		"lab", "labs", "laboratory", "laboratories", "diagnostic lab", "diagnostic labs")
+	add(ConstraintFacilityType, "diagnostic_centre", "Diagnostic centres", []string{"diagnostic_lab", "imaging_center"},
+		"diagnostic centre", "diagnostic center", "diagnostic centres", "diagnostic centers")
+	add(ConstraintFacilityType, "imaging_center", "Imaging centres", []string{"imaging_center"},
+		"imaging centre", "imaging center", "radiology centre", "radiology center", "scan centre", "scan center")
+	add(ConstraintFacilityType, "urgent_care", "Urgent care", []string{"urgent_care"},
+		"urgent care", "emergency room")
+
+	// Longest phrases first, so "open 24 hours" wins over "24 hours"
+	sort.SliceStable(phrases, func(i, j int) bool {
+		return len(phrases[i].words) > len(phrases[j].words)
+	})
+	return phrases
+}
+
+// Words around an insurer's name that only say the facility accepts it
+var (
+	insuranceLeadWords  = map[string]struct{}{"that": {}, "which": {}, "takes": {}, "take": {}, "accepts": {}, "accept": {}, "accepting": {}, "covered": {}, "by": {}, "with": {}, "on": {}, "using": {}}
+	insuranceTrailWords = map[string]struct{}{"hmo": {}, "insurance": {}, "cover": {}, "plan": {}}
+	// insurerSuffixes are dropped from insurer names to get the name people type
+	insurerSuffixes = map[string]struct{}{"hmo": {}, "health": {}, "healthcare": {}, "insurance": {}, "limited": {}, "ltd": {}, "plc": {}, "nigeria": {}, "nig": {}, "scheme": {}, "services": {}, "company": {}, "co": {}}
+)
+
+// localityCues precede a place name: "scan in ikeja"
+var localityCues = map[string]struct{}{"in": {}, "at": {}, "around": {}, "near": {}}
+
+// insurerAlias is a way of writing an insurer's name in a query
+type insurerAlias struct {
+	words []string
+	name  string
+}
+
+// constraintResolvers hold the lookups constraint extraction needs beyond
+// the query text
+type constraintResolvers struct {
+	mu               sync.Mutex
+	insurance        repositories.InsuranceRepository
+	insurers         []insurerAlias
+	insurersLoadedAt time.Time
+	gazetteer        providers.PlaceGazetteer
+}
+
+// SetInsuranceRepository lets interpretations recognise insurer names
+func (s *QueryUnderstandingService) SetInsuranceRepository(repo repositories.InsuranceRepository) {
+	s.resolvers.mu.Lock()
+	defer s.resolvers.mu.Unlock()
+	s.resolvers.insurance = repo
+	s.resolvers.insurers = nil
+	s.resolvers.insurersLoadedAt = time.Time{}
+}
+
+// SetGazetteer lets interpretations resolve place names to an area to
+// search, after a cue word as in "scan in ikeja" or without one as in
+// "pharmacy surulere". Place names are only resolved from the gazetteer: a
+// network geocode would hold up every search naming something after "in" or
+// "near", and loose matches of phrases such as "in pain" would filter the
+// results to the wrong place.
+func (s *QueryUnderstandingService) SetGazetteer(gazetteer providers.PlaceGazetteer) {
+	s.resolvers.mu.Lock()
+	defer s.resolvers.mu.Unlock()
+	s.resolvers.gazetteer = gazetteer
+}
+
+// InterpretSearch interprets a search query after reading its constraints:
+// price bounds, place, facility type, insurer, opening hours and sort order.
+// The remaining words are interpreted as Interpret does and reported as the
+// keyword query.
+func (s *QueryUnderstandingService) InterpretSearch(ctx context.Context, query string) *QueryInterpretation {
+	keywords, constraints := s.extractConstraints(ctx, query)
+	if len(constraints) == 0 {
+		return s.Interpret(query)
+	}
+
+	result := s.Interpret(keywords)
+	result.OriginalQuery = query
+	result.KeywordQuery = keywords
+	result.Constraints = constraints
+	if keywords == "" {
+		// Only constraints were given, e.g. "24 hour pharmacy in lekki"
+		result.DetectedIntent = evaluation.IntentFacility
+		result.IntentConfidence = 0.6
+	}
+	return result
+}
+
+// extractConstraints reads constraints from the query and returns the
+// words left for keyword search
+func (s *QueryUnderstandingService) extractConstraints(ctx context.Context, query string) (string, []QueryConstraint) {
+	text := strings.ToLower(strings.Join(strings.Fields(query), " "))
+	if text == "" {
+		return "", nil
+	}
+
+	text, constraints := extractPriceConstraints(text)
+
+	words := strings.Fields(normalizeQueryText(text))
+	consumed := make([]bool, len(words))
+	constraints = append(constraints, matchConstraintPhrases(words, consumed)...)
+	constraints = append(constraints, s.matchInsurers(ctx, words, consumed)...)
+	constraints = append(constraints, s.matchLocality(words, consumed)...)
+
+	remaining := make([]string, 0, len(words))
+	for i, word := range words {
+		if !consumed[i] {
+			remaining = append(remaining, word)
+		}
+	}
+	return strings.Join(remaining, " "), constraints
+}
+
+// extractPriceConstraints reads price bounds and blanks them out of text
+func extractPriceConstraints(text string) (string, []QueryConstraint) {
+	var constraints []QueryConstraint
+	blank := func(start, end int) {
+		text = text[:start] + strings.Repeat(" ", end-start) + text[end:]
+	}
+
+	for _, m := range priceRangePattern.FindAllStringSubmatchIndex(text, -1) {
+		low, lowMarked := parseAmountMatch(text, m[4:12])
+		high, highMarked := parseAmountMatch(text, m[12:20])
+		between := m[2] >= 0
+		if !between && !lowMarked && !highMarked {
+			continue
+		}
+		if (!lowMarked && !between && low < minBareAmount) || high < low || high <= 0 {
+			continue
+		}
+		matched := strings.TrimSpace(text[m[0]:m[1]])
+		constraints = append(constraints,
+			priceConstraint(ConstraintMinPrice, matched, low),
+			priceConstraint(ConstraintMaxPrice, matched, high))
+		blank(m[0], m[1])
+	}
+
+	for _, bound := range []struct {
+		pattern *regexp.Regexp
+		kind    QueryConstraintType
+		offset  int
+	}{
+		{maxPricePattern, ConstraintMaxPrice, 2},
+		{minPricePattern, ConstraintMinPrice, 2},
+		{maxSuffixPattern, ConstraintMaxPrice, 2},
+		{minSuffixPattern, ConstraintMinPrice, 2},
+	} {
+		for _, m := range bound.pattern.FindAllStringSubmatchIndex(text, -1) {
+			amount, marked := parseAmountMatch(text, m[bound.offset:bound.offset+8])
+			if amount <= 0 || (!marked && amount < minBareAmount) {
+				continue
+			}
+			constraints = append(constraints, priceConstraint(bound.kind, strings.TrimSpace(text[m[0]:m[1]]), amount))
+			blank(m[0], m[1])
+		}
+	}
+	return text, constraints
+}
+
+// parseAmountMatch parses the groups of one amountPattern match: currency
+// prefix, number, multiplier and currency suffix. marked reports whether
+// the amount carried a currency or multiplier.
+func parseAmountMatch(text string, groups []int) (amount float64, marked bool) {
+	group := func(i int) string {
+		if groups[2*i] < 0 {
+			return ""
+		}
+		return strings.TrimSpace(text[groups[2*i]:groups[2*i+1]])
+	}
+	amount, err := strconv.ParseFloat(strings.ReplaceAll(group(1), ",", ""), 64)
+	if err != nil {
+		return 0, false
+	}
+	switch group(2) {
+	case "k", "thousand":
+		amount *= 1000
+	case "m", "million":
+		amount *= 1000000
+	}
+	return amount, group(0) != "" || group(2) != "" || group(3) != ""
+}
+
+func priceConstraint(kind QueryConstraintType, text string, amount float64) QueryConstraint {
+	label := "Under " + formatNaira(amount)
+	if kind == ConstraintMinPrice {
+		label = "Over " + formatNaira(amount)
+	}
+	return QueryConstraint{
+		Type:   kind,
+		Text:   text,
+		Value:  strconv.FormatFloat(amount, 'f', -1, 64),
+		Label:  label,
+		Amount: &amount,
+	}
+}
+
+// formatNaira writes a whole naira amount with thousands separators
+func formatNaira(amount float64) string {
+	digits := strconv.FormatInt(int64(amount+0.5), 10)
+	var b strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(d)
+	}
+	return "₦" + b.String()
+}
+
+// matchConstraintPhrases reads opening hours, sort order and facility type
+// phrases. Each constraint type is read once.
+func matchConstraintPhrases(words []string, consumed []bool) []QueryConstraint {
+	var constraints []QueryConstraint
+	seen := map[QueryConstraintType]bool{}
+	for _, phrase := range constraintPhrases {
+		if seen[phrase.kind] {
+			continue
+		}
+		start := findPhrase(words, consumed, phrase.words)
+		if start < 0 {
+			continue
+		}
+		for i := range phrase.words {
+			consumed[start+i] = true
+		}
+		seen[phrase.kind] = true
+		constraints = append(constraints, QueryConstraint{
+			Type:          phrase.kind,
+			Text:          strings.Join(phrase.words, " "),
+			Value:         phrase.value,
+			Label:         phrase.label,
+			Note:          constraintNotes[phrase.kind],
+			FacilityTypes: phrase.facilityTypes,
+		})
+	}
+	return constraints
+}
+
+// findPhrase returns where phrase starts among the unconsumed words, or -1
+func findPhrase(words []string, consumed []bool, phrase []string) int {
+	for start := 0; start+len(phrase) <= len(words); start++ {
+		match := true
+		for i, word := range phrase {
+			if consumed[start+i] || words[start+i] != word {
+				match = false
+				break
+			}
+		}
+		if match {
+			return start
+		}
+	}
+	return -1
+}
+
+// matchInsurers reads the first insurer named in the query, with the words
+// around it that only say it is accepted
+func (s *QueryUnderstandingService) matchInsurers(ctx context.Context, words []string, consumed []bool) []QueryConstraint {
+	for _, alias := range s.insurerAliases(ctx) {
+		start := findPhrase(words, consumed, alias.words)
+		if start < 0 {
+			continue
+		}
+		end := start + len(alias.words)
+		for start > 0 && !consumed[start-1] && isInsuranceLeadWord(words[start-1]) {
+			start--
+		}
+		for end < len(words) && !consumed[end] && isInsuranceTrailWord(words[end]) {
+			end++
+		}
+		for i := start; i < end; i++ {
+			consumed[i] = true
+		}
+		return []QueryConstraint{{
+			Type:  ConstraintInsurance,
+			Text:  strings.Join(words[start:end], " "),
+			Value: alias.name,
+			Label: "Accepts " + alias.name,
+		}}
+	}
+	return nil
+}
+
+func isInsuranceLeadWord(word string) bool {
+	_, ok := insuranceLeadWords[word]
+	return ok
+}
+
+func isInsuranceTrailWord(word string) bool {
+	_, ok := insuranceTrailWords[word]
+	return ok
+}
+
+// insurerAliases returns the ways of writing each active insurer's name,
+// longest first, reading insurers again when the list is stale
+func (s *QueryUnderstandingService) insurerAliases(ctx context.Context) []insurerAlias {
+	r := &s.resolvers
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.insurance == nil {
+		return nil
+	}
+	if !r.insurersLoadedAt.IsZero() && time.Since(r.insurersLoadedAt) < insuranceRefreshInterval {
+		return r.insurers
+	}
+
+	active := true
+	insurers, err := r.insurance.List(ctx, repositories.InsuranceFilter{IsActive: &active})
+	r.insurersLoadedAt = time.Now()
+	if err != nil {
+		log.Printf("Warning: failed to load insurers for query understanding: %v", err)
+		return r.insurers
+	}
+
+	var aliases []insurerAlias
+	for _, insurer := range insurers {
+		if insurer == nil || insurer.Name == "" {
+			continue
+		}
+		seen := map[string]struct{}{}
+		for _, alias := range insurerNameAliases(insurer) {
+			if _, dup := seen[alias]; dup {
+				continue
+			}
+			seen[alias] = struct{}{}
+			aliases = append(aliases, insurerAlias{words: strings.Fields(alias), name: insurer.Name})
+		}
+	}
+	sort.SliceStable(aliases, func(i, j int) bool {
+		return len(aliases[i].words) > len(aliases[j].words)
+	})
+	r.insurers = aliases
+	return aliases
+}
+
+// insurerNameAliases returns an insurer's full name, the name without
+// generic endings ("Hygeia HMO" → "hygeia") and its code
+func insurerNameAliases(insurer *entities.InsuranceProvider) []string {
+	var aliases []string
+	words := strings.Fields(normalizeQueryText(insurer.Name))
+	if len(words) == 0 {
+		return nil
+	}
+	aliases = append(aliases, strings.Join(words, " "))
+
+	short := words
+	for len(short) > 1 {
+		if _, generic := insurerSuffixes[short[len(short)-1]]; !generic {
+			break
+		}
+		short = short[:len(short)-1]
+	}
+	if alias := strings.Join(short, " "); len(alias) >= 4 {
+		if _, generic := insurerSuffixes[alias]; !generic {
+			aliases = append(aliases, alias)
+		}
+	}
+
+	if code := normalizeQueryText(insurer.Code); len(code) >= 3 && !strings.ContainsAny(code, "0123456789 ") {
+		aliases = append(aliases, code)
+	}
+	return aliases
+}
+
+// matchLocality resolves a gazetteer place the query names to an area to
+// search: the words after a cue, as in "scan in ikeja" or "clinic around
+// wuse 2", or a longer name without a cue, as in "mri surulere"
+func (s *QueryUnderstandingService) matchLocality(words []string, consumed []bool) []QueryConstraint {
+	s.resolvers.mu.Lock()
+	gazetteer := s.resolvers.gazetteer
+	s.resolvers.mu.Unlock()
+	if gazetteer == nil {
+		return nil
+	}
+
+	for cue := 0; cue < len(words)-1; cue++ {
+		if consumed[cue] {
+			continue
+		}
+		if _, ok := localityCues[words[cue]]; !ok || words[cue+1] == "me" {
+			continue
+		}
+
+		end := cue + 1
+		for end < len(words) && end-cue <= maxLocalityWords && !consumed[end] {
+			end++
+		}
+		// Prefer the longest place name: "victoria island" over "victoria"
+		for stop := end; stop > cue+1; stop-- {
+			name := strings.Join(words[cue+1:stop], " ")
+			if s.HasConcept(name) {
+				continue
+			}
+			if place, ok := gazetteer.LookupPlace(name); ok {
+				return []QueryConstraint{localityConstraint(words, consumed, cue, stop, gazetteerArea(place))}
+			}
+		}
+	}
+
+	for size := maxLocalityWords; size >= 1; size-- {
+		for start := 0; start+size <= len(words); start++ {
+			name := strings.Join(words[start:start+size], " ")
+			if len(name) < minBareLocalityLength || anyConsumed(consumed[start:start+size]) ||
+				s.HasConcept(name) || s.hasLocalLanguageWord(words[start:start+size]) {
+				continue
+			}
+			if place, ok := gazetteer.LookupPlace(name); ok {
+				return []QueryConstraint{localityConstraint(words, consumed, start, start+size, gazetteerArea(place))}
+			}
+		}
+	}
+	return nil
+}
+
+// localityConstraint consumes words[start:stop] as naming area
+func localityConstraint(words []string, consumed []bool, start, stop int, area *repositories.SearchArea) QueryConstraint {
+	for i := start; i < stop; i++ {
+		consumed[i] = true
+	}
+	return QueryConstraint{
+		Type:  ConstraintLocality,
+		Text:  strings.Join(words[start:stop], " "),
+		Value: area.Name,
+		Label: "In " + area.Name,
+		Area:  area,
+	}
+}
+
+func anyConsumed(consumed []bool) bool {
+	for _, c := range consumed {
+		if c {
+			return true
+		}
+	}
+	return false
+}
+
+// gazetteerArea covers a gazetteer place's bounding box with a circle
+func gazetteerArea(place *providers.GazetteerPlace) *repositories.SearchArea {
+	b := place.Bounds
+	radius := haversineKm(b.MinLatitude, b.MinLongitude, b.MaxLatitude, b.MaxLongitude) / 2
+	radius = math.Max(minLocalityRadiusKm, math.Min(radius, maxLocalityRadiusKm))
+	return &repositories.SearchArea{
+		Name:      place.Name,
+		Latitude:  place.Centroid.Latitude,
+		Longitude: place.Centroid.Longitude,
+		RadiusKm:  math.Round(radius*10) / 10,
+	}
+}
+
+// capitalizeWords upper-cases the first letter of each word
+func capitalizeWords(text string) string {
+	words := strings.Fields(text)
+	for i, word := range words {
+		runes := []rune(word)
+		runes[0] = unicode.ToUpper(runes[0])
+		words[i] = string(runes)
+	}
+	return strings.Join(words, " ")
+}
+
+// applyQueryConstraints sets the filters the query stated, unless the
+// caller set them explicitly or the user removed them, and records which
+// were applied
+func applyQueryConstraints(params *repositories.SearchParams, constraints []QueryConstraint) {
+	ignored := make(map[string]struct{}, len(params.IgnoredConstraints))
+	for _, t := range params.IgnoredConstraints {
+		ignored[t] = struct{}{}
+	}
+
+	for i := range constraints {
+		c := &constraints[i]
+		c.Applied = false
+		if _, skip := ignored[string(c.Type)]; skip {
+			continue
+		}
+		switch c.Type {
+		case ConstraintMinPrice:
+			if params.MinPrice == nil {
+				params.MinPrice = c.Amount
+				c.Applied = true
+			}
+		case ConstraintMaxPrice:
+			if params.MaxPrice == nil {
+				params.MaxPrice = c.Amount
+				c.Applied = true
+			}
+		case ConstraintLocality:
+			if params.Area == nil {
+				params.Area = c.Area
+				c.Applied = true
+			}
+		case ConstraintFacilityType:
+			if len(params.FacilityTypes) == 0 {
+				params.FacilityTypes = c.FacilityTypes
+				c.Applied = true
+			}
+		case ConstraintInsurance:
+			if params.InsuranceProvider == "" {
+				params.InsuranceProvider = c.Value
+				c.Applied = true
+			}
+		case ConstraintOpenNow:
+			params.OpenNow = true
+			c.Applied = true
+		case ConstraintOpen24Hours:
+			params.Open24Hours = true
+			c.Applied = true
+		case ConstraintSort:
+			if params.SortBy == "" {
+				params.SortBy = c.Value
+				c.Applied = true
+			}
+		}
+	}
+}
@@ -0,0 +1,265 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/providers"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/repositories"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/evaluation"
+)
+
+// stubInsurerList lists a fixed set of insurers
+type stubInsurerList struct {
+	repositories.InsuranceRepository
+	insurers []*entities.InsuranceProvider
+	calls    int
+}
+
+func (r *stubInsurerList) List(ctx context.Context, filter repositories.InsuranceFilter) ([]*entities.InsuranceProvider, error) {
+	r.calls++
+	return r.insurers, nil
+}
+
+// stubPlaceGeocoder geocodes a fixed set of place names
+type stubPlaceGeocoder struct {
+	providers.GeolocationProvider
+	places map[string]*providers.GeocodedAddress
+	calls  int
+}
+
+func (g *stubPlaceGeocoder) Geocode(ctx context.Context, address string) (*providers.GeocodedAddress, error) {
+	g.calls++
+	if place, ok := g.places[address]; ok {
+		return place, nil
+	}
+	return &providers.GeocodedAddress{Country: "Nigeria", Coordinates: providers.Coordinates{Latitude: 9.08, Longitude: 8.67}}, nil
+}
+
+func findConstraint(constraints []QueryConstraint, kind QueryConstraintType) *QueryConstraint {
+	for i := range constraints {
+		if constraints[i].Type == kind {
+			return &constraints[i]
+		}
+	}
+	return nil
+}
+
+func TestInterpretSearch_ExtractsPriceBounds(t *testing.T) {
+	svc := newTestQueryService(t)
+
+	tests := []struct {
+		query    string
+		keywords string
+		min, max float64
+	}{
+		{query: "mri under 50k", keywords: "mri", max: 50000},
+		{query: "blood test between 20k and 50k", keywords: "blood test", min: 20000, max: 50000},
+		{query: "x-ray less than ₦5,000", keywords: "x-ray", max: 5000},
+		{query: "dental cleaning over 10000 naira", keywords: "dental cleaning", min: 10000},
+	}
+	for _, tt := range tests {
+		result := svc.InterpretSearch(context.Background(), tt.query)
+		if result.KeywordQuery != tt.keywords {
+			t.Errorf("%q: expected keywords %q, got %q", tt.query, tt.keywords, result.KeywordQuery)
+		}
+		if c := findConstraint(result.Constraints, ConstraintMinPrice); (c == nil) != (tt.min == 0) || (c != nil && *c.Amount != tt.min) {
+			t.Errorf("%q: expected min price %.0f, got %+v", tt.query, tt.min, c)
+		}
+		if c := findConstraint(result.Constraints, ConstraintMaxPrice); (c == nil) != (tt.max == 0) || (c != nil && *c.Amount != tt.max) {
+			t.Errorf("%q: expected max price %.0f, got %+v", tt.query, tt.max, c)
+		}
+	}
+
+	if c := findConstraint(svc.InterpretSearch(context.Background(), "mri under 50k").Constraints, ConstraintMaxPrice); c.Label != "Under ₦50,000" {
+		t.Errorf("expected a readable label, got %q", c.Label)
+	}
+}
+
+func TestInterpretSearch_IgnoresSmallBareNumbers(t *testing.T) {
+	svc := newTestQueryService(t)
+
+	result := svc.InterpretSearch(context.Background(), "vaccines for children under 5")
+	if len(result.Constraints) != 0 {
+		t.Fatalf("expected no constraints, got %+v", result.Constraints)
+	}
+	if result.KeywordQuery != "" {
+		t.Fatalf("expected no keyword rewrite, got %q", result.KeywordQuery)
+	}
+}
+
+func TestInterpretSearch_ExtractsHoursTypeAndSort(t *testing.T) {
+	svc := newTestQueryService(t)
+
+	result := svc.InterpretSearch(context.Background(), "24 hour pharmacy")
+	if findConstraint(result.Constraints, ConstraintOpen24Hours) == nil {
+		t.Fatalf("expected a 24-hour constraint, got %+v", result.Constraints)
+	}
+	if c := findConstraint(result.Constraints, ConstraintFacilityType); c == nil || len(c.FacilityTypes) != 1 || c.FacilityTypes[0] != "pharmacy" {
+		t.Fatalf("expected a pharmacy constraint, got %+v", c)
+	}
+	if result.DetectedIntent != evaluation.IntentFacility {
+		t.Fatalf("expected a query of only constraints to be a facility search, got %q", result.DetectedIntent)
+	}
+
+	result = svc.InterpretSearch(context.Background(), "cheapest ultrasound scan open now")
+	if c := findConstraint(result.Constraints, ConstraintSort); c == nil || c.Value != repositories.SearchSortPrice {
+		t.Fatalf("expected a price sort, got %+v", c)
+	}
+	if c := findConstraint(result.Constraints, ConstraintOpenNow); c == nil || c.Note == "" {
+		t.Fatalf("expected an open-now constraint noting it is approximate, got %+v", result.Constraints)
+	}
+	if result.KeywordQuery != "ultrasound scan" {
+		t.Fatalf("expected constraint words removed from the keywords, got %q", result.KeywordQuery)
+	}
+
+	result = svc.InterpretSearch(context.Background(), "pharmacy quickest to reach")
+	if c := findConstraint(result.Constraints, ConstraintSort); c == nil || c.Value != repositories.SearchSortTravelTime {
+		t.Fatalf("expected a travel time sort, got %+v", c)
+	}
+}
+
+func TestInterpretSearch_MatchesInsurersByShortName(t *testing.T) {
+	svc := newTestQueryService(t)
+	insurers := &stubInsurerList{insurers: []*entities.InsuranceProvider{
+		{ID: "ins-1", Name: "Hygeia HMO", Code: "HYG-01", IsActive: true},
+		{ID: "ins-2", Name: "AXA Mansard Health", Code: "AXA", IsActive: true},
+	}}
+	svc.SetInsuranceRepository(insurers)
+
+	result := svc.InterpretSearch(context.Background(), "hospital that takes hygeia")
+	c := findConstraint(result.Constraints, ConstraintInsurance)
+	if c == nil || c.Value != "Hygeia HMO" {
+		t.Fatalf("expected Hygeia HMO, got %+v", result.Constraints)
+	}
+	if result.KeywordQuery != "" {
+		t.Fatalf("expected every word to be a constraint, got keywords %q", result.KeywordQuery)
+	}
+
+	result = svc.InterpretSearch(context.Background(), "physiotherapy with axa mansard")
+	if c := findConstraint(result.Constraints, ConstraintInsurance); c == nil || c.Value != "AXA Mansard Health" {
+		t.Fatalf("expected AXA Mansard Health, got %+v", result.Constraints)
+	}
+	if insurers.calls != 1 {
+		t.Fatalf("expected the insurer list to be cached, got %d loads", insurers.calls)
+	}
+}
+
+// stubGazetteer knows a fixed set of places
+type stubGazetteer map[string]*providers.GazetteerPlace
+
+func (g stubGazetteer) LookupPlace(name string) (*providers.GazetteerPlace, bool) {
+	place, ok := g[name]
+	return place, ok
+}
+
+func TestInterpretSearch_ResolvesLocalitiesFromTheGazetteer(t *testing.T) {
+	svc := newTestQueryService(t)
+	surulere := &providers.GazetteerPlace{
+		Name: "Surulere", Kind: providers.PlaceKindLGA, State: "Lagos",
+		Centroid: providers.Coordinates{Latitude: 6.4969, Longitude: 3.3481},
+		Bounds:   providers.BoundingBox{MinLatitude: 6.4469, MinLongitude: 3.2981, MaxLatitude: 6.5469, MaxLongitude: 3.3981},
+	}
+	victoriaIsland := &providers.GazetteerPlace{
+		Name: "Victoria Island", Kind: providers.PlaceKindNeighbourhood, State: "Lagos",
+		Centroid: providers.Coordinates{Latitude: 6.4281, Longitude: 3.4219},
+		Bounds:   providers.BoundingBox{MinLatitude: 6.4131, MinLongitude: 3.4069, MaxLatitude: 6.4431, MaxLongitude: 3.4369},
+	}
+	oyun := &providers.GazetteerPlace{Name: "Oyun", Kind: providers.PlaceKindLGA, State: "Kwara"}
+	svc.SetGazetteer(stubGazetteer{"surulere": surulere, "vi": victoriaIsland, "oyun": oyun})
+
+	result := svc.InterpretSearch(context.Background(), "pharmacy surulere")
+	c := findConstraint(result.Constraints, ConstraintLocality)
+	if c == nil || c.Area.Name != "Surulere" || c.Text != "surulere" {
+		t.Fatalf("expected Surulere without a cue word, got %+v", c)
+	}
+	if c.Area.RadiusKm < 5 || c.Area.RadiusKm > 10 {
+		t.Fatalf("expected the radius to cover the LGA's box, got %f", c.Area.RadiusKm)
+	}
+
+	// Short aliases need a cue word
+	if c := findConstraint(svc.InterpretSearch(context.Background(), "scan vi").Constraints, ConstraintLocality); c != nil {
+		t.Fatalf("expected no locality for a short alias without a cue, got %+v", c)
+	}
+	if c := findConstraint(svc.InterpretSearch(context.Background(), "scan in vi").Constraints, ConstraintLocality); c == nil || c.Area.Name != "Victoria Island" {
+		t.Fatalf("expected Victoria Island after a cue, got %+v", c)
+	}
+
+	// Words after a cue that the gazetteer does not know are not places
+	for _, query := range []string{"mri in london", "pharmacy near me", "clinic for pain in chest"} {
+		result := svc.InterpretSearch(context.Background(), query)
+		if c := findConstraint(result.Constraints, ConstraintLocality); c != nil {
+			t.Errorf("%q: expected no locality, got %+v", query, c)
+		}
+	}
+
+	// "oyun" is the Yoruba for pregnancy as well as an LGA in Kwara
+	if c := findConstraint(svc.InterpretSearch(context.Background(), "oyun test").Constraints, ConstraintLocality); c != nil {
+		t.Fatalf("expected no locality inside a local-language term, got %+v", c)
+	}
+}
+
+func TestApplyQueryConstraints_ExplicitParamsAndIgnoredTypesWin(t *testing.T) {
+	explicitMax := 80000.0
+	queryMax := 50000.0
+	queryMin := 10000.0
+	params := repositories.SearchParams{
+		MaxPrice:           &explicitMax,
+		IgnoredConstraints: []string{string(ConstraintMinPrice)},
+	}
+	constraints := []QueryConstraint{
+		{Type: ConstraintMaxPrice, Amount: &queryMax},
+		{Type: ConstraintMinPrice, Amount: &queryMin},
+		{Type: ConstraintSort, Value: repositories.SearchSortRating},
+		{Type: ConstraintOpenNow},
+	}
+
+	applyQueryConstraints(&params, constraints)
+
+	if *params.MaxPrice != explicitMax || constraints[0].Applied {
+		t.Fatalf("expected the explicit max price to win, got %v", *params.MaxPrice)
+	}
+	if params.MinPrice != nil || constraints[1].Applied {
+		t.Fatalf("expected the ignored min price to be dropped, got %v", params.MinPrice)
+	}
+	if params.SortBy != repositories.SearchSortRating || !constraints[2].Applied {
+		t.Fatalf("expected the rating sort to apply, got %q", params.SortBy)
+	}
+	if !params.OpenNow || !constraints[3].Applied {
+		t.Fatalf("expected open-now to apply")
+	}
+}
+
+func TestFacilitySearch_AppliesQueryConstraints(t *testing.T) {
+	corpus := evaluation.NewFixtureCorpus([]*evaluation.FixtureFacility{
+		{Facility: entities.Facility{ID: "pharmacy", Name: "Lekki Pharmacy", FacilityType: "pharmacy", IsActive: true}, Keywords: []string{"paracetamol"}},
+		{Facility: entities.Facility{ID: "urgent", Name: "City Urgent Care", FacilityType: "urgent_care", IsActive: true}, Keywords: []string{"paracetamol"}},
+	})
+	facilityService := NewFacilityService(corpus, nil, nil, nil, nil)
+	facilityService.SetQueryUnderstanding(newTestQueryService(t))
+
+	params := repositories.SearchParams{Query: "paracetamol pharmacy", Limit: 10}
+	results, total, interpretation, err := facilityService.SearchResultsWithCount(context.Background(), params)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if total != 1 || results[0].ID != "pharmacy" {
+		t.Fatalf("expected only the pharmacy, got %d results", total)
+	}
+	if interpretation == nil || findConstraint(interpretation.Constraints, ConstraintFacilityType) == nil {
+		t.Fatalf("expected the interpretation to report the facility type constraint")
+	}
+
+	params.IgnoredConstraints = []string{string(ConstraintFacilityType)}
+	_, total, interpretation, err = facilityService.SearchResultsWithCount(context.Background(), params)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if total != 2 {
+		t.Fatalf("expected both facilities once the constraint is removed, got %d", total)
+	}
+	if c := findConstraint(interpretation.Constraints, ConstraintFacilityType); c == nil || c.Applied {
+		t.Fatalf("expected the removed constraint to be reported as not applied, got %+v", c)
+	}
+}
@@ -0,0 +1,302 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/evaluation"
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	languageMarkerWeight  = 1
+	languageConceptWeight = 3
+	languageScriptWeight  = 2
+	languageMinScore      = 2
+)
+
+// languageProfileFile is the on-disk format of config/languages/<code>.json.
+// Concepts map local terms to one or more keys of the English concept dictionary.
+type languageProfileFile struct {
+	Language evaluation.Language `json:"language"`
+	Name     string              `json:"name"`
+	Markers  []string            `json:"markers"`
+	Concepts map[string][]string `json:"concepts"`
+	Spelling map[string]string   `json:"spelling"`
+}
+
+// languageProfile is the folded, indexed form of a language dictionary.
+type languageProfile struct {
+	language       evaluation.Language
+	name           string
+	markers        map[string]struct{}
+	concepts       map[string][]string // folded local term → concept keys
+	multiWordIndex map[string][]string // first word → folded multi-word terms
+	spelling       map[string]string
+	vocabulary     map[string]struct{} // every word of the markers and local terms
+}
+
+// scriptHints are letters that only appear in a given language's orthography.
+var scriptHints = map[evaluation.Language]string{
+	evaluation.LanguageYoruba: "ẹṣ",
+	evaluation.LanguageHausa:  "ɓɗƙƴ",
+	evaluation.LanguageIgbo:   "ịụṅ",
+}
+
+// LoadLanguageProfiles loads every <code>.json language dictionary in dir.
+// Concept targets must already exist in the English concept dictionary.
+func (s *QueryUnderstandingService) LoadLanguageProfiles(dir string) error {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return err
+	}
+	sort.Strings(paths)
+
+	profiles := make([]*languageProfile, 0, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		var raw languageProfileFile
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return fmt.Errorf("failed to parse language profile %s: %w", path, err)
+		}
+		if !raw.Language.IsValid() || raw.Language == evaluation.LanguageEnglish {
+			return fmt.Errorf("language profile %s: unsupported language %q", path, raw.Language)
+		}
+
+		profile := &languageProfile{
+			language:       raw.Language,
+			name:           raw.Name,
+			markers:        make(map[string]struct{}, len(raw.Markers)),
+			concepts:       make(map[string][]string, len(raw.Concepts)),
+			multiWordIndex: make(map[string][]string),
+			spelling:       make(map[string]string, len(raw.Spelling)),
+			vocabulary:     make(map[string]struct{}),
+		}
+		for _, marker := range raw.Markers {
+			if m := foldDiacritics(normalizeQueryText(marker)); m != "" {
+				profile.markers[m] = struct{}{}
+				profile.vocabulary[m] = struct{}{}
+			}
+		}
+		for term, targets := range raw.Concepts {
+			key := foldDiacritics(normalizeQueryText(term))
+			if key == "" || len(targets) == 0 {
+				continue
+			}
+			for _, target := range targets {
+				if _, ok := s.conceptDict[strings.ToLower(strings.TrimSpace(target))]; !ok {
+					return fmt.Errorf("language profile %s: %q maps to unknown concept %q", path, term, target)
+				}
+			}
+			profile.concepts[key] = targets
+			for _, word := range strings.Fields(key) {
+				profile.vocabulary[word] = struct{}{}
+			}
+			if words := strings.Fields(key); len(words) > 1 {
+				profile.multiWordIndex[words[0]] = append(profile.multiWordIndex[words[0]], key)
+			}
+		}
+		for from, to := range raw.Spelling {
+			f := foldDiacritics(normalizeQueryText(from))
+			t := foldDiacritics(normalizeQueryText(to))
+			if f != "" && t != "" && f != t {
+				profile.spelling[f] = t
+			}
+		}
+		profiles = append(profiles, profile)
+	}
+
+	// Ties between languages sharing a term (e.g. "iba") resolve in ValidLanguages order.
+	priority := make(map[evaluation.Language]int)
+	for i, lang := range evaluation.ValidLanguages() {
+		priority[lang] = i
+	}
+	sort.SliceStable(profiles, func(i, j int) bool {
+		return priority[profiles[i].language] < priority[profiles[j].language]
+	})
+
+	s.mu.Lock()
+	s.languages = profiles
+	s.mu.Unlock()
+	return nil
+}
+
+// detectLanguage scores each language profile against the query and returns
+// the best match, falling back to English when no profile is convincing.
+// Local terms only count where the English dictionary does not already
+// explain the words, so "sugar test" stays English while "sugar" alone does not.
+func (s *QueryUnderstandingService) detectLanguage(rawQuery, folded string) (*languageProfile, evaluation.Language) {
+	if len(s.languages) == 0 || folded == "" {
+		return nil, evaluation.LanguageEnglish
+	}
+	words := strings.Fields(folded)
+	english := s.englishCoverage(words)
+	composed := norm.NFC.String(strings.ToLower(rawQuery))
+
+	var best *languageProfile
+	bestScore := 0
+	for _, profile := range s.languages {
+		corrected := profile.correctSpelling(words)
+		score := 0
+		for _, w := range corrected {
+			if _, ok := profile.markers[w]; ok {
+				score += languageMarkerWeight
+			}
+		}
+		for _, m := range profile.matchPhrases(corrected) {
+			for i := m.start; i < m.end; i++ {
+				if !english[i] {
+					score += languageConceptWeight
+					break
+				}
+			}
+		}
+		if hints, ok := scriptHints[profile.language]; ok && strings.ContainsAny(composed, hints) {
+			score += languageScriptWeight
+		}
+		if score > bestScore {
+			best = profile
+			bestScore = score
+		}
+	}
+
+	if best == nil || bestScore < languageMinScore {
+		return nil, evaluation.LanguageEnglish
+	}
+	return best, best.language
+}
+
+// hasLocalLanguageWord reports whether any of the words belongs to a local
+// language profile, such as the "ife" of the Yoruba "iko ife"
+func (s *QueryUnderstandingService) hasLocalLanguageWord(words []string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, profile := range s.languages {
+		for _, word := range words {
+			if _, ok := profile.vocabulary[foldDiacritics(word)]; ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// englishCoverage marks word positions covered by an English concept dictionary key.
+func (s *QueryUnderstandingService) englishCoverage(words []string) map[int]bool {
+	covered := make(map[int]bool, len(words))
+	for i := 0; i < len(words); i++ {
+		n := 0
+		for _, phrase := range s.multiWordIndex[words[i]] {
+			pw := strings.Fields(phrase)
+			if len(pw) > n && i+len(pw) <= len(words) && strings.Join(words[i:i+len(pw)], " ") == phrase {
+				n = len(pw)
+			}
+		}
+		if n == 0 {
+			if _, ok := s.conceptDict[words[i]]; ok {
+				n = 1
+			}
+		}
+		for j := i; j < i+n; j++ {
+			covered[j] = true
+		}
+	}
+	return covered
+}
+
+// translate rewrites local-language terms in the query to their English concept
+// keys so the rest of the pipeline can operate on a single dictionary.
+func (p *languageProfile) translate(folded string) string {
+	words := p.correctSpelling(strings.Fields(folded))
+	matches := p.matchPhrases(words)
+	if len(matches) == 0 {
+		return strings.Join(words, " ")
+	}
+
+	var out []string
+	next := 0
+	for _, m := range matches {
+		out = append(out, words[next:m.start]...)
+		out = append(out, p.concepts[m.term]...)
+		next = m.end
+	}
+	out = append(out, words[next:]...)
+	return strings.Join(out, " ")
+}
+
+func (p *languageProfile) correctSpelling(words []string) []string {
+	corrected := make([]string, len(words))
+	for i, w := range words {
+		if c, ok := p.spelling[w]; ok {
+			corrected[i] = c
+		} else {
+			corrected[i] = w
+		}
+	}
+	return corrected
+}
+
+type phraseMatch struct {
+	start, end int
+	term       string
+}
+
+// matchPhrases finds non-overlapping local concept terms, preferring the longest phrase at each position.
+func (p *languageProfile) matchPhrases(words []string) []phraseMatch {
+	var matches []phraseMatch
+	for i := 0; i < len(words); i++ {
+		bestLen := 0
+		bestTerm := ""
+		for _, phrase := range p.multiWordIndex[words[i]] {
+			n := len(strings.Fields(phrase))
+			if n <= bestLen || i+n > len(words) {
+				continue
+			}
+			if strings.Join(words[i:i+n], " ") == phrase {
+				bestLen = n
+				bestTerm = phrase
+			}
+		}
+		if bestLen == 0 {
+			if _, ok := p.concepts[words[i]]; ok {
+				bestLen = 1
+				bestTerm = words[i]
+			}
+		}
+		if bestLen > 0 {
+			matches = append(matches, phraseMatch{start: i, end: i + bestLen, term: bestTerm})
+			i += bestLen - 1
+		}
+	}
+	return matches
+}
+
+// foldDiacritics strips tone marks and under-dots so "ìbà" and "iba" match.
+func foldDiacritics(s string) string {
+	var b strings.Builder
+	for _, r := range norm.NFD.String(s) {
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+		switch r {
+		case 'ɓ':
+			r = 'b'
+		case 'ɗ':
+			r = 'd'
+		case 'ƙ':
+			r = 'k'
+		case 'ƴ':
+			r = 'y'
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
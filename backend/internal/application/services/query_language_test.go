@@ -0,0 +1,177 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/evaluation"
+)
+
+func TestDetectLanguage_EnglishDefault(t *testing.T) {
+	svc := newTestQueryService(t)
+	for _, q := range []string{"malaria", "ct scan near me", "sugar test", "blood test"} {
+		result := svc.Interpret(q)
+		if result.DetectedLanguage != evaluation.LanguageEnglish {
+			t.Errorf("%q: expected English, got %q", q, result.DetectedLanguage)
+		}
+		if result.TranslatedQuery != "" {
+			t.Errorf("%q: expected no translation, got %q", q, result.TranslatedQuery)
+		}
+	}
+}
+
+func TestDetectLanguage_YorubaIbaMapsToMalariaAndFever(t *testing.T) {
+	svc := newTestQueryService(t)
+	result := svc.Interpret("ibà")
+	if result.DetectedLanguage != evaluation.LanguageYoruba {
+		t.Fatalf("expected Yoruba, got %q", result.DetectedLanguage)
+	}
+	if result.MappedConcepts == nil {
+		t.Fatal("expected mapped concepts")
+	}
+	if !containsStr(result.MappedConcepts.Conditions, "malaria") {
+		t.Errorf("expected malaria condition, got %v", result.MappedConcepts.Conditions)
+	}
+	if !containsStr(result.MappedConcepts.Symptoms, "fever") {
+		t.Errorf("expected fever symptom, got %v", result.MappedConcepts.Symptoms)
+	}
+}
+
+func TestDetectLanguage_PidginBelleDeyPainMe(t *testing.T) {
+	svc := newTestQueryService(t)
+	result := svc.Interpret("Belle dey pain me")
+	if result.DetectedLanguage != evaluation.LanguagePidgin {
+		t.Fatalf("expected Pidgin, got %q", result.DetectedLanguage)
+	}
+	if result.TranslatedQuery != "belle pain" {
+		t.Errorf("expected translation 'belle pain', got %q", result.TranslatedQuery)
+	}
+	if result.MappedConcepts == nil || !containsStr(result.MappedConcepts.Symptoms, "abdominal pain") {
+		t.Errorf("expected abdominal pain symptom, got %+v", result.MappedConcepts)
+	}
+	if result.DetectedIntent != evaluation.IntentSymptom {
+		t.Errorf("expected symptom intent, got %q", result.DetectedIntent)
+	}
+}
+
+// "Running stomach" is diarrhoea and "wound" asks for dressing; routing either
+// to cholera or infection sends common Pidgin queries to the wrong procedures.
+func TestDetectLanguage_PidginRunningStomachAndWound(t *testing.T) {
+	svc := newTestQueryService(t)
+
+	stomach := svc.Interpret("my pikin get running stomach")
+	if stomach.DetectedLanguage != evaluation.LanguagePidgin {
+		t.Fatalf("expected Pidgin, got %q", stomach.DetectedLanguage)
+	}
+	if stomach.MappedConcepts == nil || !containsStr(stomach.MappedConcepts.Symptoms, "diarrhoea") {
+		t.Errorf("expected diarrhoea symptom, got %+v", stomach.MappedConcepts)
+	}
+	if stomach.MappedConcepts != nil && containsStr(stomach.MappedConcepts.Conditions, "cholera") {
+		t.Errorf("running stomach should not map to cholera, got %v", stomach.MappedConcepts.Conditions)
+	}
+
+	wound := svc.Interpret("where dem dey dress wound")
+	if wound.DetectedLanguage != evaluation.LanguagePidgin {
+		t.Fatalf("expected Pidgin, got %q", wound.DetectedLanguage)
+	}
+	if wound.MappedConcepts == nil || !containsStr(wound.MappedConcepts.LayTerms, "wound care") {
+		t.Errorf("expected wound care procedure, got %+v", wound.MappedConcepts)
+	}
+	if wound.MappedConcepts != nil && containsStr(wound.MappedConcepts.Conditions, "infection") {
+		t.Errorf("wound should not map to infection, got %v", wound.MappedConcepts.Conditions)
+	}
+}
+
+func TestDetectLanguage_HausaAndIgbo(t *testing.T) {
+	svc := newTestQueryService(t)
+
+	ha := svc.Interpret("ciwon kai")
+	if ha.DetectedLanguage != evaluation.LanguageHausa {
+		t.Errorf("expected Hausa, got %q", ha.DetectedLanguage)
+	}
+	if ha.MappedConcepts == nil || !containsStr(ha.MappedConcepts.Symptoms, "headache") {
+		t.Errorf("expected headache symptom, got %+v", ha.MappedConcepts)
+	}
+
+	ig := svc.Interpret("afọ mgbu")
+	if ig.DetectedLanguage != evaluation.LanguageIgbo {
+		t.Errorf("expected Igbo, got %q", ig.DetectedLanguage)
+	}
+	if ig.MappedConcepts == nil || !containsStr(ig.MappedConcepts.Symptoms, "stomach ache") {
+		t.Errorf("expected stomach ache symptom, got %+v", ig.MappedConcepts)
+	}
+}
+
+func TestDetectLanguage_LanguageSpellingMap(t *testing.T) {
+	svc := newTestQueryService(t)
+	result := svc.Interpret("zazzabe")
+	if result.DetectedLanguage != evaluation.LanguageHausa {
+		t.Fatalf("expected Hausa, got %q", result.DetectedLanguage)
+	}
+	if result.MappedConcepts == nil || !containsStr(result.MappedConcepts.Symptoms, "fever") {
+		t.Errorf("expected fever symptom, got %+v", result.MappedConcepts)
+	}
+}
+
+func TestFoldDiacritics(t *testing.T) {
+	cases := map[string]string{
+		"ìbà":   "iba",
+		"ẹ̀yìn": "eyin",
+		"ɗaki":  "daki",
+		"afọ":   "afo",
+		"plain": "plain",
+	}
+	for in, want := range cases {
+		if got := foldDiacritics(in); got != want {
+			t.Errorf("foldDiacritics(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestLoadLanguageProfiles_UnknownConcept(t *testing.T) {
+	svc := newTestQueryService(t)
+	dir := t.TempDir()
+	content := `{"language": "yo", "concepts": {"nkan": ["not a concept"]}}`
+	if err := os.WriteFile(filepath.Join(dir, "yo.json"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.LoadLanguageProfiles(dir); err == nil {
+		t.Error("expected error for unknown concept target")
+	}
+}
+
+// Every non-English golden query must be detected as its labelled language and
+// map onto at least one English concept.
+func TestGoldenQueries_LanguageDetection(t *testing.T) {
+	svc := newTestQueryService(t)
+	queries, err := evaluation.LoadGoldenQueries(filepath.Join(testConfigDir(), "golden_queries.json"))
+	if err != nil {
+		t.Fatalf("failed to load golden queries: %v", err)
+	}
+	if err := evaluation.ValidateGoldenQueries(queries); err != nil {
+		t.Fatalf("invalid golden queries: %v", err)
+	}
+
+	perLanguage := make(map[evaluation.Language]int)
+	for _, q := range queries {
+		lang := q.LanguageOrDefault()
+		perLanguage[lang]++
+		if lang == evaluation.LanguageEnglish {
+			continue
+		}
+		result := svc.Interpret(q.Query)
+		if result.DetectedLanguage != lang {
+			t.Errorf("%s %q: expected language %q, got %q", q.ID, q.Query, lang, result.DetectedLanguage)
+		}
+		if result.MappedConcepts == nil {
+			t.Errorf("%s %q: expected mapped concepts", q.ID, q.Query)
+		}
+	}
+
+	for _, lang := range evaluation.ValidLanguages() {
+		if perLanguage[lang] == 0 {
+			t.Errorf("expected golden queries for language %q", lang)
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"os"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -22,6 +23,8 @@ import (
 type QueryInterpretation struct {
 	OriginalQuery    string                   `json:"original_query"`
 	NormalizedQuery  string                   `json:"normalized_query"`
+	DetectedLanguage evaluation.Language      `json:"detected_language,omitempty"`
+	TranslatedQuery  string                   `json:"translated_query,omitempty"`
 	CorrectedQuery   string                   `json:"corrected_query,omitempty"`
 	DetectedIntent   evaluation.Intent        `json:"detected_intent"`
 	IntentConfidence float64                  `json:"intent_confidence"`
@@ -29,6 +32,10 @@ type QueryInterpretation struct {
 	ExpandedTerms    []string                 `json:"expanded_terms,omitempty"`
 	SearchTerms      []string                 `json:"search_terms"`
 	UnmatchedTerms   []string                 `json:"unmatched_terms,omitempty"`
+	// KeywordQuery is the query without the words its constraints were read
+	// from; it is what keyword search matches
+	KeywordQuery string            `json:"keyword_query,omitempty"`
+	Constraints  []QueryConstraint `json:"constraints,omitempty"`
 }
 
 // ConceptEntry represents a single entry in the concept dictionary.
@@ -43,10 +50,13 @@ type ConceptEntry struct {
 // QueryUnderstandingService interprets user search queries by normalizing,
 // spell-correcting, detecting intent, and mapping to medical concepts.
 type QueryUnderstandingService struct {
+	mu             sync.RWMutex
 	conceptDict    map[string]*ConceptEntry // term → concept
 	spellingDict   map[string]string        // misspelling → correct
 	multiWordIndex map[string][]string      // first word → full multi-word keys
+	languages      []*languageProfile       // non-English dictionaries, in detection priority order
 	cache          providers.CacheProvider
+	resolvers      constraintResolvers
 }
 
 var nonAlphaNumDash = regexp.MustCompile(`[^\p{L}\p{N}\s\-'/]`)
@@ -60,6 +70,7 @@ const (
 	missingTermCountCacheKeyPrefix = "metrics:term_not_found:"
 	missingTermCountTTLSeconds     = 86400
 	missingTermCatalogThreshold    = 3
+	interpretationCacheKeyPrefix   = "query_interp:"
 )
 
 var ignoredUnmatchedTerms = map[string]struct{}{
@@ -129,6 +140,84 @@ func (s *QueryUnderstandingService) SetCache(cache providers.CacheProvider) {
 	s.cache = cache
 }
 
+// ConceptTerms returns all concept dictionary keys in sorted order.
+func (s *QueryUnderstandingService) ConceptTerms() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	terms := make([]string, 0, len(s.conceptDict))
+	for term := range s.conceptDict {
+		terms = append(terms, term)
+	}
+	sort.Strings(terms)
+	return terms
+}
+
+// HasConcept reports whether the term is a concept dictionary key.
+func (s *QueryUnderstandingService) HasConcept(term string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.conceptDict[strings.ToLower(strings.TrimSpace(term))]
+	return ok
+}
+
+// AddSpellingCorrection registers a misspelling → correction mapping at runtime.
+func (s *QueryUnderstandingService) AddSpellingCorrection(misspelling, correction string) {
+	from := strings.ToLower(strings.TrimSpace(misspelling))
+	to := strings.ToLower(strings.TrimSpace(correction))
+	if from == "" || to == "" || from == to {
+		return
+	}
+
+	s.mu.Lock()
+	s.spellingDict[from] = to
+	s.mu.Unlock()
+
+	s.invalidateInterpretations()
+}
+
+// AddSynonym maps a term or phrase onto the concept for target. When target is
+// not yet a concept key (e.g. a procedure name), a procedure concept is created.
+func (s *QueryUnderstandingService) AddSynonym(term, target string) {
+	from := strings.ToLower(strings.TrimSpace(term))
+	to := strings.ToLower(strings.TrimSpace(target))
+	if from == "" || to == "" || from == to {
+		return
+	}
+
+	s.mu.Lock()
+	entry, ok := s.conceptDict[to]
+	if !ok {
+		entry = &ConceptEntry{
+			CanonicalForm: to,
+			Category:      "procedure",
+			RelatedTerms:  []string{to},
+		}
+		s.addConceptLocked(to, entry)
+	}
+	s.addConceptLocked(from, entry)
+	s.mu.Unlock()
+
+	s.invalidateInterpretations()
+}
+
+func (s *QueryUnderstandingService) addConceptLocked(key string, entry *ConceptEntry) {
+	if _, exists := s.conceptDict[key]; !exists {
+		words := strings.Fields(key)
+		if len(words) > 1 {
+			s.multiWordIndex[words[0]] = append(s.multiWordIndex[words[0]], key)
+		}
+	}
+	s.conceptDict[key] = entry
+}
+
+func (s *QueryUnderstandingService) invalidateInterpretations() {
+	if s.cache == nil {
+		return
+	}
+	_ = s.cache.DeletePattern(context.Background(), interpretationCacheKeyPrefix+"*")
+}
+
 // Interpret processes a raw search query through the full understanding pipeline.
 func (s *QueryUnderstandingService) Interpret(query string) *QueryInterpretation {
 	q := strings.TrimSpace(strings.ToLower(query))
@@ -137,7 +226,7 @@ func (s *QueryUnderstandingService) Interpret(query string) *QueryInterpretation
 	}
 
 	if s.cache != nil {
-		cacheKey := "query_interp:" + q
+		cacheKey := interpretationCacheKeyPrefix + q
 		if data, err := s.cache.Get(context.Background(), cacheKey); err == nil {
 			var cached QueryInterpretation
 			if json.Unmarshal(data, &cached) == nil {
@@ -157,12 +246,26 @@ func (s *QueryUnderstandingService) Interpret(query string) *QueryInterpretation
 		return result
 	}
 
+	s.mu.RLock()
+
+	// Step 1b: Detect language and translate local-language terms to concept keys
+	effectiveQuery := normalized
+	profile, language := s.detectLanguage(query, foldDiacritics(normalized))
+	result.DetectedLanguage = language
+	if profile != nil {
+		translated := profile.translate(foldDiacritics(normalized))
+		if translated != normalized {
+			result.TranslatedQuery = translated
+		}
+		effectiveQuery = translated
+	}
+
 	// Step 2: Spell correct
-	corrected, wasChanged := s.spellCorrect(normalized)
+	corrected, wasChanged := s.spellCorrect(effectiveQuery)
 	if wasChanged {
 		result.CorrectedQuery = corrected
 	}
-	effectiveQuery := corrected
+	effectiveQuery = corrected
 
 	// Step 3: Map to concepts (try multi-word first, then individual words)
 	concepts, matchedEntries, unmatchedTerms := s.mapToConcepts(effectiveQuery)
@@ -175,10 +278,11 @@ func (s *QueryUnderstandingService) Interpret(query string) *QueryInterpretation
 	// Step 5: Build search terms (original + related + concept terms)
 	result.SearchTerms = s.buildSearchTerms(effectiveQuery, matchedEntries)
 	result.ExpandedTerms = result.SearchTerms
+	s.mu.RUnlock()
 	s.recordMissingTermMetrics(result.UnmatchedTerms)
 
 	if s.cache != nil {
-		cacheKey := "query_interp:" + q
+		cacheKey := interpretationCacheKeyPrefix + q
 		if data, err := json.Marshal(result); err == nil {
 			_ = s.cache.Set(context.Background(), cacheKey, data, 86400) // 24 hours
 		}
@@ -188,6 +292,11 @@ func (s *QueryUnderstandingService) Interpret(query string) *QueryInterpretation
 }
 
 func (s *QueryUnderstandingService) normalize(query string) string {
+	return normalizeQueryText(query)
+}
+
+// normalizeQueryText lowercases, strips punctuation and collapses whitespace.
+func normalizeQueryText(query string) string {
 	q := strings.ToLower(strings.TrimSpace(query))
 	q = nonAlphaNumDash.ReplaceAllString(q, "")
 	// Collapse multiple spaces
@@ -25,6 +25,9 @@ func newTestQueryService(t *testing.T) *QueryUnderstandingService {
 	if err != nil {
 		t.Fatalf("failed to create service: %v", err)
 	}
+	if err := svc.LoadLanguageProfiles(filepath.Join(configDir, "languages")); err != nil {
+		t.Fatalf("failed to load language profiles: %v", err)
+	}
 	return svc
 }
 
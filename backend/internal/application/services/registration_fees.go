@@ -0,0 +1,118 @@
+package services
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/repositories"
+)
+
+// FacilityProcedureLister lists a facility's procedures
+type FacilityProcedureLister interface {
+	ListByFacilityWithCount(ctx context.Context, facilityID string, filter repositories.FacilityProcedureFilter) ([]*entities.FacilityProcedure, int, error)
+}
+
+var (
+	registrationFeeRe = regexp.MustCompile(`(?i)\b(folder|appointment\s*card|registration|chart)\b`)
+	treatmentCardRe   = regexp.MustCompile(`(?i)\btreatment\s*card\b`)
+	appointmentCardRe = regexp.MustCompile(`(?i)\bappointment\s*card\b`)
+	// Documentation and report items would inflate the fees
+	documentationFeeRe = regexp.MustCompile(`(?i)\b(certificate|report|police|assault|adoption|notification\s+of\s+birth|sick\s+leave|maternity\s+leave|suppliers?|contractors?|leaving\s+the\s+country)\b`)
+)
+
+// registrationFeeCategories hold registration-type fees. Some datasets
+// historically stored these under category=administrative, while newer
+// ingestions split them into category=registration, so both categories are
+// read and then filtered conservatively.
+var registrationFeeCategories = []string{"registration", "administrative"}
+
+// ListRegistrationFees returns the registration-type fees a facility charges
+// on a visit, such as folder, card and appointment fees.
+func ListRegistrationFees(ctx context.Context, lister FacilityProcedureLister, facilityID string) ([]*entities.FacilityProcedure, error) {
+	var items []*entities.FacilityProcedure
+	for _, category := range registrationFeeCategories {
+		filter := repositories.FacilityProcedureFilter{
+			Category:  category,
+			Limit:     500,
+			Offset:    0,
+			SortBy:    "name",
+			SortOrder: "asc",
+		}
+		categoryItems, _, err := lister.ListByFacilityWithCount(ctx, facilityID, filter)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, categoryItems...)
+	}
+	return RegistrationFees(items), nil
+}
+
+// ListRegistrationFeesByFacility returns the registration-type fees of each
+// facility, keyed by facility ID, with one query per category however many
+// facilities there are
+func ListRegistrationFeesByFacility(ctx context.Context, repo repositories.FacilityProcedureCategoryRepository, facilityIDs []string) (map[string][]*entities.FacilityProcedure, error) {
+	items := make(map[string][]*entities.FacilityProcedure)
+	for _, category := range registrationFeeCategories {
+		byFacility, err := repo.ListByFacilitiesAndCategory(ctx, facilityIDs, category)
+		if err != nil {
+			return nil, err
+		}
+		for facilityID, categoryItems := range byFacility {
+			items[facilityID] = append(items[facilityID], categoryItems...)
+		}
+	}
+
+	fees := make(map[string][]*entities.FacilityProcedure, len(items))
+	for facilityID, facilityItems := range items {
+		fees[facilityID] = RegistrationFees(facilityItems)
+	}
+	return fees, nil
+}
+
+// RegistrationFees picks the registration-type fees out of a facility's
+// registration and administrative items, dropping documentation and report
+// items and counting an appointment card and a treatment card only once
+func RegistrationFees(items []*entities.FacilityProcedure) []*entities.FacilityProcedure {
+	// De-duplicate by facility_procedure id.
+	seenIDs := make(map[string]struct{}, len(items))
+	fees := make([]*entities.FacilityProcedure, 0, len(items))
+	appointmentCardPresent := false
+	for _, item := range items {
+		if item == nil || item.ID == "" {
+			continue
+		}
+		if _, ok := seenIDs[item.ID]; ok {
+			continue
+		}
+		seenIDs[item.ID] = struct{}{}
+
+		lower := strings.ToLower(strings.TrimSpace(item.ProcedureName))
+		if lower == "" || documentationFeeRe.MatchString(lower) {
+			continue
+		}
+		if registrationFeeRe.MatchString(lower) {
+			if appointmentCardRe.MatchString(lower) {
+				appointmentCardPresent = true
+			}
+			fees = append(fees, item)
+			continue
+		}
+		if treatmentCardRe.MatchString(lower) {
+			fees = append(fees, item)
+		}
+	}
+
+	if !appointmentCardPresent {
+		return fees
+	}
+	// An appointment card stands in for the treatment card
+	final := make([]*entities.FacilityProcedure, 0, len(fees))
+	for _, fee := range fees {
+		if !treatmentCardRe.MatchString(strings.ToLower(fee.ProcedureName)) {
+			final = append(final, fee)
+		}
+	}
+	return final
+}
@@ -0,0 +1,36 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+)
+
+func TestRegistrationFees_KeepsRegistrationItemsOnly(t *testing.T) {
+	items := []*entities.FacilityProcedure{
+		{ID: "1", ProcedureName: "Folder Fee", Price: 1000},
+		{ID: "2", ProcedureName: "Medical Report", Price: 5000},
+		{ID: "3", ProcedureName: "Treatment Card", Price: 300},
+		{ID: "1", ProcedureName: "Folder Fee", Price: 1000},
+		{ID: "4", ProcedureName: "Birth Certificate", Price: 2000},
+		{ID: "5", ProcedureName: "Photocopy", Price: 50},
+	}
+
+	fees := RegistrationFees(items)
+	if len(fees) != 2 || fees[0].ID != "1" || fees[1].ID != "3" {
+		t.Fatalf("expected the folder fee and treatment card, got %+v", fees)
+	}
+}
+
+func TestRegistrationFees_AppointmentCardReplacesTreatmentCard(t *testing.T) {
+	items := []*entities.FacilityProcedure{
+		{ID: "1", ProcedureName: "Treatment Card", Price: 300},
+		{ID: "2", ProcedureName: "Appointment Card", Price: 500},
+		{ID: "3", ProcedureName: "Registration", Price: 1000},
+	}
+
+	fees := RegistrationFees(items)
+	if len(fees) != 2 || fees[0].ID != "2" || fees[1].ID != "3" {
+		t.Fatalf("expected the appointment card and registration, got %+v", fees)
+	}
+}
@@ -0,0 +1,55 @@
+package services
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+)
+
+const redactedPlaceholder = "[redacted]"
+
+var (
+	reviewEmailPattern = regexp.MustCompile(`(?i)[a-z0-9._%+\-]+@[a-z0-9.\-]+\.[a-z]{2,}`)
+	// Phone numbers: 10+ digits, optionally with a leading + and space, dot or dash separators.
+	reviewPhonePattern = regexp.MustCompile(`\+?\d(?:[\s.\-]?\d){9,}`)
+	reviewWordPattern  = regexp.MustCompile(`[\p{L}']+`)
+)
+
+// reviewProfanity is matched against whole lowercase words. Moderators make the
+// final call, so the list favours recall over precision.
+var reviewProfanity = map[string]struct{}{
+	"arse": {}, "ass": {}, "asshole": {}, "bastard": {}, "bitch": {}, "bollocks": {},
+	"bullshit": {}, "crap": {}, "cunt": {}, "damn": {}, "dick": {}, "fuck": {},
+	"fucked": {}, "fucking": {}, "motherfucker": {}, "piss": {}, "prick": {},
+	"shit": {}, "shitty": {}, "slut": {}, "twat": {}, "wanker": {}, "whore": {},
+	// Nigerian Pidgin and local insults commonly seen in feedback
+	"mumu": {}, "ode": {}, "olodo": {}, "ashawo": {}, "werey": {}, "oloshi": {},
+}
+
+// screenReviewText redacts contact details from review text and returns the
+// moderation flags raised.
+func screenReviewText(text string) (string, []string) {
+	var flags []string
+
+	redacted := reviewEmailPattern.ReplaceAllString(text, redactedPlaceholder)
+	redacted = reviewPhonePattern.ReplaceAllString(redacted, redactedPlaceholder)
+	if redacted != text {
+		flags = append(flags, entities.ReviewFlagPIIRedacted)
+	}
+
+	if containsProfanity(redacted) {
+		flags = append(flags, entities.ReviewFlagProfanity)
+	}
+
+	return redacted, flags
+}
+
+func containsProfanity(text string) bool {
+	for _, word := range reviewWordPattern.FindAllString(strings.ToLower(text), -1) {
+		if _, ok := reviewProfanity[strings.Trim(word, "'")]; ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,286 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/repositories"
+	apperrors "github.com/zatekoja/Patientpricediscoverydesign/backend/pkg/errors"
+)
+
+const (
+	maxReviewLength        = 2000
+	defaultReviewLimit     = 10
+	maxReviewLimit         = 50
+	defaultModerationLimit = 50
+)
+
+// FacilityReindexer refreshes a facility's search document.
+type FacilityReindexer interface {
+	Reindex(ctx context.Context, facility *entities.Facility) error
+}
+
+// SubmitReviewInput is a patient's review of a completed appointment. The
+// patient email must match the appointment, which proves the visit.
+type SubmitReviewInput struct {
+	AppointmentID string `json:"appointment_id"`
+	PatientEmail  string `json:"patient_email"`
+	Rating        int    `json:"rating"`
+	Comment       string `json:"comment"`
+}
+
+// ReviewService handles verified patient reviews, moderation and facility responses.
+type ReviewService struct {
+	reviews      repositories.ReviewRepository
+	appointments repositories.AppointmentRepository
+	facilities   repositories.FacilityRepository
+	indexer      FacilityReindexer
+	audit        *AuditService
+	now          func() time.Time
+}
+
+// NewReviewService creates a new review service. facilities should read from
+// the database rather than a cache so reindexing sees the recomputed rating.
+func NewReviewService(
+	reviews repositories.ReviewRepository,
+	appointments repositories.AppointmentRepository,
+	facilities repositories.FacilityRepository,
+) *ReviewService {
+	return &ReviewService{
+		reviews:      reviews,
+		appointments: appointments,
+		facilities:   facilities,
+		now:          time.Now,
+	}
+}
+
+// SetReindexer enables search reindexing when a facility's rating changes.
+func (s *ReviewService) SetReindexer(indexer FacilityReindexer) {
+	s.indexer = indexer
+}
+
+// SetAuditLog records moderation decisions and facility responses in the audit log.
+func (s *ReviewService) SetAuditLog(audit *AuditService) {
+	s.audit = audit
+}
+
+// Submit creates a review for a completed appointment. Contact details are
+// redacted and every review waits in the moderation queue before publication.
+func (s *ReviewService) Submit(ctx context.Context, input SubmitReviewInput) (*entities.Review, error) {
+	input.AppointmentID = strings.TrimSpace(input.AppointmentID)
+	input.PatientEmail = strings.TrimSpace(input.PatientEmail)
+	input.Comment = strings.TrimSpace(input.Comment)
+	if input.AppointmentID == "" {
+		return nil, apperrors.NewValidationError("appointment_id is required")
+	}
+	// An appointment booked without an email must not be reviewable by sending none
+	if input.PatientEmail == "" {
+		return nil, apperrors.NewValidationError("patient_email is required")
+	}
+	if input.Rating < 1 || input.Rating > 5 {
+		return nil, apperrors.NewValidationError("rating must be between 1 and 5")
+	}
+	if utf8.RuneCountInString(input.Comment) > maxReviewLength {
+		return nil, apperrors.NewValidationError(fmt.Sprintf("comment cannot exceed %d characters", maxReviewLength))
+	}
+
+	appointment, err := s.appointments.GetByID(ctx, input.AppointmentID)
+	if err != nil {
+		return nil, err
+	}
+	// Same response as a missing appointment, so appointment IDs cannot be probed.
+	if !strings.EqualFold(strings.TrimSpace(appointment.PatientEmail), input.PatientEmail) {
+		return nil, apperrors.NewNotFoundError(fmt.Sprintf("appointment with id %s not found", input.AppointmentID))
+	}
+	if appointment.Status != entities.AppointmentStatusCompleted {
+		return nil, apperrors.NewValidationError("only completed appointments can be reviewed")
+	}
+
+	if _, err := s.reviews.GetByAppointmentID(ctx, appointment.ID); err == nil {
+		return nil, apperrors.NewConflictError("a review already exists for this appointment")
+	} else if !isNotFound(err) {
+		return nil, err
+	}
+
+	comment, flags := screenReviewText(input.Comment)
+	review := &entities.Review{
+		FacilityID:      appointment.FacilityID,
+		AppointmentID:   appointment.ID,
+		ReviewerName:    reviewerDisplayName(appointment.PatientName),
+		Rating:          input.Rating,
+		Comment:         comment,
+		Status:          entities.ReviewStatusPending,
+		ModerationFlags: flags,
+	}
+	if appointment.UserID != nil {
+		review.UserID = *appointment.UserID
+	}
+
+	if err := s.reviews.Create(ctx, review); err != nil {
+		return nil, err
+	}
+	return review, nil
+}
+
+// ListFacilityReviews returns a page of a facility's published reviews and the total published count.
+func (s *ReviewService) ListFacilityReviews(ctx context.Context, facilityID string, limit, offset int) ([]*entities.Review, int, error) {
+	facility, err := s.facilities.GetByID(ctx, facilityID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	limit, offset = clampPage(limit, offset, defaultReviewLimit, maxReviewLimit)
+	reviews, err := s.reviews.ListByFacility(ctx, facilityID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	// review_count is recomputed with every review write, so it is the published total.
+	return reviews, facility.ReviewCount, nil
+}
+
+// ModerationQueue returns reviews in the given status, pending by default, oldest first.
+func (s *ReviewService) ModerationQueue(ctx context.Context, status entities.ReviewStatus, limit, offset int) ([]*entities.Review, error) {
+	if status == "" {
+		status = entities.ReviewStatusPending
+	}
+	if !status.IsValid() {
+		return nil, apperrors.NewValidationError("status must be one of pending, published, rejected, hidden")
+	}
+	limit, offset = clampPage(limit, offset, defaultModerationLimit, maxReviewLimit)
+	return s.reviews.ListByStatus(ctx, status, limit, offset)
+}
+
+// Moderate moves a review through the moderation workflow. Publishing or
+// unpublishing recomputes the facility rating and reindexes the facility.
+func (s *ReviewService) Moderate(ctx context.Context, reviewID string, status entities.ReviewStatus, note string) (*entities.Review, error) {
+	review, err := s.reviews.GetByID(ctx, reviewID)
+	if err != nil {
+		return nil, err
+	}
+	if !status.IsValid() {
+		return nil, apperrors.NewValidationError("status must be one of pending, published, rejected, hidden")
+	}
+	if !review.Status.CanTransitionTo(status) {
+		return nil, apperrors.NewConflictError(fmt.Sprintf("review cannot move from %s to %s", review.Status, status))
+	}
+
+	before := *review
+	previous := review.Status
+	now := s.now().UTC()
+	review.Status = status
+	review.ModerationNote = strings.TrimSpace(note)
+	review.ModeratedAt = &now
+	err = s.audit.Transact(ctx, func(ctx context.Context) (*AuditEvent, error) {
+		if err = s.reviews.Update(ctx, review); err != nil {
+			return nil, err
+		}
+		return &AuditEvent{
+			Action:     "review.moderate",
+			EntityType: "review",
+			EntityID:   review.ID,
+			FacilityID: review.FacilityID,
+			Before:     &before,
+			After:      review,
+		}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if previous == entities.ReviewStatusPublished || status == entities.ReviewStatusPublished {
+		s.reindexFacility(ctx, review.FacilityID)
+	}
+	return review, nil
+}
+
+// Respond sets the facility's public response to a review.
+func (s *ReviewService) Respond(ctx context.Context, reviewID, response string) (*entities.Review, error) {
+	response = strings.TrimSpace(response)
+	if response == "" {
+		return nil, apperrors.NewValidationError("response is required")
+	}
+	if utf8.RuneCountInString(response) > maxReviewLength {
+		return nil, apperrors.NewValidationError(fmt.Sprintf("response cannot exceed %d characters", maxReviewLength))
+	}
+	if containsProfanity(response) {
+		return nil, apperrors.NewValidationError("response contains inappropriate language")
+	}
+
+	review, err := s.reviews.GetByID(ctx, reviewID)
+	if err != nil {
+		return nil, err
+	}
+	if review.Status == entities.ReviewStatusRejected {
+		return nil, apperrors.NewConflictError("cannot respond to a rejected review")
+	}
+
+	before := *review
+	now := s.now().UTC()
+	review.FacilityResponse = response
+	review.RespondedAt = &now
+	err = s.audit.Transact(ctx, func(ctx context.Context) (*AuditEvent, error) {
+		if err = s.reviews.Update(ctx, review); err != nil {
+			return nil, err
+		}
+		return &AuditEvent{
+			Action:     "review.respond",
+			EntityType: "review",
+			EntityID:   review.ID,
+			FacilityID: review.FacilityID,
+			Before:     &before,
+			After:      review,
+		}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return review, nil
+}
+
+// reindexFacility pushes the recomputed rating to search. Failures are logged
+// because the database already holds the correct rating.
+func (s *ReviewService) reindexFacility(ctx context.Context, facilityID string) {
+	if s.indexer == nil {
+		return
+	}
+	facility, err := s.facilities.GetByID(ctx, facilityID)
+	if err != nil {
+		log.Printf("Warning: Failed to load facility %s for reindex: %v", facilityID, err)
+		return
+	}
+	if err := s.indexer.Reindex(ctx, facility); err != nil {
+		log.Printf("Warning: Failed to reindex facility %s after rating change: %v", facilityID, err)
+	}
+}
+
+// reviewerDisplayName shows the first name and last initial, e.g. "Ada O."
+func reviewerDisplayName(fullName string) string {
+	parts := strings.Fields(fullName)
+	switch len(parts) {
+	case 0:
+		return "Verified patient"
+	case 1:
+		return parts[0]
+	default:
+		last, _ := utf8.DecodeRuneInString(parts[len(parts)-1])
+		return parts[0] + " " + strings.ToUpper(string(last)) + "."
+	}
+}
+
+func clampPage(limit, offset, defaultLimit, maxLimit int) (int, int) {
+	if limit <= 0 {
+		limit = defaultLimit
+	}
+	if limit > maxLimit {
+		limit = maxLimit
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return limit, offset
+}
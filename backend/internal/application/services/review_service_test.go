@@ -0,0 +1,381 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/providers"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/repositories"
+	apperrors "github.com/zatekoja/Patientpricediscoverydesign/backend/pkg/errors"
+)
+
+type stubReviewRepo struct {
+	reviews map[string]*entities.Review
+	updates int
+}
+
+func newStubReviewRepo(reviews ...*entities.Review) *stubReviewRepo {
+	repo := &stubReviewRepo{reviews: map[string]*entities.Review{}}
+	for _, review := range reviews {
+		repo.reviews[review.ID] = review
+	}
+	return repo
+}
+
+func (r *stubReviewRepo) Create(ctx context.Context, review *entities.Review) error {
+	review.ID = "review-new"
+	r.reviews[review.ID] = review
+	return nil
+}
+
+func (r *stubReviewRepo) GetByID(ctx context.Context, id string) (*entities.Review, error) {
+	if review, ok := r.reviews[id]; ok {
+		copied := *review
+		return &copied, nil
+	}
+	return nil, apperrors.NewNotFoundError("review not found")
+}
+
+func (r *stubReviewRepo) GetByAppointmentID(ctx context.Context, appointmentID string) (*entities.Review, error) {
+	for _, review := range r.reviews {
+		if review.AppointmentID == appointmentID {
+			return review, nil
+		}
+	}
+	return nil, apperrors.NewNotFoundError("review not found")
+}
+
+func (r *stubReviewRepo) ListByFacility(ctx context.Context, facilityID string, limit, offset int) ([]*entities.Review, error) {
+	return nil, nil
+}
+
+func (r *stubReviewRepo) ListByStatus(ctx context.Context, status entities.ReviewStatus, limit, offset int) ([]*entities.Review, error) {
+	return nil, nil
+}
+
+func (r *stubReviewRepo) ListByUser(ctx context.Context, userID string, limit, offset int) ([]*entities.Review, error) {
+	return nil, nil
+}
+
+func (r *stubReviewRepo) Update(ctx context.Context, review *entities.Review) error {
+	r.updates++
+	r.reviews[review.ID] = review
+	return nil
+}
+
+func (r *stubReviewRepo) Delete(ctx context.Context, id string) error {
+	delete(r.reviews, id)
+	return nil
+}
+
+type stubReviewAppointments struct {
+	appointments map[string]*entities.Appointment
+}
+
+func (r *stubReviewAppointments) Create(ctx context.Context, appointment *entities.Appointment) error {
+	copied := *appointment
+	r.appointments[appointment.ID] = &copied
+	return nil
+}
+
+func (r *stubReviewAppointments) GetByID(ctx context.Context, id string) (*entities.Appointment, error) {
+	if appointment, ok := r.appointments[id]; ok {
+		copied := *appointment
+		return &copied, nil
+	}
+	return nil, apperrors.NewNotFoundError("appointment not found")
+}
+
+func (r *stubReviewAppointments) Update(ctx context.Context, appointment *entities.Appointment) error {
+	copied := *appointment
+	r.appointments[appointment.ID] = &copied
+	return nil
+}
+
+func (r *stubReviewAppointments) Cancel(ctx context.Context, id string) error {
+	return nil
+}
+
+func (r *stubReviewAppointments) ListByUser(ctx context.Context, userID string, filter repositories.AppointmentFilter) ([]*entities.Appointment, error) {
+	return nil, nil
+}
+
+func (r *stubReviewAppointments) ListByFacility(ctx context.Context, facilityID string, filter repositories.AppointmentFilter) ([]*entities.Appointment, error) {
+	return nil, nil
+}
+
+func (r *stubReviewAppointments) LinkToUserByPhone(ctx context.Context, userID, phone string) (int, error) {
+	return 0, nil
+}
+
+// stubReviewFacilities only implements GetByID; the review service uses nothing else.
+type stubReviewFacilities struct {
+	repositories.FacilityRepository
+	facility *entities.Facility
+}
+
+func (r *stubReviewFacilities) GetByID(ctx context.Context, id string) (*entities.Facility, error) {
+	if r.facility == nil || r.facility.ID != id {
+		return nil, apperrors.NewNotFoundError("facility not found")
+	}
+	return r.facility, nil
+}
+
+type recordingReindexer struct {
+	reindexed []string
+}
+
+func (r *recordingReindexer) Reindex(ctx context.Context, facility *entities.Facility) error {
+	r.reindexed = append(r.reindexed, facility.ID)
+	return nil
+}
+
+func newReviewServiceFixture(reviews ...*entities.Review) (*ReviewService, *stubReviewRepo, *recordingReindexer) {
+	repo := newStubReviewRepo(reviews...)
+	appointments := &stubReviewAppointments{appointments: map[string]*entities.Appointment{
+		"appt-done": {
+			ID:           "appt-done",
+			FacilityID:   "fac-1",
+			PatientName:  "Ada Okafor",
+			PatientEmail: "ada@example.com",
+			Status:       entities.AppointmentStatusCompleted,
+		},
+		"appt-no-email": {
+			ID:          "appt-no-email",
+			FacilityID:  "fac-1",
+			PatientName: "Ada Okafor",
+			Status:      entities.AppointmentStatusCompleted,
+		},
+		"appt-upcoming": {
+			ID:           "appt-upcoming",
+			FacilityID:   "fac-1",
+			PatientName:  "Ada Okafor",
+			PatientEmail: "ada@example.com",
+			Status:       entities.AppointmentStatusConfirmed,
+		},
+	}}
+	facilities := &stubReviewFacilities{facility: &entities.Facility{ID: "fac-1", ReviewCount: 3}}
+	indexer := &recordingReindexer{}
+
+	service := NewReviewService(repo, appointments, facilities)
+	service.SetReindexer(indexer)
+	service.now = func() time.Time { return time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC) }
+	return service, repo, indexer
+}
+
+func TestReviewService_SubmitCreatesPendingReview(t *testing.T) {
+	service, _, indexer := newReviewServiceFixture()
+
+	review, err := service.Submit(context.Background(), SubmitReviewInput{
+		AppointmentID: "appt-done",
+		PatientEmail:  " ADA@example.com ",
+		Rating:        4,
+		Comment:       "Quick and friendly. Call me on +234 803 123 4567 or ada@example.com",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, entities.ReviewStatusPending, review.Status)
+	assert.Equal(t, "fac-1", review.FacilityID)
+	assert.Equal(t, "appt-done", review.AppointmentID)
+	assert.Equal(t, "Ada O.", review.ReviewerName)
+	assert.Equal(t, "Quick and friendly. Call me on [redacted] or [redacted]", review.Comment)
+	assert.Equal(t, []string{entities.ReviewFlagPIIRedacted}, review.ModerationFlags)
+	assert.Empty(t, indexer.reindexed, "pending reviews do not affect the rating")
+}
+
+func TestReviewService_SubmitFlagsProfanity(t *testing.T) {
+	service, _, _ := newReviewServiceFixture()
+
+	review, err := service.Submit(context.Background(), SubmitReviewInput{
+		AppointmentID: "appt-done",
+		PatientEmail:  "ada@example.com",
+		Rating:        1,
+		Comment:       "The nurse was a total MUMU",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{entities.ReviewFlagProfanity}, review.ModerationFlags)
+}
+
+func TestReviewService_SubmitRejectsInvalidInput(t *testing.T) {
+	duplicate := &entities.Review{ID: "review-1", FacilityID: "fac-1", AppointmentID: "appt-done", Status: entities.ReviewStatusPending}
+
+	tests := []struct {
+		name    string
+		input   SubmitReviewInput
+		want    apperrors.ErrorType
+		reviews []*entities.Review
+	}{
+		{
+			name:  "missing appointment",
+			input: SubmitReviewInput{PatientEmail: "ada@example.com", Rating: 4},
+			want:  apperrors.ErrorTypeValidation,
+		},
+		{
+			name:  "rating out of range",
+			input: SubmitReviewInput{AppointmentID: "appt-done", PatientEmail: "ada@example.com", Rating: 6},
+			want:  apperrors.ErrorTypeValidation,
+		},
+		{
+			name:  "comment too long",
+			input: SubmitReviewInput{AppointmentID: "appt-done", PatientEmail: "ada@example.com", Rating: 4, Comment: strings.Repeat("a", maxReviewLength+1)},
+			want:  apperrors.ErrorTypeValidation,
+		},
+		{
+			name:  "email does not match appointment",
+			input: SubmitReviewInput{AppointmentID: "appt-done", PatientEmail: "someone@example.com", Rating: 4},
+			want:  apperrors.ErrorTypeNotFound,
+		},
+		{
+			name:  "missing email on an appointment booked without one",
+			input: SubmitReviewInput{AppointmentID: "appt-no-email", Rating: 4},
+			want:  apperrors.ErrorTypeValidation,
+		},
+		{
+			name:  "appointment not completed",
+			input: SubmitReviewInput{AppointmentID: "appt-upcoming", PatientEmail: "ada@example.com", Rating: 4},
+			want:  apperrors.ErrorTypeValidation,
+		},
+		{
+			name:    "appointment already reviewed",
+			input:   SubmitReviewInput{AppointmentID: "appt-done", PatientEmail: "ada@example.com", Rating: 4},
+			want:    apperrors.ErrorTypeConflict,
+			reviews: []*entities.Review{duplicate},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, _, _ := newReviewServiceFixture(tt.reviews...)
+			_, err := service.Submit(context.Background(), tt.input)
+			requireAppErrorType(t, err, tt.want, tt.name)
+		})
+	}
+}
+
+// visitProvider books a native slot whose visit has already taken place.
+type visitProvider struct{}
+
+func (visitProvider) GetAvailableSlots(ctx context.Context, externalID string, from, to time.Time) ([]entities.AvailabilitySlot, error) {
+	return nil, nil
+}
+
+func (visitProvider) CreateAppointment(ctx context.Context, appointment *entities.Appointment) (string, string, error) {
+	appointment.ScheduledAt = time.Now().Add(-time.Hour)
+	return "booking-1", "", nil
+}
+
+func (visitProvider) CancelAppointment(ctx context.Context, externalID, reason string) error {
+	return nil
+}
+
+type nativeResolver struct{}
+
+func (nativeResolver) Resolve(ctx context.Context, facility *entities.Facility) (*providers.SchedulingTarget, error) {
+	return &providers.SchedulingTarget{Provider: visitProvider{}, ExternalID: facility.ID, Method: entities.BookingMethodNative}, nil
+}
+
+func TestReviewService_BookCompleteThenReview(t *testing.T) {
+	service, _, _ := newReviewServiceFixture()
+	facilities := &stubReviewFacilities{facility: &entities.Facility{ID: "fac-1"}}
+	appointmentService := NewAppointmentService(service.appointments, facilities, nil, visitProvider{}, false, nil)
+	appointmentService.SetProviderResolver(nativeResolver{})
+	ctx := context.Background()
+
+	appointment := &entities.Appointment{ID: "appt-new", FacilityID: "fac-1", SlotID: "slot-1", PatientName: "Ada Okafor", PatientEmail: "ada@example.com"}
+	require.NoError(t, appointmentService.BookAppointment(ctx, appointment))
+	require.Equal(t, entities.AppointmentStatusConfirmed, appointment.Status)
+
+	input := SubmitReviewInput{AppointmentID: "appt-new", PatientEmail: "ada@example.com", Rating: 5}
+	_, err := service.Submit(ctx, input)
+	requireAppErrorType(t, err, apperrors.ErrorTypeValidation, "review before completion")
+
+	completed, err := appointmentService.CompleteAppointment(ctx, "appt-new")
+	require.NoError(t, err)
+	assert.Equal(t, entities.AppointmentStatusCompleted, completed.Status)
+
+	review, err := service.Submit(ctx, input)
+	require.NoError(t, err)
+	assert.Equal(t, "appt-new", review.AppointmentID)
+	assert.Equal(t, entities.ReviewStatusPending, review.Status)
+}
+
+func TestReviewService_ModeratePublishReindexesFacility(t *testing.T) {
+	service, repo, indexer := newReviewServiceFixture(&entities.Review{
+		ID: "review-1", FacilityID: "fac-1", Rating: 5, Status: entities.ReviewStatusPending,
+	})
+
+	review, err := service.Moderate(context.Background(), "review-1", entities.ReviewStatusPublished, " looks fine ")
+	require.NoError(t, err)
+
+	assert.Equal(t, entities.ReviewStatusPublished, review.Status)
+	assert.Equal(t, "looks fine", review.ModerationNote)
+	require.NotNil(t, review.ModeratedAt)
+	assert.Equal(t, 1, repo.updates)
+	assert.Equal(t, []string{"fac-1"}, indexer.reindexed)
+}
+
+func TestReviewService_ModerateRejectDoesNotReindex(t *testing.T) {
+	service, _, indexer := newReviewServiceFixture(&entities.Review{
+		ID: "review-1", FacilityID: "fac-1", Rating: 1, Status: entities.ReviewStatusPending,
+	})
+
+	_, err := service.Moderate(context.Background(), "review-1", entities.ReviewStatusRejected, "abusive")
+	require.NoError(t, err)
+	assert.Empty(t, indexer.reindexed)
+}
+
+func TestReviewService_ModerateRejectsInvalidTransition(t *testing.T) {
+	service, repo, _ := newReviewServiceFixture(&entities.Review{
+		ID: "review-1", FacilityID: "fac-1", Rating: 1, Status: entities.ReviewStatusRejected,
+	})
+
+	_, err := service.Moderate(context.Background(), "review-1", entities.ReviewStatusHidden, "")
+	requireAppErrorType(t, err, apperrors.ErrorTypeConflict, "rejected reviews cannot be hidden")
+
+	_, err = service.Moderate(context.Background(), "review-1", entities.ReviewStatus("archived"), "")
+	requireAppErrorType(t, err, apperrors.ErrorTypeValidation, "unknown status")
+	assert.Zero(t, repo.updates)
+}
+
+func TestReviewService_Respond(t *testing.T) {
+	service, _, _ := newReviewServiceFixture(
+		&entities.Review{ID: "review-1", FacilityID: "fac-1", Status: entities.ReviewStatusPublished},
+		&entities.Review{ID: "review-2", FacilityID: "fac-1", Status: entities.ReviewStatusRejected},
+	)
+
+	review, err := service.Respond(context.Background(), "review-1", "  Thank you for visiting.  ")
+	require.NoError(t, err)
+	assert.Equal(t, "Thank you for visiting.", review.FacilityResponse)
+	require.NotNil(t, review.RespondedAt)
+
+	_, err = service.Respond(context.Background(), "review-1", " ")
+	requireAppErrorType(t, err, apperrors.ErrorTypeValidation, "empty response")
+
+	_, err = service.Respond(context.Background(), "review-1", "What crap")
+	requireAppErrorType(t, err, apperrors.ErrorTypeValidation, "profane response")
+
+	_, err = service.Respond(context.Background(), "review-2", "Sorry to hear that.")
+	requireAppErrorType(t, err, apperrors.ErrorTypeConflict, "rejected review")
+}
+
+func TestReviewService_ModerationQueueDefaultsToPending(t *testing.T) {
+	service, _, _ := newReviewServiceFixture()
+
+	_, err := service.ModerationQueue(context.Background(), "", 0, 0)
+	require.NoError(t, err)
+
+	_, err = service.ModerationQueue(context.Background(), entities.ReviewStatus("archived"), 0, 0)
+	requireAppErrorType(t, err, apperrors.ErrorTypeValidation, "unknown status")
+}
+
+func TestReviewerDisplayName(t *testing.T) {
+	assert.Equal(t, "Ada O.", reviewerDisplayName("Ada Okafor"))
+	assert.Equal(t, "Chinedu E.", reviewerDisplayName("Chinedu Nnamdi ezeh"))
+	assert.Equal(t, "Bola", reviewerDisplayName(" Bola "))
+	assert.Equal(t, "Verified patient", reviewerDisplayName(""))
+}
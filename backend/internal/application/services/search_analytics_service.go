@@ -8,12 +8,17 @@ import (
 	"time"
 )
 
+// cachedImpressionCopyAttempts is how many times a cached impression's search
+// is looked for before the impression is logged on its own
+const cachedImpressionCopyAttempts = 3
+
 type SearchAnalyticsService struct {
-	repo repositories.SearchAnalyticsRepository
+	repo           repositories.SearchAnalyticsRepository
+	copyRetryDelay time.Duration
 }
 
 func NewSearchAnalyticsService(repo repositories.SearchAnalyticsRepository) *SearchAnalyticsService {
-	return &SearchAnalyticsService{repo: repo}
+	return &SearchAnalyticsService{repo: repo, copyRetryDelay: 500 * time.Millisecond}
 }
 
 func (s *SearchAnalyticsService) TrackSearch(ctx context.Context, event *entities.SearchEvent) {
@@ -29,6 +34,37 @@ func (s *SearchAnalyticsService) TrackSearch(ctx context.Context, event *entitie
 	}()
 }
 
+// TrackCachedImpression logs a search response served from cache as a copy of
+// the search it was cached from, under the impression ID the client was given,
+// so clicks on cached results still join to a search event. Searches are
+// logged in the background, so the copy is retried briefly; if the search was
+// never logged, impression, the response as served, is logged on its own.
+func (s *SearchAnalyticsService) TrackCachedImpression(ctx context.Context, searchID string, impression *entities.SearchEvent) {
+	go func() {
+		bgCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+		defer cancel()
+
+		for attempt := 0; attempt < cachedImpressionCopyAttempts; attempt++ {
+			if attempt > 0 {
+				time.Sleep(s.copyRetryDelay)
+			}
+			copied, err := s.repo.CopyEvent(bgCtx, searchID, impression.ID)
+			if err != nil {
+				log.Printf("Warning: failed to log cached search impression: %v", err)
+				return
+			}
+			if copied {
+				return
+			}
+		}
+
+		log.Printf("Warning: cached search %s was never logged; logging impression %s on its own", searchID, impression.ID)
+		if err := s.repo.LogEvent(bgCtx, impression); err != nil {
+			log.Printf("Warning: failed to log cached search impression: %v", err)
+		}
+	}()
+}
+
 func (s *SearchAnalyticsService) GetZeroResultQueries(ctx context.Context, limit int) ([]*entities.SearchEvent, error) {
 	return s.repo.GetZeroResultQueries(ctx, limit)
 }
@@ -0,0 +1,73 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/repositories"
+)
+
+// stubCopyAnalyticsRepo finds the source search once it has been looked for
+// loggedAfter times, and reports every event logged
+type stubCopyAnalyticsRepo struct {
+	repositories.SearchAnalyticsRepository
+	loggedAfter int
+	copies      int
+	copied      chan string
+	logged      chan *entities.SearchEvent
+}
+
+func (r *stubCopyAnalyticsRepo) CopyEvent(ctx context.Context, sourceID, newID string) (bool, error) {
+	r.copies++
+	if r.loggedAfter > 0 && r.copies >= r.loggedAfter {
+		r.copied <- newID
+		return true, nil
+	}
+	return false, nil
+}
+
+func (r *stubCopyAnalyticsRepo) LogEvent(ctx context.Context, event *entities.SearchEvent) error {
+	r.logged <- event
+	return nil
+}
+
+func TestTrackCachedImpression_RetriesTheCopyWhileTheSearchIsBeingLogged(t *testing.T) {
+	repo := &stubCopyAnalyticsRepo{loggedAfter: 2, copied: make(chan string, 1), logged: make(chan *entities.SearchEvent, 1)}
+	svc := NewSearchAnalyticsService(repo)
+	svc.copyRetryDelay = 0
+
+	svc.TrackCachedImpression(context.Background(), "search-1", &entities.SearchEvent{ID: "impression-1", Query: "malaria"})
+
+	select {
+	case id := <-repo.copied:
+		if id != "impression-1" {
+			t.Fatalf("expected the copy under the impression ID, got %q", id)
+		}
+	case event := <-repo.logged:
+		t.Fatalf("expected the search copied, not logged on its own: %+v", event)
+	case <-time.After(time.Second):
+		t.Fatal("expected the cached impression to be copied")
+	}
+}
+
+func TestTrackCachedImpression_LogsTheImpressionWhenTheSearchWasNeverLogged(t *testing.T) {
+	repo := &stubCopyAnalyticsRepo{copied: make(chan string, 1), logged: make(chan *entities.SearchEvent, 1)}
+	svc := NewSearchAnalyticsService(repo)
+	svc.copyRetryDelay = 0
+
+	svc.TrackCachedImpression(context.Background(), "search-1", &entities.SearchEvent{ID: "impression-1", Query: "malaria", ResultCount: 7})
+
+	select {
+	case event := <-repo.logged:
+		if event.ID != "impression-1" || event.Query != "malaria" || event.ResultCount != 7 {
+			t.Fatalf("expected the served impression logged, got %+v", event)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the impression to be logged on its own")
+	}
+	if repo.copies != cachedImpressionCopyAttempts {
+		t.Fatalf("expected %d copy attempts, got %d", cachedImpressionCopyAttempts, repo.copies)
+	}
+}
@@ -0,0 +1,344 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/repositories"
+	apperrors "github.com/zatekoja/Patientpricediscoverydesign/backend/pkg/errors"
+)
+
+// ExperimentAssignment is the variant a session was bucketed into.
+type ExperimentAssignment struct {
+	ExperimentID   string
+	ExperimentName string
+	Variant        string
+	Config         entities.ExperimentVariantConfig
+}
+
+// SearchExperimentService manages search A/B experiments and assigns sessions
+// to variants. Only one experiment runs at a time so variant configurations
+// never conflict; the running experiment is cached in memory for the search path
+// and reloaded by WatchRunning, so starts and stops reach every replica.
+type SearchExperimentService struct {
+	repo          repositories.SearchExperimentRepository
+	analyticsRepo repositories.SearchAnalyticsRepository
+	audit         *AuditService
+	now           func() time.Time
+
+	mu      sync.RWMutex
+	running *entities.SearchExperiment
+}
+
+// NewSearchExperimentService creates a new search experiment service.
+func NewSearchExperimentService(repo repositories.SearchExperimentRepository, analyticsRepo repositories.SearchAnalyticsRepository) *SearchExperimentService {
+	return &SearchExperimentService{
+		repo:          repo,
+		analyticsRepo: analyticsRepo,
+		now:           time.Now,
+	}
+}
+
+// SetAuditLog records experiment creation, starts and stops in the audit log.
+func (s *SearchExperimentService) SetAuditLog(audit *AuditService) {
+	s.audit = audit
+}
+
+// LoadRunning caches the currently running experiment, if any.
+func (s *SearchExperimentService) LoadRunning(ctx context.Context) error {
+	running, err := s.findRunning(ctx)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.running = running
+	s.mu.Unlock()
+	return nil
+}
+
+// WatchRunning reloads the running experiment every interval until ctx is
+// done, picking up experiments started or stopped through other replicas.
+func (s *SearchExperimentService) WatchRunning(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := s.LoadRunning(ctx); err != nil {
+					log.Printf("Failed to reload running search experiment: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// findRunning reads the running experiment from the store rather than the
+// cache, which may lag behind other replicas.
+func (s *SearchExperimentService) findRunning(ctx context.Context) (*entities.SearchExperiment, error) {
+	experiments, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range experiments {
+		if e.Status == entities.ExperimentStatusRunning {
+			return e, nil
+		}
+	}
+	return nil, nil
+}
+
+// Create validates and stores a new draft experiment.
+func (s *SearchExperimentService) Create(ctx context.Context, experiment *entities.SearchExperiment) error {
+	experiment.Name = strings.TrimSpace(experiment.Name)
+	if experiment.TrafficPercent == 0 {
+		experiment.TrafficPercent = 100
+	}
+	if err := validateExperiment(experiment); err != nil {
+		return err
+	}
+	experiment.Status = entities.ExperimentStatusDraft
+	experiment.StartedAt = nil
+	experiment.StoppedAt = nil
+	return s.audit.Transact(ctx, func(ctx context.Context) (*AuditEvent, error) {
+		if err := s.repo.Create(ctx, experiment); err != nil {
+			return nil, err
+		}
+		return &AuditEvent{
+			Action:     "experiment.create",
+			EntityType: "search_experiment",
+			EntityID:   experiment.ID,
+			After:      experiment,
+		}, nil
+	})
+}
+
+// Get returns an experiment by ID.
+func (s *SearchExperimentService) Get(ctx context.Context, id string) (*entities.SearchExperiment, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+// List returns all experiments.
+func (s *SearchExperimentService) List(ctx context.Context) ([]*entities.SearchExperiment, error) {
+	return s.repo.List(ctx)
+}
+
+// Start begins routing traffic to a draft experiment.
+func (s *SearchExperimentService) Start(ctx context.Context, id string) (*entities.SearchExperiment, error) {
+	experiment, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if experiment.Status != entities.ExperimentStatusDraft {
+		return nil, apperrors.NewConflictError(fmt.Sprintf("experiment is %s; only draft experiments can be started", experiment.Status))
+	}
+
+	running, err := s.findRunning(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if running != nil && running.ID != id {
+		return nil, apperrors.NewConflictError(fmt.Sprintf("experiment %q is already running", running.Name))
+	}
+
+	before := *experiment
+	now := s.now().UTC()
+	experiment.Status = entities.ExperimentStatusRunning
+	experiment.StartedAt = &now
+	if err := s.audit.Transact(ctx, func(ctx context.Context) (*AuditEvent, error) {
+		if err := s.repo.Update(ctx, experiment); err != nil {
+			return nil, err
+		}
+		return &AuditEvent{
+			Action:     "experiment.start",
+			EntityType: "search_experiment",
+			EntityID:   experiment.ID,
+			Before:     &before,
+			After:      experiment,
+		}, nil
+	}); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.running = experiment
+	s.mu.Unlock()
+	return experiment, nil
+}
+
+// Stop ends a running experiment. Stopped experiments cannot be restarted so
+// their reports stay comparable.
+func (s *SearchExperimentService) Stop(ctx context.Context, id string) (*entities.SearchExperiment, error) {
+	experiment, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if experiment.Status != entities.ExperimentStatusRunning {
+		return nil, apperrors.NewConflictError(fmt.Sprintf("experiment is %s, not running", experiment.Status))
+	}
+
+	before := *experiment
+	now := s.now().UTC()
+	experiment.Status = entities.ExperimentStatusStopped
+	experiment.StoppedAt = &now
+	if err := s.audit.Transact(ctx, func(ctx context.Context) (*AuditEvent, error) {
+		if err := s.repo.Update(ctx, experiment); err != nil {
+			return nil, err
+		}
+		return &AuditEvent{
+			Action:     "experiment.stop",
+			EntityType: "search_experiment",
+			EntityID:   experiment.ID,
+			Before:     &before,
+			After:      experiment,
+		}, nil
+	}); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	if s.running != nil && s.running.ID == id {
+		s.running = nil
+	}
+	s.mu.Unlock()
+	return experiment, nil
+}
+
+// Assign buckets a session into the running experiment. It returns nil when no
+// experiment is running, the session is anonymous, or the session falls
+// outside the experiment's traffic allocation.
+func (s *SearchExperimentService) Assign(sessionID string) *ExperimentAssignment {
+	sessionID = strings.TrimSpace(sessionID)
+	if sessionID == "" {
+		return nil
+	}
+	s.mu.RLock()
+	experiment := s.running
+	s.mu.RUnlock()
+	if experiment == nil {
+		return nil
+	}
+
+	variant := assignVariant(experiment, sessionID)
+	if variant == nil {
+		return nil
+	}
+	return &ExperimentAssignment{
+		ExperimentID:   experiment.ID,
+		ExperimentName: experiment.Name,
+		Variant:        variant.Name,
+		Config:         variant.Config,
+	}
+}
+
+// Report compares zero-result rate, click-through and latency across variants.
+func (s *SearchExperimentService) Report(ctx context.Context, id string) (*entities.ExperimentReport, error) {
+	experiment, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	stats, err := s.analyticsRepo.GetExperimentVariantStats(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Include variants that have not received traffic yet, in definition order.
+	byVariant := make(map[string]*entities.ExperimentVariantStats, len(stats))
+	for _, st := range stats {
+		byVariant[st.Variant] = st
+	}
+	variants := make([]*entities.ExperimentVariantStats, 0, len(experiment.Variants))
+	for _, v := range experiment.Variants {
+		if st, ok := byVariant[v.Name]; ok {
+			variants = append(variants, st)
+		} else {
+			variants = append(variants, &entities.ExperimentVariantStats{Variant: v.Name})
+		}
+	}
+
+	return &entities.ExperimentReport{
+		Experiment:  experiment,
+		Variants:    variants,
+		GeneratedAt: s.now().UTC(),
+	}, nil
+}
+
+// assignVariant deterministically maps a session to a variant. Enrollment and
+// variant choice use independent hashes so changing the traffic percentage does
+// not reshuffle sessions that were already enrolled.
+func assignVariant(experiment *entities.SearchExperiment, sessionID string) *entities.ExperimentVariant {
+	if experiment.TrafficPercent < 100 &&
+		bucket(experiment.ID+":traffic:"+sessionID, 100) >= uint32(experiment.TrafficPercent) {
+		return nil
+	}
+
+	total := 0
+	for _, v := range experiment.Variants {
+		total += v.Weight
+	}
+	if total <= 0 {
+		return nil
+	}
+
+	b := int(bucket(experiment.ID+":variant:"+sessionID, uint32(total)))
+	for i := range experiment.Variants {
+		b -= experiment.Variants[i].Weight
+		if b < 0 {
+			return &experiment.Variants[i]
+		}
+	}
+	return nil
+}
+
+func bucket(key string, n uint32) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return h.Sum32() % n
+}
+
+func validateExperiment(e *entities.SearchExperiment) error {
+	if e.Name == "" {
+		return apperrors.NewValidationError("name is required")
+	}
+	if e.TrafficPercent < 0 || e.TrafficPercent > 100 {
+		return apperrors.NewValidationError("traffic_percent must be between 0 and 100")
+	}
+	if len(e.Variants) < 2 {
+		return apperrors.NewValidationError("an experiment needs at least two variants")
+	}
+
+	seen := make(map[string]struct{}, len(e.Variants))
+	for i := range e.Variants {
+		v := &e.Variants[i]
+		v.Name = strings.TrimSpace(v.Name)
+		if v.Name == "" {
+			return apperrors.NewValidationError(fmt.Sprintf("variant %d: name is required", i))
+		}
+		if _, dup := seen[v.Name]; dup {
+			return apperrors.NewValidationError(fmt.Sprintf("duplicate variant name %q", v.Name))
+		}
+		seen[v.Name] = struct{}{}
+		if v.Weight <= 0 {
+			return apperrors.NewValidationError(fmt.Sprintf("variant %q: weight must be positive", v.Name))
+		}
+		if w := v.Config.RankingWeights; w != nil && (negativeWeight(w.Lexical) || negativeWeight(w.Concept) || negativeWeight(w.Geo) || negativeWeight(w.Specialty)) {
+			return apperrors.NewValidationError(fmt.Sprintf("variant %q: ranking weights must not be negative", v.Name))
+		}
+		if n := v.Config.MaxExpansionTerms; n != nil && *n <= 0 {
+			return apperrors.NewValidationError(fmt.Sprintf("variant %q: max_expansion_terms must be positive", v.Name))
+		}
+	}
+	return nil
+}
+
+func negativeWeight(w *float64) bool {
+	return w != nil && *w < 0
+}
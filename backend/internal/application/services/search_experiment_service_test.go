@@ -0,0 +1,276 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/repositories"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/evaluation"
+	apperrors "github.com/zatekoja/Patientpricediscoverydesign/backend/pkg/errors"
+)
+
+// stubExperimentRepo stores copies, like a database, and is safe for the
+// concurrent reads of WatchRunning
+type stubExperimentRepo struct {
+	mu          sync.Mutex
+	experiments map[string]*entities.SearchExperiment
+}
+
+func newStubExperimentRepo() *stubExperimentRepo {
+	return &stubExperimentRepo{experiments: make(map[string]*entities.SearchExperiment)}
+}
+
+func (r *stubExperimentRepo) Create(ctx context.Context, e *entities.SearchExperiment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if e.ID == "" {
+		e.ID = fmt.Sprintf("exp-%d", len(r.experiments)+1)
+	}
+	copied := *e
+	r.experiments[e.ID] = &copied
+	return nil
+}
+
+func (r *stubExperimentRepo) Update(ctx context.Context, e *entities.SearchExperiment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	copied := *e
+	r.experiments[e.ID] = &copied
+	return nil
+}
+
+func (r *stubExperimentRepo) GetByID(ctx context.Context, id string) (*entities.SearchExperiment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if e, ok := r.experiments[id]; ok {
+		copied := *e
+		return &copied, nil
+	}
+	return nil, apperrors.NewNotFoundError("experiment not found")
+}
+
+func (r *stubExperimentRepo) List(ctx context.Context) ([]*entities.SearchExperiment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []*entities.SearchExperiment
+	for _, e := range r.experiments {
+		copied := *e
+		out = append(out, &copied)
+	}
+	return out, nil
+}
+
+var _ repositories.SearchExperimentRepository = (*stubExperimentRepo)(nil)
+
+func twoVariantExperiment(name string) *entities.SearchExperiment {
+	off := false
+	return &entities.SearchExperiment{
+		Name: name,
+		Variants: []entities.ExperimentVariant{
+			{Name: "control", Weight: 50},
+			{Name: "no_contextual", Weight: 50, Config: entities.ExperimentVariantConfig{ContextualSearch: &off}},
+		},
+	}
+}
+
+func startedExperiment(t *testing.T, svc *SearchExperimentService, e *entities.SearchExperiment) *entities.SearchExperiment {
+	t.Helper()
+	require.NoError(t, svc.Create(context.Background(), e))
+	started, err := svc.Start(context.Background(), e.ID)
+	require.NoError(t, err)
+	return started
+}
+
+func TestSearchExperiment_CreateValidation(t *testing.T) {
+	svc := NewSearchExperimentService(newStubExperimentRepo(), &stubTriageAnalyticsRepo{})
+	zero := 0
+
+	cases := []*entities.SearchExperiment{
+		{Name: "", Variants: twoVariantExperiment("x").Variants},
+		{Name: "one-arm", Variants: []entities.ExperimentVariant{{Name: "a", Weight: 1}}},
+		{Name: "dup", Variants: []entities.ExperimentVariant{{Name: "a", Weight: 1}, {Name: "a", Weight: 1}}},
+		{Name: "weight", Variants: []entities.ExperimentVariant{{Name: "a", Weight: 0}, {Name: "b", Weight: 1}}},
+		{Name: "traffic", TrafficPercent: 120, Variants: twoVariantExperiment("x").Variants},
+		{Name: "expansion", Variants: []entities.ExperimentVariant{
+			{Name: "a", Weight: 1}, {Name: "b", Weight: 1, Config: entities.ExperimentVariantConfig{MaxExpansionTerms: &zero}},
+		}},
+	}
+	for _, e := range cases {
+		err := svc.Create(context.Background(), e)
+		assert.Error(t, err, e.Name)
+	}
+
+	e := twoVariantExperiment("valid")
+	require.NoError(t, svc.Create(context.Background(), e))
+	assert.Equal(t, entities.ExperimentStatusDraft, e.Status)
+	assert.Equal(t, 100, e.TrafficPercent)
+}
+
+func TestSearchExperiment_AssignIsDeterministicAndWeighted(t *testing.T) {
+	svc := NewSearchExperimentService(newStubExperimentRepo(), &stubTriageAnalyticsRepo{})
+	e := twoVariantExperiment("ranking-test")
+	e.Variants[0].Weight = 80
+	e.Variants[1].Weight = 20
+	startedExperiment(t, svc, e)
+
+	assert.Nil(t, svc.Assign(""), "anonymous sessions are not enrolled")
+
+	first := svc.Assign("session-42")
+	require.NotNil(t, first)
+	for i := 0; i < 5; i++ {
+		assert.Equal(t, first.Variant, svc.Assign("session-42").Variant)
+	}
+	assert.Equal(t, e.ID, first.ExperimentID)
+
+	counts := make(map[string]int)
+	for i := 0; i < 5000; i++ {
+		counts[svc.Assign(fmt.Sprintf("s-%d", i)).Variant]++
+	}
+	assert.InDelta(t, 0.8, float64(counts["control"])/5000, 0.03)
+	assert.InDelta(t, 0.2, float64(counts["no_contextual"])/5000, 0.03)
+}
+
+func TestSearchExperiment_TrafficAllocation(t *testing.T) {
+	svc := NewSearchExperimentService(newStubExperimentRepo(), &stubTriageAnalyticsRepo{})
+	e := twoVariantExperiment("partial")
+	e.TrafficPercent = 10
+	startedExperiment(t, svc, e)
+
+	enrolled := 0
+	for i := 0; i < 5000; i++ {
+		if svc.Assign(fmt.Sprintf("s-%d", i)) != nil {
+			enrolled++
+		}
+	}
+	assert.InDelta(t, 0.1, float64(enrolled)/5000, 0.02)
+}
+
+func TestSearchExperiment_OnlyOneRunning(t *testing.T) {
+	svc := NewSearchExperimentService(newStubExperimentRepo(), &stubTriageAnalyticsRepo{})
+	first := startedExperiment(t, svc, twoVariantExperiment("first"))
+
+	second := twoVariantExperiment("second")
+	require.NoError(t, svc.Create(context.Background(), second))
+	_, err := svc.Start(context.Background(), second.ID)
+	var appErr *apperrors.AppError
+	require.ErrorAs(t, err, &appErr)
+	assert.Equal(t, apperrors.ErrorTypeConflict, appErr.Type)
+
+	stopped, err := svc.Stop(context.Background(), first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, entities.ExperimentStatusStopped, stopped.Status)
+	assert.Nil(t, svc.Assign("session-1"))
+
+	_, err = svc.Start(context.Background(), first.ID)
+	assert.Error(t, err, "stopped experiments cannot restart")
+	_, err = svc.Start(context.Background(), second.ID)
+	assert.NoError(t, err)
+}
+
+func TestSearchExperiment_LoadRunning(t *testing.T) {
+	repo := newStubExperimentRepo()
+	e := twoVariantExperiment("persisted")
+	e.ID = "exp-1"
+	e.Status = entities.ExperimentStatusRunning
+	e.TrafficPercent = 100
+	repo.experiments[e.ID] = e
+
+	svc := NewSearchExperimentService(repo, &stubTriageAnalyticsRepo{})
+	assert.Nil(t, svc.Assign("s"))
+	require.NoError(t, svc.LoadRunning(context.Background()))
+	assert.NotNil(t, svc.Assign("s"))
+}
+
+func TestSearchExperiment_StartsAndStopsReachOtherReplicas(t *testing.T) {
+	repo := newStubExperimentRepo()
+	admin := NewSearchExperimentService(repo, &stubTriageAnalyticsRepo{})
+	replica := NewSearchExperimentService(repo, &stubTriageAnalyticsRepo{})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	replica.WatchRunning(ctx, 5*time.Millisecond)
+
+	first := startedExperiment(t, admin, twoVariantExperiment("first"))
+	require.Eventually(t, func() bool { return replica.Assign("s") != nil }, time.Second, 5*time.Millisecond)
+
+	// The replica sees the running experiment in the store even before its
+	// cache would
+	second := twoVariantExperiment("second")
+	require.NoError(t, replica.Create(context.Background(), second))
+	_, err := NewSearchExperimentService(repo, &stubTriageAnalyticsRepo{}).Start(context.Background(), second.ID)
+	var appErr *apperrors.AppError
+	require.ErrorAs(t, err, &appErr)
+	assert.Equal(t, apperrors.ErrorTypeConflict, appErr.Type)
+
+	_, err = admin.Stop(context.Background(), first.ID)
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return replica.Assign("s") == nil }, time.Second, 5*time.Millisecond)
+}
+
+func TestSearchExperiment_ReportIncludesIdleVariants(t *testing.T) {
+	analytics := &stubTriageAnalyticsRepo{variantStats: []*entities.ExperimentVariantStats{
+		{Variant: "control", Searches: 10, ZeroResults: 2, ZeroResultRate: 0.2, AvgLatencyMs: 40},
+	}}
+	svc := NewSearchExperimentService(newStubExperimentRepo(), analytics)
+	svc.now = func() time.Time { return time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC) }
+	e := startedExperiment(t, svc, twoVariantExperiment("report"))
+
+	report, err := svc.Report(context.Background(), e.ID)
+	require.NoError(t, err)
+	require.Len(t, report.Variants, 2)
+	assert.Equal(t, "control", report.Variants[0].Variant)
+	assert.InDelta(t, 0.2, report.Variants[0].ZeroResultRate, 1e-9)
+	assert.Equal(t, "no_contextual", report.Variants[1].Variant)
+	assert.Zero(t, report.Variants[1].Searches)
+}
+
+func TestFacilitySearch_AppliesExperimentVariant(t *testing.T) {
+	corpus := evaluation.NewFixtureCorpus([]*evaluation.FixtureFacility{
+		{Facility: entities.Facility{ID: "lab", Name: "City Lab", IsActive: true}, Keywords: []string{"malaria"}},
+	})
+	facilityService := NewFacilityService(corpus, nil, nil, nil, nil)
+	facilityService.SetQueryUnderstanding(newTestQueryService(t))
+
+	experiments := NewSearchExperimentService(newStubExperimentRepo(), &stubTriageAnalyticsRepo{})
+	e := twoVariantExperiment("contextual")
+	e.Variants[0].Weight = 1
+	e.Variants[1].Weight = 1
+	startedExperiment(t, experiments, e)
+	facilityService.SetExperiments(experiments)
+
+	// Find one session per variant.
+	sessions := make(map[string]string)
+	for i := 0; len(sessions) < 2; i++ {
+		id := fmt.Sprintf("s-%d", i)
+		sessions[experiments.Assign(id).Variant] = id
+	}
+
+	_, _, interp, err := facilityService.SearchResultsWithCount(context.Background(), repositories.SearchParams{Query: "malaria", SessionID: sessions["control"]})
+	require.NoError(t, err)
+	assert.NotNil(t, interp)
+
+	_, _, interp, err = facilityService.SearchResultsWithCount(context.Background(), repositories.SearchParams{Query: "malaria", SessionID: sessions["no_contextual"]})
+	require.NoError(t, err)
+	assert.Nil(t, interp, "variant disables contextual search")
+}
+
+func TestSearchRanking_WithWeights(t *testing.T) {
+	base := NewSearchRankingService()
+	lexical, geo := 1.0, 0.0
+	custom := base.WithWeights(entities.RankingWeights{Lexical: &lexical})
+
+	assert.Equal(t, 0.3, *base.Weights().Lexical)
+	assert.Equal(t, 1.0, *custom.Weights().Lexical)
+	assert.Equal(t, 0.3, *custom.Weights().Concept, "omitted weights keep the default")
+	assert.Equal(t, 0.2, *custom.Weights().Geo)
+	assert.Equal(t, 0.2, *custom.Weights().Specialty)
+
+	noGeo := base.WithWeights(entities.RankingWeights{Geo: &geo})
+	assert.Zero(t, *noGeo.Weights().Geo, "an explicit zero disables the signal")
+	assert.Equal(t, 0.3, *noGeo.Weights().Lexical)
+}
@@ -0,0 +1,299 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/providers"
+)
+
+// defaultSearchIndexDebounce is how long changes are gathered before the
+// facilities they touch are reindexed
+const defaultSearchIndexDebounce = 2 * time.Second
+
+// searchEmbeddingField holds a document's embedding. Typesense returns it
+// at its own float precision, so documents are compared on whether they
+// have one rather than on its values.
+const searchEmbeddingField = "embedding"
+
+// SearchDocumentSource builds facility search documents from the database
+type SearchDocumentSource interface {
+	// FacilityDocument builds a facility's document, returning a NotFound
+	// error when the facility no longer exists
+	FacilityDocument(ctx context.Context, facilityID string) (map[string]interface{}, error)
+
+	// FacilityDocuments builds every facility's document by ID
+	FacilityDocuments(ctx context.Context) (map[string]map[string]interface{}, error)
+}
+
+// SearchDocumentIndex is the live facilities collection
+type SearchDocumentIndex interface {
+	IndexFacility(ctx context.Context, document map[string]interface{}) error
+	DeleteFacility(ctx context.Context, id string) error
+	ExportFacilities(ctx context.Context) (map[string]map[string]interface{}, error)
+}
+
+// ProcedureDocumentIndexer refreshes procedure documents, whose price
+// statistics change with facility prices
+type ProcedureDocumentIndexer interface {
+	// IndexProcedures reindexes the given procedures, or every procedure,
+	// removing documents of deleted ones, when procedureIDs is empty
+	IndexProcedures(ctx context.Context, procedureIDs []string) error
+}
+
+// SearchIndexDrift counts the documents a reconciliation found out of date
+type SearchIndexDrift struct {
+	Checked  int `json:"checked"`
+	Missing  int `json:"missing"`
+	Stale    int `json:"stale"`
+	Orphaned int `json:"orphaned"`
+	Repaired int `json:"repaired"`
+}
+
+// Total returns how many documents were out of date
+func (d *SearchIndexDrift) Total() int {
+	return d.Missing + d.Stale + d.Orphaned
+}
+
+// SearchIndexSyncService keeps the search index current by reindexing the
+// facilities named in facility events, and the procedures named in service
+// events. Changes to the same facility or procedure within the debounce
+// window are coalesced into one reindex. The event bus does not guarantee
+// delivery, so a periodic reconciliation compares every document with the
+// database and repairs whatever events missed.
+type SearchIndexSyncService struct {
+	eventBus   providers.EventBus
+	source     SearchDocumentSource
+	index      SearchDocumentIndex
+	procedures ProcedureDocumentIndexer
+	deduper    *entities.FacilityEventDeduper
+	debounce   time.Duration
+}
+
+// NewSearchIndexSyncService creates a new search index sync service
+func NewSearchIndexSyncService(eventBus providers.EventBus, source SearchDocumentSource, index SearchDocumentIndex) *SearchIndexSyncService {
+	return &SearchIndexSyncService{
+		eventBus: eventBus,
+		source:   source,
+		index:    index,
+		deduper:  entities.NewFacilityEventDeduper(facilityEventDedupeSize),
+		debounce: defaultSearchIndexDebounce,
+	}
+}
+
+// SetDebounce sets how long changes are gathered before reindexing
+func (s *SearchIndexSyncService) SetDebounce(debounce time.Duration) {
+	if debounce > 0 {
+		s.debounce = debounce
+	}
+}
+
+// SetProcedureIndexer also keeps procedure documents current
+func (s *SearchIndexSyncService) SetProcedureIndexer(procedures ProcedureDocumentIndexer) {
+	s.procedures = procedures
+}
+
+// Start subscribes to facility events and reindexes the facilities they name,
+// and reconciles the whole index every reconcileInterval, until ctx is done
+func (s *SearchIndexSyncService) Start(ctx context.Context, reconcileInterval time.Duration) error {
+	events, err := s.eventBus.Subscribe(ctx, providers.EventChannelFacilityUpdates)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to facility updates: %w", err)
+	}
+	go s.run(ctx, events, reconcileInterval)
+	return nil
+}
+
+func (s *SearchIndexSyncService) run(ctx context.Context, events <-chan *entities.FacilityEvent, reconcileInterval time.Duration) {
+	var reconcile <-chan time.Time
+	if reconcileInterval > 0 {
+		ticker := time.NewTicker(reconcileInterval)
+		defer ticker.Stop()
+		reconcile = ticker.C
+	}
+
+	pending := map[string]struct{}{}
+	pendingProcedures := map[string]struct{}{}
+	var flush <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				log.Println("Facility event subscription closed; search index sync stopped")
+				return
+			}
+			if event == nil || event.FacilityID == "" || s.deduper.Seen(event) {
+				continue
+			}
+			// The first change opens the window; later ones join it
+			if flush == nil {
+				flush = time.After(s.debounce)
+			}
+			pending[event.FacilityID] = struct{}{}
+			if procedureID, ok := event.ChangedFields["procedure_id"].(string); ok && procedureID != "" {
+				pendingProcedures[procedureID] = struct{}{}
+			}
+		case <-flush:
+			ids := setKeys(pending)
+			procedureIDs := setKeys(pendingProcedures)
+			pending = map[string]struct{}{}
+			pendingProcedures = map[string]struct{}{}
+			flush = nil
+
+			indexed, err := s.SyncFacilities(ctx, ids)
+			if err != nil {
+				log.Printf("Search index sync failed for some facilities: %v", err)
+			}
+			log.Printf("Reindexed %d of %d changed facilities", indexed, len(ids))
+
+			if s.procedures != nil && len(procedureIDs) > 0 {
+				if err := s.procedures.IndexProcedures(ctx, procedureIDs); err != nil {
+					log.Printf("Failed to reindex changed procedures: %v", err)
+				}
+			}
+		case <-reconcile:
+			drift, err := s.Reconcile(ctx)
+			if err != nil {
+				log.Printf("Search index reconciliation failed: %v", err)
+				continue
+			}
+			log.Printf("Search index reconciled: %d checked, %d missing, %d stale, %d orphaned, %d repaired",
+				drift.Checked, drift.Missing, drift.Stale, drift.Orphaned, drift.Repaired)
+		}
+	}
+}
+
+// SyncFacilities reindexes the given facilities, removing the documents of
+// those that no longer exist, and returns how many were written. A failure
+// does not stop the others.
+func (s *SearchIndexSyncService) SyncFacilities(ctx context.Context, facilityIDs []string) (int, error) {
+	sort.Strings(facilityIDs)
+
+	written := 0
+	var errs []error
+	for _, id := range facilityIDs {
+		document, err := s.source.FacilityDocument(ctx, id)
+		switch {
+		case isNotFound(err):
+			err = s.index.DeleteFacility(ctx, id)
+		case err == nil:
+			err = s.index.IndexFacility(ctx, document)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("facility %s: %w", id, err))
+			continue
+		}
+		written++
+	}
+	return written, errors.Join(errs...)
+}
+
+// Reconcile compares every indexed document with the one the database
+// builds, reindexes missing and stale documents, removes those of deleted
+// facilities and returns the counts. The index is read first, so a facility
+// created in between is at worst reindexed rather than removed.
+func (s *SearchIndexSyncService) Reconcile(ctx context.Context) (*SearchIndexDrift, error) {
+	indexed, err := s.index.ExportFacilities(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export indexed documents: %w", err)
+	}
+	documents, err := s.source.FacilityDocuments(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build documents: %w", err)
+	}
+
+	drift := &SearchIndexDrift{Checked: len(documents)}
+	for id, document := range documents {
+		current, ok := indexed[id]
+		switch {
+		case !ok:
+			drift.Missing++
+		case !sameSearchDocument(document, current):
+			drift.Stale++
+		default:
+			continue
+		}
+		if err := s.index.IndexFacility(ctx, document); err != nil {
+			log.Printf("Failed to repair search document %s: %v", id, err)
+			continue
+		}
+		drift.Repaired++
+	}
+
+	for id := range indexed {
+		if _, ok := documents[id]; ok {
+			continue
+		}
+		drift.Orphaned++
+		if err := s.index.DeleteFacility(ctx, id); err != nil {
+			log.Printf("Failed to remove orphaned search document %s: %v", id, err)
+			continue
+		}
+		drift.Repaired++
+	}
+
+	// Procedure documents are few and cheap to build, so they are all rebuilt
+	if s.procedures != nil {
+		if err := s.procedures.IndexProcedures(ctx, nil); err != nil {
+			log.Printf("Failed to reindex procedures: %v", err)
+		}
+	}
+	return drift, nil
+}
+
+func setKeys(set map[string]struct{}) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// sameSearchDocument compares documents as Typesense stores them, ignoring
+// the order of list values
+func sameSearchDocument(a, b map[string]interface{}) bool {
+	return reflect.DeepEqual(canonicalSearchDocument(a), canonicalSearchDocument(b))
+}
+
+func canonicalSearchDocument(document map[string]interface{}) interface{} {
+	data, err := json.Marshal(document)
+	if err != nil {
+		return nil
+	}
+	var canonical map[string]interface{}
+	if err := json.Unmarshal(data, &canonical); err != nil {
+		return nil
+	}
+	if _, ok := canonical[searchEmbeddingField]; ok {
+		canonical[searchEmbeddingField] = true
+	}
+	for key, value := range canonical {
+		values, ok := value.([]interface{})
+		if !ok {
+			continue
+		}
+		strs := make([]string, 0, len(values))
+		for _, v := range values {
+			str, ok := v.(string)
+			if !ok {
+				break
+			}
+			strs = append(strs, str)
+		}
+		// Only string lists are sets; a geopoint's order matters
+		if len(strs) == len(values) {
+			sort.Strings(strs)
+			canonical[key] = strs
+		}
+	}
+	return canonical
+}
@@ -0,0 +1,257 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/providers"
+	apperrors "github.com/zatekoja/Patientpricediscoverydesign/backend/pkg/errors"
+)
+
+// memorySearchIndex serves database documents and keeps indexed ones in memory
+type memorySearchIndex struct {
+	mu        sync.Mutex
+	source    map[string]map[string]interface{}
+	indexed   map[string]map[string]interface{}
+	built     map[string]int
+	deletions []string
+}
+
+func newMemorySearchIndex() *memorySearchIndex {
+	return &memorySearchIndex{
+		source:  map[string]map[string]interface{}{},
+		indexed: map[string]map[string]interface{}{},
+		built:   map[string]int{},
+	}
+}
+
+func (m *memorySearchIndex) FacilityDocument(ctx context.Context, facilityID string) (map[string]interface{}, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.built[facilityID]++
+	document, ok := m.source[facilityID]
+	if !ok {
+		return nil, apperrors.NewNotFoundError("facility not found")
+	}
+	return document, nil
+}
+
+func (m *memorySearchIndex) FacilityDocuments(ctx context.Context) (map[string]map[string]interface{}, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	documents := map[string]map[string]interface{}{}
+	for id, document := range m.source {
+		documents[id] = document
+	}
+	return documents, nil
+}
+
+func (m *memorySearchIndex) IndexFacility(ctx context.Context, document map[string]interface{}) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.indexed[document["id"].(string)] = document
+	return nil
+}
+
+func (m *memorySearchIndex) DeleteFacility(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.indexed, id)
+	m.deletions = append(m.deletions, id)
+	return nil
+}
+
+func (m *memorySearchIndex) ExportFacilities(ctx context.Context) (map[string]map[string]interface{}, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	documents := map[string]map[string]interface{}{}
+	for id, document := range m.indexed {
+		documents[id] = document
+	}
+	return documents, nil
+}
+
+func (m *memorySearchIndex) builds(id string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.built[id]
+}
+
+// channelEventBus hands out one subscription fed by the test
+type channelEventBus struct {
+	providers.EventBus
+	events chan *entities.FacilityEvent
+}
+
+func (b *channelEventBus) Subscribe(ctx context.Context, channel string) (<-chan *entities.FacilityEvent, error) {
+	return b.events, nil
+}
+
+func TestSearchIndexSync_CoalescesChangesPerFacility(t *testing.T) {
+	index := newMemorySearchIndex()
+	index.source["fac-1"] = map[string]interface{}{"id": "fac-1", "price": 5000.0}
+	index.source["fac-2"] = map[string]interface{}{"id": "fac-2", "price": 8000.0}
+
+	bus := &channelEventBus{events: make(chan *entities.FacilityEvent, 10)}
+	sync := NewSearchIndexSyncService(bus, index, index)
+	sync.SetDebounce(50 * time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := sync.Start(ctx, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	price := entities.NewFacilityEvent("fac-1", entities.FacilityEventTypeServicePriceUpdate, entities.Location{}, nil)
+	bus.events <- price
+	bus.events <- entities.NewFacilityEvent("fac-1", entities.FacilityEventTypeServiceAvailabilityUpdate, entities.Location{}, nil)
+	bus.events <- price // redelivered
+	bus.events <- entities.NewFacilityEvent("fac-2", entities.FacilityEventTypeWaitTimeUpdate, entities.Location{}, nil)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for index.builds("fac-1") == 0 || index.builds("fac-2") == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("expected the changed facilities to be reindexed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	// Give a second flush the chance to happen if changes were not coalesced
+	time.Sleep(100 * time.Millisecond)
+
+	if got := index.builds("fac-1"); got != 1 {
+		t.Fatalf("expected fac-1 to be reindexed once, got %d", got)
+	}
+	if got := index.builds("fac-2"); got != 1 {
+		t.Fatalf("expected fac-2 to be reindexed once, got %d", got)
+	}
+}
+
+func TestSearchIndexSync_SyncFacilitiesRemovesDeleted(t *testing.T) {
+	index := newMemorySearchIndex()
+	index.source["fac-1"] = map[string]interface{}{"id": "fac-1"}
+	index.indexed["gone"] = map[string]interface{}{"id": "gone"}
+
+	written, err := NewSearchIndexSyncService(nil, index, index).SyncFacilities(context.Background(), []string{"gone", "fac-1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if written != 2 {
+		t.Fatalf("expected 2 writes, got %d", written)
+	}
+	if _, ok := index.indexed["fac-1"]; !ok {
+		t.Fatal("expected fac-1 to be indexed")
+	}
+	if _, ok := index.indexed["gone"]; ok {
+		t.Fatal("expected the deleted facility's document to be removed")
+	}
+}
+
+func TestSearchIndexSync_ReconcileReportsAndRepairsDrift(t *testing.T) {
+	index := newMemorySearchIndex()
+	index.source["current"] = map[string]interface{}{"id": "current", "price": 5000.0, "tags": []string{"lab", "xray"}, "location": []float64{6.5, 3.4}}
+	index.source["stale"] = map[string]interface{}{"id": "stale", "price": 4000.0}
+	index.source["missing"] = map[string]interface{}{"id": "missing"}
+
+	// Typesense returns decoded JSON; tag order is not significant
+	index.indexed["current"] = map[string]interface{}{"id": "current", "price": 5000.0, "tags": []interface{}{"xray", "lab"}, "location": []interface{}{6.5, 3.4}}
+	index.indexed["stale"] = map[string]interface{}{"id": "stale", "price": 9000.0}
+	index.indexed["orphan"] = map[string]interface{}{"id": "orphan"}
+
+	sync := NewSearchIndexSyncService(nil, index, index)
+	drift, err := sync.Reconcile(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := SearchIndexDrift{Checked: 3, Missing: 1, Stale: 1, Orphaned: 1, Repaired: 3}
+	if *drift != want {
+		t.Fatalf("expected %+v, got %+v", want, *drift)
+	}
+	if index.indexed["stale"]["price"] != 4000.0 {
+		t.Fatalf("expected the stale document to be reindexed, got %v", index.indexed["stale"])
+	}
+
+	// A second pass finds nothing to do
+	drift, err = sync.Reconcile(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if drift.Total() != 0 {
+		t.Fatalf("expected no drift after repair, got %+v", *drift)
+	}
+}
+
+// recordingProcedureIndexer records which procedures were reindexed
+type recordingProcedureIndexer struct {
+	mu    sync.Mutex
+	calls [][]string
+}
+
+func (r *recordingProcedureIndexer) IndexProcedures(ctx context.Context, procedureIDs []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, procedureIDs)
+	return nil
+}
+
+func (r *recordingProcedureIndexer) reindexed() [][]string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([][]string(nil), r.calls...)
+}
+
+func TestSearchIndexSync_ReindexesChangedProcedures(t *testing.T) {
+	index := newMemorySearchIndex()
+	index.source["fac-1"] = map[string]interface{}{"id": "fac-1"}
+	index.source["fac-2"] = map[string]interface{}{"id": "fac-2"}
+	procedures := &recordingProcedureIndexer{}
+
+	bus := &channelEventBus{events: make(chan *entities.FacilityEvent, 10)}
+	sync := NewSearchIndexSyncService(bus, index, index)
+	sync.SetDebounce(50 * time.Millisecond)
+	sync.SetProcedureIndexer(procedures)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := sync.Start(ctx, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	bus.events <- entities.NewFacilityEvent("fac-1", entities.FacilityEventTypeServicePriceUpdate, entities.Location{}, map[string]interface{}{"procedure_id": "proc-1"})
+	bus.events <- entities.NewFacilityEvent("fac-2", entities.FacilityEventTypeServiceAvailabilityUpdate, entities.Location{}, map[string]interface{}{"procedure_id": "proc-1"})
+	bus.events <- entities.NewFacilityEvent("fac-2", entities.FacilityEventTypeWaitTimeUpdate, entities.Location{}, nil)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for len(procedures.reindexed()) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("expected the changed procedure to be reindexed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	calls := procedures.reindexed()
+	if len(calls) != 1 || len(calls[0]) != 1 || calls[0][0] != "proc-1" {
+		t.Fatalf("expected proc-1 to be reindexed once, got %v", calls)
+	}
+
+	// Reconciliation rebuilds every procedure document
+	if _, err := sync.Reconcile(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	calls = procedures.reindexed()
+	if len(calls) != 2 || calls[1] != nil {
+		t.Fatalf("expected reconciliation to reindex all procedures, got %v", calls)
+	}
+}
+
+func TestSameSearchDocument_ComparesEmbeddingPresence(t *testing.T) {
+	built := map[string]interface{}{"id": "f", "embedding": []float32{0.123456789, 0.5}}
+	indexed := map[string]interface{}{"id": "f", "embedding": []interface{}{0.1234568, 0.5}}
+	if !sameSearchDocument(built, indexed) {
+		t.Fatalf("expected embeddings differing only in precision to match")
+	}
+	if sameSearchDocument(built, map[string]interface{}{"id": "f"}) {
+		t.Fatalf("expected a document missing its embedding to be stale")
+	}
+}
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/repositories"
+	apperrors "github.com/zatekoja/Patientpricediscoverydesign/backend/pkg/errors"
+)
+
+const (
+	defaultEngagementWindow = 30 * 24 * time.Hour
+	defaultEngagementLimit  = 100
+	maxEngagementLimit      = 1000
+)
+
+// SearchInteractionService records what users do after a search and reports
+// click-through and conversion per query, facility and result position.
+type SearchInteractionService struct {
+	repo repositories.SearchInteractionRepository
+	now  func() time.Time
+}
+
+// NewSearchInteractionService creates a new search interaction service.
+func NewSearchInteractionService(repo repositories.SearchInteractionRepository) *SearchInteractionService {
+	return &SearchInteractionService{
+		repo: repo,
+		now:  time.Now,
+	}
+}
+
+// Record validates and stores an interaction. The impression is not looked up
+// because search events are logged asynchronously and may not be stored yet.
+func (s *SearchInteractionService) Record(ctx context.Context, interaction *entities.SearchInteraction) error {
+	interaction.ImpressionID = strings.TrimSpace(interaction.ImpressionID)
+	interaction.FacilityID = strings.TrimSpace(interaction.FacilityID)
+	interaction.SessionID = strings.TrimSpace(interaction.SessionID)
+
+	if _, err := uuid.Parse(interaction.ImpressionID); err != nil {
+		return apperrors.NewValidationError("impression_id must be the X-Impression-ID returned with the search results")
+	}
+	if !interaction.EventType.IsValid() {
+		return apperrors.NewValidationError("event_type must be one of result_click, facility_view, call_tap, whatsapp_tap, booking")
+	}
+	if interaction.FacilityID == "" {
+		return apperrors.NewValidationError("facility_id is required")
+	}
+	if interaction.Position != nil && *interaction.Position <= 0 {
+		return apperrors.NewValidationError("position must be 1 or greater")
+	}
+	if interaction.EventType == entities.SearchInteractionResultClick && interaction.Position == nil {
+		return apperrors.NewValidationError("position is required for result_click")
+	}
+
+	interaction.ID = ""
+	interaction.CreatedAt = s.now().UTC()
+	return s.repo.Create(ctx, interaction)
+}
+
+// QueryReport returns engagement per normalized query, most searched first.
+func (s *SearchInteractionService) QueryReport(ctx context.Context, filter entities.SearchInteractionReportFilter) ([]*entities.QueryEngagementStats, error) {
+	stats, err := s.repo.GetQueryEngagement(ctx, s.normalizeFilter(filter))
+	if err != nil {
+		return nil, err
+	}
+	for _, st := range stats {
+		st.ClickThroughRate = engagementRate(st.ClickedSearches, st.Searches)
+		st.ConversionRate = engagementRate(st.ConvertedSearches, st.Searches)
+	}
+	return stats, nil
+}
+
+// FacilityReport returns engagement per facility, most shown first.
+func (s *SearchInteractionService) FacilityReport(ctx context.Context, filter entities.SearchInteractionReportFilter) ([]*entities.FacilityEngagementStats, error) {
+	stats, err := s.repo.GetFacilityEngagement(ctx, s.normalizeFilter(filter))
+	if err != nil {
+		return nil, err
+	}
+	for _, st := range stats {
+		st.ClickThroughRate = engagementRate(st.Clicks, st.Impressions)
+		st.ConversionRate = engagementRate(st.Bookings, st.Impressions)
+	}
+	return stats, nil
+}
+
+// PositionReport returns click-through per result position, top position first.
+func (s *SearchInteractionService) PositionReport(ctx context.Context, filter entities.SearchInteractionReportFilter) ([]*entities.PositionEngagementStats, error) {
+	stats, err := s.repo.GetPositionEngagement(ctx, s.normalizeFilter(filter))
+	if err != nil {
+		return nil, err
+	}
+	for _, st := range stats {
+		st.ClickThroughRate = engagementRate(st.Clicks, st.Impressions)
+	}
+	return stats, nil
+}
+
+func (s *SearchInteractionService) normalizeFilter(filter entities.SearchInteractionReportFilter) entities.SearchInteractionReportFilter {
+	if filter.Until.IsZero() {
+		filter.Until = s.now().UTC()
+	}
+	if filter.Since.IsZero() {
+		filter.Since = filter.Until.Add(-defaultEngagementWindow)
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = defaultEngagementLimit
+	}
+	if filter.Limit > maxEngagementLimit {
+		filter.Limit = maxEngagementLimit
+	}
+	return filter
+}
+
+func engagementRate(n, d int) float64 {
+	if d <= 0 {
+		return 0
+	}
+	return float64(n) / float64(d)
+}
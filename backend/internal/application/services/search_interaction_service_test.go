@@ -0,0 +1,139 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/repositories"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/evaluation"
+	apperrors "github.com/zatekoja/Patientpricediscoverydesign/backend/pkg/errors"
+)
+
+type stubInteractionRepo struct {
+	created    []*entities.SearchInteraction
+	lastFilter entities.SearchInteractionReportFilter
+	queries    []*entities.QueryEngagementStats
+	facilities []*entities.FacilityEngagementStats
+	positions  []*entities.PositionEngagementStats
+}
+
+func (r *stubInteractionRepo) Create(ctx context.Context, interaction *entities.SearchInteraction) error {
+	r.created = append(r.created, interaction)
+	return nil
+}
+
+func (r *stubInteractionRepo) GetQueryEngagement(ctx context.Context, filter entities.SearchInteractionReportFilter) ([]*entities.QueryEngagementStats, error) {
+	r.lastFilter = filter
+	return r.queries, nil
+}
+
+func (r *stubInteractionRepo) GetFacilityEngagement(ctx context.Context, filter entities.SearchInteractionReportFilter) ([]*entities.FacilityEngagementStats, error) {
+	r.lastFilter = filter
+	return r.facilities, nil
+}
+
+func (r *stubInteractionRepo) GetPositionEngagement(ctx context.Context, filter entities.SearchInteractionReportFilter) ([]*entities.PositionEngagementStats, error) {
+	r.lastFilter = filter
+	return r.positions, nil
+}
+
+var _ repositories.SearchInteractionRepository = (*stubInteractionRepo)(nil)
+
+// eventChanAnalyticsRepo hands logged events to the test, since search events are logged in the background.
+type eventChanAnalyticsRepo struct {
+	stubTriageAnalyticsRepo
+	events chan *entities.SearchEvent
+}
+
+func (r *eventChanAnalyticsRepo) LogEvent(ctx context.Context, event *entities.SearchEvent) error {
+	r.events <- event
+	return nil
+}
+
+func TestSearchInteraction_RecordValidation(t *testing.T) {
+	repo := &stubInteractionRepo{}
+	svc := NewSearchInteractionService(repo)
+	impression := "6f1c2a8e-3b1d-4f5e-9a7c-2d8e4b6f1a3c"
+	zero, one := 0, 1
+
+	cases := map[string]*entities.SearchInteraction{
+		"bad impression":    {ImpressionID: "abc", EventType: entities.SearchInteractionFacilityView, FacilityID: "f1"},
+		"unknown type":      {ImpressionID: impression, EventType: "share", FacilityID: "f1"},
+		"missing facility":  {ImpressionID: impression, EventType: entities.SearchInteractionCallTap},
+		"click no position": {ImpressionID: impression, EventType: entities.SearchInteractionResultClick, FacilityID: "f1"},
+		"zero position":     {ImpressionID: impression, EventType: entities.SearchInteractionResultClick, FacilityID: "f1", Position: &zero},
+	}
+	for name, interaction := range cases {
+		err := svc.Record(context.Background(), interaction)
+		var appErr *apperrors.AppError
+		require.ErrorAs(t, err, &appErr, name)
+		assert.Equal(t, apperrors.ErrorTypeValidation, appErr.Type, name)
+	}
+	assert.Empty(t, repo.created)
+
+	click := &entities.SearchInteraction{ImpressionID: " " + impression + " ", EventType: entities.SearchInteractionResultClick, FacilityID: "f1", Position: &one}
+	require.NoError(t, svc.Record(context.Background(), click))
+	booking := &entities.SearchInteraction{ImpressionID: impression, EventType: entities.SearchInteractionBooking, FacilityID: "f1"}
+	require.NoError(t, svc.Record(context.Background(), booking))
+
+	require.Len(t, repo.created, 2)
+	assert.Equal(t, impression, repo.created[0].ImpressionID)
+	assert.False(t, repo.created[0].CreatedAt.IsZero())
+}
+
+func TestSearchInteraction_ReportsComputeRates(t *testing.T) {
+	repo := &stubInteractionRepo{
+		queries:    []*entities.QueryEngagementStats{{Query: "malaria test", Searches: 10, ClickedSearches: 4, ConvertedSearches: 1}},
+		facilities: []*entities.FacilityEngagementStats{{FacilityID: "f1", Impressions: 20, Clicks: 5, Bookings: 2}, {FacilityID: "f2", Clicks: 1}},
+		positions:  []*entities.PositionEngagementStats{{Position: 1, Impressions: 10, Clicks: 3}},
+	}
+	svc := NewSearchInteractionService(repo)
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+
+	queries, err := svc.QueryReport(context.Background(), entities.SearchInteractionReportFilter{})
+	require.NoError(t, err)
+	assert.InDelta(t, 0.4, queries[0].ClickThroughRate, 1e-9)
+	assert.InDelta(t, 0.1, queries[0].ConversionRate, 1e-9)
+	assert.Equal(t, now, repo.lastFilter.Until)
+	assert.Equal(t, now.Add(-defaultEngagementWindow), repo.lastFilter.Since)
+	assert.Equal(t, defaultEngagementLimit, repo.lastFilter.Limit)
+
+	facilities, err := svc.FacilityReport(context.Background(), entities.SearchInteractionReportFilter{Limit: 5000})
+	require.NoError(t, err)
+	assert.InDelta(t, 0.25, facilities[0].ClickThroughRate, 1e-9)
+	assert.InDelta(t, 0.1, facilities[0].ConversionRate, 1e-9)
+	assert.Zero(t, facilities[1].ClickThroughRate, "no impressions means no rate")
+	assert.Equal(t, maxEngagementLimit, repo.lastFilter.Limit)
+
+	positions, err := svc.PositionReport(context.Background(), entities.SearchInteractionReportFilter{})
+	require.NoError(t, err)
+	assert.InDelta(t, 0.3, positions[0].ClickThroughRate, 1e-9)
+}
+
+func TestFacilitySearch_LogsImpression(t *testing.T) {
+	corpus := evaluation.NewFixtureCorpus([]*evaluation.FixtureFacility{
+		{Facility: entities.Facility{ID: "lab-a", Name: "Malaria Lab A", IsActive: true}},
+		{Facility: entities.Facility{ID: "lab-b", Name: "Malaria Lab B", IsActive: true}},
+	})
+	analytics := &eventChanAnalyticsRepo{events: make(chan *entities.SearchEvent, 1)}
+	facilityService := NewFacilityService(corpus, nil, nil, nil, nil)
+	facilityService.SetAnalytics(NewSearchAnalyticsService(analytics))
+
+	params := repositories.SearchParams{Query: "malaria", Limit: 10, Offset: 0, ImpressionID: "6f1c2a8e-3b1d-4f5e-9a7c-2d8e4b6f1a3c"}
+	_, _, _, err := facilityService.SearchResultsWithCount(context.Background(), params)
+	require.NoError(t, err)
+
+	select {
+	case event := <-analytics.events:
+		assert.Equal(t, params.ImpressionID, event.ID)
+		assert.ElementsMatch(t, []string{"lab-a", "lab-b"}, event.ResultFacilityIDs)
+		assert.Equal(t, 0, event.ResultOffset)
+	case <-time.After(2 * time.Second):
+		t.Fatal("search event was not logged")
+	}
+}
@@ -30,14 +30,54 @@ func NewSearchRankingService() *SearchRankingService {
 	}
 }
 
+// Weights returns the ranking signal weights.
+func (s *SearchRankingService) Weights() entities.RankingWeights {
+	lexical, concept, geo, specialty := s.wLexical, s.wConcept, s.wGeo, s.wSpecialty
+	return entities.RankingWeights{
+		Lexical:   &lexical,
+		Concept:   &concept,
+		Geo:       &geo,
+		Specialty: &specialty,
+	}
+}
+
+// WithWeights returns a copy of the ranking service with the given weights
+// replacing its own; weights left nil keep their current value.
+func (s *SearchRankingService) WithWeights(w entities.RankingWeights) *SearchRankingService {
+	merged := *s
+	for _, weight := range []struct {
+		override *float64
+		target   *float64
+	}{
+		{w.Lexical, &merged.wLexical},
+		{w.Concept, &merged.wConcept},
+		{w.Geo, &merged.wGeo},
+		{w.Specialty, &merged.wSpecialty},
+	} {
+		if weight.override != nil {
+			*weight.target = *weight.override
+		}
+	}
+	return &merged
+}
+
+// travelDecayMinutes is the travel time at which proximity scores half
+const travelDecayMinutes = 20.0
+
 func (s *SearchRankingService) Rank(facilities []*entities.Facility, interp *QueryInterpretation, userLat, userLon float64) []ScoredResult {
+	return s.RankWithTravelTimes(facilities, interp, userLat, userLon, nil)
+}
+
+// RankWithTravelTimes ranks like Rank, scoring proximity by travel minutes
+// for the facilities that have them instead of by straight-line distance
+func (s *SearchRankingService) RankWithTravelTimes(facilities []*entities.Facility, interp *QueryInterpretation, userLat, userLon float64, travelMinutes map[string]float64) []ScoredResult {
 	if len(facilities) == 0 {
 		return nil
 	}
 
 	scored := make([]ScoredResult, len(facilities))
 	for i, f := range facilities {
-		score, breakdown := s.calculateScore(f, interp, userLat, userLon)
+		score, breakdown := s.calculateScore(f, interp, userLat, userLon, travelMinutes)
 		scored[i] = ScoredResult{
 			Facility:       f,
 			Score:          score,
@@ -52,7 +92,7 @@ func (s *SearchRankingService) Rank(facilities []*entities.Facility, interp *Que
 	return scored
 }
 
-func (s *SearchRankingService) calculateScore(f *entities.Facility, interp *QueryInterpretation, lat, lon float64) (float64, map[string]float64) {
+func (s *SearchRankingService) calculateScore(f *entities.Facility, interp *QueryInterpretation, lat, lon float64, travelMinutes map[string]float64) (float64, map[string]float64) {
 	breakdown := make(map[string]float64)
 
 	// 1. Lexical Match
@@ -82,7 +122,9 @@ func (s *SearchRankingService) calculateScore(f *entities.Facility, interp *Quer
 
 	// 3. Geo Proximity
 	geoScore := 0.0
-	if lat != 0 && lon != 0 && f.Location.Latitude != 0 && f.Location.Longitude != 0 {
+	if minutes, ok := travelMinutes[f.ID]; ok {
+		geoScore = 1.0 / (1.0 + minutes/travelDecayMinutes)
+	} else if lat != 0 && lon != 0 && f.Location.Latitude != 0 && f.Location.Longitude != 0 {
 		dist := distance(lat, lon, f.Location.Latitude, f.Location.Longitude)
 		// decay score: 1.0 at 0km, 0.5 at 10km
 		geoScore = 1.0 / (1.0 + dist/10.0)
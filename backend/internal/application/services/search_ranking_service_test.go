@@ -50,3 +50,17 @@ func TestRank_EmptyResults(t *testing.T) {
 	results := svc.Rank([]*entities.Facility{}, interp, 0, 0)
 	assert.Empty(t, results)
 }
+
+func TestScore_TravelTimeReplacesDistance(t *testing.T) {
+	svc := NewSearchRankingService()
+	interp := &QueryInterpretation{OriginalQuery: "clinic"}
+
+	// f1 is closer in a straight line, but across water
+	f1 := &entities.Facility{ID: "f1", Location: entities.Location{Latitude: 10.0, Longitude: 10.0}}
+	f2 := &entities.Facility{ID: "f2", Location: entities.Location{Latitude: 10.1, Longitude: 10.1}}
+
+	results := svc.RankWithTravelTimes([]*entities.Facility{f1, f2}, interp, 10.0, 10.0, map[string]float64{"f1": 55, "f2": 15})
+
+	assert.Equal(t, "f2", results[0].Facility.ID)
+	assert.InDelta(t, 0.2/(1+15.0/20), results[0].ScoreBreakdown["geo"], 1e-9)
+}
@@ -0,0 +1,606 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/repositories"
+	apperrors "github.com/zatekoja/Patientpricediscoverydesign/backend/pkg/errors"
+)
+
+const (
+	triageDefaultWindowDays       = 14
+	triageDefaultLimit            = 50
+	triageEventScanLimit          = 5000
+	triageProcedureScanLimit      = 2000
+	triageMaxSuggestions          = 5
+	triageMaxSampleQueries        = 5
+	triageMaxGeoBuckets           = 10
+	triagePhraseSimilarity        = 0.7
+	triageWordSimilarity          = 0.75
+	triageMinSpellingWordLength   = 4
+	triageUnresolvedAfterSearches = 3
+)
+
+// SearchTriageOptions controls how zero-result queries are clustered.
+type SearchTriageOptions struct {
+	WindowDays     int // how far back to look
+	MaxResultCount int // searches returning at most this many results are "low-result"
+	MinOccurrences int // drop clusters seen fewer times than this
+	Limit          int // maximum clusters returned
+}
+
+// SearchTriageService turns zero- and low-result search analytics into
+// dictionary fixes and tracks whether those fixes worked.
+type SearchTriageService struct {
+	analyticsRepo      repositories.SearchAnalyticsRepository
+	fixRepo            repositories.SearchDictionaryFixRepository
+	procedureRepo      repositories.ProcedureRepository
+	queryUnderstanding *QueryUnderstandingService
+	audit              *AuditService
+	now                func() time.Time
+
+	mu      sync.Mutex
+	applied map[string]bool // IDs of the fixes applied to queryUnderstanding
+}
+
+// NewSearchTriageService creates a new triage service. procedureRepo and
+// queryUnderstanding are optional; without them fewer suggestions are produced
+// and accepted fixes are only stored.
+func NewSearchTriageService(
+	analyticsRepo repositories.SearchAnalyticsRepository,
+	fixRepo repositories.SearchDictionaryFixRepository,
+	procedureRepo repositories.ProcedureRepository,
+	queryUnderstanding *QueryUnderstandingService,
+) *SearchTriageService {
+	return &SearchTriageService{
+		analyticsRepo:      analyticsRepo,
+		fixRepo:            fixRepo,
+		procedureRepo:      procedureRepo,
+		queryUnderstanding: queryUnderstanding,
+		now:                time.Now,
+		applied:            make(map[string]bool),
+	}
+}
+
+// SetAuditLog records accepted dictionary fixes in the audit log.
+func (s *SearchTriageService) SetAuditLog(audit *AuditService) {
+	s.audit = audit
+}
+
+// GetClusters groups recent zero- and low-result searches by normalized query
+// and attaches frequency, trend, geography and candidate fixes.
+func (s *SearchTriageService) GetClusters(ctx context.Context, opts SearchTriageOptions) ([]*entities.ZeroResultQueryCluster, error) {
+	if opts.WindowDays <= 0 {
+		opts.WindowDays = triageDefaultWindowDays
+	}
+	if opts.Limit <= 0 {
+		opts.Limit = triageDefaultLimit
+	}
+	if opts.MinOccurrences <= 0 {
+		opts.MinOccurrences = 1
+	}
+
+	now := s.now().UTC()
+	since := startOfDay(now).AddDate(0, 0, -(opts.WindowDays - 1))
+
+	events, err := s.analyticsRepo.GetLowResultQueries(ctx, opts.MaxResultCount, since, triageEventScanLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	clusters := make(map[string]*entities.ZeroResultQueryCluster)
+	daily := make(map[string]map[string]int)
+	geo := make(map[string]map[[2]float64]int)
+	for _, event := range events {
+		if event == nil {
+			continue
+		}
+		key := event.NormalizedQuery
+		if key == "" {
+			key = normalizeQueryText(event.Query)
+		}
+		if key == "" {
+			continue
+		}
+
+		cluster, ok := clusters[key]
+		if !ok {
+			cluster = &entities.ZeroResultQueryCluster{
+				NormalizedQuery: key,
+				FirstSeen:       event.CreatedAt,
+				LastSeen:        event.CreatedAt,
+			}
+			clusters[key] = cluster
+			daily[key] = make(map[string]int)
+			geo[key] = make(map[[2]float64]int)
+		}
+
+		cluster.Occurrences++
+		if event.ResultCount == 0 {
+			cluster.ZeroResultCount++
+		} else {
+			cluster.LowResultCount++
+		}
+		if event.CreatedAt.Before(cluster.FirstSeen) {
+			cluster.FirstSeen = event.CreatedAt
+		}
+		if event.CreatedAt.After(cluster.LastSeen) {
+			cluster.LastSeen = event.CreatedAt
+		}
+		if len(cluster.SampleQueries) < triageMaxSampleQueries {
+			cluster.SampleQueries = appendUnique(cluster.SampleQueries, strings.TrimSpace(event.Query))
+		}
+
+		daily[key][event.CreatedAt.UTC().Format("2006-01-02")]++
+		if event.UserLatitude != 0 || event.UserLongitude != 0 {
+			cell := [2]float64{roundTo(event.UserLatitude, 1), roundTo(event.UserLongitude, 1)}
+			geo[key][cell]++
+		}
+	}
+
+	result := make([]*entities.ZeroResultQueryCluster, 0, len(clusters))
+	for key, cluster := range clusters {
+		if cluster.Occurrences < opts.MinOccurrences {
+			continue
+		}
+		cluster.DailyCounts = buildDailyCounts(daily[key], since, opts.WindowDays)
+		cluster.Trend = classifyTrend(cluster.DailyCounts)
+		cluster.Geography = buildGeoBuckets(geo[key])
+		result = append(result, cluster)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Occurrences != result[j].Occurrences {
+			return result[i].Occurrences > result[j].Occurrences
+		}
+		return result[i].LastSeen.After(result[j].LastSeen)
+	})
+	if len(result) > opts.Limit {
+		result = result[:opts.Limit]
+	}
+
+	vocabulary := s.buildVocabulary(ctx)
+	// Only fixes still waiting on an outcome are pending; resolved and
+	// unresolved ones are history
+	var fixesByQuery map[string]*entities.SearchDictionaryFix
+	if s.fixRepo != nil {
+		if fixes, err := s.fixRepo.List(ctx, entities.DictionaryFixStatusPending); err == nil {
+			fixesByQuery = make(map[string]*entities.SearchDictionaryFix, len(fixes))
+			for _, fix := range fixes {
+				if _, exists := fixesByQuery[fix.NormalizedQuery]; !exists {
+					fixesByQuery[fix.NormalizedQuery] = fix
+				}
+			}
+		}
+	}
+
+	for _, cluster := range result {
+		cluster.Suggestions = suggestDictionaryFixes(cluster.NormalizedQuery, vocabulary)
+		if fix, ok := fixesByQuery[cluster.NormalizedQuery]; ok {
+			cluster.PendingFix = fix
+		}
+	}
+
+	return result, nil
+}
+
+// AcceptFix persists an admin-accepted suggestion and applies it to this
+// replica's query understanding service. Other replicas pick it up on their
+// next LoadAcceptedFixes.
+func (s *SearchTriageService) AcceptFix(ctx context.Context, fix *entities.SearchDictionaryFix) error {
+	if fix == nil {
+		return apperrors.NewValidationError("fix is required")
+	}
+	if s.fixRepo == nil {
+		return apperrors.NewInternalError("dictionary fix repository not configured", fmt.Errorf("nil repository"))
+	}
+
+	fix.Term = normalizeQueryText(fix.Term)
+	fix.Target = normalizeQueryText(fix.Target)
+	fix.NormalizedQuery = normalizeQueryText(fix.NormalizedQuery)
+	if fix.Term == "" || fix.Target == "" {
+		return apperrors.NewValidationError("term and target are required")
+	}
+	if fix.Term == fix.Target {
+		return apperrors.NewValidationError("term and target must differ")
+	}
+	switch fix.FixType {
+	case entities.DictionaryFixSpelling:
+		if strings.Contains(fix.Term, " ") {
+			return apperrors.NewValidationError("spelling corrections apply to a single word")
+		}
+	case entities.DictionaryFixSynonym:
+	default:
+		return apperrors.NewValidationError("fix_type must be 'spelling' or 'synonym'")
+	}
+	if fix.NormalizedQuery == "" {
+		fix.NormalizedQuery = fix.Term
+	}
+
+	fix.Status = entities.DictionaryFixStatusPending
+	fix.SearchesAfter = 0
+	fix.SuccessfulAfter = 0
+	fix.LastCheckedAt = nil
+	fix.CreatedAt = s.now().UTC()
+
+	if err := s.audit.Transact(ctx, func(ctx context.Context) (*AuditEvent, error) {
+		if err := s.fixRepo.Create(ctx, fix); err != nil {
+			return nil, err
+		}
+		return &AuditEvent{
+			Action:     "search_fix.accept",
+			EntityType: "search_dictionary_fix",
+			EntityID:   fix.ID,
+			After:      fix,
+		}, nil
+	}); err != nil {
+		return err
+	}
+
+	s.applyFix(fix)
+	return nil
+}
+
+// ListFixes returns accepted fixes with their outcome as of the last
+// RefreshFixOutcomes.
+func (s *SearchTriageService) ListFixes(ctx context.Context, status entities.DictionaryFixStatus) ([]*entities.SearchDictionaryFix, error) {
+	if s.fixRepo == nil {
+		return []*entities.SearchDictionaryFix{}, nil
+	}
+	fixes, err := s.fixRepo.List(ctx, status)
+	if err != nil {
+		return nil, err
+	}
+	if fixes == nil {
+		fixes = []*entities.SearchDictionaryFix{}
+	}
+	return fixes, nil
+}
+
+// RefreshFixOutcomes re-checks pending fixes against searches made after they
+// were accepted. A fix is resolved once its query returns results, and
+// unresolved if it keeps failing.
+func (s *SearchTriageService) RefreshFixOutcomes(ctx context.Context) error {
+	if s.fixRepo == nil {
+		return nil
+	}
+	fixes, err := s.fixRepo.List(ctx, entities.DictionaryFixStatusPending)
+	if err != nil {
+		return err
+	}
+
+	for _, fix := range fixes {
+		outcome, err := s.analyticsRepo.GetQueryOutcome(ctx, fix.NormalizedQuery, fix.CreatedAt)
+		if err != nil {
+			return err
+		}
+
+		checkedAt := s.now().UTC()
+		fix.SearchesAfter = outcome.Searches
+		fix.SuccessfulAfter = outcome.WithResult
+		fix.LastCheckedAt = &checkedAt
+		switch {
+		case outcome.WithResult > 0:
+			fix.Status = entities.DictionaryFixStatusResolved
+		case outcome.Searches >= triageUnresolvedAfterSearches:
+			fix.Status = entities.DictionaryFixStatusUnresolved
+		}
+
+		if err := s.fixRepo.Update(ctx, fix); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// LoadAcceptedFixes applies the persisted fixes not yet applied to the query
+// understanding service and returns how many it applied. It is called at
+// startup so fixes survive restarts, and by WatchAcceptedFixes.
+func (s *SearchTriageService) LoadAcceptedFixes(ctx context.Context) (int, error) {
+	if s.fixRepo == nil || s.queryUnderstanding == nil {
+		return 0, nil
+	}
+	fixes, err := s.fixRepo.List(ctx, "")
+	if err != nil {
+		return 0, err
+	}
+	// List is newest first; apply oldest first so later fixes win.
+	applied := 0
+	for i := len(fixes) - 1; i >= 0; i-- {
+		if s.applyFix(fixes[i]) {
+			applied++
+		}
+	}
+	return applied, nil
+}
+
+// WatchAcceptedFixes applies fixes accepted through other replicas and
+// refreshes the outcome of pending fixes every interval until ctx is done.
+func (s *SearchTriageService) WatchAcceptedFixes(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := s.LoadAcceptedFixes(ctx); err != nil {
+					log.Printf("Failed to reload accepted search dictionary fixes: %v", err)
+				}
+				if err := s.RefreshFixOutcomes(ctx); err != nil {
+					log.Printf("Failed to refresh search dictionary fix outcomes: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// applyFix applies fix unless it already has been, reporting whether it did
+func (s *SearchTriageService) applyFix(fix *entities.SearchDictionaryFix) bool {
+	if s.queryUnderstanding == nil {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if fix.ID != "" && s.applied[fix.ID] {
+		return false
+	}
+	if fix.ID != "" {
+		s.applied[fix.ID] = true
+	}
+	switch fix.FixType {
+	case entities.DictionaryFixSpelling:
+		s.queryUnderstanding.AddSpellingCorrection(fix.Term, fix.Target)
+	case entities.DictionaryFixSynonym:
+		s.queryUnderstanding.AddSynonym(fix.Term, fix.Target)
+	}
+	return true
+}
+
+type triageCandidate struct {
+	term   string
+	source string
+}
+
+type triageVocabulary struct {
+	phrases []triageCandidate // concept keys and procedure names
+	words   map[string]string // single word → source
+}
+
+func (s *SearchTriageService) buildVocabulary(ctx context.Context) *triageVocabulary {
+	vocab := &triageVocabulary{words: make(map[string]string)}
+	seen := make(map[string]struct{})
+
+	add := func(term, source string) {
+		term = normalizeQueryText(term)
+		if term == "" {
+			return
+		}
+		if _, ok := seen[term]; !ok {
+			seen[term] = struct{}{}
+			vocab.phrases = append(vocab.phrases, triageCandidate{term: term, source: source})
+		}
+		for _, word := range strings.Fields(term) {
+			if len(word) < triageMinSpellingWordLength {
+				continue
+			}
+			if _, ok := vocab.words[word]; !ok {
+				vocab.words[word] = source
+			}
+		}
+	}
+
+	if s.queryUnderstanding != nil {
+		for _, term := range s.queryUnderstanding.ConceptTerms() {
+			add(term, "concept")
+		}
+	}
+
+	if s.procedureRepo != nil {
+		active := true
+		procedures, err := s.procedureRepo.List(ctx, repositories.ProcedureFilter{IsActive: &active, Limit: triageProcedureScanLimit})
+		if err == nil {
+			for _, procedure := range procedures {
+				if procedure == nil {
+					continue
+				}
+				add(procedure.Name, "procedure")
+				if procedure.DisplayName != "" {
+					add(procedure.DisplayName, "procedure")
+				}
+			}
+		}
+	}
+
+	return vocab
+}
+
+// suggestDictionaryFixes proposes synonyms for the whole query and spelling
+// corrections for individual words using edit-distance similarity.
+func suggestDictionaryFixes(query string, vocab *triageVocabulary) []entities.DictionaryFixSuggestion {
+	if vocab == nil || query == "" {
+		return nil
+	}
+
+	seen := make(map[string]struct{})
+	var suggestions []entities.DictionaryFixSuggestion
+	add := func(s entities.DictionaryFixSuggestion) {
+		key := string(s.FixType) + "|" + s.Term + "|" + s.Target
+		if _, ok := seen[key]; ok {
+			return
+		}
+		seen[key] = struct{}{}
+		suggestions = append(suggestions, s)
+	}
+
+	queryWords := strings.Fields(query)
+	for _, candidate := range vocab.phrases {
+		if candidate.term == query {
+			continue
+		}
+		score := stringSimilarity(query, candidate.term)
+		if score < triagePhraseSimilarity {
+			continue
+		}
+		fixType := entities.DictionaryFixSynonym
+		if len(queryWords) == 1 && !strings.Contains(candidate.term, " ") {
+			fixType = entities.DictionaryFixSpelling
+		}
+		add(entities.DictionaryFixSuggestion{
+			FixType: fixType,
+			Term:    query,
+			Target:  candidate.term,
+			Source:  candidate.source,
+			Score:   roundTo(score, 3),
+		})
+	}
+
+	for _, word := range queryWords {
+		if len(word) < triageMinSpellingWordLength {
+			continue
+		}
+		if _, known := vocab.words[word]; known {
+			continue
+		}
+		if _, ignored := ignoredUnmatchedTerms[word]; ignored {
+			continue
+		}
+		bestScore := 0.0
+		bestWord := ""
+		for candidate := range vocab.words {
+			score := stringSimilarity(word, candidate)
+			if score > bestScore || (score == bestScore && candidate < bestWord) {
+				bestScore = score
+				bestWord = candidate
+			}
+		}
+		if bestScore >= triageWordSimilarity {
+			add(entities.DictionaryFixSuggestion{
+				FixType: entities.DictionaryFixSpelling,
+				Term:    word,
+				Target:  bestWord,
+				Source:  vocab.words[bestWord],
+				Score:   roundTo(bestScore, 3),
+			})
+		}
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		if suggestions[i].Score != suggestions[j].Score {
+			return suggestions[i].Score > suggestions[j].Score
+		}
+		return suggestions[i].Target < suggestions[j].Target
+	})
+	if len(suggestions) > triageMaxSuggestions {
+		suggestions = suggestions[:triageMaxSuggestions]
+	}
+	return suggestions
+}
+
+// stringSimilarity returns 1 - normalized Levenshtein distance.
+func stringSimilarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	maxLen := len(ra)
+	if len(rb) > maxLen {
+		maxLen = len(rb)
+	}
+	if maxLen == 0 {
+		return 1
+	}
+	return 1 - float64(levenshteinDistance(ra, rb))/float64(maxLen)
+}
+
+func levenshteinDistance(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+func buildDailyCounts(counts map[string]int, since time.Time, days int) []entities.QueryTrendPoint {
+	points := make([]entities.QueryTrendPoint, 0, days)
+	for i := 0; i < days; i++ {
+		date := since.AddDate(0, 0, i).Format("2006-01-02")
+		points = append(points, entities.QueryTrendPoint{Date: date, Count: counts[date]})
+	}
+	return points
+}
+
+// classifyTrend compares the second half of the window with the first half.
+func classifyTrend(points []entities.QueryTrendPoint) string {
+	half := len(points) / 2
+	if half == 0 {
+		return "stable"
+	}
+	earlier, later := 0, 0
+	for i, p := range points {
+		if i < len(points)-half {
+			earlier += p.Count
+		} else {
+			later += p.Count
+		}
+	}
+	switch {
+	case later >= earlier+2 && float64(later) > float64(earlier)*1.25:
+		return "rising"
+	case earlier >= later+2 && float64(later) < float64(earlier)*0.75:
+		return "falling"
+	default:
+		return "stable"
+	}
+}
+
+func buildGeoBuckets(cells map[[2]float64]int) []entities.QueryGeoBucket {
+	if len(cells) == 0 {
+		return nil
+	}
+	buckets := make([]entities.QueryGeoBucket, 0, len(cells))
+	for cell, count := range cells {
+		buckets = append(buckets, entities.QueryGeoBucket{Latitude: cell[0], Longitude: cell[1], Count: count})
+	}
+	sort.Slice(buckets, func(i, j int) bool {
+		if buckets[i].Count != buckets[j].Count {
+			return buckets[i].Count > buckets[j].Count
+		}
+		if buckets[i].Latitude != buckets[j].Latitude {
+			return buckets[i].Latitude < buckets[j].Latitude
+		}
+		return buckets[i].Longitude < buckets[j].Longitude
+	})
+	if len(buckets) > triageMaxGeoBuckets {
+		buckets = buckets[:triageMaxGeoBuckets]
+	}
+	return buckets
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+func roundTo(value float64, places int) float64 {
+	factor := math.Pow(10, float64(places))
+	return math.Round(value*factor) / factor
+}
@@ -0,0 +1,283 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/repositories"
+)
+
+type stubTriageAnalyticsRepo struct {
+	events       []*entities.SearchEvent
+	outcomes     map[string]*entities.QueryOutcome
+	variantStats []*entities.ExperimentVariantStats
+}
+
+func (r *stubTriageAnalyticsRepo) LogEvent(ctx context.Context, event *entities.SearchEvent) error {
+	r.events = append(r.events, event)
+	return nil
+}
+
+func (r *stubTriageAnalyticsRepo) CopyEvent(ctx context.Context, sourceID, newID string) (bool, error) {
+	return false, nil
+}
+
+func (r *stubTriageAnalyticsRepo) GetZeroResultQueries(ctx context.Context, limit int) ([]*entities.SearchEvent, error) {
+	return r.events, nil
+}
+
+func (r *stubTriageAnalyticsRepo) GetLowResultQueries(ctx context.Context, maxResultCount int, since time.Time, limit int) ([]*entities.SearchEvent, error) {
+	var out []*entities.SearchEvent
+	for _, e := range r.events {
+		if e.ResultCount <= maxResultCount && !e.CreatedAt.Before(since) {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+func (r *stubTriageAnalyticsRepo) GetQueryOutcome(ctx context.Context, normalizedQuery string, since time.Time) (*entities.QueryOutcome, error) {
+	if outcome, ok := r.outcomes[normalizedQuery]; ok {
+		return outcome, nil
+	}
+	return &entities.QueryOutcome{}, nil
+}
+
+func (r *stubTriageAnalyticsRepo) GetExperimentVariantStats(ctx context.Context, experimentID string) ([]*entities.ExperimentVariantStats, error) {
+	return r.variantStats, nil
+}
+
+type stubDictionaryFixRepo struct {
+	fixes   []*entities.SearchDictionaryFix
+	updates int
+}
+
+func (r *stubDictionaryFixRepo) Create(ctx context.Context, fix *entities.SearchDictionaryFix) error {
+	if fix.ID == "" {
+		fix.ID = "fix-" + fix.Term
+	}
+	r.fixes = append([]*entities.SearchDictionaryFix{fix}, r.fixes...)
+	return nil
+}
+
+func (r *stubDictionaryFixRepo) Update(ctx context.Context, fix *entities.SearchDictionaryFix) error {
+	r.updates++
+	return nil
+}
+
+func (r *stubDictionaryFixRepo) List(ctx context.Context, status entities.DictionaryFixStatus) ([]*entities.SearchDictionaryFix, error) {
+	var out []*entities.SearchDictionaryFix
+	for _, fix := range r.fixes {
+		if status == "" || fix.Status == status {
+			out = append(out, fix)
+		}
+	}
+	return out, nil
+}
+
+var _ repositories.SearchAnalyticsRepository = (*stubTriageAnalyticsRepo)(nil)
+var _ repositories.SearchDictionaryFixRepository = (*stubDictionaryFixRepo)(nil)
+
+func newTestTriageService(t *testing.T, analytics *stubTriageAnalyticsRepo, fixes *stubDictionaryFixRepo, now time.Time) *SearchTriageService {
+	t.Helper()
+	svc := NewSearchTriageService(analytics, fixes, nil, newTestQueryService(t))
+	svc.now = func() time.Time { return now }
+	return svc
+}
+
+func TestSearchTriage_ClustersByNormalizedQuery(t *testing.T) {
+	now := time.Date(2026, 3, 14, 12, 0, 0, 0, time.UTC)
+	analytics := &stubTriageAnalyticsRepo{events: []*entities.SearchEvent{
+		{Query: "Malarai", NormalizedQuery: "malarai", CreatedAt: now.Add(-1 * time.Hour), UserLatitude: 6.52, UserLongitude: 3.37},
+		{Query: "malarai!", NormalizedQuery: "malarai", CreatedAt: now.Add(-2 * time.Hour), UserLatitude: 6.54, UserLongitude: 3.38},
+		{Query: "MALARAI", CreatedAt: now.Add(-24 * time.Hour), ResultCount: 1},
+		{Query: "zzzz", NormalizedQuery: "zzzz", CreatedAt: now.Add(-3 * time.Hour)},
+	}}
+	svc := newTestTriageService(t, analytics, &stubDictionaryFixRepo{}, now)
+
+	clusters, err := svc.GetClusters(context.Background(), SearchTriageOptions{WindowDays: 7, MaxResultCount: 2})
+	require.NoError(t, err)
+	require.Len(t, clusters, 2)
+
+	top := clusters[0]
+	assert.Equal(t, "malarai", top.NormalizedQuery)
+	assert.Equal(t, 3, top.Occurrences)
+	assert.Equal(t, 2, top.ZeroResultCount)
+	assert.Equal(t, 1, top.LowResultCount)
+	assert.Len(t, top.DailyCounts, 7)
+	require.Len(t, top.Geography, 1)
+	assert.Equal(t, 2, top.Geography[0].Count)
+
+	require.NotEmpty(t, top.Suggestions)
+	assert.Equal(t, entities.DictionaryFixSpelling, top.Suggestions[0].FixType)
+	assert.Equal(t, "malaria", top.Suggestions[0].Target)
+	assert.Equal(t, "concept", top.Suggestions[0].Source)
+}
+
+func TestSearchTriage_ZeroOnlyByDefault(t *testing.T) {
+	now := time.Date(2026, 3, 14, 12, 0, 0, 0, time.UTC)
+	analytics := &stubTriageAnalyticsRepo{events: []*entities.SearchEvent{
+		{Query: "malarai", NormalizedQuery: "malarai", CreatedAt: now, ResultCount: 1},
+	}}
+	svc := newTestTriageService(t, analytics, &stubDictionaryFixRepo{}, now)
+
+	clusters, err := svc.GetClusters(context.Background(), SearchTriageOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, clusters)
+}
+
+func TestSearchTriage_PendingFixOnlyForPendingStatus(t *testing.T) {
+	now := time.Date(2026, 3, 14, 12, 0, 0, 0, time.UTC)
+	analytics := &stubTriageAnalyticsRepo{events: []*entities.SearchEvent{
+		{Query: "malarai", NormalizedQuery: "malarai", CreatedAt: now},
+		{Query: "zzzz", NormalizedQuery: "zzzz", CreatedAt: now},
+	}}
+	fixes := &stubDictionaryFixRepo{fixes: []*entities.SearchDictionaryFix{
+		{ID: "fix-1", NormalizedQuery: "malarai", Status: entities.DictionaryFixStatusPending},
+		{ID: "fix-2", NormalizedQuery: "zzzz", Status: entities.DictionaryFixStatusResolved},
+	}}
+	svc := newTestTriageService(t, analytics, fixes, now)
+
+	clusters, err := svc.GetClusters(context.Background(), SearchTriageOptions{WindowDays: 7})
+	require.NoError(t, err)
+	require.Len(t, clusters, 2)
+	for _, cluster := range clusters {
+		if cluster.NormalizedQuery == "malarai" {
+			require.NotNil(t, cluster.PendingFix)
+			assert.Equal(t, "fix-1", cluster.PendingFix.ID)
+		} else {
+			assert.Nil(t, cluster.PendingFix)
+		}
+	}
+}
+
+func TestSearchTriage_AcceptSpellingFixAppliesToQueryUnderstanding(t *testing.T) {
+	now := time.Date(2026, 3, 14, 12, 0, 0, 0, time.UTC)
+	fixes := &stubDictionaryFixRepo{}
+	svc := newTestTriageService(t, &stubTriageAnalyticsRepo{}, fixes, now)
+
+	before := svc.queryUnderstanding.Interpret("malarai")
+	assert.Nil(t, before.MappedConcepts)
+
+	err := svc.AcceptFix(context.Background(), &entities.SearchDictionaryFix{
+		FixType: entities.DictionaryFixSpelling,
+		Term:    "Malarai",
+		Target:  "malaria",
+	})
+	require.NoError(t, err)
+	require.Len(t, fixes.fixes, 1)
+	assert.Equal(t, "malarai", fixes.fixes[0].NormalizedQuery)
+	assert.Equal(t, entities.DictionaryFixStatusPending, fixes.fixes[0].Status)
+
+	after := svc.queryUnderstanding.Interpret("malarai")
+	assert.Equal(t, "malaria", after.CorrectedQuery)
+	require.NotNil(t, after.MappedConcepts)
+	assert.Contains(t, after.MappedConcepts.Conditions, "malaria")
+}
+
+func TestSearchTriage_AcceptSynonymForProcedureName(t *testing.T) {
+	now := time.Date(2026, 3, 14, 12, 0, 0, 0, time.UTC)
+	svc := newTestTriageService(t, &stubTriageAnalyticsRepo{}, &stubDictionaryFixRepo{}, now)
+
+	err := svc.AcceptFix(context.Background(), &entities.SearchDictionaryFix{
+		FixType: entities.DictionaryFixSynonym,
+		Term:    "belly scan",
+		Target:  "abdominal ultrasound scan",
+	})
+	require.NoError(t, err)
+
+	result := svc.queryUnderstanding.Interpret("belly scan")
+	require.NotNil(t, result.MappedConcepts)
+	assert.Contains(t, result.SearchTerms, "abdominal ultrasound scan")
+}
+
+func TestSearchTriage_LoadAcceptedFixesPicksUpOtherReplicas(t *testing.T) {
+	now := time.Date(2026, 3, 14, 12, 0, 0, 0, time.UTC)
+	fixes := &stubDictionaryFixRepo{}
+	svc := newTestTriageService(t, &stubTriageAnalyticsRepo{}, fixes, now)
+	require.NoError(t, svc.AcceptFix(context.Background(), &entities.SearchDictionaryFix{
+		FixType: entities.DictionaryFixSpelling, Term: "malarai", Target: "malaria",
+	}))
+
+	// Another replica accepts a fix straight into the store
+	fixes.fixes = append([]*entities.SearchDictionaryFix{{
+		ID: "fix-elsewhere", FixType: entities.DictionaryFixSpelling, Term: "typhiod", Target: "typhoid",
+	}}, fixes.fixes...)
+	assert.NotEqual(t, "typhoid", svc.queryUnderstanding.Interpret("typhiod").CorrectedQuery)
+
+	applied, err := svc.LoadAcceptedFixes(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, applied, "only the fix not yet applied here")
+	assert.Equal(t, "typhoid", svc.queryUnderstanding.Interpret("typhiod").CorrectedQuery)
+
+	applied, err = svc.LoadAcceptedFixes(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, applied)
+}
+
+func TestSearchTriage_AcceptFixValidation(t *testing.T) {
+	svc := newTestTriageService(t, &stubTriageAnalyticsRepo{}, &stubDictionaryFixRepo{}, time.Now())
+
+	cases := []*entities.SearchDictionaryFix{
+		{FixType: entities.DictionaryFixSpelling, Term: "", Target: "malaria"},
+		{FixType: entities.DictionaryFixSpelling, Term: "two words", Target: "malaria"},
+		{FixType: "other", Term: "malarai", Target: "malaria"},
+		{FixType: entities.DictionaryFixSynonym, Term: "malaria", Target: "malaria"},
+	}
+	for _, fix := range cases {
+		assert.Error(t, svc.AcceptFix(context.Background(), fix))
+	}
+}
+
+func TestSearchTriage_RefreshFixOutcomes(t *testing.T) {
+	now := time.Date(2026, 3, 14, 12, 0, 0, 0, time.UTC)
+	fixes := &stubDictionaryFixRepo{fixes: []*entities.SearchDictionaryFix{
+		{ID: "1", NormalizedQuery: "malarai", Status: entities.DictionaryFixStatusPending},
+		{ID: "2", NormalizedQuery: "zzzz", Status: entities.DictionaryFixStatusPending},
+		{ID: "3", NormalizedQuery: "quiet", Status: entities.DictionaryFixStatusPending},
+	}}
+	analytics := &stubTriageAnalyticsRepo{outcomes: map[string]*entities.QueryOutcome{
+		"malarai": {Searches: 2, WithResult: 2},
+		"zzzz":    {Searches: 5, WithResult: 0},
+		"quiet":   {Searches: 1, WithResult: 0},
+	}}
+	svc := newTestTriageService(t, analytics, fixes, now)
+
+	list, err := svc.ListFixes(context.Background(), "")
+	require.NoError(t, err)
+	assert.Zero(t, fixes.updates, "listing fixes does not write")
+	assert.Equal(t, entities.DictionaryFixStatusPending, list[0].Status)
+
+	require.NoError(t, svc.RefreshFixOutcomes(context.Background()))
+	list, err = svc.ListFixes(context.Background(), "")
+	require.NoError(t, err)
+	require.Len(t, list, 3)
+	assert.Equal(t, entities.DictionaryFixStatusResolved, list[0].Status)
+	assert.Equal(t, 2, list[0].SuccessfulAfter)
+	assert.Equal(t, entities.DictionaryFixStatusUnresolved, list[1].Status)
+	assert.Equal(t, entities.DictionaryFixStatusPending, list[2].Status)
+	require.NotNil(t, list[2].LastCheckedAt)
+}
+
+func TestClassifyTrend(t *testing.T) {
+	points := func(counts ...int) []entities.QueryTrendPoint {
+		out := make([]entities.QueryTrendPoint, len(counts))
+		for i, c := range counts {
+			out[i].Count = c
+		}
+		return out
+	}
+	assert.Equal(t, "rising", classifyTrend(points(0, 0, 1, 3)))
+	assert.Equal(t, "falling", classifyTrend(points(4, 2, 0, 1)))
+	assert.Equal(t, "stable", classifyTrend(points(1, 1, 1, 1)))
+}
+
+func TestStringSimilarity(t *testing.T) {
+	assert.InDelta(t, 1.0, stringSimilarity("malaria", "malaria"), 0.001)
+	assert.InDelta(t, 0.714, stringSimilarity("malarai", "malaria"), 0.001)
+	assert.Less(t, stringSimilarity("xray", "malaria"), 0.5)
+}
@@ -0,0 +1,94 @@
+package services
+
+import (
+	"context"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/providers"
+)
+
+const (
+	// queryEmbeddingTimeout bounds how long a search waits for its query
+	// embedding before falling back to keyword matching alone
+	queryEmbeddingTimeout = 2 * time.Second
+
+	// queryEmbeddingCacheSize is how many query embeddings are kept; the
+	// cache is cleared when it fills
+	queryEmbeddingCacheSize = 1000
+)
+
+// SemanticSearch embeds search queries so keyword search can be fused with
+// vector similarity. Documents must have been embedded by the same provider.
+type SemanticSearch struct {
+	provider      providers.EmbeddingProvider
+	vectorWeight  float64
+	minSimilarity float64
+
+	mu    sync.Mutex
+	cache map[string][]float32
+}
+
+// NewSemanticSearch creates a new semantic search. vectorWeight is the share
+// of the fused rank given to vector similarity and minSimilarity the cosine
+// similarity below which vector matches are dropped.
+func NewSemanticSearch(provider providers.EmbeddingProvider, vectorWeight, minSimilarity float64) *SemanticSearch {
+	if vectorWeight <= 0 || vectorWeight >= 1 {
+		vectorWeight = 0.3
+	}
+	return &SemanticSearch{
+		provider:      provider,
+		vectorWeight:  vectorWeight,
+		minSimilarity: minSimilarity,
+		cache:         map[string][]float32{},
+	}
+}
+
+// EmbeddingModel returns the model query embeddings come from
+func (s *SemanticSearch) EmbeddingModel() string {
+	return s.provider.EmbeddingModel()
+}
+
+// VectorWeight returns the share of the fused rank given to vector similarity
+func (s *SemanticSearch) VectorWeight() float64 {
+	return s.vectorWeight
+}
+
+// MinSimilarity returns the similarity below which vector matches are dropped
+func (s *SemanticSearch) MinSimilarity() float64 {
+	return s.minSimilarity
+}
+
+// EmbedQuery returns the query's embedding, or nil when it is empty or the
+// provider fails, in which case the search stays keyword-only
+func (s *SemanticSearch) EmbedQuery(ctx context.Context, query string) []float32 {
+	key := strings.ToLower(strings.Join(strings.Fields(query), " "))
+	if key == "" {
+		return nil
+	}
+
+	s.mu.Lock()
+	embedding, ok := s.cache[key]
+	s.mu.Unlock()
+	if ok {
+		return embedding
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, queryEmbeddingTimeout)
+	defer cancel()
+	vectors, err := s.provider.Embed(ctx, []string{key})
+	if err != nil || len(vectors) != 1 {
+		log.Printf("Warning: failed to embed query %q, searching by keyword only: %v", query, err)
+		return nil
+	}
+
+	s.mu.Lock()
+	if len(s.cache) >= queryEmbeddingCacheSize {
+		s.cache = map[string][]float32{}
+	}
+	s.cache[key] = vectors[0]
+	s.mu.Unlock()
+	return vectors[0]
+}
@@ -0,0 +1,105 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/repositories"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/evaluation"
+)
+
+// stubEmbeddingProvider embeds texts by whether they mention malaria or
+// mosquitoes, counting the texts it is asked to embed
+type stubEmbeddingProvider struct {
+	calls int
+	err   error
+}
+
+func (p *stubEmbeddingProvider) EmbeddingModel() string { return "stub" }
+
+func (p *stubEmbeddingProvider) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	p.calls += len(texts)
+	if p.err != nil {
+		return nil, p.err
+	}
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		text = strings.ToLower(text)
+		if strings.Contains(text, "malaria") || strings.Contains(text, "mosquito") {
+			vectors[i] = []float32{1, 0}
+		} else {
+			vectors[i] = []float32{0, 1}
+		}
+	}
+	return vectors, nil
+}
+
+func TestSemanticSearch_EmbedQueryCachesNormalizedQueries(t *testing.T) {
+	provider := &stubEmbeddingProvider{}
+	semantic := NewSemanticSearch(provider, 0.4, 0.5)
+
+	first := semantic.EmbedQuery(context.Background(), "Mosquito  bite")
+	second := semantic.EmbedQuery(context.Background(), " mosquito bite ")
+	if first == nil || second == nil {
+		t.Fatalf("expected embeddings, got %v and %v", first, second)
+	}
+	if provider.calls != 1 {
+		t.Fatalf("expected the second query to be served from cache, got %d provider calls", provider.calls)
+	}
+	if semantic.VectorWeight() != 0.4 || semantic.MinSimilarity() != 0.5 {
+		t.Fatalf("unexpected weights %f/%f", semantic.VectorWeight(), semantic.MinSimilarity())
+	}
+}
+
+func TestSemanticSearch_EmbedQueryFallsBackToKeywords(t *testing.T) {
+	provider := &stubEmbeddingProvider{err: errors.New("provider down")}
+	semantic := NewSemanticSearch(provider, 0, 0)
+
+	if embedding := semantic.EmbedQuery(context.Background(), "malaria"); embedding != nil {
+		t.Fatalf("expected no embedding when the provider fails, got %v", embedding)
+	}
+	if embedding := semantic.EmbedQuery(context.Background(), "   "); embedding != nil {
+		t.Fatalf("expected no embedding for an empty query, got %v", embedding)
+	}
+	if provider.calls != 1 {
+		t.Fatalf("expected the empty query not to reach the provider, got %d calls", provider.calls)
+	}
+	if semantic.VectorWeight() != 0.3 {
+		t.Fatalf("expected the default vector weight, got %f", semantic.VectorWeight())
+	}
+}
+
+func TestFacilitySearch_SemanticSearchFindsParaphrases(t *testing.T) {
+	provider := &stubEmbeddingProvider{}
+	corpus := evaluation.NewFixtureCorpus([]*evaluation.FixtureFacility{
+		{Facility: entities.Facility{ID: "lab", Name: "City Lab", IsActive: true}, Keywords: []string{"malaria"}},
+		{Facility: entities.Facility{ID: "dental", Name: "Smile Dental", IsActive: true}, Keywords: []string{"tooth"}},
+	})
+	if err := corpus.EmbedFacilities(context.Background(), provider); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	facilityService := NewFacilityService(corpus, nil, nil, nil, nil)
+	facilityService.SetSemanticSearch(NewSemanticSearch(provider, 0.3, 0.5))
+
+	params := repositories.SearchParams{Query: "mosquito bite", Limit: 10}
+	results, total, _, err := facilityService.SearchResultsWithCount(context.Background(), params)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if total != 1 || len(results) != 1 || results[0].ID != "lab" {
+		t.Fatalf("expected the malaria lab for a paraphrase, got %d results", total)
+	}
+
+	// The feature flag turns vector similarity off
+	facilityService.SetFeatureFlags(&FeatureFlags{})
+	_, total, _, err = facilityService.SearchResultsWithCount(context.Background(), params)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if total != 0 {
+		t.Fatalf("expected keyword-only search to find nothing, got %d", total)
+	}
+}
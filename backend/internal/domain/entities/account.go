@@ -0,0 +1,31 @@
+package entities
+
+import "time"
+
+// LoginOTP is a one-time login code sent to a phone number
+type LoginOTP struct {
+	ID         string              `json:"id" db:"id"`
+	Phone      string              `json:"phone" db:"phone"`
+	CodeHash   string              `json:"-" db:"code_hash"`
+	Channel    NotificationChannel `json:"channel" db:"channel"`
+	Attempts   int                 `json:"attempts" db:"attempts"`
+	ExpiresAt  time.Time           `json:"expires_at" db:"expires_at"`
+	ConsumedAt *time.Time          `json:"consumed_at,omitempty" db:"consumed_at"`
+	CreatedAt  time.Time           `json:"created_at" db:"created_at"`
+}
+
+// UserSession is a bearer session issued after a successful login
+type UserSession struct {
+	ID        string    `json:"id" db:"id"`
+	UserID    string    `json:"user_id" db:"user_id"`
+	TokenHash string    `json:"-" db:"token_hash"`
+	ExpiresAt time.Time `json:"expires_at" db:"expires_at"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// SavedFacility is a facility a patient has marked as a favourite
+type SavedFacility struct {
+	UserID     string    `json:"user_id" db:"user_id"`
+	FacilityID string    `json:"facility_id" db:"facility_id"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
@@ -21,6 +21,7 @@ const (
 	BookingMethodManual   BookingMethod = "manual"
 	BookingMethodCalendly BookingMethod = "calendly"
 	BookingMethodAPI      BookingMethod = "api"
+	BookingMethodNative   BookingMethod = "native"
 )
 
 // Appointment represents a scheduled appointment
@@ -44,17 +45,35 @@ type Appointment struct {
 	CalendlyInviteeURI *string       `json:"calendly_invitee_uri,omitempty" db:"calendly_invitee_uri"`
 	MeetingLink        *string       `json:"meeting_link,omitempty" db:"meeting_link"`
 	BookingMethod      BookingMethod `json:"booking_method" db:"booking_method"`
-	CreatedAt          time.Time     `json:"created_at" db:"created_at"`
-	UpdatedAt          time.Time     `json:"updated_at" db:"updated_at"`
+	// Native calendar fields. SlotID or HoldID select the slot when booking;
+	// SlotReservationID is the resulting booking on the slot.
+	SlotID            string    `json:"slot_id,omitempty" db:"-"`
+	HoldID            string    `json:"hold_id,omitempty" db:"-"`
+	SlotReservationID *string   `json:"slot_reservation_id,omitempty" db:"slot_reservation_id"`
+	CreatedAt         time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at" db:"updated_at"`
 }
 
 // AvailabilitySlot represents an available time slot at a facility
 type AvailabilitySlot struct {
 	ID         string    `json:"id" db:"id"`
 	FacilityID string    `json:"facility_id" db:"facility_id"`
+	WardID     *string   `json:"ward_id,omitempty" db:"ward_id"`
+	TemplateID *string   `json:"template_id,omitempty" db:"template_id"`
 	StartTime  time.Time `json:"start_time" db:"start_time"`
 	EndTime    time.Time `json:"end_time" db:"end_time"`
 	IsBooked   bool      `json:"is_booked" db:"is_booked"`
+	Capacity   int       `json:"capacity,omitempty" db:"capacity"`
+	Booked     int       `json:"booked,omitempty" db:"-"` // confirmed bookings (native calendars)
+	Held       int       `json:"held,omitempty" db:"-"`   // unexpired checkout holds (native calendars)
 	CreatedAt  time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
 }
+
+// Remaining returns how many more bookings the slot can take.
+func (s *AvailabilitySlot) Remaining() int {
+	if r := s.Capacity - s.Booked - s.Held; r > 0 {
+		return r
+	}
+	return 0
+}
@@ -0,0 +1,100 @@
+package entities
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DefaultCalendarTimezone is used when a facility calendar does not set one.
+const DefaultCalendarTimezone = "Africa/Lagos"
+
+// DefaultSlotHoldMinutes is how long a checkout hold keeps a slot reserved.
+const DefaultSlotHoldMinutes = 10
+
+// FacilityCalendar enables native scheduling for a facility. Facilities
+// without an enabled calendar keep using Calendly.
+type FacilityCalendar struct {
+	FacilityID  string    `json:"facility_id" db:"facility_id"`
+	Enabled     bool      `json:"enabled" db:"enabled"`
+	Timezone    string    `json:"timezone" db:"timezone"`
+	HoldMinutes int       `json:"hold_minutes" db:"hold_minutes"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// SlotTemplate is a recurring weekly opening, split into slots of SlotMinutes.
+// StartTime and EndTime are "HH:MM" wall-clock times in the calendar timezone.
+type SlotTemplate struct {
+	ID          string       `json:"id" db:"id"`
+	FacilityID  string       `json:"facility_id" db:"facility_id"`
+	WardID      *string      `json:"ward_id,omitempty" db:"ward_id"`
+	Weekday     time.Weekday `json:"weekday" db:"weekday"` // 0 = Sunday
+	StartTime   string       `json:"start_time" db:"start_time"`
+	EndTime     string       `json:"end_time" db:"end_time"`
+	SlotMinutes int          `json:"slot_minutes" db:"slot_minutes"`
+	Capacity    int          `json:"capacity" db:"capacity"`
+	ValidFrom   *time.Time   `json:"valid_from,omitempty" db:"valid_from"`
+	ValidUntil  *time.Time   `json:"valid_until,omitempty" db:"valid_until"`
+	CreatedAt   time.Time    `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at" db:"updated_at"`
+}
+
+// CalendarBlackout closes a facility, or one ward when WardID is set, for a period.
+type CalendarBlackout struct {
+	ID         string    `json:"id" db:"id"`
+	FacilityID string    `json:"facility_id" db:"facility_id"`
+	WardID     *string   `json:"ward_id,omitempty" db:"ward_id"`
+	StartsAt   time.Time `json:"starts_at" db:"starts_at"`
+	EndsAt     time.Time `json:"ends_at" db:"ends_at"`
+	Reason     string    `json:"reason,omitempty" db:"reason"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
+
+// Covers reports whether the blackout closes the given slot.
+func (b *CalendarBlackout) Covers(slot *AvailabilitySlot) bool {
+	if b.WardID != nil && (slot.WardID == nil || *slot.WardID != *b.WardID) {
+		return false
+	}
+	return slot.StartTime.Before(b.EndsAt) && slot.EndTime.After(b.StartsAt)
+}
+
+// SlotReservationStatus is the state of a hold or booking on a slot.
+type SlotReservationStatus string
+
+const (
+	SlotReservationHeld      SlotReservationStatus = "held"
+	SlotReservationBooked    SlotReservationStatus = "booked"
+	SlotReservationReleased  SlotReservationStatus = "released"
+	SlotReservationCancelled SlotReservationStatus = "cancelled"
+)
+
+// SlotReservation takes one unit of a slot's capacity. Holds expire at
+// ExpiresAt unless confirmed into a booking.
+type SlotReservation struct {
+	ID         string                `json:"id" db:"id"`
+	SlotID     string                `json:"slot_id" db:"slot_id"`
+	FacilityID string                `json:"facility_id" db:"-"`
+	StartTime  time.Time             `json:"start_time" db:"-"`
+	Status     SlotReservationStatus `json:"status" db:"status"`
+	ExpiresAt  *time.Time            `json:"expires_at,omitempty" db:"expires_at"`
+	CreatedAt  time.Time             `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time             `json:"updated_at" db:"updated_at"`
+}
+
+// ParseClock parses an "HH:MM" wall-clock time into minutes after midnight.
+// "24:00" is accepted as the end of the day.
+func ParseClock(value string) (int, error) {
+	parts := strings.Split(strings.TrimSpace(value), ":")
+	if len(parts) != 2 || len(parts[0]) != 2 || len(parts[1]) != 2 {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", value)
+	}
+	var h, m int
+	if _, err := fmt.Sscanf(parts[0]+" "+parts[1], "%d %d", &h, &m); err != nil {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", value)
+	}
+	if h < 0 || m < 0 || m > 59 || h > 24 || (h == 24 && m != 0) {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", value)
+	}
+	return h*60 + m, nil
+}
@@ -0,0 +1,168 @@
+package entities
+
+import "time"
+
+// CapacityStatusUnknown replaces a capacity status whose report has expired
+const CapacityStatusUnknown = "unknown"
+
+// CapacityStatusClosed is reported by a facility not receiving patients
+const CapacityStatusClosed = "closed"
+
+// CapacityField names a capacity value that is reported by facilities and
+// expires when it is not refreshed
+type CapacityField string
+
+const (
+	CapacityFieldStatus     CapacityField = "capacity_status"
+	CapacityFieldWaitTime   CapacityField = "avg_wait_minutes"
+	CapacityFieldUrgentCare CapacityField = "urgent_care_available"
+)
+
+// CapacityFields lists every capacity field in a stable order
+var CapacityFields = []CapacityField{CapacityFieldStatus, CapacityFieldWaitTime, CapacityFieldUrgentCare}
+
+// FreshnessState says how much a capacity value can be trusted
+type FreshnessState string
+
+const (
+	FreshnessFresh FreshnessState = "fresh"
+	// FreshnessExpiring values are still valid but the operator has been asked to refresh them
+	FreshnessExpiring FreshnessState = "expiring"
+	FreshnessStale    FreshnessState = "stale"
+)
+
+// DataFreshness accompanies a capacity value shown to patients
+type DataFreshness struct {
+	State      FreshnessState `json:"state"`
+	ReportedAt *time.Time     `json:"reported_at,omitempty"`
+	ExpiresAt  *time.Time     `json:"expires_at,omitempty"`
+}
+
+// CapacityFreshnessPolicy sets how long each capacity field stays valid after
+// it was reported
+type CapacityFreshnessPolicy struct {
+	CapacityStatusTTL time.Duration
+	WaitTimeTTL       time.Duration
+	UrgentCareTTL     time.Duration
+	// NudgeBefore is how long before a value expires its operator is reminded
+	// to refresh it
+	NudgeBefore time.Duration
+}
+
+// DefaultCapacityFreshnessPolicy returns the windows used when none are configured
+func DefaultCapacityFreshnessPolicy() CapacityFreshnessPolicy {
+	return CapacityFreshnessPolicy{
+		CapacityStatusTTL: 12 * time.Hour,
+		WaitTimeTTL:       4 * time.Hour,
+		UrgentCareTTL:     24 * time.Hour,
+		NudgeBefore:       time.Hour,
+	}
+}
+
+// TTL returns how long the field stays valid after it was reported
+func (p CapacityFreshnessPolicy) TTL(field CapacityField) time.Duration {
+	switch field {
+	case CapacityFieldStatus:
+		return p.CapacityStatusTTL
+	case CapacityFieldWaitTime:
+		return p.WaitTimeTTL
+	case CapacityFieldUrgentCare:
+		return p.UrgentCareTTL
+	default:
+		return 0
+	}
+}
+
+// Freshness rates a field reported at reportedAt. A value with no report time
+// is treated as stale.
+func (p CapacityFreshnessPolicy) Freshness(field CapacityField, reportedAt *time.Time, now time.Time) DataFreshness {
+	if reportedAt == nil || reportedAt.IsZero() {
+		return DataFreshness{State: FreshnessStale}
+	}
+
+	reported := *reportedAt
+	expires := reported.Add(p.TTL(field))
+	freshness := DataFreshness{State: FreshnessFresh, ReportedAt: &reported, ExpiresAt: &expires}
+	switch {
+	case !now.Before(expires):
+		freshness.State = FreshnessStale
+	case !now.Before(expires.Add(-p.NudgeBefore)):
+		freshness.State = FreshnessExpiring
+	}
+	return freshness
+}
+
+// CapacityValues returns the facility's reported capacity fields with the time
+// each was reported. Fields without a value, or already decayed to unknown,
+// are left out.
+func (f *Facility) CapacityValues() map[CapacityField]*time.Time {
+	values := make(map[CapacityField]*time.Time, len(CapacityFields))
+	if f.CapacityStatus != nil && *f.CapacityStatus != "" && *f.CapacityStatus != CapacityStatusUnknown {
+		values[CapacityFieldStatus] = f.CapacityStatusReportedAt
+	}
+	if f.AvgWaitMinutes != nil {
+		values[CapacityFieldWaitTime] = f.AvgWaitReportedAt
+	}
+	if f.UrgentCareAvailable != nil {
+		values[CapacityFieldUrgentCare] = f.UrgentCareReportedAt
+	}
+	return values
+}
+
+// ClearCapacity resets a capacity field to unknown without touching when it
+// was reported
+func (f *Facility) ClearCapacity(field CapacityField) {
+	switch field {
+	case CapacityFieldStatus:
+		unknown := CapacityStatusUnknown
+		f.CapacityStatus = &unknown
+	case CapacityFieldWaitTime:
+		f.AvgWaitMinutes = nil
+	case CapacityFieldUrgentCare:
+		f.UrgentCareAvailable = nil
+	}
+}
+
+// MarkCapacityReported records that the facility's current value for the
+// field was reported at the given time
+func (f *Facility) MarkCapacityReported(field CapacityField, at time.Time) {
+	switch field {
+	case CapacityFieldStatus:
+		f.CapacityStatusReportedAt = &at
+	case CapacityFieldWaitTime:
+		f.AvgWaitReportedAt = &at
+	case CapacityFieldUrgentCare:
+		f.UrgentCareReportedAt = &at
+	}
+}
+
+// CapacityValues returns the ward's reported capacity fields, all reported at
+// LastUpdated. Fields without a value, or already decayed to unknown, are
+// left out.
+func (w *FacilityWard) CapacityValues() map[CapacityField]*time.Time {
+	reportedAt := w.LastUpdated
+	values := make(map[CapacityField]*time.Time, len(CapacityFields))
+	if w.CapacityStatus != nil && *w.CapacityStatus != "" && *w.CapacityStatus != CapacityStatusUnknown {
+		values[CapacityFieldStatus] = &reportedAt
+	}
+	if w.AvgWaitMinutes != nil {
+		values[CapacityFieldWaitTime] = &reportedAt
+	}
+	if w.UrgentCareAvailable != nil {
+		values[CapacityFieldUrgentCare] = &reportedAt
+	}
+	return values
+}
+
+// ClearCapacity resets a ward capacity field to unknown
+func (w *FacilityWard) ClearCapacity(field CapacityField) {
+	switch field {
+	case CapacityFieldStatus:
+		unknown := CapacityStatusUnknown
+		w.CapacityStatus = &unknown
+	case CapacityFieldWaitTime:
+		w.AvgWaitMinutes = nil
+	case CapacityFieldUrgentCare:
+		w.UrgentCareAvailable = nil
+	}
+}
@@ -0,0 +1,34 @@
+package entities
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCapacityFreshnessPolicy_Freshness(t *testing.T) {
+	policy := CapacityFreshnessPolicy{CapacityStatusTTL: 12 * time.Hour, NudgeBefore: time.Hour}
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		reportedAt *time.Time
+		want       FreshnessState
+	}{
+		{name: "recent report is fresh", reportedAt: ptrTime(now.Add(-time.Hour)), want: FreshnessFresh},
+		{name: "report inside the nudge window is expiring", reportedAt: ptrTime(now.Add(-11*time.Hour - 30*time.Minute)), want: FreshnessExpiring},
+		{name: "report past its window is stale", reportedAt: ptrTime(now.Add(-12 * time.Hour)), want: FreshnessStale},
+		{name: "value never reported is stale", reportedAt: nil, want: FreshnessStale},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := policy.Freshness(CapacityFieldStatus, tt.reportedAt, now)
+			if got.State != tt.want {
+				t.Fatalf("expected %s, got %s", tt.want, got.State)
+			}
+		})
+	}
+}
+
+func ptrTime(t time.Time) *time.Time {
+	return &t
+}
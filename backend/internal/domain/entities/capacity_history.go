@@ -0,0 +1,64 @@
+package entities
+
+import "time"
+
+// HoursPerWeek is the number of hour-of-week buckets in a wait time profile
+const HoursPerWeek = 7 * 24
+
+// CapacityObservation is one capacity report for a facility, or one of its
+// wards when WardName is set. Fields that were not part of the report are nil.
+type CapacityObservation struct {
+	ID             int64     `json:"id" db:"id"`
+	FacilityID     string    `json:"facility_id" db:"facility_id"`
+	WardName       string    `json:"ward_name,omitempty" db:"ward_name"`
+	CapacityStatus *string   `json:"capacity_status,omitempty" db:"capacity_status"`
+	AvgWaitMinutes *int      `json:"avg_wait_minutes,omitempty" db:"avg_wait_minutes"`
+	ObservedAt     time.Time `json:"observed_at" db:"observed_at"`
+}
+
+// WaitTimeProfile holds the typical capacity of a facility or ward during one
+// hour of the week, averaged over its history with recent weeks weighted more
+type WaitTimeProfile struct {
+	FacilityID string `json:"facility_id" db:"facility_id"`
+	WardName   string `json:"ward_name,omitempty" db:"ward_name"`
+	// HourOfWeek counts hours from Sunday 00:00 in the capacity timezone
+	HourOfWeek     int      `json:"hour_of_week" db:"hour_of_week"`
+	AvgWaitMinutes *float64 `json:"avg_wait_minutes,omitempty" db:"avg_wait_minutes"`
+	// WaitWeight is the total recency-weighted hours behind AvgWaitMinutes
+	WaitWeight            float64   `json:"wait_weight" db:"wait_weight"`
+	TypicalCapacityStatus *string   `json:"typical_capacity_status,omitempty" db:"typical_capacity_status"`
+	Samples               int       `json:"samples" db:"samples"`
+	UpdatedAt             time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// HourOfWeek returns the hour-of-week bucket t falls in, in t's location
+func HourOfWeek(t time.Time) int {
+	return int(t.Weekday())*24 + t.Hour()
+}
+
+// ForecastBasis says what a wait forecast was made from
+type ForecastBasis string
+
+const (
+	// ForecastBasisLive uses the current report alone; there is no history yet
+	ForecastBasisLive ForecastBasis = "live"
+	// ForecastBasisSeasonal uses the hour-of-week profile alone
+	ForecastBasisSeasonal ForecastBasis = "seasonal"
+	// ForecastBasisBlended mixes the current report into the profile, weighted
+	// by how close the arrival is to when the report was made
+	ForecastBasisBlended ForecastBasis = "blended"
+	// ForecastBasisNone means there is nothing to forecast from
+	ForecastBasisNone ForecastBasis = "none"
+)
+
+// WaitForecast is the expected wait for an arrival at a facility or ward
+type WaitForecast struct {
+	FacilityID            string        `json:"facility_id"`
+	WardName              string        `json:"ward_name,omitempty"`
+	At                    time.Time     `json:"at"`
+	ExpectedWaitMinutes   *int          `json:"expected_wait_minutes"`
+	TypicalCapacityStatus *string       `json:"typical_capacity_status,omitempty"`
+	Basis                 ForecastBasis `json:"basis"`
+	// Samples counts the historical reports behind the seasonal part
+	Samples int `json:"samples"`
+}
@@ -0,0 +1,103 @@
+package entities
+
+// ProcedureOffer is one facility's price for a procedure, with how far the
+// facility is from the patient
+type ProcedureOffer struct {
+	FacilityID    string   `json:"facility_id"`
+	FacilityName  string   `json:"facility_name"`
+	Address       Address  `json:"address"`
+	Location      Location `json:"location"`
+	ProcedureID   string   `json:"procedure_id"`
+	ProcedureName string   `json:"procedure_name"`
+	Price         float64  `json:"price"`
+	Currency      string   `json:"currency"`
+	DistanceKm    float64  `json:"distance_km"`
+}
+
+// CareBasketItem is one thing a patient needs, given either as a procedure
+// ID or as free text such as "malaria test"
+type CareBasketItem struct {
+	ProcedureID string `json:"procedure_id,omitempty"`
+	Query       string `json:"query,omitempty"`
+}
+
+// CareBasketRequest asks where a set of procedures can be had most cheaply
+// near a location
+type CareBasketRequest struct {
+	Items             []CareBasketItem `json:"items"`
+	Latitude          float64          `json:"latitude"`
+	Longitude         float64          `json:"longitude"`
+	MaxDistanceKm     float64          `json:"max_distance_km,omitempty"`
+	InsuranceProvider string           `json:"insurance_provider,omitempty"`
+	// AllowSplit lets an option spread the items over more than one facility
+	AllowSplit bool `json:"allow_split,omitempty"`
+	Limit      int  `json:"limit,omitempty"`
+}
+
+// BasketProcedure is a procedure a basket item resolved to. Query is the
+// free text it was resolved from, if any.
+type BasketProcedure struct {
+	ProcedureID string `json:"procedure_id"`
+	Name        string `json:"name"`
+	Query       string `json:"query,omitempty"`
+}
+
+// BasketItemPrice is what a facility charges for one procedure in a basket
+type BasketItemPrice struct {
+	ProcedureID string  `json:"procedure_id"`
+	Name        string  `json:"name"`
+	Price       float64 `json:"price"`
+	Currency    string  `json:"currency"`
+}
+
+// BasketFee is a registration-type fee, such as a folder or card fee, a
+// facility charges on a visit
+type BasketFee struct {
+	ID    string  `json:"id"`
+	Name  string  `json:"name"`
+	Price float64 `json:"price"`
+}
+
+// BasketFeeWaiver is a sponsored waiver of a facility's registration fees
+type BasketFeeWaiver struct {
+	SponsorName  string   `json:"sponsor_name"`
+	WaiverType   string   `json:"waiver_type"`
+	WaiverAmount *float64 `json:"waiver_amount,omitempty"`
+	Savings      float64  `json:"savings"`
+}
+
+// BasketVisit is the part of a basket bought at one facility
+type BasketVisit struct {
+	FacilityID       string            `json:"facility_id"`
+	FacilityName     string            `json:"facility_name"`
+	Address          Address           `json:"address"`
+	Location         Location          `json:"location"`
+	DistanceKm       float64           `json:"distance_km"`
+	Items            []BasketItemPrice `json:"items"`
+	ItemsTotal       float64           `json:"items_total"`
+	RegistrationFees []BasketFee       `json:"registration_fees"`
+	RegistrationFee  float64           `json:"registration_fee"`
+	FeeWaiver        *BasketFeeWaiver  `json:"fee_waiver,omitempty"`
+	// Total is the items plus the registration fees after any waiver
+	Total float64 `json:"total"`
+}
+
+// CareBasketOption is one way to buy a basket: a visit to a single facility,
+// or visits to several when splitting is allowed
+type CareBasketOption struct {
+	Visits []BasketVisit `json:"visits"`
+	// MissingItems are the procedures none of the visited facilities offer
+	MissingItems     []BasketProcedure `json:"missing_items"`
+	ItemsTotal       float64           `json:"items_total"`
+	RegistrationFees float64           `json:"registration_fees"`
+	WaiverSavings    float64           `json:"waiver_savings"`
+	Total            float64           `json:"total"`
+	Currency         string            `json:"currency"`
+}
+
+// CareBasketQuote answers a CareBasketRequest with options ranked best first
+type CareBasketQuote struct {
+	Items           []BasketProcedure  `json:"items"`
+	UnresolvedItems []CareBasketItem   `json:"unresolved_items"`
+	Options         []CareBasketOption `json:"options"`
+}
@@ -0,0 +1,42 @@
+package entities
+
+import "math"
+
+// EmbeddingDimensions is the length of every stored and query embedding.
+// Vectors from different providers or models are not comparable, so the
+// API and the indexer must embed with the same one.
+const EmbeddingDimensions = 256
+
+// CosineSimilarity returns the cosine of the angle between two vectors, or 0
+// when their lengths differ or either is zero
+func CosineSimilarity(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// HybridRankScore fuses a document's keyword and vector ranks the way
+// Typesense hybrid search does: each 1-based rank contributes its
+// reciprocal, weighted by vectorWeight for the vector rank and the rest for
+// the keyword rank. A rank of 0 means the document was not retrieved by
+// that side.
+func HybridRankScore(keywordRank, vectorRank int, vectorWeight float64) float64 {
+	score := 0.0
+	if keywordRank > 0 {
+		score += (1 - vectorWeight) / float64(keywordRank)
+	}
+	if vectorRank > 0 {
+		score += vectorWeight / float64(vectorRank)
+	}
+	return score
+}
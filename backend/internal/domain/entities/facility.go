@@ -2,15 +2,20 @@ package entities
 
 import (
 	"encoding/json"
+	"strings"
 	"time"
 )
 
 // Facility represents a healthcare facility in the system
 type Facility struct {
-	ID                   string          `json:"id" db:"id"`
-	Name                 string          `json:"name" db:"name"`
-	Address              Address         `json:"address" db:"-"`
-	Location             Location        `json:"location" db:"-"`
+	ID       string   `json:"id" db:"id"`
+	Name     string   `json:"name" db:"name"`
+	Address  Address  `json:"address" db:"-"`
+	Location Location `json:"location" db:"-"`
+	// LocationSource is the GeocodeSource the coordinates came from; a
+	// pinned location was set by an admin and ingestion never replaces it
+	LocationSource       string          `json:"location_source,omitempty" db:"location_source"`
+	LocationPinned       bool            `json:"location_pinned" db:"location_pinned"`
 	PhoneNumber          string          `json:"phone_number" db:"phone_number"`
 	WhatsAppNumber       string          `json:"whatsapp_number,omitempty" db:"whatsapp_number"`
 	Email                string          `json:"email" db:"email"`
@@ -26,9 +31,17 @@ type Facility struct {
 	WardStatuses         json.RawMessage `json:"ward_statuses,omitempty" db:"ward_statuses"`
 	AvgWaitMinutes       *int            `json:"avg_wait_minutes,omitempty" db:"avg_wait_minutes"`
 	UrgentCareAvailable  *bool           `json:"urgent_care_available,omitempty" db:"urgent_care_available"`
-	IsActive             bool            `json:"is_active" db:"is_active"`
-	CreatedAt            time.Time       `json:"created_at" db:"created_at"`
-	UpdatedAt            time.Time       `json:"updated_at" db:"updated_at"`
+	// When each capacity field was last reported; the values expire after the
+	// freshness policy's window
+	CapacityStatusReportedAt *time.Time `json:"capacity_status_reported_at,omitempty" db:"capacity_status_reported_at"`
+	AvgWaitReportedAt        *time.Time `json:"avg_wait_reported_at,omitempty" db:"avg_wait_reported_at"`
+	UrgentCareReportedAt     *time.Time `json:"urgent_care_reported_at,omitempty" db:"urgent_care_reported_at"`
+	IsActive                 bool       `json:"is_active" db:"is_active"`
+	CreatedAt                time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt                time.Time  `json:"updated_at" db:"updated_at"`
+	// Version increments on every update; an update only applies when it
+	// carries the version it was read at
+	Version int `json:"version" db:"version"`
 }
 
 // Address represents a physical address
@@ -45,3 +58,30 @@ type Location struct {
 	Latitude  float64 `json:"latitude" db:"latitude"`
 	Longitude float64 `json:"longitude" db:"longitude"`
 }
+
+// RoundTheClockMarkers in a facility's name say it never closes
+var RoundTheClockMarkers = []string{"24 hour", "24-hour", "24hr", "24 hrs", "24/7", "twenty four hour"}
+
+// OpenAroundTheClock reports whether the facility never closes: urgent care
+// facilities, and facilities whose name says so
+func (f *Facility) OpenAroundTheClock() bool {
+	if f.FacilityType == "urgent_care" {
+		return true
+	}
+	name := strings.ToLower(f.Name)
+	for _, marker := range RoundTheClockMarkers {
+		if strings.Contains(name, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// CurrentCapacityStatus returns the lowercased capacity status, or unknown
+// when none has been reported
+func (f *Facility) CurrentCapacityStatus() string {
+	if f.CapacityStatus == nil || strings.TrimSpace(*f.CapacityStatus) == "" {
+		return CapacityStatusUnknown
+	}
+	return strings.ToLower(strings.TrimSpace(*f.CapacityStatus))
+}
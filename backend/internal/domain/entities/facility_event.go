@@ -3,6 +3,7 @@ package entities
 import (
 	"crypto/rand"
 	"encoding/hex"
+	"sync"
 	"time"
 )
 
@@ -16,6 +17,7 @@ const (
 	FacilityEventTypeUrgentCareUpdate          FacilityEventType = "urgent_care_update"
 	FacilityEventTypeServiceHealthUpdate       FacilityEventType = "service_health_update"
 	FacilityEventTypeServiceAvailabilityUpdate FacilityEventType = "service_availability_update"
+	FacilityEventTypeServicePriceUpdate        FacilityEventType = "service_price_update"
 )
 
 // FacilityEvent represents a real-time update event for a facility
@@ -40,6 +42,49 @@ func NewFacilityEvent(facilityID string, eventType FacilityEventType, location L
 	}
 }
 
+// FacilityEventDeduper remembers the IDs of the most recently seen events.
+// Facility events are delivered at least once, so consumers use it to drop
+// redeliveries.
+type FacilityEventDeduper struct {
+	mu    sync.Mutex
+	seen  map[string]struct{}
+	order []string
+	next  int
+}
+
+// NewFacilityEventDeduper creates a deduper that remembers up to size event IDs
+func NewFacilityEventDeduper(size int) *FacilityEventDeduper {
+	if size <= 0 {
+		size = 1
+	}
+	return &FacilityEventDeduper{
+		seen:  make(map[string]struct{}, size),
+		order: make([]string, size),
+	}
+}
+
+// Seen records the event's ID and reports whether it had already been seen.
+// Events without an ID are never treated as duplicates.
+func (d *FacilityEventDeduper) Seen(event *FacilityEvent) bool {
+	if event == nil || event.ID == "" {
+		return false
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if _, ok := d.seen[event.ID]; ok {
+		return true
+	}
+	if evicted := d.order[d.next]; evicted != "" {
+		delete(d.seen, evicted)
+	}
+	d.order[d.next] = event.ID
+	d.seen[event.ID] = struct{}{}
+	d.next = (d.next + 1) % len(d.order)
+	return false
+}
+
 // generateEventID generates a unique event ID
 func generateEventID() string {
 	return time.Now().Format("20060102150405") + "-" + randomString(8)
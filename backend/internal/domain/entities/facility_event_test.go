@@ -0,0 +1,34 @@
+package entities
+
+import (
+	"testing"
+)
+
+func TestFacilityEventDeduper_DropsRedeliveries(t *testing.T) {
+	deduper := NewFacilityEventDeduper(2)
+	first := &FacilityEvent{ID: "evt-1"}
+
+	if deduper.Seen(first) {
+		t.Fatal("first delivery reported as duplicate")
+	}
+	if !deduper.Seen(&FacilityEvent{ID: "evt-1"}) {
+		t.Error("redelivery of evt-1 not detected")
+	}
+	if deduper.Seen(&FacilityEvent{}) || deduper.Seen(&FacilityEvent{}) {
+		t.Error("events without an ID should never be duplicates")
+	}
+}
+
+func TestFacilityEventDeduper_ForgetsOldestBeyondSize(t *testing.T) {
+	deduper := NewFacilityEventDeduper(2)
+	for _, id := range []string{"evt-1", "evt-2", "evt-3"} {
+		deduper.Seen(&FacilityEvent{ID: id})
+	}
+
+	if !deduper.Seen(&FacilityEvent{ID: "evt-3"}) {
+		t.Error("evt-3 should still be remembered")
+	}
+	if deduper.Seen(&FacilityEvent{ID: "evt-1"}) {
+		t.Error("evt-1 should have been evicted")
+	}
+}
@@ -0,0 +1,65 @@
+package entities
+
+// GeoBounds is a latitude/longitude box, such as the visible part of a map
+type GeoBounds struct {
+	MinLatitude  float64 `json:"min_latitude"`
+	MinLongitude float64 `json:"min_longitude"`
+	MaxLatitude  float64 `json:"max_latitude"`
+	MaxLongitude float64 `json:"max_longitude"`
+}
+
+// Contains reports whether a location is inside the box, edges included
+func (b GeoBounds) Contains(location Location) bool {
+	return location.Latitude >= b.MinLatitude && location.Latitude <= b.MaxLatitude &&
+		location.Longitude >= b.MinLongitude && location.Longitude <= b.MaxLongitude
+}
+
+// Extend grows the box to include a location
+func (b *GeoBounds) Extend(location Location) {
+	b.MinLatitude = min(b.MinLatitude, location.Latitude)
+	b.MaxLatitude = max(b.MaxLatitude, location.Latitude)
+	b.MinLongitude = min(b.MinLongitude, location.Longitude)
+	b.MaxLongitude = max(b.MaxLongitude, location.Longitude)
+}
+
+// FacilityMapPoint is the little of a facility a map marker needs
+type FacilityMapPoint struct {
+	ID             string   `json:"id"`
+	Name           string   `json:"name"`
+	FacilityType   string   `json:"facility_type"`
+	Location       Location `json:"location"`
+	Rating         float64  `json:"rating"`
+	CapacityStatus string   `json:"capacity_status"`
+	// Price is the price of the filtered procedure, or the facility's
+	// cheapest service without a procedure filter
+	Price *float64 `json:"price,omitempty"`
+}
+
+// FacilityCluster stands for the facilities in one grid cell of a map
+type FacilityCluster struct {
+	ID string `json:"id"`
+	// Location is the mean of the facilities' locations, and Bounds the box
+	// they fit in, which a client can zoom to
+	Location Location  `json:"location"`
+	Bounds   GeoBounds `json:"bounds"`
+	Count    int       `json:"count"`
+	// MinPrice and MaxPrice span the prices of the facilities with one
+	MinPrice *float64 `json:"min_price,omitempty"`
+	MaxPrice *float64 `json:"max_price,omitempty"`
+	// CapacityMix counts the facilities by capacity status
+	CapacityMix map[string]int `json:"capacity_mix"`
+}
+
+// FacilityMap is what a map viewport shows: facilities on their own when
+// zoomed in, and clusters with the facilities left on their own otherwise
+type FacilityMap struct {
+	Bounds     GeoBounds          `json:"bounds"`
+	Zoom       int                `json:"zoom"`
+	Clustered  bool               `json:"clustered"`
+	Facilities []FacilityMapPoint `json:"facilities"`
+	Clusters   []FacilityCluster  `json:"clusters"`
+	// Total counts every matching facility in the viewport; Truncated is set
+	// when only some of them were placed on the map
+	Total     int  `json:"total"`
+	Truncated bool `json:"truncated"`
+}
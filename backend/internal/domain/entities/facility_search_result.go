@@ -10,22 +10,31 @@ type WardCapacityResult struct {
 	AvgWaitMinutes      *int      `json:"avg_wait_minutes,omitempty"`
 	UrgentCareAvailable *bool     `json:"urgent_care_available,omitempty"`
 	LastUpdated         time.Time `json:"last_updated"`
+	// CapacityFreshness rates each capacity value above, keyed by field name
+	CapacityFreshness map[CapacityField]DataFreshness `json:"capacity_freshness,omitempty"`
+	// ExpectedWaitMinutes is the typical wait at this hour of the week, set
+	// when the live wait is missing or stale
+	ExpectedWaitMinutes *int `json:"expected_wait_minutes,omitempty"`
 }
 
 // FacilitySearchResult represents the enriched search payload returned to the UI.
 type FacilitySearchResult struct {
-	ID                  string               `json:"id"`
-	Name                string               `json:"name"`
-	FacilityType        string               `json:"facility_type"`
-	Address             Address              `json:"address"`
-	Location            Location             `json:"location"`
-	PhoneNumber         string               `json:"phone_number,omitempty"`
-	WhatsAppNumber      string               `json:"whatsapp_number,omitempty"`
-	Email               string               `json:"email,omitempty"`
-	Website             string               `json:"website,omitempty"`
-	Rating              float64              `json:"rating"`
-	ReviewCount         int                  `json:"review_count"`
-	DistanceKm          float64              `json:"distance_km"`
+	ID             string   `json:"id"`
+	Name           string   `json:"name"`
+	FacilityType   string   `json:"facility_type"`
+	Address        Address  `json:"address"`
+	Location       Location `json:"location"`
+	PhoneNumber    string   `json:"phone_number,omitempty"`
+	WhatsAppNumber string   `json:"whatsapp_number,omitempty"`
+	Email          string   `json:"email,omitempty"`
+	Website        string   `json:"website,omitempty"`
+	Rating         float64  `json:"rating"`
+	ReviewCount    int      `json:"review_count"`
+	DistanceKm     float64  `json:"distance_km"`
+	// TravelMinutes and RoadDistanceKm estimate the trip by road from the
+	// search location, when a routing provider is configured
+	TravelMinutes       *int                 `json:"travel_minutes,omitempty"`
+	RoadDistanceKm      *float64             `json:"road_distance_km,omitempty"`
 	Price               *FacilityPriceRange  `json:"price,omitempty"`
 	Services            []string             `json:"services"`
 	ServicePrices       []ServicePrice       `json:"service_prices"`
@@ -38,7 +47,12 @@ type FacilitySearchResult struct {
 	WardStatuses        interface{}          `json:"ward_statuses,omitempty"`
 	UrgentCareAvailable *bool                `json:"urgent_care_available,omitempty"`
 	Wards               []WardCapacityResult `json:"wards,omitempty"`
-	UpdatedAt           time.Time            `json:"updated_at"`
+	// CapacityFreshness rates each facility capacity value, keyed by field name
+	CapacityFreshness map[CapacityField]DataFreshness `json:"capacity_freshness,omitempty"`
+	// ExpectedWaitMinutes is the typical wait at this hour of the week, set
+	// when the live wait is missing or stale
+	ExpectedWaitMinutes *int      `json:"expected_wait_minutes,omitempty"`
+	UpdatedAt           time.Time `json:"updated_at"`
 }
 
 // FacilityPriceRange summarizes price ranges for a facility.
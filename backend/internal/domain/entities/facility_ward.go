@@ -15,4 +15,6 @@ type FacilityWard struct {
 	UrgentCareAvailable *bool     `json:"urgent_care_available,omitempty" db:"urgent_care_available"`
 	LastUpdated         time.Time `json:"last_updated" db:"last_updated"`
 	CreatedAt           time.Time `json:"created_at" db:"created_at"`
+	// Version is zero for a ward that has not been stored yet
+	Version int `json:"version" db:"version"`
 }
@@ -0,0 +1,59 @@
+package entities
+
+import (
+	"strings"
+	"time"
+	"unicode"
+)
+
+// GeocodeSource records where a set of coordinates came from
+type GeocodeSource string
+
+const (
+	GeocodeSourceGoogle          GeocodeSource = "google"
+	GeocodeSourceGazetteer       GeocodeSource = "gazetteer"
+	GeocodeSourceManual          GeocodeSource = "manual"
+	GeocodeSourceProviderProfile GeocodeSource = "provider_profile"
+)
+
+// GeocodedLocation is a stored geocoding result for one address
+type GeocodedLocation struct {
+	AddressKey       string        `json:"address_key" db:"address_key"`
+	Address          string        `json:"address" db:"address"`
+	FormattedAddress string        `json:"formatted_address,omitempty" db:"formatted_address"`
+	Street           string        `json:"street,omitempty" db:"street"`
+	City             string        `json:"city,omitempty" db:"city"`
+	State            string        `json:"state,omitempty" db:"state"`
+	ZipCode          string        `json:"zip_code,omitempty" db:"zip_code"`
+	Country          string        `json:"country,omitempty" db:"country"`
+	Location         Location      `json:"location" db:"-"`
+	Source           GeocodeSource `json:"source" db:"source"`
+	// Confidence is between 0 and 1; manual entries are 1
+	Confidence float64   `json:"confidence" db:"confidence"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// NormalizeGeocodeAddress returns the key an address is stored under:
+// lowercase, with punctuation dropped and whitespace collapsed, so that
+// "12 Allen Ave., Ikeja" and "12 allen ave ikeja" share an entry
+func NormalizeGeocodeAddress(address string) string {
+	fields := strings.FieldsFunc(strings.ToLower(address), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(fields, " ")
+}
+
+// GeocodeQuery returns the address as sent to a geocoder
+func (a Address) GeocodeQuery() string {
+	parts := make([]string, 0, 5)
+	for _, part := range []string{a.Street, a.City, a.State, a.Country} {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	if strings.TrimSpace(a.Country) == "" {
+		parts = append(parts, "Nigeria")
+	}
+	return strings.Join(parts, ", ")
+}
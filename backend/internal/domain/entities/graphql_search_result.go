@@ -17,6 +17,19 @@ type GraphQLFacilitySearchResult struct {
 
 	// SearchTimeMs is the time taken for the search in milliseconds
 	SearchTimeMs float64
+
+	// ImpressionID identifies this response for click and conversion tracking
+	ImpressionID string
+
+	// TravelTimes are the estimated trips to the facilities that could be routed
+	TravelTimes []FacilityTravelTime
+}
+
+// FacilityTravelTime is the estimated trip by road from the search location to a facility
+type FacilityTravelTime struct {
+	FacilityID     string
+	TravelMinutes  int
+	RoadDistanceKm float64
 }
 
 // SearchFacets contains aggregated facet data from search results
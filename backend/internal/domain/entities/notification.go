@@ -46,6 +46,8 @@ const (
 	NotificationStatusDelivered NotificationStatus = "delivered"
 	NotificationStatusRead      NotificationStatus = "read"
 	NotificationStatusFailed    NotificationStatus = "failed"
+	// NotificationStatusDeadLetter marks a notification that exhausted its retries
+	NotificationStatusDeadLetter NotificationStatus = "dead_letter"
 )
 
 // AppointmentNotification tracks sent notifications
@@ -63,6 +65,7 @@ type AppointmentNotification struct {
 	FailedAt         *time.Time             `json:"failed_at,omitempty" db:"failed_at"`
 	ErrorMessage     *string                `json:"error_message,omitempty" db:"error_message"`
 	RetryCount       int                    `json:"retry_count" db:"retry_count"`
+	NextRetryAt      *time.Time             `json:"next_retry_at,omitempty" db:"next_retry_at"`
 	Metadata         map[string]interface{} `json:"metadata,omitempty" db:"metadata"`
 	CreatedAt        time.Time              `json:"created_at" db:"created_at"`
 	UpdatedAt        time.Time              `json:"updated_at" db:"updated_at"`
@@ -34,6 +34,7 @@ type FacilityProcedure struct {
 	IsAvailable       bool      `json:"is_available" db:"is_available"`
 	CreatedAt         time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt         time.Time `json:"updated_at" db:"updated_at"`
+	Version           int       `json:"version" db:"version"`
 
 	// Enriched fields populated by JOIN queries (not stored in facility_procedures table)
 	ProcedureName        string   `json:"name,omitempty" db:"-"`
@@ -0,0 +1,77 @@
+package entities
+
+// ProcedurePriceStats summarises what facilities charge for a procedure,
+// counting only services that are available and priced
+type ProcedurePriceStats struct {
+	ProcedureID   string  `json:"procedure_id"`
+	FacilityCount int     `json:"facility_count"`
+	MinPrice      float64 `json:"min_price"`
+	MaxPrice      float64 `json:"max_price"`
+	MedianPrice   float64 `json:"median_price"`
+	Currency      string  `json:"currency,omitempty"`
+}
+
+// ProcedureSearchResult is a canonical procedure matched by procedure search
+type ProcedureSearchResult struct {
+	ID          string               `json:"id"`
+	Name        string               `json:"name"`
+	DisplayName string               `json:"display_name"`
+	Code        string               `json:"code,omitempty"`
+	Category    string               `json:"category,omitempty"`
+	Tags        []string             `json:"tags,omitempty"`
+	Conditions  []string             `json:"conditions,omitempty"`
+	Specialties []string             `json:"specialties,omitempty"`
+	Price       *ProcedurePriceStats `json:"price,omitempty"`
+}
+
+// Label returns the name to show for the procedure
+func (r *ProcedureSearchResult) Label() string {
+	if r.DisplayName != "" {
+		return r.DisplayName
+	}
+	return r.Name
+}
+
+// ConceptSuggestion is a condition or specialty value matching a typed
+// prefix, with how many procedures carry it
+type ConceptSuggestion struct {
+	Value      string `json:"value"`
+	Procedures int    `json:"procedures"`
+}
+
+// SuggestionType identifies what a suggestion refers to
+type SuggestionType string
+
+const (
+	SuggestionTypeProcedure SuggestionType = "procedure"
+	SuggestionTypeCondition SuggestionType = "condition"
+	SuggestionTypeFacility  SuggestionType = "facility"
+	SuggestionTypeSpecialty SuggestionType = "specialty"
+)
+
+// SuggestionActionType is what a client does when a suggestion is picked
+type SuggestionActionType string
+
+const (
+	// SuggestionActionSearchFacilities searches facilities with Params
+	SuggestionActionSearchFacilities SuggestionActionType = "search_facilities"
+	// SuggestionActionViewFacility opens the facility at Path
+	SuggestionActionViewFacility SuggestionActionType = "view_facility"
+)
+
+// SuggestionAction is the request a client makes for a picked suggestion
+type SuggestionAction struct {
+	Type   SuggestionActionType `json:"type"`
+	Path   string               `json:"path"`
+	Params map[string]string    `json:"params,omitempty"`
+}
+
+// Suggestion is one typed autocomplete entry
+type Suggestion struct {
+	Type   SuggestionType       `json:"type"`
+	ID     string               `json:"id,omitempty"`
+	Label  string               `json:"label"`
+	Detail string               `json:"detail,omitempty"`
+	Price  *ProcedurePriceStats `json:"price,omitempty"`
+	Action SuggestionAction     `json:"action"`
+}
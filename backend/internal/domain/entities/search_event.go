@@ -6,15 +6,20 @@ import (
 
 // SearchEvent represents a single search interaction for analytics.
 type SearchEvent struct {
-	ID               string    `json:"id" db:"id"`
-	Query            string    `json:"query" db:"query"`
-	NormalizedQuery  string    `json:"normalized_query" db:"normalized_query"`
-	DetectedIntent   string    `json:"detected_intent" db:"detected_intent"`
-	IntentConfidence float64   `json:"intent_confidence" db:"intent_confidence"`
-	ResultCount      int       `json:"result_count" db:"result_count"`
-	LatencyMs        int       `json:"latency_ms" db:"latency_ms"`
-	UserLatitude     float64   `json:"user_latitude" db:"user_latitude"`
-	UserLongitude    float64   `json:"user_longitude" db:"user_longitude"`
-	SessionID        string    `json:"session_id,omitempty" db:"session_id"`
-	CreatedAt        time.Time `json:"created_at" db:"created_at"`
+	ID                string    `json:"id" db:"id"`
+	Query             string    `json:"query" db:"query"`
+	NormalizedQuery   string    `json:"normalized_query" db:"normalized_query"`
+	DetectedLanguage  string    `json:"detected_language,omitempty" db:"detected_language"`
+	DetectedIntent    string    `json:"detected_intent" db:"detected_intent"`
+	IntentConfidence  float64   `json:"intent_confidence" db:"intent_confidence"`
+	ResultCount       int       `json:"result_count" db:"result_count"`
+	LatencyMs         int       `json:"latency_ms" db:"latency_ms"`
+	UserLatitude      float64   `json:"user_latitude" db:"user_latitude"`
+	UserLongitude     float64   `json:"user_longitude" db:"user_longitude"`
+	SessionID         string    `json:"session_id,omitempty" db:"session_id"`
+	ExperimentID      string    `json:"experiment_id,omitempty" db:"experiment_id"`
+	ExperimentVariant string    `json:"experiment_variant,omitempty" db:"experiment_variant"`
+	ResultFacilityIDs []string  `json:"result_facility_ids,omitempty" db:"result_facility_ids"` // facilities shown, in rank order
+	ResultOffset      int       `json:"result_offset" db:"result_offset"`
+	CreatedAt         time.Time `json:"created_at" db:"created_at"`
 }
@@ -0,0 +1,72 @@
+package entities
+
+import "time"
+
+// ExperimentStatus is the lifecycle state of a search experiment.
+type ExperimentStatus string
+
+const (
+	ExperimentStatusDraft   ExperimentStatus = "draft"
+	ExperimentStatusRunning ExperimentStatus = "running"
+	ExperimentStatusStopped ExperimentStatus = "stopped"
+)
+
+// RankingWeights are the signal weights used by the search ranking service.
+// A nil weight keeps the service's default, so a variant can override just
+// the weights it tests.
+type RankingWeights struct {
+	Lexical   *float64 `json:"lexical,omitempty"`
+	Concept   *float64 `json:"concept,omitempty"`
+	Geo       *float64 `json:"geo,omitempty"`
+	Specialty *float64 `json:"specialty,omitempty"`
+}
+
+// ExperimentVariantConfig overrides search behaviour for sessions in a variant.
+// Nil fields keep the default behaviour.
+type ExperimentVariantConfig struct {
+	RankingWeights    *RankingWeights `json:"ranking_weights,omitempty"`
+	MaxExpansionTerms *int            `json:"max_expansion_terms,omitempty"`
+	ContextualSearch  *bool           `json:"contextual_search,omitempty"`
+	SemanticSearch    *bool           `json:"semantic_search,omitempty"`
+}
+
+// ExperimentVariant is one arm of an experiment. Weight is its relative share
+// of enrolled traffic.
+type ExperimentVariant struct {
+	Name   string                  `json:"name"`
+	Weight int                     `json:"weight"`
+	Config ExperimentVariantConfig `json:"config"`
+}
+
+// SearchExperiment is a named A/B test over search configuration.
+type SearchExperiment struct {
+	ID             string              `json:"id" db:"id"`
+	Name           string              `json:"name" db:"name"`
+	Description    string              `json:"description,omitempty" db:"description"`
+	Status         ExperimentStatus    `json:"status" db:"status"`
+	TrafficPercent int                 `json:"traffic_percent" db:"traffic_percent"` // share of sessions enrolled
+	Variants       []ExperimentVariant `json:"variants" db:"variants"`
+	StartedAt      *time.Time          `json:"started_at,omitempty" db:"started_at"`
+	StoppedAt      *time.Time          `json:"stopped_at,omitempty" db:"stopped_at"`
+	CreatedAt      time.Time           `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time           `json:"updated_at" db:"updated_at"`
+}
+
+// ExperimentVariantStats aggregates search outcomes for one variant.
+type ExperimentVariantStats struct {
+	Variant          string   `json:"variant"`
+	Sessions         int      `json:"sessions"`
+	Searches         int      `json:"searches"`
+	ZeroResults      int      `json:"zero_results"`
+	ZeroResultRate   float64  `json:"zero_result_rate"`
+	AvgLatencyMs     float64  `json:"avg_latency_ms"`
+	P95LatencyMs     float64  `json:"p95_latency_ms"`
+	ClickThroughRate *float64 `json:"click_through_rate"` // share of searches with at least one result click
+}
+
+// ExperimentReport compares variants of an experiment.
+type ExperimentReport struct {
+	Experiment  *SearchExperiment         `json:"experiment"`
+	Variants    []*ExperimentVariantStats `json:"variants"`
+	GeneratedAt time.Time                 `json:"generated_at"`
+}
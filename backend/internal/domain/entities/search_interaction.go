@@ -0,0 +1,79 @@
+package entities
+
+import "time"
+
+// SearchInteractionType is something a user did after seeing search results.
+type SearchInteractionType string
+
+const (
+	SearchInteractionResultClick  SearchInteractionType = "result_click"
+	SearchInteractionFacilityView SearchInteractionType = "facility_view"
+	SearchInteractionCallTap      SearchInteractionType = "call_tap"
+	SearchInteractionWhatsAppTap  SearchInteractionType = "whatsapp_tap"
+	SearchInteractionBooking      SearchInteractionType = "booking"
+)
+
+// IsValid reports whether t is a known interaction type.
+func (t SearchInteractionType) IsValid() bool {
+	switch t {
+	case SearchInteractionResultClick, SearchInteractionFacilityView, SearchInteractionCallTap,
+		SearchInteractionWhatsAppTap, SearchInteractionBooking:
+		return true
+	}
+	return false
+}
+
+// SearchInteraction is a user action tied back to the search impression that led to it.
+type SearchInteraction struct {
+	ID           string                `json:"id" db:"id"`
+	ImpressionID string                `json:"impression_id" db:"impression_id"`
+	EventType    SearchInteractionType `json:"event_type" db:"event_type"`
+	FacilityID   string                `json:"facility_id" db:"facility_id"`
+	Position     *int                  `json:"position,omitempty" db:"position"` // 1-based rank in the result list
+	SessionID    string                `json:"session_id,omitempty" db:"session_id"`
+	CreatedAt    time.Time             `json:"created_at" db:"created_at"`
+}
+
+// SearchInteractionReportFilter bounds an engagement report.
+type SearchInteractionReportFilter struct {
+	Since time.Time
+	Until time.Time
+	Limit int
+}
+
+// QueryEngagementStats aggregates engagement for one normalized query.
+type QueryEngagementStats struct {
+	Query             string  `json:"query"`
+	Searches          int     `json:"searches"`
+	ClickedSearches   int     `json:"clicked_searches"`
+	Clicks            int     `json:"clicks"`
+	Views             int     `json:"views"`
+	CallTaps          int     `json:"call_taps"`
+	WhatsAppTaps      int     `json:"whatsapp_taps"`
+	Bookings          int     `json:"bookings"`
+	ConvertedSearches int     `json:"converted_searches"` // searches that led to a booking
+	ClickThroughRate  float64 `json:"click_through_rate"`
+	ConversionRate    float64 `json:"conversion_rate"`
+	MeanClickPosition float64 `json:"mean_click_position"`
+}
+
+// FacilityEngagementStats aggregates engagement for one facility across searches.
+type FacilityEngagementStats struct {
+	FacilityID       string  `json:"facility_id"`
+	Impressions      int     `json:"impressions"`
+	Clicks           int     `json:"clicks"`
+	Views            int     `json:"views"`
+	CallTaps         int     `json:"call_taps"`
+	WhatsAppTaps     int     `json:"whatsapp_taps"`
+	Bookings         int     `json:"bookings"`
+	ClickThroughRate float64 `json:"click_through_rate"`
+	ConversionRate   float64 `json:"conversion_rate"`
+}
+
+// PositionEngagementStats aggregates clicks for one rank in the result list.
+type PositionEngagementStats struct {
+	Position         int     `json:"position"`
+	Impressions      int     `json:"impressions"`
+	Clicks           int     `json:"clicks"`
+	ClickThroughRate float64 `json:"click_through_rate"`
+}
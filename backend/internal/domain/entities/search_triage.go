@@ -0,0 +1,82 @@
+package entities
+
+import "time"
+
+// DictionaryFixType identifies which query-understanding dictionary a fix writes to.
+type DictionaryFixType string
+
+const (
+	// DictionaryFixSpelling maps a misspelled word to its correct form.
+	DictionaryFixSpelling DictionaryFixType = "spelling"
+	// DictionaryFixSynonym maps a term or phrase onto an existing concept.
+	DictionaryFixSynonym DictionaryFixType = "synonym"
+)
+
+// DictionaryFixStatus tracks whether an accepted fix made the query start returning results.
+type DictionaryFixStatus string
+
+const (
+	DictionaryFixStatusPending    DictionaryFixStatus = "pending"
+	DictionaryFixStatusResolved   DictionaryFixStatus = "resolved"
+	DictionaryFixStatusUnresolved DictionaryFixStatus = "unresolved"
+)
+
+// QueryTrendPoint is the number of matching searches on a single day.
+type QueryTrendPoint struct {
+	Date  string `json:"date"`
+	Count int    `json:"count"`
+}
+
+// QueryGeoBucket groups searches by a rounded user location.
+type QueryGeoBucket struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Count     int     `json:"count"`
+}
+
+// DictionaryFixSuggestion is a candidate fix for a poorly performing query.
+type DictionaryFixSuggestion struct {
+	FixType DictionaryFixType `json:"fix_type"`
+	Term    string            `json:"term"`
+	Target  string            `json:"target"`
+	Source  string            `json:"source"` // concept or procedure
+	Score   float64           `json:"score"`
+}
+
+// ZeroResultQueryCluster aggregates zero- and low-result searches sharing a normalized form.
+type ZeroResultQueryCluster struct {
+	NormalizedQuery string                    `json:"normalized_query"`
+	SampleQueries   []string                  `json:"sample_queries"`
+	Occurrences     int                       `json:"occurrences"`
+	ZeroResultCount int                       `json:"zero_result_count"`
+	LowResultCount  int                       `json:"low_result_count"`
+	FirstSeen       time.Time                 `json:"first_seen"`
+	LastSeen        time.Time                 `json:"last_seen"`
+	Trend           string                    `json:"trend"` // rising, falling, stable
+	DailyCounts     []QueryTrendPoint         `json:"daily_counts"`
+	Geography       []QueryGeoBucket          `json:"geography,omitempty"`
+	Suggestions     []DictionaryFixSuggestion `json:"suggestions,omitempty"`
+	PendingFix      *SearchDictionaryFix      `json:"pending_fix,omitempty"`
+}
+
+// SearchDictionaryFix is an admin-accepted dictionary change and its observed outcome.
+type SearchDictionaryFix struct {
+	ID              string              `json:"id" db:"id"`
+	NormalizedQuery string              `json:"normalized_query" db:"normalized_query"`
+	FixType         DictionaryFixType   `json:"fix_type" db:"fix_type"`
+	Term            string              `json:"term" db:"term"`
+	Target          string              `json:"target" db:"target"`
+	AcceptedBy      string              `json:"accepted_by,omitempty" db:"accepted_by"`
+	Status          DictionaryFixStatus `json:"status" db:"status"`
+	SearchesAfter   int                 `json:"searches_after" db:"searches_after"`
+	SuccessfulAfter int                 `json:"successful_after" db:"successful_after"`
+	LastCheckedAt   *time.Time          `json:"last_checked_at,omitempty" db:"last_checked_at"`
+	CreatedAt       time.Time           `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time           `json:"updated_at" db:"updated_at"`
+}
+
+// QueryOutcome summarises how a normalized query performed over a time window.
+type QueryOutcome struct {
+	Searches   int `json:"searches"`
+	WithResult int `json:"with_result"`
+}
@@ -4,24 +4,74 @@ import (
 	"time"
 )
 
-// User represents a user in the system
+// User represents a patient account. Accounts are created on first phone
+// login, so Phone is always set and in E.164 format; Email is optional.
 type User struct {
-	ID        string    `json:"id" db:"id"`
-	Email     string    `json:"email" db:"email"`
-	FirstName string    `json:"first_name" db:"first_name"`
-	LastName  string    `json:"last_name" db:"last_name"`
-	Phone     string    `json:"phone" db:"phone"`
-	CreatedAt time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+	ID          string     `json:"id" db:"id"`
+	Email       string     `json:"email,omitempty" db:"email"`
+	FirstName   string     `json:"first_name" db:"first_name"`
+	LastName    string     `json:"last_name" db:"last_name"`
+	Phone       string     `json:"phone" db:"phone"`
+	LastLoginAt *time.Time `json:"last_login_at,omitempty" db:"last_login_at"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
 }
 
-// Review represents a user review of a facility
+// ReviewStatus is the moderation state of a review
+type ReviewStatus string
+
+const (
+	ReviewStatusPending   ReviewStatus = "pending"   // awaiting moderation
+	ReviewStatusPublished ReviewStatus = "published" // visible and counted in the facility rating
+	ReviewStatusRejected  ReviewStatus = "rejected"  // refused by a moderator
+	ReviewStatusHidden    ReviewStatus = "hidden"    // taken down after publication
+)
+
+// reviewTransitions lists the moderation moves allowed from each status
+var reviewTransitions = map[ReviewStatus][]ReviewStatus{
+	ReviewStatusPending:   {ReviewStatusPublished, ReviewStatusRejected},
+	ReviewStatusPublished: {ReviewStatusHidden},
+	ReviewStatusHidden:    {ReviewStatusPublished},
+	ReviewStatusRejected:  {ReviewStatusPublished},
+}
+
+// IsValid reports whether the status is a known review status
+func (s ReviewStatus) IsValid() bool {
+	_, ok := reviewTransitions[s]
+	return ok
+}
+
+// CanTransitionTo reports whether a moderator may move a review from s to next
+func (s ReviewStatus) CanTransitionTo(next ReviewStatus) bool {
+	for _, allowed := range reviewTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// Review moderation flags raised by automatic screening
+const (
+	ReviewFlagProfanity   = "profanity"
+	ReviewFlagPIIRedacted = "pii_redacted"
+)
+
+// Review represents a patient review of a facility, tied to a completed appointment
 type Review struct {
-	ID         string    `json:"id" db:"id"`
-	UserID     string    `json:"user_id" db:"user_id"`
-	FacilityID string    `json:"facility_id" db:"facility_id"`
-	Rating     int       `json:"rating" db:"rating"` // 1-5
-	Comment    string    `json:"comment" db:"comment"`
-	CreatedAt  time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
+	ID               string       `json:"id" db:"id"`
+	UserID           string       `json:"user_id,omitempty" db:"user_id"`
+	FacilityID       string       `json:"facility_id" db:"facility_id"`
+	AppointmentID    string       `json:"appointment_id,omitempty" db:"appointment_id"`
+	ReviewerName     string       `json:"reviewer_name,omitempty" db:"reviewer_name"`
+	Rating           int          `json:"rating" db:"rating"` // 1-5
+	Comment          string       `json:"comment" db:"comment"`
+	Status           ReviewStatus `json:"status" db:"status"`
+	ModerationFlags  []string     `json:"moderation_flags,omitempty" db:"moderation_flags"`
+	ModerationNote   string       `json:"moderation_note,omitempty" db:"moderation_note"`
+	ModeratedAt      *time.Time   `json:"moderated_at,omitempty" db:"moderated_at"`
+	FacilityResponse string       `json:"facility_response,omitempty" db:"facility_response"`
+	RespondedAt      *time.Time   `json:"responded_at,omitempty" db:"responded_at"`
+	CreatedAt        time.Time    `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time    `json:"updated_at" db:"updated_at"`
 }
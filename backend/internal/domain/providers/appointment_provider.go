@@ -18,3 +18,30 @@ type AppointmentProvider interface {
 	// CancelAppointment cancels an appointment on the external provider
 	CancelAppointment(ctx context.Context, externalID string, reason string) error
 }
+
+// NativeCalendarProvider is an AppointmentProvider backed by facility-managed
+// calendars. External IDs are facility IDs for slots and reservation IDs for bookings.
+type NativeCalendarProvider interface {
+	AppointmentProvider
+
+	// ListSlots returns every slot offered in the range, including full ones, for operators
+	ListSlots(ctx context.Context, facilityID string, from, to time.Time) ([]entities.AvailabilitySlot, error)
+
+	// HoldSlot reserves a slot for the facility's hold period during checkout
+	HoldSlot(ctx context.Context, facilityID, slotID string) (*entities.SlotReservation, error)
+
+	// ReleaseHold gives a held slot back before the hold expires
+	ReleaseHold(ctx context.Context, facilityID, holdID string) error
+}
+
+// SchedulingTarget is the provider and external ID used to schedule at one facility.
+type SchedulingTarget struct {
+	Provider   AppointmentProvider
+	ExternalID string
+	Method     entities.BookingMethod
+}
+
+// AppointmentProviderResolver chooses the scheduling provider for each facility.
+type AppointmentProviderResolver interface {
+	Resolve(ctx context.Context, facility *entities.Facility) (*SchedulingTarget, error)
+}
@@ -0,0 +1,23 @@
+package providers
+
+import "context"
+
+// EmbeddingProvider turns text into vectors of entities.EmbeddingDimensions
+// whose cosine similarity reflects how close the texts are in meaning
+type EmbeddingProvider interface {
+	// Embed returns one vector per text, in order
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+
+	// EmbeddingModel identifies the vector space, so stored and query
+	// vectors can be checked to come from the same model
+	EmbeddingModel() string
+}
+
+// BulkEmbeddingProvider is implemented by providers whose bulk embedding, such
+// as a reindex, must be metered so it does not exhaust the provider's quota
+type BulkEmbeddingProvider interface {
+	EmbeddingProvider
+
+	// EmbedBulk is Embed after waiting for the provider's rate limiter
+	EmbedBulk(ctx context.Context, texts []string) ([][]float32, error)
+}
@@ -2,6 +2,8 @@ package providers
 
 import (
 	"context"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
 )
 
 // GeolocationProvider defines the interface for geolocation services
@@ -34,6 +36,10 @@ type GeocodedAddress struct {
 	ZipCode          string
 	Country          string
 	Coordinates      Coordinates
+	// Source and Confidence describe how the coordinates were found; results
+	// without a source are not stored
+	Source     entities.GeocodeSource
+	Confidence float64
 }
 
 // Place represents a geographical place
@@ -44,3 +50,44 @@ type Place struct {
 	Coordinates Coordinates
 	PlaceType   string
 }
+
+// PlaceKind is the administrative level of a gazetteer place
+type PlaceKind string
+
+const (
+	PlaceKindState         PlaceKind = "state"
+	PlaceKindLGA           PlaceKind = "lga"
+	PlaceKindCity          PlaceKind = "city"
+	PlaceKindNeighbourhood PlaceKind = "neighbourhood"
+)
+
+// BoundingBox is a latitude/longitude rectangle
+type BoundingBox struct {
+	MinLatitude  float64
+	MinLongitude float64
+	MaxLatitude  float64
+	MaxLongitude float64
+}
+
+// Contains reports whether the coordinates fall inside the box
+func (b BoundingBox) Contains(c Coordinates) bool {
+	return c.Latitude >= b.MinLatitude && c.Latitude <= b.MaxLatitude &&
+		c.Longitude >= b.MinLongitude && c.Longitude <= b.MaxLongitude
+}
+
+// GazetteerPlace is a named state, LGA, city or neighbourhood
+type GazetteerPlace struct {
+	Name  string
+	Kind  PlaceKind
+	State string
+	// City is the city a neighbourhood belongs to
+	City     string
+	Centroid Coordinates
+	Bounds   BoundingBox
+}
+
+// PlaceGazetteer resolves place names without network calls
+type PlaceGazetteer interface {
+	// LookupPlace returns the place a name or alias denotes, if known
+	LookupPlace(name string) (*GazetteerPlace, bool)
+}
@@ -0,0 +1,34 @@
+package providers
+
+import (
+	"context"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+)
+
+// NotificationMessage is a rendered notification for one recipient on one channel
+type NotificationMessage struct {
+	// To is an E.164 phone number for WhatsApp and SMS, or an email address
+	To string
+
+	// Subject is the email subject line; other channels ignore it
+	Subject string
+
+	// Body is the rendered message text
+	Body string
+
+	// TemplateName selects a pre-approved WhatsApp template, sent with
+	// TemplateParams instead of Body. Other channels ignore it.
+	TemplateName     string
+	TemplateLanguage string
+	TemplateParams   []string
+}
+
+// NotificationSender delivers notifications over a single channel (WhatsApp, SMS, email)
+type NotificationSender interface {
+	// Channel returns the channel this sender delivers on
+	Channel() entities.NotificationChannel
+
+	// Send delivers the message and returns the provider's message ID
+	Send(ctx context.Context, message NotificationMessage) (string, error)
+}
@@ -0,0 +1,16 @@
+package providers
+
+import "context"
+
+// RoutingProvider estimates trips by road, which in traffic or across water
+// can differ a lot from straight-line distance
+type RoutingProvider interface {
+	// Routes returns one route from origin per destination, in order
+	Routes(ctx context.Context, origin Coordinates, destinations []Coordinates) ([]Route, error)
+}
+
+// Route is a trip by road
+type Route struct {
+	DurationMinutes float64
+	DistanceKm      float64
+}
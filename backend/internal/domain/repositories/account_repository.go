@@ -0,0 +1,64 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+)
+
+// LoginOTPRepository defines the interface for one-time login codes
+type LoginOTPRepository interface {
+	// Create stores a new code
+	Create(ctx context.Context, otp *entities.LoginOTP) error
+
+	// GetLatest retrieves the most recently issued code for a phone number
+	GetLatest(ctx context.Context, phone string) (*entities.LoginOTP, error)
+
+	// CountSince counts codes issued to a phone number since the given time
+	CountSince(ctx context.Context, phone string, since time.Time) (int, error)
+
+	// IncrementAttempts atomically records a verification attempt on an unused
+	// code below maxAttempts and returns the new count. It returns a conflict
+	// error when the code is used or out of attempts, so concurrent guesses
+	// cannot get past the cap.
+	IncrementAttempts(ctx context.Context, id string, maxAttempts int) (int, error)
+
+	// Consume marks a code with at most maxAttempts attempts as used. It
+	// returns a conflict error if the code was already consumed or is out of
+	// attempts, so a code can only log in once.
+	Consume(ctx context.Context, id string, maxAttempts int) error
+}
+
+// SessionRepository defines the interface for login sessions
+type SessionRepository interface {
+	// Create stores a new session
+	Create(ctx context.Context, session *entities.UserSession) error
+
+	// GetByTokenHash retrieves a session by the SHA-256 of its token
+	GetByTokenHash(ctx context.Context, tokenHash string) (*entities.UserSession, error)
+
+	// Delete removes a session
+	Delete(ctx context.Context, id string) error
+}
+
+// SavedFacilityRepository defines the interface for a patient's saved facilities
+type SavedFacilityRepository interface {
+	// Save adds a facility to the user's saved list; saving twice is a no-op
+	Save(ctx context.Context, userID, facilityID string) error
+
+	// Remove removes a facility from the user's saved list
+	Remove(ctx context.Context, userID, facilityID string) error
+
+	// ListByUser retrieves saved facilities, most recently saved first
+	ListByUser(ctx context.Context, userID string) ([]*entities.SavedFacility, error)
+}
+
+// NotificationPreferenceRepository defines the interface for notification preferences
+type NotificationPreferenceRepository interface {
+	// GetByUserID retrieves a user's preferences
+	GetByUserID(ctx context.Context, userID string) (*entities.NotificationPreference, error)
+
+	// Upsert creates or replaces a user's preferences
+	Upsert(ctx context.Context, prefs *entities.NotificationPreference) error
+}
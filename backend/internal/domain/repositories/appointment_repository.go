@@ -26,6 +26,10 @@ type AppointmentRepository interface {
 
 	// ListByFacility retrieves appointments for a facility
 	ListByFacility(ctx context.Context, facilityID string, filter AppointmentFilter) ([]*entities.Appointment, error)
+
+	// LinkToUserByPhone assigns appointments booked without an account to the user
+	// when the patient phone matches, and returns how many were linked
+	LinkToUserByPhone(ctx context.Context, userID, phone string) (int, error)
 }
 
 // AppointmentFilter defines filters for listing appointments
@@ -45,9 +49,13 @@ type AvailabilityRepository interface {
 	// GetByID retrieves an availability slot by ID
 	GetByID(ctx context.Context, id string) (*entities.AvailabilitySlot, error)
 
-	// ListByFacility retrieves availability slots for a facility
+	// ListByFacility retrieves availability slots for a facility that start in [from, to),
+	// with booked and unexpired held counts filled in
 	ListByFacility(ctx context.Context, facilityID string, from, to time.Time) ([]*entities.AvailabilitySlot, error)
 
+	// EnsureSlots inserts slots that do not exist yet; existing slots are left untouched
+	EnsureSlots(ctx context.Context, slots []*entities.AvailabilitySlot) error
+
 	// Update updates an availability slot
 	Update(ctx context.Context, slot *entities.AvailabilitySlot) error
 
@@ -0,0 +1,34 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+)
+
+// AuditFilter selects audit entries. Empty fields match everything.
+type AuditFilter struct {
+	FacilityID string
+	ActorID    string
+	EntityType string
+	EntityID   string
+	From       *time.Time
+	To         *time.Time
+	Limit      int
+	Offset     int
+}
+
+// AuditRepository stores the append-only audit log
+type AuditRepository interface {
+	// Append assigns the entry its sequence and chains it to the latest entry.
+	// Appends are serialized so the chain never forks.
+	Append(ctx context.Context, entry *entities.AuditEntry) error
+
+	// List returns entries matching the filter, newest first
+	List(ctx context.Context, filter AuditFilter) ([]*entities.AuditEntry, error)
+
+	// ListAfter returns up to limit entries with a sequence above afterSequence,
+	// in chain order
+	ListAfter(ctx context.Context, afterSequence int64, limit int) ([]*entities.AuditEntry, error)
+}
@@ -0,0 +1,57 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+)
+
+// CalendarRepository defines the interface for native facility calendar configuration
+type CalendarRepository interface {
+	// GetCalendar retrieves a facility's calendar settings; returns a not found error when none exist
+	GetCalendar(ctx context.Context, facilityID string) (*entities.FacilityCalendar, error)
+
+	// UpsertCalendar creates or replaces a facility's calendar settings
+	UpsertCalendar(ctx context.Context, calendar *entities.FacilityCalendar) error
+
+	// ListTemplates retrieves all weekly slot templates for a facility
+	ListTemplates(ctx context.Context, facilityID string) ([]*entities.SlotTemplate, error)
+
+	// CreateTemplate creates a weekly slot template
+	CreateTemplate(ctx context.Context, template *entities.SlotTemplate) error
+
+	// DeleteTemplate deletes a facility's slot template
+	DeleteTemplate(ctx context.Context, facilityID, id string) error
+
+	// ListBlackouts retrieves blackouts for a facility that overlap [from, to)
+	ListBlackouts(ctx context.Context, facilityID string, from, to time.Time) ([]*entities.CalendarBlackout, error)
+
+	// CreateBlackout creates a blackout period
+	CreateBlackout(ctx context.Context, blackout *entities.CalendarBlackout) error
+
+	// DeleteBlackout deletes a facility's blackout period
+	DeleteBlackout(ctx context.Context, facilityID, id string) error
+}
+
+// SlotReservationRepository defines the interface for holds and bookings on availability slots.
+// Implementations must serialize reservations per slot so capacity is never exceeded.
+type SlotReservationRepository interface {
+	// GetByID retrieves a reservation with its slot's facility and start time
+	GetByID(ctx context.Context, id string) (*entities.SlotReservation, error)
+
+	// Hold reserves one unit of the slot until expiresAt; returns a conflict error when the slot is full
+	Hold(ctx context.Context, slotID string, expiresAt, now time.Time) (*entities.SlotReservation, error)
+
+	// Book books one unit of the slot directly; returns a conflict error when the slot is full
+	Book(ctx context.Context, slotID string, now time.Time) (*entities.SlotReservation, error)
+
+	// ConfirmHold turns an unexpired hold into a booking; returns a conflict error when it has expired
+	ConfirmHold(ctx context.Context, holdID string, now time.Time) (*entities.SlotReservation, error)
+
+	// Release releases an active hold
+	Release(ctx context.Context, holdID string) error
+
+	// Cancel cancels a booking, returning its capacity to the slot
+	Cancel(ctx context.Context, bookingID string) error
+}
@@ -0,0 +1,28 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+)
+
+// CapacityCutoffs holds, per capacity field, the report time before which a
+// value is due for attention
+type CapacityCutoffs map[entities.CapacityField]time.Time
+
+// CapacityFreshnessRepository finds capacity data that is about to expire and
+// tracks operator reminders
+type CapacityFreshnessRepository interface {
+	// ListFacilitiesReportedBefore returns, in ID order after afterID, up to
+	// limit active facilities with a facility or ward capacity value that was
+	// reported before its field's cutoff and has not already decayed to unknown
+	ListFacilitiesReportedBefore(ctx context.Context, cutoffs CapacityCutoffs, afterID string, limit int) ([]string, error)
+
+	// LastNudgedAt returns when the facility's operator was last reminded, or
+	// nil if never
+	LastNudgedAt(ctx context.Context, facilityID string) (*time.Time, error)
+
+	// RecordNudge records that the facility's operator was reminded at the given time
+	RecordNudge(ctx context.Context, facilityID string, at time.Time) error
+}
@@ -0,0 +1,32 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+)
+
+// CapacityHistoryRepository stores capacity reports over time and the
+// hour-of-week profiles built from them
+type CapacityHistoryRepository interface {
+	// Record appends a capacity report
+	Record(ctx context.Context, observation *entities.CapacityObservation) error
+
+	// ListObservedFacilityIDs returns the facilities with reports observed at or after since
+	ListObservedFacilityIDs(ctx context.Context, since time.Time) ([]string, error)
+
+	// ListObservations returns a facility's reports observed at or after
+	// since, ordered by ward and observation time
+	ListObservations(ctx context.Context, facilityID string, since time.Time) ([]*entities.CapacityObservation, error)
+
+	// ReplaceProfiles replaces all of a facility's profiles with the given ones
+	ReplaceProfiles(ctx context.Context, facilityID string, profiles []*entities.WaitTimeProfile) error
+
+	// ListProfiles returns the profiles of the given facilities for the given hours of the week
+	ListProfiles(ctx context.Context, facilityIDs []string, hoursOfWeek []int) ([]*entities.WaitTimeProfile, error)
+
+	// DeleteObservationsBefore removes reports observed before the given time
+	// and returns how many were removed
+	DeleteObservationsBefore(ctx context.Context, before time.Time) (int64, error)
+}
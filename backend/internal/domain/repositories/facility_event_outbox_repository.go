@@ -0,0 +1,35 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+)
+
+// FacilityEventOutboxRepository writes facility changes together with the
+// events describing them, so an event is recorded if and only if its change
+// is committed. Writes follow the same version checks as the entity
+// repositories: a stale version returns a Conflict error and records nothing.
+type FacilityEventOutboxRepository interface {
+	// UpdateFacility updates a facility and records its events in one transaction
+	UpdateFacility(ctx context.Context, facility *entities.Facility, events []*entities.FacilityEvent) error
+
+	// CreateFacilityProcedure creates a facility procedure and records its events in one transaction
+	CreateFacilityProcedure(ctx context.Context, fp *entities.FacilityProcedure, events []*entities.FacilityEvent) error
+
+	// UpdateFacilityProcedure updates a facility procedure and records its events in one transaction
+	UpdateFacilityProcedure(ctx context.Context, fp *entities.FacilityProcedure, events []*entities.FacilityEvent) error
+
+	// UpsertWard creates or updates a facility ward and records its events in one transaction
+	UpsertWard(ctx context.Context, ward *entities.FacilityWard, events []*entities.FacilityEvent) error
+
+	// Relay passes up to limit pending events to publish in the order they were
+	// recorded and marks each published. It stops at the first publish error so
+	// later events are not delivered ahead of it, and returns how many were
+	// published. Only one relay runs at a time across instances.
+	Relay(ctx context.Context, limit int, publish func(*entities.FacilityEvent) error) (int, error)
+
+	// DeletePublishedBefore prunes events published before the cutoff
+	DeletePublishedBefore(ctx context.Context, cutoff time.Time) (int64, error)
+}
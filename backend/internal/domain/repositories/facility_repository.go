@@ -17,7 +17,8 @@ type FacilityRepository interface {
 	// GetByIDs retrieves multiple facilities by their IDs
 	GetByIDs(ctx context.Context, ids []string) ([]*entities.Facility, error)
 
-	// Update updates a facility
+	// Update updates a facility if it is still at facility.Version and bumps
+	// the version. A stale version returns a Conflict error.
 	Update(ctx context.Context, facility *entities.Facility) error
 
 	// Delete deletes a facility
@@ -48,6 +49,40 @@ type FacilitySearchRepository interface {
 	Delete(ctx context.Context, id string) error
 }
 
+// FacilityMapRepository finds the facilities inside a map viewport
+type FacilityMapRepository interface {
+	// MapPoints returns up to limit facilities inside params.Bounds that match
+	// the search filters, along with how many match in all
+	MapPoints(ctx context.Context, params SearchParams, limit int) ([]*entities.FacilityMapPoint, int, error)
+}
+
+// FacilityMapCellRepository sums up the facilities inside a map viewport by
+// grid cell, so clusters cover every match rather than a page of them
+type FacilityMapCellRepository interface {
+	// MapCells groups the facilities inside params.Bounds that match the
+	// search filters into square cells cellDegrees across
+	MapCells(ctx context.Context, params SearchParams, cellDegrees float64) ([]*MapCell, error)
+}
+
+// MapCell sums up the facilities in one grid cell. X and Y count cells east
+// of the antimeridian and north of the south pole.
+type MapCell struct {
+	X     int
+	Y     int
+	Count int
+	// Location is the mean of the facilities' locations, and Bounds the box
+	// they fit in
+	Location entities.Location
+	Bounds   entities.GeoBounds
+	// MinPrice and MaxPrice span the prices of the facilities with one
+	MinPrice *float64
+	MaxPrice *float64
+	// CapacityMix counts the facilities by capacity status
+	CapacityMix map[string]int
+	// Facility is the cell's facility when it holds only one
+	Facility *entities.FacilityMapPoint
+}
+
 // FacilityFilter defines filters for listing facilities
 
 type FacilityFilter struct {
@@ -74,4 +109,71 @@ type SearchParams struct {
 	MaxPrice          *float64
 	Limit             int
 	Offset            int
+	SessionID         string // anonymous client session, used for experiment bucketing and analytics
+	ImpressionID      string // identifies this search response so later clicks and bookings can be attributed to it
+
+	// QueryEmbedding turns the search hybrid: keyword matches are fused with
+	// facilities whose embedding is at least MinSimilarity to it, with
+	// VectorWeight of the rank given to vector similarity. EmbeddingModel
+	// is the model it came from; the search stays keyword-only when the
+	// indexed embeddings came from another.
+	QueryEmbedding []float32
+	EmbeddingModel string
+	VectorWeight   float64
+	MinSimilarity  float64
+
+	// Area, when set, is searched instead of RadiusKm around the user, who
+	// stays the origin for distances
+	Area *SearchArea
+	// Bounds is the map viewport searched by FacilityMapRepository
+	Bounds *entities.GeoBounds
+	// OpenNow drops facilities reporting themselves closed; Open24Hours
+	// keeps only facilities open around the clock
+	OpenNow     bool
+	Open24Hours bool
+	// SortBy orders results by one of the SearchSort values instead of relevance
+	SortBy string
+	// IgnoredConstraints are constraint types the user removed, which are
+	// not applied even when the query states them
+	IgnoredConstraints []string
+}
+
+// Sort orders for facility search
+const (
+	SearchSortPrice    = "price"
+	SearchSortDistance = "distance"
+	SearchSortRating   = "rating"
+	// SearchSortTravelTime orders by estimated travel time by road. Backends
+	// sort by distance; the service reorders the nearest facilities.
+	SearchSortTravelTime = "travel_time"
+)
+
+// IsValidSearchSort reports whether sortBy is empty or a known sort order
+func IsValidSearchSort(sortBy string) bool {
+	switch sortBy {
+	case "", SearchSortPrice, SearchSortDistance, SearchSortRating, SearchSortTravelTime:
+		return true
+	}
+	return false
+}
+
+// SearchArea is a named circle to search within, such as a neighbourhood
+type SearchArea struct {
+	Name      string  `json:"name"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	RadiusKm  float64 `json:"radius_km"`
+}
+
+// GeoFilter returns the circle results must fall within: the area when one
+// is set, otherwise the radius around the user. ok is false when neither
+// is set.
+func (p SearchParams) GeoFilter() (lat, lon, radiusKm float64, ok bool) {
+	if p.Area != nil {
+		return p.Area.Latitude, p.Area.Longitude, p.Area.RadiusKm, true
+	}
+	if p.Latitude != 0 || p.Longitude != 0 {
+		return p.Latitude, p.Longitude, p.RadiusKm, true
+	}
+	return 0, 0, 0, false
 }
@@ -23,10 +23,13 @@ type FacilityWardRepository interface {
 	// GetByFacilityAndWard retrieves a specific ward by facility ID and ward name
 	GetByFacilityAndWard(ctx context.Context, facilityID, wardName string) (*entities.FacilityWard, error)
 
-	// Update updates a facility ward
+	// Update updates a facility ward if it is still at ward.Version and bumps
+	// the version. A stale version returns a Conflict error.
 	Update(ctx context.Context, ward *entities.FacilityWard) error
 
-	// Upsert creates or updates a facility ward (inserts if not exists, updates if exists)
+	// Upsert creates or updates a facility ward (inserts if not exists, updates if exists).
+	// An existing ward is only updated if it is still at ward.Version (zero when
+	// the caller saw no ward); otherwise a Conflict error is returned.
 	Upsert(ctx context.Context, ward *entities.FacilityWard) error
 
 	// Delete deletes a facility ward
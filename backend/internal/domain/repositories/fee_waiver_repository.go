@@ -11,6 +11,10 @@ type FeeWaiverRepository interface {
 	Create(ctx context.Context, waiver *entities.FeeWaiver) error
 	GetByID(ctx context.Context, id string) (*entities.FeeWaiver, error)
 	GetActiveFacilityWaiver(ctx context.Context, facilityID string) (*entities.FeeWaiver, error)
+	// GetActiveFacilityWaivers returns the active waiver of each facility,
+	// its own or else a global one, keyed by facility ID. Facilities without
+	// one are absent from the result.
+	GetActiveFacilityWaivers(ctx context.Context, facilityIDs []string) (map[string]*entities.FeeWaiver, error)
 	IncrementUsage(ctx context.Context, id string) error
 	Update(ctx context.Context, waiver *entities.FeeWaiver) error
 }
@@ -0,0 +1,17 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+)
+
+// GeocodeRepository stores geocoding results keyed by normalised address
+type GeocodeRepository interface {
+	// Get returns the stored result for an address key, or a not found error
+	Get(ctx context.Context, addressKey string) (*entities.GeocodedLocation, error)
+
+	// Save inserts or replaces the result for its address key. A manual
+	// entry is only replaced by another manual entry.
+	Save(ctx context.Context, location *entities.GeocodedLocation) error
+}
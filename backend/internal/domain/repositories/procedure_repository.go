@@ -57,13 +57,23 @@ type FacilityProcedureRepository interface {
 	// This ensures search results are complete, not limited to current page
 	ListByFacilityWithCount(ctx context.Context, facilityID string, filter FacilityProcedureFilter) ([]*entities.FacilityProcedure, int, error)
 
-	// Update updates a facility procedure
+	// Update updates a facility procedure if it is still at fp.Version and
+	// bumps the version. A stale version returns a Conflict error.
 	Update(ctx context.Context, fp *entities.FacilityProcedure) error
 
 	// Delete deletes a facility procedure
 	Delete(ctx context.Context, id string) error
 }
 
+// FacilityProcedureCategoryRepository lists facility procedures of one
+// category for many facilities in a single query
+type FacilityProcedureCategoryRepository interface {
+	// ListByFacilitiesAndCategory returns the facilities' procedures whose
+	// category matches, by name within each facility, keyed by facility ID.
+	// Facilities without any are absent from the result.
+	ListByFacilitiesAndCategory(ctx context.Context, facilityIDs []string, category string) (map[string][]*entities.FacilityProcedure, error)
+}
+
 // FacilityProcedureFilter defines filters for facility procedure queries
 // All filters are applied BEFORE pagination to ensure search works across entire dataset
 type FacilityProcedureFilter struct {
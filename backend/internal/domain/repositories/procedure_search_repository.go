@@ -0,0 +1,56 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+)
+
+// ProcedureSearchRepository searches canonical procedures
+type ProcedureSearchRepository interface {
+	// SearchProcedures returns the procedures matching params and the total number of matches
+	SearchProcedures(ctx context.Context, params ProcedureSearchParams) ([]*entities.ProcedureSearchResult, int, error)
+
+	// SuggestConcepts returns conditions and specialties starting with the query
+	SuggestConcepts(ctx context.Context, query string, limit int) (conditions, specialties []entities.ConceptSuggestion, err error)
+}
+
+// ProcedureSearchParams defines parameters for procedure search
+type ProcedureSearchParams struct {
+	Query    string
+	Category string
+	Limit    int
+	Offset   int
+
+	// QueryEmbedding turns the search hybrid, as in SearchParams
+	QueryEmbedding []float32
+	EmbeddingModel string
+	VectorWeight   float64
+	MinSimilarity  float64
+}
+
+// ProcedurePriceRepository aggregates facility prices per procedure
+type ProcedurePriceRepository interface {
+	// PriceStats returns price statistics for the given procedures, or for
+	// every priced procedure when procedureIDs is empty. Procedures no
+	// facility offers are absent from the result.
+	PriceStats(ctx context.Context, procedureIDs []string) (map[string]*entities.ProcedurePriceStats, error)
+}
+
+// ProcedureOfferRepository finds the facilities offering procedures near a
+// location
+type ProcedureOfferRepository interface {
+	// ListOffers returns the available priced offers for the procedures at
+	// active facilities within the radius, nearest first
+	ListOffers(ctx context.Context, params ProcedureOfferParams) ([]*entities.ProcedureOffer, error)
+}
+
+// ProcedureOfferParams filters procedure offers
+type ProcedureOfferParams struct {
+	ProcedureIDs      []string
+	Latitude          float64
+	Longitude         float64
+	RadiusKm          float64
+	InsuranceProvider string // Only facilities accepting this insurer, by name or code
+	Limit             int
+}
@@ -2,10 +2,56 @@ package repositories
 
 import (
 	"context"
+	"time"
+
 	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
 )
 
 type SearchAnalyticsRepository interface {
 	LogEvent(ctx context.Context, event *entities.SearchEvent) error
+
+	// CopyEvent logs a copy of an earlier search event under newID, for a
+	// response served from cache. It reports false, copying nothing, when
+	// sourceID has not been logged.
+	CopyEvent(ctx context.Context, sourceID, newID string) (bool, error)
+
 	GetZeroResultQueries(ctx context.Context, limit int) ([]*entities.SearchEvent, error)
+
+	// GetLowResultQueries returns searches since the given time that produced at most maxResultCount results.
+	GetLowResultQueries(ctx context.Context, maxResultCount int, since time.Time, limit int) ([]*entities.SearchEvent, error)
+
+	// GetQueryOutcome counts searches for a normalized query since the given time and how many returned results.
+	GetQueryOutcome(ctx context.Context, normalizedQuery string, since time.Time) (*entities.QueryOutcome, error)
+
+	// GetExperimentVariantStats aggregates search outcomes per variant of an experiment.
+	GetExperimentVariantStats(ctx context.Context, experimentID string) ([]*entities.ExperimentVariantStats, error)
+}
+
+// SearchDictionaryFixRepository persists dictionary fixes accepted through zero-result triage.
+type SearchDictionaryFixRepository interface {
+	Create(ctx context.Context, fix *entities.SearchDictionaryFix) error
+	Update(ctx context.Context, fix *entities.SearchDictionaryFix) error
+	List(ctx context.Context, status entities.DictionaryFixStatus) ([]*entities.SearchDictionaryFix, error)
+}
+
+// SearchExperimentRepository persists search A/B experiments.
+type SearchExperimentRepository interface {
+	Create(ctx context.Context, experiment *entities.SearchExperiment) error
+	Update(ctx context.Context, experiment *entities.SearchExperiment) error
+	GetByID(ctx context.Context, id string) (*entities.SearchExperiment, error)
+	List(ctx context.Context) ([]*entities.SearchExperiment, error)
+}
+
+// SearchInteractionRepository persists post-search interactions and aggregates them into engagement reports.
+type SearchInteractionRepository interface {
+	Create(ctx context.Context, interaction *entities.SearchInteraction) error
+
+	// GetQueryEngagement aggregates searches and their interactions per normalized query.
+	GetQueryEngagement(ctx context.Context, filter entities.SearchInteractionReportFilter) ([]*entities.QueryEngagementStats, error)
+
+	// GetFacilityEngagement aggregates impressions and interactions per facility.
+	GetFacilityEngagement(ctx context.Context, filter entities.SearchInteractionReportFilter) ([]*entities.FacilityEngagementStats, error)
+
+	// GetPositionEngagement aggregates impressions and result clicks per result position.
+	GetPositionEngagement(ctx context.Context, filter entities.SearchInteractionReportFilter) ([]*entities.PositionEngagementStats, error)
 }
@@ -0,0 +1,11 @@
+package repositories
+
+import "context"
+
+// Transactor runs a unit of work in one database transaction. Repository
+// calls made with the ctx passed to fn join that transaction, and nested
+// calls join the outermost one.
+type Transactor interface {
+	// InTx commits when fn returns nil and rolls back otherwise
+	InTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
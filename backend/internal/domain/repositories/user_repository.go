@@ -17,6 +17,9 @@ type UserRepository interface {
 	// GetByEmail retrieves a user by email
 	GetByEmail(ctx context.Context, email string) (*entities.User, error)
 
+	// GetByPhone retrieves a user by E.164 phone number
+	GetByPhone(ctx context.Context, phone string) (*entities.User, error)
+
 	// Update updates a user
 	Update(ctx context.Context, user *entities.User) error
 
@@ -24,7 +27,9 @@ type UserRepository interface {
 	Delete(ctx context.Context, id string) error
 }
 
-// ReviewRepository defines the interface for review operations
+// ReviewRepository defines the interface for review operations. Writes
+// recompute the facility's rating and review count from its published
+// reviews in the same transaction.
 type ReviewRepository interface {
 	// Create creates a new review
 	Create(ctx context.Context, review *entities.Review) error
@@ -32,9 +37,15 @@ type ReviewRepository interface {
 	// GetByID retrieves a review by ID
 	GetByID(ctx context.Context, id string) (*entities.Review, error)
 
-	// ListByFacility retrieves reviews for a facility
+	// GetByAppointmentID retrieves the review left for an appointment
+	GetByAppointmentID(ctx context.Context, appointmentID string) (*entities.Review, error)
+
+	// ListByFacility retrieves published reviews for a facility, newest first
 	ListByFacility(ctx context.Context, facilityID string, limit, offset int) ([]*entities.Review, error)
 
+	// ListByStatus retrieves reviews in a moderation status, oldest first
+	ListByStatus(ctx context.Context, status entities.ReviewStatus, limit, offset int) ([]*entities.Review, error)
+
 	// ListByUser retrieves reviews by a user
 	ListByUser(ctx context.Context, userID string, limit, offset int) ([]*entities.Review, error)
 
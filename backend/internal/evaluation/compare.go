@@ -0,0 +1,314 @@
+package evaluation
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// metricEpsilon absorbs floating point noise when comparing averages.
+const metricEpsilon = 1e-9
+
+// MetricThreshold bounds a group's metrics. Drops are measured against the
+// baseline; minimums are absolute and apply even without a baseline. Zero
+// disables a bound, so use a small positive value to forbid any drop.
+type MetricThreshold struct {
+	MaxRecallDrop float64 `json:"max_recall_drop,omitempty"`
+	MaxMRRDrop    float64 `json:"max_mrr_drop,omitempty"`
+	MinRecall     float64 `json:"min_recall,omitempty"`
+	MinMRR        float64 `json:"min_mrr,omitempty"`
+}
+
+// Thresholds configures when a run fails the regression gate.
+type Thresholds struct {
+	Overall       MetricThreshold            `json:"overall"`
+	PerIntent     map[Intent]MetricThreshold `json:"per_intent,omitempty"`
+	PerDifficulty map[string]MetricThreshold `json:"per_difficulty,omitempty"`
+	// QueryTolerance is how far a single query's Recall@10 or MRR@10 may fall
+	// before the query is listed as regressed.
+	QueryTolerance float64 `json:"query_tolerance"`
+	// MaxRegressedQueries fails the run when more queries regress; negative disables.
+	MaxRegressedQueries int `json:"max_regressed_queries"`
+}
+
+// LoadThresholds reads gate thresholds from a JSON file.
+func LoadThresholds(path string) (Thresholds, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Thresholds{}, fmt.Errorf("failed to read thresholds file: %w", err)
+	}
+	var t Thresholds
+	if err := json.Unmarshal(data, &t); err != nil {
+		return Thresholds{}, fmt.Errorf("failed to parse thresholds: %w", err)
+	}
+	for intent := range t.PerIntent {
+		if !intent.IsValid() {
+			return Thresholds{}, fmt.Errorf("thresholds: invalid intent %q", intent)
+		}
+	}
+	for difficulty := range t.PerDifficulty {
+		if !validDifficulties[difficulty] {
+			return Thresholds{}, fmt.Errorf("thresholds: invalid difficulty %q", difficulty)
+		}
+	}
+	return t, nil
+}
+
+// MetricDelta compares one group of queries between the baseline and current run.
+type MetricDelta struct {
+	Group          string  `json:"group"`
+	Count          int     `json:"count"`
+	BaselineRecall float64 `json:"baseline_recall"`
+	CurrentRecall  float64 `json:"current_recall"`
+	RecallDelta    float64 `json:"recall_delta"`
+	BaselineMRR    float64 `json:"baseline_mrr"`
+	CurrentMRR     float64 `json:"current_mrr"`
+	MRRDelta       float64 `json:"mrr_delta"`
+}
+
+// QueryRegression is a golden query that scored worse than in the baseline.
+type QueryRegression struct {
+	QueryID        string  `json:"query_id"`
+	Query          string  `json:"query"`
+	Intent         Intent  `json:"intent"`
+	Difficulty     string  `json:"difficulty"`
+	BaselineRecall float64 `json:"baseline_recall"`
+	CurrentRecall  float64 `json:"current_recall"`
+	BaselineMRR    float64 `json:"baseline_mrr"`
+	CurrentMRR     float64 `json:"current_mrr"`
+	Error          string  `json:"error,omitempty"`
+}
+
+// Breach is a threshold the current run violated.
+type Breach struct {
+	Group   string  `json:"group"`
+	Metric  string  `json:"metric"`
+	Value   float64 `json:"value"`
+	Limit   float64 `json:"limit"`
+	Message string  `json:"message"`
+}
+
+// Comparison is the outcome of checking a run against a baseline and thresholds.
+type Comparison struct {
+	HasBaseline      bool              `json:"has_baseline"`
+	Overall          MetricDelta       `json:"overall"`
+	ByIntent         []MetricDelta     `json:"by_intent"`
+	ByDifficulty     []MetricDelta     `json:"by_difficulty"`
+	RegressedQueries []QueryRegression `json:"regressed_queries"`
+	NewQueries       []string          `json:"new_queries,omitempty"`
+	Breaches         []Breach          `json:"breaches"`
+}
+
+// Passed reports whether no threshold was breached.
+func (c *Comparison) Passed() bool {
+	return len(c.Breaches) == 0
+}
+
+// Compare checks current against baseline and thresholds. A nil baseline only
+// applies the absolute minimums.
+func Compare(baseline, current *EvalSummary, t Thresholds) *Comparison {
+	c := &Comparison{
+		HasBaseline:      baseline != nil,
+		RegressedQueries: []QueryRegression{},
+		Breaches:         []Breach{},
+	}
+	if baseline == nil {
+		baseline = &EvalSummary{}
+	}
+
+	overallBase := &IntentSummary{Count: baseline.TotalQueries, AvgRecallAt10: baseline.AvgRecallAt10, AvgMRRAt10: baseline.AvgMRRAt10}
+	overallCur := &IntentSummary{Count: current.TotalQueries, AvgRecallAt10: current.AvgRecallAt10, AvgMRRAt10: current.AvgMRRAt10}
+	c.Overall = newMetricDelta("overall", overallBase, overallCur)
+	c.checkGroup(c.Overall, t.Overall)
+
+	for _, intent := range ValidIntents() {
+		cur, ok := current.ByIntent[intent]
+		if !ok {
+			continue
+		}
+		delta := newMetricDelta("intent:"+string(intent), baseline.ByIntent[intent], cur)
+		c.ByIntent = append(c.ByIntent, delta)
+		if threshold, ok := t.PerIntent[intent]; ok {
+			c.checkGroup(delta, threshold)
+		}
+	}
+
+	difficulties := make([]string, 0, len(current.ByDifficulty))
+	for d := range current.ByDifficulty {
+		difficulties = append(difficulties, d)
+	}
+	sort.Slice(difficulties, func(i, j int) bool {
+		return difficultyRank(difficulties[i]) < difficultyRank(difficulties[j])
+	})
+	for _, d := range difficulties {
+		delta := newMetricDelta("difficulty:"+d, baseline.ByDifficulty[d], current.ByDifficulty[d])
+		c.ByDifficulty = append(c.ByDifficulty, delta)
+		if threshold, ok := t.PerDifficulty[d]; ok {
+			c.checkGroup(delta, threshold)
+		}
+	}
+
+	c.compareQueries(baseline.Results, current.Results, t.QueryTolerance)
+	if c.HasBaseline && t.MaxRegressedQueries >= 0 && len(c.RegressedQueries) > t.MaxRegressedQueries {
+		c.Breaches = append(c.Breaches, Breach{
+			Group:   "queries",
+			Metric:  "regressed_queries",
+			Value:   float64(len(c.RegressedQueries)),
+			Limit:   float64(t.MaxRegressedQueries),
+			Message: fmt.Sprintf("%d queries regressed (max %d)", len(c.RegressedQueries), t.MaxRegressedQueries),
+		})
+	}
+
+	return c
+}
+
+func newMetricDelta(group string, baseline, current *IntentSummary) MetricDelta {
+	if baseline == nil {
+		baseline = &IntentSummary{}
+	}
+	if current == nil {
+		current = &IntentSummary{}
+	}
+	return MetricDelta{
+		Group:          group,
+		Count:          current.Count,
+		BaselineRecall: baseline.AvgRecallAt10,
+		CurrentRecall:  current.AvgRecallAt10,
+		RecallDelta:    current.AvgRecallAt10 - baseline.AvgRecallAt10,
+		BaselineMRR:    baseline.AvgMRRAt10,
+		CurrentMRR:     current.AvgMRRAt10,
+		MRRDelta:       current.AvgMRRAt10 - baseline.AvgMRRAt10,
+	}
+}
+
+func (c *Comparison) checkGroup(d MetricDelta, t MetricThreshold) {
+	if c.HasBaseline {
+		if t.MaxRecallDrop > 0 && -d.RecallDelta > t.MaxRecallDrop+metricEpsilon {
+			c.addBreach(d.Group, "recall_drop", -d.RecallDelta, t.MaxRecallDrop)
+		}
+		if t.MaxMRRDrop > 0 && -d.MRRDelta > t.MaxMRRDrop+metricEpsilon {
+			c.addBreach(d.Group, "mrr_drop", -d.MRRDelta, t.MaxMRRDrop)
+		}
+	}
+	if t.MinRecall > 0 && d.CurrentRecall < t.MinRecall-metricEpsilon {
+		c.addBreach(d.Group, "recall", d.CurrentRecall, t.MinRecall)
+	}
+	if t.MinMRR > 0 && d.CurrentMRR < t.MinMRR-metricEpsilon {
+		c.addBreach(d.Group, "mrr", d.CurrentMRR, t.MinMRR)
+	}
+}
+
+func (c *Comparison) addBreach(group, metric string, value, limit float64) {
+	var message string
+	if strings.HasSuffix(metric, "_drop") {
+		message = fmt.Sprintf("%s %s dropped by %.4f (max %.4f)", group, strings.TrimSuffix(metric, "_drop"), value, limit)
+	} else {
+		message = fmt.Sprintf("%s %s is %.4f (min %.4f)", group, metric, value, limit)
+	}
+	c.Breaches = append(c.Breaches, Breach{Group: group, Metric: metric, Value: value, Limit: limit, Message: message})
+}
+
+func (c *Comparison) compareQueries(baseline, current []EvalResult, tolerance float64) {
+	byID := make(map[string]EvalResult, len(baseline))
+	for _, r := range baseline {
+		byID[r.QueryID] = r
+	}
+
+	for _, cur := range current {
+		base, ok := byID[cur.QueryID]
+		if !ok {
+			if c.HasBaseline {
+				c.NewQueries = append(c.NewQueries, cur.QueryID)
+			}
+			continue
+		}
+		failed := cur.Error != "" && base.Error == ""
+		if !failed &&
+			base.RecallAt10-cur.RecallAt10 <= tolerance+metricEpsilon &&
+			base.MRRAt10-cur.MRRAt10 <= tolerance+metricEpsilon {
+			continue
+		}
+		c.RegressedQueries = append(c.RegressedQueries, QueryRegression{
+			QueryID:        cur.QueryID,
+			Query:          cur.Query,
+			Intent:         cur.Intent,
+			Difficulty:     cur.Difficulty,
+			BaselineRecall: base.RecallAt10,
+			CurrentRecall:  cur.RecallAt10,
+			BaselineMRR:    base.MRRAt10,
+			CurrentMRR:     cur.MRRAt10,
+			Error:          cur.Error,
+		})
+	}
+
+	// Worst regressions first.
+	sort.SliceStable(c.RegressedQueries, func(i, j int) bool {
+		a, b := c.RegressedQueries[i], c.RegressedQueries[j]
+		da := (a.BaselineRecall - a.CurrentRecall) + (a.BaselineMRR - a.CurrentMRR)
+		db := (b.BaselineRecall - b.CurrentRecall) + (b.BaselineMRR - b.CurrentMRR)
+		if da != db {
+			return da > db
+		}
+		return a.QueryID < b.QueryID
+	})
+}
+
+func difficultyRank(d string) int {
+	switch d {
+	case "easy":
+		return 0
+	case "medium":
+		return 1
+	case "hard":
+		return 2
+	}
+	return 3
+}
+
+// WriteTable renders the comparison as a plain-text table.
+func (c *Comparison) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "GROUP\tN\tRECALL@10\tΔ\tMRR@10\tΔ")
+	rows := append([]MetricDelta{c.Overall}, c.ByIntent...)
+	rows = append(rows, c.ByDifficulty...)
+	for _, d := range rows {
+		recallDelta, mrrDelta := "-", "-"
+		if c.HasBaseline {
+			recallDelta = fmt.Sprintf("%+.4f", d.RecallDelta)
+			mrrDelta = fmt.Sprintf("%+.4f", d.MRRDelta)
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%.4f\t%s\t%.4f\t%s\n",
+			d.Group, d.Count, d.CurrentRecall, recallDelta, d.CurrentMRR, mrrDelta)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if len(c.RegressedQueries) > 0 {
+		fmt.Fprintf(w, "\nRegressed queries (%d):\n", len(c.RegressedQueries))
+		tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "ID\tQUERY\tINTENT\tDIFFICULTY\tRECALL@10\tMRR@10")
+		for _, q := range c.RegressedQueries {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%.2f → %.2f\t%.2f → %.2f\n",
+				q.QueryID, q.Query, q.Intent, q.Difficulty, q.BaselineRecall, q.CurrentRecall, q.BaselineMRR, q.CurrentMRR)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintln(w)
+	if c.Passed() {
+		_, err := fmt.Fprintln(w, "PASS")
+		return err
+	}
+	for _, b := range c.Breaches {
+		fmt.Fprintf(w, "BREACH: %s\n", b.Message)
+	}
+	_, err := fmt.Fprintln(w, "FAIL")
+	return err
+}
@@ -0,0 +1,184 @@
+package evaluation
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/repositories"
+)
+
+type stubProvider struct {
+	results map[string][]entities.FacilitySearchResult
+}
+
+func (p *stubProvider) Search(ctx context.Context, params repositories.SearchParams) ([]entities.FacilitySearchResult, int, error) {
+	if params.Query == "broken" {
+		return nil, 0, errors.New("search backend unavailable")
+	}
+	res := p.results[params.Query]
+	return res, len(res), nil
+}
+
+func runStub(t *testing.T, results map[string][]entities.FacilitySearchResult) *EvalSummary {
+	t.Helper()
+	queries := []GoldenQuery{
+		{ID: "q1", Query: "malaria", Intent: IntentCondition, ExpectedTags: []string{"laboratory"}, Difficulty: "easy"},
+		{ID: "q2", Query: "ct scan", Intent: IntentProcedure, ExpectedTags: []string{"imaging"}, Difficulty: "medium"},
+		{ID: "q3", Query: "broken", Intent: IntentProcedure, ExpectedTags: []string{"imaging"}, Difficulty: "hard"},
+	}
+	summary, err := NewRunner(&stubProvider{results: results}).Run(context.Background(), queries)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return summary
+}
+
+func goodResults() map[string][]entities.FacilitySearchResult {
+	return map[string][]entities.FacilitySearchResult{
+		"malaria": {{ID: "a", Tags: []string{"laboratory"}}},
+		"ct scan": {{ID: "b", Tags: []string{"imaging"}}},
+	}
+}
+
+func TestRunner_RecordsPerQueryResultsAndDifficulty(t *testing.T) {
+	summary := runStub(t, goodResults())
+
+	if len(summary.Results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(summary.Results))
+	}
+	if summary.FailedQueries != 1 {
+		t.Errorf("expected 1 failed query, got %d", summary.FailedQueries)
+	}
+	if summary.Results[2].Error == "" {
+		t.Error("expected error recorded for failed query")
+	}
+	if ds := summary.ByDifficulty["easy"]; ds == nil || ds.Count != 1 || !almostEqual(ds.AvgRecallAt10, 1.0) {
+		t.Errorf("unexpected easy difficulty summary: %+v", ds)
+	}
+	if _, ok := summary.ByDifficulty["hard"]; ok {
+		t.Error("failed queries should not contribute to difficulty groups")
+	}
+}
+
+func TestCompare_NoRegressionPasses(t *testing.T) {
+	baseline := runStub(t, goodResults())
+	current := runStub(t, goodResults())
+
+	cmp := Compare(baseline, current, Thresholds{
+		Overall:             MetricThreshold{MaxRecallDrop: 0.01, MaxMRRDrop: 0.01},
+		MaxRegressedQueries: 0,
+	})
+	if !cmp.Passed() {
+		t.Errorf("expected pass, got breaches %+v", cmp.Breaches)
+	}
+	if len(cmp.RegressedQueries) != 0 {
+		t.Errorf("expected no regressed queries, got %+v", cmp.RegressedQueries)
+	}
+}
+
+func TestCompare_ListsRegressedQueriesAndBreaches(t *testing.T) {
+	baseline := runStub(t, goodResults())
+	worse := goodResults()
+	worse["ct scan"] = []entities.FacilitySearchResult{{ID: "c", Tags: []string{"dental"}}, {ID: "b", Tags: []string{"imaging"}}}
+	current := runStub(t, worse)
+
+	cmp := Compare(baseline, current, Thresholds{
+		Overall:             MetricThreshold{MaxMRRDrop: 0.5},
+		PerIntent:           map[Intent]MetricThreshold{IntentProcedure: {MaxMRRDrop: 0.1}},
+		PerDifficulty:       map[string]MetricThreshold{"medium": {MaxRecallDrop: 0.1}},
+		MaxRegressedQueries: 0,
+	})
+
+	if len(cmp.RegressedQueries) != 1 || cmp.RegressedQueries[0].QueryID != "q2" {
+		t.Fatalf("expected q2 regressed, got %+v", cmp.RegressedQueries)
+	}
+	if !almostEqual(cmp.RegressedQueries[0].CurrentMRR, 0.5) {
+		t.Errorf("expected current MRR 0.5, got %f", cmp.RegressedQueries[0].CurrentMRR)
+	}
+
+	groups := make(map[string]bool)
+	for _, b := range cmp.Breaches {
+		groups[b.Group+"/"+b.Metric] = true
+	}
+	if !groups["intent:procedure/mrr_drop"] {
+		t.Errorf("expected procedure MRR breach, got %+v", cmp.Breaches)
+	}
+	if !groups["queries/regressed_queries"] {
+		t.Errorf("expected regressed query breach, got %+v", cmp.Breaches)
+	}
+	if groups["overall/mrr_drop"] || groups["difficulty:medium/recall_drop"] {
+		t.Errorf("unexpected breach within tolerance: %+v", cmp.Breaches)
+	}
+	if cmp.Passed() {
+		t.Error("expected comparison to fail")
+	}
+}
+
+func TestCompare_WithoutBaselineOnlyChecksMinimums(t *testing.T) {
+	current := runStub(t, map[string][]entities.FacilitySearchResult{})
+
+	cmp := Compare(nil, current, Thresholds{Overall: MetricThreshold{MaxRecallDrop: 0.01, MinRecall: 0.5}})
+	if cmp.HasBaseline {
+		t.Error("expected no baseline")
+	}
+	if len(cmp.Breaches) != 1 || cmp.Breaches[0].Metric != "recall" {
+		t.Errorf("expected only a min recall breach, got %+v", cmp.Breaches)
+	}
+}
+
+func TestReport_SaveAndLoadRoundTrip(t *testing.T) {
+	summary := runStub(t, goodResults())
+	report := &Report{
+		CreatedAt: time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC),
+		Corpus:    "fixture",
+		Summary:   summary,
+	}
+	path, err := SaveReportToDir(t.TempDir(), report)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if filepath.Base(path) != "eval-fixture-20260301T093000Z.json" {
+		t.Errorf("unexpected report file name %s", filepath.Base(path))
+	}
+
+	loaded, err := LoadReport(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !almostEqual(loaded.Summary.AvgRecallAt10, summary.AvgRecallAt10) {
+		t.Errorf("recall mismatch: %f vs %f", loaded.Summary.AvgRecallAt10, summary.AvgRecallAt10)
+	}
+	if loaded.Summary.ByDifficulty["medium"] == nil || len(loaded.Summary.Results) != 3 {
+		t.Error("expected difficulty breakdown and per-query results to round-trip")
+	}
+}
+
+func TestLoadThresholds_InvalidIntent(t *testing.T) {
+	path := writeTempFile(t, `{"per_intent": {"weather": {"max_recall_drop": 0.1}}}`)
+	if _, err := LoadThresholds(path); err == nil {
+		t.Error("expected error for invalid intent")
+	}
+}
+
+func TestComparison_WriteTable(t *testing.T) {
+	baseline := runStub(t, goodResults())
+	current := runStub(t, map[string][]entities.FacilitySearchResult{})
+	cmp := Compare(baseline, current, Thresholds{MaxRegressedQueries: 0})
+
+	var buf bytes.Buffer
+	if err := cmp.WriteTable(&buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{"intent:condition", "difficulty:easy", "Regressed queries (2)", "FAIL"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected table to contain %q:\n%s", want, out)
+		}
+	}
+}
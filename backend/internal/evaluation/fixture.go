@@ -0,0 +1,429 @@
+package evaluation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/providers"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/repositories"
+	apperrors "github.com/zatekoja/Patientpricediscoverydesign/backend/pkg/errors"
+)
+
+// FixtureFacility is a facility in the in-memory evaluation corpus. Keywords
+// stand in for the procedure, concept and specialty fields indexed in Typesense.
+type FixtureFacility struct {
+	entities.Facility
+	Keywords []string `json:"keywords"`
+
+	embedding []float32
+}
+
+// minVectorCandidates mirrors the fewest nearest neighbours the Typesense
+// adapter asks for in hybrid search
+const minVectorCandidates = 100
+
+// FixtureCorpus is an in-memory FacilityRepository used to run the evaluation
+// gate without Postgres or Typesense. Search approximates the Typesense query:
+// any query token may match, name hits weigh double and results are ordered by
+// score, then rating.
+type FixtureCorpus struct {
+	mu         sync.RWMutex
+	facilities []*FixtureFacility
+}
+
+var _ repositories.FacilityRepository = (*FixtureCorpus)(nil)
+
+// NewFixtureCorpus creates a corpus from the given facilities.
+func NewFixtureCorpus(facilities []*FixtureFacility) *FixtureCorpus {
+	return &FixtureCorpus{facilities: facilities}
+}
+
+// LoadFixtureCorpus reads a corpus from a JSON file of the form {"facilities": [...]}.
+func LoadFixtureCorpus(path string) (*FixtureCorpus, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixture corpus: %w", err)
+	}
+
+	var file struct {
+		Facilities []*FixtureFacility `json:"facilities"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse fixture corpus: %w", err)
+	}
+
+	seen := make(map[string]struct{}, len(file.Facilities))
+	for i, f := range file.Facilities {
+		if f.ID == "" {
+			return nil, fmt.Errorf("fixture facility at index %d: missing id", i)
+		}
+		if _, dup := seen[f.ID]; dup {
+			return nil, fmt.Errorf("fixture facility at index %d: duplicate id %q", i, f.ID)
+		}
+		seen[f.ID] = struct{}{}
+	}
+
+	return NewFixtureCorpus(file.Facilities), nil
+}
+
+// EmbedFacilities embeds every facility's name, type, tags and keywords, as
+// the indexer embeds facility concept aggregates, so searches with a query
+// embedding are hybrid.
+func (c *FixtureCorpus) EmbedFacilities(ctx context.Context, provider providers.EmbeddingProvider) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	texts := make([]string, len(c.facilities))
+	for i, f := range c.facilities {
+		parts := append([]string{f.Name, f.FacilityType}, f.Tags...)
+		texts[i] = strings.Join(append(parts, f.Keywords...), ". ")
+	}
+	vectors, err := provider.Embed(ctx, texts)
+	if err != nil {
+		return fmt.Errorf("failed to embed fixture facilities: %w", err)
+	}
+	if len(vectors) != len(c.facilities) {
+		return fmt.Errorf("expected %d embeddings, got %d", len(c.facilities), len(vectors))
+	}
+	for i, f := range c.facilities {
+		f.embedding = vectors[i]
+	}
+	return nil
+}
+
+// Len returns the number of facilities in the corpus.
+func (c *FixtureCorpus) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.facilities)
+}
+
+func (c *FixtureCorpus) Create(ctx context.Context, facility *entities.Facility) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, f := range c.facilities {
+		if f.ID == facility.ID {
+			return apperrors.NewConflictError("facility already exists")
+		}
+	}
+	c.facilities = append(c.facilities, &FixtureFacility{Facility: *facility})
+	return nil
+}
+
+func (c *FixtureCorpus) GetByID(ctx context.Context, id string) (*entities.Facility, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, f := range c.facilities {
+		if f.ID == id {
+			facility := f.Facility
+			return &facility, nil
+		}
+	}
+	return nil, apperrors.NewNotFoundError("facility not found")
+}
+
+func (c *FixtureCorpus) GetByIDs(ctx context.Context, ids []string) ([]*entities.Facility, error) {
+	out := make([]*entities.Facility, 0, len(ids))
+	for _, id := range ids {
+		if facility, err := c.GetByID(ctx, id); err == nil {
+			out = append(out, facility)
+		}
+	}
+	return out, nil
+}
+
+func (c *FixtureCorpus) Update(ctx context.Context, facility *entities.Facility) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, f := range c.facilities {
+		if f.ID == facility.ID {
+			f.Facility = *facility
+			return nil
+		}
+	}
+	return apperrors.NewNotFoundError("facility not found")
+}
+
+func (c *FixtureCorpus) Delete(ctx context.Context, id string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, f := range c.facilities {
+		if f.ID == id {
+			c.facilities = append(c.facilities[:i], c.facilities[i+1:]...)
+			return nil
+		}
+	}
+	return apperrors.NewNotFoundError("facility not found")
+}
+
+func (c *FixtureCorpus) List(ctx context.Context, filter repositories.FacilityFilter) ([]*entities.Facility, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var out []*entities.Facility
+	for _, f := range c.facilities {
+		if filter.FacilityType != "" && f.FacilityType != filter.FacilityType {
+			continue
+		}
+		if filter.IsActive != nil && f.IsActive != *filter.IsActive {
+			continue
+		}
+		facility := f.Facility
+		out = append(out, &facility)
+	}
+	return paginate(out, filter.Offset, filter.Limit), nil
+}
+
+func (c *FixtureCorpus) Search(ctx context.Context, params repositories.SearchParams) ([]*entities.Facility, error) {
+	facilities, _, err := c.SearchWithCount(ctx, params)
+	return facilities, err
+}
+
+// SearchWithCount returns the matching page and the total number of matches.
+// With a query embedding, keyword matches are fused with the facilities most
+// similar to it, as Typesense hybrid search does.
+func (c *FixtureCorpus) SearchWithCount(ctx context.Context, params repositories.SearchParams) ([]*entities.Facility, int, error) {
+	query := params.Query
+	if len(params.ExpandedTerms) > 0 {
+		query = strings.Join(params.ExpandedTerms, " ")
+	}
+	queryTokens := uniqueTokens(query)
+
+	allowedTypes := make(map[string]struct{}, len(params.FacilityTypes))
+	for _, t := range params.FacilityTypes {
+		allowedTypes[strings.ToLower(t)] = struct{}{}
+	}
+
+	type scored struct {
+		facility   *FixtureFacility
+		score      int
+		similarity float64
+	}
+
+	c.mu.RLock()
+	var matches, similar []scored
+	for _, f := range c.facilities {
+		if !f.IsActive {
+			continue
+		}
+		if len(allowedTypes) > 0 {
+			if _, ok := allowedTypes[strings.ToLower(f.FacilityType)]; !ok {
+				continue
+			}
+		}
+		if !f.matchesFilters(params) {
+			continue
+		}
+
+		if len(params.QueryEmbedding) > 0 {
+			if similarity := entities.CosineSimilarity(params.QueryEmbedding, f.embedding); similarity > 0 && similarity >= params.MinSimilarity {
+				similar = append(similar, scored{facility: f, similarity: similarity})
+			}
+		}
+
+		score := 0
+		if len(queryTokens) > 0 {
+			score = f.score(queryTokens)
+			if score == 0 {
+				continue
+			}
+		}
+		matches = append(matches, scored{facility: f, score: score})
+	}
+	c.mu.RUnlock()
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score > matches[j].score
+		}
+		if matches[i].facility.Rating != matches[j].facility.Rating {
+			return matches[i].facility.Rating > matches[j].facility.Rating
+		}
+		return matches[i].facility.ID < matches[j].facility.ID
+	})
+
+	limit := params.Limit
+	if limit <= 0 {
+		limit = 20
+	}
+
+	ranked := make([]*FixtureFacility, 0, len(matches))
+	for _, m := range matches {
+		ranked = append(ranked, m.facility)
+	}
+	if len(params.QueryEmbedding) > 0 {
+		sort.SliceStable(similar, func(i, j int) bool {
+			if similar[i].similarity != similar[j].similarity {
+				return similar[i].similarity > similar[j].similarity
+			}
+			return similar[i].facility.ID < similar[j].facility.ID
+		})
+		if candidates := max(params.Offset+limit, minVectorCandidates); len(similar) > candidates {
+			similar = similar[:candidates]
+		}
+		vectorRanked := make([]*FixtureFacility, 0, len(similar))
+		for _, m := range similar {
+			vectorRanked = append(vectorRanked, m.facility)
+		}
+		ranked = fuseRanks(ranked, vectorRanked, params.VectorWeight)
+	}
+
+	sortFixtures(ranked, params)
+
+	out := make([]*entities.Facility, 0, len(ranked))
+	for _, f := range ranked {
+		facility := f.Facility
+		out = append(out, &facility)
+	}
+	return paginate(out, params.Offset, limit), len(out), nil
+}
+
+// matchesFilters applies the search filters the corpus has data for.
+// Fixtures carry no prices, so price bounds match everything.
+func (f *FixtureFacility) matchesFilters(params repositories.SearchParams) bool {
+	if lat, lon, radius, ok := params.GeoFilter(); ok && radius > 0 &&
+		distanceKm(lat, lon, f.Location.Latitude, f.Location.Longitude) > radius {
+		return false
+	}
+	if params.OpenNow && f.CurrentCapacityStatus() == entities.CapacityStatusClosed {
+		return false
+	}
+	if params.Open24Hours && !f.OpenAroundTheClock() {
+		return false
+	}
+	if params.InsuranceProvider != "" {
+		accepted := false
+		for _, name := range f.AcceptedInsurance {
+			if strings.EqualFold(name, params.InsuranceProvider) {
+				accepted = true
+				break
+			}
+		}
+		if !accepted {
+			return false
+		}
+	}
+	return true
+}
+
+// sortFixtures reorders results by rating or distance when asked, keeping
+// relevance order between equals
+func sortFixtures(ranked []*FixtureFacility, params repositories.SearchParams) {
+	switch params.SortBy {
+	case repositories.SearchSortRating:
+		sort.SliceStable(ranked, func(i, j int) bool {
+			return ranked[i].Rating > ranked[j].Rating
+		})
+	case repositories.SearchSortDistance:
+		lat, lon := params.Latitude, params.Longitude
+		if lat == 0 && lon == 0 && params.Area != nil {
+			lat, lon = params.Area.Latitude, params.Area.Longitude
+		}
+		if lat == 0 && lon == 0 {
+			return
+		}
+		sort.SliceStable(ranked, func(i, j int) bool {
+			return distanceKm(lat, lon, ranked[i].Location.Latitude, ranked[i].Location.Longitude) <
+				distanceKm(lat, lon, ranked[j].Location.Latitude, ranked[j].Location.Longitude)
+		})
+	}
+}
+
+// fuseRanks merges keyword and vector rankings by their fused rank score.
+// Ties keep keyword order, then vector order.
+func fuseRanks(keyword, vector []*FixtureFacility, vectorWeight float64) []*FixtureFacility {
+	keywordRank := make(map[*FixtureFacility]int, len(keyword))
+	for i, f := range keyword {
+		keywordRank[f] = i + 1
+	}
+	vectorRank := make(map[*FixtureFacility]int, len(vector))
+	for i, f := range vector {
+		vectorRank[f] = i + 1
+	}
+
+	fused := append([]*FixtureFacility{}, keyword...)
+	for _, f := range vector {
+		if _, ok := keywordRank[f]; !ok {
+			fused = append(fused, f)
+		}
+	}
+	sort.SliceStable(fused, func(i, j int) bool {
+		return entities.HybridRankScore(keywordRank[fused[i]], vectorRank[fused[i]], vectorWeight) >
+			entities.HybridRankScore(keywordRank[fused[j]], vectorRank[fused[j]], vectorWeight)
+	})
+	return fused
+}
+
+// score sums, for each query token, the weight of the best field it appears in.
+func (f *FixtureFacility) score(queryTokens []string) int {
+	name := make(map[string]struct{})
+	for _, t := range tokenize(f.Name) {
+		name[t] = struct{}{}
+	}
+	other := make(map[string]struct{})
+	fields := append([]string{f.FacilityType}, f.Tags...)
+	fields = append(fields, f.Keywords...)
+	for _, field := range fields {
+		for _, t := range tokenize(field) {
+			other[t] = struct{}{}
+		}
+	}
+
+	score := 0
+	for _, t := range queryTokens {
+		if _, ok := name[t]; ok {
+			score += 2
+		} else if _, ok := other[t]; ok {
+			score++
+		}
+	}
+	return score
+}
+
+func tokenize(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func uniqueTokens(s string) []string {
+	seen := make(map[string]struct{})
+	var out []string
+	for _, t := range tokenize(s) {
+		if _, ok := seen[t]; ok {
+			continue
+		}
+		seen[t] = struct{}{}
+		out = append(out, t)
+	}
+	return out
+}
+
+func paginate(facilities []*entities.Facility, offset, limit int) []*entities.Facility {
+	if offset >= len(facilities) {
+		return []*entities.Facility{}
+	}
+	if offset > 0 {
+		facilities = facilities[offset:]
+	}
+	if limit > 0 && limit < len(facilities) {
+		facilities = facilities[:limit]
+	}
+	return facilities
+}
+
+func distanceKm(lat1, lon1, lat2, lon2 float64) float64 {
+	const earthRadiusKm = 6371.0
+	dLat := (lat2 - lat1) * math.Pi / 180
+	dLon := (lon2 - lon1) * math.Pi / 180
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*math.Pi/180)*math.Cos(lat2*math.Pi/180)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return earthRadiusKm * 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
@@ -0,0 +1,165 @@
+package evaluation
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/repositories"
+)
+
+func newTestCorpus() *FixtureCorpus {
+	return NewFixtureCorpus([]*FixtureFacility{
+		{Facility: entities.Facility{ID: "lab", Name: "City Lab", FacilityType: "diagnostic_lab", Tags: []string{"laboratory"}, Rating: 4, IsActive: true,
+			Location: entities.Location{Latitude: 6.45, Longitude: 3.39}}, Keywords: []string{"malaria test", "blood test"}},
+		{Facility: entities.Facility{ID: "hosp", Name: "General Hospital", FacilityType: "hospital", Tags: []string{"emergency"}, Rating: 3, IsActive: true,
+			Location: entities.Location{Latitude: 6.45, Longitude: 3.40}}, Keywords: []string{"malaria", "x-ray"}},
+		{Facility: entities.Facility{ID: "far", Name: "Abuja Malaria Clinic", FacilityType: "clinic", Rating: 5, IsActive: true,
+			Location: entities.Location{Latitude: 9.07, Longitude: 7.49}}},
+		{Facility: entities.Facility{ID: "closed", Name: "Malaria Lab", IsActive: false}},
+	})
+}
+
+func TestFixtureCorpus_SearchScoresAndOrders(t *testing.T) {
+	corpus := newTestCorpus()
+
+	results, total, err := corpus.SearchWithCount(context.Background(), repositories.SearchParams{Query: "malaria test", Limit: 10})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if total != 3 {
+		t.Fatalf("expected 3 matches, got %d", total)
+	}
+	// "far" (one name hit) ties "lab" (two keyword hits); rating breaks the tie.
+	got := []string{results[0].ID, results[1].ID, results[2].ID}
+	want := []string{"far", "lab", "hosp"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected order %v, got %v", want, got)
+		}
+	}
+}
+
+func TestFixtureCorpus_SearchFiltersByRadiusAndType(t *testing.T) {
+	corpus := newTestCorpus()
+
+	results, err := corpus.Search(context.Background(), repositories.SearchParams{
+		Query: "malaria", Latitude: 6.45, Longitude: 3.39, RadiusKm: 50, Limit: 10,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected 2 nearby results, got %d", len(results))
+	}
+
+	results, err = corpus.Search(context.Background(), repositories.SearchParams{
+		Query: "malaria", FacilityTypes: []string{"hospital"}, Limit: 10,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != 1 || results[0].ID != "hosp" {
+		t.Errorf("expected only hospital, got %v", results)
+	}
+}
+
+func TestFixtureCorpus_GetByIDReturnsCopy(t *testing.T) {
+	corpus := newTestCorpus()
+
+	f, err := corpus.GetByID(context.Background(), "lab")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	f.Name = "changed"
+	again, _ := corpus.GetByID(context.Background(), "lab")
+	if again.Name != "City Lab" {
+		t.Error("expected corpus to be unaffected by caller mutation")
+	}
+	if _, err := corpus.GetByID(context.Background(), "missing"); err == nil {
+		t.Error("expected not found error")
+	}
+}
+
+func TestLoadFixtureCorpus_ConfigFile(t *testing.T) {
+	corpus, err := LoadFixtureCorpus("../../config/eval_fixture_corpus.json")
+	if err != nil {
+		t.Fatalf("failed to load fixture corpus: %v", err)
+	}
+	if corpus.Len() < 20 {
+		t.Errorf("expected at least 20 fixture facilities, got %d", corpus.Len())
+	}
+}
+
+func TestLoadFixtureCorpus_DuplicateID(t *testing.T) {
+	path := writeTempFile(t, `{"facilities": [{"id": "a"}, {"id": "a"}]}`)
+	if _, err := LoadFixtureCorpus(path); err == nil {
+		t.Error("expected error for duplicate id")
+	}
+}
+
+// conceptEmbedder embeds texts by which of a few concepts they mention
+type conceptEmbedder struct{}
+
+func (conceptEmbedder) EmbeddingModel() string { return "concepts" }
+
+func (conceptEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	concepts := [][]string{{"malaria", "mosquito"}, {"x-ray", "scan"}}
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vectors[i] = make([]float32, len(concepts))
+		for c, words := range concepts {
+			for _, word := range words {
+				if strings.Contains(strings.ToLower(text), word) {
+					vectors[i][c] = 1
+				}
+			}
+		}
+	}
+	return vectors, nil
+}
+
+func TestFixtureCorpus_HybridSearchFindsParaphrases(t *testing.T) {
+	corpus := newTestCorpus()
+	if err := corpus.EmbedFacilities(context.Background(), conceptEmbedder{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// No facility mentions mosquitoes, so keyword search alone finds nothing
+	_, total, err := corpus.SearchWithCount(context.Background(), repositories.SearchParams{Query: "mosquito bite", Limit: 10})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if total != 0 {
+		t.Fatalf("expected no keyword matches, got %d", total)
+	}
+
+	query, _ := conceptEmbedder{}.Embed(context.Background(), []string{"mosquito bite"})
+	results, total, err := corpus.SearchWithCount(context.Background(), repositories.SearchParams{
+		Query: "mosquito bite", Limit: 10, QueryEmbedding: query[0], VectorWeight: 0.3, MinSimilarity: 0.5,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// The closed facility is filtered out as in keyword search
+	if total != 3 || len(results) != 3 {
+		t.Fatalf("expected the 3 active malaria facilities, got %d", total)
+	}
+
+	// The keyword match comes first, then facilities only similar to the query
+	query, _ = conceptEmbedder{}.Embed(context.Background(), []string{"malaria"})
+	results, _, err = corpus.SearchWithCount(context.Background(), repositories.SearchParams{
+		Query: "laboratory", Limit: 10, QueryEmbedding: query[0], VectorWeight: 0.3, MinSimilarity: 0.5,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got := make([]string, 0, len(results))
+	for _, f := range results {
+		got = append(got, f.ID)
+	}
+	if strings.Join(got, ",") != "lab,far,hosp" {
+		t.Fatalf("expected lab,far,hosp, got %v", got)
+	}
+}
@@ -0,0 +1,90 @@
+package evaluation
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+)
+
+// RunRecord is the headline metrics of a stored evaluation run.
+type RunRecord struct {
+	ID              int64
+	CreatedAt       time.Time
+	Corpus          string
+	GoldenSet       string
+	GitCommit       string
+	EmbeddingModel  string
+	TotalQueries    int
+	AvgRecallAt10   float64
+	AvgMRRAt10      float64
+	AvgLatency      time.Duration
+	QueriesWithHits int
+	FailedQueries   int
+	// Passed is nil for runs that were not gated, such as baseline updates.
+	Passed   *bool
+	Breaches int
+}
+
+// RunStore keeps evaluation runs so their metrics can be compared over time.
+type RunStore interface {
+	// SaveRun stores the report and returns the run's ID.
+	SaveRun(ctx context.Context, report *Report) (int64, error)
+	// ListRuns returns the latest runs against a corpus and golden set, newest first.
+	ListRuns(ctx context.Context, corpus, goldenSet string, limit int) ([]RunRecord, error)
+}
+
+// NewRunRecord extracts the headline metrics of a report.
+func NewRunRecord(report *Report) RunRecord {
+	record := RunRecord{
+		CreatedAt:      report.CreatedAt,
+		Corpus:         report.Corpus,
+		GoldenSet:      report.GoldenSet,
+		GitCommit:      report.GitCommit,
+		EmbeddingModel: report.EmbeddingModel,
+	}
+	if s := report.Summary; s != nil {
+		record.TotalQueries = s.TotalQueries
+		record.AvgRecallAt10 = s.AvgRecallAt10
+		record.AvgMRRAt10 = s.AvgMRRAt10
+		record.AvgLatency = s.AvgLatency
+		record.QueriesWithHits = s.QueriesWithHits
+		record.FailedQueries = s.FailedQueries
+	}
+	if c := report.Comparison; c != nil {
+		passed := c.Passed()
+		record.Passed = &passed
+		record.Breaches = len(c.Breaches)
+	}
+	return record
+}
+
+// WriteHistoryTable renders runs as a plain-text table, with each run's
+// change from the run below it.
+func WriteHistoryTable(w io.Writer, runs []RunRecord) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "RUN\tCREATED\tCOMMIT\tN\tRECALL@10\tΔ\tMRR@10\tΔ\tLATENCY\tGATE")
+	for i, run := range runs {
+		recallDelta, mrrDelta := "-", "-"
+		if i+1 < len(runs) {
+			recallDelta = fmt.Sprintf("%+.4f", run.AvgRecallAt10-runs[i+1].AvgRecallAt10)
+			mrrDelta = fmt.Sprintf("%+.4f", run.AvgMRRAt10-runs[i+1].AvgMRRAt10)
+		}
+		gate := "-"
+		if run.Passed != nil {
+			gate = "pass"
+			if !*run.Passed {
+				gate = fmt.Sprintf("fail (%d)", run.Breaches)
+			}
+		}
+		commit := run.GitCommit
+		if commit == "" {
+			commit = "-"
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%d\t%.4f\t%s\t%.4f\t%s\t%s\t%s\n",
+			run.ID, run.CreatedAt.UTC().Format(time.RFC3339), commit, run.TotalQueries,
+			run.AvgRecallAt10, recallDelta, run.AvgMRRAt10, mrrDelta, run.AvgLatency.Round(time.Millisecond), gate)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,54 @@
+package evaluation
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewRunRecord(t *testing.T) {
+	report := &Report{
+		CreatedAt: time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC),
+		Corpus:    "fixture",
+		GoldenSet: "config/golden_queries.json",
+		GitCommit: "abc1234",
+		Summary:   &EvalSummary{TotalQueries: 40, AvgRecallAt10: 0.8, AvgMRRAt10: 0.6, AvgLatency: 12 * time.Millisecond, QueriesWithHits: 38, FailedQueries: 1},
+	}
+
+	record := NewRunRecord(report)
+	if record.TotalQueries != 40 || record.AvgRecallAt10 != 0.8 || record.AvgMRRAt10 != 0.6 || record.FailedQueries != 1 {
+		t.Fatalf("unexpected metrics: %+v", record)
+	}
+	if record.Passed != nil {
+		t.Fatalf("expected an ungated run without a gate result, got %v", *record.Passed)
+	}
+
+	report.Comparison = &Comparison{Breaches: []Breach{{}, {}}}
+	record = NewRunRecord(report)
+	if record.Passed == nil || *record.Passed || record.Breaches != 2 {
+		t.Fatalf("expected a failed gate with 2 breaches, got %+v", record)
+	}
+}
+
+func TestWriteHistoryTable(t *testing.T) {
+	passed := true
+	runs := []RunRecord{
+		{ID: 2, CreatedAt: time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC), GitCommit: "def5678", TotalQueries: 40, AvgRecallAt10: 0.85, AvgMRRAt10: 0.6, Passed: &passed},
+		{ID: 1, CreatedAt: time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC), TotalQueries: 40, AvgRecallAt10: 0.8, AvgMRRAt10: 0.65},
+	}
+
+	var out strings.Builder
+	if err := WriteHistoryTable(&out, runs); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected a header and 2 rows, got %q", out.String())
+	}
+	if !strings.Contains(lines[1], "+0.0500") || !strings.Contains(lines[1], "-0.0500") || !strings.Contains(lines[1], "pass") {
+		t.Fatalf("expected deltas against the previous run, got %q", lines[1])
+	}
+	if !strings.Contains(lines[2], "2026-03-01T09:00:00Z  -  ") {
+		t.Fatalf("expected the oldest run without a commit or deltas, got %q", lines[2])
+	}
+}
@@ -49,6 +49,9 @@ func ValidateGoldenQueries(queries []GoldenQuery) error {
 		if !validDifficulties[q.Difficulty] {
 			return fmt.Errorf("query %q: invalid difficulty %q (must be easy/medium/hard)", q.ID, q.Difficulty)
 		}
+		if q.Language != "" && !q.Language.IsValid() {
+			return fmt.Errorf("query %q: invalid language %q", q.ID, q.Language)
+		}
 	}
 
 	return nil
@@ -131,6 +131,25 @@ func TestValidateGoldenQueries_DuplicateIDs(t *testing.T) {
 	}
 }
 
+func TestValidateGoldenQueries_InvalidLanguage(t *testing.T) {
+	queries := []GoldenQuery{
+		{ID: "q1", Query: "iba", Intent: IntentCondition, Difficulty: "easy", Language: Language("fr")},
+	}
+	err := ValidateGoldenQueries(queries)
+	if err == nil {
+		t.Error("expected validation error for invalid language")
+	}
+}
+
+func TestGoldenQuery_LanguageOrDefault(t *testing.T) {
+	if got := (GoldenQuery{}).LanguageOrDefault(); got != LanguageEnglish {
+		t.Errorf("expected default English, got %q", got)
+	}
+	if got := (GoldenQuery{Language: LanguagePidgin}).LanguageOrDefault(); got != LanguagePidgin {
+		t.Errorf("expected pcm, got %q", got)
+	}
+}
+
 func TestValidateGoldenQueries_Valid(t *testing.T) {
 	queries := []GoldenQuery{
 		{ID: "q1", Query: "malaria", Intent: IntentCondition, ExpectedTags: []string{"lab"}, Difficulty: "easy"},
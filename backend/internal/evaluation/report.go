@@ -0,0 +1,59 @@
+package evaluation
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Report is a persisted evaluation run, used both as a run log and as a baseline.
+type Report struct {
+	CreatedAt time.Time `json:"created_at"`
+	Corpus    string    `json:"corpus"` // "fixture" or "live"
+	// EmbeddingModel is set when the run used hybrid search
+	EmbeddingModel string       `json:"embedding_model,omitempty"`
+	GoldenSet      string       `json:"golden_set"`
+	GitCommit      string       `json:"git_commit,omitempty"`
+	Summary        *EvalSummary `json:"summary"`
+	Comparison     *Comparison  `json:"comparison,omitempty"`
+}
+
+// SaveReport writes the report to path, creating parent directories as needed.
+func SaveReport(path string, report *Report) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create report directory: %w", err)
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode report: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	return nil
+}
+
+// SaveReportToDir writes the report to a timestamped file in dir and returns its path.
+func SaveReportToDir(dir string, report *Report) (string, error) {
+	name := fmt.Sprintf("eval-%s-%s.json", report.Corpus, report.CreatedAt.UTC().Format("20060102T150405Z"))
+	path := filepath.Join(dir, name)
+	return path, SaveReport(path, report)
+}
+
+// LoadReport reads a report previously written by SaveReport.
+func LoadReport(path string) (*Report, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read report: %w", err)
+	}
+	var report Report
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("failed to parse report: %w", err)
+	}
+	if report.Summary == nil {
+		return nil, fmt.Errorf("report %s has no summary", path)
+	}
+	return &report, nil
+}
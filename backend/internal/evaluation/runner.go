@@ -26,6 +26,9 @@ func (r *Runner) Run(ctx context.Context, queries []GoldenQuery) (*EvalSummary,
 	summary := &EvalSummary{
 		TotalQueries: len(queries),
 		ByIntent:     make(map[Intent]*IntentSummary),
+		ByLanguage:   make(map[Language]*IntentSummary),
+		ByDifficulty: make(map[string]*IntentSummary),
+		Results:      make([]EvalResult, 0, len(queries)),
 	}
 
 	for _, gq := range queries {
@@ -40,6 +43,18 @@ func (r *Runner) Run(ctx context.Context, queries []GoldenQuery) (*EvalSummary,
 		duration := time.Since(start)
 
 		if err != nil {
+			// Failed queries are kept in the per-query results so a baseline
+			// comparison can flag them; they add nothing to the metric sums.
+			summary.FailedQueries++
+			summary.Results = append(summary.Results, EvalResult{
+				QueryID:    gq.ID,
+				Query:      gq.Query,
+				Intent:     gq.Intent,
+				Language:   gq.LanguageOrDefault(),
+				Difficulty: gq.Difficulty,
+				Latency:    duration,
+				Error:      err.Error(),
+			})
 			continue
 		}
 
@@ -60,6 +75,8 @@ func (r *Runner) Run(ctx context.Context, queries []GoldenQuery) (*EvalSummary,
 			QueryID:       gq.ID,
 			Query:         gq.Query,
 			Intent:        gq.Intent,
+			Language:      gq.LanguageOrDefault(),
+			Difficulty:    gq.Difficulty,
 			RecallAt10:    recall,
 			MRRAt10:       mrr,
 			ResultCount:   count,
@@ -67,6 +84,7 @@ func (r *Runner) Run(ctx context.Context, queries []GoldenQuery) (*EvalSummary,
 			Latency:       duration,
 		}
 
+		summary.Results = append(summary.Results, result)
 		r.updateSummary(summary, result)
 	}
 
@@ -138,6 +156,22 @@ func (r *Runner) updateSummary(s *EvalSummary, res EvalResult) {
 	is.Count++
 	is.AvgRecallAt10 += res.RecallAt10
 	is.AvgMRRAt10 += res.MRRAt10
+
+	if _, ok := s.ByLanguage[res.Language]; !ok {
+		s.ByLanguage[res.Language] = &IntentSummary{}
+	}
+	ls := s.ByLanguage[res.Language]
+	ls.Count++
+	ls.AvgRecallAt10 += res.RecallAt10
+	ls.AvgMRRAt10 += res.MRRAt10
+
+	if _, ok := s.ByDifficulty[res.Difficulty]; !ok {
+		s.ByDifficulty[res.Difficulty] = &IntentSummary{}
+	}
+	ds := s.ByDifficulty[res.Difficulty]
+	ds.Count++
+	ds.AvgRecallAt10 += res.RecallAt10
+	ds.AvgMRRAt10 += res.MRRAt10
 }
 
 func (r *Runner) finalizeSummary(s *EvalSummary) {
@@ -155,4 +189,20 @@ func (r *Runner) finalizeSummary(s *EvalSummary) {
 			is.AvgMRRAt10 /= n
 		}
 	}
+
+	for _, ls := range s.ByLanguage {
+		if ls.Count > 0 {
+			n := float64(ls.Count)
+			ls.AvgRecallAt10 /= n
+			ls.AvgMRRAt10 /= n
+		}
+	}
+
+	for _, ds := range s.ByDifficulty {
+		if ds.Count > 0 {
+			n := float64(ds.Count)
+			ds.AvgRecallAt10 /= n
+			ds.AvgMRRAt10 /= n
+		}
+	}
 }
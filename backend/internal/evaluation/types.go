@@ -26,6 +26,31 @@ func (i Intent) IsValid() bool {
 	return false
 }
 
+// Language identifies the language a query was written in.
+type Language string
+
+const (
+	LanguageEnglish Language = "en"
+	LanguageYoruba  Language = "yo"
+	LanguageHausa   Language = "ha"
+	LanguageIgbo    Language = "ig"
+	LanguagePidgin  Language = "pcm" // Nigerian Pidgin
+)
+
+// ValidLanguages returns all supported query languages.
+func ValidLanguages() []Language {
+	return []Language{LanguageEnglish, LanguageYoruba, LanguageHausa, LanguageIgbo, LanguagePidgin}
+}
+
+// IsValid checks if the language value is one of the defined constants.
+func (l Language) IsValid() bool {
+	switch l {
+	case LanguageEnglish, LanguageYoruba, LanguageHausa, LanguageIgbo, LanguagePidgin:
+		return true
+	}
+	return false
+}
+
 // GoldenQuery represents a labeled test query with expected outcomes.
 type GoldenQuery struct {
 	ID               string   `json:"id"`
@@ -33,7 +58,16 @@ type GoldenQuery struct {
 	Intent           Intent   `json:"intent"`
 	ExpectedTags     []string `json:"expected_tags"`
 	ExpectedFacTypes []string `json:"expected_facility_types"`
-	Difficulty       string   `json:"difficulty"` // easy, medium, hard
+	Difficulty       string   `json:"difficulty"`         // easy, medium, hard
+	Language         Language `json:"language,omitempty"` // defaults to English
+}
+
+// LanguageOrDefault returns the query language, treating an empty value as English.
+func (q GoldenQuery) LanguageOrDefault() Language {
+	if q.Language == "" {
+		return LanguageEnglish
+	}
+	return q.Language
 }
 
 // EvalResult holds the evaluation outcome for a single query.
@@ -41,11 +75,14 @@ type EvalResult struct {
 	QueryID       string
 	Query         string
 	Intent        Intent
+	Language      Language
+	Difficulty    string
 	RecallAt10    float64
 	MRRAt10       float64
 	ResultCount   int
 	RetrievedTags []string
 	Latency       time.Duration
+	Error         string `json:",omitempty"` // search failure; metrics are zero
 }
 
 // EvalSummary holds aggregate metrics across all golden queries.
@@ -55,10 +92,14 @@ type EvalSummary struct {
 	AvgMRRAt10      float64
 	AvgLatency      time.Duration
 	QueriesWithHits int // queries that returned at least 1 result
+	FailedQueries   int // queries whose search returned an error
 	ByIntent        map[Intent]*IntentSummary
+	ByLanguage      map[Language]*IntentSummary
+	ByDifficulty    map[string]*IntentSummary
+	Results         []EvalResult
 }
 
-// IntentSummary holds metrics grouped by intent type.
+// IntentSummary holds metrics for a group of queries sharing an intent, language or difficulty.
 type IntentSummary struct {
 	Count         int
 	AvgRecallAt10 float64
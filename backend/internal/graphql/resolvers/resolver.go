@@ -1,8 +1,20 @@
 package resolvers
 
 import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	appservices "github.com/zatekoja/Patientpricediscoverydesign/backend/internal/application/services"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/providers"
 	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/repositories"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/graphql/generated"
 	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/infrastructure/clients/providerapi"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/infrastructure/observability"
 	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/query/services"
 )
 
@@ -12,7 +24,6 @@ import (
 // here.
 
 type Resolver struct {
-	searchAdapter         services.SearchAdapter
 	facilityRepo          repositories.FacilityRepository
 	appointmentRepo       repositories.AppointmentRepository
 	procedureRepo         repositories.ProcedureRepository
@@ -20,6 +31,11 @@ type Resolver struct {
 	insuranceRepo         repositories.InsuranceRepository
 	cache                 services.QueryCacheProvider
 	providerClient        providerapi.Client
+	searchAnalytics       repositories.SearchAnalyticsRepository
+	reviewRepo            repositories.ReviewRepository
+	// facilities runs facility searches as the REST API does, with travel
+	// times
+	facilities *appservices.FacilityService
 }
 
 // NewResolver creates a new resolver with dependencies
@@ -34,7 +50,6 @@ func NewResolver(
 	providerClient providerapi.Client,
 ) *Resolver {
 	return &Resolver{
-		searchAdapter:         searchAdapter,
 		facilityRepo:          facilityRepo,
 		appointmentRepo:       appointmentRepo,
 		procedureRepo:         procedureRepo,
@@ -42,5 +57,90 @@ func NewResolver(
 		insuranceRepo:         insuranceRepo,
 		cache:                 cache,
 		providerClient:        providerClient,
+		facilities:            appservices.NewFacilityService(facilityRepo, searchAdapter, facilityProcedureRepo, procedureRepo, insuranceRepo),
+	}
+}
+
+// SetSearchAnalytics enables logging of search impressions for click and conversion tracking.
+func (r *Resolver) SetSearchAnalytics(repo repositories.SearchAnalyticsRepository) {
+	r.searchAnalytics = repo
+}
+
+// SetReviewRepository enables the published reviews field on facilities.
+func (r *Resolver) SetReviewRepository(repo repositories.ReviewRepository) {
+	r.reviewRepo = repo
+}
+
+// SetRoutingProvider enables travel times on search results and the travel time sort.
+func (r *Resolver) SetRoutingProvider(routing providers.RoutingProvider) {
+	r.facilities.SetRoutingProvider(routing)
+}
+
+// newSearchImpression assigns an impression ID to a search response and, when
+// analytics is configured, logs it in the background so interactions reported
+// against the ID can be attributed to this search.
+func (r *Resolver) newSearchImpression(ctx context.Context, params repositories.SearchParams, facilities []*entities.Facility, totalCount int, latency time.Duration) string {
+	impressionID := uuid.New().String()
+	if r.searchAnalytics == nil || params.Query == "" {
+		return impressionID
+	}
+
+	event := &entities.SearchEvent{
+		ID:            impressionID,
+		Query:         params.Query,
+		ResultCount:   totalCount,
+		LatencyMs:     int(latency.Milliseconds()),
+		UserLatitude:  params.Latitude,
+		UserLongitude: params.Longitude,
+		ResultOffset:  params.Offset,
+	}
+	event.ResultFacilityIDs = make([]string, 0, len(facilities))
+	for _, f := range facilities {
+		if f != nil {
+			event.ResultFacilityIDs = append(event.ResultFacilityIDs, f.ID)
+		}
+	}
+
+	// Detached from the request so the write outlives the response, but bounded
+	go func() {
+		logCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+		defer cancel()
+		if err := r.searchAnalytics.LogEvent(logCtx, event); err != nil {
+			observability.LoggerFromContext(logCtx).Warn().Err(err).Str("impression_id", impressionID).Msg("failed to log search impression")
+		}
+	}()
+	return impressionID
+}
+
+// searchFacilities runs a facility search the way the REST API does, with
+// the travel time to each facility found
+func (r *Resolver) searchFacilities(ctx context.Context, params repositories.SearchParams) ([]*entities.Facility, int, []entities.FacilityTravelTime, error) {
+	facilities, totalCount, routes, err := r.facilities.SearchWithRoutes(ctx, params)
+	if err != nil {
+		return nil, 0, nil, fmt.Errorf("search failed: %w", err)
+	}
+
+	travelTimes := []entities.FacilityTravelTime{}
+	for _, facility := range facilities {
+		if route, ok := routes[facility.ID]; ok {
+			travelTimes = append(travelTimes, entities.FacilityTravelTime{
+				FacilityID:     facility.ID,
+				TravelMinutes:  int(math.Ceil(route.DurationMinutes)),
+				RoadDistanceKm: math.Round(route.DistanceKm*10) / 10,
+			})
+		}
+	}
+	return facilities, totalCount, travelTimes, nil
+}
+
+// searchSortBy maps a GraphQL sort field to the search sort order, leaving
+// fields search cannot sort by to relevance
+func searchSortBy(field *generated.FacilitySortField) string {
+	if field == nil {
+		return ""
+	}
+	if sortBy := strings.ToLower(string(*field)); repositories.IsValidSearchSort(sortBy) {
+		return sortBy
 	}
+	return ""
 }
@@ -225,6 +225,60 @@ func (r *facilityResolver) Procedures(ctx context.Context, obj *entities.Facilit
 	}, nil
 }
 
+// Reviews is the resolver for the reviews field.
+func (r *facilityResolver) Reviews(ctx context.Context, obj *entities.Facility, limit *int, offset *int) (*generated.ReviewConnection, error) {
+	start, size := 0, 10
+	if offset != nil && *offset > 0 {
+		start = *offset
+	}
+	if limit != nil && *limit > 0 {
+		size = *limit
+	}
+	if size > 50 {
+		size = 50
+	}
+
+	connection := &generated.ReviewConnection{
+		Nodes:      []*generated.FacilityReview{},
+		PageInfo:   &generated.PageInfo{HasPreviousPage: start > 0},
+		TotalCount: obj.ReviewCount,
+	}
+	if r.reviewRepo == nil {
+		return connection, nil
+	}
+
+	reviews, err := r.reviewRepo.ListByFacility(ctx, obj.ID, size, start)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch facility reviews: %w", err)
+	}
+
+	for _, review := range reviews {
+		node := &generated.FacilityReview{
+			ID:           review.ID,
+			ReviewerName: review.ReviewerName,
+			Rating:       review.Rating,
+			CreatedAt:    review.CreatedAt.Format(time.RFC3339),
+		}
+		if review.Comment != "" {
+			comment := review.Comment
+			node.Comment = &comment
+		}
+		if review.FacilityResponse != "" {
+			response := review.FacilityResponse
+			node.FacilityResponse = &response
+		}
+		if review.RespondedAt != nil {
+			respondedAt := review.RespondedAt.Format(time.RFC3339)
+			node.RespondedAt = &respondedAt
+		}
+		connection.Nodes = append(connection.Nodes, node)
+	}
+	// review_count is recomputed with every review write, so it is the published total.
+	connection.PageInfo.HasNextPage = start+len(reviews) < obj.ReviewCount
+
+	return connection, nil
+}
+
 // AvailableServices is the resolver for the availableServices field.
 func (r *facilityResolver) AvailableServices(ctx context.Context, obj *entities.Facility, filter *generated.ServiceFilter, limit *int, offset *int, sortBy *generated.ServiceSortField, sortOrder *generated.SortOrder) (*generated.ServiceConnection, error) {
 	repoFilter := repositories.FacilityProcedureFilter{
@@ -457,22 +511,13 @@ func (r *queryResolver) Facilities(ctx context.Context, filter generated.Facilit
 	if filter.Offset != nil {
 		params.Offset = *filter.Offset
 	}
+	params.SortBy = searchSortBy(filter.SortBy)
 
 	// Execute search
-	facilities, err := r.searchAdapter.Search(ctx, params)
+	start := time.Now()
+	facilities, totalCount, travelTimes, err := r.searchFacilities(ctx, params)
 	if err != nil {
-		return nil, fmt.Errorf("search failed: %w", err)
-	}
-
-	totalCount := len(facilities)
-	if searchAdapterWithCount, ok := r.searchAdapter.(interface {
-		SearchWithCount(ctx context.Context, params repositories.SearchParams) ([]*entities.Facility, int, error)
-	}); ok {
-		var countErr error
-		facilities, totalCount, countErr = searchAdapterWithCount.SearchWithCount(ctx, params)
-		if countErr != nil {
-			return nil, fmt.Errorf("search with count failed: %w", countErr)
-		}
+		return nil, err
 	}
 
 	// Calculate pagination info
@@ -484,11 +529,15 @@ func (r *queryResolver) Facilities(ctx context.Context, filter generated.Facilit
 		hasNextPage = true
 	}
 
+	searchTime := time.Since(start)
+
 	// Build result
 	result := &entities.GraphQLFacilitySearchResult{
 		FacilitiesData:  facilities,
 		TotalCountValue: totalCount,
-		SearchTimeMs:    0, // TODO: track actual search time
+		SearchTimeMs:    float64(searchTime.Microseconds()) / 1000,
+		ImpressionID:    r.newSearchImpression(ctx, params, facilities, totalCount, searchTime),
+		TravelTimes:     travelTimes,
 		// TODO: Implement facets
 		FacetsData: &entities.SearchFacets{
 			FacilityTypes:      []entities.FacetCount{},
@@ -538,24 +587,15 @@ func (r *queryResolver) SearchFacilities(ctx context.Context, query string, loca
 		if filters.Offset != nil {
 			params.Offset = *filters.Offset
 		}
+		params.SortBy = searchSortBy(filters.SortBy)
 		// Additional filter criteria could be applied here
 	}
 
 	// Execute search
-	facilities, err := r.searchAdapter.Search(ctx, params)
+	start := time.Now()
+	facilities, totalCount, travelTimes, err := r.searchFacilities(ctx, params)
 	if err != nil {
-		return nil, fmt.Errorf("search failed: %w", err)
-	}
-
-	totalCount := len(facilities)
-	if searchAdapterWithCount, ok := r.searchAdapter.(interface {
-		SearchWithCount(ctx context.Context, params repositories.SearchParams) ([]*entities.Facility, int, error)
-	}); ok {
-		var countErr error
-		facilities, totalCount, countErr = searchAdapterWithCount.SearchWithCount(ctx, params)
-		if countErr != nil {
-			return nil, fmt.Errorf("search with count failed: %w", countErr)
-		}
+		return nil, err
 	}
 
 	// Calculate pagination info
@@ -567,11 +607,15 @@ func (r *queryResolver) SearchFacilities(ctx context.Context, query string, loca
 		hasNextPage = true
 	}
 
+	searchTime := time.Since(start)
+
 	// Build result
 	result := &entities.GraphQLFacilitySearchResult{
 		FacilitiesData:  facilities,
 		TotalCountValue: totalCount,
-		SearchTimeMs:    0, // TODO: track actual search time
+		SearchTimeMs:    float64(searchTime.Microseconds()) / 1000,
+		ImpressionID:    r.newSearchImpression(ctx, params, facilities, totalCount, searchTime),
+		TravelTimes:     travelTimes,
 		FacetsData: &entities.SearchFacets{
 			FacilityTypes:      []entities.FacetCount{},
 			InsuranceProviders: []entities.FacetCount{},
@@ -613,7 +657,7 @@ func (r *queryResolver) FacilitySuggestions(ctx context.Context, query string, l
 	}
 
 	// Execute search
-	facilities, err := r.searchAdapter.Search(ctx, params)
+	facilities, err := r.facilities.Search(ctx, params)
 	if err != nil {
 		return nil, fmt.Errorf("suggestions search failed: %w", err)
 	}
@@ -18,15 +18,19 @@ import (
 	"go.opentelemetry.io/otel/metric"
 )
 
-const defaultBaseURL = "https://api.openai.com/v1"
+const (
+	defaultBaseURL        = "https://api.openai.com/v1"
+	defaultEmbeddingModel = "text-embedding-3-small"
+)
 
-// Client implements the OpenAI procedure enrichment provider.
+// Client implements the OpenAI procedure enrichment and embedding providers.
 type Client struct {
-	apiKey     string
-	model      string
-	baseURL    string
-	httpClient *http.Client
-	limiter    *tokenBucket
+	apiKey         string
+	model          string
+	embeddingModel string
+	baseURL        string
+	httpClient     *http.Client
+	limiter        *tokenBucket
 }
 
 // NewClient creates a new OpenAI client.
@@ -40,12 +44,18 @@ func NewClient(cfg *config.OpenAIConfig) (*Client, error) {
 		model = "gpt-4o-mini"
 	}
 
+	embeddingModel := cfg.EmbeddingModel
+	if embeddingModel == "" {
+		embeddingModel = defaultEmbeddingModel
+	}
+
 	limiter := newTokenBucket(cfg.RateLimitRPM, cfg.RateLimitBurst)
 
 	return &Client{
-		apiKey:  cfg.APIKey,
-		model:   model,
-		baseURL: defaultBaseURL,
+		apiKey:         cfg.APIKey,
+		model:          model,
+		embeddingModel: embeddingModel,
+		baseURL:        defaultBaseURL,
 		httpClient: &http.Client{
 			Timeout: 20 * time.Second,
 		},
@@ -0,0 +1,110 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/providers"
+)
+
+var _ providers.BulkEmbeddingProvider = (*Client)(nil)
+
+type embeddingRequest struct {
+	Model      string   `json:"model"`
+	Input      []string `json:"input"`
+	Dimensions int      `json:"dimensions"`
+}
+
+type embeddingResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float32 `json:"embedding"`
+	} `json:"data"`
+}
+
+// EmbeddingModel returns the model embeddings are requested from
+func (c *Client) EmbeddingModel() string {
+	return c.embeddingModel
+}
+
+// Embed returns an embedding of entities.EmbeddingDimensions for each text.
+// It serves interactive searches, so unlike enrichment it does not wait on
+// the rate limiter; bulk callers use EmbedBulk.
+func (c *Client) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	if len(texts) == 0 {
+		return [][]float32{}, nil
+	}
+
+	body, err := json.Marshal(embeddingRequest{
+		Model:      c.embeddingModel,
+		Input:      texts,
+		Dimensions: entities.EmbeddingDimensions,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/embeddings", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	start := time.Now()
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		recordOpenAIMetric(ctx, c.embeddingModel, 0, time.Since(start), err)
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		err := fmt.Errorf("openai embedding request failed with status %d", resp.StatusCode)
+		recordOpenAIMetric(ctx, c.embeddingModel, resp.StatusCode, time.Since(start), err)
+		return nil, err
+	}
+
+	var parsed embeddingResponse
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		recordOpenAIMetric(ctx, c.embeddingModel, resp.StatusCode, time.Since(start), err)
+		return nil, fmt.Errorf("failed to parse openai embedding response: %w", err)
+	}
+
+	vectors := make([][]float32, len(texts))
+	for _, item := range parsed.Data {
+		if item.Index < 0 || item.Index >= len(texts) {
+			continue
+		}
+		vectors[item.Index] = item.Embedding
+	}
+	for i, vector := range vectors {
+		if len(vector) != entities.EmbeddingDimensions {
+			err := fmt.Errorf("openai returned %d dimensions for input %d, expected %d", len(vector), i, entities.EmbeddingDimensions)
+			recordOpenAIMetric(ctx, c.embeddingModel, resp.StatusCode, time.Since(start), err)
+			return nil, err
+		}
+	}
+
+	recordOpenAIMetric(ctx, c.embeddingModel, resp.StatusCode, time.Since(start), nil)
+	return vectors, nil
+}
+
+// EmbedBulk embeds texts in one request once the rate limiter allows it, so
+// reindexing shares the request budget with enrichment
+func (c *Client) EmbedBulk(ctx context.Context, texts []string) ([][]float32, error) {
+	if c.limiter != nil && len(texts) > 0 {
+		waitStart := time.Now()
+		if err := c.limiter.Wait(ctx); err != nil {
+			recordOpenAIMetric(ctx, c.embeddingModel, 0, 0, err)
+			return nil, err
+		}
+		recordOpenAIRateLimitWait(ctx, c.embeddingModel, time.Since(waitStart))
+	}
+	return c.Embed(ctx, texts)
+}
@@ -0,0 +1,110 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/pkg/config"
+)
+
+func TestEmbed_OrdersVectorsByIndex(t *testing.T) {
+	var request embeddingRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/embeddings" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		first := make([]float32, entities.EmbeddingDimensions)
+		second := make([]float32, entities.EmbeddingDimensions)
+		first[0], second[1] = 1, 1
+		// Returned out of order; the index says which input each belongs to
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": []map[string]interface{}{
+				{"index": 1, "embedding": second},
+				{"index": 0, "embedding": first},
+			},
+		})
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&config.OpenAIConfig{APIKey: "test-key"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	client.baseURL = server.URL
+
+	vectors, err := client.Embed(context.Background(), []string{"pain when I pee", "urinary tract infection"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if request.Model != defaultEmbeddingModel || request.Dimensions != entities.EmbeddingDimensions || len(request.Input) != 2 {
+		t.Fatalf("unexpected request %+v", request)
+	}
+	if len(vectors) != 2 || vectors[0][0] != 1 || vectors[1][1] != 1 {
+		t.Fatalf("expected vectors in input order, got %v", vectors)
+	}
+}
+
+func TestEmbed_RejectsWrongDimensions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": []map[string]interface{}{{"index": 0, "embedding": []float32{0.1, 0.2}}},
+		})
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&config.OpenAIConfig{APIKey: "test-key"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	client.baseURL = server.URL
+
+	if _, err := client.Embed(context.Background(), []string{"malaria"}); err == nil {
+		t.Fatal("expected an error for a vector of the wrong length")
+	}
+}
+
+func TestEmbedBulk_WaitsForTheRateLimiter(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		vector := make([]float32, entities.EmbeddingDimensions)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": []map[string]interface{}{{"index": 0, "embedding": vector}},
+		})
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&config.OpenAIConfig{APIKey: "test-key", RateLimitRPM: 1, RateLimitBurst: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	client.baseURL = server.URL
+
+	if _, err := client.EmbedBulk(context.Background(), []string{"malaria"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The burst is spent and the next token is a minute away
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := client.EmbedBulk(ctx, []string{"typhoid"}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the second bulk request to wait on the limiter, got %v", err)
+	}
+	if requests != 1 {
+		t.Fatalf("expected 1 request to reach the API, got %d", requests)
+	}
+
+	// Interactive embedding is not metered
+	if _, err := client.Embed(context.Background(), []string{"typhoid"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -69,6 +69,46 @@ func (c *Client) BeginTx(ctx context.Context) (*sql.Tx, error) {
 	return c.db.BeginTx(ctx, nil)
 }
 
+// Querier runs statements on the pool or inside a transaction
+type Querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+type txKey struct{}
+
+// Conn returns the transaction InTx attached to ctx, or the pool outside one,
+// so adapters join a caller's transaction without knowing about it
+func (c *Client) Conn(ctx context.Context) Querier {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return tx
+	}
+	return c.db
+}
+
+// InTx runs fn in a transaction attached to the ctx it is given, committing
+// when fn succeeds. Called inside another InTx, fn joins the outer transaction
+// and the outermost caller commits.
+func (c *Client) InTx(ctx context.Context, fn func(ctx context.Context, tx *sql.Tx) error) error {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return fn(ctx, tx)
+	}
+
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	if err := fn(context.WithValue(ctx, txKey{}, tx), tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
 // Ping verifies the connection to the database
 func (c *Client) Ping(ctx context.Context) error {
 	return c.db.PingContext(ctx)
@@ -1,9 +1,16 @@
 package typesense
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/typesense/typesense-go/v2/typesense"
@@ -14,7 +21,26 @@ import (
 )
 
 const (
+	// FacilitiesCollection is the alias searches use; it points at the live
+	// facilities_v{n} collection
 	FacilitiesCollection = "facilities"
+
+	// ProceduresCollection is the alias procedure searches use; it points at
+	// the live procedures_v{n} collection
+	ProceduresCollection = "procedures"
+)
+
+// versionedCollection is searched through an alias pointing at one of its
+// {alias}_v{n} collections, so a rebuild can be swapped in whole
+type versionedCollection struct {
+	alias  string
+	prefix string
+	schema func(name string) *api.CollectionSchema
+}
+
+var (
+	facilities = versionedCollection{alias: FacilitiesCollection, prefix: facilitiesCollectionPrefix, schema: FacilitiesSchema}
+	procedures = versionedCollection{alias: ProceduresCollection, prefix: proceduresCollectionPrefix, schema: ProceduresSchema}
 )
 
 // Client represents a Typesense client
@@ -60,115 +86,386 @@ func (c *Client) Client() *typesense.Client {
 	return c.client
 }
 
-// InitSchema ensures the facilities collection exists
+// InitSchema ensures the facilities alias exists, creating an empty first
+// collection behind it on a new cluster, and the procedures collection, and
+// logs any drift between the live collections and the schemas in code. A
+// legacy facilities collection without an alias keeps serving until the
+// indexer replaces it.
 func (c *Client) InitSchema(ctx context.Context) error {
-	collections, err := c.client.Collections().Retrieve(ctx)
+	drift, err := c.EnsureProceduresCollection(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to retrieve collections: %w", err)
+		return err
+	}
+	if len(drift) > 0 {
+		log.Printf("Typesense collection '%s' differs from its schema; run the indexer to rebuild it: %s",
+			ProceduresCollection, strings.Join(drift, "; "))
 	}
 
-	for _, col := range collections {
-		if col.Name == FacilitiesCollection {
-			log.Println("Typesense collection 'facilities' already exists")
-			return nil
+	live, aliased, err := c.LiveCollection(ctx)
+	if err != nil {
+		return err
+	}
+	if live != "" {
+		if !aliased {
+			log.Printf("Typesense collection '%s' is not versioned; run the indexer to move it behind an alias", FacilitiesCollection)
 		}
+		drift, err := c.SchemaDrift(ctx, live)
+		if err != nil {
+			return err
+		}
+		if len(drift) > 0 {
+			log.Printf("Typesense collection '%s' differs from schema version %d; run the indexer to rebuild it: %s",
+				live, FacilitiesSchemaVersion, strings.Join(drift, "; "))
+		}
+		return nil
 	}
 
-	schema := &api.CollectionSchema{
-		Name: FacilitiesCollection,
-		Fields: []api.Field{
-			{
-				Name: "id",
-				Type: "string",
-			},
-			{
-				Name: "name",
-				Type: "string",
-			},
-			{
-				Name:  "facility_type",
-				Type:  "string",
-				Facet: pointer.True(),
-			},
-			{
-				Name: "location",
-				Type: "geopoint",
-			},
-			{
-				Name:     "price",
-				Type:     "float",
-				Facet:    pointer.True(),
-				Optional: pointer.True(),
-			},
-			{
-				Name:  "rating",
-				Type:  "float",
-				Facet: pointer.True(),
-			},
-			{
-				Name: "review_count",
-				Type: "int32",
-			},
-			{
-				Name: "created_at",
-				Type: "int64",
-			},
-			{
-				Name: "is_active",
-				Type: "bool",
-			},
-			{
-				Name:     "insurance",
-				Type:     "string[]",
-				Facet:    pointer.True(),
-				Optional: pointer.True(),
-			},
-			{
-				Name:     "procedures",
-				Type:     "string[]",
-				Optional: pointer.True(),
-			},
-			{
-				Name:     "tags",
-				Type:     "string[]",
-				Optional: pointer.True(),
-			},
-			{
-				Name:     "concepts",
-				Type:     "string[]",
-				Optional: pointer.True(),
-			},
-			{
-				Name:     "conditions",
-				Type:     "string[]",
-				Optional: pointer.True(),
-			},
-			{
-				Name:     "symptoms",
-				Type:     "string[]",
-				Optional: pointer.True(),
-			},
-			{
-				Name:     "specialties",
-				Type:     "string[]",
-				Facet:    pointer.True(),
-				Optional: pointer.True(),
-			},
-		},
-		DefaultSortingField: pointer.String("created_at"),
+	name, err := c.CreateVersionedCollection(ctx)
+	if err != nil {
+		return err
+	}
+	if _, err := c.SwapAlias(ctx, name); err != nil {
+		return err
+	}
+	log.Printf("Created Typesense collection '%s' behind alias '%s'", name, FacilitiesCollection)
+	return nil
+}
+
+// LiveCollection returns the collection searches are served from and whether
+// it is behind the facilities alias, or "" when there is none yet
+func (c *Client) LiveCollection(ctx context.Context) (string, bool, error) {
+	return c.liveCollection(ctx, facilities)
+}
+
+// SchemaDrift compares a live collection with the schema in code
+func (c *Client) SchemaDrift(ctx context.Context, collection string) ([]string, error) {
+	return c.schemaDrift(ctx, facilities, collection)
+}
+
+// CreateVersionedCollection creates the next facilities_v{n} collection with
+// the schema in code and returns its name
+func (c *Client) CreateVersionedCollection(ctx context.Context) (string, error) {
+	return c.createVersionedCollection(ctx, facilities)
+}
+
+func (c *Client) liveCollection(ctx context.Context, vc versionedCollection) (string, bool, error) {
+	alias, err := c.client.Alias(vc.alias).Retrieve(ctx)
+	if err == nil {
+		return alias.CollectionName, true, nil
+	}
+	if !isNotFound(err) {
+		return "", false, fmt.Errorf("failed to retrieve alias: %w", err)
+	}
+
+	_, err = c.client.Collection(vc.alias).Retrieve(ctx)
+	if err == nil {
+		return vc.alias, false, nil
+	}
+	if !isNotFound(err) {
+		return "", false, fmt.Errorf("failed to retrieve collection: %w", err)
+	}
+	return "", false, nil
+}
+
+func (c *Client) schemaDrift(ctx context.Context, vc versionedCollection, collection string) ([]string, error) {
+	live, err := c.client.Collection(collection).Retrieve(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve collection %s: %w", collection, err)
+	}
+	return SchemaDrift(vc.schema(collection), live), nil
+}
+
+func (c *Client) createVersionedCollection(ctx context.Context, vc versionedCollection) (string, error) {
+	names, err := c.collectionNames(ctx)
+	if err != nil {
+		return "", err
+	}
+	next := 1
+	for _, name := range names {
+		if n, ok := parseVersion(vc.prefix, name); ok && n >= next {
+			next = n + 1
+		}
 	}
 
-	_, err = c.client.Collections().Create(ctx, schema)
+	name := vc.prefix + strconv.Itoa(next)
+	if _, err := c.client.Collections().Create(ctx, vc.schema(name)); err != nil {
+		return "", fmt.Errorf("failed to create collection %s: %w", name, err)
+	}
+	return name, nil
+}
+
+// VerifyCollection checks a freshly built collection before it goes live: it
+// must hold exactly the expected number of documents, and searching for each
+// sample facility's name must find it. samples maps facility IDs to names.
+func (c *Client) VerifyCollection(ctx context.Context, collection string, expectedDocuments int, samples map[string]string) error {
+	count, err := c.DocumentCount(ctx, collection)
 	if err != nil {
-		return fmt.Errorf("failed to create collection: %w", err)
+		return err
+	}
+	if count != int64(expectedDocuments) {
+		return fmt.Errorf("collection %s has %d documents, expected %d", collection, count, expectedDocuments)
 	}
 
-	log.Println("Created Typesense collection 'facilities'")
+	for id, name := range samples {
+		result, err := c.client.Collection(collection).Documents().Search(ctx, &api.SearchCollectionParams{
+			Q:        pointer.String(name),
+			QueryBy:  pointer.String("name"),
+			FilterBy: pointer.String("id:=" + id),
+			PerPage:  pointer.Int(1),
+		})
+		if err != nil {
+			return fmt.Errorf("smoke query for %q failed: %w", name, err)
+		}
+		if result.Found == nil || *result.Found == 0 {
+			return fmt.Errorf("smoke query for %q did not find facility %s", name, id)
+		}
+	}
 	return nil
 }
 
-// IndexFacility indexes a facility document
+// DocumentCount returns the number of documents in a collection
+func (c *Client) DocumentCount(ctx context.Context, collection string) (int64, error) {
+	info, err := c.client.Collection(collection).Retrieve(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to retrieve collection %s: %w", collection, err)
+	}
+	if info.NumDocuments == nil {
+		return 0, nil
+	}
+	return *info.NumDocuments, nil
+}
+
+// EmbeddingModels returns the models the embeddings in a collection came
+// from, most common first
+func (c *Client) EmbeddingModels(ctx context.Context, collection string) ([]string, error) {
+	result, err := c.client.Collection(collection).Documents().Search(ctx, &api.SearchCollectionParams{
+		Q:              pointer.String("*"),
+		FacetBy:        pointer.String(EmbeddingModelField),
+		MaxFacetValues: pointer.Int(10),
+		PerPage:        pointer.Int(0),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to facet %s on %s: %w", collection, EmbeddingModelField, err)
+	}
+
+	models := []string{}
+	if result.FacetCounts == nil {
+		return models, nil
+	}
+	for _, facet := range *result.FacetCounts {
+		if facet.FieldName == nil || *facet.FieldName != EmbeddingModelField || facet.Counts == nil {
+			continue
+		}
+		for _, count := range *facet.Counts {
+			if count.Value != nil && *count.Value != "" {
+				models = append(models, *count.Value)
+			}
+		}
+	}
+	return models, nil
+}
+
+// SwapAlias points the facilities alias at collection and returns the
+// collection it pointed at before. A legacy facilities collection has to be
+// dropped first because an alias cannot share its name; searches fail for
+// the moment in between, once, during that migration.
+func (c *Client) SwapAlias(ctx context.Context, collection string) (string, error) {
+	return c.swapAlias(ctx, facilities, collection)
+}
+
+// DropCollection deletes a collection
+func (c *Client) DropCollection(ctx context.Context, collection string) error {
+	if _, err := c.client.Collection(collection).Delete(ctx); err != nil && !isNotFound(err) {
+		return fmt.Errorf("failed to drop collection %s: %w", collection, err)
+	}
+	return nil
+}
+
+// DropStaleCollections deletes versioned collections other than the live one
+// and the newest keep older ones, and returns their names
+func (c *Client) DropStaleCollections(ctx context.Context, keep int) ([]string, error) {
+	return c.dropStaleCollections(ctx, facilities, keep)
+}
+
+func (c *Client) swapAlias(ctx context.Context, vc versionedCollection, collection string) (string, error) {
+	previous, aliased, err := c.liveCollection(ctx, vc)
+	if err != nil {
+		return "", err
+	}
+	if previous != "" && !aliased {
+		if _, err := c.client.Collection(vc.alias).Delete(ctx); err != nil {
+			return "", fmt.Errorf("failed to drop legacy collection: %w", err)
+		}
+		previous = ""
+	}
+
+	_, err = c.client.Aliases().Upsert(ctx, vc.alias, &api.CollectionAliasSchema{CollectionName: collection})
+	if err != nil {
+		return "", fmt.Errorf("failed to point alias at %s: %w", collection, err)
+	}
+	return previous, nil
+}
+
+func (c *Client) dropStaleCollections(ctx context.Context, vc versionedCollection, keep int) ([]string, error) {
+	live, aliased, err := c.liveCollection(ctx, vc)
+	if err != nil {
+		return nil, err
+	}
+	if !aliased {
+		return nil, nil
+	}
+	names, err := c.collectionNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var dropped []string
+	for _, name := range staleCollections(vc.prefix, names, live, keep) {
+		if err := c.DropCollection(ctx, name); err != nil {
+			return dropped, err
+		}
+		dropped = append(dropped, name)
+	}
+	return dropped, nil
+}
+
+func (c *Client) collectionNames(ctx context.Context) ([]string, error) {
+	collections, err := c.client.Collections().Retrieve(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve collections: %w", err)
+	}
+	names := make([]string, 0, len(collections))
+	for _, collection := range collections {
+		names = append(names, collection.Name)
+	}
+	return names, nil
+}
+
+func isNotFound(err error) bool {
+	var httpErr *typesense.HTTPError
+	return errors.As(err, &httpErr) && httpErr.Status == http.StatusNotFound
+}
+
+// IndexFacility indexes a facility document in the live collection
 func (c *Client) IndexFacility(ctx context.Context, document map[string]interface{}) error {
-	_, err := c.client.Collection(FacilitiesCollection).Documents().Upsert(ctx, document)
+	return c.IndexFacilityInto(ctx, FacilitiesCollection, document)
+}
+
+// IndexFacilityInto indexes a facility document in the given collection
+func (c *Client) IndexFacilityInto(ctx context.Context, collection string, document map[string]interface{}) error {
+	_, err := c.client.Collection(collection).Documents().Upsert(ctx, document)
 	return err
 }
+
+// DeleteFacility removes a facility document from the live collection. A
+// document that is already gone is not an error.
+func (c *Client) DeleteFacility(ctx context.Context, id string) error {
+	_, err := c.client.Collection(FacilitiesCollection).Document(id).Delete(ctx)
+	if err != nil && !isNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// ExportFacilities returns every document in the live collection by ID
+func (c *Client) ExportFacilities(ctx context.Context) (map[string]map[string]interface{}, error) {
+	return c.exportDocuments(ctx, FacilitiesCollection)
+}
+
+// EnsureProceduresCollection ensures the procedures alias exists, creating an
+// empty first collection behind it when there is none, and returns the live
+// collection's drift from the schema in code. A legacy procedures collection
+// without an alias keeps serving until the indexer replaces it.
+func (c *Client) EnsureProceduresCollection(ctx context.Context) ([]string, error) {
+	live, _, err := c.liveCollection(ctx, procedures)
+	if err != nil {
+		return nil, err
+	}
+	if live != "" {
+		return c.schemaDrift(ctx, procedures, live)
+	}
+
+	name, err := c.createVersionedCollection(ctx, procedures)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := c.swapAlias(ctx, procedures, name); err != nil {
+		return nil, err
+	}
+	log.Printf("Created Typesense collection '%s' behind alias '%s'", name, ProceduresCollection)
+	return nil, nil
+}
+
+// CreateVersionedProceduresCollection creates the next procedures_v{n}
+// collection with the schema in code and returns its name
+func (c *Client) CreateVersionedProceduresCollection(ctx context.Context) (string, error) {
+	return c.createVersionedCollection(ctx, procedures)
+}
+
+// SwapProceduresAlias points the procedures alias at collection and returns
+// the collection it pointed at before, dropping a legacy procedures
+// collection the way SwapAlias does
+func (c *Client) SwapProceduresAlias(ctx context.Context, collection string) (string, error) {
+	return c.swapAlias(ctx, procedures, collection)
+}
+
+// DropStaleProceduresCollections deletes procedures_v{n} collections other
+// than the live one and the newest keep older ones, and returns their names
+func (c *Client) DropStaleProceduresCollections(ctx context.Context, keep int) ([]string, error) {
+	return c.dropStaleCollections(ctx, procedures, keep)
+}
+
+// IndexProcedure indexes a procedure document in the live collection
+func (c *Client) IndexProcedure(ctx context.Context, document map[string]interface{}) error {
+	return c.IndexProcedureInto(ctx, ProceduresCollection, document)
+}
+
+// IndexProcedureInto indexes a procedure document in the given collection
+func (c *Client) IndexProcedureInto(ctx context.Context, collection string, document map[string]interface{}) error {
+	_, err := c.client.Collection(collection).Documents().Upsert(ctx, document)
+	return err
+}
+
+// DeleteProcedure removes a procedure document. A document that is already
+// gone is not an error.
+func (c *Client) DeleteProcedure(ctx context.Context, id string) error {
+	_, err := c.client.Collection(ProceduresCollection).Document(id).Delete(ctx)
+	if err != nil && !isNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// ExportProcedures returns every procedure document by ID
+func (c *Client) ExportProcedures(ctx context.Context) (map[string]map[string]interface{}, error) {
+	return c.exportDocuments(ctx, ProceduresCollection)
+}
+
+func (c *Client) exportDocuments(ctx context.Context, collection string) (map[string]map[string]interface{}, error) {
+	body, err := c.client.Collection(collection).Documents().Export(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	documents := map[string]map[string]interface{}{}
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var document map[string]interface{}
+		if err := json.Unmarshal(line, &document); err != nil {
+			return nil, fmt.Errorf("failed to decode exported document: %w", err)
+		}
+		if id, ok := document["id"].(string); ok {
+			documents[id] = document
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read exported documents: %w", err)
+	}
+	return documents, nil
+}
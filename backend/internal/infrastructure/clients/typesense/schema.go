@@ -0,0 +1,254 @@
+package typesense
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/typesense/typesense-go/v2/typesense/api"
+	"github.com/typesense/typesense-go/v2/typesense/api/pointer"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+)
+
+const (
+	// EmbeddingField is the vector field in facility and procedure documents
+	EmbeddingField = "embedding"
+
+	// EmbeddingModelField names the model a document's embedding came from;
+	// vectors are only comparable with query vectors from the same model
+	EmbeddingModelField = "embedding_model"
+)
+
+// FacilitiesSchemaVersion identifies the facilities schema below. Bump it with
+// every field change; running the indexer then builds a collection with the
+// new schema and swaps it in behind the alias.
+const FacilitiesSchemaVersion = 6
+
+// facilitiesCollectionPrefix names versioned collections, facilities_v{n}
+const facilitiesCollectionPrefix = FacilitiesCollection + "_v"
+
+// FacilitiesSchema returns the facilities schema for a collection with the given name
+func FacilitiesSchema(name string) *api.CollectionSchema {
+	return &api.CollectionSchema{
+		Name: name,
+		Fields: []api.Field{
+			{Name: "id", Type: "string"},
+			{Name: "name", Type: "string"},
+			{Name: "facility_type", Type: "string", Facet: pointer.True()},
+			{Name: "location", Type: "geopoint"},
+			{Name: "price", Type: "float", Facet: pointer.True(), Optional: pointer.True()},
+			{Name: "rating", Type: "float", Facet: pointer.True()},
+			{Name: "review_count", Type: "int32"},
+			{Name: "created_at", Type: "int64"},
+			{Name: "is_active", Type: "bool"},
+			{Name: "capacity_status", Type: "string", Facet: pointer.True(), Optional: pointer.True()},
+			{Name: "open_24_hours", Type: "bool", Optional: pointer.True()},
+			{Name: "insurance", Type: "string[]", Facet: pointer.True(), Optional: pointer.True()},
+			{Name: "procedures", Type: "string[]", Optional: pointer.True()},
+			{Name: "procedure_ids", Type: "string[]", Optional: pointer.True()},
+			{Name: "tags", Type: "string[]", Optional: pointer.True()},
+			{Name: "concepts", Type: "string[]", Optional: pointer.True()},
+			{Name: "conditions", Type: "string[]", Optional: pointer.True()},
+			{Name: "symptoms", Type: "string[]", Optional: pointer.True()},
+			{Name: "specialties", Type: "string[]", Facet: pointer.True(), Optional: pointer.True()},
+			embeddingField(),
+			embeddingModelField(),
+		},
+		DefaultSortingField: pointer.String("created_at"),
+	}
+}
+
+// ProceduresSchemaVersion identifies the procedures schema below. Like the
+// facilities schema, a change is rolled out by the indexer building a new
+// procedures_v{n} collection and swapping it in behind the alias.
+const ProceduresSchemaVersion = 3
+
+// proceduresCollectionPrefix names versioned collections, procedures_v{n}
+const proceduresCollectionPrefix = ProceduresCollection + "_v"
+
+// ProceduresSchema returns the procedures schema for a collection with the
+// given name. Conditions and specialties are faceted so autocomplete can
+// complete them from a prefix.
+func ProceduresSchema(name string) *api.CollectionSchema {
+	return &api.CollectionSchema{
+		Name: name,
+		Fields: []api.Field{
+			{Name: "id", Type: "string"},
+			{Name: "name", Type: "string"},
+			{Name: "display_name", Type: "string", Optional: pointer.True()},
+			{Name: "code", Type: "string", Optional: pointer.True()},
+			{Name: "category", Type: "string", Facet: pointer.True(), Optional: pointer.True()},
+			{Name: "tags", Type: "string[]", Optional: pointer.True()},
+			{Name: "conditions", Type: "string[]", Facet: pointer.True(), Optional: pointer.True()},
+			{Name: "symptoms", Type: "string[]", Optional: pointer.True()},
+			{Name: "lay_terms", Type: "string[]", Optional: pointer.True()},
+			{Name: "synonyms", Type: "string[]", Optional: pointer.True()},
+			{Name: "specialties", Type: "string[]", Facet: pointer.True(), Optional: pointer.True()},
+			{Name: "is_active", Type: "bool"},
+			{Name: "facility_count", Type: "int32"},
+			{Name: "min_price", Type: "float", Optional: pointer.True()},
+			{Name: "max_price", Type: "float", Optional: pointer.True()},
+			{Name: "median_price", Type: "float", Optional: pointer.True()},
+			{Name: "currency", Type: "string", Optional: pointer.True()},
+			embeddingField(),
+			embeddingModelField(),
+		},
+		DefaultSortingField: pointer.String("facility_count"),
+	}
+}
+
+// embeddingField holds a document's embedding for semantic search. It is
+// optional so documents still index when the embedding provider fails.
+func embeddingField() api.Field {
+	return api.Field{
+		Name:     EmbeddingField,
+		Type:     "float[]",
+		NumDim:   pointer.Int(entities.EmbeddingDimensions),
+		Optional: pointer.True(),
+	}
+}
+
+// embeddingModelField is faceted so searches can check which models a
+// collection's embeddings came from
+func embeddingModelField() api.Field {
+	return api.Field{Name: EmbeddingModelField, Type: "string", Facet: pointer.True(), Optional: pointer.True()}
+}
+
+// VersionedCollectionName returns the name of the nth facilities collection
+func VersionedCollectionName(n int) string {
+	return facilitiesCollectionPrefix + strconv.Itoa(n)
+}
+
+// ParseCollectionVersion returns n for a facilities_v{n} collection name
+func ParseCollectionVersion(name string) (int, bool) {
+	return parseVersion(facilitiesCollectionPrefix, name)
+}
+
+// VersionedProceduresCollectionName returns the name of the nth procedures collection
+func VersionedProceduresCollectionName(n int) string {
+	return proceduresCollectionPrefix + strconv.Itoa(n)
+}
+
+// ParseProceduresCollectionVersion returns n for a procedures_v{n} collection name
+func ParseProceduresCollectionVersion(name string) (int, bool) {
+	return parseVersion(proceduresCollectionPrefix, name)
+}
+
+func parseVersion(prefix, name string) (int, bool) {
+	suffix, ok := strings.CutPrefix(name, prefix)
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.Atoi(suffix)
+	if err != nil || n <= 0 {
+		return 0, false
+	}
+	return n, true
+}
+
+// SchemaDrift lists the differences between the schema in code and a live
+// collection, empty when they match. Typesense manages the id field itself,
+// so it is not compared.
+func SchemaDrift(expected *api.CollectionSchema, live *api.CollectionResponse) []string {
+	var drift []string
+
+	liveFields := make(map[string]api.Field, len(live.Fields))
+	for _, field := range live.Fields {
+		liveFields[field.Name] = field
+	}
+
+	for _, want := range expected.Fields {
+		if want.Name == "id" {
+			continue
+		}
+		got, ok := liveFields[want.Name]
+		if !ok {
+			drift = append(drift, fmt.Sprintf("field %s is missing", want.Name))
+			continue
+		}
+		delete(liveFields, want.Name)
+		if got.Type != want.Type {
+			drift = append(drift, fmt.Sprintf("field %s has type %s, expected %s", want.Name, got.Type, want.Type))
+		}
+		if flag(got.Facet) != flag(want.Facet) {
+			drift = append(drift, fmt.Sprintf("field %s has facet=%t, expected %t", want.Name, flag(got.Facet), flag(want.Facet)))
+		}
+		if flag(got.Optional) != flag(want.Optional) {
+			drift = append(drift, fmt.Sprintf("field %s has optional=%t, expected %t", want.Name, flag(got.Optional), flag(want.Optional)))
+		}
+		if intValue(got.NumDim) != intValue(want.NumDim) {
+			drift = append(drift, fmt.Sprintf("field %s has %d dimensions, expected %d", want.Name, intValue(got.NumDim), intValue(want.NumDim)))
+		}
+	}
+
+	extra := make([]string, 0, len(liveFields))
+	for name := range liveFields {
+		if name != "id" {
+			extra = append(extra, name)
+		}
+	}
+	sort.Strings(extra)
+	for _, name := range extra {
+		drift = append(drift, fmt.Sprintf("field %s is not in the schema", name))
+	}
+
+	if want, got := stringValue(expected.DefaultSortingField), stringValue(live.DefaultSortingField); want != got {
+		drift = append(drift, fmt.Sprintf("default sorting field is %q, expected %q", got, want))
+	}
+	return drift
+}
+
+// StaleCollections returns the facilities_v{n} collections that can be
+// dropped: abandoned builds newer than the live collection, and all but the
+// newest keep older ones, which stay available for rollback.
+func StaleCollections(names []string, live string, keep int) []string {
+	return staleCollections(facilitiesCollectionPrefix, names, live, keep)
+}
+
+// StaleProceduresCollections is StaleCollections for procedures_v{n} collections
+func StaleProceduresCollections(names []string, live string, keep int) []string {
+	return staleCollections(proceduresCollectionPrefix, names, live, keep)
+}
+
+func staleCollections(prefix string, names []string, live string, keep int) []string {
+	liveVersion, _ := parseVersion(prefix, live)
+	var older []int
+	var stale []string
+	for _, name := range names {
+		n, ok := parseVersion(prefix, name)
+		switch {
+		case !ok || name == live:
+		case n > liveVersion:
+			stale = append(stale, name)
+		default:
+			older = append(older, n)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(older)))
+	for i, n := range older {
+		if i >= keep {
+			stale = append(stale, prefix+strconv.Itoa(n))
+		}
+	}
+	sort.Strings(stale)
+	return stale
+}
+
+func flag(value *bool) bool {
+	return value != nil && *value
+}
+
+func intValue(value *int) int {
+	if value == nil {
+		return 0
+	}
+	return *value
+}
+
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
@@ -0,0 +1,97 @@
+package typesense
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/typesense/typesense-go/v2/typesense/api"
+	"github.com/typesense/typesense-go/v2/typesense/api/pointer"
+)
+
+// liveCollection returns the collection Typesense reports for a schema
+func liveCollection(schema *api.CollectionSchema) *api.CollectionResponse {
+	fields := make([]api.Field, 0, len(schema.Fields))
+	for _, field := range schema.Fields {
+		if field.Name == "id" {
+			continue
+		}
+		// Typesense fills in the defaults
+		if field.Facet == nil {
+			field.Facet = pointer.False()
+		}
+		if field.Optional == nil {
+			field.Optional = pointer.False()
+		}
+		field.Index = pointer.True()
+		fields = append(fields, field)
+	}
+	return &api.CollectionResponse{Name: schema.Name, Fields: fields, DefaultSortingField: schema.DefaultSortingField}
+}
+
+func TestSchemaDrift(t *testing.T) {
+	expected := FacilitiesSchema("facilities_v3")
+
+	assert.Empty(t, SchemaDrift(expected, liveCollection(expected)))
+
+	live := liveCollection(expected)
+	for i := range live.Fields {
+		switch live.Fields[i].Name {
+		case "rating":
+			live.Fields[i].Facet = pointer.False()
+		case "review_count":
+			live.Fields[i].Type = "int64"
+		case "embedding":
+			live.Fields[i].NumDim = pointer.Int(1536)
+		}
+	}
+	live.Fields = append(live.Fields, api.Field{Name: "legacy_score", Type: "float"})
+	withoutSpecialties := live.Fields[:0]
+	for _, field := range live.Fields {
+		if field.Name != "specialties" {
+			withoutSpecialties = append(withoutSpecialties, field)
+		}
+	}
+	live.Fields = withoutSpecialties
+
+	assert.Equal(t, []string{
+		"field rating has facet=false, expected true",
+		"field review_count has type int64, expected int32",
+		"field specialties is missing",
+		"field embedding has 1536 dimensions, expected 256",
+		"field legacy_score is not in the schema",
+	}, SchemaDrift(expected, live))
+}
+
+func TestParseCollectionVersion(t *testing.T) {
+	n, ok := ParseCollectionVersion(VersionedCollectionName(12))
+	assert.True(t, ok)
+	assert.Equal(t, 12, n)
+
+	for _, name := range []string{"facilities", "facilities_v", "facilities_v0", "facilities_vx", "procedures_v1"} {
+		_, ok := ParseCollectionVersion(name)
+		assert.False(t, ok, name)
+	}
+}
+
+func TestStaleCollections(t *testing.T) {
+	names := []string{"procedures", "facilities_v1", "facilities_v2", "facilities_v3", "facilities_v4", "facilities_v5"}
+
+	// v5 is an abandoned build, v3 is kept for rollback
+	assert.Equal(t, []string{"facilities_v1", "facilities_v2", "facilities_v5"}, StaleCollections(names, "facilities_v4", 1))
+	assert.Equal(t, []string{"facilities_v5"}, StaleCollections(names, "facilities_v4", 5))
+	assert.Equal(t, []string{"facilities_v1", "facilities_v2", "facilities_v3", "facilities_v5"}, StaleCollections(names, "facilities_v4", 0))
+}
+
+func TestStaleProceduresCollections(t *testing.T) {
+	names := []string{"procedures_v1", "procedures_v2", "procedures_v3", "facilities_v1", "facilities_v2"}
+
+	// Facility collections are never procedure collections to drop
+	assert.Equal(t, []string{"procedures_v1"}, StaleProceduresCollections(names, "procedures_v3", 1))
+	assert.Equal(t, []string{"procedures_v3"}, StaleProceduresCollections(names, "procedures_v2", 1))
+
+	n, ok := ParseProceduresCollectionVersion(VersionedProceduresCollectionName(7))
+	assert.True(t, ok)
+	assert.Equal(t, 7, n)
+	_, ok = ParseProceduresCollectionVersion("facilities_v7")
+	assert.False(t, ok)
+}
@@ -0,0 +1,205 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/providers"
+)
+
+// smtpImplicitTLSPort is the submission port that expects TLS from the first byte
+const smtpImplicitTLSPort = "465"
+
+// SMTPEmailSender sends plain text email through an SMTP relay. Connections
+// upgrade with STARTTLS when the server offers it, or use implicit TLS on port 465.
+type SMTPEmailSender struct {
+	host     string
+	port     string
+	username string
+	password string
+	from     *mail.Address
+	timeout  time.Duration
+}
+
+// NewSMTPEmailSender creates a new email sender from SMTP_HOST, SMTP_PORT,
+// SMTP_USERNAME, SMTP_PASSWORD and SMTP_FROM
+func NewSMTPEmailSender() (*SMTPEmailSender, error) {
+	host := os.Getenv("SMTP_HOST")
+	fromValue := os.Getenv("SMTP_FROM")
+
+	if host == "" || fromValue == "" {
+		return nil, fmt.Errorf("SMTP_HOST and SMTP_FROM must be set")
+	}
+
+	from, err := mail.ParseAddress(fromValue)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SMTP_FROM address: %w", err)
+	}
+
+	port := os.Getenv("SMTP_PORT")
+	if port == "" {
+		port = "587"
+	}
+
+	return &SMTPEmailSender{
+		host:     host,
+		port:     port,
+		username: os.Getenv("SMTP_USERNAME"),
+		password: os.Getenv("SMTP_PASSWORD"),
+		from:     from,
+		timeout:  30 * time.Second,
+	}, nil
+}
+
+// Channel returns the email channel
+func (s *SMTPEmailSender) Channel() entities.NotificationChannel {
+	return entities.ChannelEmail
+}
+
+// Send delivers the message as a plain text email and returns its Message-ID
+func (s *SMTPEmailSender) Send(ctx context.Context, message providers.NotificationMessage) (string, error) {
+	to, err := mail.ParseAddress(message.To)
+	if err != nil {
+		return "", fmt.Errorf("invalid recipient address: %w", err)
+	}
+
+	messageID, err := s.newMessageID()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate message ID: %w", err)
+	}
+	data, err := s.buildMessage(to, message.Subject, message.Body, messageID)
+	if err != nil {
+		return "", err
+	}
+
+	client, err := s.dial(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer client.Close()
+
+	if err := s.deliver(client, to.Address, data); err != nil {
+		return "", err
+	}
+	return messageID, nil
+}
+
+// dial connects to the relay, upgrades to TLS when available and authenticates
+func (s *SMTPEmailSender) dial(ctx context.Context) (*smtp.Client, error) {
+	address := net.JoinHostPort(s.host, s.port)
+	dialer := &net.Dialer{Timeout: s.timeout}
+
+	var conn net.Conn
+	var err error
+	if s.port == smtpImplicitTLSPort {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: s.host}}).DialContext(ctx, "tcp", address)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", address)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to SMTP server: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	} else {
+		_ = conn.SetDeadline(time.Now().Add(s.timeout))
+	}
+
+	client, err := smtp.NewClient(conn, s.host)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to start SMTP session: %w", err)
+	}
+
+	if ok, _ := client.Extension("STARTTLS"); ok && s.port != smtpImplicitTLSPort {
+		if err := client.StartTLS(&tls.Config{ServerName: s.host}); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("failed to start TLS: %w", err)
+		}
+	}
+
+	if s.username != "" {
+		if ok, _ := client.Extension("AUTH"); ok {
+			if err := client.Auth(smtp.PlainAuth("", s.username, s.password, s.host)); err != nil {
+				client.Close()
+				return nil, fmt.Errorf("SMTP authentication failed: %w", err)
+			}
+		}
+	}
+
+	return client, nil
+}
+
+func (s *SMTPEmailSender) deliver(client *smtp.Client, to string, data []byte) error {
+	if err := client.Mail(s.from.Address); err != nil {
+		return fmt.Errorf("SMTP MAIL FROM rejected: %w", err)
+	}
+	if err := client.Rcpt(to); err != nil {
+		return fmt.Errorf("SMTP RCPT TO rejected: %w", err)
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("SMTP DATA rejected: %w", err)
+	}
+	if _, err := writer.Write(data); err != nil {
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("SMTP server rejected message: %w", err)
+	}
+
+	return client.Quit()
+}
+
+// buildMessage renders RFC 5322 headers and a quoted-printable UTF-8 body
+func (s *SMTPEmailSender) buildMessage(to *mail.Address, subject, body, messageID string) ([]byte, error) {
+	var buf bytes.Buffer
+	headers := []string{
+		"From: " + s.from.String(),
+		"To: " + to.String(),
+		"Subject: " + mime.QEncoding.Encode("UTF-8", subject),
+		"Date: " + time.Now().Format(time.RFC1123Z),
+		"Message-ID: " + messageID,
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=UTF-8",
+		"Content-Transfer-Encoding: quoted-printable",
+	}
+	buf.WriteString(strings.Join(headers, "\r\n"))
+	buf.WriteString("\r\n\r\n")
+
+	// The quoted-printable writer emits CRLF line breaks
+	writer := quotedprintable.NewWriter(&buf)
+	if _, err := writer.Write([]byte(body)); err != nil {
+		return nil, fmt.Errorf("failed to encode message body: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encode message body: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func (s *SMTPEmailSender) newMessageID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	domain := s.host
+	if at := strings.LastIndex(s.from.Address, "@"); at >= 0 {
+		domain = s.from.Address[at+1:]
+	}
+	return fmt.Sprintf("<%s@%s>", hex.EncodeToString(buf), domain), nil
+}
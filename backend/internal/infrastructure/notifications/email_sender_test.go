@@ -0,0 +1,95 @@
+package notifications
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/providers"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/infrastructure/notifications/notificationstest"
+)
+
+func newTestEmailSender(t *testing.T, server *notificationstest.SMTPServer) *SMTPEmailSender {
+	t.Helper()
+	t.Setenv("SMTP_HOST", server.Host())
+	t.Setenv("SMTP_PORT", server.Port())
+	t.Setenv("SMTP_USERNAME", "mailer")
+	t.Setenv("SMTP_PASSWORD", "secret")
+	t.Setenv("SMTP_FROM", "Price Finder <no-reply@example.com>")
+
+	sender, err := NewSMTPEmailSender()
+	if err != nil {
+		t.Fatalf("NewSMTPEmailSender() error = %v", err)
+	}
+	return sender
+}
+
+func TestNewSMTPEmailSender_Validation(t *testing.T) {
+	t.Setenv("SMTP_HOST", "smtp.example.com")
+	t.Setenv("SMTP_FROM", "")
+	if _, err := NewSMTPEmailSender(); err == nil {
+		t.Error("expected error when SMTP_FROM is missing")
+	}
+
+	t.Setenv("SMTP_FROM", "not an address")
+	if _, err := NewSMTPEmailSender(); err == nil {
+		t.Error("expected error for an invalid SMTP_FROM")
+	}
+}
+
+func TestSMTPEmailSender_Send(t *testing.T) {
+	server := notificationstest.NewSMTPServer(t)
+	sender := newTestEmailSender(t, server)
+	if sender.Channel() != entities.ChannelEmail {
+		t.Errorf("Channel() = %q, want email", sender.Channel())
+	}
+
+	messageID, err := sender.Send(context.Background(), providers.NotificationMessage{
+		To:      "ada@example.com",
+		Subject: "Appointment confirmed: MRI Scan",
+		Body:    "Hi Ada,\n\nYour appointment is confirmed.",
+	})
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if !strings.HasPrefix(messageID, "<") || !strings.HasSuffix(messageID, "@example.com>") {
+		t.Errorf("messageID = %q, want <...@example.com>", messageID)
+	}
+
+	messages := server.Messages()
+	if len(messages) != 1 {
+		t.Fatalf("server received %d messages, want 1", len(messages))
+	}
+	msg := messages[0]
+	if msg.AuthUser != "mailer" {
+		t.Errorf("AuthUser = %q, want mailer", msg.AuthUser)
+	}
+	if msg.From != "no-reply@example.com" || len(msg.To) != 1 || msg.To[0] != "ada@example.com" {
+		t.Errorf("unexpected envelope: from %q to %v", msg.From, msg.To)
+	}
+	for _, want := range []string{
+		"Subject: Appointment confirmed: MRI Scan",
+		"Message-ID: " + messageID,
+		"Content-Type: text/plain; charset=UTF-8",
+		"Your appointment is confirmed.",
+	} {
+		if !strings.Contains(msg.Data, want) {
+			t.Errorf("message data missing %q:\n%s", want, msg.Data)
+		}
+	}
+}
+
+func TestSMTPEmailSender_RecipientRejected(t *testing.T) {
+	server := notificationstest.NewSMTPServer(t)
+	server.RejectRecipients(true)
+	sender := newTestEmailSender(t, server)
+
+	_, err := sender.Send(context.Background(), providers.NotificationMessage{To: "ada@example.com", Subject: "Hi", Body: "Hello"})
+	if err == nil || !strings.Contains(err.Error(), "RCPT TO rejected") {
+		t.Fatalf("Send() error = %v, want RCPT TO rejected", err)
+	}
+	if len(server.Messages()) != 0 {
+		t.Error("no message should be accepted when the recipient is rejected")
+	}
+}
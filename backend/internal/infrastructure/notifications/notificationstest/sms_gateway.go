@@ -0,0 +1,81 @@
+package notificationstest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// SMSRequest is a message received by the fake SMS gateway
+type SMSRequest struct {
+	APIKey  string
+	To      string `json:"to"`
+	From    string `json:"from"`
+	Message string `json:"message"`
+}
+
+// SMSGateway is a fake HTTP SMS gateway that records messages and replies
+// with a message ID, or with a configured error status.
+type SMSGateway struct {
+	server *httptest.Server
+
+	mu       sync.Mutex
+	requests []SMSRequest
+	status   int
+}
+
+// NewSMSGateway starts a fake SMS gateway that is closed when the test ends
+func NewSMSGateway(t testing.TB) *SMSGateway {
+	t.Helper()
+
+	gateway := &SMSGateway{status: http.StatusOK}
+	gateway.server = httptest.NewServer(http.HandlerFunc(gateway.handle))
+	t.Cleanup(gateway.server.Close)
+	return gateway
+}
+
+// URL returns the gateway endpoint
+func (g *SMSGateway) URL() string {
+	return g.server.URL + "/sms"
+}
+
+// FailWith makes the gateway answer every request with the given status
+func (g *SMSGateway) FailWith(status int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.status = status
+}
+
+// Requests returns the messages received so far
+func (g *SMSGateway) Requests() []SMSRequest {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return append([]SMSRequest(nil), g.requests...)
+}
+
+func (g *SMSGateway) handle(w http.ResponseWriter, r *http.Request) {
+	var req SMSRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	req.APIKey = strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+
+	g.mu.Lock()
+	g.requests = append(g.requests, req)
+	status := g.status
+	count := len(g.requests)
+	g.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	if status != http.StatusOK {
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": "gateway unavailable"})
+		return
+	}
+	_ = json.NewEncoder(w).Encode(map[string]string{"message_id": fmt.Sprintf("sms-%d", count)})
+}
@@ -0,0 +1,178 @@
+// Package notificationstest provides local fake SMTP and SMS gateway servers
+// for testing notification delivery without external services.
+package notificationstest
+
+import (
+	"bufio"
+	"encoding/base64"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// SMTPMessage is a message accepted by the fake SMTP server
+type SMTPMessage struct {
+	AuthUser string
+	From     string
+	To       []string
+	Data     string
+}
+
+// SMTPServer is a minimal plain-text SMTP server listening on 127.0.0.1. It
+// supports EHLO, AUTH PLAIN, MAIL, RCPT, DATA, RSET, NOOP and QUIT.
+type SMTPServer struct {
+	listener net.Listener
+
+	mu               sync.Mutex
+	messages         []SMTPMessage
+	rejectRecipients bool
+}
+
+// NewSMTPServer starts a fake SMTP server that is closed when the test ends
+func NewSMTPServer(t testing.TB) *SMTPServer {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to start fake SMTP server: %v", err)
+	}
+
+	server := &SMTPServer{listener: listener}
+	go server.serve()
+	t.Cleanup(func() { listener.Close() })
+	return server
+}
+
+// Host returns the address the server listens on
+func (s *SMTPServer) Host() string {
+	host, _, _ := net.SplitHostPort(s.listener.Addr().String())
+	return host
+}
+
+// Port returns the port the server listens on
+func (s *SMTPServer) Port() string {
+	_, port, _ := net.SplitHostPort(s.listener.Addr().String())
+	return port
+}
+
+// RejectRecipients makes the server refuse every RCPT TO with a 550
+func (s *SMTPServer) RejectRecipients(reject bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rejectRecipients = reject
+}
+
+// Messages returns the messages accepted so far
+func (s *SMTPServer) Messages() []SMTPMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]SMTPMessage(nil), s.messages...)
+}
+
+func (s *SMTPServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *SMTPServer) handle(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	reply := func(line string) {
+		_, _ = conn.Write([]byte(line + "\r\n"))
+	}
+
+	var current SMTPMessage
+	var authUser string
+	reply("220 fake.smtp ESMTP ready")
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+
+		switch verb {
+		case "EHLO":
+			reply("250-fake.smtp")
+			reply("250 AUTH PLAIN")
+		case "HELO", "NOOP":
+			reply("250 OK")
+		case "AUTH":
+			authUser = decodePlainAuthUser(line)
+			reply("235 2.7.0 Authentication successful")
+		case "MAIL":
+			current = SMTPMessage{AuthUser: authUser, From: addressArg(line)}
+			reply("250 OK")
+		case "RCPT":
+			s.mu.Lock()
+			reject := s.rejectRecipients
+			s.mu.Unlock()
+			if reject {
+				reply("550 5.1.1 Mailbox unavailable")
+				continue
+			}
+			current.To = append(current.To, addressArg(line))
+			reply("250 OK")
+		case "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				dataLine, err := reader.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if dataLine == ".\r\n" || dataLine == ".\n" {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(dataLine, "."))
+			}
+			current.Data = data.String()
+			s.mu.Lock()
+			s.messages = append(s.messages, current)
+			s.mu.Unlock()
+			reply("250 OK queued")
+		case "RSET":
+			current = SMTPMessage{AuthUser: authUser}
+			reply("250 OK")
+		case "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("502 Command not implemented")
+		}
+	}
+}
+
+// addressArg extracts the address from "MAIL FROM:<a@b>" or "RCPT TO:<a@b>"
+func addressArg(line string) string {
+	start := strings.Index(line, "<")
+	end := strings.LastIndex(line, ">")
+	if start < 0 || end <= start {
+		return ""
+	}
+	return line[start+1 : end]
+}
+
+// decodePlainAuthUser reads the username from "AUTH PLAIN <base64(\x00user\x00pass)>"
+func decodePlainAuthUser(line string) string {
+	fields := strings.Fields(line)
+	if len(fields) < 3 {
+		return ""
+	}
+	decoded, err := base64.StdEncoding.DecodeString(fields[2])
+	if err != nil {
+		return ""
+	}
+	parts := strings.Split(string(decoded), "\x00")
+	if len(parts) < 2 {
+		return ""
+	}
+	return parts[1]
+}
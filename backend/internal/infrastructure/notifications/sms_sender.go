@@ -0,0 +1,110 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/providers"
+)
+
+// HTTPSMSSender sends SMS through a generic HTTP gateway. The gateway receives
+// a JSON POST of {"to", "from", "message"} authenticated with a bearer API key.
+type HTTPSMSSender struct {
+	gatewayURL string
+	apiKey     string
+	senderID   string
+	httpClient *http.Client
+}
+
+// NewHTTPSMSSender creates a new SMS sender from SMS_GATEWAY_URL, SMS_GATEWAY_API_KEY and SMS_SENDER_ID
+func NewHTTPSMSSender() (*HTTPSMSSender, error) {
+	gatewayURL := os.Getenv("SMS_GATEWAY_URL")
+	apiKey := os.Getenv("SMS_GATEWAY_API_KEY")
+
+	if gatewayURL == "" || apiKey == "" {
+		return nil, fmt.Errorf("SMS_GATEWAY_URL and SMS_GATEWAY_API_KEY must be set")
+	}
+
+	return &HTTPSMSSender{
+		gatewayURL: gatewayURL,
+		apiKey:     apiKey,
+		senderID:   os.Getenv("SMS_SENDER_ID"),
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}, nil
+}
+
+// SMSGatewayRequest is the payload posted to the SMS gateway
+type SMSGatewayRequest struct {
+	To      string `json:"to"`
+	From    string `json:"from,omitempty"`
+	Message string `json:"message"`
+}
+
+// SMSGatewayResponse holds the message ID fields returned by common gateways
+type SMSGatewayResponse struct {
+	MessageID    string `json:"message_id"`
+	MessageIDAlt string `json:"messageId"`
+	ID           string `json:"id"`
+}
+
+// Channel returns the SMS channel
+func (s *HTTPSMSSender) Channel() entities.NotificationChannel {
+	return entities.ChannelSMS
+}
+
+// Send delivers the message body as an SMS
+func (s *HTTPSMSSender) Send(ctx context.Context, message providers.NotificationMessage) (string, error) {
+	return s.SendText(ctx, message.To, message.Body)
+}
+
+// SendText sends a plain text SMS
+func (s *HTTPSMSSender) SendText(ctx context.Context, to, body string) (string, error) {
+	jsonData, err := json.Marshal(SMSGatewayRequest{To: to, From: s.senderID, Message: body})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.gatewayURL, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+s.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", fmt.Errorf("SMS gateway error (status %d): %s", resp.StatusCode, string(respBody))
+	}
+
+	// Gateways that accept the message without returning an ID are still a successful send
+	var gatewayResp SMSGatewayResponse
+	if err := json.Unmarshal(respBody, &gatewayResp); err != nil {
+		return "", nil
+	}
+	for _, id := range []string{gatewayResp.MessageID, gatewayResp.MessageIDAlt, gatewayResp.ID} {
+		if id != "" {
+			return id, nil
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,71 @@
+package notifications
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/providers"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/infrastructure/notifications/notificationstest"
+)
+
+func TestNewHTTPSMSSender_RequiresGateway(t *testing.T) {
+	t.Setenv("SMS_GATEWAY_URL", "")
+	t.Setenv("SMS_GATEWAY_API_KEY", "key")
+
+	if _, err := NewHTTPSMSSender(); err == nil {
+		t.Fatal("expected error when SMS_GATEWAY_URL is missing")
+	}
+}
+
+func TestHTTPSMSSender_Send(t *testing.T) {
+	gateway := notificationstest.NewSMSGateway(t)
+	t.Setenv("SMS_GATEWAY_URL", gateway.URL())
+	t.Setenv("SMS_GATEWAY_API_KEY", "sms-key")
+	t.Setenv("SMS_SENDER_ID", "PriceFinder")
+
+	sender, err := NewHTTPSMSSender()
+	if err != nil {
+		t.Fatalf("NewHTTPSMSSender() error = %v", err)
+	}
+	if sender.Channel() != entities.ChannelSMS {
+		t.Errorf("Channel() = %q, want sms", sender.Channel())
+	}
+
+	messageID, err := sender.Send(context.Background(), providers.NotificationMessage{
+		To:   "+2348031234567",
+		Body: "Your appointment is confirmed",
+	})
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if messageID != "sms-1" {
+		t.Errorf("messageID = %q, want sms-1", messageID)
+	}
+
+	requests := gateway.Requests()
+	if len(requests) != 1 {
+		t.Fatalf("gateway received %d requests, want 1", len(requests))
+	}
+	got := requests[0]
+	if got.APIKey != "sms-key" || got.To != "+2348031234567" || got.From != "PriceFinder" || got.Message != "Your appointment is confirmed" {
+		t.Errorf("unexpected gateway request: %+v", got)
+	}
+}
+
+func TestHTTPSMSSender_GatewayError(t *testing.T) {
+	gateway := notificationstest.NewSMSGateway(t)
+	gateway.FailWith(http.StatusServiceUnavailable)
+	t.Setenv("SMS_GATEWAY_URL", gateway.URL())
+	t.Setenv("SMS_GATEWAY_API_KEY", "sms-key")
+
+	sender, err := NewHTTPSMSSender()
+	if err != nil {
+		t.Fatalf("NewHTTPSMSSender() error = %v", err)
+	}
+
+	if _, err := sender.SendText(context.Background(), "+2348031234567", "hello"); err == nil {
+		t.Fatal("expected error when the gateway returns 503")
+	}
+}
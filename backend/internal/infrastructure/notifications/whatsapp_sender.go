@@ -2,12 +2,16 @@ package notifications
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"time"
+
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/entities"
+	"github.com/zatekoja/Patientpricediscoverydesign/backend/internal/domain/providers"
 )
 
 // WhatsAppCloudSender sends messages via WhatsApp Cloud API
@@ -184,3 +188,20 @@ func (w *WhatsAppCloudSender) sendMessage(message interface{}) (string, error) {
 
 	return "", fmt.Errorf("no message ID in response")
 }
+
+// Channel returns the WhatsApp channel
+func (w *WhatsAppCloudSender) Channel() entities.NotificationChannel {
+	return entities.ChannelWhatsApp
+}
+
+// Send delivers a message as an approved template when one is named, otherwise as free-form text
+func (w *WhatsAppCloudSender) Send(ctx context.Context, message providers.NotificationMessage) (string, error) {
+	if message.TemplateName != "" {
+		language := message.TemplateLanguage
+		if language == "" {
+			language = "en_US"
+		}
+		return w.SendTemplate(message.To, message.TemplateName, language, message.TemplateParams)
+	}
+	return w.SendText(message.To, message.Body)
+}
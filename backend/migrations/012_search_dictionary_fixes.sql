@@ -0,0 +1,23 @@
+-- Dictionary fixes accepted from zero-result query triage
+CREATE TABLE IF NOT EXISTS search_dictionary_fixes (
+    id UUID PRIMARY KEY,
+    normalized_query TEXT NOT NULL,
+    fix_type VARCHAR(20) NOT NULL,
+    term TEXT NOT NULL,
+    target TEXT NOT NULL,
+    accepted_by VARCHAR(255),
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    searches_after INT NOT NULL DEFAULT 0,
+    successful_after INT NOT NULL DEFAULT 0,
+    last_checked_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_search_dictionary_fixes_term
+    ON search_dictionary_fixes (fix_type, term);
+CREATE INDEX IF NOT EXISTS idx_search_dictionary_fixes_status
+    ON search_dictionary_fixes (status);
+
+CREATE INDEX IF NOT EXISTS idx_search_analytics_normalized_query
+    ON search_analytics (normalized_query, created_at);
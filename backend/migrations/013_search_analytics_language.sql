@@ -0,0 +1,6 @@
+-- Record the detected query language on search analytics events
+ALTER TABLE search_analytics
+    ADD COLUMN IF NOT EXISTS detected_language VARCHAR(10);
+
+CREATE INDEX IF NOT EXISTS idx_search_analytics_detected_language
+    ON search_analytics (detected_language);
@@ -0,0 +1,24 @@
+-- Search A/B experiments and per-event variant assignment
+CREATE TABLE IF NOT EXISTS search_experiments (
+    id UUID PRIMARY KEY,
+    name VARCHAR(100) NOT NULL UNIQUE,
+    description TEXT,
+    status VARCHAR(20) NOT NULL DEFAULT 'draft',
+    traffic_percent INT NOT NULL DEFAULT 100 CHECK (traffic_percent BETWEEN 0 AND 100),
+    variants JSONB NOT NULL,
+    started_at TIMESTAMP,
+    stopped_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- At most one experiment may run at a time
+CREATE UNIQUE INDEX IF NOT EXISTS idx_search_experiments_single_running
+    ON search_experiments (status) WHERE status = 'running';
+
+ALTER TABLE search_analytics
+    ADD COLUMN IF NOT EXISTS experiment_id UUID,
+    ADD COLUMN IF NOT EXISTS experiment_variant VARCHAR(100);
+
+CREATE INDEX IF NOT EXISTS idx_search_analytics_experiment
+    ON search_analytics (experiment_id, experiment_variant);
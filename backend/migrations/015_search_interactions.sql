@@ -0,0 +1,20 @@
+-- Post-search interactions (clicks, views, contact taps, bookings) tied to a search impression
+ALTER TABLE search_analytics
+    ADD COLUMN IF NOT EXISTS result_facility_ids TEXT[],
+    ADD COLUMN IF NOT EXISTS result_offset INT NOT NULL DEFAULT 0;
+
+-- impression_id references search_analytics.id. There is no foreign key because
+-- search events are logged asynchronously and a click can arrive first.
+CREATE TABLE IF NOT EXISTS search_interactions (
+    id UUID PRIMARY KEY,
+    impression_id UUID NOT NULL,
+    event_type VARCHAR(30) NOT NULL,
+    facility_id VARCHAR(255) NOT NULL,
+    position INT CHECK (position > 0),
+    session_id VARCHAR(255),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_search_interactions_impression ON search_interactions (impression_id);
+CREATE INDEX IF NOT EXISTS idx_search_interactions_facility ON search_interactions (facility_id, event_type);
+CREATE INDEX IF NOT EXISTS idx_search_interactions_created_at ON search_interactions (created_at);
@@ -0,0 +1,62 @@
+-- Native facility-managed appointment calendars (used instead of Calendly when enabled)
+CREATE TABLE IF NOT EXISTS facility_calendars (
+    facility_id VARCHAR(255) PRIMARY KEY REFERENCES facilities(id) ON DELETE CASCADE,
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    timezone VARCHAR(64) NOT NULL DEFAULT 'Africa/Lagos',
+    hold_minutes INT NOT NULL DEFAULT 10 CHECK (hold_minutes > 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Recurring weekly slot templates; times are wall-clock in the calendar's timezone
+CREATE TABLE IF NOT EXISTS calendar_slot_templates (
+    id VARCHAR(255) PRIMARY KEY,
+    facility_id VARCHAR(255) NOT NULL REFERENCES facilities(id) ON DELETE CASCADE,
+    ward_id VARCHAR(255) REFERENCES facility_wards(id) ON DELETE CASCADE,
+    weekday SMALLINT NOT NULL CHECK (weekday BETWEEN 0 AND 6),
+    start_time VARCHAR(5) NOT NULL,
+    end_time VARCHAR(5) NOT NULL,
+    slot_minutes INT NOT NULL CHECK (slot_minutes > 0),
+    capacity INT NOT NULL DEFAULT 1 CHECK (capacity > 0),
+    valid_from DATE,
+    valid_until DATE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_calendar_slot_templates_facility ON calendar_slot_templates(facility_id);
+
+CREATE TABLE IF NOT EXISTS calendar_blackouts (
+    id VARCHAR(255) PRIMARY KEY,
+    facility_id VARCHAR(255) NOT NULL REFERENCES facilities(id) ON DELETE CASCADE,
+    ward_id VARCHAR(255) REFERENCES facility_wards(id) ON DELETE CASCADE,
+    starts_at TIMESTAMPTZ NOT NULL,
+    ends_at TIMESTAMPTZ NOT NULL CHECK (ends_at > starts_at),
+    reason TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_calendar_blackouts_facility ON calendar_blackouts(facility_id, starts_at, ends_at);
+
+-- Slots are materialized from templates on demand; ids are derived from
+-- template and start time so materialization is idempotent
+ALTER TABLE availability_slots
+    ADD COLUMN IF NOT EXISTS ward_id VARCHAR(255) REFERENCES facility_wards(id) ON DELETE CASCADE,
+    ADD COLUMN IF NOT EXISTS template_id VARCHAR(255) REFERENCES calendar_slot_templates(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS capacity INT NOT NULL DEFAULT 1 CHECK (capacity > 0);
+
+-- Holds and bookings against a slot. Capacity is enforced by locking the slot
+-- row (SELECT ... FOR UPDATE) before counting active reservations.
+CREATE TABLE IF NOT EXISTS slot_reservations (
+    id VARCHAR(255) PRIMARY KEY,
+    slot_id VARCHAR(255) NOT NULL REFERENCES availability_slots(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL,
+    expires_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_slot_reservations_slot_status ON slot_reservations(slot_id, status);
+
+ALTER TABLE appointments
+    ADD COLUMN IF NOT EXISTS slot_reservation_id VARCHAR(255) REFERENCES slot_reservations(id) ON DELETE SET NULL;
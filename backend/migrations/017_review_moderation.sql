@@ -0,0 +1,20 @@
+-- Verified patient reviews: each review belongs to a completed appointment and
+-- is only counted in facilities.rating / review_count once published.
+ALTER TABLE reviews
+    ALTER COLUMN user_id DROP NOT NULL,
+    DROP CONSTRAINT IF EXISTS reviews_user_id_facility_id_key,
+    ADD COLUMN IF NOT EXISTS appointment_id VARCHAR(255) REFERENCES appointments(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS reviewer_name VARCHAR(255),
+    ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    ADD COLUMN IF NOT EXISTS moderation_flags TEXT[] NOT NULL DEFAULT '{}',
+    ADD COLUMN IF NOT EXISTS moderation_note TEXT,
+    ADD COLUMN IF NOT EXISTS moderated_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS facility_response TEXT,
+    ADD COLUMN IF NOT EXISTS responded_at TIMESTAMPTZ;
+
+-- Reviews that predate moderation were already counted in the facility rating
+UPDATE reviews SET status = 'published' WHERE appointment_id IS NULL AND status = 'pending';
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_reviews_appointment ON reviews(appointment_id) WHERE appointment_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_reviews_facility_status ON reviews(facility_id, status, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_reviews_status_created ON reviews(status, created_at);
@@ -0,0 +1,45 @@
+-- Patient accounts: phone OTP login, sessions, saved facilities and notification
+-- preferences. Accounts are keyed by an E.164 phone number; email is optional.
+ALTER TABLE users
+    ALTER COLUMN email DROP NOT NULL,
+    ADD COLUMN IF NOT EXISTS last_login_at TIMESTAMPTZ;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_phone ON users(phone) WHERE phone IS NOT NULL;
+
+-- One-time login codes. Only a hash of the code is stored.
+CREATE TABLE IF NOT EXISTS login_otps (
+    id VARCHAR(255) PRIMARY KEY,
+    phone VARCHAR(50) NOT NULL,
+    code_hash VARCHAR(64) NOT NULL,
+    channel VARCHAR(20) NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    expires_at TIMESTAMPTZ NOT NULL,
+    consumed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_login_otps_phone_created ON login_otps(phone, created_at DESC);
+
+-- Bearer sessions. The token is only returned to the client; the table keeps its SHA-256.
+CREATE TABLE IF NOT EXISTS user_sessions (
+    id VARCHAR(255) PRIMARY KEY,
+    user_id VARCHAR(255) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_sessions_user ON user_sessions(user_id);
+CREATE INDEX IF NOT EXISTS idx_user_sessions_expires ON user_sessions(expires_at);
+
+CREATE TABLE IF NOT EXISTS saved_facilities (
+    user_id VARCHAR(255) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    facility_id VARCHAR(255) NOT NULL REFERENCES facilities(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, facility_id)
+);
+
+-- Anonymous bookings are claimed by phone on first login
+CREATE INDEX IF NOT EXISTS idx_appointments_unclaimed_phone
+ON appointments(RIGHT(regexp_replace(patient_phone, '\D', '', 'g'), 10))
+WHERE user_id IS NULL AND patient_phone IS NOT NULL;
@@ -0,0 +1,111 @@
+-- Multi-channel notifications: SMS and email templates for the WhatsApp
+-- fallback chain. The WhatsApp 24h reminder was seeded with template_type
+-- 'reminder', which never matched the 'reminder_24h' notification type.
+UPDATE notification_templates
+SET template_type = 'reminder_24h', updated_at = CURRENT_TIMESTAMP
+WHERE name = 'reminder_24h_whatsapp' AND template_type = 'reminder';
+
+INSERT INTO notification_templates (id, name, channel, template_type, subject, body)
+VALUES
+(
+    'tmpl_booking_confirm_sms',
+    'booking_confirmation_sms',
+    'sms',
+    'booking_confirmation',
+    NULL,
+    'Appointment confirmed: {{procedure_name}} at {{facility_name}}, {{scheduled_date}} {{scheduled_time}}. {{facility_address}}'
+),
+(
+    'tmpl_reminder_24h_sms',
+    'reminder_24h_sms',
+    'sms',
+    'reminder_24h',
+    NULL,
+    'Reminder: your appointment at {{facility_name}} is tomorrow, {{scheduled_date}} {{scheduled_time}}.'
+),
+(
+    'tmpl_reminder_1h_sms',
+    'reminder_1h_sms',
+    'sms',
+    'reminder_1h',
+    NULL,
+    'Reminder: your appointment at {{facility_name}} starts at {{scheduled_time}} today.'
+),
+(
+    'tmpl_cancellation_sms',
+    'cancellation_sms',
+    'sms',
+    'cancellation',
+    NULL,
+    'Your appointment at {{facility_name}} on {{scheduled_date}} {{scheduled_time}} has been canceled.'
+),
+(
+    'tmpl_booking_confirm_email',
+    'booking_confirmation_email',
+    'email',
+    'booking_confirmation',
+    'Appointment confirmed: {{procedure_name}} on {{scheduled_date}}',
+    'Hi {{patient_name}},
+
+Your appointment is confirmed.
+
+Procedure: {{procedure_name}}
+Date: {{scheduled_date}}
+Time: {{scheduled_time}}
+Facility: {{facility_name}}
+Location: {{facility_address}}
+{{#if meeting_link}}
+Join online: {{meeting_link}}
+{{/if}}
+If you need to cancel, please contact the facility.'
+),
+(
+    'tmpl_reminder_24h_email',
+    'reminder_24h_email',
+    'email',
+    'reminder_24h',
+    'Reminder: your appointment tomorrow at {{facility_name}}',
+    'Hi {{patient_name}},
+
+This is a reminder that your appointment is tomorrow.
+
+Procedure: {{procedure_name}}
+Date: {{scheduled_date}}
+Time: {{scheduled_time}}
+Facility: {{facility_name}}
+Location: {{facility_address}}
+{{#if meeting_link}}
+Join online: {{meeting_link}}
+{{/if}}'
+),
+(
+    'tmpl_reminder_1h_email',
+    'reminder_1h_email',
+    'email',
+    'reminder_1h',
+    'Starting soon: your appointment at {{facility_name}}',
+    'Hi {{patient_name}},
+
+Your appointment at {{facility_name}} starts at {{scheduled_time}} today.
+{{#if meeting_link}}
+Join online: {{meeting_link}}
+{{/if}}'
+),
+(
+    'tmpl_cancellation_email',
+    'cancellation_email',
+    'email',
+    'cancellation',
+    'Appointment canceled: {{procedure_name}} on {{scheduled_date}}',
+    'Hi {{patient_name}},
+
+Your appointment has been canceled.
+
+Procedure: {{procedure_name}}
+Date: {{scheduled_date}}
+Time: {{scheduled_time}}
+Facility: {{facility_name}}
+
+You can book a new appointment at any time.'
+)
+ON CONFLICT (name) DO NOTHING;
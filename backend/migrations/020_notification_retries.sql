@@ -0,0 +1,14 @@
+-- Notification retry queue: failed sends are retried with exponential backoff
+-- until next_retry_at is cleared by success or they move to dead_letter.
+ALTER TABLE appointment_notifications
+    ADD COLUMN IF NOT EXISTS next_retry_at TIMESTAMP;
+
+DROP INDEX IF EXISTS idx_notifications_failed;
+
+CREATE INDEX IF NOT EXISTS idx_notifications_retry_due
+ON appointment_notifications(next_retry_at)
+WHERE status = 'failed' AND next_retry_at IS NOT NULL;
+
+-- Delivery status callbacks are matched by provider message ID
+CREATE INDEX IF NOT EXISTS idx_notifications_message_id
+ON appointment_notifications(message_id) WHERE message_id IS NOT NULL;
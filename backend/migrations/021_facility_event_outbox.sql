@@ -0,0 +1,21 @@
+-- Transactional outbox for facility events. Facility, ward and service
+-- availability writes insert their events here in the same transaction; a
+-- relay publishes pending rows to the event bus in sequence order.
+CREATE TABLE IF NOT EXISTS facility_event_outbox (
+    sequence BIGSERIAL PRIMARY KEY,
+    event_id VARCHAR(64) NOT NULL UNIQUE,
+    facility_id VARCHAR(255) NOT NULL,
+    event_type VARCHAR(50) NOT NULL,
+    payload JSONB NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    published_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_facility_event_outbox_pending
+ON facility_event_outbox(sequence) WHERE published_at IS NULL;
+
+-- Published rows are pruned by age
+CREATE INDEX IF NOT EXISTS idx_facility_event_outbox_published
+ON facility_event_outbox(published_at) WHERE published_at IS NOT NULL;
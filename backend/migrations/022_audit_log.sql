@@ -0,0 +1,35 @@
+-- Append-only audit log of operator and admin mutations. Each row's hash
+-- covers its content and the previous row's hash, so tampering with or
+-- removing a row is detected by re-walking the chain.
+CREATE TABLE IF NOT EXISTS audit_log (
+    sequence BIGSERIAL PRIMARY KEY,
+    id VARCHAR(255) NOT NULL UNIQUE,
+    actor_id VARCHAR(255) NOT NULL,
+    action VARCHAR(100) NOT NULL,
+    entity_type VARCHAR(50) NOT NULL,
+    entity_id VARCHAR(255) NOT NULL,
+    facility_id VARCHAR(255),
+    changes JSONB NOT NULL DEFAULT '{}',
+    request_id VARCHAR(255),
+    ip_address VARCHAR(64),
+    created_at TIMESTAMP NOT NULL,
+    prev_hash VARCHAR(64) NOT NULL,
+    hash VARCHAR(64) NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_facility ON audit_log(facility_id, created_at DESC) WHERE facility_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_audit_log_actor ON audit_log(actor_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_log_entity ON audit_log(entity_type, entity_id);
+CREATE INDEX IF NOT EXISTS idx_audit_log_created ON audit_log(created_at DESC);
+
+-- Rows are never updated or deleted
+CREATE OR REPLACE FUNCTION reject_audit_log_change() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS audit_log_append_only ON audit_log;
+CREATE TRIGGER audit_log_append_only
+BEFORE UPDATE OR DELETE ON audit_log
+FOR EACH ROW EXECUTE FUNCTION reject_audit_log_change();
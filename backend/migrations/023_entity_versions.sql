@@ -0,0 +1,6 @@
+-- Row versions for optimistic concurrency. Every update bumps the version and
+-- only applies when the writer saw the current one, so concurrent edits to
+-- the same facility, ward or service conflict instead of overwriting each other.
+ALTER TABLE facilities ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE facility_wards ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE facility_procedures ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
@@ -0,0 +1,23 @@
+-- When each facility capacity field was last reported. Capacity decays to
+-- unknown once its report is older than the freshness window, so stale values
+-- are not shown to patients indefinitely. Ward fields are measured from
+-- facility_wards.last_updated.
+ALTER TABLE facilities
+    ADD COLUMN IF NOT EXISTS capacity_status_reported_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS avg_wait_reported_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS urgent_care_reported_at TIMESTAMP;
+
+-- Existing values were last reported no later than the facility's last update
+UPDATE facilities SET capacity_status_reported_at = updated_at
+    WHERE capacity_status IS NOT NULL AND capacity_status_reported_at IS NULL;
+UPDATE facilities SET avg_wait_reported_at = updated_at
+    WHERE avg_wait_minutes IS NOT NULL AND avg_wait_reported_at IS NULL;
+UPDATE facilities SET urgent_care_reported_at = updated_at
+    WHERE urgent_care_available IS NOT NULL AND urgent_care_reported_at IS NULL;
+
+-- Last time the facility's operator was reminded that its capacity data is
+-- about to expire, so each report gets at most one reminder
+CREATE TABLE IF NOT EXISTS facility_capacity_nudges (
+    facility_id VARCHAR(255) PRIMARY KEY REFERENCES facilities(id) ON DELETE CASCADE,
+    nudged_at TIMESTAMP NOT NULL
+);
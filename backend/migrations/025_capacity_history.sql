@@ -0,0 +1,31 @@
+-- Every reported capacity status and wait time, so typical conditions can be
+-- learned per hour of the week. ward_name is empty for facility-wide reports.
+-- A NULL value means the field was not part of that report.
+CREATE TABLE IF NOT EXISTS capacity_observations (
+    id BIGSERIAL PRIMARY KEY,
+    facility_id VARCHAR(255) NOT NULL REFERENCES facilities(id) ON DELETE CASCADE,
+    ward_name VARCHAR(100) NOT NULL DEFAULT '',
+    capacity_status TEXT,
+    avg_wait_minutes INTEGER,
+    observed_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_capacity_observations_facility_observed
+    ON capacity_observations(facility_id, ward_name, observed_at);
+CREATE INDEX IF NOT EXISTS idx_capacity_observations_observed_at
+    ON capacity_observations(observed_at);
+
+-- Recency-weighted averages per facility, ward and hour of the week (0 is
+-- Sunday 00:00 in the configured capacity timezone), rebuilt from
+-- capacity_observations by the history aggregation job
+CREATE TABLE IF NOT EXISTS wait_time_profiles (
+    facility_id VARCHAR(255) NOT NULL REFERENCES facilities(id) ON DELETE CASCADE,
+    ward_name VARCHAR(100) NOT NULL DEFAULT '',
+    hour_of_week SMALLINT NOT NULL CHECK (hour_of_week BETWEEN 0 AND 167),
+    avg_wait_minutes DOUBLE PRECISION,
+    wait_weight DOUBLE PRECISION NOT NULL DEFAULT 0,
+    typical_capacity_status TEXT,
+    samples INTEGER NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (facility_id, ward_name, hour_of_week)
+);
@@ -0,0 +1,26 @@
+-- Geocoding results keyed by normalised address, so each address is only
+-- sent to a paid provider once. source records where the coordinates came
+-- from; manual entries are set by an admin and are never replaced by a
+-- provider's answer.
+CREATE TABLE IF NOT EXISTS geocoded_addresses (
+    address_key TEXT PRIMARY KEY,
+    address TEXT NOT NULL,
+    formatted_address TEXT,
+    street TEXT,
+    city TEXT,
+    state TEXT,
+    zip_code TEXT,
+    country TEXT,
+    latitude DOUBLE PRECISION NOT NULL,
+    longitude DOUBLE PRECISION NOT NULL,
+    source VARCHAR(32) NOT NULL CHECK (source IN ('google', 'gazetteer', 'manual', 'provider_profile')),
+    confidence DOUBLE PRECISION NOT NULL DEFAULT 0 CHECK (confidence BETWEEN 0 AND 1),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Where a facility's coordinates came from. A pinned location was set by an
+-- admin and ingestion leaves it alone.
+ALTER TABLE facilities
+    ADD COLUMN IF NOT EXISTS location_source VARCHAR(32),
+    ADD COLUMN IF NOT EXISTS location_pinned BOOLEAN NOT NULL DEFAULT FALSE;
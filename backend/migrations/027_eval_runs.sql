@@ -0,0 +1,23 @@
+-- Search quality evaluation runs, so metrics can be compared across commits.
+-- report holds the full run report, per-query results included; passed is
+-- NULL for runs that only recorded a baseline.
+CREATE TABLE IF NOT EXISTS eval_runs (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    corpus VARCHAR(32) NOT NULL,
+    golden_set TEXT NOT NULL,
+    git_commit VARCHAR(64),
+    embedding_model TEXT,
+    total_queries INTEGER NOT NULL,
+    avg_recall_at_10 DOUBLE PRECISION NOT NULL,
+    avg_mrr_at_10 DOUBLE PRECISION NOT NULL,
+    avg_latency_ms DOUBLE PRECISION NOT NULL,
+    queries_with_hits INTEGER NOT NULL,
+    failed_queries INTEGER NOT NULL,
+    passed BOOLEAN,
+    breaches INTEGER NOT NULL DEFAULT 0,
+    report JSONB NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_eval_runs_corpus_created
+    ON eval_runs(corpus, golden_set, created_at DESC);
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 )
 
 // Config holds all application configuration
@@ -15,7 +16,11 @@ type Config struct {
 	ProviderAPI ProviderAPIConfig
 	Geolocation GeolocationConfig
 	OpenAI      OpenAIConfig
+	Embedding   EmbeddingConfig
+	Routing     RoutingConfig
 	OTEL        OTELConfig
+	Capacity    CapacityFreshnessConfig
+	Audit       AuditConfig
 }
 
 // ServerConfig holds server configuration
@@ -65,10 +70,25 @@ type GeolocationConfig struct {
 type OpenAIConfig struct {
 	APIKey         string
 	Model          string
+	EmbeddingModel string
 	RateLimitRPM   int
 	RateLimitBurst int
 }
 
+// EmbeddingConfig holds semantic search configuration. The API and the
+// indexer must use the same provider, since vectors from different models
+// cannot be compared.
+type EmbeddingConfig struct {
+	Provider      string  // "local" or "openai"
+	VectorWeight  float64 // share of the hybrid rank given to vector similarity
+	MinSimilarity float64 // cosine similarity below which vector matches are dropped
+}
+
+// RoutingConfig holds travel time estimation configuration
+type RoutingConfig struct {
+	Provider string // "local"
+}
+
 // OTELConfig holds OpenTelemetry configuration
 type OTELConfig struct {
 	ServiceName    string
@@ -77,6 +97,29 @@ type OTELConfig struct {
 	Enabled        bool
 }
 
+// CapacityFreshnessConfig holds how long reported capacity values stay valid
+// and how their history is kept
+type CapacityFreshnessConfig struct {
+	StatusTTLMinutes     int
+	WaitTimeTTLMinutes   int
+	UrgentCareTTLMinutes int
+	NudgeBeforeMinutes   int
+	CheckIntervalMinutes int
+	// Timezone is the IANA zone hours of the week are counted in for wait forecasts
+	Timezone                      string
+	HistoryRebuildIntervalMinutes int
+	HistoryRetentionDays          int
+}
+
+// AuditConfig holds how requests are attributed in the audit log
+type AuditConfig struct {
+	// OperatorTokens maps operator names to the bearer tokens they authenticate with
+	OperatorTokens map[string]string
+	// TrustedProxies are the CIDRs or addresses whose X-Forwarded-For and
+	// X-Real-IP headers are believed
+	TrustedProxies []string
+}
+
 // Load loads configuration from environment variables
 func Load() (*Config, error) {
 	return &Config{
@@ -114,15 +157,38 @@ func Load() (*Config, error) {
 		OpenAI: OpenAIConfig{
 			APIKey:         getEnv("OPENAI_API_KEY", ""),
 			Model:          getEnv("OPENAI_MODEL", "gpt-4o-mini"),
+			EmbeddingModel: getEnv("OPENAI_EMBEDDING_MODEL", "text-embedding-3-small"),
 			RateLimitRPM:   getEnvAsInt("OPENAI_RATE_LIMIT_RPM", 60),
 			RateLimitBurst: getEnvAsInt("OPENAI_RATE_LIMIT_BURST", 5),
 		},
+		Embedding: EmbeddingConfig{
+			Provider:      getEnv("EMBEDDING_PROVIDER", "local"),
+			VectorWeight:  getEnvAsFloat("SEMANTIC_SEARCH_VECTOR_WEIGHT", 0.3),
+			MinSimilarity: getEnvAsFloat("SEMANTIC_SEARCH_MIN_SIMILARITY", 0.3),
+		},
+		Routing: RoutingConfig{
+			Provider: getEnv("ROUTING_PROVIDER", "local"),
+		},
 		OTEL: OTELConfig{
 			ServiceName:    getEnv("OTEL_SERVICE_NAME", "patient-price-discovery"),
 			ServiceVersion: getEnv("OTEL_SERVICE_VERSION", "1.0.0"),
 			Endpoint:       getEnv("OTEL_ENDPOINT", ""),
 			Enabled:        getEnvAsBool("OTEL_ENABLED", false),
 		},
+		Capacity: CapacityFreshnessConfig{
+			StatusTTLMinutes:              getEnvAsInt("CAPACITY_STATUS_TTL_MINUTES", 720),
+			WaitTimeTTLMinutes:            getEnvAsInt("CAPACITY_WAIT_TIME_TTL_MINUTES", 240),
+			UrgentCareTTLMinutes:          getEnvAsInt("CAPACITY_URGENT_CARE_TTL_MINUTES", 1440),
+			NudgeBeforeMinutes:            getEnvAsInt("CAPACITY_NUDGE_BEFORE_MINUTES", 60),
+			CheckIntervalMinutes:          getEnvAsInt("CAPACITY_CHECK_INTERVAL_MINUTES", 5),
+			Timezone:                      getEnv("CAPACITY_TIMEZONE", "Africa/Lagos"),
+			HistoryRebuildIntervalMinutes: getEnvAsInt("CAPACITY_HISTORY_REBUILD_INTERVAL_MINUTES", 60),
+			HistoryRetentionDays:          getEnvAsInt("CAPACITY_HISTORY_RETENTION_DAYS", 182),
+		},
+		Audit: AuditConfig{
+			OperatorTokens: getEnvAsMap("AUDIT_OPERATOR_TOKENS"),
+			TrustedProxies: getEnvAsList("TRUSTED_PROXIES"),
+		},
 	}, nil
 }
 
@@ -155,6 +221,15 @@ func getEnvAsInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+func getEnvAsFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if floatVal, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatVal
+		}
+	}
+	return defaultValue
+}
+
 func getEnvAsBool(key string, defaultValue bool) bool {
 	if value := os.Getenv(key); value != "" {
 		if boolVal, err := strconv.ParseBool(value); err == nil {
@@ -163,3 +238,27 @@ func getEnvAsBool(key string, defaultValue bool) bool {
 	}
 	return defaultValue
 }
+
+// getEnvAsList splits a comma-separated value, dropping empty items
+func getEnvAsList(key string) []string {
+	var items []string
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// getEnvAsMap parses a comma-separated list of name=value pairs
+func getEnvAsMap(key string) map[string]string {
+	pairs := make(map[string]string)
+	for _, item := range getEnvAsList(key) {
+		name, value, ok := strings.Cut(item, "=")
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+		if ok && name != "" && value != "" {
+			pairs[name] = value
+		}
+	}
+	return pairs
+}
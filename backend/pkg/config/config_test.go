@@ -56,3 +56,42 @@ func TestLoad_OpenAIConfig(t *testing.T) {
 	assert.Equal(t, "test-openai-key", cfg.OpenAI.APIKey)
 	assert.Equal(t, "gpt-4o-mini", cfg.OpenAI.Model)
 }
+
+func TestLoad_EmbeddingConfig(t *testing.T) {
+	cfg, err := Load()
+	assert.NoError(t, err)
+	assert.Equal(t, "local", cfg.Embedding.Provider)
+	assert.Equal(t, 0.3, cfg.Embedding.VectorWeight)
+	assert.Equal(t, "text-embedding-3-small", cfg.OpenAI.EmbeddingModel)
+
+	os.Setenv("EMBEDDING_PROVIDER", "openai")
+	os.Setenv("SEMANTIC_SEARCH_VECTOR_WEIGHT", "0.5")
+	defer func() {
+		os.Unsetenv("EMBEDDING_PROVIDER")
+		os.Unsetenv("SEMANTIC_SEARCH_VECTOR_WEIGHT")
+	}()
+
+	cfg, err = Load()
+	assert.NoError(t, err)
+	assert.Equal(t, "openai", cfg.Embedding.Provider)
+	assert.Equal(t, 0.5, cfg.Embedding.VectorWeight)
+}
+
+func TestLoad_AuditConfig(t *testing.T) {
+	cfg, err := Load()
+	assert.NoError(t, err)
+	assert.Empty(t, cfg.Audit.OperatorTokens)
+	assert.Empty(t, cfg.Audit.TrustedProxies)
+
+	os.Setenv("AUDIT_OPERATOR_TOKENS", "ada=tok-1, grace = tok-2,broken")
+	os.Setenv("TRUSTED_PROXIES", "10.0.0.0/8, 192.168.1.5,")
+	defer func() {
+		os.Unsetenv("AUDIT_OPERATOR_TOKENS")
+		os.Unsetenv("TRUSTED_PROXIES")
+	}()
+
+	cfg, err = Load()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"ada": "tok-1", "grace": "tok-2"}, cfg.Audit.OperatorTokens)
+	assert.Equal(t, []string{"10.0.0.0/8", "192.168.1.5"}, cfg.Audit.TrustedProxies)
+}
@@ -20,6 +20,9 @@ const (
 	// ErrorTypeUnauthorized indicates unauthorized access
 	ErrorTypeUnauthorized ErrorType = "UNAUTHORIZED"
 
+	// ErrorTypeRateLimited indicates the caller must wait before retrying
+	ErrorTypeRateLimited ErrorType = "RATE_LIMITED"
+
 	// ErrorTypeInternal indicates an internal server error
 	ErrorTypeInternal ErrorType = "INTERNAL"
 
@@ -79,6 +82,14 @@ func NewUnauthorizedError(message string) *AppError {
 	}
 }
 
+// NewRateLimitedError creates a new rate limited error
+func NewRateLimitedError(message string) *AppError {
+	return &AppError{
+		Type:    ErrorTypeRateLimited,
+		Message: message,
+	}
+}
+
 // NewInternalError creates a new internal error
 func NewInternalError(message string, err error) *AppError {
 	return &AppError{
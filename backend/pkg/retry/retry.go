@@ -3,6 +3,7 @@ package retry
 import (
 	"context"
 	"fmt"
+	"math/rand/v2"
 	"time"
 )
 
@@ -13,8 +14,15 @@ type Config struct {
 	MaxDelay        time.Duration
 	BackoffFactor   float64
 	MaxTotalTimeout time.Duration
+	// Jitter spreads each Backoff delay uniformly within this fraction of
+	// itself (0.1 is ±10%), so callers that failed together do not retry in
+	// lockstep. Zero keeps delays exact.
+	Jitter float64
 }
 
+// jitterFloat returns a value in [0, 1); tests replace it to pin the extremes
+var jitterFloat = rand.Float64
+
 // DefaultConfig returns a default retry configuration with 1 minute max timeout
 func DefaultConfig() Config {
 	return Config{
@@ -26,6 +34,28 @@ func DefaultConfig() Config {
 	}
 }
 
+// Backoff returns the delay to wait after the given failed attempt (1-based),
+// growing from InitialDelay by BackoffFactor and capped at MaxDelay, then
+// spread by Jitter without exceeding MaxDelay. It lets durable queues schedule
+// retries with the same curve Do uses in-process.
+func Backoff(cfg Config, attempt int) time.Duration {
+	delay := cfg.InitialDelay
+	for i := 1; i < attempt; i++ {
+		delay = time.Duration(float64(delay) * cfg.BackoffFactor)
+		if cfg.MaxDelay > 0 && delay >= cfg.MaxDelay {
+			delay = cfg.MaxDelay
+			break
+		}
+	}
+	if cfg.Jitter > 0 {
+		delay += time.Duration((jitterFloat()*2 - 1) * cfg.Jitter * float64(delay))
+	}
+	if cfg.MaxDelay > 0 && delay > cfg.MaxDelay {
+		return cfg.MaxDelay
+	}
+	return delay
+}
+
 // Do executes the given function with exponential backoff retry logic
 func Do(ctx context.Context, cfg Config, fn func() error) error {
 	if cfg.MaxTotalTimeout > 0 {
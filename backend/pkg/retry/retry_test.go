@@ -0,0 +1,80 @@
+package retry
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	cfg := Config{InitialDelay: time.Second, MaxDelay: 10 * time.Second, BackoffFactor: 2.0}
+
+	tests := []struct {
+		name    string
+		attempt int
+		want    time.Duration
+	}{
+		{"first attempt waits the initial delay", 1, time.Second},
+		{"attempt zero is treated as the first", 0, time.Second},
+		{"second attempt doubles", 2, 2 * time.Second},
+		{"fourth attempt grows geometrically", 4, 8 * time.Second},
+		{"fifth attempt is capped", 5, 10 * time.Second},
+		{"late attempts stay at the cap", 50, 10 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Backoff(cfg, tt.attempt); got != tt.want {
+				t.Errorf("Backoff(attempt %d) = %v, want %v", tt.attempt, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBackoff_NoCap(t *testing.T) {
+	cfg := Config{InitialDelay: time.Second, BackoffFactor: 3.0}
+	if got := Backoff(cfg, 4); got != 27*time.Second {
+		t.Errorf("Backoff without MaxDelay = %v, want 27s", got)
+	}
+}
+
+func TestBackoff_JitterBounds(t *testing.T) {
+	defer func(f func() float64) { jitterFloat = f }(jitterFloat)
+	cfg := Config{InitialDelay: time.Second, MaxDelay: 10 * time.Second, BackoffFactor: 2.0, Jitter: 0.1}
+
+	tests := []struct {
+		name    string
+		random  float64
+		attempt int
+		want    time.Duration
+	}{
+		{"lowest draw shortens by the jitter fraction", 0, 2, 1800 * time.Millisecond},
+		{"middle draw keeps the delay", 0.5, 2, 2 * time.Second},
+		{"highest draw lengthens by the jitter fraction", 0.999999, 2, 2200 * time.Millisecond},
+		{"jitter never exceeds the cap", 0.999999, 5, 10 * time.Second},
+		{"jitter can shorten a capped delay", 0, 5, 9 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jitterFloat = func() float64 { return tt.random }
+			got := Backoff(cfg, tt.attempt)
+			if diff := got - tt.want; diff < -time.Millisecond || diff > time.Millisecond {
+				t.Errorf("Backoff(attempt %d, draw %v) = %v, want %v", tt.attempt, tt.random, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBackoff_JitterSpreadsWithinBounds(t *testing.T) {
+	cfg := Config{InitialDelay: time.Second, MaxDelay: time.Minute, BackoffFactor: 2.0, Jitter: 0.2}
+
+	seen := map[time.Duration]bool{}
+	for i := 0; i < 1000; i++ {
+		got := Backoff(cfg, 3)
+		if got < 3200*time.Millisecond || got > 4800*time.Millisecond {
+			t.Fatalf("Backoff = %v, outside 4s ±20%%", got)
+		}
+		seen[got] = true
+	}
+	if len(seen) < 2 {
+		t.Error("expected jitter to vary the delay")
+	}
+}
@@ -0,0 +1,60 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+)
+
+// defaultCountryCode is applied to numbers written in national format, e.g. 0803 123 4567.
+const defaultCountryCode = "234"
+
+// NormalizePhone converts a phone number to E.164 (+2348031234567). Nigerian
+// national and bare subscriber numbers get the +234 country code; other numbers
+// must carry an international prefix.
+func NormalizePhone(raw string) (string, error) {
+	var digits strings.Builder
+	value := strings.TrimSpace(raw)
+	international := strings.HasPrefix(value, "+")
+	for _, r := range value {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case r == '+' || r == ' ' || r == '-' || r == '.' || r == '(' || r == ')':
+		default:
+			return "", fmt.Errorf("phone number contains invalid character %q", r)
+		}
+	}
+
+	number := digits.String()
+	switch {
+	case international:
+	case strings.HasPrefix(number, "00"):
+		number = number[2:]
+	case strings.HasPrefix(number, defaultCountryCode) && len(number) == 13:
+	case strings.HasPrefix(number, "0") && len(number) == 11:
+		number = defaultCountryCode + number[1:]
+	case len(number) == 10:
+		number = defaultCountryCode + number
+	}
+
+	if len(number) < 8 || len(number) > 15 || number[0] == '0' {
+		return "", fmt.Errorf("phone number %q is not valid", raw)
+	}
+	return "+" + number, nil
+}
+
+// PhoneSubscriberDigits returns the last ten digits of a phone number, which
+// match the same Nigerian line however the country code was written.
+func PhoneSubscriberDigits(phone string) string {
+	var digits strings.Builder
+	for _, r := range phone {
+		if r >= '0' && r <= '9' {
+			digits.WriteRune(r)
+		}
+	}
+	number := digits.String()
+	if len(number) > 10 {
+		return number[len(number)-10:]
+	}
+	return number
+}
@@ -0,0 +1,42 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizePhone(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{"08031234567", "+2348031234567"},
+		{"0803 123 4567", "+2348031234567"},
+		{"+234 803-123-4567", "+2348031234567"},
+		{"2348031234567", "+2348031234567"},
+		{"8031234567", "+2348031234567"},
+		{"00447700900123", "+447700900123"},
+		{"+1 (415) 555-0100", "+14155550100"},
+	}
+
+	for _, tt := range tests {
+		got, err := NormalizePhone(tt.raw)
+		require.NoError(t, err, tt.raw)
+		assert.Equal(t, tt.want, got, tt.raw)
+	}
+}
+
+func TestNormalizePhone_Invalid(t *testing.T) {
+	for _, raw := range []string{"", "12345", "0803abc4567", "+0123456789", "+1234567890123456"} {
+		_, err := NormalizePhone(raw)
+		assert.Error(t, err, raw)
+	}
+}
+
+func TestPhoneSubscriberDigits(t *testing.T) {
+	assert.Equal(t, "8031234567", PhoneSubscriberDigits("+234 803 123 4567"))
+	assert.Equal(t, "8031234567", PhoneSubscriberDigits("08031234567"))
+	assert.Equal(t, "12345", PhoneSubscriberDigits("12-345"))
+}
@@ -95,7 +95,7 @@ func TestFacilityService_UpdateServiceAvailability_PublishesEvent(t *testing.T)
 	require.NoError(t, err)
 	time.Sleep(50 * time.Millisecond)
 
-	fp, err := service.UpdateServiceAvailability(ctx, "fac-avail-1", "proc-avail-1", true)
+	fp, err := service.UpdateServiceAvailability(ctx, "fac-avail-1", "proc-avail-1", true, 0)
 	require.NoError(t, err)
 	assert.True(t, fp.IsAvailable)
 